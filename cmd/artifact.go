@@ -6,36 +6,54 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/mkoepf/ghcrctl/internal/discover"
 	"github.com/mkoepf/ghcrctl/internal/display"
+	"github.com/mkoepf/ghcrctl/internal/provenance"
+	"github.com/mkoepf/ghcrctl/internal/sbom"
 )
 
+// artifactFetchFunc fetches the documents of an attestation artifact.
+// discover.GetArtifactContent satisfies this signature.
+type artifactFetchFunc func(ctx context.Context, image, digest string) ([]map[string]interface{}, error)
+
 // fetchAndDisplayArtifact fetches and displays a single artifact
-func fetchAndDisplayArtifact(w io.Writer, ctx context.Context, image, digest string, jsonOutput bool, artifactType string) error {
+func fetchAndDisplayArtifact(w io.Writer, ctx context.Context, fetch artifactFetchFunc, image, digest string, jsonOutput, decode bool, artifactType string) error {
 	// Fetch the artifact content
-	content, err := discover.GetArtifactContent(ctx, image, digest)
+	content, err := fetch(ctx, image, digest)
 	if err != nil {
 		return fmt.Errorf("failed to fetch %s: %w", artifactType, err)
 	}
+	return displayArtifact(w, ctx, content, digest, jsonOutput, decode, artifactType)
+}
 
-	// Display the content
+// displayArtifact displays fetched artifact content. With decode, DSSE
+// envelopes are replaced by the in-toto statements they carry.
+func displayArtifact(w io.Writer, ctx context.Context, content []map[string]interface{}, digest string, jsonOutput, decode bool, artifactType string) error {
+	if decode {
+		content = discover.UnwrapDSSE(content)
+	}
 	if jsonOutput {
-		return display.OutputJSON(w, content)
+		return display.OutputJSON(ctx, w, content)
 	}
 	return outputArtifactReadable(w, content, digest, artifactType)
 }
 
 // fetchAndDisplayAllArtifacts fetches and displays all artifacts
-func fetchAndDisplayAllArtifacts(w io.Writer, ctx context.Context, image string, artifacts []discover.VersionInfo, jsonOutput bool, artifactType string) error {
+func fetchAndDisplayAllArtifacts(w io.Writer, ctx context.Context, fetch artifactFetchFunc, image string, artifacts []discover.VersionInfo, jsonOutput, decode bool, artifactType string) error {
 	allContent := make([]interface{}, 0, len(artifacts))
 
 	for _, artifact := range artifacts {
-		content, err := discover.GetArtifactContent(ctx, image, artifact.Digest)
+		content, err := fetch(ctx, image, artifact.Digest)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Warning: failed to fetch %s %s: %v\n", artifactType, artifact.Digest, err)
 			continue
 		}
+		if decode {
+			content = discover.UnwrapDSSE(content)
+		}
 
 		if jsonOutput {
 			allContent = append(allContent, map[string]interface{}{
@@ -51,16 +69,279 @@ func fetchAndDisplayAllArtifacts(w io.Writer, ctx context.Context, image string,
 	}
 
 	if jsonOutput {
-		data, err := json.MarshalIndent(allContent, "", "  ")
+		return display.OutputJSON(ctx, w, allContent)
+	}
+
+	return nil
+}
+
+// fetchAndSaveArtifact fetches a single artifact and writes its content to path.
+// If path has no extension, one is chosen from the detected predicate type.
+func fetchAndSaveArtifact(w io.Writer, ctx context.Context, fetch artifactFetchFunc, image, digest, path string, decode bool, artifactType string) error {
+	content, err := fetch(ctx, image, digest)
+	if err != nil {
+		return fmt.Errorf("failed to fetch %s: %w", artifactType, err)
+	}
+	if decode {
+		content = discover.UnwrapDSSE(content)
+	}
+
+	filename := artifactFileName(path, 0, content)
+	if err := writeArtifactFile(filename, content); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Wrote %s %s to %s\n", artifactType, display.ShortDigest(digest), filename)
+	return nil
+}
+
+// fetchAndSaveAllArtifacts fetches all artifacts and writes each to its own numbered file
+func fetchAndSaveAllArtifacts(w io.Writer, ctx context.Context, fetch artifactFetchFunc, image string, artifacts []discover.VersionInfo, path string, decode bool, artifactType string) error {
+	written := 0
+	for i, artifact := range artifacts {
+		content, err := fetch(ctx, image, artifact.Digest)
 		if err != nil {
-			return fmt.Errorf("failed to marshal JSON: %w", err)
+			fmt.Fprintf(os.Stderr, "Warning: failed to fetch %s %s: %v\n", artifactType, artifact.Digest, err)
+			continue
 		}
-		fmt.Fprintln(w, string(data))
+		if decode {
+			content = discover.UnwrapDSSE(content)
+		}
+
+		filename := artifactFileName(path, i+1, content)
+		if err := writeArtifactFile(filename, content); err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "Wrote %s %s to %s\n", artifactType, display.ShortDigest(artifact.Digest), filename)
+		written++
+	}
+
+	if written == 0 {
+		return fmt.Errorf("no %s documents could be fetched", artifactType)
+	}
+	return nil
+}
+
+// writeArtifactFile writes artifact content as indented JSON. A single document
+// is written as an object, multiple documents as an array.
+func writeArtifactFile(path string, content []map[string]interface{}) error {
+	var doc interface{} = content
+	if len(content) == 1 {
+		doc = content[0]
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	data = append(data, '\n')
+
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// artifactFileName builds the output filename for an artifact.
+// A non-zero index is inserted before the extension to number files written with --all.
+// If path has no extension, one is derived from the content's predicate type.
+func artifactFileName(path string, index int, content []map[string]interface{}) string {
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+	if ext == "" {
+		ext = artifactFileExtension(content)
+	} else if strings.HasSuffix(base, ".spdx") || strings.HasSuffix(base, ".cdx") ||
+		strings.HasSuffix(base, ".slsa") || strings.HasSuffix(base, ".vex") {
+		// Keep compound extensions like ".spdx.json" together
+		compound := filepath.Ext(base)
+		base = strings.TrimSuffix(base, compound)
+		ext = compound + ext
+	}
+
+	if index > 0 {
+		return fmt.Sprintf("%s-%d%s", base, index, ext)
+	}
+	return base + ext
+}
+
+// artifactFileExtension returns the file extension for the predicate type of the
+// first document in content, falling back to ".json" for unknown types.
+func artifactFileExtension(content []map[string]interface{}) string {
+	if len(content) == 0 {
+		return ".json"
+	}
+
+	predicateType := strings.ToLower(predicateTypeOf(content[0]))
+	switch {
+	case strings.Contains(predicateType, "spdx"):
+		return ".spdx.json"
+	case strings.Contains(predicateType, "cyclonedx"):
+		return ".cdx.json"
+	case strings.Contains(predicateType, "slsa"), strings.Contains(predicateType, "provenance"):
+		return ".slsa.json"
+	case strings.Contains(predicateType, "vex"):
+		return ".vex.json"
+	default:
+		return ".json"
+	}
+}
+
+// predicateTypeOf returns the predicate type of an in-toto statement, unwrapping
+// a DSSE envelope if necessary.
+func predicateTypeOf(doc map[string]interface{}) string {
+	pt, _ := discover.InTotoStatement(doc)["predicateType"].(string)
+	return pt
+}
+
+// provenanceBuilderID returns the SLSA builder ID of a provenance statement.
+// SLSA v0.2 stores it at predicate.builder.id, SLSA v1 at predicate.runDetails.builder.id.
+func provenanceBuilderID(doc map[string]interface{}) string {
+	predicate, _ := discover.InTotoStatement(doc)["predicate"].(map[string]interface{})
+	if predicate == nil {
+		return ""
+	}
+
+	if runDetails, ok := predicate["runDetails"].(map[string]interface{}); ok {
+		predicate = runDetails
+	}
+	builder, _ := predicate["builder"].(map[string]interface{})
+	id, _ := builder["id"].(string)
+	return id
+}
+
+// checkProvenanceBuilder verifies that every provenance statement in content
+// was produced by the expected builder.
+func checkProvenanceBuilder(w io.Writer, content []map[string]interface{}, digest, expected string) error {
+	found := 0
+	for _, doc := range content {
+		actual := provenanceBuilderID(doc)
+		if actual == "" {
+			continue
+		}
+		found++
+		if actual != expected {
+			return fmt.Errorf("builder mismatch for provenance %s: expected %q, got %q", display.ShortDigest(digest), expected, actual)
+		}
+	}
+
+	if found == 0 {
+		return fmt.Errorf("no SLSA builder ID found in provenance %s", display.ShortDigest(digest))
+	}
+
+	fmt.Fprintf(w, "%s provenance %s was built by %s\n", display.ColorSuccess("Verified:"), display.ShortDigest(digest), expected)
+	return nil
+}
+
+// verifyProvenanceBuilders fetches each provenance attestation and checks its builder ID.
+// It stops at the first provenance that does not match.
+func verifyProvenanceBuilders(w io.Writer, ctx context.Context, fetch artifactFetchFunc, image string, artifacts []discover.VersionInfo, expected string) error {
+	for _, artifact := range artifacts {
+		content, err := fetch(ctx, image, artifact.Digest)
+		if err != nil {
+			return fmt.Errorf("failed to fetch provenance %s: %w", display.ShortDigest(artifact.Digest), err)
+		}
+		if err := checkProvenanceBuilder(w, content, artifact.Digest, expected); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// provenanceMaterials is the JSON output of get provenance --materials for one
+// provenance statement
+type provenanceMaterials struct {
+	Digest      string                `json:"digest"`
+	SLSAVersion string                `json:"slsa_version"`
+	Materials   []provenance.Material `json:"materials"`
+}
+
+// collectProvenanceMaterials parses the SLSA provenance statements in the
+// content of the attestation with the given digest. Other documents are skipped.
+func collectProvenanceMaterials(content []map[string]interface{}, digest string) []provenanceMaterials {
+	var results []provenanceMaterials
+	for _, doc := range content {
+		statement := discover.InTotoStatement(doc)
+		if statement == nil {
+			continue
+		}
+		parsed, err := provenance.Parse(statement)
+		if err != nil {
+			continue
+		}
+		results = append(results, provenanceMaterials{
+			Digest:      digest,
+			SLSAVersion: parsed.SLSAVersion,
+			Materials:   parsed.Materials,
+		})
+	}
+	return results
+}
+
+// fetchAndDisplayMaterials fetches each provenance attestation and lists the
+// materials of its SLSA statements instead of the whole documents
+func fetchAndDisplayMaterials(w io.Writer, ctx context.Context, fetch artifactFetchFunc, image string, artifacts []discover.VersionInfo, jsonOutput bool) error {
+	results := []provenanceMaterials{}
+	for _, artifact := range artifacts {
+		content, err := fetch(ctx, image, artifact.Digest)
+		if err != nil {
+			return fmt.Errorf("failed to fetch provenance %s: %w", display.ShortDigest(artifact.Digest), err)
+		}
+		results = append(results, collectProvenanceMaterials(content, artifact.Digest)...)
+	}
+	if len(results) == 0 {
+		return fmt.Errorf("no SLSA provenance statement found")
 	}
 
+	if jsonOutput {
+		return display.OutputJSON(ctx, w, results)
+	}
+	outputMaterialsTable(w, results)
 	return nil
 }
 
+// outputMaterialsTable prints the materials of each provenance statement as a
+// table of URIs and resolved digests
+func outputMaterialsTable(w io.Writer, results []provenanceMaterials) {
+	for i, result := range results {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "Provenance: %s (SLSA %s)\n\n", display.ShortDigest(result.Digest), result.SLSAVersion)
+		if len(result.Materials) == 0 {
+			fmt.Fprintln(w, "No materials listed")
+			continue
+		}
+
+		uriWidth := len("URI")
+		for _, m := range result.Materials {
+			uriWidth = max(uriWidth, len(materialURI(m)))
+		}
+
+		fmt.Fprintf(w, "  %s  %s\n",
+			display.ColorHeader(fmt.Sprintf("%-*s", uriWidth, "URI")),
+			display.ColorHeader("DIGEST"))
+		fmt.Fprintf(w, "  %s  %s\n",
+			display.ColorSeparator(strings.Repeat("-", uriWidth)),
+			display.ColorSeparator(strings.Repeat("-", len("DIGEST"))))
+		for _, m := range result.Materials {
+			digest := m.DigestString()
+			if digest == "" {
+				digest = "-"
+			}
+			fmt.Fprintf(w, "  %-*s  %s\n", uriWidth, materialURI(m), digest)
+		}
+
+		fmt.Fprintf(w, "\nTotal: %s material(s).\n", display.ColorCount(len(result.Materials)))
+	}
+}
+
+// materialURI returns the URI of a material, or its name if it has no URI
+func materialURI(m provenance.Material) string {
+	if m.URI == "" {
+		return m.Name
+	}
+	return m.URI
+}
+
 // listArtifacts lists available artifacts without fetching their content
 // selectorType is "tag", "digest", or "version" to describe how the image was selected
 // selectorValue is the actual value used (e.g., "v1.0.0", "abc123", "12345678")
@@ -88,8 +369,13 @@ func listArtifacts(w io.Writer, artifacts []discover.VersionInfo, imageName, art
 	return nil
 }
 
-// outputArtifactReadable outputs artifact content in human-readable format
+// outputArtifactReadable outputs artifact content in human-readable format.
+// SBOMs are summarized as a component table if they can be parsed.
 func outputArtifactReadable(w io.Writer, content []map[string]interface{}, digest, artifactType string) error {
+	if artifactType == "sbom" && outputSBOMTable(w, content, digest) {
+		return nil
+	}
+
 	fmt.Fprintf(w, "%s: %s\n\n", capitalizeFirst(artifactType), display.ShortDigest(digest))
 
 	for _, attestation := range content {
@@ -104,6 +390,67 @@ func outputArtifactReadable(w io.Writer, content []map[string]interface{}, diges
 	return nil
 }
 
+// outputSBOMTable prints the components of each SPDX or CycloneDX document in
+// content as a table. It returns false without printing anything if no
+// document could be parsed.
+func outputSBOMTable(w io.Writer, content []map[string]interface{}, digest string) bool {
+	var docs []*sbom.Document
+	for _, doc := range content {
+		statement := discover.InTotoStatement(doc)
+		if statement == nil {
+			statement = doc
+		}
+		if parsed, err := sbom.Parse(statement); err == nil {
+			docs = append(docs, parsed)
+		}
+	}
+	if len(docs) == 0 {
+		return false
+	}
+
+	for i, doc := range docs {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "SBOM: %s (%s)\n\n", display.ShortDigest(digest), doc.Spec())
+		if len(doc.Components) == 0 {
+			fmt.Fprintln(w, "No components listed")
+			continue
+		}
+
+		nameWidth := len("NAME")
+		versionWidth := len("VERSION")
+		licenses := make(map[string]bool)
+		for _, c := range doc.Components {
+			nameWidth = max(nameWidth, len(c.Name))
+			versionWidth = max(versionWidth, len(c.Version))
+			if c.License != "" {
+				licenses[c.License] = true
+			}
+		}
+
+		fmt.Fprintf(w, "  %s  %s  %s\n",
+			display.ColorHeader(fmt.Sprintf("%-*s", nameWidth, "NAME")),
+			display.ColorHeader(fmt.Sprintf("%-*s", versionWidth, "VERSION")),
+			display.ColorHeader("LICENSE"))
+		fmt.Fprintf(w, "  %s  %s  %s\n",
+			display.ColorSeparator(strings.Repeat("-", nameWidth)),
+			display.ColorSeparator(strings.Repeat("-", versionWidth)),
+			display.ColorSeparator(strings.Repeat("-", len("LICENSE"))))
+		for _, c := range doc.Components {
+			license := c.License
+			if license == "" {
+				license = "-"
+			}
+			fmt.Fprintf(w, "  %-*s  %-*s  %s\n", nameWidth, c.Name, versionWidth, c.Version, license)
+		}
+
+		fmt.Fprintf(w, "\nTotal: %s component(s), %d distinct license(s).\n",
+			display.ColorCount(len(doc.Components)), len(licenses))
+	}
+	return true
+}
+
 // capitalizeFirst returns the string with the first letter capitalized
 func capitalizeFirst(s string) string {
 	if s == "" {
@@ -2,9 +2,15 @@ package cmd
 
 import (
 	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/mkoepf/ghcrctl/internal/discover"
+	"github.com/mkoepf/ghcrctl/internal/display"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -133,3 +139,135 @@ func TestListArtifacts_VersionSelector(t *testing.T) {
 	// Should show header with version context
 	assert.Contains(t, output, "image containing version 12345678", "Expected header with version context")
 }
+
+func TestArtifactFileExtension(t *testing.T) {
+	t.Parallel()
+
+	dssePayload := base64.StdEncoding.EncodeToString([]byte(`{"predicateType":"https://cyclonedx.org/bom"}`))
+
+	tests := []struct {
+		name    string
+		content []map[string]interface{}
+		want    string
+	}{
+		{"SPDX statement", []map[string]interface{}{{"predicateType": "https://spdx.dev/Document"}}, ".spdx.json"},
+		{"CycloneDX statement", []map[string]interface{}{{"predicateType": "https://cyclonedx.org/bom"}}, ".cdx.json"},
+		{"SLSA provenance v1", []map[string]interface{}{{"predicateType": "https://slsa.dev/provenance/v1"}}, ".slsa.json"},
+		{"OpenVEX statement", []map[string]interface{}{{"predicateType": "https://openvex.dev/ns/v0.2.0"}}, ".vex.json"},
+		{"DSSE envelope", []map[string]interface{}{{"payloadType": "application/vnd.in-toto+json", "payload": dssePayload}}, ".cdx.json"},
+		{"unknown predicate", []map[string]interface{}{{"predicateType": "https://example.com/custom"}}, ".json"},
+		{"empty content", nil, ".json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, artifactFileExtension(tt.content))
+		})
+	}
+}
+
+func TestArtifactFileName(t *testing.T) {
+	t.Parallel()
+
+	spdx := []map[string]interface{}{{"predicateType": "https://spdx.dev/Document"}}
+	slsa := []map[string]interface{}{{"predicateType": "https://slsa.dev/provenance/v0.2"}}
+
+	tests := []struct {
+		name    string
+		path    string
+		index   int
+		content []map[string]interface{}
+		want    string
+	}{
+		{"extension added from SPDX content", "out/sbom", 0, spdx, "out/sbom.spdx.json"},
+		{"extension added from SLSA content", "provenance", 0, slsa, "provenance.slsa.json"},
+		{"explicit extension kept", "sbom.json", 0, spdx, "sbom.json"},
+		{"numbered without extension", "sbom", 2, spdx, "sbom-2.spdx.json"},
+		{"numbered with explicit extension", "sbom.json", 1, spdx, "sbom-1.json"},
+		{"numbered keeps compound extension", "sbom.spdx.json", 3, spdx, "sbom-3.spdx.json"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, artifactFileName(tt.path, tt.index, tt.content))
+		})
+	}
+}
+
+func TestWriteArtifactFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	single := filepath.Join(dir, "single.json")
+	multi := filepath.Join(dir, "multi.json")
+
+	require.NoError(t, writeArtifactFile(single, []map[string]interface{}{{"predicateType": "https://spdx.dev/Document"}}))
+	require.NoError(t, writeArtifactFile(multi, []map[string]interface{}{{"a": "1"}, {"b": "2"}}))
+
+	data, err := os.ReadFile(single)
+	require.NoError(t, err)
+	var obj map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &obj), "a single document is written as an object")
+	assert.Equal(t, "https://spdx.dev/Document", obj["predicateType"])
+
+	data, err = os.ReadFile(multi)
+	require.NoError(t, err)
+	var arr []map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &arr), "multiple documents are written as an array")
+	assert.Len(t, arr, 2)
+}
+
+func TestGetSBOMCommandHasOutputFileFlag(t *testing.T) {
+	t.Parallel()
+	cmd := NewRootCmd()
+	for _, name := range []string{"sbom", "provenance", "vex", "vuln-scan"} {
+		sub, _, err := cmd.Find([]string{"get", name})
+		require.NoError(t, err)
+		assert.NotNil(t, sub.Flags().Lookup("output-file"), "expected --output-file on get %s", name)
+	}
+}
+
+func TestDisplayArtifact_Decode(t *testing.T) {
+	t.Parallel()
+	statement := `{"_type":"https://in-toto.io/Statement/v0.1","predicateType":"https://spdx.dev/Document",` +
+		`"predicate":{"spdxVersion":"SPDX-2.3","name":"myimage"}}`
+	envelope := map[string]interface{}{
+		"payloadType": "application/vnd.in-toto+json",
+		"payload":     base64.StdEncoding.EncodeToString([]byte(statement)),
+		"signatures":  []interface{}{map[string]interface{}{"sig": "MEUCIQ=="}},
+	}
+	content := []map[string]interface{}{envelope}
+	ctx := display.WithJSONStyle(context.Background(), display.JSONStyleCompact)
+
+	t.Run("decoded statement", func(t *testing.T) {
+		t.Parallel()
+		var out bytes.Buffer
+		require.NoError(t, displayArtifact(&out, ctx, content, "sha256:abc", true, true, "sbom"))
+
+		var docs []map[string]interface{}
+		require.NoError(t, json.Unmarshal(out.Bytes(), &docs))
+		require.Len(t, docs, 1)
+		assert.Equal(t, "https://spdx.dev/Document", docs[0]["predicateType"])
+		assert.Equal(t, map[string]interface{}{"spdxVersion": "SPDX-2.3", "name": "myimage"}, docs[0]["predicate"])
+		assert.NotContains(t, docs[0], "payload")
+	})
+
+	t.Run("raw envelope without --decode", func(t *testing.T) {
+		t.Parallel()
+		var out bytes.Buffer
+		require.NoError(t, displayArtifact(&out, ctx, content, "sha256:abc", true, false, "sbom"))
+
+		var docs []map[string]interface{}
+		require.NoError(t, json.Unmarshal(out.Bytes(), &docs))
+		require.Len(t, docs, 1)
+		assert.Equal(t, envelope["payload"], docs[0]["payload"])
+		assert.NotContains(t, docs[0], "predicateType")
+	})
+
+	t.Run("readable output", func(t *testing.T) {
+		t.Parallel()
+		var out bytes.Buffer
+		require.NoError(t, displayArtifact(&out, ctx, content, "sha256:abc", false, true, "sbom"))
+		assert.Contains(t, out.String(), "SBOM: abc (SPDX-2.3)", "SBOMs are summarized as a component table")
+	})
+}
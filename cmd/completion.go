@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/mkoepf/ghcrctl/internal/display"
 	"github.com/mkoepf/ghcrctl/internal/gh"
 	"github.com/spf13/cobra"
 )
@@ -103,7 +104,7 @@ func completeImageRef(cmd *cobra.Command, toComplete string) []string {
 	}
 
 	// List packages for owner
-	packages, err := client.ListPackages(ctx, owner, ownerType)
+	packages, err := client.ListPackages(ctx, owner, ownerType, gh.PackageTypeContainer)
 	if err != nil {
 		return nil
 	}
@@ -130,3 +131,17 @@ func imageRefValidArgsFunc(cmd *cobra.Command, args []string, toComplete string)
 	completions := completeImageRef(cmd, toComplete)
 	return completions, cobra.ShellCompDirectiveNoFileComp
 }
+
+// addOutputFlag registers the -o/--output flag with shell completion for the allowed modes
+func addOutputFlag(cmd *cobra.Command, target *string, allowed ...display.OutputMode) {
+	cmd.Flags().StringVarP(target, "output", "o", "", fmt.Sprintf("Output format (%s)", display.JoinOutputModes(allowed)))
+	_ = cmd.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		completions := make([]string, 0, len(allowed))
+		for _, mode := range allowed {
+			if strings.HasPrefix(string(mode), toComplete) {
+				completions = append(completions, string(mode))
+			}
+		}
+		return completions, cobra.ShellCompDirectiveNoFileComp
+	})
+}
@@ -0,0 +1,408 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/mkoepf/ghcrctl/internal/config"
+	"github.com/mkoepf/ghcrctl/internal/discover"
+	"github.com/mkoepf/ghcrctl/internal/display"
+	"github.com/mkoepf/ghcrctl/internal/gh"
+	"github.com/spf13/cobra"
+)
+
+// registryHost is the registry ghcrctl works with
+const registryHost = "ghcr.io"
+
+// newConfigCmd creates the config command with its subcommands.
+func newConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect the ghcrctl configuration",
+		Long:  `Inspect the ghcrctl configuration and check the setup.`,
+	}
+
+	cmd.AddCommand(newConfigDoctorCmd())
+	cmd.AddCommand(newConfigShowCmd())
+
+	return cmd
+}
+
+// newConfigDoctorCmd creates the config doctor subcommand.
+func newConfigDoctorCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check the token, configuration, and registry access",
+		Long: `Check the setup for the most common configuration problems.
+
+The checks run in order:
+  1. GITHUB_TOKEN is set
+  2. the token is valid (GitHub returns the user it belongs to)
+  3. the token has the scopes ghcrctl needs: read:packages to list, and
+     write:packages and delete:packages to tag and delete
+  4. the config file, if any, can be read, and the profile selected with
+     --profile or $GHCRCTL_PROFILE exists
+  5. the registry ghcr.io is reachable
+
+Each check is listed with a hint on how to fix it. The command fails if any
+critical check fails; missing write or delete scopes are only warnings. Scopes
+of fine-grained and GitHub Actions tokens cannot be checked.
+
+Examples:
+  # Check the setup
+  ghcrctl config doctor`,
+		Args:        cobra.NoArgs,
+		Annotations: map[string]string{skipProfileAnnotation: "true"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profileName, _ := cmd.Flags().GetString("profile")
+			if profileName == "" {
+				profileName = os.Getenv(profileEnv)
+			}
+			checks := runDoctor(cmd.Context(), liveDoctorProbe{}, profileName)
+			cmd.SilenceUsage = true
+			return reportDoctor(cmd.OutOrStdout(), checks)
+		},
+	}
+
+	return cmd
+}
+
+// newConfigShowCmd creates the config show subcommand.
+func newConfigShowCmd() *cobra.Command {
+	var (
+		jsonOutput   bool
+		outputFormat string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "show",
+		Short: "Show the config file, the selected profile, and all profiles",
+		Long: `Show the configuration ghcrctl runs with: the location of the config file,
+the registry, the profile selected with --profile or $GHCRCTL_PROFILE, and the
+owner and flag defaults of every profile.
+
+With --json, the configuration is printed as a JSON object with the fields
+path, registry, profile, and profiles, for tools that set up ghcrctl.
+
+Examples:
+  # Show the configuration
+  ghcrctl config show
+
+  # Read the configuration from a script
+  ghcrctl config show --json`,
+		Args:        cobra.NoArgs,
+		Annotations: map[string]string{skipProfileAnnotation: "true"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			mode, err := display.ParseOutputMode(outputFormat, display.OutputModeJSON, display.OutputModeTable)
+			if err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
+			switch mode {
+			case display.OutputModeJSON:
+				jsonOutput = true
+			case display.OutputModeTable:
+				jsonOutput = false
+			}
+
+			profileName, _ := cmd.Flags().GetString("profile")
+			if profileName == "" {
+				profileName = os.Getenv(profileEnv)
+			}
+
+			cmd.SilenceUsage = true
+			path, err := config.Path()
+			if err != nil {
+				return err
+			}
+			cfg, err := config.Load(path)
+			if err != nil {
+				return err
+			}
+
+			view := newConfigView(path, cfg, profileName)
+			if jsonOutput {
+				return display.OutputJSON(cmd.Context(), cmd.OutOrStdout(), view)
+			}
+			return outputConfigView(cmd.OutOrStdout(), view)
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output in JSON format")
+	addOutputFlag(cmd, &outputFormat, display.OutputModeJSON, display.OutputModeTable)
+
+	return cmd
+}
+
+// configView is the configuration as shown by config show
+type configView struct {
+	Path     string                    `json:"path"`
+	Registry string                    `json:"registry"`
+	Profile  string                    `json:"profile,omitempty"` // Selected profile, if any
+	Profiles map[string]config.Profile `json:"profiles"`
+}
+
+// newConfigView builds the view of cfg, read from path, with profileName selected
+func newConfigView(path string, cfg *config.Config, profileName string) configView {
+	profiles := cfg.Profiles
+	if profiles == nil {
+		profiles = map[string]config.Profile{}
+	}
+	return configView{Path: path, Registry: registryHost, Profile: profileName, Profiles: profiles}
+}
+
+// outputConfigView prints the configuration with the profiles sorted by name
+func outputConfigView(w io.Writer, view configView) error {
+	fmt.Fprintf(w, "%-12s %s\n", "Config file:", view.Path)
+	fmt.Fprintf(w, "%-12s %s\n", "Registry:", view.Registry)
+	switch {
+	case view.Profile == "":
+		fmt.Fprintf(w, "%-12s (none)\n", "Profile:")
+	case hasProfile(view, view.Profile):
+		fmt.Fprintf(w, "%-12s %s\n", "Profile:", view.Profile)
+	default:
+		fmt.Fprintf(w, "%-12s %s %s\n", "Profile:", view.Profile, display.ColorWarning("(not defined)"))
+	}
+
+	if len(view.Profiles) == 0 {
+		fmt.Fprintln(w, "\nNo profiles defined")
+		return nil
+	}
+
+	fmt.Fprintf(w, "\nProfiles:\n")
+	for _, name := range sortedKeys(view.Profiles) {
+		profile := view.Profiles[name]
+		fmt.Fprintf(w, "\n  %s\n", display.ColorHeader(name))
+		if profile.Owner != "" {
+			fmt.Fprintf(w, "    owner: %s\n", profile.Owner)
+		}
+		for _, commandPath := range sortedKeys(profile.Defaults) {
+			defaults, err := profile.FlagDefaults(commandPath)
+			if err != nil {
+				return err
+			}
+			// The profile-wide owner is shown above
+			if _, explicit := profile.Defaults[commandPath]["owner"]; !explicit {
+				delete(defaults, "owner")
+			}
+			flags := make([]string, 0, len(defaults))
+			for _, flag := range sortedKeys(defaults) {
+				flags = append(flags, fmt.Sprintf("--%s=%s", flag, defaults[flag]))
+			}
+			if len(flags) > 0 {
+				fmt.Fprintf(w, "    %s: %s\n", commandPath, strings.Join(flags, " "))
+			}
+		}
+	}
+	return nil
+}
+
+// hasProfile reports whether the view defines the named profile
+func hasProfile(view configView, name string) bool {
+	_, ok := view.Profiles[name]
+	return ok
+}
+
+// sortedKeys returns the keys of m in order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// doctorProbe gives config doctor access to the environment it checks
+type doctorProbe interface {
+	Token() (string, error)
+	WhoAmI(ctx context.Context, token string) (gh.TokenInfo, error)
+	ConfigPath() (string, error)
+	PingRegistry(ctx context.Context) error
+}
+
+// liveDoctorProbe checks the real environment, GitHub, and ghcr.io
+type liveDoctorProbe struct{}
+
+func (liveDoctorProbe) Token() (string, error) {
+	return gh.GetToken()
+}
+
+func (liveDoctorProbe) WhoAmI(ctx context.Context, token string) (gh.TokenInfo, error) {
+	client, err := gh.NewClientWithContext(ctx, token)
+	if err != nil {
+		return gh.TokenInfo{}, err
+	}
+	return client.WhoAmI(ctx)
+}
+
+func (liveDoctorProbe) ConfigPath() (string, error) {
+	return config.Path()
+}
+
+func (liveDoctorProbe) PingRegistry(ctx context.Context) error {
+	return discover.PingRegistry(ctx, registryHost)
+}
+
+// Outcomes of a doctor check
+const (
+	doctorOK   = "ok"
+	doctorWarn = "warn"
+	doctorFail = "fail"
+	doctorSkip = "skip"
+)
+
+// doctorCheck is the outcome of one config doctor check
+type doctorCheck struct {
+	Name   string
+	Status string
+	Detail string
+	Hint   string
+}
+
+// runDoctor runs the config doctor checks in order. Checks that depend on a
+// failed one are skipped.
+func runDoctor(ctx context.Context, probe doctorProbe, profileName string) []doctorCheck {
+	var checks []doctorCheck
+
+	// Token present
+	token, err := probe.Token()
+	if err != nil {
+		checks = append(checks,
+			doctorCheck{Name: "GitHub token", Status: doctorFail, Detail: err.Error(),
+				Hint: "Create a personal access token and export it: export GITHUB_TOKEN=<token>"},
+			doctorCheck{Name: "Token valid", Status: doctorSkip, Detail: "no token"},
+			doctorCheck{Name: "Token scopes", Status: doctorSkip, Detail: "no token"})
+	} else {
+		checks = append(checks, doctorCheck{Name: "GitHub token", Status: doctorOK, Detail: "GITHUB_TOKEN is set"})
+		checks = append(checks, checkToken(ctx, probe, token)...)
+	}
+
+	checks = append(checks, checkConfig(probe, profileName))
+
+	// Registry reachable
+	if err := probe.PingRegistry(ctx); err != nil {
+		checks = append(checks, doctorCheck{Name: "Registry", Status: doctorFail, Detail: err.Error(),
+			Hint: fmt.Sprintf("Check network access and proxy settings for https://%s", registryHost)})
+	} else {
+		checks = append(checks, doctorCheck{Name: "Registry", Status: doctorOK, Detail: fmt.Sprintf("%s is reachable", registryHost)})
+	}
+
+	return checks
+}
+
+// checkToken checks that the token is valid and has the scopes ghcrctl needs
+func checkToken(ctx context.Context, probe doctorProbe, token string) []doctorCheck {
+	info, err := probe.WhoAmI(ctx, token)
+	if err != nil {
+		return []doctorCheck{
+			{Name: "Token valid", Status: doctorFail, Detail: err.Error(),
+				Hint: "Check that the token is not expired or revoked, and create a new one if needed"},
+			{Name: "Token scopes", Status: doctorSkip, Detail: "token is not valid"},
+		}
+	}
+	valid := doctorCheck{Name: "Token valid", Status: doctorOK, Detail: fmt.Sprintf("authenticated as %s", info.Login)}
+
+	if !info.ScopesKnown {
+		return []doctorCheck{valid, {Name: "Token scopes", Status: doctorWarn,
+			Detail: "scopes of fine-grained and GitHub Actions tokens cannot be checked",
+			Hint:   "Make sure the token has read access to packages, and write access to tag or delete"}}
+	}
+	if !info.HasScope("read:packages") {
+		return []doctorCheck{valid, {Name: "Token scopes", Status: doctorFail,
+			Detail: "read:packages is missing",
+			Hint:   "Create a classic token with read:packages, plus write:packages and delete:packages to tag and delete"}}
+	}
+
+	var missing []string
+	for _, scope := range []string{"write:packages", "delete:packages"} {
+		if !info.HasScope(scope) {
+			missing = append(missing, scope)
+		}
+	}
+	if len(missing) > 0 {
+		return []doctorCheck{valid, {Name: "Token scopes", Status: doctorWarn,
+			Detail: fmt.Sprintf("missing %s; listing works, but tagging or deleting does not", joinList(missing)),
+			Hint:   "Add the missing scopes to the token if you want to tag or delete versions"}}
+	}
+	return []doctorCheck{valid, {Name: "Token scopes", Status: doctorOK, Detail: "read, write, and delete packages"}}
+}
+
+// checkConfig checks that the config file can be read and contains the
+// selected profile
+func checkConfig(probe doctorProbe, profileName string) doctorCheck {
+	path, err := probe.ConfigPath()
+	if err != nil {
+		return doctorCheck{Name: "Config file", Status: doctorFail, Detail: err.Error(),
+			Hint: fmt.Sprintf("Set %s to the path of the config file", config.PathEnv)}
+	}
+	if _, statErr := os.Stat(path); os.IsNotExist(statErr) && profileName == "" {
+		return doctorCheck{Name: "Config file", Status: doctorOK, Detail: fmt.Sprintf("%s does not exist (no profiles defined)", path)}
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		return doctorCheck{Name: "Config file", Status: doctorFail, Detail: err.Error(),
+			Hint: "Fix the JSON in the config file, or remove it"}
+	}
+	if profileName != "" {
+		if _, err := cfg.Profile(profileName); err != nil {
+			return doctorCheck{Name: "Config file", Status: doctorFail, Detail: err.Error(),
+				Hint: fmt.Sprintf("Define the profile in %s, or unset %s", path, profileEnv)}
+		}
+		return doctorCheck{Name: "Config file", Status: doctorOK, Detail: fmt.Sprintf("%s, profile %q", path, profileName)}
+	}
+	return doctorCheck{Name: "Config file", Status: doctorOK, Detail: fmt.Sprintf("%s, %d profile(s)", path, len(cfg.Profiles))}
+}
+
+// joinList joins items with commas and a final "and"
+func joinList(items []string) string {
+	switch len(items) {
+	case 0:
+		return ""
+	case 1:
+		return items[0]
+	}
+	result := items[0]
+	for _, item := range items[1 : len(items)-1] {
+		result += ", " + item
+	}
+	return result + " and " + items[len(items)-1]
+}
+
+// reportDoctor prints the checklist and fails if any check failed
+func reportDoctor(w io.Writer, checks []doctorCheck) error {
+	nameWidth := 0
+	for _, check := range checks {
+		nameWidth = max(nameWidth, len(check.Name))
+	}
+
+	failed := 0
+	for _, check := range checks {
+		var status string
+		switch check.Status {
+		case doctorOK:
+			status = display.ColorSuccess("[ok]  ")
+		case doctorWarn:
+			status = display.ColorWarning("[warn]")
+		case doctorFail:
+			status = display.ColorError("[fail]")
+			failed++
+		default:
+			status = "[skip]"
+		}
+		fmt.Fprintf(w, "%s %-*s  %s\n", status, nameWidth, check.Name, check.Detail)
+		if check.Hint != "" && check.Status != doctorOK {
+			fmt.Fprintf(w, "       %-*s  hint: %s\n", nameWidth, "", check.Hint)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d check(s) failed", failed)
+	}
+	fmt.Fprintf(w, "\n%s\n", display.ColorSuccess("All critical checks passed"))
+	return nil
+}
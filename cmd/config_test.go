@@ -0,0 +1,265 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mkoepf/ghcrctl/internal/config"
+	"github.com/mkoepf/ghcrctl/internal/display"
+	"github.com/mkoepf/ghcrctl/internal/gh"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDoctorProbe answers the config doctor checks with fixed results
+type fakeDoctorProbe struct {
+	token      string
+	tokenErr   error
+	info       gh.TokenInfo
+	whoAmIErr  error
+	configPath string
+	pingErr    error
+}
+
+func (p fakeDoctorProbe) Token() (string, error) { return p.token, p.tokenErr }
+
+func (p fakeDoctorProbe) WhoAmI(ctx context.Context, token string) (gh.TokenInfo, error) {
+	return p.info, p.whoAmIErr
+}
+
+func (p fakeDoctorProbe) ConfigPath() (string, error) { return p.configPath, nil }
+
+func (p fakeDoctorProbe) PingRegistry(ctx context.Context) error { return p.pingErr }
+
+// healthyDoctorProbe passes every check
+func healthyDoctorProbe(t *testing.T) fakeDoctorProbe {
+	return fakeDoctorProbe{
+		token:      "ghp_fake",
+		info:       gh.TokenInfo{Login: "mkoepf", Scopes: []string{"write:packages", "delete:packages"}, ScopesKnown: true},
+		configPath: filepath.Join(t.TempDir(), "config.json"),
+	}
+}
+
+// doctorStatuses maps check names to their status
+func doctorStatuses(checks []doctorCheck) map[string]string {
+	statuses := make(map[string]string, len(checks))
+	for _, check := range checks {
+		statuses[check.Name] = check.Status
+	}
+	return statuses
+}
+
+func TestRunDoctor(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		modify func(p *fakeDoctorProbe)
+		want   map[string]string
+	}{
+		{
+			name:   "all checks pass",
+			modify: func(p *fakeDoctorProbe) {},
+			want:   map[string]string{"GitHub token": doctorOK, "Token valid": doctorOK, "Token scopes": doctorOK, "Config file": doctorOK, "Registry": doctorOK},
+		},
+		{
+			name:   "no token skips the token checks",
+			modify: func(p *fakeDoctorProbe) { p.tokenErr = fmt.Errorf("GITHUB_TOKEN environment variable not set") },
+			want:   map[string]string{"GitHub token": doctorFail, "Token valid": doctorSkip, "Token scopes": doctorSkip, "Config file": doctorOK, "Registry": doctorOK},
+		},
+		{
+			name:   "invalid token",
+			modify: func(p *fakeDoctorProbe) { p.whoAmIErr = fmt.Errorf("401 Bad credentials") },
+			want:   map[string]string{"GitHub token": doctorOK, "Token valid": doctorFail, "Token scopes": doctorSkip, "Config file": doctorOK, "Registry": doctorOK},
+		},
+		{
+			name:   "read scope missing",
+			modify: func(p *fakeDoctorProbe) { p.info.Scopes = []string{"repo"} },
+			want:   map[string]string{"GitHub token": doctorOK, "Token valid": doctorOK, "Token scopes": doctorFail, "Config file": doctorOK, "Registry": doctorOK},
+		},
+		{
+			name:   "delete scope missing is a warning",
+			modify: func(p *fakeDoctorProbe) { p.info.Scopes = []string{"read:packages"} },
+			want:   map[string]string{"GitHub token": doctorOK, "Token valid": doctorOK, "Token scopes": doctorWarn, "Config file": doctorOK, "Registry": doctorOK},
+		},
+		{
+			name:   "unknown scopes are a warning",
+			modify: func(p *fakeDoctorProbe) { p.info = gh.TokenInfo{Login: "github-actions[bot]"} },
+			want:   map[string]string{"GitHub token": doctorOK, "Token valid": doctorOK, "Token scopes": doctorWarn, "Config file": doctorOK, "Registry": doctorOK},
+		},
+		{
+			name:   "registry unreachable",
+			modify: func(p *fakeDoctorProbe) { p.pingErr = fmt.Errorf("registry ghcr.io is not reachable: timeout") },
+			want:   map[string]string{"GitHub token": doctorOK, "Token valid": doctorOK, "Token scopes": doctorOK, "Config file": doctorOK, "Registry": doctorFail},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			probe := healthyDoctorProbe(t)
+			tt.modify(&probe)
+			assert.Equal(t, tt.want, doctorStatuses(runDoctor(context.Background(), probe, "")))
+		})
+	}
+}
+
+func TestRunDoctor_ScopeWarningNamesMissingScopes(t *testing.T) {
+	t.Parallel()
+	probe := healthyDoctorProbe(t)
+	probe.info.Scopes = []string{"read:packages"}
+
+	checks := runDoctor(context.Background(), probe, "")
+	assert.Equal(t, "missing write:packages and delete:packages; listing works, but tagging or deleting does not", checks[2].Detail)
+}
+
+func TestCheckConfig(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "valid.json")
+	require.NoError(t, os.WriteFile(valid, []byte(`{"profiles": {"cleanup": {"defaults": {}}}}`), 0o644))
+	invalid := filepath.Join(dir, "invalid.json")
+	require.NoError(t, os.WriteFile(invalid, []byte(`{"profiles":`), 0o644))
+	missing := filepath.Join(dir, "missing.json")
+
+	tests := []struct {
+		name       string
+		path       string
+		profile    string
+		wantStatus string
+		wantDetail string
+	}{
+		{name: "no config file", path: missing, wantStatus: doctorOK, wantDetail: "does not exist"},
+		{name: "valid config", path: valid, wantStatus: doctorOK, wantDetail: "1 profile(s)"},
+		{name: "selected profile exists", path: valid, profile: "cleanup", wantStatus: doctorOK, wantDetail: `profile "cleanup"`},
+		{name: "selected profile missing", path: valid, profile: "nightly", wantStatus: doctorFail, wantDetail: "nightly"},
+		{name: "profile without config file", path: missing, profile: "cleanup", wantStatus: doctorFail, wantDetail: "cleanup"},
+		{name: "invalid JSON", path: invalid, wantStatus: doctorFail, wantDetail: "failed to parse config"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			check := checkConfig(fakeDoctorProbe{configPath: tt.path}, tt.profile)
+			assert.Equal(t, tt.wantStatus, check.Status)
+			assert.Contains(t, check.Detail, tt.wantDetail)
+		})
+	}
+}
+
+func TestReportDoctor(t *testing.T) {
+	t.Parallel()
+
+	t.Run("failure is an error with hints", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		err := reportDoctor(&buf, []doctorCheck{
+			{Name: "GitHub token", Status: doctorFail, Detail: "GITHUB_TOKEN environment variable not set", Hint: "export GITHUB_TOKEN=<token>"},
+			{Name: "Token valid", Status: doctorSkip, Detail: "no token"},
+			{Name: "Registry", Status: doctorOK, Detail: "ghcr.io is reachable"},
+		})
+		assert.EqualError(t, err, "1 check(s) failed")
+		assert.Contains(t, buf.String(), "[fail] GitHub token  GITHUB_TOKEN environment variable not set")
+		assert.Contains(t, buf.String(), "hint: export GITHUB_TOKEN=<token>")
+		assert.Contains(t, buf.String(), "[skip] Token valid   no token")
+		assert.NotContains(t, buf.String(), "All critical checks passed")
+	})
+
+	t.Run("warnings pass", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		err := reportDoctor(&buf, []doctorCheck{
+			{Name: "Token scopes", Status: doctorWarn, Detail: "missing delete:packages", Hint: "Add the missing scopes"},
+		})
+		require.NoError(t, err)
+		assert.Contains(t, buf.String(), "[warn] Token scopes  missing delete:packages")
+		assert.Contains(t, buf.String(), "hint: Add the missing scopes")
+		assert.Contains(t, buf.String(), "All critical checks passed")
+	})
+}
+
+func TestJoinList(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "", joinList(nil))
+	assert.Equal(t, "a", joinList([]string{"a"}))
+	assert.Equal(t, "a and b", joinList([]string{"a", "b"}))
+	assert.Equal(t, "a, b and c", joinList([]string{"a", "b", "c"}))
+}
+
+// writeConfig writes a config file with a cleanup profile and returns its path
+func writeConfig(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"profiles": {
+		"cleanup": {"owner": "myorg", "defaults": {"delete version": {"untagged": true, "older-than": "30d"}}},
+		"ci": {"defaults": {"list versions": {"owner": "mkoepf", "type": ["index", "manifest"]}}}
+	}}`), 0o600))
+	return path
+}
+
+func TestConfigView_JSON(t *testing.T) {
+	t.Parallel()
+	path := writeConfig(t)
+	cfg, err := config.Load(path)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, display.OutputJSON(context.Background(), &buf, newConfigView(path, cfg, "cleanup")))
+
+	var view configView
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &view))
+	assert.Equal(t, path, view.Path)
+	assert.Equal(t, "ghcr.io", view.Registry)
+	assert.Equal(t, "cleanup", view.Profile)
+	assert.Equal(t, "myorg", view.Profiles["cleanup"].Owner)
+	assert.Equal(t, map[string]any{"untagged": true, "older-than": "30d"}, view.Profiles["cleanup"].Defaults["delete version"])
+
+	// The profiles read back from the JSON work like the config file's
+	defaults, err := view.Profiles["cleanup"].FlagDefaults("delete version")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"untagged": "true", "older-than": "30d", "owner": "myorg"}, defaults)
+}
+
+func TestConfigView_NoConfigFile(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "missing.json")
+	cfg, err := config.Load(path)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, display.OutputJSON(context.Background(), &buf, newConfigView(path, cfg, "")))
+	assert.JSONEq(t, fmt.Sprintf(`{"path": %q, "registry": "ghcr.io", "profiles": {}}`, path), buf.String())
+
+	buf.Reset()
+	require.NoError(t, outputConfigView(&buf, newConfigView(path, cfg, "")))
+	assert.Contains(t, buf.String(), "Profile:     (none)")
+	assert.Contains(t, buf.String(), "No profiles defined")
+}
+
+func TestOutputConfigView(t *testing.T) {
+	t.Parallel()
+	path := writeConfig(t)
+	cfg, err := config.Load(path)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, outputConfigView(&buf, newConfigView(path, cfg, "nightly")))
+	assert.Equal(t, "Config file: "+path+`
+Registry:    ghcr.io
+Profile:     nightly (not defined)
+
+Profiles:
+
+  ci
+    list versions: --owner=mkoepf --type=index,manifest
+
+  cleanup
+    owner: myorg
+    delete version: --older-than=30d --untagged=true
+`, buf.String())
+}
@@ -2,15 +2,24 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/mkoepf/ghcrctl/internal/audit"
 	"github.com/mkoepf/ghcrctl/internal/discover"
 	"github.com/mkoepf/ghcrctl/internal/display"
 	"github.com/mkoepf/ghcrctl/internal/filter"
 	"github.com/mkoepf/ghcrctl/internal/gh"
+	"github.com/mkoepf/ghcrctl/internal/metrics"
 	"github.com/mkoepf/ghcrctl/internal/prompts"
 	"github.com/spf13/cobra"
 )
@@ -44,14 +53,34 @@ func newDeleteVersionCmd() *cobra.Command {
 		force        bool
 		yes          bool
 		dryRun       bool
+		detailedExit bool
 		versionID    int64
 		digest       string
 		tag          string
 		tagPattern   string
+		tagPrefix    string
+		tagSuffix    string
 		onlyTagged   bool
 		onlyUntagged bool
 		olderThan    string
 		newerThan    string
+		minAge       string
+		maxAge       string
+		afterID      int64
+		beforeID     int64
+		batchSize    int
+		ifBlocked    bool
+		oldest       bool
+		newest       bool
+		maxDelete    int
+		excludeIDs   []int64
+		excludeDigs  []string
+		verify       bool
+		orphanAtts   bool
+		outputFormat string
+		metricsFile  string
+		auditLog     string
+		strictDigest bool
 	)
 
 	cmd := &cobra.Command{
@@ -63,9 +92,77 @@ This command can delete a single version by ID/digest/tag, or multiple versions
 By default, it will prompt for confirmation before deleting.
 
 IMPORTANT: Deletion is permanent and cannot be undone (except within 30 days
-via the GitHub web UI if the package namespace is available).
-
-Requires a selector: --version, --digest, --tag, or filter flags for bulk deletion.
+via the GitHub web UI if the package namespace is available; 'ghcrctl recover'
+shows where).
+
+Requires a selector: --version, --digest, --tag, --oldest, --newest, or filter
+flags for bulk deletion.
+
+A short --digest must match exactly one version. If it matches several, the
+command fails and lists the candidates; --strict-digest=false picks the newest
+match instead. A digest that GHCR lists under several version IDs, e.g. after a
+re-push, is deleted under all of them, with --digest as well as --tag.
+
+--oldest and --newest delete the single oldest or newest version (by creation
+date) that matches the filter flags, or of the whole package without filters.
+Versions created at the same time are ordered by version ID.
+
+--max-delete caps how many versions bulk deletion removes in one run, picking
+the oldest matching versions first. If more versions match, the output says so
+and the next run continues where this one stopped, which keeps the blast radius
+of a scheduled cleanup small.
+
+--exclude-version and --exclude-digest spare the given version IDs and digests
+in bulk deletion, even if they match the filters. Versions they reference are
+preserved as well, like other shared versions.
+
+For very large packages, --batch-size switches bulk deletion to a streaming mode
+that does not keep the listing in memory: versions are listed and filtered
+this many per page instead of discovering the whole package up front. Only
+versions that are kept are discovered, and everything they reference is
+protected, down to any depth. After the confirmation prompt, which shows the
+first versions to delete, the pages are listed again from the last to the
+first, and the matching versions of each page are deleted before the next
+page is read.
+
+With --delete-package-if-blocked, a deletion that GHCR stops at the last tagged
+version is completed by deleting the whole package, after an extra
+confirmation (skipped with --force). This only happens if no other versions are
+left in the package. It cannot be combined with --batch-size.
+
+With -o github-actions, the result is also written as step outputs to the file
+named by GITHUB_OUTPUT: deleted_count, failed_count, would_delete_count (dry
+runs), and digest when a single version is deleted by --digest or --tag.
+
+With --metrics-file, the result is written as Prometheus metrics for the
+textfile collector of node_exporter, labeled by owner and package:
+ghcrctl_versions_deleted_total, ghcrctl_versions_failed_total,
+ghcrctl_bytes_reclaimed and ghcrctl_run_duration_seconds. The bytes reclaimed
+are the sizes of the deleted manifests and artifacts as shown by list graphs;
+they are left out with --batch-size and for single versions, whose sizes are
+not discovered. The file is replaced at the end of every run that gets to
+deleting, including dry runs and runs that match nothing.
+
+With --audit-log, a JSON line is appended to the given file for every version
+that was deleted: timestamp, actor (the user of the token, or GITHUB_ACTOR),
+owner, package, version ID, digest, tags, and the selector, i.e. the command
+and its selecting flags. The file keeps growing across runs as an audit trail.
+
+With --verify, the package is listed again after deleting, and the command
+fails if any version reported as deleted is still listed. It cannot be
+combined with --batch-size.
+
+--orphan-attestations-only deletes only attestations and signatures (sbom,
+provenance, vex, vuln-scan, signature) whose subject is gone. A version is
+selected if discovery finds no parent for it, and its own manifest and in-toto
+statements confirm that none of the digests it is about is still in the
+package. Attestations whose subject cannot be read are kept. It combines with
+the other filter flags, e.g. --older-than.
+
+--min-age and --max-age take only durations, so the direction of a deletion
+cannot be mistaken: --min-age 30d deletes versions at least 30 days old (same
+as --older-than 30d), --max-age 7d deletes versions at most 7 days old (same
+as --newer-than 7d). A date given to them is an error.
 
 Examples:
   # Delete by version ID
@@ -83,31 +180,128 @@ Examples:
   # Delete untagged versions older than 30 days
   ghcrctl delete version mkoepf/myimage --untagged --older-than 30d
 
+  # Same, with the duration-only alias
+  ghcrctl delete version mkoepf/myimage --untagged --min-age 30d
+
+  # Delete the oldest untagged version
+  ghcrctl delete version mkoepf/myimage --untagged --oldest
+
   # Delete versions matching tag pattern older than a date
   ghcrctl delete version mkoepf/myimage --tag-pattern ".*-rc.*" --older-than 2025-01-01
 
+  # Delete pull request builds older than 14 days (literal prefix, no regex)
+  ghcrctl delete version mkoepf/myimage --tag-prefix pr- --older-than 14d
+
   # Preview what would be deleted (dry-run)
   ghcrctl delete version mkoepf/myimage --untagged --dry-run
 
+  # Delete the versions pushed between two known versions (both are kept)
+  ghcrctl delete version mkoepf/myimage --after-id 12345000 --before-id 12346000 --dry-run
+
+  # Delete old versions, but keep two known-good ones
+  ghcrctl delete version mkoepf/myimage --older-than 90d --exclude-version 12345678 --exclude-digest abc123
+
+  # Fail a CI job (exit code 2) if there is anything to clean up
+  ghcrctl delete version mkoepf/myimage --untagged --dry-run --detailed-exitcode
+
   # Skip confirmation for bulk deletion
-  ghcrctl delete version mkoepf/myimage --untagged --older-than 30d --force`,
+  ghcrctl delete version mkoepf/myimage --untagged --older-than 30d --force
+
+  # Nightly cleanup: delete at most 200 old untagged versions per run
+  ghcrctl delete version mkoepf/myimage --untagged --older-than 14d --max-delete 200 --force
+
+  # Stream deletion of a huge package in pages of 100 versions
+  ghcrctl delete version mkoepf/myimage --untagged --older-than 90d --batch-size 100
+
+  # Check that the deleted versions are really gone
+  ghcrctl delete version mkoepf/myimage --untagged --force --verify
+
+  # Delete attestations and signatures of images that were deleted
+  ghcrctl delete version mkoepf/myimage --orphan-attestations-only --dry-run
+
+  # Expose deleted_count and failed_count as GitHub Actions step outputs
+  ghcrctl delete version mkoepf/myimage --untagged --force -o github-actions
+
+  # Keep an audit trail of scheduled cleanups
+  ghcrctl delete version mkoepf/myimage --untagged --older-than 30d --force --audit-log deletions.ndjson`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// Parse owner/package reference (reject inline tags)
-			owner, packageName, err := parsePackageRef(args[0])
+			owner, packageName, err := parsePackageRef(args[0], defaultOwner(cmd))
 			if err != nil {
 				cmd.SilenceUsage = true
 				return err
 			}
 
+			if err := applyAgeFlags(minAge, maxAge, &olderThan, &newerThan); err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
+
 			// Check if any selector is provided
 			hasSingleSelector := versionID != 0 || digest != "" || tag != ""
 			hasFilterSelector := onlyTagged || onlyUntagged || tagPattern != "" ||
-				olderThan != "" || newerThan != ""
+				tagPrefix != "" || tagSuffix != "" || olderThan != "" || newerThan != "" ||
+				afterID != 0 || beforeID != 0 || orphanAtts
+
+			hasExtremeSelector := oldest || newest
+
+			if !hasSingleSelector && !hasFilterSelector && !hasExtremeSelector {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("selector required: use --version, --digest, --tag, --oldest, --newest, or filter flags (--untagged, --older-than, etc.)")
+			}
+
+			if orphanAtts && (hasSingleSelector || hasExtremeSelector) {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("--orphan-attestations-only only applies to bulk deletion")
+			}
 
-			if !hasSingleSelector && !hasFilterSelector {
+			if detailedExit && !dryRun {
 				cmd.SilenceUsage = true
-				return fmt.Errorf("selector required: use --version, --digest, --tag, or filter flags (--untagged, --older-than, etc.)")
+				return fmt.Errorf("--detailed-exitcode requires --dry-run")
+			}
+
+			// A profile's --max-delete and --batch-size are kept for the bulk
+			// deletions they are meant for and do not get in the way of others
+			if flagSet(cmd, "max-delete") {
+				if cmd.Flags().Changed("max-delete") && (hasSingleSelector || hasExtremeSelector || !hasFilterSelector) {
+					cmd.SilenceUsage = true
+					return fmt.Errorf("--max-delete only applies to bulk deletion with filter flags")
+				}
+				if maxDelete < 1 {
+					cmd.SilenceUsage = true
+					return fmt.Errorf("--max-delete must be at least 1, got %d", maxDelete)
+				}
+			}
+
+			if len(excludeIDs) > 0 || len(excludeDigs) > 0 {
+				if hasSingleSelector || hasExtremeSelector || !hasFilterSelector {
+					cmd.SilenceUsage = true
+					return fmt.Errorf("--exclude-version and --exclude-digest only apply to bulk deletion with filter flags")
+				}
+			}
+
+			streaming := flagSet(cmd, "batch-size")
+			if streaming && (hasSingleSelector || hasExtremeSelector) {
+				if cmd.Flags().Changed("batch-size") {
+					cmd.SilenceUsage = true
+					return fmt.Errorf("--batch-size only applies to bulk deletion with filter flags")
+				}
+				streaming = false
+			}
+			if streaming {
+				if err := validateBatchSize(batchSize); err != nil {
+					cmd.SilenceUsage = true
+					return err
+				}
+			}
+
+			if err := enableGitHubOutput(cmd, outputFormat); err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
+			if metricsFile != "" {
+				cmd.SetContext(metrics.WithFile(cmd.Context(), metricsFile, owner, packageName))
 			}
 
 			// Get GitHub token
@@ -123,6 +317,12 @@ Examples:
 				cmd.SilenceUsage = true
 				return fmt.Errorf("failed to create GitHub client: %w", err)
 			}
+			if auditLog != "" {
+				if err := enableAuditLog(cmd, client, auditLog, owner, packageName); err != nil {
+					cmd.SilenceUsage = true
+					return err
+				}
+			}
 
 			ctx := cmd.Context()
 
@@ -135,39 +335,109 @@ Examples:
 
 			// Route to appropriate handler
 			skipConfirm := force || yes
-			if hasFilterSelector && !hasSingleSelector {
+			fallback := newPackageFallback(cmd, client, packageName, ifBlocked, skipConfirm)
+			if streaming {
+				versionFilter, err := buildDeleteVersionFilter(tagPattern, tagPrefix, tagSuffix, onlyTagged, onlyUntagged, olderThan, newerThan, dateLocation(cmd), afterID, beforeID)
+				if err != nil {
+					cmd.SilenceUsage = true
+					return fmt.Errorf("invalid filter options: %w", err)
+				}
+
+				cmd.SilenceUsage = true
+				return executeStreamingDelete(ctx, client, client, discover.NewPackageDiscoverer(), streamDeleteParams{
+					Owner:            owner,
+					OwnerType:        ownerType,
+					PackageName:      packageName,
+					Filter:           versionFilter,
+					BatchSize:        batchSize,
+					Force:            skipConfirm,
+					DryRun:           dryRun,
+					DetailedExitCode: detailedExit,
+				}, cmd.OutOrStdout(), func(count int) (bool, error) {
+					return prompts.Confirm(os.Stdin, cmd.OutOrStdout(),
+						display.ColorWarning(fmt.Sprintf("Delete %d version(s) of %s?", count, packageName)))
+				})
+			}
+
+			if hasExtremeSelector {
+				// Pick the oldest or newest matching version, then delete it like --version
+				versionFilter, err := buildDeleteVersionFilter(tagPattern, tagPrefix, tagSuffix, onlyTagged, onlyUntagged, olderThan, newerThan, dateLocation(cmd), afterID, beforeID)
+				if err != nil {
+					cmd.SilenceUsage = true
+					return fmt.Errorf("invalid filter options: %w", err)
+				}
+				allVersions, err := client.ListPackageVersions(ctx, owner, ownerType, packageName)
+				if err != nil {
+					cmd.SilenceUsage = true
+					return fmt.Errorf("failed to list package versions: %w", err)
+				}
+				selected, err := selectExtremeVersion(versionFilter.Apply(allVersions), newest)
+				if err != nil {
+					cmd.SilenceUsage = true
+					return err
+				}
+				versionID = selected.ID
+			} else if hasFilterSelector && !hasSingleSelector {
 				// Bulk deletion mode
 				return runBulkDeleteVersion(ctx, cmd, client, owner, ownerType, packageName,
-					tagPattern, onlyTagged, onlyUntagged, olderThan, newerThan,
-					skipConfirm, dryRun)
+					tagPattern, tagPrefix, tagSuffix, onlyTagged, onlyUntagged, olderThan, newerThan, afterID, beforeID, maxDelete,
+					excludeIDs, excludeDigs, orphanAtts, skipConfirm, dryRun, detailedExit, verify, fallback)
 			}
 
 			// Single deletion mode
 			return runSingleDeleteVersion(ctx, cmd, client, owner, ownerType, packageName,
-				versionID, digest, tag, skipConfirm, dryRun)
+				versionID, digest, tag, strictDigest, skipConfirm, dryRun, detailedExit, verify, fallback)
 		},
 	}
 
 	// Single version selectors
 	cmd.Flags().Int64Var(&versionID, "version", 0, "Delete version by ID")
-	cmd.Flags().StringVar(&digest, "digest", "", "Delete version by digest")
+	cmd.Flags().StringVar(&digest, "digest", "", "Delete version by digest (full or short)")
+	cmd.Flags().BoolVar(&strictDigest, "strict-digest", true, "Fail if a short --digest matches more than one version (use --strict-digest=false to pick the newest)")
 	cmd.Flags().StringVar(&tag, "tag", "", "Delete version by tag")
 
 	// Filter flags for bulk deletion
 	cmd.Flags().StringVar(&tagPattern, "tag-pattern", "", "Delete versions matching regex pattern")
+	cmd.Flags().StringVar(&tagPrefix, "tag-prefix", "", "Delete versions with a tag starting with this literal prefix")
+	cmd.Flags().StringVar(&tagSuffix, "tag-suffix", "", "Delete versions with a tag ending with this literal suffix")
 	cmd.Flags().BoolVar(&onlyTagged, "tagged", false, "Delete only tagged versions")
 	cmd.Flags().BoolVar(&onlyUntagged, "untagged", false, "Delete only untagged versions")
 	cmd.Flags().StringVar(&olderThan, "older-than", "", "Delete versions older than date or duration (e.g., 2025-01-01, 7d, 24h)")
 	cmd.Flags().StringVar(&newerThan, "newer-than", "", "Delete versions newer than date or duration (e.g., 2025-01-01, 7d, 24h)")
+	addAgeFlags(cmd, &minAge, &maxAge)
+	cmd.Flags().Int64Var(&afterID, "after-id", 0, "Delete versions with an ID greater than this (exclusive)")
+	cmd.Flags().Int64Var(&beforeID, "before-id", 0, "Delete versions with an ID less than this (exclusive)")
+	cmd.Flags().BoolVar(&oldest, "oldest", false, "Delete only the oldest version matching the filters")
+	cmd.Flags().BoolVar(&newest, "newest", false, "Delete only the newest version matching the filters")
+	cmd.Flags().Int64SliceVar(&excludeIDs, "exclude-version", nil, "Never delete this version ID, even if it matches the filters (repeatable)")
+	cmd.Flags().StringSliceVar(&excludeDigs, "exclude-digest", nil, "Never delete the version with this digest (full or short), even if it matches the filters (repeatable)")
+	cmd.Flags().IntVar(&maxDelete, "max-delete", 0, "Delete at most this many versions per run, oldest first")
+	cmd.Flags().IntVar(&batchSize, "batch-size", maxBatchSize, "Stream bulk deletion page by page, listing, discovering and deleting this many versions at a time (max 100)")
 
 	// Common flags
 	cmd.Flags().BoolVar(&force, "force", false, "Skip confirmation prompt")
 	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Skip confirmation prompt (alias for --force)")
 	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be deleted without deleting")
+	cmd.Flags().BoolVar(&detailedExit, "detailed-exitcode", false, "With --dry-run, exit with code 2 if any version would be deleted")
+	cmd.Flags().BoolVar(&ifBlocked, "delete-package-if-blocked", false, "Delete the whole package if GHCR refuses to delete the last tagged version")
+	cmd.Flags().BoolVar(&verify, "verify", false, "List the package again after deleting and fail if a deleted version is still listed")
+	addOutputFlag(cmd, &outputFormat, display.OutputModeGitHubActions)
+	addMetricsFileFlag(cmd, &metricsFile)
+	addAuditLogFlag(cmd, &auditLog)
 
 	// Mark single selectors as mutually exclusive
-	cmd.MarkFlagsMutuallyExclusive("version", "digest", "tag")
+	cmd.MarkFlagsMutuallyExclusive("version", "digest", "tag", "oldest", "newest")
 	cmd.MarkFlagsMutuallyExclusive("tagged", "untagged")
+	cmd.MarkFlagsMutuallyExclusive("batch-size", "delete-package-if-blocked")
+	// Streaming deletes its whole plan and does not rank it by age
+	cmd.MarkFlagsMutuallyExclusive("batch-size", "max-delete")
+	cmd.MarkFlagsMutuallyExclusive("batch-size", "verify")
+	cmd.MarkFlagsMutuallyExclusive("batch-size", "exclude-version")
+	cmd.MarkFlagsMutuallyExclusive("batch-size", "exclude-digest")
+	cmd.MarkFlagsMutuallyExclusive("dry-run", "verify")
+	cmd.Flags().BoolVar(&orphanAtts, "orphan-attestations-only", false, "Delete only attestations and signatures whose subject is confirmed to be deleted")
+	// Streaming deletion never discovers the whole package, so it cannot tell whether a subject is gone
+	cmd.MarkFlagsMutuallyExclusive("batch-size", "orphan-attestations-only")
 
 	return cmd
 }
@@ -175,12 +445,28 @@ Examples:
 // newDeleteGraphCmd creates the delete graph subcommand with isolated flag state.
 func newDeleteGraphCmd() *cobra.Command {
 	var (
-		force     bool
-		yes       bool
-		dryRun    bool
-		tag       string
-		digest    string
-		versionID int64
+		force        bool
+		yes          bool
+		dryRun       bool
+		detailedExit bool
+		tag          string
+		digest       string
+		versionID    int64
+		allTags      bool
+		closedPRs    string
+		prPattern    string
+		strictDigest bool
+		ifBlocked    bool
+		confirmDig   bool
+		jsonOutput   bool
+		verify       bool
+		atomic       bool
+		planFile     string
+		applyPlan    string
+		outputFormat string
+		metricsFile  string
+		auditLog     string
+		strict       bool
 	)
 
 	cmd := &cobra.Command{
@@ -191,10 +477,80 @@ func newDeleteGraphCmd() *cobra.Command {
 This command discovers and deletes all versions that make up an OCI graph,
 including the root index, platform manifests, and attestations (SBOM, provenance).
 
-Requires a selector: --tag, --digest, or --version.
+Requires a selector: --tag, --digest, --version, --all-tags, or
+--closed-prs-file.
+
+With --all-tags, every tagged graph in the package is deleted. Versions shared
+with untagged graphs outside the deletion are preserved. Because GHCR refuses
+to delete the last tagged version of a package, the final deletion may fail;
+use 'ghcrctl delete package' to remove the package entirely in that case.
+
+With --closed-prs-file, the graphs tagged for closed pull requests are deleted
+like with --all-tags. The file lists pull request numbers separated by
+newlines, spaces, or commas (- reads them from stdin). --pr-pattern is the
+regex that extracts the number from a tag with one capture group; it defaults
+to ^pr-(\d+)$. Versions that also carry other tags, e.g. latest, are kept.
+
+If --digest or --version selects a platform manifest, signature, or
+attestation, the graph of the image it belongs to is deleted. A child shared
+by several images is refused; select one of the images instead.
+
+A short --digest must match exactly one version. If it matches several, the
+command fails and lists the candidates; --strict-digest=false picks the newest
+match instead.
+
+With --delete-package-if-blocked, a deletion that GHCR stops at the last tagged
+version is completed by deleting the whole package, after an extra
+confirmation (skipped with --force). This only happens if no other versions are
+left in the package.
+
+With --atomic, the command checks before deleting anything that GHCR will
+accept deleting the root: it fails without changes if the graph holds the last
+tagged version of the package, unless --delete-package-if-blocked would then
+delete the package. Without it, the children may already be deleted when GHCR
+refuses the root.
+
+With --plan-file, a dry run also writes its plan as JSON to the given file. The
+plan can be reviewed and later executed with --apply-plan, which deletes
+exactly the planned version IDs in the planned order. It refuses to delete
+anything if the plan is for another package, if a planned version no longer
+exists or has a different digest, or if a planned version is now referenced by
+a version outside the plan. It cannot be combined with --verify, --atomic,
+--confirm-digest, --delete-package-if-blocked, --detailed-exitcode, -o, or
+--metrics-file.
+
+With --confirm-digest, the yes/no prompt is replaced by asking you to type the
+short digest of the graph's root, so that a mistyped tag does not delete the
+wrong image.
+
+With --json, the plan is printed as JSON instead: the root digest, the tag, the
+ordered version_ids to delete, and the versions to delete and preserve. Without
+--dry-run, the outcome of each version ID is included, and --force is required
+since there is no prompt.
+
+With -o github-actions, the result is also written as step outputs to the file
+named by GITHUB_OUTPUT: digest (the root of the graph), deleted_count,
+failed_count, and would_delete_count (dry runs). It cannot be combined with
+--all-tags.
+
+With --metrics-file, the result is written as Prometheus metrics for the
+textfile collector of node_exporter, like with delete version. It also works
+with --all-tags and --closed-prs-file, but not with --apply-plan.
+
+With --audit-log, a JSON line is appended to the given file for every deleted
+version, like with delete version.
+
+With --strict, nothing is deleted if a manifest or index of the package cannot
+be parsed. Without it, such a version is treated as having no children, so
+the versions it references may be reported as shared or left behind.
+
+With --verify, the package is listed again after deleting, and the command
+fails if any version of the graph is still listed. It cannot be combined with
+--all-tags or --json.
 
 IMPORTANT: Deletion is permanent and cannot be undone (except within 30 days
-via the GitHub web UI if the package namespace is available).
+via the GitHub web UI if the package namespace is available; 'ghcrctl recover'
+shows where).
 
 Examples:
   # Delete graph by tag (most common)
@@ -210,20 +566,83 @@ Examples:
   ghcrctl delete graph mkoepf/myimage --tag v1.0.0 --force
 
   # Preview what would be deleted
-  ghcrctl delete graph mkoepf/myimage --tag v1.0.0 --dry-run`,
+  ghcrctl delete graph mkoepf/myimage --tag v1.0.0 --dry-run
+
+  # Preview deleting every tagged graph in the package
+  ghcrctl delete graph mkoepf/myimage --all-tags --dry-run
+
+  # Delete the pr-<number> images of closed pull requests
+  gh pr list --state closed --json number -q '.[].number' > closed.txt
+  ghcrctl delete graph mkoepf/myimage --closed-prs-file closed.txt --force
+
+  # Same for tags like pr-123-amd64
+  ghcrctl delete graph mkoepf/myimage --closed-prs-file closed.txt --pr-pattern '^pr-(\d+)-'
+
+  # Require typing the root digest to confirm
+  ghcrctl delete graph mkoepf/myimage --tag v1.0.0 --confirm-digest
+
+  # Print the plan as JSON
+  ghcrctl delete graph mkoepf/myimage --tag v1.0.0 --dry-run --json
+
+  # Save the plan for review, then delete exactly what it lists
+  ghcrctl delete graph mkoepf/myimage --tag v1.0.0 --dry-run --plan-file plan.json
+  ghcrctl delete graph mkoepf/myimage --apply-plan plan.json
+
+  # Exit with code 2 if the dry run would delete anything
+  ghcrctl delete graph mkoepf/myimage --tag v1.0.0 --dry-run --detailed-exitcode`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// Parse owner/package reference (reject inline tags)
-			owner, packageName, err := parsePackageRef(args[0])
+			owner, packageName, err := parsePackageRef(args[0], defaultOwner(cmd))
 			if err != nil {
 				cmd.SilenceUsage = true
 				return err
 			}
 
 			// Require at least one selector
-			if tag == "" && digest == "" && versionID == 0 {
+			if tag == "" && digest == "" && versionID == 0 && !allTags && closedPRs == "" && applyPlan == "" {
 				cmd.SilenceUsage = true
-				return fmt.Errorf("selector required: use --tag, --digest, or --version")
+				return fmt.Errorf("selector required: use --tag, --digest, --version, --all-tags, --closed-prs-file, or --apply-plan")
+			}
+
+			if planFile != "" && !dryRun {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("--plan-file requires --dry-run")
+			}
+
+			var prRegex *regexp.Regexp
+			if cmd.Flags().Changed("pr-pattern") && closedPRs == "" {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("--pr-pattern requires --closed-prs-file")
+			}
+			if closedPRs != "" {
+				prRegex, err = compilePRPattern(prPattern)
+				if err != nil {
+					cmd.SilenceUsage = true
+					return err
+				}
+				if closedPRs == "-" && !dryRun && !force && !yes {
+					cmd.SilenceUsage = true
+					return fmt.Errorf("--closed-prs-file - requires --force or --dry-run, since stdin cannot also answer the confirmation")
+				}
+			}
+
+			if detailedExit && !dryRun {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("--detailed-exitcode requires --dry-run")
+			}
+
+			if jsonOutput && !dryRun && !force && !yes {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("--json requires --dry-run or --force")
+			}
+
+			if err := enableGitHubOutput(cmd, outputFormat); err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
+			if metricsFile != "" {
+				cmd.SetContext(metrics.WithFile(cmd.Context(), metricsFile, owner, packageName))
 			}
 
 			// Get GitHub token
@@ -239,6 +658,12 @@ Examples:
 				cmd.SilenceUsage = true
 				return fmt.Errorf("failed to create GitHub client: %w", err)
 			}
+			if auditLog != "" {
+				if err := enableAuditLog(cmd, ghClient, auditLog, owner, packageName); err != nil {
+					cmd.SilenceUsage = true
+					return err
+				}
+			}
 
 			ctx := cmd.Context()
 
@@ -251,6 +676,89 @@ Examples:
 
 			ociRef := fmt.Sprintf("ghcr.io/%s/%s", owner, packageName)
 
+			if applyPlan != "" {
+				cmd.SilenceUsage = true
+				plan, err := readPlanFile(applyPlan)
+				if err != nil {
+					return err
+				}
+				return executeApplyGraphPlan(ctx, ghClient, discover.NewPackageDiscoverer(), plan, applyPlanParams{
+					Owner:       owner,
+					OwnerType:   ownerType,
+					PackageName: packageName,
+					Image:       ociRef,
+					Force:       force || yes,
+					DryRun:      dryRun,
+				}, cmd.OutOrStdout(), func() (bool, error) {
+					return prompts.Confirm(os.Stdin, cmd.OutOrStdout(), display.ColorWarning("Are you sure you want to delete the planned versions?"))
+				})
+			}
+
+			if allTags || closedPRs != "" {
+				var closed map[int]bool
+				if closedPRs != "" {
+					closed, err = loadClosedPRs(closedPRs, cmd.InOrStdin())
+					if err != nil {
+						cmd.SilenceUsage = true
+						return err
+					}
+				}
+
+				allVersions, err := ghClient.ListPackageVersions(ctx, owner, ownerType, packageName)
+				if err != nil {
+					cmd.SilenceUsage = true
+					return fmt.Errorf("failed to list package versions: %w", err)
+				}
+
+				var allTagNames []string
+				for _, v := range allVersions {
+					allTagNames = append(allTagNames, v.Tags...)
+				}
+
+				discoverer := discover.NewPackageDiscoverer()
+				discoverer.Strict = strict
+				versions, err := discoverer.DiscoverPackage(ctx, ociRef, allVersions, allTagNames)
+				if err != nil {
+					cmd.SilenceUsage = true
+					return fmt.Errorf("failed to discover package: %w", err)
+				}
+				printDiscoveryWarnings(ctx, cmd.ErrOrStderr(), versions)
+
+				cmd.SilenceUsage = true
+				var tagged, toDelete, shared []discover.VersionInfo
+				scope, prompt := "", "Are you sure you want to delete ALL tagged graphs?"
+				if closedPRs != "" {
+					var kept []discover.VersionInfo
+					tagged, toDelete, shared, kept = planDeleteClosedPRs(versions, prRegex, closed)
+					scope, prompt = "graphs of closed pull requests", "Are you sure you want to delete the graphs of closed pull requests?"
+					if len(kept) > 0 {
+						fmt.Fprintf(cmd.OutOrStdout(), "%s %d version(s) of closed pull requests also carry other tags and will be kept:\n",
+							display.ColorWarning("Note:"), len(kept))
+						for _, v := range kept {
+							fmt.Fprintf(cmd.OutOrStdout(), "  - version %d%s\n", v.ID, formatVersionTags(v.Tags))
+						}
+						fmt.Fprintln(cmd.OutOrStdout())
+					}
+				} else {
+					tagged, toDelete, shared = planDeleteAllTags(versions)
+				}
+				return executeDeleteAllTags(ctx, ghClient, deleteAllTagsParams{
+					Owner:            owner,
+					OwnerType:        ownerType,
+					PackageName:      packageName,
+					Scope:            scope,
+					Tagged:           tagged,
+					ToDelete:         toDelete,
+					Shared:           shared,
+					Force:            force || yes,
+					DryRun:           dryRun,
+					DetailedExitCode: detailedExit,
+					Fallback:         newPackageFallback(cmd, ghClient, packageName, ifBlocked, force || yes),
+				}, cmd.OutOrStdout(), func() (bool, error) {
+					return prompts.Confirm(os.Stdin, cmd.OutOrStdout(), display.ColorWarning(prompt))
+				})
+			}
+
 			// Determine the root digest based on which selector was used
 			var rootDigest string
 
@@ -276,18 +784,10 @@ Examples:
 						return fmt.Errorf("failed to list package versions: %w", err)
 					}
 
-					var found bool
-					for _, ver := range allVersions {
-						if strings.HasPrefix(ver.Digest, digestInput) {
-							rootDigest = ver.Digest
-							found = true
-							break
-						}
-					}
-
-					if !found {
+					rootDigest, err = resolveDigestPrefix(allVersions, digest, strictDigest, cmd.ErrOrStderr())
+					if err != nil {
 						cmd.SilenceUsage = true
-						return fmt.Errorf("no version found matching digest prefix %s", digest)
+						return err
 					}
 				} else {
 					rootDigest = digestInput
@@ -323,14 +823,23 @@ Examples:
 			}
 
 			discoverer := discover.NewPackageDiscoverer()
+			discoverer.Strict = strict
 			versions, err := discoverer.DiscoverPackage(ctx, ociRef, allVersions, nil)
 			if err != nil {
 				cmd.SilenceUsage = true
 				return fmt.Errorf("failed to discover package: %w", err)
 			}
+			printDiscoveryWarnings(ctx, cmd.ErrOrStderr(), versions)
 
 			versionMap := discover.ToMap(versions)
 
+			// A child digest selects the whole image it belongs to
+			rootDigest, err = resolveGraphRoot(versionMap, rootDigest, cmd.ErrOrStderr())
+			if err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
+
 			// Find graph by root digest
 			graphVersions := discover.FindGraphByDigest(versionMap, rootDigest)
 			if len(graphVersions) == 0 {
@@ -341,10 +850,62 @@ Examples:
 			// Classify versions into exclusive (to delete) and shared (to preserve)
 			toDelete, shared := discover.ClassifyGraphVersions(graphVersions)
 
-			// Extract version IDs from toDelete list
-			versionIDs := make([]int64, len(toDelete))
-			for i, v := range toDelete {
-				versionIDs[i] = v.ID
+			// Extract version IDs from toDelete list, including duplicates of a digest
+			var versionIDs []int64
+			for _, v := range toDelete {
+				versionIDs = append(versionIDs, v.VersionIDs()...)
+			}
+
+			if atomic {
+				if err := checkGraphDeletable(allVersions, versionIDs, ifBlocked); err != nil {
+					cmd.SilenceUsage = true
+					return err
+				}
+			}
+
+			plan := newGraphDeletePlan(packageName, tag, rootDigest, toDelete, shared, graphVersions)
+			plan.Owner = owner
+			plan.DryRun = dryRun
+			if planFile != "" {
+				if err := writePlanFile(planFile, plan); err != nil {
+					cmd.SilenceUsage = true
+					return err
+				}
+			}
+
+			if jsonOutput {
+				cmd.SilenceUsage = true
+				if dryRun {
+					if err := display.OutputJSON(ctx, cmd.OutOrStdout(), plan); err != nil {
+						return err
+					}
+					if err := (deleteOutputs{WouldDelete: len(versionIDs), Digest: rootDigest}).write(ctx); err != nil {
+						return err
+					}
+					return dryRunResult(detailedExit, len(versionIDs))
+				}
+				var deleteErr error
+				deleter := auditedDeleter{ghClient, auditVersions(toDelete)}
+				plan.Results, deleteErr = deleteGraphVersionsWithResults(ctx, deleter, owner, ownerType, packageName, versionIDs)
+				if err := display.OutputJSON(ctx, cmd.OutOrStdout(), plan); err != nil {
+					return err
+				}
+				outputs := deleteOutputs{Digest: rootDigest, SizeKnown: true}
+				var deletedIDs []int64
+				for _, result := range plan.Results {
+					switch result.Status {
+					case "deleted":
+						outputs.Deleted++
+						deletedIDs = append(deletedIDs, result.ID)
+					case "failed":
+						outputs.Failed++
+					}
+				}
+				outputs.Reclaimed = reclaimedBytes(toDelete, deletedIDs)
+				if err := outputs.write(ctx); err != nil {
+					return err
+				}
+				return deleteErr
 			}
 
 			// Display what will be deleted
@@ -361,32 +922,72 @@ Examples:
 			// Handle dry-run
 			if dryRun {
 				fmt.Fprintln(cmd.OutOrStdout(), display.ColorDryRun("DRY RUN: No changes made"))
-				return nil
+				if planFile != "" {
+					fmt.Fprintf(cmd.OutOrStdout(), "Plan written to %s; apply it with --apply-plan %s\n", planFile, planFile)
+				}
+				cmd.SilenceUsage = true
+				if err := (deleteOutputs{WouldDelete: len(versionIDs), Digest: rootDigest}).write(ctx); err != nil {
+					return err
+				}
+				return dryRunResult(detailedExit, len(versionIDs))
 			}
 
 			// Confirm deletion unless --force or --yes is used
 			skipConfirm := force || yes
 			if !skipConfirm {
-				confirmed, err := prompts.Confirm(os.Stdin, cmd.OutOrStdout(), display.ColorWarning("Are you sure you want to delete this graph?"))
+				confirmed, err := confirmGraphDeletion(os.Stdin, cmd.OutOrStdout(), rootDigest, confirmDig)
 				if err != nil {
 					return fmt.Errorf("failed to read confirmation: %w", err)
 				}
 
 				if !confirmed {
-					fmt.Fprintln(cmd.OutOrStdout(), "Deletion cancelled")
+					if confirmDig {
+						fmt.Fprintln(cmd.OutOrStdout(), "Deletion cancelled (input did not match digest)")
+					} else {
+						fmt.Fprintln(cmd.OutOrStdout(), "Deletion cancelled")
+					}
 					return nil
 				}
 			}
 
 			// Perform deletions (children first, then root)
-			err = deleteVersionsInOrder(ctx, ghClient, owner, ownerType, packageName, versionIDs, cmd.OutOrStdout())
+			deleter := auditedDeleter{ghClient, auditVersions(toDelete)}
+			deletedCount, err := deleteVersionsInOrder(ctx, deleter, owner, ownerType, packageName, versionIDs, cmd.OutOrStdout())
+			outputs := deleteOutputs{
+				Deleted:   deletedCount,
+				Digest:    rootDigest,
+				Reclaimed: reclaimedBytes(toDelete, versionIDs[:deletedCount]),
+				SizeKnown: true,
+			}
 			if err != nil {
+				outputs.Failed = 1
+			}
+			if outputErr := outputs.write(ctx); outputErr != nil {
 				cmd.SilenceUsage = true
+				return outputErr
+			}
+			if err != nil {
+				cmd.SilenceUsage = true
+				if gh.IsLastTaggedVersionError(err) {
+					fallback := newPackageFallback(cmd, ghClient, packageName, ifBlocked, skipConfirm)
+					deleted, fallbackErr := deletePackageIfBlocked(ctx, fallback, owner, ownerType, packageName, versionIDs, cmd.OutOrStdout())
+					if fallbackErr != nil {
+						return fallbackErr
+					}
+					if deleted {
+						return nil
+					}
+				}
 				return err
 			}
 
 			fmt.Fprintf(cmd.OutOrStdout(), "\n%s\n",
 				display.ColorSuccess(fmt.Sprintf("Successfully deleted %d version(s) of %s", len(versionIDs), packageName)))
+			printRestoreHint(cmd.OutOrStdout(), owner, ownerType, packageName)
+			if verify {
+				cmd.SilenceUsage = true
+				return verifyDeleted(ctx, ghClient, owner, ownerType, packageName, versionIDs, cmd.OutOrStdout())
+			}
 			return nil
 		},
 	}
@@ -395,13 +996,60 @@ Examples:
 	cmd.Flags().StringVar(&tag, "tag", "", "Delete graph by tag")
 	cmd.Flags().StringVar(&digest, "digest", "", "Delete graph by digest")
 	cmd.Flags().Int64Var(&versionID, "version", 0, "Delete graph containing this version ID")
+	cmd.Flags().BoolVar(&allTags, "all-tags", false, "Delete every tagged graph in the package")
+	cmd.Flags().StringVar(&closedPRs, "closed-prs-file", "", "Delete the graphs tagged for the closed pull requests listed in this file (- for stdin)")
+	cmd.Flags().StringVar(&prPattern, "pr-pattern", defaultPRPattern, "With --closed-prs-file, regex whose capture group extracts the pull request number from a tag")
+	cmd.Flags().BoolVar(&strictDigest, "strict-digest", true, "Fail if a short --digest matches more than one version (use --strict-digest=false to pick the newest)")
 
 	// Common flags
 	cmd.Flags().BoolVar(&force, "force", false, "Skip confirmation prompt")
 	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Skip confirmation prompt (alias for --force)")
 	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be deleted without deleting")
-
-	cmd.MarkFlagsMutuallyExclusive("tag", "digest", "version")
+	cmd.Flags().BoolVar(&detailedExit, "detailed-exitcode", false, "With --dry-run, exit with code 2 if any version would be deleted")
+	cmd.Flags().BoolVar(&ifBlocked, "delete-package-if-blocked", false, "Delete the whole package if GHCR refuses to delete the last tagged version")
+	cmd.Flags().BoolVar(&confirmDig, "confirm-digest", false, "Confirm by typing the short digest of the graph instead of y/N")
+
+	cmd.MarkFlagsMutuallyExclusive("tag", "digest", "version", "all-tags", "closed-prs-file")
+	cmd.MarkFlagsMutuallyExclusive("confirm-digest", "force")
+	cmd.MarkFlagsMutuallyExclusive("confirm-digest", "yes")
+	cmd.MarkFlagsMutuallyExclusive("confirm-digest", "all-tags")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output the deletion plan (and with --force, the outcome per version) as JSON")
+	cmd.MarkFlagsMutuallyExclusive("json", "all-tags")
+	cmd.MarkFlagsMutuallyExclusive("json", "confirm-digest")
+	cmd.MarkFlagsMutuallyExclusive("json", "delete-package-if-blocked")
+	addOutputFlag(cmd, &outputFormat, display.OutputModeGitHubActions)
+	cmd.MarkFlagsMutuallyExclusive("output", "all-tags")
+	cmd.Flags().BoolVar(&verify, "verify", false, "List the package again after deleting and fail if a deleted version is still listed")
+	cmd.MarkFlagsMutuallyExclusive("verify", "all-tags")
+	cmd.MarkFlagsMutuallyExclusive("verify", "json")
+	cmd.MarkFlagsMutuallyExclusive("verify", "dry-run")
+	cmd.MarkFlagsMutuallyExclusive("closed-prs-file", "confirm-digest")
+	cmd.MarkFlagsMutuallyExclusive("closed-prs-file", "json")
+	cmd.MarkFlagsMutuallyExclusive("closed-prs-file", "output")
+	cmd.MarkFlagsMutuallyExclusive("closed-prs-file", "verify")
+	cmd.Flags().BoolVar(&atomic, "atomic", false, "Check that the root can be deleted before deleting any version of the graph")
+	cmd.MarkFlagsMutuallyExclusive("atomic", "all-tags")
+	cmd.MarkFlagsMutuallyExclusive("atomic", "closed-prs-file")
+	cmd.Flags().StringVar(&planFile, "plan-file", "", "With --dry-run, write the deletion plan as JSON to this file")
+	cmd.Flags().StringVar(&applyPlan, "apply-plan", "", "Delete exactly the versions of a plan written with --plan-file")
+	cmd.MarkFlagsMutuallyExclusive("apply-plan", "tag", "digest", "version", "all-tags", "closed-prs-file")
+	cmd.MarkFlagsMutuallyExclusive("apply-plan", "plan-file")
+	cmd.MarkFlagsMutuallyExclusive("apply-plan", "json")
+	// A plan is applied as written, without the checks, prompts and outputs of
+	// a graph deletion
+	cmd.MarkFlagsMutuallyExclusive("apply-plan", "verify")
+	cmd.MarkFlagsMutuallyExclusive("apply-plan", "atomic")
+	cmd.MarkFlagsMutuallyExclusive("apply-plan", "confirm-digest")
+	cmd.MarkFlagsMutuallyExclusive("apply-plan", "delete-package-if-blocked")
+	cmd.MarkFlagsMutuallyExclusive("apply-plan", "detailed-exitcode")
+	cmd.MarkFlagsMutuallyExclusive("apply-plan", "output")
+	cmd.MarkFlagsMutuallyExclusive("plan-file", "all-tags")
+	cmd.MarkFlagsMutuallyExclusive("plan-file", "closed-prs-file")
+	addMetricsFileFlag(cmd, &metricsFile)
+	cmd.MarkFlagsMutuallyExclusive("metrics-file", "apply-plan")
+	addAuditLogFlag(cmd, &auditLog)
+	cmd.Flags().BoolVar(&strict, "strict", false, "Delete nothing if a manifest or index cannot be parsed")
+	cmd.MarkFlagsMutuallyExclusive("strict", "apply-plan")
 
 	return cmd
 }
@@ -409,8 +1057,11 @@ Examples:
 // newDeletePackageCmd creates the delete package subcommand with isolated flag state.
 func newDeletePackageCmd() *cobra.Command {
 	var (
-		force bool
-		yes   bool
+		force            bool
+		yes              bool
+		archivePath      string
+		archiveManifests bool
+		auditLog         string
 	)
 
 	cmd := &cobra.Command{
@@ -422,23 +1073,41 @@ This command deletes the package and ALL its versions permanently.
 Use this when you cannot delete the last tagged version of a package.
 
 IMPORTANT: Deletion is permanent and cannot be undone (except within 30 days
-via the GitHub web UI if the package namespace is available).
+via the GitHub web UI if the package namespace is available; 'ghcrctl recover'
+shows where).
+
+Use --archive to write a JSON record of all versions (IDs, digests, tags,
+creation dates) before deleting. With --archive-manifests, the manifest of
+each root version (index or standalone image) is fetched and included, which
+helps to re-push the images from backups. The package is not deleted if the
+archive cannot be written.
+
+With --audit-log, a JSON line is appended to the given file for every version
+of the deleted package, like with delete version.
 
 Examples:
   # Delete a package
   ghcrctl delete package mkoepf/myimage
 
   # Delete without confirmation
-  ghcrctl delete package mkoepf/myimage --force`,
+  ghcrctl delete package mkoepf/myimage --force
+
+  # Keep a record of all versions and root manifests before deleting
+  ghcrctl delete package mkoepf/myimage --archive myimage-archive.json --archive-manifests`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// Parse owner/package reference
-			owner, packageName, err := parsePackageRef(args[0])
+			owner, packageName, err := parsePackageRef(args[0], defaultOwner(cmd))
 			if err != nil {
 				cmd.SilenceUsage = true
 				return err
 			}
 
+			if archiveManifests && archivePath == "" {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("--archive-manifests requires --archive")
+			}
+
 			// Get GitHub token
 			token, err := gh.GetToken()
 			if err != nil {
@@ -452,6 +1121,12 @@ Examples:
 				cmd.SilenceUsage = true
 				return fmt.Errorf("failed to create GitHub client: %w", err)
 			}
+			if auditLog != "" {
+				if err := enableAuditLog(cmd, client, auditLog, owner, packageName); err != nil {
+					cmd.SilenceUsage = true
+					return err
+				}
+			}
 
 			ctx := cmd.Context()
 
@@ -462,114 +1137,394 @@ Examples:
 				return fmt.Errorf("failed to determine owner type: %w", err)
 			}
 
-			// Fetch version count to show the user what they're deleting
+			// Fetch versions to show the user what they're deleting
 			versions, err := client.ListPackageVersions(ctx, owner, ownerType, packageName)
 			if err != nil {
 				cmd.SilenceUsage = true
 				return fmt.Errorf("failed to list package versions: %w", err)
 			}
 
-			// Count tagged vs untagged
-			taggedCount := 0
-			for _, v := range versions {
-				if len(v.Tags) > 0 {
-					taggedCount++
-				}
-			}
-			untaggedCount := len(versions) - taggedCount
-
-			// Show what will be deleted
-			fmt.Fprintf(cmd.OutOrStdout(), "Preparing to delete entire package:\n")
-			fmt.Fprintf(cmd.OutOrStdout(), "  Package:  %s/%s\n", owner, packageName)
-			fmt.Fprintf(cmd.OutOrStdout(), "  Owner:    %s (%s)\n", owner, ownerType)
-			fmt.Fprintf(cmd.OutOrStdout(), "  Versions: %s (%d tagged, %d untagged)\n\n",
-				display.ColorWarning(fmt.Sprintf("%d total", len(versions))), taggedCount, untaggedCount)
-			fmt.Fprintf(cmd.OutOrStdout(), "%s\n\n", display.ColorError("WARNING: This will permanently delete ALL versions of this package!"))
-
-			// Confirm deletion unless --force or --yes is used
-			skipConfirm := force || yes
-			if !skipConfirm {
-				confirmed, err := prompts.ConfirmWithInput(os.Stdin, cmd.OutOrStdout(),
-					"To confirm, type the package name", packageName)
+			// Fetch root manifests up front so a failure aborts before anything is deleted
+			var manifests map[string]json.RawMessage
+			if archiveManifests {
+				manifests, err = fetchRootManifests(ctx, fmt.Sprintf("ghcr.io/%s/%s", owner, packageName), versions)
 				if err != nil {
-					return fmt.Errorf("failed to read confirmation: %w", err)
-				}
-
-				if !confirmed {
-					fmt.Fprintln(cmd.OutOrStdout(), "Deletion cancelled (input did not match package name)")
-					return nil
+					cmd.SilenceUsage = true
+					return err
 				}
 			}
 
-			// Perform deletion
-			err = client.DeletePackage(ctx, owner, ownerType, packageName)
-			if err != nil {
-				cmd.SilenceUsage = true
-				return fmt.Errorf("failed to delete package: %w", err)
-			}
-
-			fmt.Fprintln(cmd.OutOrStdout(), display.ColorSuccess(fmt.Sprintf("Successfully deleted package %s/%s", owner, packageName)))
-			return nil
+			cmd.SilenceUsage = true
+			return executeDeletePackage(ctx, client, deletePackageParams{
+				Owner:       owner,
+				OwnerType:   ownerType,
+				PackageName: packageName,
+				Versions:    versions,
+				Force:       force || yes,
+				ArchivePath: archivePath,
+				Manifests:   manifests,
+			}, cmd.OutOrStdout(), func() (bool, error) {
+				return prompts.ConfirmWithInput(os.Stdin, cmd.OutOrStdout(),
+					"To confirm, type the package name", packageName)
+			})
 		},
 	}
 
 	// Common flags
 	cmd.Flags().BoolVar(&force, "force", false, "Skip confirmation prompt")
 	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Skip confirmation prompt (alias for --force)")
+	cmd.Flags().StringVar(&archivePath, "archive", "", "Write a JSON record of all versions to this file before deleting")
+	cmd.Flags().BoolVar(&archiveManifests, "archive-manifests", false, "Include the manifest of each root version in the archive (requires --archive)")
+	addAuditLogFlag(cmd, &auditLog)
 
 	return cmd
 }
 
-// runSingleDeleteVersion handles deletion of a single version
-func runSingleDeleteVersion(ctx context.Context, cmd *cobra.Command, client *gh.Client, owner, ownerType, packageName string,
-	versionID int64, digest, tag string, force, dryRun bool) error {
+// confirmGraphDeletion asks before deleting the graph rooted at rootDigest. With
+// typeDigest the user must type the short root digest, like delete package asks
+// for the package name; otherwise a yes/no prompt is shown.
+func confirmGraphDeletion(r io.Reader, w io.Writer, rootDigest string, typeDigest bool) (bool, error) {
+	if typeDigest {
+		return prompts.ConfirmWithInput(r, w, "To confirm, type the digest", display.ShortDigest(rootDigest))
+	}
+	return prompts.Confirm(r, w, display.ColorWarning("Are you sure you want to delete this graph?"))
+}
+
+// packageRemover is an interface for deleting an entire package
+type packageRemover interface {
+	DeletePackage(ctx context.Context, owner, ownerType, packageName string) error
+}
+
+// blockedPackageClient lists what is left of a package and deletes the package.
+// *gh.Client satisfies this interface.
+type blockedPackageClient interface {
+	versionLister
+	packageRemover
+}
 
-	var targetVersionID int64
-	var err error
+// packageFallback enables --delete-package-if-blocked: when GHCR refuses to
+// delete the last tagged version, the whole package is deleted instead.
+// A nil *packageFallback disables the fallback.
+type packageFallback struct {
+	Client  blockedPackageClient
+	Force   bool                 // Skip the extra confirmation
+	Confirm func() (bool, error) // Extra confirmation before deleting the package
+}
 
-	if versionID != 0 {
-		targetVersionID = versionID
-	} else if digest != "" {
-		// Normalize digest format
-		if !strings.HasPrefix(digest, "sha256:") {
-			digest = "sha256:" + digest
+// newPackageFallback returns the fallback for --delete-package-if-blocked, or
+// nil if the flag is not set. The extra confirmation asks for the package name
+// like delete package does.
+func newPackageFallback(cmd *cobra.Command, client blockedPackageClient, packageName string, enabled, force bool) *packageFallback {
+	if !enabled {
+		return nil
+	}
+	return &packageFallback{
+		Client: client,
+		Force:  force,
+		Confirm: func() (bool, error) {
+			return prompts.ConfirmWithInput(os.Stdin, cmd.OutOrStdout(),
+				"To delete the whole package instead, type the package name", packageName)
+		},
+	}
+}
+
+// deletePackageIfBlocked deletes the whole package after GHCR refused to delete
+// its last tagged version. It only does so if every version left in the package
+// is in planned, i.e. was going to be deleted anyway, so that nothing else is
+// lost. It reports whether the package was deleted.
+func deletePackageIfBlocked(ctx context.Context, fallback *packageFallback, owner, ownerType, packageName string, planned []int64, w io.Writer) (bool, error) {
+	if fallback == nil {
+		return false, nil
+	}
+
+	remaining, err := fallback.Client.ListPackageVersions(ctx, owner, ownerType, packageName)
+	if err != nil {
+		return false, fmt.Errorf("failed to list remaining versions: %w", err)
+	}
+	plannedIDs := make(map[int64]bool, len(planned))
+	for _, id := range planned {
+		plannedIDs[id] = true
+	}
+	others := 0
+	for _, v := range remaining {
+		if !plannedIDs[v.ID] {
+			others++
 		}
-		// Look up version ID by digest
-		targetVersionID, err = client.GetVersionIDByDigest(ctx, owner, ownerType, packageName, digest)
+	}
+	if others > 0 {
+		fmt.Fprintf(w, "\nNot deleting the package: %d other version(s) would be lost.\n", others)
+		return false, nil
+	}
+
+	fmt.Fprintf(w, "\n%s\n", display.ColorWarning(fmt.Sprintf(
+		"Only the last tagged version blocks the deletion. Deleting package %s/%s removes the remaining %d version(s).",
+		owner, packageName, len(remaining))))
+	if !fallback.Force {
+		confirmed, err := fallback.Confirm()
 		if err != nil {
-			cmd.SilenceUsage = true
-			return fmt.Errorf("failed to find version by digest: %w", err)
+			return false, fmt.Errorf("failed to read confirmation: %w", err)
 		}
-	} else if tag != "" {
-		// Resolve tag to digest first, then get version ID
-		ociRef := fmt.Sprintf("ghcr.io/%s/%s", owner, packageName)
-		resolvedDigest, err := discover.ResolveTag(ctx, ociRef, tag)
+		if !confirmed {
+			fmt.Fprintln(w, "Package deletion cancelled")
+			return false, nil
+		}
+	}
+
+	if err := fallback.Client.DeletePackage(ctx, owner, ownerType, packageName); err != nil {
+		return false, fmt.Errorf("failed to delete package: %w", err)
+	}
+	if err := recordPackageDeleted(ctx, owner, packageName, remaining); err != nil {
+		return true, err
+	}
+	fmt.Fprintln(w, display.ColorSuccess(fmt.Sprintf("Successfully deleted package %s/%s", owner, packageName)))
+	printPackageRestoreHint(w, owner, ownerType)
+	return true, nil
+}
+
+// checkGraphDeletable is the --atomic pre-check of delete graph. GHCR refuses
+// to delete the last tagged version of a package, so deleting the versions in
+// planned would stop at the root if they hold every tagged version of
+// allVersions. That is only acceptable if ifBlocked is set and no other
+// versions are left, so that deletePackageIfBlocked removes the package.
+func checkGraphDeletable(allVersions []gh.PackageVersionInfo, planned []int64, ifBlocked bool) error {
+	plannedIDs := make(map[int64]bool, len(planned))
+	for _, id := range planned {
+		plannedIDs[id] = true
+	}
+
+	plansTagged := false
+	taggedLeft, othersLeft := 0, 0
+	for _, v := range allVersions {
+		if plannedIDs[v.ID] {
+			plansTagged = plansTagged || len(v.Tags) > 0
+			continue
+		}
+		othersLeft++
+		if len(v.Tags) > 0 {
+			taggedLeft++
+		}
+	}
+
+	if !plansTagged || taggedLeft > 0 {
+		return nil
+	}
+	if !ifBlocked {
+		return fmt.Errorf("nothing deleted: the graph holds the last tagged version of the package, which GHCR refuses to delete. Use --delete-package-if-blocked or 'ghcrctl delete package' instead")
+	}
+	if othersLeft > 0 {
+		return fmt.Errorf("nothing deleted: the graph holds the last tagged version of the package, and --delete-package-if-blocked would not delete the package because %d other version(s) would be lost", othersLeft)
+	}
+	return nil
+}
+
+// deletePackageParams contains parameters for deleting an entire package
+type deletePackageParams struct {
+	Owner       string
+	OwnerType   string
+	PackageName string
+	Versions    []gh.PackageVersionInfo
+	Force       bool
+	ArchivePath string                     // Empty to skip the archive
+	Manifests   map[string]json.RawMessage // Root manifests by digest, included in the archive
+}
+
+// packageArchive is the record written by delete package --archive
+type packageArchive struct {
+	Package    string                  `json:"package"`
+	OwnerType  string                  `json:"owner_type"`
+	ArchivedAt string                  `json:"archived_at"`
+	Versions   []packageArchiveVersion `json:"versions"`
+}
+
+// packageArchiveVersion is a single version in a package archive
+type packageArchiveVersion struct {
+	ID        int64           `json:"id"`
+	Digest    string          `json:"digest"`
+	Tags      []string        `json:"tags"`
+	CreatedAt string          `json:"created_at"`
+	Manifest  json.RawMessage `json:"manifest,omitempty"`
+}
+
+// executeDeletePackage shows what will be deleted, asks for confirmation, writes
+// the archive if requested, and deletes the package.
+func executeDeletePackage(ctx context.Context, remover packageRemover, params deletePackageParams, w io.Writer, confirmFn func() (bool, error)) error {
+	// Count tagged vs untagged
+	taggedCount := 0
+	for _, v := range params.Versions {
+		if len(v.Tags) > 0 {
+			taggedCount++
+		}
+	}
+	untaggedCount := len(params.Versions) - taggedCount
+
+	// Show what will be deleted
+	fmt.Fprintf(w, "Preparing to delete entire package:\n")
+	fmt.Fprintf(w, "  Package:  %s/%s\n", params.Owner, params.PackageName)
+	fmt.Fprintf(w, "  Owner:    %s (%s)\n", params.Owner, params.OwnerType)
+	fmt.Fprintf(w, "  Versions: %s (%d tagged, %d untagged)\n\n",
+		display.ColorWarning(fmt.Sprintf("%d total", len(params.Versions))), taggedCount, untaggedCount)
+	fmt.Fprintf(w, "%s\n\n", display.ColorError("WARNING: This will permanently delete ALL versions of this package!"))
+
+	// Confirm deletion unless --force or --yes is used
+	if !params.Force {
+		confirmed, err := confirmFn()
 		if err != nil {
-			cmd.SilenceUsage = true
-			return fmt.Errorf("failed to resolve tag '%s': %w", tag, err)
+			return fmt.Errorf("failed to read confirmation: %w", err)
 		}
-		targetVersionID, err = client.GetVersionIDByDigest(ctx, owner, ownerType, packageName, resolvedDigest)
+
+		if !confirmed {
+			fmt.Fprintln(w, "Deletion cancelled (input did not match package name)")
+			return nil
+		}
+	}
+
+	// Write the archive before anything is deleted
+	if params.ArchivePath != "" {
+		if err := writePackageArchive(params.ArchivePath, params, time.Now()); err != nil {
+			return fmt.Errorf("%w; package was not deleted", err)
+		}
+		fmt.Fprintf(w, "Archived %d version(s) to %s\n", len(params.Versions), params.ArchivePath)
+	}
+
+	// Perform deletion
+	if err := remover.DeletePackage(ctx, params.Owner, params.OwnerType, params.PackageName); err != nil {
+		return fmt.Errorf("failed to delete package: %w", err)
+	}
+	if err := recordPackageDeleted(ctx, params.Owner, params.PackageName, params.Versions); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w, display.ColorSuccess(fmt.Sprintf("Successfully deleted package %s/%s", params.Owner, params.PackageName)))
+	printPackageRestoreHint(w, params.Owner, params.OwnerType)
+	return nil
+}
+
+// writePackageArchive writes the versions of a package as indented JSON
+func writePackageArchive(path string, params deletePackageParams, now time.Time) error {
+	archive := packageArchive{
+		Package:    fmt.Sprintf("%s/%s", params.Owner, params.PackageName),
+		OwnerType:  params.OwnerType,
+		ArchivedAt: now.UTC().Format(time.RFC3339),
+		Versions:   make([]packageArchiveVersion, 0, len(params.Versions)),
+	}
+	for _, v := range params.Versions {
+		archive.Versions = append(archive.Versions, packageArchiveVersion{
+			ID:        v.ID,
+			Digest:    v.Digest,
+			Tags:      v.Tags,
+			CreatedAt: v.CreatedAt,
+			Manifest:  params.Manifests[v.Digest],
+		})
+	}
+
+	data, err := json.MarshalIndent(archive, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal archive: %w", err)
+	}
+	data = append(data, '\n')
+
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write archive %s: %w", path, err)
+	}
+	return nil
+}
+
+// fetchRootManifests fetches the manifest of every root version in the package
+func fetchRootManifests(ctx context.Context, image string, versions []gh.PackageVersionInfo) (map[string]json.RawMessage, error) {
+	discovered, err := discover.NewPackageDiscoverer().DiscoverPackage(ctx, image, versions, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover package: %w", err)
+	}
+	versionMap := discover.ToMap(discovered)
+
+	manifests := make(map[string]json.RawMessage)
+	for _, v := range discovered {
+		if !v.IsRoot(versionMap) {
+			continue
+		}
+		manifest, _, err := discover.FetchManifest(ctx, image, v.Digest)
+		if err != nil {
+			return nil, fmt.Errorf("failed to archive manifest %s: %w", display.ShortDigest(v.Digest), err)
+		}
+		manifests[v.Digest] = manifest
+	}
+	return manifests, nil
+}
+
+// resolveDigestPrefix resolves a short or full digest to the digest of a package version.
+// Versions are expected newest first. If more than one version matches, strict mode
+// fails with the candidates; otherwise the newest match is used and a warning is written.
+func resolveDigestPrefix(versions []gh.PackageVersionInfo, input string, strict bool, warn io.Writer) (string, error) {
+	// A digest listed under several version IDs is still one candidate
+	digests := make([]string, 0, len(versions))
+	seen := make(map[string]bool, len(versions))
+	for _, ver := range versions {
+		if !seen[ver.Digest] {
+			seen[ver.Digest] = true
+			digests = append(digests, ver.Digest)
+		}
+	}
+
+	matches := discover.MatchShortDigest(digests, input)
+	switch {
+	case len(matches) == 0:
+		return "", fmt.Errorf("no version found matching digest prefix %s", input)
+	case len(matches) == 1:
+		return matches[0], nil
+	case strict:
+		return "", &discover.AmbiguousDigestError{Input: input, Candidates: matches}
+	}
+
+	fmt.Fprintf(warn, "%s digest %s matches %d versions, using the newest: %s\n",
+		display.ColorWarning("Warning:"), input, len(matches), matches[0])
+	return matches[0], nil
+}
+
+// runSingleDeleteVersion handles deletion of a single version
+func runSingleDeleteVersion(ctx context.Context, cmd *cobra.Command, client *gh.Client, owner, ownerType, packageName string,
+	versionID int64, digest, tag string, strictDigest, force, dryRun, detailedExit, verify bool, fallback *packageFallback) error {
+
+	ociRef := fmt.Sprintf("ghcr.io/%s/%s", owner, packageName)
+
+	// A full or short digest is resolved against the listing below
+	targetDigest := digest
+	if tag != "" {
+		// Resolve tag to digest first, then find its version
+		resolvedDigest, err := discover.ResolveTag(ctx, ociRef, tag)
 		if err != nil {
 			cmd.SilenceUsage = true
-			return fmt.Errorf("failed to find version for tag '%s': %w", tag, err)
+			return fmt.Errorf("failed to resolve tag '%s': %w", tag, err)
 		}
+		targetDigest = resolvedDigest
 	}
 
-	// Get version tags to show what we're deleting
-	tags, err := client.GetVersionTags(ctx, owner, ownerType, packageName, targetVersionID)
+	// One listing of the package provides the version ID, its tags, and the
+	// versions that reference it
+	target, allVersions, err := findSingleVersion(ctx, client, owner, ownerType, packageName, versionID, targetDigest, strictDigest, cmd.ErrOrStderr())
 	if err != nil {
 		cmd.SilenceUsage = true
-		return fmt.Errorf("failed to get version details: %w", err)
+		if tag != "" {
+			return fmt.Errorf("failed to find version for tag '%s': %w", tag, err)
+		}
+		return fmt.Errorf("failed to find version: %w", err)
+	}
+	targetVersionID := target.ID
+	tags := target.Tags
+	targets := []gh.PackageVersionInfo{target}
+	if targetDigest != "" {
+		// Deleting a digest deletes every version ID it is listed under
+		targetDigest = target.Digest
+		targets = versionsWithDigest(allVersions, targetDigest)
 	}
 
 	// Count how many other versions reference this one
-	refCount := countIncomingRefs(ctx, client, owner, ownerType, packageName, targetVersionID)
+	refCount := countIncomingRefsIn(ctx, discover.NewPackageDiscoverer(), ociRef, allVersions, targetVersionID)
 
 	// Show what will be deleted
 	fmt.Fprintf(cmd.OutOrStdout(), "Preparing to delete package version:\n")
 	fmt.Fprintf(cmd.OutOrStdout(), "  Package:    %s\n", packageName)
 	fmt.Fprintf(cmd.OutOrStdout(), "  Owner:      %s (%s)\n", owner, ownerType)
-	fmt.Fprintf(cmd.OutOrStdout(), "  Version ID: %d\n", targetVersionID)
+	fmt.Fprintf(cmd.OutOrStdout(), "  Version ID: %d%s\n", targetVersionID, formatDuplicateIDs(versionIDsOf(targets[1:])))
 	fmt.Fprintf(cmd.OutOrStdout(), "  Tags:       %s\n", formatTagsForDisplay(tags))
 	if refCount > 0 {
 		versionWord := "version"
@@ -583,7 +1538,11 @@ func runSingleDeleteVersion(ctx context.Context, cmd *cobra.Command, client *gh.
 	// Handle dry-run
 	if dryRun {
 		fmt.Fprintln(cmd.OutOrStdout(), display.ColorDryRun("DRY RUN: No changes made"))
-		return nil
+		cmd.SilenceUsage = true
+		if err := (deleteOutputs{WouldDelete: len(targets), Digest: targetDigest}).write(ctx); err != nil {
+			return err
+		}
+		return dryRunResult(detailedExit, len(targets))
 	}
 
 	// Confirm deletion unless --force is used
@@ -600,10 +1559,28 @@ func runSingleDeleteVersion(ctx context.Context, cmd *cobra.Command, client *gh.
 	}
 
 	// Perform deletion
-	err = client.DeletePackageVersion(ctx, owner, ownerType, packageName, targetVersionID)
+	deleted, err := deleteSameDigest(ctx, client, owner, ownerType, packageName, targets)
+	outputs := deleteOutputs{Deleted: len(deleted), Failed: len(targets) - len(deleted), Digest: targetDigest}
+	if outputErr := outputs.write(ctx); outputErr != nil {
+		cmd.SilenceUsage = true
+		return outputErr
+	}
+	for _, ver := range deleted {
+		if auditErr := recordDeleted(ctx, audit.Version{ID: ver.ID, Digest: ver.Digest, Tags: ver.Tags}); auditErr != nil {
+			cmd.SilenceUsage = true
+			return auditErr
+		}
+	}
 	if err != nil {
 		cmd.SilenceUsage = true
 		if gh.IsLastTaggedVersionError(err) {
+			deleted, fallbackErr := deletePackageIfBlocked(ctx, fallback, owner, ownerType, packageName, versionIDsOf(targets), cmd.OutOrStdout())
+			if fallbackErr != nil {
+				return fallbackErr
+			}
+			if deleted {
+				return nil
+			}
 			fmt.Fprintf(cmd.OutOrStdout(), "\n%s\n", display.ColorWarning("GHCR does not allow to delete the last tagged version of a package."))
 			fmt.Fprintf(cmd.OutOrStdout(), "You can delete the package instead:\n")
 			fmt.Fprintf(cmd.OutOrStdout(), "  ghcrctl delete package %s/%s\n", owner, packageName)
@@ -612,17 +1589,57 @@ func runSingleDeleteVersion(ctx context.Context, cmd *cobra.Command, client *gh.
 		return fmt.Errorf("failed to delete package version: %w", err)
 	}
 
-	fmt.Fprintln(cmd.OutOrStdout(), display.ColorSuccess(fmt.Sprintf("Successfully deleted version %d of %s", targetVersionID, packageName)))
+	fmt.Fprintln(cmd.OutOrStdout(), display.ColorSuccess(fmt.Sprintf("Successfully deleted version %d%s of %s",
+		targetVersionID, formatDuplicateIDs(versionIDsOf(targets[1:])), packageName)))
+	printRestoreHint(cmd.OutOrStdout(), owner, ownerType, packageName)
+	if verify {
+		cmd.SilenceUsage = true
+		return verifyDeleted(ctx, client, owner, ownerType, packageName, versionIDsOf(targets), cmd.OutOrStdout())
+	}
 	return nil
 }
 
+// versionsWithDigest returns the versions listed with digest, in listing order.
+// GHCR can list the same digest under several version IDs, e.g. after a re-push.
+func versionsWithDigest(allVersions []gh.PackageVersionInfo, digest string) []gh.PackageVersionInfo {
+	var matches []gh.PackageVersionInfo
+	for _, ver := range allVersions {
+		if ver.Digest == digest {
+			matches = append(matches, ver)
+		}
+	}
+	return matches
+}
+
+// versionIDsOf returns the IDs of versions
+func versionIDsOf(versions []gh.PackageVersionInfo) []int64 {
+	ids := make([]int64, len(versions))
+	for i, ver := range versions {
+		ids[i] = ver.ID
+	}
+	return ids
+}
+
+// deleteSameDigest deletes the version IDs of one digest, stopping at the first
+// failure. It returns the versions that were deleted.
+func deleteSameDigest(ctx context.Context, deleter packageDeleter, owner, ownerType, packageName string, versions []gh.PackageVersionInfo) ([]gh.PackageVersionInfo, error) {
+	deleted := make([]gh.PackageVersionInfo, 0, len(versions))
+	for _, ver := range versions {
+		if err := deleter.DeletePackageVersion(ctx, owner, ownerType, packageName, ver.ID); err != nil {
+			return deleted, err
+		}
+		deleted = append(deleted, ver)
+	}
+	return deleted, nil
+}
+
 // runBulkDeleteVersion handles deletion of multiple versions using filters
 func runBulkDeleteVersion(ctx context.Context, cmd *cobra.Command, client *gh.Client, owner, ownerType, packageName string,
-	tagPattern string, onlyTagged, onlyUntagged bool, olderThan, newerThan string,
-	force, dryRun bool) error {
+	tagPattern, tagPrefix, tagSuffix string, onlyTagged, onlyUntagged bool, olderThan, newerThan string, afterID, beforeID int64, maxDelete int,
+	excludeIDs []int64, excludeDigests []string, orphanAttestations, force, dryRun, detailedExit, verify bool, fallback *packageFallback) error {
 
 	// Build filter from flags
-	versionFilter, err := buildDeleteVersionFilter(tagPattern, onlyTagged, onlyUntagged, olderThan, newerThan)
+	versionFilter, err := buildDeleteVersionFilter(tagPattern, tagPrefix, tagSuffix, onlyTagged, onlyUntagged, olderThan, newerThan, dateLocation(cmd), afterID, beforeID)
 	if err != nil {
 		cmd.SilenceUsage = true
 		return fmt.Errorf("invalid filter options: %w", err)
@@ -641,17 +1658,49 @@ func runBulkDeleteVersion(ctx context.Context, cmd *cobra.Command, client *gh.Cl
 	// Check if any versions match
 	if len(matchingVersions) == 0 {
 		fmt.Fprintln(cmd.OutOrStdout(), "No versions match the specified filters")
-		return nil
+		cmd.SilenceUsage = true
+		return deleteOutputs{}.write(ctx)
+	}
+
+	// Spare explicitly excluded versions before anything else, so they count
+	// neither against --max-delete nor as part of the deletion set
+	matchingVersions, excluded := excludeVersions(matchingVersions, excludeIDs, excludeDigests)
+	if len(excluded) > 0 {
+		fmt.Fprintf(cmd.OutOrStdout(), "%s %d matching version(s) are excluded and will be preserved.\n\n",
+			display.ColorWarning("Note:"), len(excluded))
+	}
+	if len(matchingVersions) == 0 {
+		fmt.Fprintln(cmd.OutOrStdout(), "No versions to delete (all matching versions are excluded)")
+		cmd.SilenceUsage = true
+		return deleteOutputs{}.write(ctx)
+	}
+
+	// Build all graphs to identify shared children that should be protected
+	ociRef := fmt.Sprintf("ghcr.io/%s/%s", owner, packageName)
+	discoverer := discover.NewPackageDiscoverer()
+	versions, discoverErr := discoverer.DiscoverPackage(ctx, ociRef, allVersions, nil)
+	printDiscoveryWarnings(ctx, cmd.ErrOrStderr(), versions)
+
+	if orphanAttestations {
+		if err := discoverErr; err != nil {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("failed to discover the package: %w", err)
+		}
+		matchingVersions = selectOrphanAttestations(ctx, orasSubjectExtractor{}, ociRef, versions, matchingVersions, cmd.OutOrStdout())
+		if len(matchingVersions) == 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), "No orphaned attestations match the specified filters")
+			cmd.SilenceUsage = true
+			return deleteOutputs{}.write(ctx)
+		}
 	}
 
-	// Build all graphs to identify shared children that should be protected
-	ociRef := fmt.Sprintf("ghcr.io/%s/%s", owner, packageName)
-	discoverer := discover.NewPackageDiscoverer()
-	versions, err := discoverer.DiscoverPackage(ctx, ociRef, allVersions, nil)
+	// Cap the run before protecting shared children, so that a version whose
+	// parent is left for a later run is protected as well
+	matchingVersions, remaining := limitOldest(matchingVersions, maxDelete)
 
 	// Track which version IDs are shared (have incoming refs from outside deletion set)
 	sharedChildren := make(map[int64]bool)
-	if err == nil {
+	if discoverErr == nil {
 		// Build set of version IDs being deleted
 		deletingIDs := make(map[int64]bool)
 		for _, ver := range matchingVersions {
@@ -664,13 +1713,16 @@ func runBulkDeleteVersion(ctx context.Context, cmd *cobra.Command, client *gh.Cl
 			digestToID[ver.Digest] = ver.ID
 		}
 
-		// A version is shared if it has incoming refs from versions NOT being deleted
+		// A version is shared if it has incoming refs from versions NOT being deleted.
+		// Duplicate IDs of a shared digest are shared as well.
 		for _, ver := range versions {
 			for _, inRef := range ver.IncomingRefs {
 				if refID, ok := digestToID[inRef]; ok {
 					if !deletingIDs[refID] {
 						// This version has a ref from something not being deleted
-						sharedChildren[ver.ID] = true
+						for _, id := range ver.VersionIDs() {
+							sharedChildren[id] = true
+						}
 						break
 					}
 				}
@@ -700,7 +1752,8 @@ func runBulkDeleteVersion(ctx context.Context, cmd *cobra.Command, client *gh.Cl
 	// Re-check if any versions remain after filtering
 	if len(matchingVersions) == 0 {
 		fmt.Fprintln(cmd.OutOrStdout(), "No versions to delete (all matching versions are shared with other graphs)")
-		return nil
+		cmd.SilenceUsage = true
+		return deleteOutputs{}.write(ctx)
 	}
 
 	// Display summary of what will be deleted
@@ -722,10 +1775,22 @@ func runBulkDeleteVersion(ctx context.Context, cmd *cobra.Command, client *gh.Cl
 	}
 	fmt.Fprintln(cmd.OutOrStdout())
 
+	if remaining > 0 {
+		fmt.Fprintf(cmd.OutOrStdout(), "%s --max-delete %d reached; %d more matching version(s) are left for the next run.\n\n",
+			display.ColorWarning("Note:"), maxDelete, remaining)
+	}
+
 	// Handle dry-run
 	if dryRun {
 		fmt.Fprintln(cmd.OutOrStdout(), display.ColorDryRun("DRY RUN: No changes made"))
-		return nil
+		cmd.SilenceUsage = true
+		if err := appendDeleteSummary(ctx, packageName, matchingVersions, nil); err != nil {
+			return err
+		}
+		if err := (deleteOutputs{WouldDelete: len(matchingVersions)}).write(ctx); err != nil {
+			return err
+		}
+		return dryRunResult(detailedExit, len(matchingVersions))
 	}
 
 	// Confirm deletion unless --force is used
@@ -743,22 +1808,32 @@ func runBulkDeleteVersion(ctx context.Context, cmd *cobra.Command, client *gh.Cl
 	}
 
 	// Perform bulk deletion
-	successCount := 0
-	failCount := 0
+	results, deletedIDs, err := deleteVersionsEach(ctx, client, owner, ownerType, packageName, matchingVersions, cmd.OutOrStdout())
+	if err != nil {
+		cmd.SilenceUsage = true
+		return err
+	}
+	successCount := len(deletedIDs)
+	failCount := len(matchingVersions) - successCount
 	lastTaggedHit := false
-	for i, ver := range matchingVersions {
-		fmt.Fprintf(cmd.OutOrStdout(), "Deleting version %d/%d (ID: %d)...\n", i+1, len(matchingVersions), ver.ID)
-		err := client.DeletePackageVersion(ctx, owner, ownerType, packageName, ver.ID)
-		if err != nil {
-			if gh.IsLastTaggedVersionError(err) {
-				lastTaggedHit = true
-			}
-			fmt.Fprintf(cmd.OutOrStdout(), "  %s\n", display.ColorError(fmt.Sprintf("Failed: %v", err)))
-			failCount++
-		} else {
-			successCount++
+	for _, result := range results {
+		if result != nil && gh.IsLastTaggedVersionError(result) {
+			lastTaggedHit = true
 		}
 	}
+	if err := appendDeleteSummary(ctx, packageName, matchingVersions, results); err != nil {
+		cmd.SilenceUsage = true
+		return err
+	}
+	outputs := deleteOutputs{Deleted: successCount, Failed: failCount}
+	if discoverErr == nil {
+		outputs.Reclaimed = reclaimedBytes(versions, deletedIDs)
+		outputs.SizeKnown = true
+	}
+	if err := outputs.write(ctx); err != nil {
+		cmd.SilenceUsage = true
+		return err
+	}
 
 	// Summary
 	fmt.Fprintln(cmd.OutOrStdout())
@@ -770,13 +1845,35 @@ func runBulkDeleteVersion(ctx context.Context, cmd *cobra.Command, client *gh.Cl
 		fmt.Fprintf(cmd.OutOrStdout(), "Deletion complete: %s succeeded\n",
 			display.ColorSuccess(fmt.Sprintf("%d", successCount)))
 	}
+	if successCount > 0 {
+		printRestoreHint(cmd.OutOrStdout(), owner, ownerType, packageName)
+	}
 
 	if lastTaggedHit {
+		planned := make([]int64, len(matchingVersions))
+		for i, ver := range matchingVersions {
+			planned[i] = ver.ID
+		}
+		deleted, err := deletePackageIfBlocked(ctx, fallback, owner, ownerType, packageName, planned, cmd.OutOrStdout())
+		if err != nil {
+			cmd.SilenceUsage = true
+			return err
+		}
+		if deleted {
+			return nil
+		}
 		fmt.Fprintf(cmd.OutOrStdout(), "\n%s\n", display.ColorWarning("Note: GHCR does not allow to delete the last tagged version of a package."))
 		fmt.Fprintf(cmd.OutOrStdout(), "You can delete the package instead:\n")
 		fmt.Fprintf(cmd.OutOrStdout(), "  ghcrctl delete package %s/%s\n", owner, packageName)
 	}
 
+	if verify && successCount > 0 {
+		if err := verifyDeleted(ctx, client, owner, ownerType, packageName, deletedIDs, cmd.OutOrStdout()); err != nil {
+			cmd.SilenceUsage = true
+			return err
+		}
+	}
+
 	if failCount > 0 {
 		return fmt.Errorf("failed to delete %d version(s)", failCount)
 	}
@@ -784,23 +1881,207 @@ func runBulkDeleteVersion(ctx context.Context, cmd *cobra.Command, client *gh.Cl
 	return nil
 }
 
+// deleteVersionsEach deletes every version, continuing after failures, and
+// records each deleted version in the audit log. It returns the result of each
+// version, in order, and the IDs of the deleted versions. The error is only set
+// if the audit log cannot be written.
+func deleteVersionsEach(ctx context.Context, deleter packageDeleter, owner, ownerType, packageName string, versions []gh.PackageVersionInfo, w io.Writer) ([]error, []int64, error) {
+	results := make([]error, len(versions))
+	var deletedIDs []int64
+	for i, ver := range versions {
+		fmt.Fprintf(w, "Deleting version %d/%d (ID: %d)...\n", i+1, len(versions), ver.ID)
+		results[i] = deleter.DeletePackageVersion(ctx, owner, ownerType, packageName, ver.ID)
+		if results[i] != nil {
+			fmt.Fprintf(w, "  %s\n", display.ColorError(fmt.Sprintf("Failed: %v", results[i])))
+			continue
+		}
+		deletedIDs = append(deletedIDs, ver.ID)
+		if err := recordDeleted(ctx, audit.Version{ID: ver.ID, Digest: ver.Digest, Tags: ver.Tags}); err != nil {
+			return results, deletedIDs, err
+		}
+	}
+	return results, deletedIDs, nil
+}
+
+// subjectExtractor reads the digests an attestation or signature is about
+type subjectExtractor interface {
+	SubjectDigests(ctx context.Context, image, digest string) ([]string, error)
+}
+
+// orasSubjectExtractor reads subjects from the registry using the discover package
+type orasSubjectExtractor struct{}
+
+func (orasSubjectExtractor) SubjectDigests(ctx context.Context, image, digest string) ([]string, error) {
+	return discover.ExtractSubjectDigests(ctx, image, digest)
+}
+
+// selectOrphanAttestations returns the versions of matching that are
+// attestations or signatures without a parent in the discovered graphs, and
+// whose subjects, as read from the attestation itself, are all gone from the
+// package. Discovery follows references from the subject and can miss some,
+// e.g. cosign .att links, so the subject check keeps attestations that are
+// still valid. Versions whose subject cannot be read are kept as well.
+func selectOrphanAttestations(ctx context.Context, extractor subjectExtractor, image string, discovered []discover.VersionInfo,
+	matching []gh.PackageVersionInfo, w io.Writer) []gh.PackageVersionInfo {
+	byDigest := discover.ToMap(discovered)
+
+	var orphans []gh.PackageVersionInfo
+	stillAttached, unconfirmed := 0, 0
+	for _, ver := range matching {
+		v, ok := byDigest[ver.Digest]
+		if !ok || !v.IsReferrer() || hasParentIn(v, byDigest) {
+			continue
+		}
+
+		subjects, err := extractor.SubjectDigests(ctx, image, ver.Digest)
+		if err != nil || len(subjects) == 0 {
+			unconfirmed++
+			continue
+		}
+		if slices.ContainsFunc(subjects, func(subject string) bool {
+			_, exists := byDigest[subject]
+			return exists
+		}) {
+			stillAttached++
+			continue
+		}
+		orphans = append(orphans, ver)
+	}
+
+	if stillAttached > 0 {
+		fmt.Fprintf(w, "%s %d attestation(s) without a parent in the graph still have their subject and will be preserved.\n\n",
+			display.ColorWarning("Note:"), stillAttached)
+	}
+	if unconfirmed > 0 {
+		fmt.Fprintf(w, "%s %d attestation(s) without a parent in the graph have no readable subject and will be preserved.\n\n",
+			display.ColorWarning("Note:"), unconfirmed)
+	}
+	return orphans
+}
+
+// hasParentIn reports whether any version referencing v is in versions
+func hasParentIn(v discover.VersionInfo, versions map[string]discover.VersionInfo) bool {
+	for _, in := range v.IncomingRefs {
+		if _, ok := versions[in]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// excludeVersions removes the versions given to --exclude-version (by ID) and
+// --exclude-digest (full or short digest) from versions. It returns the
+// remaining and the excluded versions, both in their original order.
+func excludeVersions(versions []gh.PackageVersionInfo, ids []int64, digests []string) (kept, excluded []gh.PackageVersionInfo) {
+	if len(ids) == 0 && len(digests) == 0 {
+		return versions, nil
+	}
+	excludedIDs := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		excludedIDs[id] = true
+	}
+
+	for _, ver := range versions {
+		exclude := excludedIDs[ver.ID]
+		for _, digest := range digests {
+			if filter.MatchesDigest(ver.Digest, digest) {
+				exclude = true
+				break
+			}
+		}
+		if exclude {
+			excluded = append(excluded, ver)
+		} else {
+			kept = append(kept, ver)
+		}
+	}
+	return kept, excluded
+}
+
+// limitOldest returns at most max versions, the oldest by creation date first,
+// and the number of versions left out. Ties are broken by version ID, and
+// versions with an unparseable creation date count as newest. A max of zero
+// or less keeps all versions in their original order.
+func limitOldest(versions []gh.PackageVersionInfo, max int) ([]gh.PackageVersionInfo, int) {
+	if max <= 0 || len(versions) <= max {
+		return versions, 0
+	}
+
+	type datedVersion struct {
+		ver       gh.PackageVersionInfo
+		createdAt time.Time
+		ok        bool
+	}
+	dated := make([]datedVersion, len(versions))
+	for i, ver := range versions {
+		createdAt, err := filter.ParseDate(ver.CreatedAt)
+		dated[i] = datedVersion{ver: ver, createdAt: createdAt, ok: err == nil}
+	}
+	sort.SliceStable(dated, func(i, j int) bool {
+		a, b := dated[i], dated[j]
+		if a.ok != b.ok {
+			return a.ok
+		}
+		if !a.createdAt.Equal(b.createdAt) {
+			return a.createdAt.Before(b.createdAt)
+		}
+		return a.ver.ID < b.ver.ID
+	})
+
+	limited := make([]gh.PackageVersionInfo, max)
+	for i := range limited {
+		limited[i] = dated[i].ver
+	}
+	return limited, len(versions) - max
+}
+
+// selectExtremeVersion returns the oldest version, or the newest if newest is
+// set, by creation date. Ties are broken by version ID: the lower ID counts as
+// older. Versions with an unparseable creation date are skipped.
+func selectExtremeVersion(versions []gh.PackageVersionInfo, newest bool) (gh.PackageVersionInfo, error) {
+	var selected gh.PackageVersionInfo
+	var selectedAt time.Time
+	found := false
+	for _, ver := range versions {
+		createdAt, err := filter.ParseDate(ver.CreatedAt)
+		if err != nil {
+			continue
+		}
+		older := createdAt.Before(selectedAt) || (createdAt.Equal(selectedAt) && ver.ID < selected.ID)
+		if !found || older != newest {
+			selected, selectedAt, found = ver, createdAt, true
+		}
+	}
+	if !found {
+		return gh.PackageVersionInfo{}, fmt.Errorf("no versions match the specified filters")
+	}
+	return selected, nil
+}
+
 // buildDeleteVersionFilter creates a VersionFilter from command-line flags
-func buildDeleteVersionFilter(tagPattern string, onlyTagged, onlyUntagged bool,
-	olderThan, newerThan string) (*filter.VersionFilter, error) {
+func buildDeleteVersionFilter(tagPattern, tagPrefix, tagSuffix string, onlyTagged, onlyUntagged bool,
+	olderThan, newerThan string, loc *time.Location, afterID, beforeID int64) (*filter.VersionFilter, error) {
 	// Check for conflicting flags
 	if onlyTagged && onlyUntagged {
 		return nil, fmt.Errorf("cannot use --tagged and --untagged together")
 	}
+	if err := validateVersionIDRange(afterID, beforeID); err != nil {
+		return nil, err
+	}
 
 	vf := &filter.VersionFilter{
 		OnlyTagged:   onlyTagged,
 		OnlyUntagged: onlyUntagged,
 		TagPattern:   tagPattern,
+		TagPrefix:    tagPrefix,
+		TagSuffix:    tagSuffix,
+		MinVersionID: afterID,
+		MaxVersionID: beforeID,
 	}
 
 	// Parse date/duration filters
 	if olderThan != "" {
-		t, err := filter.ParseDateOrDuration(olderThan)
+		t, err := filter.ParseDateOrDurationIn(olderThan, loc)
 		if err != nil {
 			return nil, fmt.Errorf("invalid --older-than value: %w", err)
 		}
@@ -808,7 +2089,7 @@ func buildDeleteVersionFilter(tagPattern string, onlyTagged, onlyUntagged bool,
 	}
 
 	if newerThan != "" {
-		t, err := filter.ParseDateOrDuration(newerThan)
+		t, err := filter.ParseDateOrDurationIn(newerThan, loc)
 		if err != nil {
 			return nil, fmt.Errorf("invalid --newer-than value: %w", err)
 		}
@@ -830,7 +2111,7 @@ func outputDeleteGraphVersions(w io.Writer, toDelete, shared, graphVersions []di
 	if len(toDelete) > 0 {
 		fmt.Fprintf(w, "Versions to delete (%d):\n", len(toDelete))
 		for _, v := range toDelete {
-			fmt.Fprintf(w, "  - %s (version %d)%s\n", formatVersionType(v.Types), v.ID, formatVersionTags(v.Tags))
+			fmt.Fprintf(w, "  - %s (version %d)%s%s\n", formatVersionType(v.Types), v.ID, formatVersionTags(v.Tags), formatDuplicateIDs(v.DuplicateIDs))
 		}
 	}
 
@@ -851,10 +2132,23 @@ func outputDeleteGraphVersions(w io.Writer, toDelete, shared, graphVersions []di
 	}
 }
 
-// formatVersionType returns the first type or "unknown" if none.
+// formatDuplicateIDs notes the other version IDs of a digest, or returns an empty string if none.
+func formatDuplicateIDs(ids []int64) string {
+	if len(ids) == 0 {
+		return ""
+	}
+	strs := make([]string, len(ids))
+	for i, id := range ids {
+		strs[i] = fmt.Sprintf("%d", id)
+	}
+	return fmt.Sprintf(" (duplicates: %s)", strings.Join(strs, ", "))
+}
+
+// formatVersionType returns all types of a version, e.g. "sbom, provenance" for
+// an attestation carrying both, or "unknown" if none.
 func formatVersionType(types []string) string {
 	if len(types) > 0 {
-		return types[0]
+		return strings.Join(types, ", ")
 	}
 	return "unknown"
 }
@@ -886,6 +2180,7 @@ type deleteVersionParams struct {
 	OwnerType   string
 	PackageName string
 	VersionID   int64
+	Digest      string
 	Tags        []string
 	RefCount    int
 	Force       bool
@@ -902,6 +2197,264 @@ type bulkDeleteParams struct {
 	DryRun      bool
 }
 
+// deleteAllTagsParams contains parameters for deleting every tagged graph
+type deleteAllTagsParams struct {
+	Owner       string
+	OwnerType   string
+	PackageName string
+	Scope       string                 // What is deleted, e.g. "graphs of closed pull requests"; empty for all tagged graphs
+	Tagged      []discover.VersionInfo // tagged versions whose graphs are deleted
+	ToDelete    []discover.VersionInfo // exclusive versions, children first
+	Shared      []discover.VersionInfo // versions referenced from outside the deletion
+	Force       bool
+	DryRun      bool
+	// DetailedExitCode makes a dry run return errWouldDelete if it found versions to delete
+	DetailedExitCode bool
+	Fallback         *packageFallback // Deletes the package if the last tagged version blocks
+}
+
+// dryRunResult returns the result of a dry run that found count versions to
+// delete: errWouldDelete with --detailed-exitcode and a non-empty plan, nil otherwise.
+func dryRunResult(detailedExitCode bool, count int) error {
+	if detailedExitCode && count > 0 {
+		return errWouldDelete
+	}
+	return nil
+}
+
+// planDeleteAllTags collects the graphs of all tagged versions and classifies
+// their union into exclusive versions (ordered children first) and versions
+// shared with graphs that are not being deleted.
+func planDeleteAllTags(versions []discover.VersionInfo) (tagged, toDelete, shared []discover.VersionInfo) {
+	return planDeleteGraphs(versions, func(v discover.VersionInfo) bool { return len(v.Tags) > 0 })
+}
+
+// planDeleteGraphs collects the graphs of the versions accepted by selected and
+// classifies them like planDeleteAllTags
+func planDeleteGraphs(versions []discover.VersionInfo, selected func(discover.VersionInfo) bool) (tagged, toDelete, shared []discover.VersionInfo) {
+	versionMap := discover.ToMap(versions)
+
+	seen := make(map[string]bool)
+	var union []discover.VersionInfo
+	for _, v := range versions {
+		if !selected(v) {
+			continue
+		}
+		tagged = append(tagged, v)
+		for _, gv := range discover.FindGraphByDigest(versionMap, v.Digest) {
+			if !seen[gv.Digest] {
+				seen[gv.Digest] = true
+				union = append(union, gv)
+			}
+		}
+	}
+
+	// Sort for stable output; discovery returns versions in map order
+	sort.Slice(tagged, func(i, j int) bool { return tagged[i].ID > tagged[j].ID })
+	sort.Slice(union, func(i, j int) bool { return union[i].ID > union[j].ID })
+
+	toDelete, shared = discover.ClassifyGraphVersions(union)
+	return tagged, discover.SortChildrenFirst(toDelete), shared
+}
+
+// executeDeleteAllTags deletes every tagged graph with confirmation. It stops
+// gracefully when GHCR refuses to delete the last tagged version.
+func executeDeleteAllTags(ctx context.Context, deleter packageDeleter, params deleteAllTagsParams, w io.Writer, confirmFn func() (bool, error)) error {
+	scope := params.Scope
+	if scope == "" {
+		scope = "all tagged graphs"
+	}
+	if len(params.Tagged) == 0 {
+		if params.Scope != "" {
+			fmt.Fprintf(w, "No %s found in %s\n", params.Scope, params.PackageName)
+		} else {
+			fmt.Fprintf(w, "No tagged versions found in %s\n", params.PackageName)
+		}
+		return nil
+	}
+
+	fmt.Fprintf(w, "Preparing to delete %s:\n", scope)
+	fmt.Fprintf(w, "  Package: %s\n", params.PackageName)
+	fmt.Fprintf(w, "  Tagged:  %d version(s)\n", len(params.Tagged))
+	for _, v := range params.Tagged {
+		fmt.Fprintf(w, "    - %s (version %d)%s\n", formatVersionType(v.Types), v.ID, formatVersionTags(v.Tags))
+	}
+	fmt.Fprintf(w, "\n")
+
+	// Duplicate version IDs of a digest are deleted along with it
+	var planned []int64
+	for _, v := range params.ToDelete {
+		planned = append(planned, v.VersionIDs()...)
+	}
+
+	graphVersions := append(append([]discover.VersionInfo{}, params.ToDelete...), params.Shared...)
+	outputDeleteGraphVersions(w, params.ToDelete, params.Shared, graphVersions)
+	fmt.Fprintf(w, "\nTotal: %s version(s) will be deleted\n\n",
+		display.ColorWarning(fmt.Sprintf("%d", len(planned))))
+
+	if params.DryRun {
+		fmt.Fprintln(w, display.ColorDryRun("DRY RUN: No changes made"))
+		return dryRunResult(params.DetailedExitCode, len(planned))
+	}
+
+	if !params.Force {
+		confirmed, err := confirmFn()
+		if err != nil {
+			return fmt.Errorf("failed to read confirmation: %w", err)
+		}
+		if !confirmed {
+			fmt.Fprintln(w, "Deletion cancelled")
+			return nil
+		}
+	}
+
+	deleter = auditedDeleter{deleter, auditVersions(params.ToDelete)}
+	for i, versionID := range planned {
+		fmt.Fprintf(w, "Deleting version %d/%d (ID: %d)...\n", i+1, len(planned), versionID)
+		err := deleter.DeletePackageVersion(ctx, params.Owner, params.OwnerType, params.PackageName, versionID)
+		if err != nil {
+			outputs := deleteOutputs{Deleted: i, Failed: 1, Reclaimed: reclaimedBytes(params.ToDelete, planned[:i]), SizeKnown: true}
+			if outputErr := outputs.write(ctx); outputErr != nil {
+				return outputErr
+			}
+			if gh.IsLastTaggedVersionError(err) {
+				deleted, fallbackErr := deletePackageIfBlocked(ctx, params.Fallback, params.Owner, params.OwnerType, params.PackageName, planned, w)
+				if fallbackErr != nil {
+					return fallbackErr
+				}
+				if deleted {
+					return nil
+				}
+				fmt.Fprintf(w, "\n%s\n", display.ColorWarning(fmt.Sprintf(
+					"Deleted %d of %d version(s). GHCR does not allow to delete the last tagged version of a package.", i, len(planned))))
+				fmt.Fprintf(w, "You can delete the package instead:\n")
+				fmt.Fprintf(w, "  ghcrctl delete package %s/%s\n", params.Owner, params.PackageName)
+				return fmt.Errorf("stopped after %d of %d version(s): GHCR does not allow to delete the last tagged version of a package", i, len(planned))
+			}
+			return fmt.Errorf("failed to delete version %d: %w", versionID, err)
+		}
+	}
+
+	outputs := deleteOutputs{Deleted: len(planned), Reclaimed: reclaimedBytes(params.ToDelete, planned), SizeKnown: true}
+	if err := outputs.write(ctx); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "\n%s\n",
+		display.ColorSuccess(fmt.Sprintf("Successfully deleted %d version(s) of %s", len(planned), params.PackageName)))
+	printRestoreHint(w, params.Owner, params.OwnerType, params.PackageName)
+	return nil
+}
+
+// graphDeletePlan is the JSON form of a delete graph run
+type graphDeletePlan struct {
+	SchemaVersion int                   `json:"schema_version"`
+	Owner         string                `json:"owner,omitempty"`
+	Package       string                `json:"package"`
+	RootDigest    string                `json:"root_digest"`
+	Tag           string                `json:"tag,omitempty"`
+	DryRun        bool                  `json:"dry_run"`
+	VersionIDs    []int64               `json:"version_ids"` // In deletion order, including duplicates of a digest
+	ToDelete      []graphPlanVersion    `json:"to_delete"`
+	Shared        []graphPlanVersion    `json:"shared"`
+	Results       []versionDeleteResult `json:"results,omitempty"`
+}
+
+// graphPlanVersion is a version in a graphDeletePlan
+type graphPlanVersion struct {
+	ID           int64    `json:"id"`
+	DuplicateIDs []int64  `json:"duplicate_ids,omitempty"`
+	Digest       string   `json:"digest"`
+	Types        []string `json:"types"`
+	Tags         []string `json:"tags"`
+	ExternalRefs int      `json:"external_refs,omitempty"` // Shared versions only: references from outside the graph
+}
+
+// versionDeleteResult is the outcome of deleting one version ID
+type versionDeleteResult struct {
+	ID     int64  `json:"id"`
+	Status string `json:"status"` // deleted, failed, or skipped after an earlier failure
+	Error  string `json:"error,omitempty"`
+}
+
+// resolveGraphRoot returns the root of the graph that contains digest, noting
+// on w when a child digest was resolved to its image
+func resolveGraphRoot(versionMap map[string]discover.VersionInfo, digest string, w io.Writer) (string, error) {
+	root, err := discover.FindRootDigest(versionMap, digest)
+	if err != nil {
+		return "", err
+	}
+	if root != digest {
+		fmt.Fprintf(w, "%s %s belongs to the image %s, deleting its whole graph\n",
+			display.ColorWarning("Note:"), display.ShortDigest(digest), display.ShortDigest(root))
+	}
+	return root, nil
+}
+
+// newGraphDeletePlan builds the JSON plan for deleting the graph rooted at
+// rootDigest from the classification shown by outputDeleteGraphVersions
+func newGraphDeletePlan(packageName, tag, rootDigest string, toDelete, shared, graphVersions []discover.VersionInfo) graphDeletePlan {
+	graphDigests := make(map[string]bool)
+	for _, v := range graphVersions {
+		graphDigests[v.Digest] = true
+	}
+
+	plan := graphDeletePlan{
+		SchemaVersion: display.SchemaVersion,
+		Package:       packageName,
+		RootDigest:    rootDigest,
+		Tag:           tag,
+		VersionIDs:    []int64{},
+		ToDelete:      []graphPlanVersion{},
+		Shared:        []graphPlanVersion{},
+	}
+	for _, v := range toDelete {
+		plan.VersionIDs = append(plan.VersionIDs, v.VersionIDs()...)
+		plan.ToDelete = append(plan.ToDelete, newGraphPlanVersion(v))
+	}
+	for _, v := range shared {
+		pv := newGraphPlanVersion(v)
+		for _, inRef := range v.IncomingRefs {
+			if !graphDigests[inRef] {
+				pv.ExternalRefs++
+			}
+		}
+		plan.Shared = append(plan.Shared, pv)
+	}
+	return plan
+}
+
+func newGraphPlanVersion(v discover.VersionInfo) graphPlanVersion {
+	pv := graphPlanVersion{ID: v.ID, DuplicateIDs: v.DuplicateIDs, Digest: v.Digest, Types: v.Types, Tags: v.Tags}
+	if pv.Types == nil {
+		pv.Types = []string{}
+	}
+	if pv.Tags == nil {
+		pv.Tags = []string{}
+	}
+	return pv
+}
+
+// deleteGraphVersionsWithResults deletes versions in order like
+// deleteGraphWithDeleter, but records the outcome of each version ID instead of
+// printing progress. Versions after a failure are skipped.
+func deleteGraphVersionsWithResults(ctx context.Context, deleter packageDeleter, owner, ownerType, packageName string, versionIDs []int64) ([]versionDeleteResult, error) {
+	results := make([]versionDeleteResult, 0, len(versionIDs))
+	var firstErr error
+	for _, versionID := range versionIDs {
+		if firstErr != nil {
+			results = append(results, versionDeleteResult{ID: versionID, Status: "skipped"})
+			continue
+		}
+		if err := deleter.DeletePackageVersion(ctx, owner, ownerType, packageName, versionID); err != nil {
+			firstErr = fmt.Errorf("failed to delete version %d: %w", versionID, err)
+			results = append(results, versionDeleteResult{ID: versionID, Status: "failed", Error: err.Error()})
+			continue
+		}
+		results = append(results, versionDeleteResult{ID: versionID, Status: "deleted"})
+	}
+	return results, firstErr
+}
+
 // deleteGraphWithDeleter deletes versions using a deleter interface
 func deleteGraphWithDeleter(ctx context.Context, deleter packageDeleter, owner, ownerType, packageName string, versionIDs []int64, w io.Writer) error {
 	for i, versionID := range versionIDs {
@@ -961,7 +2514,12 @@ func executeSingleDelete(ctx context.Context, deleter packageDeleter, params del
 		return fmt.Errorf("failed to delete package version: %w", err)
 	}
 
+	if err := recordDeleted(ctx, audit.Version{ID: params.VersionID, Digest: params.Digest, Tags: params.Tags}); err != nil {
+		return err
+	}
+
 	fmt.Fprintln(w, display.ColorSuccess(fmt.Sprintf("Successfully deleted version %d of %s", params.VersionID, params.PackageName)))
+	printRestoreHint(w, params.Owner, params.OwnerType, params.PackageName)
 	return nil
 }
 
@@ -988,7 +2546,10 @@ func executeBulkDelete(ctx context.Context, deleter packageDeleter, params bulkD
 	// Handle dry-run
 	if params.DryRun {
 		fmt.Fprintln(w, display.ColorDryRun("DRY RUN: No changes made"))
-		return nil
+		if err := appendDeleteSummary(ctx, params.PackageName, params.Versions, nil); err != nil {
+			return err
+		}
+		return deleteOutputs{WouldDelete: len(params.Versions)}.write(ctx)
 	}
 
 	// Confirm deletion unless --force is used
@@ -1006,16 +2567,27 @@ func executeBulkDelete(ctx context.Context, deleter packageDeleter, params bulkD
 	// Perform bulk deletion
 	successCount := 0
 	failCount := 0
+	results := make([]error, len(params.Versions))
 	for i, ver := range params.Versions {
 		fmt.Fprintf(w, "Deleting version %d/%d (ID: %d)...\n", i+1, len(params.Versions), ver.ID)
 		err := deleter.DeletePackageVersion(ctx, params.Owner, params.OwnerType, params.PackageName, ver.ID)
+		results[i] = err
 		if err != nil {
 			fmt.Fprintf(w, "  %s\n", display.ColorError(fmt.Sprintf("Failed: %v", err)))
 			failCount++
 		} else {
 			successCount++
+			if err := recordDeleted(ctx, audit.Version{ID: ver.ID, Digest: ver.Digest, Tags: ver.Tags}); err != nil {
+				return err
+			}
 		}
 	}
+	if err := appendDeleteSummary(ctx, params.PackageName, params.Versions, results); err != nil {
+		return err
+	}
+	if err := (deleteOutputs{Deleted: successCount, Failed: failCount}).write(ctx); err != nil {
+		return err
+	}
 
 	// Summary
 	fmt.Fprintln(w)
@@ -1027,6 +2599,9 @@ func executeBulkDelete(ctx context.Context, deleter packageDeleter, params bulkD
 		fmt.Fprintf(w, "Deletion complete: %s succeeded\n",
 			display.ColorSuccess(fmt.Sprintf("%d", successCount)))
 	}
+	if successCount > 0 {
+		printRestoreHint(w, params.Owner, params.OwnerType, params.PackageName)
+	}
 
 	if failCount > 0 {
 		return fmt.Errorf("failed to delete %d version(s)", failCount)
@@ -1035,18 +2610,178 @@ func executeBulkDelete(ctx context.Context, deleter packageDeleter, params bulkD
 	return nil
 }
 
-// deleteVersionsInOrder deletes versions in the correct order
-func deleteVersionsInOrder(ctx context.Context, client *gh.Client, owner, ownerType, packageName string, versionIDs []int64, w io.Writer) error {
+// appendDeleteSummary adds the versions of a bulk delete to the GitHub Actions
+// job summary, if --github-summary is set. results holds the error of each
+// deletion; a nil results slice marks a dry run.
+func appendDeleteSummary(ctx context.Context, packageName string, versions []gh.PackageVersionInfo, results []error) error {
+	title := fmt.Sprintf("Deleted versions of %s", packageName)
+	if results == nil {
+		title = fmt.Sprintf("Versions of %s that would be deleted (dry run)", packageName)
+	}
+
+	rows := make([][]string, len(versions))
+	for i, ver := range versions {
+		result := "would delete"
+		if results != nil {
+			result = "deleted"
+			if results[i] != nil {
+				result = fmt.Sprintf("failed: %v", results[i])
+			}
+		}
+		rows[i] = []string{fmt.Sprintf("%d", ver.ID), display.ShortDigest(ver.Digest),
+			strings.Join(ver.Tags, ", "), ver.CreatedAt, result}
+	}
+	return display.AppendStepSummary(ctx, title, []string{"Version ID", "Digest", "Tags", "Created", "Result"}, rows)
+}
+
+// deleteVersionsInOrder deletes versions in the correct order, stopping at the
+// first failure. It returns the number of versions deleted.
+func deleteVersionsInOrder(ctx context.Context, client packageDeleter, owner, ownerType, packageName string, versionIDs []int64, w io.Writer) (int, error) {
 	for i, versionID := range versionIDs {
 		fmt.Fprintf(w, "Deleting version %d/%d (ID: %d)...\n", i+1, len(versionIDs), versionID)
 		err := client.DeletePackageVersion(ctx, owner, ownerType, packageName, versionID)
 		if err != nil {
-			return fmt.Errorf("failed to delete version %d: %w", versionID, err)
+			return i, fmt.Errorf("failed to delete version %d: %w", versionID, err)
+		}
+	}
+	return len(versionIDs), nil
+}
+
+// enableGitHubOutput handles -o github-actions for delete commands: it checks
+// that GITHUB_OUTPUT is set and makes the command context write step outputs
+// to it
+func enableGitHubOutput(cmd *cobra.Command, outputFormat string) error {
+	mode, err := display.ParseOutputMode(outputFormat, display.OutputModeGitHubActions)
+	if err != nil || mode == "" {
+		return err
+	}
+	path := os.Getenv("GITHUB_OUTPUT")
+	if path == "" {
+		return fmt.Errorf("-o github-actions requires GITHUB_OUTPUT to be set (as in GitHub Actions)")
+	}
+	cmd.SetContext(display.WithGitHubOutput(cmd.Context(), path))
+	return nil
+}
+
+// addMetricsFileFlag adds the --metrics-file flag of delete commands
+func addMetricsFileFlag(cmd *cobra.Command, target *string) {
+	cmd.Flags().StringVar(target, "metrics-file", "", "Write the result as Prometheus metrics to this file (for the node_exporter textfile collector)")
+}
+
+// deleteOutputs are the step outputs of a delete command with -o github-actions,
+// and the metrics written with --metrics-file
+type deleteOutputs struct {
+	Deleted     int
+	Failed      int
+	WouldDelete int    // Versions a dry run would delete
+	Digest      string // Resolved digest of the deleted version or graph root, if known
+	Reclaimed   int64  // Bytes of the deleted versions, if SizeKnown
+	SizeKnown   bool   // The sizes of the deleted versions were discovered
+}
+
+// reclaimedBytes sums the sizes of the versions whose IDs were deleted,
+// counting a digest with several version IDs once
+func reclaimedBytes(versions []discover.VersionInfo, deletedIDs []int64) int64 {
+	byID := make(map[int64]discover.VersionInfo)
+	for _, v := range versions {
+		for _, id := range v.VersionIDs() {
+			byID[id] = v
+		}
+	}
+	counted := make(map[string]bool)
+	var total int64
+	for _, id := range deletedIDs {
+		if v, ok := byID[id]; ok && !counted[v.Digest] {
+			counted[v.Digest] = true
+			total += v.Size
+		}
+	}
+	return total
+}
+
+// write appends the outputs to the GITHUB_OUTPUT file, if -o github-actions is
+// set, and writes the metrics file, if --metrics-file is set
+func (o deleteOutputs) write(ctx context.Context) error {
+	outputs := map[string]string{
+		"deleted_count":      strconv.Itoa(o.Deleted),
+		"failed_count":       strconv.Itoa(o.Failed),
+		"would_delete_count": strconv.Itoa(o.WouldDelete),
+	}
+	if o.Digest != "" {
+		outputs["digest"] = o.Digest
+	}
+	if err := display.AppendGitHubOutputs(ctx, outputs); err != nil {
+		return err
+	}
+	return metrics.Record(ctx, metrics.Run{
+		Deleted:        o.Deleted,
+		Failed:         o.Failed,
+		BytesReclaimed: o.Reclaimed,
+		SizeKnown:      o.SizeKnown,
+	})
+}
+
+// verifyDeleted lists the package again and fails if any of the deleted
+// version IDs is still listed, e.g. because the registry has not caught up
+// yet. A package that no longer exists counts as verified.
+func verifyDeleted(ctx context.Context, lister versionLister, owner, ownerType, packageName string, versionIDs []int64, w io.Writer) error {
+	versions, err := lister.ListPackageVersions(ctx, owner, ownerType, packageName)
+	if err != nil {
+		if gh.IsNotFound(err) {
+			fmt.Fprintf(w, "Verified: package %s no longer exists\n", packageName)
+			return nil
+		}
+		return fmt.Errorf("failed to verify deletion: %w", err)
+	}
+
+	deleted := make(map[int64]bool, len(versionIDs))
+	for _, id := range versionIDs {
+		deleted[id] = true
+	}
+	var remaining []string
+	for _, ver := range versions {
+		if deleted[ver.ID] {
+			remaining = append(remaining, fmt.Sprintf("%d", ver.ID))
 		}
 	}
+	if len(remaining) > 0 {
+		return fmt.Errorf("verification failed: %d deleted version(s) of %s are still listed: %s",
+			len(remaining), packageName, strings.Join(remaining, ", "))
+	}
+
+	fmt.Fprintf(w, "Verified: %d deleted version(s) are no longer listed\n", len(versionIDs))
 	return nil
 }
 
+// findSingleVersion lists the versions of a package once and finds the version
+// with the given ID, or with the given full or short digest if versionID is
+// zero. A short digest is resolved like resolveDigestPrefix does. The listing
+// is returned as well, so that callers need no further API calls for the tags
+// of the version or for the versions that reference it.
+func findSingleVersion(ctx context.Context, lister versionLister, owner, ownerType, packageName string, versionID int64, digest string, strictDigest bool, warn io.Writer) (gh.PackageVersionInfo, []gh.PackageVersionInfo, error) {
+	allVersions, err := lister.ListPackageVersions(ctx, owner, ownerType, packageName)
+	if err != nil {
+		return gh.PackageVersionInfo{}, nil, fmt.Errorf("failed to list package versions: %w", err)
+	}
+	if versionID == 0 {
+		resolved, err := resolveDigestPrefix(allVersions, digest, strictDigest, warn)
+		var ambiguous *discover.AmbiguousDigestError
+		if errors.As(err, &ambiguous) {
+			return gh.PackageVersionInfo{}, nil, err
+		}
+		if err != nil {
+			return gh.PackageVersionInfo{}, nil, fmt.Errorf("version with digest %s %w", digest, gh.ErrNotFound)
+		}
+		digest = resolved
+	}
+	for _, ver := range allVersions {
+		if (versionID != 0 && ver.ID == versionID) || (versionID == 0 && ver.Digest == digest) {
+			return ver, allVersions, nil
+		}
+	}
+	return gh.PackageVersionInfo{}, nil, fmt.Errorf("version ID %d %w", versionID, gh.ErrNotFound)
+}
+
 // countIncomingRefs returns how many other versions reference the given version ID.
 func countIncomingRefs(ctx context.Context, client *gh.Client, owner, ownerType, packageName string, versionID int64) int {
 	// Get all versions for this package
@@ -1056,10 +2791,14 @@ func countIncomingRefs(ctx context.Context, client *gh.Client, owner, ownerType,
 	}
 
 	ociRef := fmt.Sprintf("ghcr.io/%s/%s", owner, packageName)
+	return countIncomingRefsIn(ctx, discover.NewPackageDiscoverer(), ociRef, allVersions, versionID)
+}
 
+// countIncomingRefsIn returns how many versions in allVersions reference the
+// given version ID, without listing the package again.
+func countIncomingRefsIn(ctx context.Context, discoverer graphDiscoverer, image string, allVersions []gh.PackageVersionInfo, versionID int64) int {
 	// Use discover package to get version relationships
-	discoverer := discover.NewPackageDiscoverer()
-	versions, err := discoverer.DiscoverPackage(ctx, ociRef, allVersions, nil)
+	versions, err := discoverer.DiscoverPackage(ctx, image, allVersions, nil)
 	if err != nil {
 		return 0
 	}
@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mkoepf/ghcrctl/internal/audit"
+	"github.com/mkoepf/ghcrctl/internal/discover"
+	"github.com/mkoepf/ghcrctl/internal/gh"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// actorLooker finds the user the token belongs to
+type actorLooker interface {
+	WhoAmI(ctx context.Context) (gh.TokenInfo, error)
+}
+
+// auditIgnoredFlags only change how a delete command runs, not which versions
+// it selects, and are left out of the selector in the audit log
+var auditIgnoredFlags = map[string]bool{
+	"archive":                   true,
+	"archive-manifests":         true,
+	"atomic":                    true,
+	"audit-log":                 true,
+	"batch-size":                true,
+	"confirm-digest":            true,
+	"delete-package-if-blocked": true,
+	"detailed-exitcode":         true,
+	"dry-run":                   true,
+	"force":                     true,
+	"json":                      true,
+	"metrics-file":              true,
+	"output":                    true,
+	"plan-file":                 true,
+	"strict":                    true,
+	"verify":                    true,
+	"yes":                       true,
+}
+
+// addAuditLogFlag adds the --audit-log flag of delete commands
+func addAuditLogFlag(cmd *cobra.Command, target *string) {
+	cmd.Flags().StringVar(target, "audit-log", "", "Append a JSON line for every deleted version to this file")
+}
+
+// enableAuditLog handles --audit-log for delete commands: it looks up the
+// actor, checks that the log can be written, and makes the command context
+// record every deleted version in it. It fails before anything is deleted.
+func enableAuditLog(cmd *cobra.Command, client actorLooker, path, owner, packageName string) error {
+	actor, err := auditActor(cmd.Context(), client)
+	if err != nil {
+		return err
+	}
+	if err := audit.Append(path); err != nil {
+		return err
+	}
+	cmd.SetContext(audit.WithLog(cmd.Context(), audit.Log{
+		Path:     path,
+		Actor:    actor,
+		Owner:    owner,
+		Package:  packageName,
+		Selector: auditSelector(cmd),
+	}))
+	return nil
+}
+
+// auditActor returns the login of the user the token belongs to. Tokens that
+// cannot look up their user, like the GITHUB_TOKEN of GitHub Actions, fall
+// back to the user that triggered the workflow.
+func auditActor(ctx context.Context, client actorLooker) (string, error) {
+	info, err := client.WhoAmI(ctx)
+	if err == nil && info.Login != "" {
+		return info.Login, nil
+	}
+	if actor := os.Getenv("GITHUB_ACTOR"); actor != "" {
+		return actor, nil
+	}
+	if err == nil {
+		err = fmt.Errorf("the token has no user")
+	}
+	return "", fmt.Errorf("failed to determine the actor for the audit log: %w", err)
+}
+
+// auditSelector describes how a delete command selected its versions: the
+// command path without the root, followed by the selecting flags that were set,
+// e.g. "delete version --older-than=30d --untagged"
+func auditSelector(cmd *cobra.Command) string {
+	parts := []string{strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")}
+	local := cmd.LocalFlags()
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		if auditIgnoredFlags[f.Name] || local.Lookup(f.Name) == nil {
+			return
+		}
+		value := f.Value.String()
+		if slice, ok := f.Value.(pflag.SliceValue); ok {
+			value = strings.Join(slice.GetSlice(), ",")
+		}
+		if f.Value.Type() == "bool" && value == "true" {
+			parts = append(parts, "--"+f.Name)
+			return
+		}
+		parts = append(parts, fmt.Sprintf("--%s=%s", f.Name, value))
+	})
+	return strings.Join(parts, " ")
+}
+
+// recordDeleted appends a deleted version to the audit log, if --audit-log is
+// set
+func recordDeleted(ctx context.Context, v audit.Version) error {
+	if err := audit.Record(ctx, v); err != nil {
+		return fmt.Errorf("version %d was deleted, but %w", v.ID, err)
+	}
+	return nil
+}
+
+// recordPackageDeleted appends the versions of a deleted package to the audit
+// log, if --audit-log is set
+func recordPackageDeleted(ctx context.Context, owner, packageName string, versions []gh.PackageVersionInfo) error {
+	deleted := make([]audit.Version, 0, len(versions))
+	for _, v := range versions {
+		deleted = append(deleted, audit.Version{ID: v.ID, Digest: v.Digest, Tags: v.Tags})
+	}
+	if err := audit.Record(ctx, deleted...); err != nil {
+		return fmt.Errorf("package %s/%s was deleted, but %w", owner, packageName, err)
+	}
+	return nil
+}
+
+// auditVersions maps every version ID of versions, including duplicate IDs of
+// a digest, to its audit record
+func auditVersions(versions []discover.VersionInfo) map[int64]audit.Version {
+	byID := make(map[int64]audit.Version)
+	for _, v := range versions {
+		for _, id := range v.VersionIDs() {
+			byID[id] = audit.Version{ID: id, Digest: v.Digest, Tags: v.Tags}
+		}
+	}
+	return byID
+}
+
+// auditedDeleter records every version its deleter deletes in the audit log,
+// for deletion paths that only know version IDs
+type auditedDeleter struct {
+	packageDeleter
+	versions map[int64]audit.Version
+}
+
+func (d auditedDeleter) DeletePackageVersion(ctx context.Context, owner, ownerType, packageName string, versionID int64) error {
+	if err := d.packageDeleter.DeletePackageVersion(ctx, owner, ownerType, packageName, versionID); err != nil {
+		return err
+	}
+	v, ok := d.versions[versionID]
+	if !ok {
+		v = audit.Version{ID: versionID}
+	}
+	return recordDeleted(ctx, v)
+}
@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mkoepf/ghcrctl/internal/audit"
+	"github.com/mkoepf/ghcrctl/internal/discover"
+	"github.com/mkoepf/ghcrctl/internal/gh"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// auditContext returns a context that records deletions in a new audit log
+func auditContext(t *testing.T) (context.Context, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "audit.ndjson")
+	return audit.WithLog(context.Background(), audit.Log{
+		Path:     path,
+		Actor:    "octocat",
+		Owner:    "mkoepf",
+		Package:  "myimage",
+		Selector: "delete version --untagged",
+	}), path
+}
+
+// readAuditLog reads the entries of the audit log at path
+func readAuditLog(t *testing.T, path string) []audit.Entry {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var entries []audit.Entry
+	for _, line := range strings.Split(strings.TrimSpace(string(content)), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry audit.Entry
+		require.NoError(t, json.Unmarshal([]byte(line), &entry), line)
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestExecuteBulkDelete_AuditLog(t *testing.T) {
+	t.Parallel()
+	ctx, path := auditContext(t)
+	mock := newMockPackageDeleter()
+	mock.deleteErrors[101] = fmt.Errorf("permission denied")
+
+	err := ExecuteBulkDelete(ctx, mock, BulkDeleteParams{
+		Owner:       "mkoepf",
+		OwnerType:   "user",
+		PackageName: "myimage",
+		Versions: []gh.PackageVersionInfo{
+			{ID: 100, Digest: "sha256:aaa", Tags: []string{"v1"}},
+			{ID: 101, Digest: "sha256:bbb"},
+			{ID: 102, Digest: "sha256:ccc"},
+		},
+		Force: true,
+	}, io.Discard, nil)
+	require.Error(t, err)
+
+	entries := readAuditLog(t, path)
+	require.Len(t, entries, 2, "one line per successfully deleted version")
+	assert.NotEmpty(t, entries[0].Timestamp)
+	entries[0].Timestamp = ""
+	assert.Equal(t, audit.Entry{
+		Actor:     "octocat",
+		Owner:     "mkoepf",
+		Package:   "myimage",
+		VersionID: 100,
+		Digest:    "sha256:aaa",
+		Tags:      []string{"v1"},
+		Selector:  "delete version --untagged",
+	}, entries[0])
+	assert.Equal(t, int64(102), entries[1].VersionID)
+	assert.Equal(t, "sha256:ccc", entries[1].Digest)
+	assert.Equal(t, []string{}, entries[1].Tags)
+}
+
+func TestExecuteDeleteAllTags_AuditLog(t *testing.T) {
+	t.Parallel()
+	ctx, path := auditContext(t)
+
+	// Both version IDs of the index are recorded with its digest
+	versions := []discover.VersionInfo{
+		{ID: 10, DuplicateIDs: []int64{11}, Digest: "sha256:index", Types: []string{"index"}, Tags: []string{"v1"}, OutgoingRefs: []string{"sha256:amd64"}},
+		{ID: 1, Digest: "sha256:amd64", Types: []string{"linux/amd64"}, IncomingRefs: []string{"sha256:index"}},
+	}
+	tagged, toDelete, shared := planDeleteAllTags(versions)
+	mock := newMockPackageDeleter()
+	mock.deleteErrors[11] = fmt.Errorf("permission denied")
+
+	err := executeDeleteAllTags(ctx, mock, deleteAllTagsParams{
+		Owner: "mkoepf", OwnerType: "user", PackageName: "myimage",
+		Tagged: tagged, ToDelete: toDelete, Shared: shared, Force: true,
+	}, io.Discard, nil)
+	require.Error(t, err)
+
+	var recorded []audit.Version
+	for _, entry := range readAuditLog(t, path) {
+		recorded = append(recorded, audit.Version{ID: entry.VersionID, Digest: entry.Digest, Tags: entry.Tags})
+	}
+	assert.ElementsMatch(t, []audit.Version{
+		{ID: 1, Digest: "sha256:amd64", Tags: []string{}},
+		{ID: 10, Digest: "sha256:index", Tags: []string{"v1"}},
+	}, recorded, "only the deleted versions are recorded")
+}
+
+func TestExecuteApplyGraphPlan_AuditLog(t *testing.T) {
+	t.Parallel()
+	ctx, path := auditContext(t)
+	client := graphPlanFake{&mockVersionLister{versions: sharedPlatformPackage()}, newMockPackageDeleter()}
+
+	err := executeApplyGraphPlan(ctx, client, sharedPlatformRefs(), sharedPlatformPlan(), applyPlanParams{
+		Owner: "mkoepf", OwnerType: "user", PackageName: "myimage", Force: true,
+	}, io.Discard, nil)
+	require.NoError(t, err)
+
+	var ids []int64
+	for _, entry := range readAuditLog(t, path) {
+		ids = append(ids, entry.VersionID)
+		assert.NotEmpty(t, entry.Digest)
+	}
+	assert.Equal(t, client.deletedVersions, ids)
+}
+
+func TestExecuteDeletePackage_AuditLog(t *testing.T) {
+	t.Parallel()
+	ctx, path := auditContext(t)
+
+	err := executeDeletePackage(ctx, &mockPackageRemover{}, deletePackageParams{
+		Owner: "mkoepf", OwnerType: "user", PackageName: "myimage", Force: true,
+		Versions: []gh.PackageVersionInfo{{ID: 1, Digest: "sha256:aaa", Tags: []string{"latest"}}, {ID: 2, Digest: "sha256:bbb"}},
+	}, io.Discard, nil)
+	require.NoError(t, err)
+
+	entries := readAuditLog(t, path)
+	require.Len(t, entries, 2)
+	assert.Equal(t, int64(1), entries[0].VersionID)
+	assert.Equal(t, []string{"latest"}, entries[0].Tags)
+	assert.Equal(t, int64(2), entries[1].VersionID)
+}
+
+func TestAuditSelector(t *testing.T) {
+	t.Parallel()
+	root := NewRootCmd()
+	cmd, _, err := root.Find([]string{"delete", "version"})
+	require.NoError(t, err)
+	require.NoError(t, cmd.ParseFlags([]string{
+		"--untagged", "--older-than", "30d", "--exclude-version", "1,2", "--force", "--audit-log", "audit.ndjson",
+	}))
+
+	assert.Equal(t, "delete version --exclude-version=1,2 --older-than=30d --untagged", auditSelector(cmd))
+}
+
+// fakeActorLooker answers WhoAmI with a fixed user or error
+type fakeActorLooker struct {
+	login string
+	err   error
+}
+
+func (f fakeActorLooker) WhoAmI(ctx context.Context) (gh.TokenInfo, error) {
+	return gh.TokenInfo{Login: f.login}, f.err
+}
+
+func TestAuditActor(t *testing.T) {
+	t.Setenv("GITHUB_ACTOR", "")
+
+	actor, err := auditActor(context.Background(), fakeActorLooker{login: "octocat"})
+	require.NoError(t, err)
+	assert.Equal(t, "octocat", actor)
+
+	lookupErr := fmt.Errorf("403 Resource not accessible by integration")
+	_, err = auditActor(context.Background(), fakeActorLooker{err: lookupErr})
+	assert.ErrorContains(t, err, "failed to determine the actor for the audit log: 403")
+
+	t.Setenv("GITHUB_ACTOR", "workflow-user")
+	actor, err = auditActor(context.Background(), fakeActorLooker{err: lookupErr})
+	require.NoError(t, err)
+	assert.Equal(t, "workflow-user", actor)
+}
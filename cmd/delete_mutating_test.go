@@ -44,7 +44,7 @@ func TestDeletePackage_Mutating(t *testing.T) {
 	require.NoError(t, err, "Failed to create client")
 
 	_, err = client.ListPackageVersions(ctx, testOwner, "user", ephemeralName)
-	assert.Error(t, err, "Expected error when listing versions of deleted package, got none")
+	assert.True(t, gh.IsNotFound(err), "Expected not found when listing versions of deleted package, got %v", err)
 
 	t.Logf("Successfully deleted package %s", ephemeralName)
 }
@@ -411,7 +411,7 @@ func TestDeletePackageCmd(t *testing.T) {
 	require.NoError(t, err, "Failed to create client")
 
 	_, err = client.ListPackageVersions(ctx, testOwner, "user", ephemeralName)
-	assert.Error(t, err, "Expected error when listing deleted package")
+	assert.True(t, gh.IsNotFound(err), "Expected not found when listing deleted package, got %v", err)
 
 	t.Logf("Successfully verified package deletion via CLI")
 }
@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/mkoepf/ghcrctl/internal/audit"
+	"github.com/mkoepf/ghcrctl/internal/discover"
+	"github.com/mkoepf/ghcrctl/internal/display"
+	"github.com/mkoepf/ghcrctl/internal/gh"
+)
+
+// graphPlanClient lists the versions of a package to check a plan file for
+// drift, and deletes the planned versions
+type graphPlanClient interface {
+	versionLister
+	packageDeleter
+}
+
+// applyPlanParams contains parameters for delete graph --apply-plan
+type applyPlanParams struct {
+	Owner       string
+	OwnerType   string
+	PackageName string
+	Image       string // OCI reference of the package, for checking references
+	Force       bool
+	DryRun      bool
+}
+
+// writePlanFile writes the plan of a delete graph dry run to path, for
+// --apply-plan to execute later
+func writePlanFile(path string, plan graphDeletePlan) error {
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal plan: %w", err)
+	}
+	data = append(data, '\n')
+
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write plan %s: %w", path, err)
+	}
+	return nil
+}
+
+// readPlanFile reads a plan written with --plan-file
+func readPlanFile(path string) (graphDeletePlan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return graphDeletePlan{}, fmt.Errorf("failed to read plan: %w", err)
+	}
+
+	var plan graphDeletePlan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return graphDeletePlan{}, fmt.Errorf("failed to parse plan %s: %w", path, err)
+	}
+	if plan.SchemaVersion != display.SchemaVersion {
+		return graphDeletePlan{}, fmt.Errorf("plan %s has schema_version %d, expected %d", path, plan.SchemaVersion, display.SchemaVersion)
+	}
+	if plan.Package == "" || len(plan.VersionIDs) == 0 {
+		return graphDeletePlan{}, fmt.Errorf("plan %s lists no package or no versions to delete", path)
+	}
+	return plan, nil
+}
+
+// checkPlanDrift checks that plan was made for owner/packageName and that every
+// planned version ID still exists with the planned digest. All differences are
+// reported together.
+func checkPlanDrift(plan graphDeletePlan, owner, packageName string, current []gh.PackageVersionInfo) error {
+	if plan.Owner == "" {
+		return fmt.Errorf("the plan names no owner, so it cannot be checked against %s/%s; write it again with --plan-file", owner, packageName)
+	}
+	if plan.Package != packageName || !strings.EqualFold(plan.Owner, owner) {
+		return fmt.Errorf("the plan is for %s/%s, not %s/%s", plan.Owner, plan.Package, owner, packageName)
+	}
+
+	planned := make(map[int64]string)
+	for _, v := range plan.ToDelete {
+		planned[v.ID] = v.Digest
+		for _, id := range v.DuplicateIDs {
+			planned[id] = v.Digest
+		}
+	}
+	digests := make(map[int64]string, len(current))
+	for _, v := range current {
+		digests[v.ID] = v.Digest
+	}
+
+	var drift []string
+	for _, id := range plan.VersionIDs {
+		want, ok := planned[id]
+		if !ok {
+			return fmt.Errorf("the plan is inconsistent: version %d is not listed in to_delete", id)
+		}
+		got, exists := digests[id]
+		switch {
+		case !exists:
+			drift = append(drift, fmt.Sprintf("version %d (%s) no longer exists", id, display.ShortDigest(want)))
+		case got != want:
+			drift = append(drift, fmt.Sprintf("version %d has digest %s, the plan has %s", id, display.ShortDigest(got), display.ShortDigest(want)))
+		}
+	}
+	if len(drift) > 0 {
+		return fmt.Errorf("the package changed since the plan was made, nothing deleted:\n  %s", strings.Join(drift, "\n  "))
+	}
+	return nil
+}
+
+// checkPlanReferences checks that no planned version is referenced by a version
+// the plan does not delete, e.g. by an image pushed since the plan was made that
+// reuses a planned platform manifest, or by a new signature of the root. All
+// such references are reported together.
+func checkPlanReferences(plan graphDeletePlan, current []discover.VersionInfo) error {
+	planned := make(map[string]bool, len(plan.ToDelete))
+	for _, v := range plan.ToDelete {
+		planned[v.Digest] = true
+	}
+
+	var referenced []string
+	for _, v := range current {
+		if !planned[v.Digest] {
+			continue
+		}
+		for _, ref := range v.IncomingRefs {
+			if !planned[ref] {
+				referenced = append(referenced, fmt.Sprintf("version %d (%s) is referenced by %s",
+					v.ID, display.ShortDigest(v.Digest), display.ShortDigest(ref)))
+			}
+		}
+	}
+	if len(referenced) > 0 {
+		return fmt.Errorf("the plan would break versions it does not delete, nothing deleted:\n  %s", strings.Join(referenced, "\n  "))
+	}
+	return nil
+}
+
+// planAuditVersions maps every planned version ID to its audit record
+func planAuditVersions(plan graphDeletePlan) map[int64]audit.Version {
+	byID := make(map[int64]audit.Version)
+	for _, v := range plan.ToDelete {
+		for _, id := range append([]int64{v.ID}, v.DuplicateIDs...) {
+			byID[id] = audit.Version{ID: id, Digest: v.Digest, Tags: v.Tags}
+		}
+	}
+	return byID
+}
+
+// executeApplyGraphPlan deletes exactly the versions of a plan file, in the
+// planned order, after checking that none of them changed and that none of
+// them is now referenced from outside the plan
+func executeApplyGraphPlan(ctx context.Context, client graphPlanClient, discoverer graphDiscoverer, plan graphDeletePlan, params applyPlanParams, out io.Writer, confirm func() (bool, error)) error {
+	current, err := client.ListPackageVersions(ctx, params.Owner, params.OwnerType, params.PackageName)
+	if err != nil {
+		return fmt.Errorf("failed to list package versions: %w", err)
+	}
+	if err := checkPlanDrift(plan, params.Owner, params.PackageName, current); err != nil {
+		return err
+	}
+
+	var allTags []string
+	for _, v := range current {
+		allTags = append(allTags, v.Tags...)
+	}
+	discovered, err := discoverer.DiscoverPackage(ctx, params.Image, current, allTags)
+	if err != nil {
+		return fmt.Errorf("failed to check the references of the plan: %w", err)
+	}
+	if err := checkPlanReferences(plan, discovered); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Applying plan for %s (root %s):\n", params.PackageName, display.ShortDigest(plan.RootDigest))
+	for _, v := range plan.ToDelete {
+		fmt.Fprintf(out, "  - version %d %s%s\n", v.ID, display.ShortDigest(v.Digest), formatVersionTags(v.Tags))
+	}
+	fmt.Fprintf(out, "\nTotal: %s version(s) will be deleted, all unchanged and unreferenced since the plan was made\n\n",
+		display.ColorWarning(fmt.Sprintf("%d", len(plan.VersionIDs))))
+
+	if params.DryRun {
+		fmt.Fprintln(out, display.ColorDryRun("DRY RUN: No changes made"))
+		return nil
+	}
+
+	if !params.Force {
+		confirmed, err := confirm()
+		if err != nil {
+			return fmt.Errorf("failed to read confirmation: %w", err)
+		}
+		if !confirmed {
+			fmt.Fprintln(out, "Deletion cancelled")
+			return nil
+		}
+	}
+
+	if _, err := deleteVersionsInOrder(ctx, auditedDeleter{client, planAuditVersions(plan)}, params.Owner, params.OwnerType, params.PackageName, plan.VersionIDs, out); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "\n%s\n",
+		display.ColorSuccess(fmt.Sprintf("Successfully deleted %d version(s) of %s", len(plan.VersionIDs), params.PackageName)))
+	printRestoreHint(out, params.Owner, params.OwnerType, params.PackageName)
+	return nil
+}
@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mkoepf/ghcrctl/internal/discover"
+	"github.com/mkoepf/ghcrctl/internal/gh"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// graphPlanFake serves the current versions of a package and records deletions
+type graphPlanFake struct {
+	*mockVersionLister
+	*mockPackageDeleter
+}
+
+// sharedPlatformPlan is the plan for deleting the v1 graph of sharedPlatformGraphs
+func sharedPlatformPlan() graphDeletePlan {
+	graphVersions := discover.FindGraphByDigest(discover.ToMap(sharedPlatformGraphs()), "sha256:index1")
+	toDelete, shared := discover.ClassifyGraphVersions(graphVersions)
+	plan := newGraphDeletePlan("myimage", "v1", "sha256:index1", toDelete, shared, graphVersions)
+	plan.Owner = "mkoepf"
+	plan.DryRun = true
+	return plan
+}
+
+// sharedPlatformPackage lists the versions of sharedPlatformGraphs as GitHub does
+func sharedPlatformPackage() []gh.PackageVersionInfo {
+	var versions []gh.PackageVersionInfo
+	for _, v := range sharedPlatformGraphs() {
+		for _, id := range v.VersionIDs() {
+			versions = append(versions, gh.PackageVersionInfo{ID: id, Digest: v.Digest, Tags: v.Tags})
+		}
+	}
+	return versions
+}
+
+// sharedPlatformRefs discovers sharedPlatformPackage with its current references
+func sharedPlatformRefs() incomingRefsDiscoverer {
+	refs := make(map[string][]string)
+	for _, v := range sharedPlatformGraphs() {
+		refs[v.Digest] = v.IncomingRefs
+	}
+	return incomingRefsDiscoverer{refs: refs}
+}
+
+func TestPlanFile_RoundTrip(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "plan.json")
+	plan := sharedPlatformPlan()
+
+	require.NoError(t, writePlanFile(path, plan))
+	read, err := readPlanFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, plan, read)
+}
+
+func TestReadPlanFile_Invalid(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "not JSON", content: "plan", wantErr: "failed to parse plan"},
+		{name: "other schema", content: `{"schema_version": 2, "package": "myimage", "version_ids": [1]}`, wantErr: "has schema_version 2, expected 1"},
+		{name: "no versions", content: `{"schema_version": 1, "package": "myimage", "version_ids": []}`, wantErr: "lists no package or no versions"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			path := filepath.Join(dir, strings.ReplaceAll(tt.name, " ", "-")+".json")
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0o644))
+			_, err := readPlanFile(path)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestCheckPlanDrift(t *testing.T) {
+	t.Parallel()
+	plan := sharedPlatformPlan()
+
+	assert.NoError(t, checkPlanDrift(plan, "mkoepf", "myimage", sharedPlatformPackage()))
+
+	err := checkPlanDrift(plan, "mkoepf", "other", sharedPlatformPackage())
+	assert.EqualError(t, err, "the plan is for mkoepf/myimage, not mkoepf/other")
+
+	err = checkPlanDrift(plan, "someone-else", "myimage", sharedPlatformPackage())
+	assert.EqualError(t, err, "the plan is for mkoepf/myimage, not someone-else/myimage")
+
+	ownerless := sharedPlatformPlan()
+	ownerless.Owner = ""
+	err = checkPlanDrift(ownerless, "mkoepf", "myimage", sharedPlatformPackage())
+	assert.ErrorContains(t, err, "the plan names no owner")
+
+	// Version 15 was deleted and version 13 now holds another digest
+	var changed []gh.PackageVersionInfo
+	for _, v := range sharedPlatformPackage() {
+		switch v.ID {
+		case 15:
+			continue
+		case 13:
+			v.Digest = "sha256:re-pushed"
+		}
+		changed = append(changed, v)
+	}
+	err = checkPlanDrift(plan, "mkoepf", "myimage", changed)
+	assert.EqualError(t, err, "the package changed since the plan was made, nothing deleted:\n"+
+		"  version 15 (arm64) no longer exists\n"+
+		"  version 13 has digest re-pushed, the plan has attest")
+
+	inconsistent := sharedPlatformPlan()
+	inconsistent.VersionIDs = append(inconsistent.VersionIDs, 99)
+	err = checkPlanDrift(inconsistent, "mkoepf", "myimage", sharedPlatformPackage())
+	assert.EqualError(t, err, "the plan is inconsistent: version 99 is not listed in to_delete")
+}
+
+func TestExecuteApplyGraphPlan(t *testing.T) {
+	t.Parallel()
+	params := applyPlanParams{Owner: "mkoepf", OwnerType: "user", PackageName: "myimage", Force: true}
+
+	t.Run("deletes exactly the plan", func(t *testing.T) {
+		t.Parallel()
+		client := graphPlanFake{&mockVersionLister{versions: sharedPlatformPackage()}, newMockPackageDeleter()}
+
+		var buf strings.Builder
+		err := executeApplyGraphPlan(context.Background(), client, sharedPlatformRefs(), sharedPlatformPlan(), params, &buf, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []int64{10, 12, 15, 13}, client.deletedVersions)
+		assert.Contains(t, buf.String(), "Successfully deleted 4 version(s) of myimage")
+	})
+
+	t.Run("drift deletes nothing", func(t *testing.T) {
+		t.Parallel()
+		current := sharedPlatformPackage()[1:] // version 10 is gone
+		client := graphPlanFake{&mockVersionLister{versions: current}, newMockPackageDeleter()}
+
+		err := executeApplyGraphPlan(context.Background(), client, sharedPlatformRefs(), sharedPlatformPlan(), params, &strings.Builder{}, nil)
+		assert.ErrorContains(t, err, "version 10 (index1) no longer exists")
+		assert.Empty(t, client.deletedVersions)
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		t.Parallel()
+		client := graphPlanFake{&mockVersionLister{versions: sharedPlatformPackage()}, newMockPackageDeleter()}
+		dryRun := params
+		dryRun.DryRun = true
+
+		var buf strings.Builder
+		require.NoError(t, executeApplyGraphPlan(context.Background(), client, sharedPlatformRefs(), sharedPlatformPlan(), dryRun, &buf, nil))
+		assert.Empty(t, client.deletedVersions)
+		assert.Contains(t, buf.String(), "DRY RUN: No changes made")
+	})
+
+	t.Run("confirmation declined", func(t *testing.T) {
+		t.Parallel()
+		client := graphPlanFake{&mockVersionLister{versions: sharedPlatformPackage()}, newMockPackageDeleter()}
+		prompted := params
+		prompted.Force = false
+
+		var buf strings.Builder
+		err := executeApplyGraphPlan(context.Background(), client, sharedPlatformRefs(), sharedPlatformPlan(), prompted, &buf, func() (bool, error) {
+			return false, nil
+		})
+		require.NoError(t, err)
+		assert.Empty(t, client.deletedVersions)
+		assert.Contains(t, buf.String(), "Deletion cancelled")
+	})
+
+	t.Run("new reference deletes nothing", func(t *testing.T) {
+		t.Parallel()
+		client := graphPlanFake{&mockVersionLister{versions: sharedPlatformPackage()}, newMockPackageDeleter()}
+		// An image pushed after the plan reuses the planned arm64 build
+		discoverer := sharedPlatformRefs()
+		discoverer.refs["sha256:arm64"] = []string{"sha256:index1", "sha256:index3"}
+
+		err := executeApplyGraphPlan(context.Background(), client, discoverer, sharedPlatformPlan(), params, &strings.Builder{}, nil)
+		assert.ErrorContains(t, err, "the plan would break versions it does not delete, nothing deleted")
+		assert.ErrorContains(t, err, "version 12 (arm64) is referenced by index3")
+		assert.Empty(t, client.deletedVersions)
+	})
+}
+
+func TestDeleteGraphCmd_ApplyPlanExclusiveFlags(t *testing.T) {
+	t.Parallel()
+	for _, flag := range [][]string{
+		{"--verify"},
+		{"--atomic"},
+		{"--confirm-digest"},
+		{"--delete-package-if-blocked"},
+		{"--detailed-exitcode"},
+		{"-o", "github-actions"},
+	} {
+		t.Run(flag[0], func(t *testing.T) {
+			t.Parallel()
+			cmd := NewRootCmd()
+			cmd.SetOut(&strings.Builder{})
+			cmd.SetErr(&strings.Builder{})
+			cmd.SetArgs(append([]string{"delete", "graph", "owner/pkg", "--apply-plan", "plan.json"}, flag...))
+
+			err := cmd.Execute()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "none of the others can be")
+		})
+	}
+}
@@ -0,0 +1,351 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/mkoepf/ghcrctl/internal/audit"
+	"github.com/mkoepf/ghcrctl/internal/discover"
+	"github.com/mkoepf/ghcrctl/internal/display"
+	"github.com/mkoepf/ghcrctl/internal/filter"
+	"github.com/mkoepf/ghcrctl/internal/gh"
+)
+
+// maxBatchSize is the largest page size the GitHub API accepts
+const maxBatchSize = 100
+
+// versionPager is an interface for listing package versions one page at a time
+type versionPager interface {
+	ListPackageVersionsPage(ctx context.Context, owner, ownerType, packageName string, page, perPage int) ([]gh.PackageVersionInfo, int, error)
+}
+
+// graphDiscoverer is an interface for discovering the children of a set of versions.
+// *discover.PackageDiscoverer satisfies this interface.
+type graphDiscoverer interface {
+	DiscoverPackage(ctx context.Context, image string, versions []gh.PackageVersionInfo, allTags []string) ([]discover.VersionInfo, error)
+}
+
+// streamDeleteParams contains parameters for streaming bulk deletion
+type streamDeleteParams struct {
+	Owner       string
+	OwnerType   string
+	PackageName string
+	Filter      *filter.VersionFilter
+	BatchSize   int
+	Force       bool
+	DryRun      bool
+	// DetailedExitCode makes a dry run return errWouldDelete if it found versions to delete
+	DetailedExitCode bool
+}
+
+// validateBatchSize checks that the batch size is accepted by the GitHub API
+func validateBatchSize(batchSize int) error {
+	if batchSize < 1 || batchSize > maxBatchSize {
+		return fmt.Errorf("--batch-size must be between 1 and %d, got %d", maxBatchSize, batchSize)
+	}
+	return nil
+}
+
+// executeStreamingDelete deletes matching versions page by page, without
+// discovering the whole package or keeping its listing in memory.
+//
+// A first pass lists the versions page by page, following the next page
+// reported by the API, and filters each page. Only versions that are kept are
+// discovered, in batches of params.BatchSize, to protect everything they
+// reference. A protected version is discovered as well, so its own references
+// are protected in turn, down to any depth. The pass keeps only version IDs
+// and digests, plus a few versions to show before the confirmation prompt.
+//
+// A second pass deletes the matching versions that are not protected, page by
+// page from the last page to the first, so that deletions cannot shift
+// versions past pages not read yet. Only versions listed by the first pass are
+// deleted.
+func executeStreamingDelete(ctx context.Context, pager versionPager, deleter packageDeleter, discoverer graphDiscoverer,
+	params streamDeleteParams, w io.Writer, confirmFn func(count int) (bool, error)) error {
+	if err := validateBatchSize(params.BatchSize); err != nil {
+		return err
+	}
+
+	plan, err := planStreamingDelete(ctx, pager, discoverer, params)
+	if err != nil {
+		return err
+	}
+	if len(plan.matching) == 0 {
+		fmt.Fprintln(w, "No versions match the specified filters")
+		return deleteOutputs{}.write(ctx)
+	}
+
+	toDeleteCount, preservedCount := plan.counts()
+	fmt.Fprintf(w, "Preparing to delete %s of %d listed package version(s):\n",
+		display.ColorWarning(fmt.Sprintf("%d", toDeleteCount)), len(plan.listedIDs))
+	fmt.Fprintf(w, "  Package: %s\n", params.PackageName)
+	fmt.Fprintf(w, "  Owner:   %s (%s)\n\n", params.Owner, params.OwnerType)
+	// Show the first versions to delete; the others are not kept in memory
+	shown := 0
+	for _, ver := range plan.sample {
+		if !plan.protected[ver.Digest] {
+			fmt.Fprintf(w, "  - ID: %d, Tags: %s, Created: %s\n", ver.ID, formatTagsForDisplay(ver.Tags), ver.CreatedAt)
+			shown++
+		}
+	}
+	if toDeleteCount > shown {
+		fmt.Fprintf(w, "  ... and %d more\n", toDeleteCount-shown)
+	}
+	if preservedCount > 0 {
+		fmt.Fprintf(w, "\n%s %d matching version(s) are shared by other graphs and will be preserved.\n",
+			display.ColorWarning("Note:"), preservedCount)
+	}
+	fmt.Fprintln(w)
+
+	if params.DryRun {
+		fmt.Fprintf(w, "Would delete %d version(s)\n", toDeleteCount)
+		fmt.Fprintln(w, display.ColorDryRun("DRY RUN: No changes made"))
+		if err := (deleteOutputs{WouldDelete: toDeleteCount}).write(ctx); err != nil {
+			return err
+		}
+		return dryRunResult(params.DetailedExitCode, toDeleteCount)
+	}
+	if toDeleteCount == 0 {
+		fmt.Fprintln(w, "Nothing to delete")
+		return deleteOutputs{}.write(ctx)
+	}
+
+	if !params.Force {
+		confirmed, err := confirmFn(toDeleteCount)
+		if err != nil {
+			return fmt.Errorf("failed to read confirmation: %w", err)
+		}
+		if !confirmed {
+			fmt.Fprintln(w, "Deletion cancelled")
+			return nil
+		}
+	}
+
+	result, err := deleteStreamedPages(ctx, pager, deleter, params, plan, toDeleteCount, w)
+	if err != nil {
+		return err
+	}
+
+	// Summary
+	fmt.Fprintln(w)
+	if err := (deleteOutputs{Deleted: result.deleted, Failed: result.failed}).write(ctx); err != nil {
+		return err
+	}
+	if result.failed > 0 {
+		fmt.Fprintf(w, "Deletion complete: %s succeeded, %s failed\n",
+			display.ColorSuccess(fmt.Sprintf("%d", result.deleted)),
+			display.ColorError(fmt.Sprintf("%d", result.failed)))
+	} else {
+		fmt.Fprintf(w, "Deletion complete: %s succeeded\n",
+			display.ColorSuccess(fmt.Sprintf("%d", result.deleted)))
+	}
+	if missed := toDeleteCount - result.deleted - result.failed; missed > 0 {
+		fmt.Fprintf(w, "%s %d version(s) were not found on the page they were listed on, e.g. because versions were pushed meanwhile. Run the command again to delete them.\n",
+			display.ColorWarning("Note:"), missed)
+	}
+	if result.deleted > 0 {
+		printRestoreHint(w, params.Owner, params.OwnerType, params.PackageName)
+	}
+
+	if result.lastTaggedHit {
+		fmt.Fprintf(w, "\n%s\n", display.ColorWarning("Note: GHCR does not allow to delete the last tagged version of a package."))
+		fmt.Fprintf(w, "You can delete the package instead:\n")
+		fmt.Fprintf(w, "  ghcrctl delete package %s/%s\n", params.Owner, params.PackageName)
+	}
+
+	if result.failed > 0 {
+		return fmt.Errorf("failed to delete %d version(s)", result.failed)
+	}
+	return nil
+}
+
+// streamPlan is what the first pass of a streaming deletion keeps
+type streamPlan struct {
+	lastPage  int                     // number of the last page listed
+	listedIDs map[int64]bool          // every version listed
+	matching  map[string]int          // number of matching versions per digest
+	protected map[string]bool         // digests referenced by kept versions, down to any depth
+	sample    []gh.PackageVersionInfo // the first matching versions, to show them
+}
+
+// counts returns the number of matching versions to delete and to preserve
+func (p streamPlan) counts() (toDelete, preserved int) {
+	for digest, count := range p.matching {
+		if p.protected[digest] {
+			preserved += count
+		} else {
+			toDelete += count
+		}
+	}
+	return toDelete, preserved
+}
+
+// streamSampleSize is the number of versions to delete shown before the prompt
+const streamSampleSize = 10
+
+// planStreamingDelete lists the versions page by page, params.BatchSize per
+// page, until the API reports no next page, and discovers the kept versions
+// of each page. Versions pushed meanwhile shift others onto the next page;
+// those are only listed once.
+func planStreamingDelete(ctx context.Context, pager versionPager, discoverer graphDiscoverer, params streamDeleteParams) (streamPlan, error) {
+	plan := streamPlan{
+		listedIDs: make(map[int64]bool),
+		matching:  make(map[string]int),
+		protected: make(map[string]bool),
+	}
+	ociRef := fmt.Sprintf("ghcr.io/%s/%s", params.Owner, params.PackageName)
+	protector := newReferenceProtector(discoverer, ociRef, plan.protected, params.BatchSize)
+
+	for page := 1; page != 0; {
+		batch, next, err := pager.ListPackageVersionsPage(ctx, params.Owner, params.OwnerType, params.PackageName, page, params.BatchSize)
+		if err != nil {
+			return streamPlan{}, fmt.Errorf("failed to list package versions: %w", err)
+		}
+		plan.lastPage = page
+
+		var listed []gh.PackageVersionInfo
+		for _, v := range batch {
+			if !plan.listedIDs[v.ID] {
+				plan.listedIDs[v.ID] = true
+				listed = append(listed, v)
+				protector.addTags(v.Tags)
+			}
+		}
+
+		matchingIDs := make(map[int64]bool)
+		for _, v := range params.Filter.Apply(listed) {
+			matchingIDs[v.ID] = true
+			plan.matching[v.Digest]++
+			if len(plan.sample) < streamSampleSize {
+				plan.sample = append(plan.sample, v)
+			}
+		}
+		for _, v := range listed {
+			if !matchingIDs[v.ID] {
+				protector.keep(v)
+			}
+		}
+		if err := protector.discover(ctx, false); err != nil {
+			return streamPlan{}, err
+		}
+		page = next
+	}
+
+	if err := protector.discover(ctx, true); err != nil {
+		return streamPlan{}, err
+	}
+	return plan, nil
+}
+
+// referenceProtector discovers kept versions in batches and protects the
+// digests they reference. Protected digests are discovered in turn, so that
+// their references are protected too.
+type referenceProtector struct {
+	discoverer graphDiscoverer
+	image      string
+	batchSize  int
+	protected  map[string]bool
+
+	allTags    []string
+	discovered map[string]bool
+	pending    []gh.PackageVersionInfo
+	batchNum   int
+}
+
+func newReferenceProtector(discoverer graphDiscoverer, image string, protected map[string]bool, batchSize int) *referenceProtector {
+	return &referenceProtector{
+		discoverer: discoverer,
+		image:      image,
+		batchSize:  batchSize,
+		protected:  protected,
+		discovered: make(map[string]bool),
+	}
+}
+
+// addTags adds tags of listed versions, which discovery uses to find referrers
+// attached by tag
+func (p *referenceProtector) addTags(tags []string) {
+	p.allTags = append(p.allTags, tags...)
+}
+
+// keep queues a kept version for discovery, unless its digest was discovered
+func (p *referenceProtector) keep(v gh.PackageVersionInfo) {
+	if !p.discovered[v.Digest] {
+		p.discovered[v.Digest] = true
+		p.pending = append(p.pending, v)
+	}
+}
+
+// discover discovers full batches of queued versions, and with all set, every
+// queued version, including the protected digests found meanwhile
+func (p *referenceProtector) discover(ctx context.Context, all bool) error {
+	for len(p.pending) >= p.batchSize || (all && len(p.pending) > 0) {
+		batch := p.pending[:min(p.batchSize, len(p.pending))]
+		p.pending = p.pending[len(batch):]
+		p.batchNum++
+
+		results, err := p.discoverer.DiscoverPackage(ctx, p.image, batch, p.allTags)
+		if err != nil {
+			return fmt.Errorf("failed to discover batch %d: %w", p.batchNum, err)
+		}
+		for _, v := range results {
+			for _, child := range v.OutgoingRefs {
+				p.protected[child] = true
+				// A referenced version is kept, and so is everything it references
+				p.keep(gh.PackageVersionInfo{Digest: child})
+			}
+		}
+	}
+	return nil
+}
+
+// streamDeleteResult counts the outcome of the second pass
+type streamDeleteResult struct {
+	deleted       int
+	failed        int
+	lastTaggedHit bool
+}
+
+// deleteStreamedPages deletes the versions planned for deletion, reading the
+// pages from plan.lastPage back to the first. A deletion only shifts versions
+// on later pages, which were read already.
+func deleteStreamedPages(ctx context.Context, pager versionPager, deleter packageDeleter, params streamDeleteParams,
+	plan streamPlan, toDeleteCount int, w io.Writer) (streamDeleteResult, error) {
+	var result streamDeleteResult
+	handled := make(map[int64]bool)
+	for page := plan.lastPage; page >= 1; page-- {
+		batch, _, err := pager.ListPackageVersionsPage(ctx, params.Owner, params.OwnerType, params.PackageName, page, params.BatchSize)
+		if err != nil {
+			return result, fmt.Errorf("failed to list package versions: %w", err)
+		}
+
+		for _, ver := range params.Filter.Apply(batch) {
+			if !plan.listedIDs[ver.ID] || handled[ver.ID] || plan.protected[ver.Digest] {
+				continue
+			}
+			handled[ver.ID] = true
+
+			fmt.Fprintf(w, "Deleting version %d/%d (ID: %d)...\n", len(handled), toDeleteCount, ver.ID)
+			if err := deleter.DeletePackageVersion(ctx, params.Owner, params.OwnerType, params.PackageName, ver.ID); err != nil {
+				// Someone else deleted it since it was listed; it is gone either way
+				if gh.IsNotFound(err) {
+					fmt.Fprintf(w, "  Already deleted\n")
+					result.deleted++
+					continue
+				}
+				if gh.IsLastTaggedVersionError(err) {
+					result.lastTaggedHit = true
+				}
+				fmt.Fprintf(w, "  %s\n", display.ColorError(fmt.Sprintf("Failed: %v", err)))
+				result.failed++
+				continue
+			}
+			result.deleted++
+			if err := recordDeleted(ctx, audit.Version{ID: ver.ID, Digest: ver.Digest, Tags: ver.Tags}); err != nil {
+				return result, err
+			}
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,417 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/mkoepf/ghcrctl/internal/discover"
+	"github.com/mkoepf/ghcrctl/internal/filter"
+	"github.com/mkoepf/ghcrctl/internal/gh"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pagedFakeClient serves versions page by page and removes them on deletion,
+// so later versions shift onto earlier pages like they do on GitHub.
+type pagedFakeClient struct {
+	versions     []gh.PackageVersionInfo // newest first
+	deleted      []int64
+	deleteErrors map[int64]error
+	pageCalls    int
+}
+
+func newPagedFakeClient(versions []gh.PackageVersionInfo) *pagedFakeClient {
+	return &pagedFakeClient{
+		versions:     versions,
+		deleteErrors: make(map[int64]error),
+	}
+}
+
+func (c *pagedFakeClient) ListPackageVersionsPage(ctx context.Context, owner, ownerType, packageName string, page, perPage int) ([]gh.PackageVersionInfo, int, error) {
+	c.pageCalls++
+	start := (page - 1) * perPage
+	if start >= len(c.versions) {
+		return nil, 0, nil
+	}
+	end := start + perPage
+	next := page + 1
+	if end >= len(c.versions) {
+		end = len(c.versions)
+		next = 0
+	}
+	result := make([]gh.PackageVersionInfo, end-start)
+	copy(result, c.versions[start:end])
+	return result, next, nil
+}
+
+func (c *pagedFakeClient) DeletePackageVersion(ctx context.Context, owner, ownerType, packageName string, versionID int64) error {
+	if err, ok := c.deleteErrors[versionID]; ok {
+		return err
+	}
+	for i, v := range c.versions {
+		if v.ID == versionID {
+			c.versions = append(c.versions[:i], c.versions[i+1:]...)
+			break
+		}
+	}
+	c.deleted = append(c.deleted, versionID)
+	return nil
+}
+
+// fakeGraphDiscoverer reports fixed children per digest
+type fakeGraphDiscoverer struct {
+	children map[string][]string
+}
+
+func (d *fakeGraphDiscoverer) DiscoverPackage(ctx context.Context, image string, versions []gh.PackageVersionInfo, allTags []string) ([]discover.VersionInfo, error) {
+	result := make([]discover.VersionInfo, 0, len(versions))
+	for _, v := range versions {
+		result = append(result, discover.VersionInfo{ID: v.ID, Digest: v.Digest, Tags: v.Tags, OutgoingRefs: d.children[v.Digest]})
+	}
+	return result, nil
+}
+
+// manyVersions returns count versions, newest first, tagging every tagEvery-th one
+func manyVersions(count, tagEvery int) []gh.PackageVersionInfo {
+	versions := make([]gh.PackageVersionInfo, 0, count)
+	for i := count; i >= 1; i-- {
+		v := gh.PackageVersionInfo{ID: int64(i), Digest: fmt.Sprintf("sha256:%04d", i)}
+		if i%tagEvery == 0 {
+			v.Tags = []string{fmt.Sprintf("v%d", i)}
+		}
+		versions = append(versions, v)
+	}
+	return versions
+}
+
+func streamParams(batchSize int) streamDeleteParams {
+	return streamDeleteParams{
+		Owner:       "owner",
+		OwnerType:   "user",
+		PackageName: "pkg",
+		Filter:      &filter.VersionFilter{OnlyUntagged: true},
+		BatchSize:   batchSize,
+		Force:       true,
+	}
+}
+
+func TestExecuteStreamingDelete_ProcessesAllPages(t *testing.T) {
+	t.Parallel()
+	client := newPagedFakeClient(manyVersions(250, 10))
+	discoverer := &fakeGraphDiscoverer{}
+
+	var buf bytes.Buffer
+	err := executeStreamingDelete(context.Background(), client, client, discoverer, streamParams(100), &buf, nil)
+	require.NoError(t, err)
+
+	// All 225 untagged versions are deleted even though deletions shift later pages
+	assert.Len(t, client.deleted, 225)
+	assert.Len(t, client.versions, 25)
+	for _, v := range client.versions {
+		assert.NotEmpty(t, v.Tags, "only tagged versions should remain")
+	}
+	assert.Contains(t, buf.String(), "Deletion complete:")
+	assert.Contains(t, buf.String(), "225")
+}
+
+func TestExecuteStreamingDelete_ProtectsChildrenOfKeptVersions(t *testing.T) {
+	t.Parallel()
+	// The tagged index is newest, so it is listed on the first page while its
+	// untagged platform manifests are on the last page.
+	versions := []gh.PackageVersionInfo{{ID: 100, Digest: "sha256:index", Tags: []string{"latest"}}}
+	versions = append(versions, manyVersions(9, 100)...)
+	versions = append(versions,
+		gh.PackageVersionInfo{ID: 0, Digest: "sha256:amd64"},
+	)
+	client := newPagedFakeClient(versions)
+	discoverer := &fakeGraphDiscoverer{children: map[string][]string{
+		"sha256:index": {"sha256:amd64", "sha256:0001"},
+	}}
+
+	var buf bytes.Buffer
+	err := executeStreamingDelete(context.Background(), client, client, discoverer, streamParams(3), &buf, nil)
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, []int64{9, 8, 7, 6, 5, 4, 3, 2}, client.deleted)
+	assert.NotContains(t, client.deleted, int64(1), "child of a kept index must be preserved")
+	assert.NotContains(t, client.deleted, int64(0), "child of a kept index must be preserved")
+	assert.Contains(t, buf.String(), "2 matching version(s) are shared by other graphs and will be preserved")
+}
+
+func TestExecuteStreamingDelete_ProtectsAllDescendantsOfKeptVersions(t *testing.T) {
+	t.Parallel()
+	// The tagged index references an untagged platform manifest, which has an
+	// untagged signature, which in turn has an attestation. They are listed in
+	// the reverse order of the graph, each on its own page.
+	versions := []gh.PackageVersionInfo{
+		{ID: 4, Digest: "sha256:attestation"},
+		{ID: 3, Digest: "sha256:signature"},
+		{ID: 2, Digest: "sha256:amd64"},
+		{ID: 1, Digest: "sha256:index", Tags: []string{"latest"}},
+		{ID: 6, Digest: "sha256:orphan-sig"},
+		{ID: 5, Digest: "sha256:orphan"},
+	}
+	client := newPagedFakeClient(versions)
+	discoverer := &recordingGraphDiscoverer{fakeGraphDiscoverer: fakeGraphDiscoverer{children: map[string][]string{
+		"sha256:index":     {"sha256:amd64"},
+		"sha256:amd64":     {"sha256:signature"},
+		"sha256:signature": {"sha256:attestation"},
+		"sha256:orphan":    {"sha256:orphan-sig"},
+	}}}
+
+	var buf bytes.Buffer
+	err := executeStreamingDelete(context.Background(), client, client, discoverer, streamParams(1), &buf, nil)
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, []int64{5, 6}, client.deleted, "only the graph without a kept root is deleted")
+	assert.Contains(t, buf.String(), "3 matching version(s) are shared by other graphs and will be preserved")
+	assert.ElementsMatch(t, []string{"sha256:index", "sha256:amd64", "sha256:signature", "sha256:attestation"}, discoverer.discovered,
+		"only kept versions are discovered")
+}
+
+// recordingGraphDiscoverer records the digests it discovers
+type recordingGraphDiscoverer struct {
+	fakeGraphDiscoverer
+	discovered []string
+}
+
+func (d *recordingGraphDiscoverer) DiscoverPackage(ctx context.Context, image string, versions []gh.PackageVersionInfo, allTags []string) ([]discover.VersionInfo, error) {
+	for _, v := range versions {
+		d.discovered = append(d.discovered, v.Digest)
+	}
+	return d.fakeGraphDiscoverer.DiscoverPackage(ctx, image, versions, allTags)
+}
+
+// growingPagedClient pushes a new version before each of the pages 2 to 4 of
+// the first listing, shifting it like concurrent pushes do
+type growingPagedClient struct {
+	*pagedFakeClient
+	nextID int64
+	pushes int
+}
+
+func (c *growingPagedClient) ListPackageVersionsPage(ctx context.Context, owner, ownerType, packageName string, page, perPage int) ([]gh.PackageVersionInfo, int, error) {
+	if page > 1 && c.pushes < 3 {
+		c.pushes++
+		c.nextID++
+		c.versions = append([]gh.PackageVersionInfo{{ID: c.nextID, Digest: fmt.Sprintf("sha256:new%d", c.nextID), Tags: []string{"new"}}}, c.versions...)
+	}
+	return c.pagedFakeClient.ListPackageVersionsPage(ctx, owner, ownerType, packageName, page, perPage)
+}
+
+func TestExecuteStreamingDelete_FollowsNextPage(t *testing.T) {
+	t.Parallel()
+	// Concurrent pushes shift versions onto later pages; a page of versions
+	// seen before must neither end the listing nor list them twice
+	client := &growingPagedClient{pagedFakeClient: newPagedFakeClient(manyVersions(9, 100)), nextID: 1000}
+
+	var buf bytes.Buffer
+	err := executeStreamingDelete(context.Background(), client, client, &fakeGraphDiscoverer{}, streamParams(1), &buf, nil)
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, []int64{9, 8, 7, 6, 5, 4, 3, 2, 1}, client.deleted)
+}
+
+// pushWhileDeletingClient pushes a tagged version right after the first deletion
+type pushWhileDeletingClient struct {
+	*pagedFakeClient
+}
+
+func (c *pushWhileDeletingClient) DeletePackageVersion(ctx context.Context, owner, ownerType, packageName string, versionID int64) error {
+	err := c.pagedFakeClient.DeletePackageVersion(ctx, owner, ownerType, packageName, versionID)
+	if len(c.deleted) == 1 {
+		c.versions = append([]gh.PackageVersionInfo{{ID: 1000, Digest: "sha256:new", Tags: []string{"new"}}}, c.versions...)
+	}
+	return err
+}
+
+func TestExecuteStreamingDelete_DeletesPageByPageFromTheEnd(t *testing.T) {
+	t.Parallel()
+	client := newPagedFakeClient(manyVersions(6, 100))
+
+	var buf bytes.Buffer
+	require.NoError(t, executeStreamingDelete(context.Background(), client, client, &fakeGraphDiscoverer{}, streamParams(2), &buf, nil))
+
+	assert.Equal(t, []int64{2, 1, 4, 3, 6, 5}, client.deleted, "the last page is deleted first")
+	assert.Equal(t, 3+3, client.pageCalls, "each page is read once to plan and once to delete")
+}
+
+func TestExecuteStreamingDelete_PushWhileDeleting(t *testing.T) {
+	t.Parallel()
+	// The push shifts the unread pages by one, so the version on the page
+	// before the one just read moves onto it and is missed
+	client := &pushWhileDeletingClient{pagedFakeClient: newPagedFakeClient(manyVersions(6, 100))}
+
+	var buf bytes.Buffer
+	require.NoError(t, executeStreamingDelete(context.Background(), client, client, &fakeGraphDiscoverer{}, streamParams(1), &buf, nil))
+
+	assert.Equal(t, []int64{1, 3, 4, 5, 6}, client.deleted, "the new version was not planned and is not deleted")
+	assert.Contains(t, buf.String(), "1 version(s) were not found on the page they were listed on")
+}
+
+func TestExecuteStreamingDelete_DryRun(t *testing.T) {
+	t.Parallel()
+	client := newPagedFakeClient(manyVersions(25, 5))
+	discoverer := &fakeGraphDiscoverer{}
+
+	params := streamParams(10)
+	params.Force = false
+	params.DryRun = true
+
+	var buf bytes.Buffer
+	err := executeStreamingDelete(context.Background(), client, client, discoverer, params, &buf, func(int) (bool, error) {
+		t.Fatal("dry run should not ask for confirmation")
+		return false, nil
+	})
+	require.NoError(t, err)
+
+	assert.Empty(t, client.deleted)
+	assert.Equal(t, 3, client.pageCalls, "listing stops at the page without a next page")
+	output := buf.String()
+	assert.Contains(t, output, "Would delete 20 version(s)")
+	assert.Contains(t, output, "DRY RUN: No changes made")
+}
+
+func TestExecuteStreamingDelete_DetailedExitCode(t *testing.T) {
+	t.Parallel()
+
+	t.Run("versions would be deleted", func(t *testing.T) {
+		t.Parallel()
+		client := newPagedFakeClient(manyVersions(5, 100))
+		params := streamParams(2)
+		params.DryRun = true
+		params.DetailedExitCode = true
+
+		err := executeStreamingDelete(context.Background(), client, client, &fakeGraphDiscoverer{}, params, new(bytes.Buffer), nil)
+		require.ErrorIs(t, err, errWouldDelete)
+		assert.Equal(t, 2, exitCode(err))
+		assert.Empty(t, client.deleted)
+	})
+
+	t.Run("nothing to delete", func(t *testing.T) {
+		t.Parallel()
+		client := newPagedFakeClient(manyVersions(5, 1))
+		params := streamParams(2)
+		params.DryRun = true
+		params.DetailedExitCode = true
+
+		err := executeStreamingDelete(context.Background(), client, client, &fakeGraphDiscoverer{}, params, new(bytes.Buffer), nil)
+		require.NoError(t, err)
+		assert.Equal(t, 0, exitCode(err))
+	})
+}
+
+func TestExecuteStreamingDelete_ContinuesAfterFailures(t *testing.T) {
+	t.Parallel()
+	client := newPagedFakeClient(manyVersions(12, 100))
+	client.deleteErrors[11] = fmt.Errorf("boom")
+	discoverer := &fakeGraphDiscoverer{}
+
+	var buf bytes.Buffer
+	err := executeStreamingDelete(context.Background(), client, client, discoverer, streamParams(5), &buf, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to delete 1 version(s)")
+
+	assert.Len(t, client.deleted, 11)
+	require.Len(t, client.versions, 1)
+	assert.Equal(t, int64(11), client.versions[0].ID)
+}
+
+// raceDeletedClient behaves as if another process deleted a version right
+// before ghcrctl tried to: the version disappears and the API answers 404.
+type raceDeletedClient struct {
+	*pagedFakeClient
+	gone int64
+}
+
+func (c *raceDeletedClient) DeletePackageVersion(ctx context.Context, owner, ownerType, packageName string, versionID int64) error {
+	if err := c.pagedFakeClient.DeletePackageVersion(ctx, owner, ownerType, packageName, versionID); err != nil || versionID != c.gone {
+		return err
+	}
+	return &gh.NotFoundError{Action: "delete version", Err: fmt.Errorf("404 Not Found")}
+}
+
+func TestExecuteStreamingDelete_AlreadyDeletedIsNotAFailure(t *testing.T) {
+	t.Parallel()
+	client := &raceDeletedClient{pagedFakeClient: newPagedFakeClient(manyVersions(12, 100)), gone: 11}
+
+	var buf bytes.Buffer
+	err := executeStreamingDelete(context.Background(), client, client, &fakeGraphDiscoverer{}, streamParams(5), &buf, nil)
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "Already deleted")
+	assert.Empty(t, client.versions)
+}
+
+func TestExecuteStreamingDelete_Cancelled(t *testing.T) {
+	t.Parallel()
+	client := newPagedFakeClient(manyVersions(5, 100))
+	params := streamParams(100)
+	params.Force = false
+
+	var buf bytes.Buffer
+	var asked int
+	err := executeStreamingDelete(context.Background(), client, client, &fakeGraphDiscoverer{}, params, &buf, func(count int) (bool, error) {
+		asked = count
+		assert.Contains(t, buf.String(), "  - ID: 5, Tags: [], Created: ", "the versions to delete are shown before the prompt")
+		return false, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 5, asked)
+	assert.Empty(t, client.deleted)
+	assert.Contains(t, buf.String(), "Deletion cancelled")
+}
+
+func TestExecuteStreamingDelete_NoMatches(t *testing.T) {
+	t.Parallel()
+	client := newPagedFakeClient(manyVersions(5, 1))
+
+	var buf bytes.Buffer
+	err := executeStreamingDelete(context.Background(), client, client, &fakeGraphDiscoverer{}, streamParams(2), &buf, nil)
+	require.NoError(t, err)
+	assert.Empty(t, client.deleted)
+	assert.Contains(t, buf.String(), "No versions match the specified filters")
+}
+
+func TestValidateBatchSize(t *testing.T) {
+	t.Parallel()
+	assert.NoError(t, validateBatchSize(1))
+	assert.NoError(t, validateBatchSize(100))
+	assert.ErrorContains(t, validateBatchSize(0), "--batch-size must be between 1 and 100")
+	assert.ErrorContains(t, validateBatchSize(101), "--batch-size must be between 1 and 100")
+}
+
+func TestDeleteVersionCmd_BatchSizeValidation(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{
+			name:    "requires filter flags",
+			args:    []string{"delete", "version", "owner/pkg", "--version", "123", "--batch-size", "50"},
+			wantErr: "--batch-size only applies to bulk deletion",
+		},
+		{
+			name:    "out of range",
+			args:    []string{"delete", "version", "owner/pkg", "--untagged", "--batch-size", "500"},
+			wantErr: "--batch-size must be between 1 and 100",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cmd := NewRootCmd()
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(new(bytes.Buffer))
+			cmd.SetErr(new(bytes.Buffer))
+
+			err := cmd.Execute()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
@@ -1,13 +1,23 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/mkoepf/ghcrctl/internal/discover"
+	"github.com/mkoepf/ghcrctl/internal/display"
+	"github.com/mkoepf/ghcrctl/internal/filter"
 	"github.com/mkoepf/ghcrctl/internal/gh"
+	"github.com/mkoepf/ghcrctl/internal/metrics"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -87,6 +97,7 @@ func TestDeleteVersionCommandHasFlags(t *testing.T) {
 		"force",
 		"yes",
 		"dry-run",
+		"detailed-exitcode",
 		"digest",
 		"tag-pattern",
 		"tagged",
@@ -126,7 +137,7 @@ func TestDeletePackageCommandHasFlags(t *testing.T) {
 	deletePackageCmd, _, err := cmd.Find([]string{"delete", "package"})
 	require.NoError(t, err, "Failed to find delete package command")
 
-	requiredFlags := []string{"force", "yes"}
+	requiredFlags := []string{"force", "yes", "archive", "archive-manifests"}
 	for _, flagName := range requiredFlags {
 		flag := deletePackageCmd.Flags().Lookup(flagName)
 		assert.NotNil(t, flag, "delete package command should have --%s flag", flagName)
@@ -250,6 +261,8 @@ func TestBuildDeleteFilter(t *testing.T) {
 		onlyUntagged bool
 		olderThan    string
 		newerThan    string
+		afterID      int64
+		beforeID     int64
 		wantErr      bool
 		errContains  string
 	}{
@@ -257,6 +270,19 @@ func TestBuildDeleteFilter(t *testing.T) {
 			name:    "no filters",
 			wantErr: false,
 		},
+		{
+			name:     "version ID range",
+			afterID:  100,
+			beforeID: 200,
+			wantErr:  false,
+		},
+		{
+			name:        "empty version ID range",
+			afterID:     100,
+			beforeID:    101,
+			wantErr:     true,
+			errContains: "leave no version IDs in between",
+		},
 		{
 			name:         "conflicting tagged/untagged flags",
 			onlyTagged:   true,
@@ -304,8 +330,8 @@ func TestBuildDeleteFilter(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			filter, err := buildDeleteVersionFilter(tt.tagPattern, tt.onlyTagged, tt.onlyUntagged,
-				tt.olderThan, tt.newerThan)
+			filter, err := buildDeleteVersionFilter(tt.tagPattern, "", "", tt.onlyTagged, tt.onlyUntagged,
+				tt.olderThan, tt.newerThan, time.UTC, tt.afterID, tt.beforeID)
 
 			if tt.wantErr {
 				require.Error(t, err, "Expected error but got none")
@@ -477,6 +503,24 @@ func TestDisplayGraphVersions(t *testing.T) {
 				"Shared versions",
 			},
 		},
+		{
+			name: "attestation manifest with sbom and provenance layers is one version",
+			toDelete: []discover.VersionInfo{
+				{ID: 200, Digest: "sha256:manifestdigest", Types: []string{"manifest"}},
+				{ID: 201, Digest: "sha256:attestdigest", Types: []string{"sbom", "provenance"}},
+			},
+			graphVersions: []discover.VersionInfo{
+				{ID: 200, Digest: "sha256:manifestdigest", Types: []string{"manifest"}},
+				{ID: 201, Digest: "sha256:attestdigest", Types: []string{"sbom", "provenance"}},
+			},
+			wantContains: []string{
+				"Versions to delete (2)",
+				"sbom, provenance (version 201)",
+			},
+			wantNotContain: []string{
+				"provenance (version 202)",
+			},
+		},
 		{
 			name: "graph with shared platforms (preserved)",
 			toDelete: []discover.VersionInfo{
@@ -678,6 +722,7 @@ func TestExecuteSingleDelete(t *testing.T) {
 				"Version ID: 12345",
 				"Tags:       v1.0, latest",
 				"Successfully deleted version 12345",
+				"Deleted versions can be restored within 30 days: https://github.com/users/testowner/packages/container/testimage/versions?filters%5Bversion_type%5D=deleted",
 			},
 		},
 		{
@@ -857,6 +902,198 @@ func TestExecuteSingleDelete(t *testing.T) {
 // Tests for executeBulkDelete
 // =============================================================================
 
+func TestExecuteBulkDelete_GitHubSummary(t *testing.T) {
+	t.Parallel()
+	params := BulkDeleteParams{
+		Owner:       "testowner",
+		OwnerType:   "user",
+		PackageName: "testimage",
+		Versions: []gh.PackageVersionInfo{
+			{ID: 100, Digest: "sha256:aaa111222333444", Tags: []string{"v1.0", "latest"}, CreatedAt: "2025-01-01"},
+			{ID: 101, Digest: "sha256:bbb111222333444", CreatedAt: "2025-01-02"},
+		},
+		Force: true,
+	}
+
+	t.Run("deletion", func(t *testing.T) {
+		t.Parallel()
+		path := filepath.Join(t.TempDir(), "summary.md")
+		ctx := display.WithStepSummary(context.Background(), path)
+		mock := newMockPackageDeleter()
+		mock.deleteErrors[101] = fmt.Errorf("permission denied")
+
+		err := ExecuteBulkDelete(ctx, mock, params, io.Discard, nil)
+		require.Error(t, err)
+
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "### Deleted versions of testimage\n\n"+
+			"| Version ID | Digest | Tags | Created | Result |\n"+
+			"| --- | --- | --- | --- | --- |\n"+
+			"| 100 | aaa111222333 | v1.0, latest | 2025-01-01 | deleted |\n"+
+			"| 101 | bbb111222333 |  | 2025-01-02 | failed: permission denied |\n\n", string(content))
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		t.Parallel()
+		path := filepath.Join(t.TempDir(), "summary.md")
+		ctx := display.WithStepSummary(context.Background(), path)
+		dryRun := params
+		dryRun.DryRun = true
+
+		require.NoError(t, ExecuteBulkDelete(ctx, newMockPackageDeleter(), dryRun, io.Discard, nil))
+
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Contains(t, string(content), "### Versions of testimage that would be deleted (dry run)")
+		assert.Contains(t, string(content), "| 100 | aaa111222333 | v1.0, latest | 2025-01-01 | would delete |")
+	})
+}
+
+func TestExecuteBulkDelete_GitHubOutput(t *testing.T) {
+	t.Parallel()
+	params := BulkDeleteParams{
+		Owner:       "testowner",
+		OwnerType:   "user",
+		PackageName: "testimage",
+		Versions: []gh.PackageVersionInfo{
+			{ID: 100, Tags: []string{"v1.0"}},
+			{ID: 101},
+			{ID: 102},
+		},
+		Force: true,
+	}
+
+	t.Run("mixed run", func(t *testing.T) {
+		t.Parallel()
+		path := filepath.Join(t.TempDir(), "output")
+		ctx := display.WithGitHubOutput(context.Background(), path)
+		mock := newMockPackageDeleter()
+		mock.deleteErrors[101] = fmt.Errorf("permission denied")
+
+		err := ExecuteBulkDelete(ctx, mock, params, io.Discard, nil)
+		require.Error(t, err)
+
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "deleted_count=2\nfailed_count=1\nwould_delete_count=0\n", string(content))
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		t.Parallel()
+		path := filepath.Join(t.TempDir(), "output")
+		ctx := display.WithGitHubOutput(context.Background(), path)
+		dryRun := params
+		dryRun.DryRun = true
+
+		require.NoError(t, ExecuteBulkDelete(ctx, newMockPackageDeleter(), dryRun, io.Discard, nil))
+
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "deleted_count=0\nfailed_count=0\nwould_delete_count=3\n", string(content))
+	})
+}
+
+func TestDeleteOutputs_Digest(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "output")
+	ctx := display.WithGitHubOutput(context.Background(), path)
+
+	require.NoError(t, deleteOutputs{Deleted: 3, Digest: "sha256:abc123"}.write(ctx))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "deleted_count=3\ndigest=sha256:abc123\nfailed_count=0\nwould_delete_count=0\n", string(content))
+}
+
+func TestExecuteBulkDelete_MetricsFile(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "ghcrctl.prom")
+	ctx := metrics.WithFile(context.Background(), path, "mkoepf", "myimage")
+	mock := newMockPackageDeleter()
+	mock.deleteErrors[101] = fmt.Errorf("permission denied")
+
+	err := ExecuteBulkDelete(ctx, mock, BulkDeleteParams{
+		Owner:       "mkoepf",
+		OwnerType:   "user",
+		PackageName: "myimage",
+		Versions:    []gh.PackageVersionInfo{{ID: 100}, {ID: 101}, {ID: 102}},
+		Force:       true,
+	}, io.Discard, nil)
+	require.Error(t, err)
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "ghcrctl_versions_deleted_total{owner=\"mkoepf\",package=\"myimage\"} 2\n")
+	assert.Contains(t, string(content), "ghcrctl_versions_failed_total{owner=\"mkoepf\",package=\"myimage\"} 1\n")
+	assert.Contains(t, string(content), "ghcrctl_run_duration_seconds{owner=\"mkoepf\",package=\"myimage\"} ")
+	// The sizes of versions deleted by ID are not known
+	assert.NotContains(t, string(content), "ghcrctl_bytes_reclaimed")
+}
+
+func TestExecuteDeleteAllTags_MetricsFile(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "ghcrctl.prom")
+	ctx := metrics.WithFile(context.Background(), path, "mkoepf", "myimage")
+
+	// The index listed under two version IDs is counted once
+	versions := []discover.VersionInfo{
+		{ID: 10, DuplicateIDs: []int64{11}, Digest: "sha256:index", Size: 500, Types: []string{"index"}, Tags: []string{"v1"}, OutgoingRefs: []string{"sha256:amd64"}},
+		{ID: 1, Digest: "sha256:amd64", Size: 1500, Types: []string{"linux/amd64"}, IncomingRefs: []string{"sha256:index"}},
+	}
+	tagged, toDelete, shared := planDeleteAllTags(versions)
+
+	err := executeDeleteAllTags(ctx, newMockPackageDeleter(), deleteAllTagsParams{
+		Owner: "mkoepf", OwnerType: "user", PackageName: "myimage",
+		Tagged: tagged, ToDelete: toDelete, Shared: shared, Force: true,
+	}, io.Discard, nil)
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "ghcrctl_versions_deleted_total{owner=\"mkoepf\",package=\"myimage\"} 3\n")
+	assert.Contains(t, string(content), "ghcrctl_versions_failed_total{owner=\"mkoepf\",package=\"myimage\"} 0\n")
+	assert.Contains(t, string(content), "ghcrctl_bytes_reclaimed{owner=\"mkoepf\",package=\"myimage\"} 2000\n")
+}
+
+func TestDeleteCmd_GitHubActionsOutputValidation(t *testing.T) {
+	t.Setenv("GITHUB_OUTPUT", "")
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{
+			name:    "requires GITHUB_OUTPUT",
+			args:    []string{"delete", "version", "owner/pkg", "--untagged", "-o", "github-actions"},
+			wantErr: "-o github-actions requires GITHUB_OUTPUT to be set",
+		},
+		{
+			name:    "only github-actions is supported",
+			args:    []string{"delete", "graph", "owner/pkg", "--tag", "v1", "-o", "json"},
+			wantErr: `invalid output format "json". Supported formats: github-actions`,
+		},
+		{
+			name:    "exclusive with all-tags",
+			args:    []string{"delete", "graph", "owner/pkg", "--all-tags", "-o", "github-actions"},
+			wantErr: "none of the others can be",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := NewRootCmd()
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(new(strings.Builder))
+			cmd.SetErr(new(strings.Builder))
+
+			err := cmd.Execute()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
 func TestExecuteBulkDelete(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
@@ -1125,9 +1362,9 @@ func TestFormatVersionType(t *testing.T) {
 			want:  "linux/amd64",
 		},
 		{
-			name:  "multiple types returns first",
+			name:  "multiple types are all listed",
 			types: []string{"sbom", "provenance"},
-			want:  "sbom",
+			want:  "sbom, provenance",
 		},
 	}
 
@@ -1138,3 +1375,1370 @@ func TestFormatVersionType(t *testing.T) {
 		})
 	}
 }
+
+// =============================================================================
+// Tests for delete graph --all-tags
+// =============================================================================
+
+// twoTaggedImagesSharingPlatform returns two tagged multi-arch images that share
+// their amd64 manifest, plus an untagged image that also references it.
+func twoTaggedImagesSharingPlatform(withUntagged bool) []discover.VersionInfo {
+	versions := []discover.VersionInfo{
+		{ID: 10, Digest: "sha256:idx1", Tags: []string{"v1.0.0"}, Types: []string{"index"},
+			OutgoingRefs: []string{"sha256:amd64", "sha256:arm64-1", "sha256:sbom1"}},
+		{ID: 20, Digest: "sha256:idx2", Tags: []string{"v1.1.0", "latest"}, Types: []string{"index"},
+			OutgoingRefs: []string{"sha256:amd64", "sha256:arm64-2"}},
+		{ID: 1, Digest: "sha256:amd64", Types: []string{"linux/amd64"}, IncomingRefs: []string{"sha256:idx1", "sha256:idx2"}},
+		{ID: 2, Digest: "sha256:arm64-1", Types: []string{"linux/arm64"}, IncomingRefs: []string{"sha256:idx1"}},
+		{ID: 3, Digest: "sha256:sbom1", Types: []string{"sbom"}, IncomingRefs: []string{"sha256:idx1"}},
+		{ID: 4, Digest: "sha256:arm64-2", Types: []string{"linux/arm64"}, IncomingRefs: []string{"sha256:idx2"}},
+	}
+	if withUntagged {
+		versions = append(versions, discover.VersionInfo{
+			ID: 30, Digest: "sha256:idx3", Types: []string{"index"}, OutgoingRefs: []string{"sha256:amd64"},
+		})
+		versions[2].IncomingRefs = append(versions[2].IncomingRefs, "sha256:idx3")
+	}
+	return versions
+}
+
+func versionIDs(versions []discover.VersionInfo) []int64 {
+	ids := make([]int64, len(versions))
+	for i, v := range versions {
+		ids[i] = v.ID
+	}
+	return ids
+}
+
+func packageVersionIDs(versions []gh.PackageVersionInfo) []int64 {
+	ids := make([]int64, len(versions))
+	for i, v := range versions {
+		ids[i] = v.ID
+	}
+	return ids
+}
+
+func TestPlanDeleteAllTags_SharedPlatformBetweenTaggedImages(t *testing.T) {
+	t.Parallel()
+
+	tagged, toDelete, shared := planDeleteAllTags(twoTaggedImagesSharingPlatform(false))
+
+	assert.Equal(t, []int64{20, 10}, versionIDs(tagged))
+	assert.ElementsMatch(t, []int64{10, 20, 1, 2, 3, 4}, versionIDs(toDelete),
+		"platform shared only between deleted images should be deleted too")
+	assert.Empty(t, shared)
+
+	// Children must be deleted before the indexes that reference them
+	position := make(map[int64]int)
+	for i, v := range toDelete {
+		position[v.ID] = i
+	}
+	assert.Less(t, position[1], position[10])
+	assert.Less(t, position[1], position[20])
+	assert.Less(t, position[3], position[10])
+}
+
+func TestPlanDeleteAllTags_PreservesPlatformSharedWithUntaggedImage(t *testing.T) {
+	t.Parallel()
+
+	_, toDelete, shared := planDeleteAllTags(twoTaggedImagesSharingPlatform(true))
+
+	assert.ElementsMatch(t, []int64{10, 20, 2, 3, 4}, versionIDs(toDelete))
+	assert.Equal(t, []int64{1}, versionIDs(shared))
+}
+
+func TestExecuteDeleteAllTags_DryRunShowsPlan(t *testing.T) {
+	t.Parallel()
+
+	tagged, toDelete, shared := planDeleteAllTags(twoTaggedImagesSharingPlatform(true))
+	mock := newMockPackageDeleter()
+
+	var buf strings.Builder
+	err := executeDeleteAllTags(context.Background(), mock, deleteAllTagsParams{
+		Owner: "owner", OwnerType: "user", PackageName: "pkg",
+		Tagged: tagged, ToDelete: toDelete, Shared: shared, DryRun: true,
+	}, &buf, nil)
+	require.NoError(t, err)
+
+	output := buf.String()
+	assert.Contains(t, output, "v1.0.0")
+	assert.Contains(t, output, "v1.1.0, latest")
+	assert.Contains(t, output, "Versions to delete (5)")
+	assert.Contains(t, output, "Shared versions (preserved)")
+	assert.Contains(t, output, "DRY RUN")
+	assert.Empty(t, mock.deletedVersions)
+}
+
+func TestExecuteDeleteAllTags_DetailedExitCode(t *testing.T) {
+	t.Parallel()
+
+	tagged, toDelete, shared := planDeleteAllTags(twoTaggedImagesSharingPlatform(true))
+
+	err := executeDeleteAllTags(context.Background(), newMockPackageDeleter(), deleteAllTagsParams{
+		Owner: "owner", OwnerType: "user", PackageName: "pkg",
+		Tagged: tagged, ToDelete: toDelete, Shared: shared, DryRun: true, DetailedExitCode: true,
+	}, new(strings.Builder), nil)
+	require.ErrorIs(t, err, errWouldDelete)
+	assert.Equal(t, 2, exitCode(err))
+}
+
+func TestDeleteCmd_DetailedExitCodeRequiresDryRun(t *testing.T) {
+	t.Parallel()
+
+	for _, args := range [][]string{
+		{"delete", "version", "owner/pkg", "--untagged", "--detailed-exitcode"},
+		{"delete", "graph", "owner/pkg", "--tag", "v1", "--detailed-exitcode"},
+	} {
+		t.Run(args[1], func(t *testing.T) {
+			t.Parallel()
+			cmd := NewRootCmd()
+			cmd.SetArgs(args)
+			cmd.SetOut(new(strings.Builder))
+			cmd.SetErr(new(strings.Builder))
+
+			err := cmd.Execute()
+			assert.EqualError(t, err, "--detailed-exitcode requires --dry-run")
+		})
+	}
+}
+
+func TestDryRunResult(t *testing.T) {
+	t.Parallel()
+
+	assert.ErrorIs(t, dryRunResult(true, 3), errWouldDelete)
+	assert.NoError(t, dryRunResult(true, 0), "an empty plan exits 0")
+	assert.NoError(t, dryRunResult(false, 3), "without the flag dry runs always succeed")
+}
+
+func TestExecuteDeleteAllTags_DeletesChildrenFirst(t *testing.T) {
+	t.Parallel()
+
+	tagged, toDelete, shared := planDeleteAllTags(twoTaggedImagesSharingPlatform(false))
+	mock := newMockPackageDeleter()
+
+	var buf strings.Builder
+	err := executeDeleteAllTags(context.Background(), mock, deleteAllTagsParams{
+		Owner: "owner", OwnerType: "user", PackageName: "pkg",
+		Tagged: tagged, ToDelete: toDelete, Shared: shared, Force: true,
+	}, &buf, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, versionIDs(toDelete), mock.deletedVersions)
+	assert.Contains(t, buf.String(), "Successfully deleted 6 version(s) of pkg")
+}
+
+func TestExecuteDeleteAllTags_DeletesDuplicateVersionIDs(t *testing.T) {
+	t.Parallel()
+
+	// GHCR lists the re-pushed index under version IDs 10 and 11
+	versions := []discover.VersionInfo{
+		{ID: 10, DuplicateIDs: []int64{11}, Digest: "sha256:index", Types: []string{"index"}, Tags: []string{"v1"}, OutgoingRefs: []string{"sha256:amd64"}},
+		{ID: 1, Digest: "sha256:amd64", Types: []string{"linux/amd64"}, IncomingRefs: []string{"sha256:index"}},
+	}
+	tagged, toDelete, shared := planDeleteAllTags(versions)
+	mock := newMockPackageDeleter()
+
+	var buf strings.Builder
+	err := executeDeleteAllTags(context.Background(), mock, deleteAllTagsParams{
+		Owner: "owner", OwnerType: "user", PackageName: "pkg",
+		Tagged: tagged, ToDelete: toDelete, Shared: shared, Force: true,
+	}, &buf, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, []int64{1, 10, 11}, mock.deletedVersions)
+	assert.Contains(t, buf.String(), "(version 10) [v1] (duplicates: 11)")
+	assert.Contains(t, buf.String(), "Total: 3 version(s) will be deleted")
+	assert.Contains(t, buf.String(), "Successfully deleted 3 version(s) of pkg")
+}
+
+func TestExecuteDeleteAllTags_LastTaggedVersionSuggestsDeletePackage(t *testing.T) {
+	t.Parallel()
+
+	tagged, toDelete, shared := planDeleteAllTags(twoTaggedImagesSharingPlatform(false))
+	last := toDelete[len(toDelete)-1]
+
+	mock := newMockPackageDeleter()
+	mock.deleteErrors[last.ID] = fmt.Errorf("400 cannot delete the last tagged version of a package")
+
+	var buf strings.Builder
+	err := executeDeleteAllTags(context.Background(), mock, deleteAllTagsParams{
+		Owner: "owner", OwnerType: "user", PackageName: "pkg",
+		Tagged: tagged, ToDelete: toDelete, Shared: shared, Force: true,
+	}, &buf, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "stopped after 5 of 6")
+	assert.Contains(t, buf.String(), "ghcrctl delete package owner/pkg")
+	assert.Len(t, mock.deletedVersions, 5)
+}
+
+func TestExecuteDeleteAllTags_NoTaggedVersions(t *testing.T) {
+	t.Parallel()
+
+	var buf strings.Builder
+	err := executeDeleteAllTags(context.Background(), newMockPackageDeleter(), deleteAllTagsParams{PackageName: "pkg"}, &buf, nil)
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "No tagged versions found in pkg")
+}
+
+func TestDeleteGraphCmd_AllTagsExclusiveWithTag(t *testing.T) {
+	t.Parallel()
+
+	cmd := NewRootCmd()
+	cmd.SetOut(&strings.Builder{})
+	cmd.SetErr(&strings.Builder{})
+	cmd.SetArgs([]string{"delete", "graph", "owner/pkg", "--all-tags", "--tag", "v1.0.0"})
+
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "none of the others can be")
+}
+
+func TestConfirmGraphDeletion_ConfirmDigest(t *testing.T) {
+	t.Parallel()
+	rootDigest := "sha256:abc123def456789000000000000000000000000000000000000000000000000"
+
+	tests := []struct {
+		name  string
+		input string
+		want  bool
+	}{
+		{name: "short digest matches", input: "abc123def456\n", want: true},
+		{name: "surrounding whitespace ignored", input: "  abc123def456  \n", want: true},
+		{name: "different digest", input: "abc123def457\n", want: false},
+		{name: "yes is not enough", input: "y\n", want: false},
+		{name: "full digest does not match", input: rootDigest + "\n", want: false},
+		{name: "no input", input: "", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var out strings.Builder
+			confirmed, err := confirmGraphDeletion(strings.NewReader(tt.input), &out, rootDigest, true)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, confirmed)
+			assert.Contains(t, out.String(), "To confirm, type the digest 'abc123def456'")
+		})
+	}
+}
+
+func TestConfirmGraphDeletion_YesNo(t *testing.T) {
+	t.Parallel()
+	var out strings.Builder
+	confirmed, err := confirmGraphDeletion(strings.NewReader("y\n"), &out, "sha256:abc123def456", false)
+	require.NoError(t, err)
+	assert.True(t, confirmed)
+	assert.Contains(t, out.String(), "[y/N]")
+}
+
+func TestDeleteGraphCmd_ConfirmDigestExclusiveWithForce(t *testing.T) {
+	t.Parallel()
+
+	cmd := NewRootCmd()
+	cmd.SetOut(&strings.Builder{})
+	cmd.SetErr(&strings.Builder{})
+	cmd.SetArgs([]string{"delete", "graph", "owner/pkg", "--tag", "v1.0.0", "--confirm-digest", "--force"})
+
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "none of the others can be")
+}
+
+// sharedPlatformGraphs has two multi-arch images: v1 with its own arm64 build
+// and an attestation, and v2, which shares v1's amd64 build
+func sharedPlatformGraphs() []discover.VersionInfo {
+	return []discover.VersionInfo{
+		{ID: 10, Digest: "sha256:index1", Tags: []string{"v1"}, Types: []string{"index"},
+			OutgoingRefs: []string{"sha256:amd64", "sha256:arm64", "sha256:attest"}},
+		{ID: 11, Digest: "sha256:amd64", Types: []string{"linux/amd64"},
+			IncomingRefs: []string{"sha256:index1", "sha256:index2"}},
+		{ID: 12, DuplicateIDs: []int64{15}, Digest: "sha256:arm64", Types: []string{"linux/arm64"},
+			IncomingRefs: []string{"sha256:index1"}},
+		{ID: 13, Digest: "sha256:attest", Types: []string{"sbom", "provenance"},
+			IncomingRefs: []string{"sha256:index1"}},
+		{ID: 20, Digest: "sha256:index2", Tags: []string{"v2"}, Types: []string{"index"},
+			OutgoingRefs: []string{"sha256:amd64"}},
+	}
+}
+
+func TestNewGraphDeletePlan_JSON(t *testing.T) {
+	t.Parallel()
+
+	graphVersions := discover.FindGraphByDigest(discover.ToMap(sharedPlatformGraphs()), "sha256:index1")
+	toDelete, shared := discover.ClassifyGraphVersions(graphVersions)
+	plan := newGraphDeletePlan("myimage", "v1", "sha256:index1", toDelete, shared, graphVersions)
+	plan.DryRun = true
+
+	data, err := json.Marshal(plan)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"schema_version": 1,
+		"package": "myimage",
+		"root_digest": "sha256:index1",
+		"tag": "v1",
+		"dry_run": true,
+		"version_ids": [10, 12, 15, 13],
+		"to_delete": [
+			{"id": 10, "digest": "sha256:index1", "types": ["index"], "tags": ["v1"]},
+			{"id": 12, "duplicate_ids": [15], "digest": "sha256:arm64", "types": ["linux/arm64"], "tags": []},
+			{"id": 13, "digest": "sha256:attest", "types": ["sbom", "provenance"], "tags": []}
+		],
+		"shared": [
+			{"id": 11, "digest": "sha256:amd64", "types": ["linux/amd64"], "tags": [], "external_refs": 1}
+		]
+	}`, string(data))
+}
+
+func TestResolveGraphRoot(t *testing.T) {
+	t.Parallel()
+	versionMap := discover.ToMap(sharedPlatformGraphs())
+
+	t.Run("child digest plans the whole image", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		root, err := resolveGraphRoot(versionMap, "sha256:attest", &buf)
+		require.NoError(t, err)
+		assert.Equal(t, "sha256:index1", root)
+		assert.Contains(t, buf.String(), "belongs to the image")
+
+		graphVersions := discover.FindGraphByDigest(versionMap, root)
+		toDelete, shared := discover.ClassifyGraphVersions(graphVersions)
+		plan := newGraphDeletePlan("myimage", "", root, toDelete, shared, graphVersions)
+		assert.Equal(t, "sha256:index1", plan.RootDigest)
+		assert.Equal(t, []int64{10, 12, 15, 13}, plan.VersionIDs)
+		require.Len(t, plan.Shared, 1)
+		assert.Equal(t, "sha256:amd64", plan.Shared[0].Digest)
+	})
+
+	t.Run("root digest is kept", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		root, err := resolveGraphRoot(versionMap, "sha256:index2", &buf)
+		require.NoError(t, err)
+		assert.Equal(t, "sha256:index2", root)
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("shared child is refused", func(t *testing.T) {
+		t.Parallel()
+		_, err := resolveGraphRoot(versionMap, "sha256:amd64", &bytes.Buffer{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "belongs to 2 graphs")
+	})
+}
+
+func TestDeleteGraphVersionsWithResults(t *testing.T) {
+	t.Parallel()
+
+	t.Run("all deleted", func(t *testing.T) {
+		t.Parallel()
+		mock := newMockPackageDeleter()
+		results, err := deleteGraphVersionsWithResults(context.Background(), mock, "owner", "user", "image", []int64{13, 12, 10})
+		require.NoError(t, err)
+		assert.Equal(t, []int64{13, 12, 10}, mock.deletedVersions)
+		assert.Equal(t, []versionDeleteResult{
+			{ID: 13, Status: "deleted"},
+			{ID: 12, Status: "deleted"},
+			{ID: 10, Status: "deleted"},
+		}, results)
+	})
+
+	t.Run("failure skips the rest", func(t *testing.T) {
+		t.Parallel()
+		mock := newMockPackageDeleter()
+		mock.deleteErrors[12] = fmt.Errorf("permission denied")
+		results, err := deleteGraphVersionsWithResults(context.Background(), mock, "owner", "user", "image", []int64{13, 12, 10})
+		require.EqualError(t, err, "failed to delete version 12: permission denied")
+		assert.Equal(t, []int64{13}, mock.deletedVersions)
+		assert.Equal(t, []versionDeleteResult{
+			{ID: 13, Status: "deleted"},
+			{ID: 12, Status: "failed", Error: "permission denied"},
+			{ID: 10, Status: "skipped"},
+		}, results)
+	})
+}
+
+func TestDeleteGraphCmd_JSONRequiresDryRunOrForce(t *testing.T) {
+	t.Parallel()
+
+	cmd := NewRootCmd()
+	cmd.SetOut(&strings.Builder{})
+	cmd.SetErr(&strings.Builder{})
+	cmd.SetArgs([]string{"delete", "graph", "owner/pkg", "--tag", "v1.0.0", "--json"})
+
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--json requires --dry-run or --force")
+}
+
+// mockPackageRemover records whether the package was deleted and what the
+// archive contained at that moment
+type mockPackageRemover struct {
+	archivePath     string
+	archiveAtDelete []byte
+	deleted         bool
+	err             error
+}
+
+func (m *mockPackageRemover) DeletePackage(ctx context.Context, owner, ownerType, packageName string) error {
+	if m.archivePath != "" {
+		data, err := os.ReadFile(m.archivePath)
+		if err != nil {
+			return err
+		}
+		m.archiveAtDelete = data
+	}
+	if m.err != nil {
+		return m.err
+	}
+	m.deleted = true
+	return nil
+}
+
+func archiveTestVersions() []gh.PackageVersionInfo {
+	return []gh.PackageVersionInfo{
+		{ID: 3, Digest: "sha256:index", Tags: []string{"v1.0.0", "latest"}, CreatedAt: "2025-01-03 10:00:00"},
+		{ID: 2, Digest: "sha256:amd64", CreatedAt: "2025-01-02 10:00:00"},
+		{ID: 1, Digest: "sha256:old", CreatedAt: "2025-01-01 10:00:00"},
+	}
+}
+
+func TestExecuteDeletePackage_WritesArchiveBeforeDelete(t *testing.T) {
+	t.Parallel()
+	archivePath := filepath.Join(t.TempDir(), "archive.json")
+	remover := &mockPackageRemover{archivePath: archivePath}
+
+	var buf strings.Builder
+	err := executeDeletePackage(context.Background(), remover, deletePackageParams{
+		Owner:       "mkoepf",
+		OwnerType:   "user",
+		PackageName: "myimage",
+		Versions:    archiveTestVersions(),
+		Force:       true,
+		ArchivePath: archivePath,
+		Manifests: map[string]json.RawMessage{
+			"sha256:index": json.RawMessage(`{"schemaVersion":2,"manifests":[]}`),
+		},
+	}, &buf, nil)
+	require.NoError(t, err)
+	require.True(t, remover.deleted)
+	require.NotEmpty(t, remover.archiveAtDelete, "archive must exist when the package is deleted")
+
+	var archive packageArchive
+	require.NoError(t, json.Unmarshal(remover.archiveAtDelete, &archive))
+	assert.Equal(t, "mkoepf/myimage", archive.Package)
+	assert.Equal(t, "user", archive.OwnerType)
+	assert.NotEmpty(t, archive.ArchivedAt)
+	require.Len(t, archive.Versions, 3)
+	assert.Equal(t, int64(3), archive.Versions[0].ID)
+	assert.Equal(t, "sha256:index", archive.Versions[0].Digest)
+	assert.Equal(t, []string{"v1.0.0", "latest"}, archive.Versions[0].Tags)
+	assert.Equal(t, "2025-01-03 10:00:00", archive.Versions[0].CreatedAt)
+	assert.JSONEq(t, `{"schemaVersion":2,"manifests":[]}`, string(archive.Versions[0].Manifest))
+	assert.Empty(t, archive.Versions[1].Manifest)
+	assert.Equal(t, int64(1), archive.Versions[2].ID)
+
+	assert.Contains(t, buf.String(), "Archived 3 version(s) to "+archivePath)
+	assert.Contains(t, buf.String(), "Successfully deleted package mkoepf/myimage")
+}
+
+func TestExecuteDeletePackage_ArchiveFailureAbortsDelete(t *testing.T) {
+	t.Parallel()
+	remover := &mockPackageRemover{}
+
+	var buf strings.Builder
+	err := executeDeletePackage(context.Background(), remover, deletePackageParams{
+		Owner:       "mkoepf",
+		OwnerType:   "user",
+		PackageName: "myimage",
+		Versions:    archiveTestVersions(),
+		Force:       true,
+		ArchivePath: filepath.Join(t.TempDir(), "missing", "archive.json"),
+	}, &buf, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to write archive")
+	assert.Contains(t, err.Error(), "package was not deleted")
+	assert.False(t, remover.deleted)
+}
+
+func TestExecuteDeletePackage_CancelledWritesNoArchive(t *testing.T) {
+	t.Parallel()
+	archivePath := filepath.Join(t.TempDir(), "archive.json")
+	remover := &mockPackageRemover{}
+
+	var buf strings.Builder
+	err := executeDeletePackage(context.Background(), remover, deletePackageParams{
+		Owner:       "mkoepf",
+		OwnerType:   "user",
+		PackageName: "myimage",
+		Versions:    archiveTestVersions(),
+		ArchivePath: archivePath,
+	}, &buf, func() (bool, error) { return false, nil })
+	require.NoError(t, err)
+	assert.False(t, remover.deleted)
+	assert.NoFileExists(t, archivePath)
+	assert.Contains(t, buf.String(), "Deletion cancelled")
+}
+
+func TestDeletePackageCmd_ArchiveManifestsRequiresArchive(t *testing.T) {
+	t.Parallel()
+
+	cmd := NewRootCmd()
+	cmd.SetOut(&strings.Builder{})
+	cmd.SetErr(&strings.Builder{})
+	cmd.SetArgs([]string{"delete", "package", "owner/pkg", "--archive-manifests"})
+
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--archive-manifests requires --archive")
+}
+
+func TestResolveDigestPrefix(t *testing.T) {
+	t.Parallel()
+
+	// Newest first, two versions share the "abc123" prefix
+	versions := []gh.PackageVersionInfo{
+		{ID: 3, Digest: "sha256:abc123999999012345678901234567890123456789012345678901234567"},
+		{ID: 2, Digest: "sha256:def456000000012345678901234567890123456789012345678901234567"},
+		{ID: 1, Digest: "sha256:abc123456789012345678901234567890123456789012345678901234567"},
+	}
+
+	t.Run("unique prefix", func(t *testing.T) {
+		t.Parallel()
+		var warn strings.Builder
+		digest, err := resolveDigestPrefix(versions, "def456", true, &warn)
+		require.NoError(t, err)
+		assert.Equal(t, versions[1].Digest, digest)
+		assert.Empty(t, warn.String())
+	})
+
+	t.Run("strict rejects ambiguous prefix", func(t *testing.T) {
+		t.Parallel()
+		var warn strings.Builder
+		_, err := resolveDigestPrefix(versions, "abc123", true, &warn)
+		require.Error(t, err)
+
+		var ambiguous *discover.AmbiguousDigestError
+		require.ErrorAs(t, err, &ambiguous)
+		assert.Equal(t, []string{versions[0].Digest, versions[2].Digest}, ambiguous.Candidates)
+		assert.Contains(t, err.Error(), versions[0].Digest)
+		assert.Contains(t, err.Error(), versions[2].Digest)
+	})
+
+	t.Run("non-strict picks newest with warning", func(t *testing.T) {
+		t.Parallel()
+		var warn strings.Builder
+		digest, err := resolveDigestPrefix(versions, "sha256:abc123", false, &warn)
+		require.NoError(t, err)
+		assert.Equal(t, versions[0].Digest, digest)
+		assert.Contains(t, warn.String(), "matches 2 versions, using the newest")
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		t.Parallel()
+		_, err := resolveDigestPrefix(versions, "999", true, io.Discard)
+		assert.ErrorContains(t, err, "no version found matching digest prefix 999")
+	})
+}
+
+func TestDeleteCmds_StrictDigestDefault(t *testing.T) {
+	t.Parallel()
+	for _, sub := range []string{"graph", "version"} {
+		cmd := NewRootCmd()
+		deleteCmd, _, err := cmd.Find([]string{"delete", sub})
+		require.NoError(t, err)
+
+		flag := deleteCmd.Flags().Lookup("strict-digest")
+		require.NotNil(t, flag, "delete %s", sub)
+		assert.Equal(t, "true", flag.DefValue, "destructive commands must reject ambiguous digests by default")
+	}
+}
+
+// blockedPackageFake serves the versions left in a package and records package deletion
+type blockedPackageFake struct {
+	*mockVersionLister
+	*mockPackageRemover
+}
+
+func newBlockedPackageFake(remaining ...gh.PackageVersionInfo) blockedPackageFake {
+	return blockedPackageFake{&mockVersionLister{versions: remaining}, &mockPackageRemover{}}
+}
+
+func TestExecuteDeleteAllTags_LastTaggedVersionFallback(t *testing.T) {
+	t.Parallel()
+
+	tagged, toDelete, shared := planDeleteAllTags(twoTaggedImagesSharingPlatform(false))
+	last := toDelete[len(toDelete)-1]
+	run := func(fallback *packageFallback) (*mockPackageDeleter, string, error) {
+		mock := newMockPackageDeleter()
+		mock.deleteErrors[last.ID] = fmt.Errorf("400 cannot delete the last tagged version of a package")
+		var buf strings.Builder
+		err := executeDeleteAllTags(context.Background(), mock, deleteAllTagsParams{
+			Owner: "owner", OwnerType: "user", PackageName: "pkg",
+			Tagged: tagged, ToDelete: toDelete, Shared: shared, Force: true, Fallback: fallback,
+		}, &buf, nil)
+		return mock, buf.String(), err
+	}
+
+	t.Run("flag set deletes the package", func(t *testing.T) {
+		t.Parallel()
+		client := newBlockedPackageFake(gh.PackageVersionInfo{ID: last.ID, Tags: last.Tags})
+
+		mock, output, err := run(&packageFallback{Client: client, Force: true})
+		require.NoError(t, err)
+		assert.True(t, client.deleted)
+		assert.Len(t, mock.deletedVersions, 5)
+		assert.Contains(t, output, "Successfully deleted package owner/pkg")
+	})
+
+	t.Run("flag not set only suggests it", func(t *testing.T) {
+		t.Parallel()
+
+		_, output, err := run(nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "stopped after 5 of 6")
+		assert.Contains(t, output, "ghcrctl delete package owner/pkg")
+	})
+}
+
+func TestCheckGraphDeletable(t *testing.T) {
+	t.Parallel()
+	// The v1 graph: tagged index 10 with platform 11 and attestation 12
+	graph := []gh.PackageVersionInfo{
+		{ID: 10, Digest: "sha256:index1", Tags: []string{"v1"}},
+		{ID: 11, Digest: "sha256:amd64"},
+		{ID: 12, Digest: "sha256:attest"},
+	}
+	planned := []int64{12, 11, 10}
+
+	tests := []struct {
+		name      string
+		versions  []gh.PackageVersionInfo
+		ifBlocked bool
+		wantErr   string
+	}{
+		{
+			name:     "other tagged version remains",
+			versions: append(slices.Clone(graph), gh.PackageVersionInfo{ID: 20, Tags: []string{"v2"}}),
+		},
+		{
+			name:     "last tagged version",
+			versions: graph,
+			wantErr:  "nothing deleted: the graph holds the last tagged version of the package",
+		},
+		{
+			name:      "last tagged version with package fallback",
+			versions:  graph,
+			ifBlocked: true,
+		},
+		{
+			name:      "package fallback would lose untagged versions",
+			versions:  append(slices.Clone(graph), gh.PackageVersionInfo{ID: 30}),
+			ifBlocked: true,
+			wantErr:   "because 1 other version(s) would be lost",
+		},
+		{
+			name:     "untagged graph",
+			versions: []gh.PackageVersionInfo{{ID: 11}, {ID: 12}, {ID: 10}, {ID: 40, Tags: []string{"v1"}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := checkGraphDeletable(tt.versions, planned, tt.ifBlocked)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestCheckGraphDeletable_NoChildrenDeleted(t *testing.T) {
+	t.Parallel()
+	versions := []gh.PackageVersionInfo{
+		{ID: 10, Digest: "sha256:index1", Tags: []string{"v1"}},
+		{ID: 11, Digest: "sha256:amd64"},
+	}
+	planned := []int64{11, 10}
+	mock := newMockPackageDeleter()
+	mock.deleteErrors[10] = fmt.Errorf("cannot delete the last tagged version of a package")
+
+	// Without the pre-check, the child is gone before GHCR refuses the root
+	_, err := deleteVersionsInOrder(context.Background(), mock, "owner", "user", "image", planned, io.Discard)
+	require.True(t, gh.IsLastTaggedVersionError(err))
+	assert.Equal(t, []int64{11}, mock.deletedVersions)
+
+	// With it, the undeletable root is found before anything is deleted
+	atomicMock := newMockPackageDeleter()
+	if err := checkGraphDeletable(versions, planned, false); err == nil {
+		_, _ = deleteVersionsInOrder(context.Background(), atomicMock, "owner", "user", "image", planned, io.Discard)
+	}
+	assert.Empty(t, atomicMock.deletedVersions)
+}
+
+func TestDeletePackageIfBlocked(t *testing.T) {
+	t.Parallel()
+	tagged := gh.PackageVersionInfo{ID: 10, Digest: "sha256:index", Tags: []string{"latest"}}
+
+	t.Run("other versions remain", func(t *testing.T) {
+		t.Parallel()
+		client := newBlockedPackageFake(tagged, gh.PackageVersionInfo{ID: 11, Digest: "sha256:keep"})
+
+		var buf strings.Builder
+		deleted, err := deletePackageIfBlocked(context.Background(), &packageFallback{Client: client, Force: true},
+			"owner", "user", "pkg", []int64{10}, &buf)
+		require.NoError(t, err)
+		assert.False(t, deleted)
+		assert.False(t, client.deleted)
+		assert.Contains(t, buf.String(), "1 other version(s) would be lost")
+	})
+
+	t.Run("extra confirmation declined", func(t *testing.T) {
+		t.Parallel()
+		client := newBlockedPackageFake(tagged)
+		asked := false
+
+		var buf strings.Builder
+		deleted, err := deletePackageIfBlocked(context.Background(), &packageFallback{Client: client, Confirm: func() (bool, error) {
+			asked = true
+			return false, nil
+		}}, "owner", "user", "pkg", []int64{10}, &buf)
+		require.NoError(t, err)
+		assert.True(t, asked)
+		assert.False(t, deleted)
+		assert.False(t, client.deleted)
+		assert.Contains(t, buf.String(), "Package deletion cancelled")
+	})
+
+	t.Run("extra confirmation accepted", func(t *testing.T) {
+		t.Parallel()
+		client := newBlockedPackageFake(tagged)
+
+		var buf strings.Builder
+		deleted, err := deletePackageIfBlocked(context.Background(), &packageFallback{Client: client, Confirm: func() (bool, error) {
+			return true, nil
+		}}, "owner", "user", "pkg", []int64{10}, &buf)
+		require.NoError(t, err)
+		assert.True(t, deleted)
+		assert.True(t, client.deleted)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		t.Parallel()
+
+		deleted, err := deletePackageIfBlocked(context.Background(), nil, "owner", "user", "pkg", []int64{10}, new(strings.Builder))
+		require.NoError(t, err)
+		assert.False(t, deleted)
+	})
+}
+
+func TestDeleteVersionCmd_DeletePackageIfBlockedExcludesBatchSize(t *testing.T) {
+	t.Parallel()
+	cmd := NewRootCmd()
+	cmd.SetOut(new(strings.Builder))
+	cmd.SetErr(new(strings.Builder))
+	cmd.SetArgs([]string{"delete", "version", "owner/pkg", "--untagged", "--batch-size", "50", "--delete-package-if-blocked"})
+
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "delete-package-if-blocked")
+}
+
+func TestSelectExtremeVersion(t *testing.T) {
+	t.Parallel()
+	versions := []gh.PackageVersionInfo{
+		{ID: 20, CreatedAt: "2025-01-02T00:00:00Z"},
+		{ID: 11, CreatedAt: "2025-01-01T00:00:00Z"},
+		{ID: 10, CreatedAt: "2025-01-01T00:00:00Z"},
+		{ID: 31, CreatedAt: "2025-01-03 12:00:00"},
+		{ID: 30, CreatedAt: "2025-01-03 12:00:00"},
+		{ID: 99, CreatedAt: "not a date"},
+	}
+
+	oldest, err := selectExtremeVersion(versions, false)
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), oldest.ID, "ties are broken by the lower version ID")
+
+	newest, err := selectExtremeVersion(versions, true)
+	require.NoError(t, err)
+	assert.Equal(t, int64(31), newest.ID, "ties are broken by the higher version ID")
+
+	_, err = selectExtremeVersion([]gh.PackageVersionInfo{{ID: 99, CreatedAt: "not a date"}}, false)
+	assert.EqualError(t, err, "no versions match the specified filters")
+}
+
+func TestDeleteVersionCmd_OldestNewestValidation(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{
+			name:    "oldest and newest",
+			args:    []string{"--oldest", "--newest"},
+			wantErr: "none of the others can be",
+		},
+		{
+			name:    "oldest and version",
+			args:    []string{"--oldest", "--version", "123"},
+			wantErr: "none of the others can be",
+		},
+		{
+			name:    "oldest with batch size",
+			args:    []string{"--untagged", "--oldest", "--batch-size", "50"},
+			wantErr: "--batch-size only applies to bulk deletion with filter flags",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cmd := NewRootCmd()
+			cmd.SetOut(new(strings.Builder))
+			cmd.SetErr(new(strings.Builder))
+			cmd.SetArgs(append([]string{"delete", "version", "owner/pkg"}, tt.args...))
+
+			err := cmd.Execute()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+// countingVersionLister counts listings of a fixed set of versions
+type countingVersionLister struct {
+	versions []gh.PackageVersionInfo
+	err      error
+	calls    int
+}
+
+func (l *countingVersionLister) ListPackageVersions(ctx context.Context, owner, ownerType, packageName string) ([]gh.PackageVersionInfo, error) {
+	l.calls++
+	return l.versions, l.err
+}
+
+func TestFindSingleVersion(t *testing.T) {
+	t.Parallel()
+	versions := []gh.PackageVersionInfo{
+		{ID: 1, Digest: "sha256:aaa", Tags: []string{"v1.0.0", "latest"}},
+		{ID: 2, Digest: "sha256:bbb"},
+	}
+
+	t.Run("by ID with one listing", func(t *testing.T) {
+		t.Parallel()
+		lister := &countingVersionLister{versions: versions}
+		ver, all, err := findSingleVersion(context.Background(), lister, "mkoepf", "user", "myimage", 1, "", true, io.Discard)
+		require.NoError(t, err)
+		assert.Equal(t, "sha256:aaa", ver.Digest)
+		assert.Equal(t, []string{"v1.0.0", "latest"}, ver.Tags)
+		assert.Len(t, all, 2)
+		assert.Equal(t, 1, lister.calls)
+	})
+
+	t.Run("by digest with one listing", func(t *testing.T) {
+		t.Parallel()
+		lister := &countingVersionLister{versions: versions}
+		ver, _, err := findSingleVersion(context.Background(), lister, "mkoepf", "user", "myimage", 0, "sha256:bbb", true, io.Discard)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), ver.ID)
+		assert.Equal(t, 1, lister.calls)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		t.Parallel()
+		lister := &countingVersionLister{versions: versions}
+		_, _, err := findSingleVersion(context.Background(), lister, "mkoepf", "user", "myimage", 0, "sha256:ccc", true, io.Discard)
+		require.ErrorIs(t, err, gh.ErrNotFound)
+		assert.Contains(t, err.Error(), "sha256:ccc")
+
+		_, _, err = findSingleVersion(context.Background(), lister, "mkoepf", "user", "myimage", 99, "", true, io.Discard)
+		require.ErrorIs(t, err, gh.ErrNotFound)
+	})
+}
+
+func TestFindSingleVersion_ShortDigest(t *testing.T) {
+	t.Parallel()
+	// Newest first, two versions share the "abc" prefix
+	versions := []gh.PackageVersionInfo{
+		{ID: 3, Digest: "sha256:abc999"},
+		{ID: 2, Digest: "sha256:def456"},
+		{ID: 1, Digest: "sha256:abc123"},
+	}
+
+	t.Run("unique prefix", func(t *testing.T) {
+		t.Parallel()
+		ver, _, err := findSingleVersion(context.Background(), &countingVersionLister{versions: versions}, "mkoepf", "user", "myimage", 0, "def", true, io.Discard)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), ver.ID)
+	})
+
+	t.Run("strict rejects ambiguous prefix", func(t *testing.T) {
+		t.Parallel()
+		_, _, err := findSingleVersion(context.Background(), &countingVersionLister{versions: versions}, "mkoepf", "user", "myimage", 0, "sha256:abc", true, io.Discard)
+		var ambiguous *discover.AmbiguousDigestError
+		require.ErrorAs(t, err, &ambiguous)
+		assert.Equal(t, []string{"sha256:abc999", "sha256:abc123"}, ambiguous.Candidates)
+	})
+
+	t.Run("non-strict picks newest", func(t *testing.T) {
+		t.Parallel()
+		var warn strings.Builder
+		ver, _, err := findSingleVersion(context.Background(), &countingVersionLister{versions: versions}, "mkoepf", "user", "myimage", 0, "abc", false, &warn)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), ver.ID)
+		assert.Contains(t, warn.String(), "matches 2 versions, using the newest")
+	})
+}
+
+func TestDeleteSameDigest_DuplicateVersionIDs(t *testing.T) {
+	t.Parallel()
+	// A re-pushed digest is listed under two version IDs, and a short
+	// digest must resolve to it without being ambiguous
+	versions := []gh.PackageVersionInfo{
+		{ID: 5, Digest: "sha256:abc123", Tags: []string{"v1"}},
+		{ID: 4, Digest: "sha256:def456"},
+		{ID: 3, Digest: "sha256:abc123"},
+	}
+
+	digest, err := resolveDigestPrefix(versions, "abc", true, io.Discard)
+	require.NoError(t, err)
+	assert.Equal(t, "sha256:abc123", digest)
+
+	targets := versionsWithDigest(versions, digest)
+	assert.Equal(t, []int64{5, 3}, versionIDsOf(targets))
+
+	t.Run("deletes every version ID", func(t *testing.T) {
+		t.Parallel()
+		deleter := newMockPackageDeleter()
+		deleted, err := deleteSameDigest(context.Background(), deleter, "mkoepf", "user", "myimage", targets)
+		require.NoError(t, err)
+		assert.Equal(t, []int64{5, 3}, versionIDsOf(deleted))
+		assert.Equal(t, []int64{5, 3}, deleter.deletedVersions)
+	})
+
+	t.Run("stops at a failure", func(t *testing.T) {
+		t.Parallel()
+		deleter := newMockPackageDeleter()
+		deleter.deleteErrors[3] = fmt.Errorf("server error")
+		deleted, err := deleteSameDigest(context.Background(), deleter, "mkoepf", "user", "myimage", targets)
+		require.Error(t, err)
+		assert.Equal(t, []int64{5}, versionIDsOf(deleted))
+	})
+}
+
+func TestDeleteVersionsEach_ReportsDeletedIDs(t *testing.T) {
+	t.Parallel()
+	versions := []gh.PackageVersionInfo{{ID: 1}, {ID: 2}, {ID: 3}}
+	deleter := newMockPackageDeleter()
+	deleter.deleteErrors[2] = fmt.Errorf("server error")
+
+	var buf bytes.Buffer
+	results, deletedIDs, err := deleteVersionsEach(context.Background(), deleter, "mkoepf", "user", "myimage", versions, &buf)
+	require.NoError(t, err)
+
+	assert.Equal(t, []int64{1, 3}, deletedIDs, "deletion continues after a failure")
+	require.Len(t, results, 3)
+	assert.NoError(t, results[0])
+	assert.EqualError(t, results[1], "server error")
+	assert.NoError(t, results[2])
+	assert.Contains(t, buf.String(), "Deleting version 3/3 (ID: 3)...")
+	assert.Contains(t, buf.String(), "Failed: server error")
+}
+
+func TestCountIncomingRefsIn_UsesGivenVersions(t *testing.T) {
+	t.Parallel()
+	versions := []gh.PackageVersionInfo{
+		{ID: 1, Digest: "sha256:index"},
+		{ID: 2, Digest: "sha256:amd64"},
+		{ID: 3, Digest: "sha256:sig"},
+	}
+	discoverer := incomingRefsDiscoverer{refs: map[string][]string{
+		"sha256:amd64": {"sha256:index", "sha256:sig"},
+	}}
+
+	ctx := context.Background()
+	assert.Equal(t, 2, countIncomingRefsIn(ctx, discoverer, "ghcr.io/mkoepf/myimage", versions, 2))
+	assert.Equal(t, 0, countIncomingRefsIn(ctx, discoverer, "ghcr.io/mkoepf/myimage", versions, 1))
+}
+
+// incomingRefsDiscoverer reports fixed incoming refs per digest
+type incomingRefsDiscoverer struct {
+	refs map[string][]string
+}
+
+func (d incomingRefsDiscoverer) DiscoverPackage(ctx context.Context, image string, versions []gh.PackageVersionInfo, allTags []string) ([]discover.VersionInfo, error) {
+	result := make([]discover.VersionInfo, 0, len(versions))
+	for _, v := range versions {
+		result = append(result, discover.VersionInfo{ID: v.ID, Digest: v.Digest, IncomingRefs: d.refs[v.Digest]})
+	}
+	return result, nil
+}
+
+func TestLimitOldest(t *testing.T) {
+	t.Parallel()
+	versions := []gh.PackageVersionInfo{
+		{ID: 40, CreatedAt: "2025-01-04T00:00:00Z"},
+		{ID: 99, CreatedAt: "not a date"},
+		{ID: 11, CreatedAt: "2025-01-01T00:00:00Z"},
+		{ID: 30, CreatedAt: "2025-01-03T00:00:00Z"},
+		{ID: 10, CreatedAt: "2025-01-01T00:00:00Z"},
+		{ID: 20, CreatedAt: "2025-01-02T00:00:00Z"},
+	}
+
+	ids := func(versions []gh.PackageVersionInfo) []int64 {
+		var result []int64
+		for _, v := range versions {
+			result = append(result, v.ID)
+		}
+		return result
+	}
+
+	limited, remaining := limitOldest(versions, 3)
+	assert.Equal(t, []int64{10, 11, 20}, ids(limited), "oldest first, ties broken by version ID")
+	assert.Equal(t, 3, remaining)
+
+	limited, remaining = limitOldest(versions, 6)
+	assert.Equal(t, ids(versions), ids(limited), "nothing is left out when the cap is not reached")
+	assert.Zero(t, remaining)
+
+	limited, remaining = limitOldest(versions, 0)
+	assert.Equal(t, ids(versions), ids(limited))
+	assert.Zero(t, remaining)
+
+	limited, remaining = limitOldest(versions, 5)
+	assert.Equal(t, []int64{10, 11, 20, 30, 40}, ids(limited), "unparseable dates count as newest")
+	assert.Equal(t, 1, remaining)
+}
+
+func TestDeleteVersionCmd_MaxDeleteValidation(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{
+			name:    "requires filter flags",
+			args:    []string{"--version", "123", "--max-delete", "10"},
+			wantErr: "--max-delete only applies to bulk deletion with filter flags",
+		},
+		{
+			name:    "not with oldest",
+			args:    []string{"--untagged", "--oldest", "--max-delete", "10"},
+			wantErr: "--max-delete only applies to bulk deletion with filter flags",
+		},
+		{
+			name:    "must be positive",
+			args:    []string{"--untagged", "--max-delete", "0"},
+			wantErr: "--max-delete must be at least 1, got 0",
+		},
+		{
+			name:    "not with batch size",
+			args:    []string{"--untagged", "--max-delete", "10", "--batch-size", "50"},
+			wantErr: "none of the others can be",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cmd := NewRootCmd()
+			cmd.SetOut(new(strings.Builder))
+			cmd.SetErr(new(strings.Builder))
+			cmd.SetArgs(append([]string{"delete", "version", "owner/pkg"}, tt.args...))
+
+			err := cmd.Execute()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestVerifyDeleted(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	t.Run("deleted versions are gone", func(t *testing.T) {
+		t.Parallel()
+		lister := &countingVersionLister{versions: []gh.PackageVersionInfo{{ID: 3}}}
+		var buf strings.Builder
+		require.NoError(t, verifyDeleted(ctx, lister, "mkoepf", "user", "myimage", []int64{1, 2}, &buf))
+		assert.Contains(t, buf.String(), "Verified: 2 deleted version(s) are no longer listed")
+	})
+
+	t.Run("lingering version is reported", func(t *testing.T) {
+		t.Parallel()
+		lister := &countingVersionLister{versions: []gh.PackageVersionInfo{{ID: 2}, {ID: 3}}}
+		err := verifyDeleted(ctx, lister, "mkoepf", "user", "myimage", []int64{1, 2}, io.Discard)
+		assert.EqualError(t, err, "verification failed: 1 deleted version(s) of myimage are still listed: 2")
+	})
+
+	t.Run("package gone", func(t *testing.T) {
+		t.Parallel()
+		lister := &countingVersionLister{err: fmt.Errorf("package myimage %w", gh.ErrNotFound)}
+		var buf strings.Builder
+		require.NoError(t, verifyDeleted(ctx, lister, "mkoepf", "user", "myimage", []int64{1}, &buf))
+		assert.Contains(t, buf.String(), "Verified: package myimage no longer exists")
+	})
+
+	t.Run("listing fails", func(t *testing.T) {
+		t.Parallel()
+		lister := &countingVersionLister{err: fmt.Errorf("rate limited")}
+		err := verifyDeleted(ctx, lister, "mkoepf", "user", "myimage", []int64{1}, io.Discard)
+		assert.EqualError(t, err, "failed to verify deletion: rate limited")
+	})
+}
+
+func TestDeleteCmd_VerifyValidation(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "version with dry run", args: []string{"delete", "version", "owner/pkg", "--untagged", "--verify", "--dry-run"}},
+		{name: "version with batch size", args: []string{"delete", "version", "owner/pkg", "--untagged", "--verify", "--batch-size", "50"}},
+		{name: "graph with all tags", args: []string{"delete", "graph", "owner/pkg", "--all-tags", "--verify"}},
+		{name: "graph with json", args: []string{"delete", "graph", "owner/pkg", "--tag", "v1", "--force", "--json", "--verify"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cmd := NewRootCmd()
+			cmd.SetOut(new(strings.Builder))
+			cmd.SetErr(new(strings.Builder))
+			cmd.SetArgs(tt.args)
+
+			err := cmd.Execute()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "none of the others can be")
+		})
+	}
+}
+
+func TestExcludeVersions(t *testing.T) {
+	t.Parallel()
+	versions := []gh.PackageVersionInfo{
+		{ID: 1, Digest: "sha256:aaa111"},
+		{ID: 2, Digest: "sha256:bbb222"},
+		{ID: 3, Digest: "sha256:ccc333"},
+		{ID: 4, Digest: "sha256:ddd444"},
+	}
+
+	t.Run("nothing excluded", func(t *testing.T) {
+		t.Parallel()
+		kept, excluded := excludeVersions(versions, nil, nil)
+		assert.Equal(t, versions, kept)
+		assert.Empty(t, excluded)
+	})
+
+	t.Run("by id and digest", func(t *testing.T) {
+		t.Parallel()
+		kept, excluded := excludeVersions(versions, []int64{2, 99}, []string{"ccc"})
+		assert.Equal(t, []int64{1, 4}, packageVersionIDs(kept))
+		assert.Equal(t, []int64{2, 3}, packageVersionIDs(excluded))
+	})
+
+	t.Run("full digest", func(t *testing.T) {
+		t.Parallel()
+		kept, excluded := excludeVersions(versions, nil, []string{"sha256:ddd444"})
+		assert.Equal(t, []int64{1, 2, 3}, packageVersionIDs(kept))
+		assert.Equal(t, []int64{4}, packageVersionIDs(excluded))
+	})
+}
+
+func TestExcludeVersions_NeverReachDeleter(t *testing.T) {
+	t.Parallel()
+	versions := []gh.PackageVersionInfo{
+		{ID: 100, Digest: "sha256:aaa111", CreatedAt: "2025-01-01"},
+		{ID: 101, Digest: "sha256:bbb222", CreatedAt: "2025-01-02"},
+		{ID: 102, Digest: "sha256:ccc333", CreatedAt: "2025-01-03"},
+	}
+	// All three match an untagged filter; two are excluded
+	matching := (&filter.VersionFilter{OnlyUntagged: true}).Apply(versions)
+	require.Len(t, matching, 3)
+	kept, _ := excludeVersions(matching, []int64{100}, []string{"ccc333"})
+
+	mock := newMockPackageDeleter()
+	err := ExecuteBulkDelete(context.Background(), mock, BulkDeleteParams{
+		Owner: "owner", OwnerType: "user", PackageName: "pkg", Versions: kept, Force: true,
+	}, io.Discard, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{101}, mock.deletedVersions)
+}
+
+func TestDeleteVersionCmd_ExcludeValidation(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{
+			name:    "requires filter flags",
+			args:    []string{"--version", "123", "--exclude-version", "456"},
+			wantErr: "--exclude-version and --exclude-digest only apply to bulk deletion with filter flags",
+		},
+		{
+			name:    "not with newest",
+			args:    []string{"--untagged", "--newest", "--exclude-digest", "abc123"},
+			wantErr: "--exclude-version and --exclude-digest only apply to bulk deletion with filter flags",
+		},
+		{
+			name:    "not with batch size",
+			args:    []string{"--untagged", "--exclude-version", "456", "--batch-size", "50"},
+			wantErr: "none of the others can be",
+		},
+		{
+			name:    "invalid id",
+			args:    []string{"--untagged", "--exclude-version", "abc"},
+			wantErr: "invalid argument",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cmd := NewRootCmd()
+			cmd.SetOut(new(strings.Builder))
+			cmd.SetErr(new(strings.Builder))
+			cmd.SetArgs(append([]string{"delete", "version", "owner/pkg"}, tt.args...))
+
+			err := cmd.Execute()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+// mockSubjectExtractor returns the subjects recorded for each digest
+type mockSubjectExtractor map[string][]string
+
+func (m mockSubjectExtractor) SubjectDigests(ctx context.Context, image, digest string) ([]string, error) {
+	subjects, ok := m[digest]
+	if !ok {
+		return nil, fmt.Errorf("manifest unknown")
+	}
+	return subjects, nil
+}
+
+func TestSelectOrphanAttestations(t *testing.T) {
+	t.Parallel()
+	// The amd64 image was deleted; its SBOM and signature are left behind.
+	// The cosign attestation of the index looks unattached to discovery, but
+	// its subject still exists.
+	discovered := []discover.VersionInfo{
+		{ID: 1, Digest: "sha256:index", Types: []string{"index"}, Tags: []string{"v1"}, OutgoingRefs: []string{"sha256:arm64", "sha256:att-arm64"}},
+		{ID: 2, Digest: "sha256:arm64", Types: []string{"linux/arm64"}, IncomingRefs: []string{"sha256:index"}},
+		{ID: 3, Digest: "sha256:att-arm64", Types: []string{"sbom"}, IncomingRefs: []string{"sha256:index"}},
+		{ID: 4, Digest: "sha256:att-amd64", Types: []string{"sbom", "provenance"}},
+		{ID: 5, Digest: "sha256:sig-amd64", Types: []string{"signature"}, DuplicateIDs: []int64{9}},
+		{ID: 6, Digest: "sha256:cosign-att", Types: []string{"sbom"}, Tags: []string{"sha256-index.att"}},
+		{ID: 7, Digest: "sha256:unreadable", Types: []string{"vex"}},
+		{ID: 8, Digest: "sha256:old-platform", Types: []string{"linux/amd64"}},
+	}
+	var matching []gh.PackageVersionInfo
+	for _, v := range discovered {
+		for _, id := range v.VersionIDs() {
+			matching = append(matching, gh.PackageVersionInfo{ID: id, Digest: v.Digest, Tags: v.Tags})
+		}
+	}
+	extractor := mockSubjectExtractor{
+		"sha256:att-arm64":  {"sha256:arm64"},
+		"sha256:att-amd64":  {"sha256:amd64"},
+		"sha256:sig-amd64":  {"sha256:amd64"},
+		"sha256:cosign-att": {"sha256:index"},
+	}
+
+	var buf bytes.Buffer
+	orphans := selectOrphanAttestations(context.Background(), extractor, "ghcr.io/owner/pkg", discovered, matching, &buf)
+
+	var ids []int64
+	for _, v := range orphans {
+		ids = append(ids, v.ID)
+	}
+	assert.Equal(t, []int64{4, 5, 9}, ids)
+	assert.Contains(t, buf.String(), "1 attestation(s) without a parent in the graph still have their subject")
+	assert.Contains(t, buf.String(), "1 attestation(s) without a parent in the graph have no readable subject")
+}
+
+func TestDeleteVersionCmd_OrphanAttestationsValidation(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{
+			name:    "not with single selector",
+			args:    []string{"--orphan-attestations-only", "--version", "123"},
+			wantErr: "--orphan-attestations-only only applies to bulk deletion",
+		},
+		{
+			name:    "not with batch size",
+			args:    []string{"--orphan-attestations-only", "--batch-size", "50"},
+			wantErr: "none of the others can be",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cmd := NewRootCmd()
+			cmd.SetOut(new(strings.Builder))
+			cmd.SetErr(new(strings.Builder))
+			cmd.SetArgs(append([]string{"delete", "version", "owner/pkg"}, tt.args...))
+
+			err := cmd.Execute()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestDeleteVersionCmd_AgeFlagsValidation(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{
+			name:    "date given as age",
+			args:    []string{"--min-age", "2025-01-01"},
+			wantErr: `invalid --min-age: "2025-01-01" is a date, not an age`,
+		},
+		{
+			name:    "not with older-than",
+			args:    []string{"--min-age", "30d", "--older-than", "60d"},
+			wantErr: "none of the others can be",
+		},
+		{
+			name:    "not with newer-than",
+			args:    []string{"--max-age", "30d", "--newer-than", "60d"},
+			wantErr: "none of the others can be",
+		},
+		{
+			name:    "empty range",
+			args:    []string{"--min-age", "30d", "--max-age", "7d"},
+			wantErr: "no version can match",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cmd := NewRootCmd()
+			cmd.SetOut(new(strings.Builder))
+			cmd.SetErr(new(strings.Builder))
+			cmd.SetArgs(append([]string{"delete", "version", "owner/pkg"}, tt.args...))
+
+			err := cmd.Execute()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
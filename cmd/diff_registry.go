@@ -0,0 +1,267 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/mkoepf/ghcrctl/internal/discover"
+	"github.com/mkoepf/ghcrctl/internal/display"
+	"github.com/mkoepf/ghcrctl/internal/quiet"
+	"github.com/spf13/cobra"
+)
+
+// Tag drift statuses reported by diff-registry
+const (
+	driftMatch         = "match"
+	driftMismatch      = "mismatch"
+	driftMissingMirror = "missing-in-mirror"
+	driftMissingSource = "missing-in-source"
+)
+
+// tagDrift describes how a single tag compares between GHCR and the mirror
+type tagDrift struct {
+	Tag          string `json:"tag"`
+	Status       string `json:"status"`
+	SourceDigest string `json:"source_digest,omitempty"`
+	MirrorDigest string `json:"mirror_digest,omitempty"`
+}
+
+// registryTagResolver is an interface for listing and resolving tags in any OCI registry
+type registryTagResolver interface {
+	ListTags(ctx context.Context, image string) ([]string, error)
+	ResolveTag(ctx context.Context, image, tag string) (string, error)
+}
+
+// orasTagResolver resolves tags using the discover package
+type orasTagResolver struct{}
+
+func (orasTagResolver) ListTags(ctx context.Context, image string) ([]string, error) {
+	return discover.ListTags(ctx, image)
+}
+
+func (orasTagResolver) ResolveTag(ctx context.Context, image, tag string) (string, error) {
+	return discover.ResolveTag(ctx, image, tag)
+}
+
+// diffRegistryParams contains parameters for diff-registry execution
+type diffRegistryParams struct {
+	SourceImage string
+	MirrorImage string
+	JSONOutput  bool
+	QuietMode   bool
+}
+
+func newDiffRegistryCmd() *cobra.Command {
+	var (
+		against    string
+		jsonOutput bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "diff-registry <owner/package>",
+		Short: "Compare tags between GHCR and a mirror registry",
+		Long: `Compare the tags of a GHCR package with a mirrored repository to detect drift.
+
+Tags are listed on both sides. For every tag present on both sides, the digest
+is resolved in each registry and reported as a match or mismatch. Tags present
+on only one side are reported as missing.
+
+--against accepts a full repository reference (registry/path). If only a
+registry host is given, the GHCR owner/package path is assumed.
+
+Examples:
+  # Compare against a mirror with the same path
+  ghcrctl diff-registry mkoepf/myimage --against registry.example.com
+
+  # Compare against a mirror with a different path
+  ghcrctl diff-registry mkoepf/myimage --against registry.example.com/mirror/myimage
+
+  # Output as JSON
+  ghcrctl diff-registry mkoepf/myimage --against registry.example.com --json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			owner, packageName, err := parsePackageRef(args[0], defaultOwner(cmd))
+			if err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
+
+			mirrorImage, err := mirrorImageRef(against, owner, packageName)
+			if err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
+
+			cmd.SilenceUsage = true
+			return executeDiffRegistry(cmd.Context(), orasTagResolver{}, diffRegistryParams{
+				SourceImage: fmt.Sprintf("ghcr.io/%s/%s", owner, packageName),
+				MirrorImage: mirrorImage,
+				JSONOutput:  jsonOutput,
+				QuietMode:   quiet.IsQuiet(cmd.Context()),
+			}, cmd.OutOrStdout())
+		},
+	}
+
+	cmd.Flags().StringVar(&against, "against", "", "Mirror registry or repository reference to compare with")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output in JSON format")
+	_ = cmd.MarkFlagRequired("against")
+
+	cmd.ValidArgsFunction = imageRefValidArgsFunc
+
+	return cmd
+}
+
+// mirrorImageRef builds the mirror repository reference from the --against value.
+// A bare registry host is combined with the GHCR owner/package path.
+func mirrorImageRef(against, owner, packageName string) (string, error) {
+	against = strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(against, "https://"), "http://"), "/")
+	if against == "" {
+		return "", fmt.Errorf("--against cannot be empty")
+	}
+	if !strings.Contains(against, "/") {
+		against = fmt.Sprintf("%s/%s/%s", against, owner, packageName)
+	}
+	if _, _, err := discover.ParseImageReference(against); err != nil {
+		return "", fmt.Errorf("invalid --against value: %w", err)
+	}
+	return against, nil
+}
+
+// executeDiffRegistry compares tags between the source and mirror repositories
+func executeDiffRegistry(ctx context.Context, resolver registryTagResolver, params diffRegistryParams, out io.Writer) error {
+	sourceTags, err := resolver.ListTags(ctx, params.SourceImage)
+	if err != nil {
+		return fmt.Errorf("failed to list tags in %s: %w", params.SourceImage, err)
+	}
+
+	mirrorTags, err := resolver.ListTags(ctx, params.MirrorImage)
+	if err != nil {
+		return fmt.Errorf("failed to list tags in %s: %w", params.MirrorImage, err)
+	}
+
+	drifts, err := compareRegistryTags(ctx, resolver, params.SourceImage, params.MirrorImage, sourceTags, mirrorTags)
+	if err != nil {
+		return err
+	}
+
+	if params.JSONOutput {
+		return display.OutputJSON(ctx, out, drifts)
+	}
+
+	return outputDiffRegistryTable(out, drifts, params.SourceImage, params.MirrorImage, params.QuietMode)
+}
+
+// compareRegistryTags resolves every common tag on both sides and classifies all tags.
+// Results are sorted by tag name.
+func compareRegistryTags(ctx context.Context, resolver registryTagResolver, sourceImage, mirrorImage string, sourceTags, mirrorTags []string) ([]tagDrift, error) {
+	inMirror := make(map[string]bool, len(mirrorTags))
+	for _, tag := range mirrorTags {
+		inMirror[tag] = true
+	}
+	inSource := make(map[string]bool, len(sourceTags))
+	for _, tag := range sourceTags {
+		inSource[tag] = true
+	}
+
+	drifts := make([]tagDrift, 0, len(sourceTags)+len(mirrorTags))
+	for tag := range inSource {
+		if !inMirror[tag] {
+			drifts = append(drifts, tagDrift{Tag: tag, Status: driftMissingMirror})
+			continue
+		}
+
+		sourceDigest, err := resolver.ResolveTag(ctx, sourceImage, tag)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve tag '%s' in %s: %w", tag, sourceImage, err)
+		}
+		mirrorDigest, err := resolver.ResolveTag(ctx, mirrorImage, tag)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve tag '%s' in %s: %w", tag, mirrorImage, err)
+		}
+
+		status := driftMatch
+		if sourceDigest != mirrorDigest {
+			status = driftMismatch
+		}
+		drifts = append(drifts, tagDrift{Tag: tag, Status: status, SourceDigest: sourceDigest, MirrorDigest: mirrorDigest})
+	}
+	for tag := range inMirror {
+		if !inSource[tag] {
+			drifts = append(drifts, tagDrift{Tag: tag, Status: driftMissingSource})
+		}
+	}
+
+	sort.Slice(drifts, func(i, j int) bool {
+		return drifts[i].Tag < drifts[j].Tag
+	})
+	return drifts, nil
+}
+
+// outputDiffRegistryTable outputs the tag comparison as a table with a summary
+func outputDiffRegistryTable(w io.Writer, drifts []tagDrift, sourceImage, mirrorImage string, quietMode bool) error {
+	if !quietMode {
+		fmt.Fprintf(w, "Comparing %s with %s\n\n", sourceImage, mirrorImage)
+	}
+
+	if len(drifts) == 0 {
+		fmt.Fprintln(w, "No tags found in either registry")
+		return nil
+	}
+
+	tagWidth := len("TAG")
+	for _, d := range drifts {
+		if width := display.Width(d.Tag); width > tagWidth {
+			tagWidth = width
+		}
+	}
+	statusWidth := len(driftMissingMirror)
+
+	fmt.Fprintf(w, "%s  %s  %s  %s\n",
+		display.ColorHeader(fmt.Sprintf("%-*s", tagWidth, "TAG")),
+		display.ColorHeader(fmt.Sprintf("%-*s", statusWidth, "STATUS")),
+		display.ColorHeader(fmt.Sprintf("%-12s", "SOURCE")),
+		display.ColorHeader("MIRROR"))
+	fmt.Fprintf(w, "%s  %s  %s  %s\n",
+		display.ColorSeparator(strings.Repeat("-", tagWidth)),
+		display.ColorSeparator(strings.Repeat("-", statusWidth)),
+		display.ColorSeparator(strings.Repeat("-", 12)),
+		display.ColorSeparator(strings.Repeat("-", 12)))
+
+	counts := make(map[string]int)
+	for _, d := range drifts {
+		counts[d.Status]++
+
+		status := fmt.Sprintf("%-*s", statusWidth, d.Status)
+		switch d.Status {
+		case driftMatch:
+			status = display.ColorSuccess(status)
+		case driftMismatch:
+			status = display.ColorError(status)
+		default:
+			status = display.ColorWarning(status)
+		}
+
+		fmt.Fprintf(w, "%s  %s  %-12s  %s\n",
+			display.PadRight(d.Tag, tagWidth),
+			status,
+			shortDigestOrDash(d.SourceDigest),
+			shortDigestOrDash(d.MirrorDigest))
+	}
+
+	if !quietMode {
+		fmt.Fprintf(w, "\n%d match, %d mismatch, %d missing in mirror, %d missing in source\n",
+			counts[driftMatch], counts[driftMismatch], counts[driftMissingMirror], counts[driftMissingSource])
+	}
+	return nil
+}
+
+// shortDigestOrDash returns the short digest or "-" if the digest is empty
+func shortDigestOrDash(digest string) string {
+	if digest == "" {
+		return "-"
+	}
+	return display.ShortDigest(digest)
+}
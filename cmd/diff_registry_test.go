@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockRegistries maps image reference -> tag -> digest
+type mockRegistries map[string]map[string]string
+
+func (m mockRegistries) ListTags(ctx context.Context, image string) ([]string, error) {
+	repo, ok := m[image]
+	if !ok {
+		return nil, fmt.Errorf("repository %s not found", image)
+	}
+	var tags []string
+	for tag := range repo {
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+func (m mockRegistries) ResolveTag(ctx context.Context, image, tag string) (string, error) {
+	digest, ok := m[image][tag]
+	if !ok {
+		return "", fmt.Errorf("tag %s not found in %s", tag, image)
+	}
+	return digest, nil
+}
+
+const (
+	testSourceImage = "ghcr.io/owner/pkg"
+	testMirrorImage = "registry.example.com/mirror/pkg"
+)
+
+func newTestRegistries() mockRegistries {
+	return mockRegistries{
+		testSourceImage: {
+			"v1.0.0": "sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+			"v1.1.0": "sha256:bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb",
+			"latest": "sha256:cccccccccccccccccccccccccccccccccccccccccccccccccccccccccccccccc",
+		},
+		testMirrorImage: {
+			"v1.0.0": "sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+			"latest": "sha256:bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb",
+			"legacy": "sha256:dddddddddddddddddddddddddddddddddddddddddddddddddddddddddddddddd",
+		},
+	}
+}
+
+func TestCompareRegistryTags(t *testing.T) {
+	t.Parallel()
+	registries := newTestRegistries()
+	ctx := context.Background()
+
+	sourceTags, _ := registries.ListTags(ctx, testSourceImage)
+	mirrorTags, _ := registries.ListTags(ctx, testMirrorImage)
+
+	drifts, err := compareRegistryTags(ctx, registries, testSourceImage, testMirrorImage, sourceTags, mirrorTags)
+	require.NoError(t, err)
+
+	statuses := make(map[string]string)
+	var order []string
+	for _, d := range drifts {
+		statuses[d.Tag] = d.Status
+		order = append(order, d.Tag)
+	}
+
+	assert.Equal(t, []string{"latest", "legacy", "v1.0.0", "v1.1.0"}, order, "results should be sorted by tag")
+	assert.Equal(t, driftMismatch, statuses["latest"])
+	assert.Equal(t, driftMissingSource, statuses["legacy"])
+	assert.Equal(t, driftMatch, statuses["v1.0.0"])
+	assert.Equal(t, driftMissingMirror, statuses["v1.1.0"])
+}
+
+func TestExecuteDiffRegistry_Table(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	err := executeDiffRegistry(context.Background(), newTestRegistries(), diffRegistryParams{
+		SourceImage: testSourceImage,
+		MirrorImage: testMirrorImage,
+	}, &buf)
+	require.NoError(t, err)
+
+	output := buf.String()
+	assert.Contains(t, output, "Comparing ghcr.io/owner/pkg with registry.example.com/mirror/pkg")
+	assert.Contains(t, output, "TAG")
+	assert.Contains(t, output, "missing-in-mirror")
+	assert.Contains(t, output, "1 match, 1 mismatch, 1 missing in mirror, 1 missing in source")
+}
+
+func TestExecuteDiffRegistry_JSON(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	err := executeDiffRegistry(context.Background(), newTestRegistries(), diffRegistryParams{
+		SourceImage: testSourceImage,
+		MirrorImage: testMirrorImage,
+		JSONOutput:  true,
+	}, &buf)
+	require.NoError(t, err)
+
+	var drifts []tagDrift
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &drifts))
+	require.Len(t, drifts, 4)
+	assert.Equal(t, "latest", drifts[0].Tag)
+	assert.NotEqual(t, drifts[0].SourceDigest, drifts[0].MirrorDigest)
+	assert.Empty(t, drifts[1].SourceDigest, "tags missing in source have no source digest")
+}
+
+func TestExecuteDiffRegistry_MirrorListError(t *testing.T) {
+	t.Parallel()
+
+	registries := newTestRegistries()
+	delete(registries, testMirrorImage)
+
+	err := executeDiffRegistry(context.Background(), registries, diffRegistryParams{
+		SourceImage: testSourceImage,
+		MirrorImage: testMirrorImage,
+	}, &bytes.Buffer{})
+	assert.ErrorContains(t, err, "failed to list tags in registry.example.com/mirror/pkg")
+}
+
+func TestMirrorImageRef(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		against string
+		want    string
+		wantErr bool
+	}{
+		{"bare registry host", "registry.example.com", "registry.example.com/owner/pkg", false},
+		{"full repository", "registry.example.com/mirror/pkg", "registry.example.com/mirror/pkg", false},
+		{"scheme and trailing slash are stripped", "https://registry.example.com/", "registry.example.com/owner/pkg", false},
+		{"empty", "", "", true},
+		{"not a registry domain", "localhost/mirror", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mirrorImageRef(tt.against, "owner", "pkg")
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDiffRegistryCmd_RequiresAgainst(t *testing.T) {
+	t.Parallel()
+
+	cmd := NewRootCmd()
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"diff-registry", "owner/pkg"})
+
+	err := cmd.Execute()
+	assert.ErrorContains(t, err, `required flag(s) "against" not set`)
+}
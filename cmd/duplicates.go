@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mkoepf/ghcrctl/internal/display"
+	"github.com/mkoepf/ghcrctl/internal/gh"
+)
+
+// defaultMaxTags is the number of tags per digest above which --duplicates reports tag sprawl.
+// A semantic version release commonly carries v1.2.3, v1.2, v1 and latest.
+const defaultMaxTags = 4
+
+// Reasons a digest is reported by --duplicates
+const (
+	duplicateReasonVersions = "duplicate-versions"
+	duplicateReasonTags     = "tag-sprawl"
+)
+
+// duplicateGroup describes a digest that is a cleanup candidate
+type duplicateGroup struct {
+	Digest     string   `json:"digest"`
+	VersionIDs []int64  `json:"version_ids"`
+	Tags       []string `json:"tags"`
+	Reasons    []string `json:"reasons"`
+}
+
+// findDuplicates groups versions by digest and returns the digests that have more
+// than one version entry or more than maxTags tags, in order of first appearance.
+func findDuplicates(versions []gh.PackageVersionInfo, maxTags int) []duplicateGroup {
+	var order []string
+	groups := make(map[string]*duplicateGroup)
+	for _, ver := range versions {
+		group, ok := groups[ver.Digest]
+		if !ok {
+			group = &duplicateGroup{Digest: ver.Digest, Tags: []string{}}
+			groups[ver.Digest] = group
+			order = append(order, ver.Digest)
+		}
+		group.VersionIDs = append(group.VersionIDs, ver.ID)
+		for _, tag := range ver.Tags {
+			if !containsString(group.Tags, tag) {
+				group.Tags = append(group.Tags, tag)
+			}
+		}
+	}
+
+	var result []duplicateGroup
+	for _, digest := range order {
+		group := groups[digest]
+		if len(group.VersionIDs) > 1 {
+			group.Reasons = append(group.Reasons, duplicateReasonVersions)
+		}
+		if len(group.Tags) > maxTags {
+			group.Reasons = append(group.Reasons, duplicateReasonTags)
+		}
+		if len(group.Reasons) > 0 {
+			result = append(result, *group)
+		}
+	}
+	return result
+}
+
+// containsString reports whether s is in list
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// outputDuplicatesTable prints the cleanup candidates found by findDuplicates
+func outputDuplicatesTable(w io.Writer, groups []duplicateGroup, packageName string, maxTags int, quiet bool) error {
+	if len(groups) == 0 {
+		if !quiet {
+			fmt.Fprintf(w, "No duplicate digests or digests with more than %d tags found in %s\n", maxTags, packageName)
+		}
+		return nil
+	}
+
+	if !quiet {
+		fmt.Fprintf(w, "Cleanup candidates in %s:\n\n", packageName)
+	}
+
+	// Find column widths
+	digestWidth := len("DIGEST")
+	idsWidth := len("VERSION IDS")
+	tagsWidth := len("TAGS")
+	rows := make([][3]string, 0, len(groups))
+	for _, group := range groups {
+		ids := make([]string, 0, len(group.VersionIDs))
+		for _, id := range group.VersionIDs {
+			ids = append(ids, fmt.Sprintf("%d", id))
+		}
+		row := [3]string{display.ShortDigest(group.Digest), strings.Join(ids, ", "), fmt.Sprintf("%d", len(group.Tags))}
+		digestWidth = max(digestWidth, len(row[0]))
+		idsWidth = max(idsWidth, len(row[1]))
+		tagsWidth = max(tagsWidth, len(row[2]))
+		rows = append(rows, row)
+	}
+
+	fmt.Fprintf(w, "  %s  %s  %s  %s\n",
+		display.ColorHeader(fmt.Sprintf("%-*s", digestWidth, "DIGEST")),
+		display.ColorHeader(fmt.Sprintf("%-*s", idsWidth, "VERSION IDS")),
+		display.ColorHeader(fmt.Sprintf("%-*s", tagsWidth, "TAGS")),
+		display.ColorHeader("ISSUE"))
+	fmt.Fprintf(w, "  %s  %s  %s  %s\n",
+		display.ColorSeparator(strings.Repeat("-", digestWidth)),
+		display.ColorSeparator(strings.Repeat("-", idsWidth)),
+		display.ColorSeparator(strings.Repeat("-", tagsWidth)),
+		display.ColorSeparator(strings.Repeat("-", len("ISSUE"))))
+
+	duplicateCount, sprawlCount := 0, 0
+	for i, group := range groups {
+		var issues []string
+		for _, reason := range group.Reasons {
+			switch reason {
+			case duplicateReasonVersions:
+				issues = append(issues, fmt.Sprintf("%d version entries", len(group.VersionIDs)))
+				duplicateCount++
+			case duplicateReasonTags:
+				issues = append(issues, fmt.Sprintf("%d tags", len(group.Tags)))
+				sprawlCount++
+			}
+		}
+		fmt.Fprintf(w, "  %s  %-*s  %-*s  %s\n",
+			display.ColorDigest(fmt.Sprintf("%-*s", digestWidth, rows[i][0])),
+			idsWidth, rows[i][1],
+			tagsWidth, rows[i][2],
+			display.ColorWarning(strings.Join(issues, ", ")))
+	}
+
+	if !quiet {
+		fmt.Fprintf(w, "\nTotal: %s digest(s) with duplicate version entries, %s digest(s) with more than %d tags.\n",
+			display.ColorCount(duplicateCount), display.ColorCount(sprawlCount), maxTags)
+	}
+	return nil
+}
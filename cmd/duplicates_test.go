@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/mkoepf/ghcrctl/internal/gh"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// duplicateTestVersions has a digest pushed three times and a digest carrying five tags
+func duplicateTestVersions() []gh.PackageVersionInfo {
+	return []gh.PackageVersionInfo{
+		{ID: 7, Digest: "sha256:aaa111222333444555", Tags: []string{"latest"}},
+		{ID: 6, Digest: "sha256:bbb111222333444555", Tags: []string{"v2.0.0", "v2.0", "v2", "stable", "prod"}},
+		{ID: 5, Digest: "sha256:aaa111222333444555"},
+		{ID: 4, Digest: "sha256:ccc111222333444555", Tags: []string{"v1.0.0", "v1.0", "v1"}},
+		{ID: 3, Digest: "sha256:aaa111222333444555", Tags: []string{"nightly", "latest"}},
+		{ID: 2, Digest: "sha256:ddd111222333444555"},
+	}
+}
+
+func TestFindDuplicates(t *testing.T) {
+	t.Parallel()
+
+	groups := findDuplicates(duplicateTestVersions(), defaultMaxTags)
+	require.Len(t, groups, 2)
+
+	assert.Equal(t, duplicateGroup{
+		Digest:     "sha256:aaa111222333444555",
+		VersionIDs: []int64{7, 5, 3},
+		Tags:       []string{"latest", "nightly"},
+		Reasons:    []string{duplicateReasonVersions},
+	}, groups[0])
+
+	assert.Equal(t, duplicateGroup{
+		Digest:     "sha256:bbb111222333444555",
+		VersionIDs: []int64{6},
+		Tags:       []string{"v2.0.0", "v2.0", "v2", "stable", "prod"},
+		Reasons:    []string{duplicateReasonTags},
+	}, groups[1])
+}
+
+func TestFindDuplicates_MaxTags(t *testing.T) {
+	t.Parallel()
+
+	// Raising the threshold removes the tag sprawl finding
+	groups := findDuplicates(duplicateTestVersions(), 5)
+	require.Len(t, groups, 1)
+	assert.Equal(t, "sha256:aaa111222333444555", groups[0].Digest)
+
+	// Lowering it reports both reasons for the duplicated digest
+	groups = findDuplicates(duplicateTestVersions(), 1)
+	require.Len(t, groups, 3)
+	assert.Equal(t, []string{duplicateReasonVersions, duplicateReasonTags}, groups[0].Reasons)
+	assert.Equal(t, "sha256:ccc111222333444555", groups[2].Digest)
+}
+
+func TestFindDuplicates_None(t *testing.T) {
+	t.Parallel()
+	versions := []gh.PackageVersionInfo{
+		{ID: 2, Digest: "sha256:one", Tags: []string{"latest"}},
+		{ID: 1, Digest: "sha256:two"},
+	}
+	assert.Empty(t, findDuplicates(versions, defaultMaxTags))
+}
+
+func TestOutputDuplicatesTable(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	err := outputDuplicatesTable(&buf, findDuplicates(duplicateTestVersions(), defaultMaxTags), "myimage", defaultMaxTags, false)
+	require.NoError(t, err)
+
+	output := buf.String()
+	assert.Contains(t, output, "Cleanup candidates in myimage:")
+	assert.Contains(t, output, "VERSION IDS")
+	assert.Contains(t, output, "7, 5, 3")
+	assert.Contains(t, output, "3 version entries")
+	assert.Contains(t, output, "5 tags")
+	assert.NotContains(t, output, "ccc111222333")
+	assert.Contains(t, output, "Total: 1 digest(s) with duplicate version entries, 1 digest(s) with more than 4 tags.")
+}
+
+func TestOutputDuplicatesTable_Empty(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	require.NoError(t, outputDuplicatesTable(&buf, nil, "myimage", 4, false))
+	assert.Equal(t, "No duplicate digests or digests with more than 4 tags found in myimage\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, outputDuplicatesTable(&buf, nil, "myimage", 4, true))
+	assert.Empty(t, buf.String())
+}
+
+func TestDuplicateGroup_JSON(t *testing.T) {
+	t.Parallel()
+
+	data, err := json.Marshal(findDuplicates(duplicateTestVersions(), defaultMaxTags))
+	require.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"digest":"sha256:aaa111222333444555","version_ids":[7,5,3],"tags":["latest","nightly"],"reasons":["duplicate-versions"]},
+		{"digest":"sha256:bbb111222333444555","version_ids":[6],"tags":["v2.0.0","v2.0","v2","stable","prod"],"reasons":["tag-sprawl"]}
+	]`, string(data))
+}
+
+func TestListVersionsCmd_DuplicatesFlags(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{
+			name:    "max-tags requires duplicates",
+			args:    []string{"list", "versions", "owner/pkg", "--max-tags", "10"},
+			wantErr: "--max-tags requires --duplicates",
+		},
+		{
+			name:    "negative max-tags",
+			args:    []string{"list", "versions", "owner/pkg", "--duplicates", "--max-tags", "-1"},
+			wantErr: "--max-tags must not be negative",
+		},
+		{
+			name:    "exclusive with watch",
+			args:    []string{"list", "versions", "owner/pkg", "--duplicates", "--watch"},
+			wantErr: "none of the others can be",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cmd := NewRootCmd()
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(new(bytes.Buffer))
+			cmd.SetErr(new(bytes.Buffer))
+
+			err := cmd.Execute()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"io"
 	"strings"
 	"testing"
 
@@ -96,6 +97,55 @@ func TestExampleFormat(t *testing.T) {
 	}
 }
 
+// TestExamplesParse ensures every example command line in the help texts
+// names an existing command and uses only flags that command accepts
+func TestExamplesParse(t *testing.T) {
+	var commands []*cobra.Command
+	var walk func(cmd *cobra.Command)
+	walk = func(cmd *cobra.Command) {
+		commands = append(commands, cmd)
+		for _, sub := range cmd.Commands() {
+			walk(sub)
+		}
+	}
+	walk(NewRootCmd())
+
+	for _, cmd := range commands {
+		_, examples, found := strings.Cut(cmd.Long, "Examples:")
+		if !found {
+			continue
+		}
+		for _, line := range strings.Split(examples, "\n") {
+			line = strings.TrimSpace(line)
+			if !strings.HasPrefix(line, "ghcrctl ") {
+				continue
+			}
+			t.Run(line, func(t *testing.T) {
+				root := NewRootCmd()
+				args := exampleArgs(line)
+				root.SetOut(io.Discard)
+				root.SetErr(io.Discard)
+				target, rest, err := root.Find(args)
+				require.NoError(t, err)
+				assert.NoError(t, target.ParseFlags(rest))
+			})
+		}
+	}
+}
+
+// exampleArgs splits an example command line into arguments, without the
+// program name, quotes, and anything after a pipe or redirection
+func exampleArgs(line string) []string {
+	var args []string
+	for _, field := range strings.Fields(line)[1:] {
+		if field == "|" || field == ">" || field == "<" || field == "&&" {
+			break
+		}
+		args = append(args, strings.Trim(field, `'"`))
+	}
+	return args
+}
+
 // findCommand searches for a command by name (supports subcommands like "delete version")
 func findCommand(root *cobra.Command, name string) *cobra.Command {
 	parts := strings.Split(name, " ")
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"sort"
+	"strings"
 
 	"github.com/mkoepf/ghcrctl/internal/discover"
 	"github.com/mkoepf/ghcrctl/internal/display"
@@ -17,20 +18,26 @@ import (
 func newGetCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "get",
-		Short: "Get attributes of a package version (labels, sbom, provenance)",
+		Short: "Get attributes of a package version (labels, manifest, sbom, provenance, vex, vuln-scan)",
 		Long: `Get attributes of a specific package version from GitHub Container Registry.
 
 Requires a selector flag to identify the version: --tag, --digest, or --version.
 
 Available subcommands:
   labels       Get OCI labels from a container image
+  manifest     Get the raw manifest or index JSON
   sbom         Get SBOM (Software Bill of Materials) attestation
-  provenance   Get provenance attestation`,
+  provenance   Get provenance attestation
+  vex          Get VEX (Vulnerability Exploitability eXchange) attestation
+  vuln-scan    Get vulnerability scan attestation`,
 	}
 
 	cmd.AddCommand(newGetLabelsCmd())
+	cmd.AddCommand(newGetManifestCmd())
 	cmd.AddCommand(newGetSBOMCmd())
 	cmd.AddCommand(newGetProvenanceCmd())
+	cmd.AddCommand(newGetVEXCmd())
+	cmd.AddCommand(newGetVulnScanCmd())
 
 	return cmd
 }
@@ -42,7 +49,10 @@ func newGetLabelsCmd() *cobra.Command {
 		digest       string
 		versionID    int64
 		key          string
+		keyPrefix    string
+		platform     string
 		jsonOutput   bool
+		envOutput    bool
 		outputFormat string
 	)
 
@@ -60,6 +70,23 @@ Docker LABEL instructions or equivalent mechanisms. Common labels include:
 
 Requires a selector: --tag, --digest, or --version.
 
+--key shows one label, --key-prefix all labels whose key starts with the
+prefix, e.g. org.opencontainers.image. If both are given, --key takes
+precedence.
+
+For a multi-arch image, the labels of the first platform are shown. Use
+--platform to pick a platform, or --platform all to show the labels of every
+platform grouped by platform, which helps to spot platforms that were built
+with different metadata.
+
+--env prints the labels as shell variable assignments for eval. Keys are
+uppercased, and characters that are not allowed in variable names, such as
+dots and dashes, become underscores. Values are single-quoted, so they are
+never expanded by the shell.
+
+Pass - instead of a package to read owner/package references from stdin.
+The selector is applied to every package.
+
 Examples:
   # Get all labels from a tagged version
   ghcrctl get labels mkoepf/myimage --tag v1.0.0
@@ -73,12 +100,38 @@ Examples:
   # Get a specific label key
   ghcrctl get labels mkoepf/myimage --tag v1.0.0 --key org.opencontainers.image.source
 
+  # Get all OCI annotation labels
+  ghcrctl get labels mkoepf/myimage --tag v1.0.0 --key-prefix org.opencontainers.image.
+
   # JSON output
-  ghcrctl get labels mkoepf/myimage --tag latest --json`,
+  ghcrctl get labels mkoepf/myimage --tag latest --json
+
+  # Get the labels of one platform of a multi-arch image
+  ghcrctl get labels mkoepf/myimage --tag v1.0.0 --platform linux/arm64
+
+  # Compare the labels of all platforms
+  ghcrctl get labels mkoepf/myimage --tag v1.0.0 --platform all
+
+  # Set ORG_OPENCONTAINERS_IMAGE_VERSION and friends in the current shell
+  eval "$(ghcrctl get labels mkoepf/myimage --tag v1.0.0 --key-prefix org.opencontainers.image. --env)"
+
+  # Get the labels of the latest tag of several packages
+  cat packages.txt | ghcrctl get labels - --tag latest`,
 		Args: cobra.ExactArgs(1),
-		RunE: func(cmd *cobra.Command, args []string) error {
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if envOutput && args[0] == stdinRefArg {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("--env cannot be used with package references from stdin")
+			}
+			if envOutput && platform == allPlatforms {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("--env cannot be used with --platform all; select one platform")
+			}
+			return nil
+		},
+		RunE: withStdinRefs(func() bool { return jsonOutput || outputFormat == "json" }, func(cmd *cobra.Command, args []string) error {
 			// Parse owner/package reference (reject inline tags)
-			owner, packageName, err := parsePackageRef(args[0])
+			owner, packageName, err := parsePackageRef(args[0], defaultOwner(cmd))
 			if err != nil {
 				cmd.SilenceUsage = true
 				return err
@@ -91,16 +144,16 @@ Examples:
 			}
 
 			// Handle output format flag (-o)
-			if outputFormat != "" {
-				switch outputFormat {
-				case "json":
-					jsonOutput = true
-				case "table":
-					jsonOutput = false
-				default:
-					cmd.SilenceUsage = true
-					return fmt.Errorf("invalid output format %q. Supported formats: json, table", outputFormat)
-				}
+			mode, err := display.ParseOutputMode(outputFormat, display.OutputModeJSON, display.OutputModeTable)
+			if err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
+			switch mode {
+			case display.OutputModeJSON:
+				jsonOutput = true
+			case display.OutputModeTable:
+				jsonOutput = false
 			}
 
 			// Construct full image reference
@@ -149,6 +202,7 @@ Examples:
 					cmd.SilenceUsage = true
 					return fmt.Errorf("failed to discover package: %w", err)
 				}
+				printDiscoveryWarnings(ctx, cmd.ErrOrStderr(), versions)
 
 				versionMap := discover.ToMap(versions)
 
@@ -167,44 +221,88 @@ Examples:
 				}
 			}
 
-			// Get labels from image
-			labels, err := getImageLabelsFromDigest(ctx, fullImage, targetDigest)
+			keyFilter := labelKeyFilter{Key: key, Prefix: keyPrefix}
+
+			if platform == allPlatforms {
+				platformLabels, err := getPlatformLabels(ctx, discover.FetchManifest, getImageLabelsFromDigest, fullImage, targetDigest)
+				if err != nil {
+					cmd.SilenceUsage = true
+					return fmt.Errorf("failed to get labels: %w", err)
+				}
+				if keyFilter.active() {
+					if platformLabels, err = filterPlatformLabels(platformLabels, keyFilter); err != nil {
+						cmd.SilenceUsage = true
+						return err
+					}
+				}
+				if jsonOutput {
+					return display.OutputJSON(ctx, cmd.OutOrStdout(), platformLabels)
+				}
+				return outputPlatformLabelsTable(cmd.OutOrStdout(), platformLabels, packageName, tag, targetDigest)
+			}
+
+			// Get labels from image, or from one platform of an index
+			labelsDigest := targetDigest
+			if platform != "" {
+				labelsDigest, err = selectPlatformDigest(ctx, discover.FetchManifest, fullImage, targetDigest, platform)
+				if err != nil {
+					cmd.SilenceUsage = true
+					return err
+				}
+			}
+			labels, err := getImageLabelsFromDigest(ctx, fullImage, labelsDigest)
 			if err != nil {
 				cmd.SilenceUsage = true
 				return fmt.Errorf("failed to get labels: %w", err)
 			}
 
-			// Filter by key if specified
-			if key != "" {
-				if value, ok := labels[key]; ok {
-					labels = map[string]string{key: value}
-				} else {
+			// Filter by key or key prefix if specified
+			if keyFilter.active() {
+				if labels, err = filterLabels(labels, keyFilter); err != nil {
 					cmd.SilenceUsage = true
-					return fmt.Errorf("label key %q not found", key)
+					return err
 				}
 			}
 
 			// Output results
+			if envOutput {
+				if err := outputLabelsEnv(cmd.OutOrStdout(), labels); err != nil {
+					cmd.SilenceUsage = true
+					return err
+				}
+				return nil
+			}
 			if jsonOutput {
-				return display.OutputJSON(cmd.OutOrStdout(), labels)
+				return display.OutputJSON(ctx, cmd.OutOrStdout(), labels)
 			}
 			return outputGetLabelsTable(cmd.OutOrStdout(), labels, packageName, tag, targetDigest)
-		},
+		}),
 	}
 
 	cmd.Flags().StringVar(&tag, "tag", "", "Select version by tag")
 	cmd.Flags().StringVar(&digest, "digest", "", "Select version by digest (supports short form)")
 	cmd.Flags().Int64Var(&versionID, "version", 0, "Select version by ID")
 	cmd.Flags().StringVar(&key, "key", "", "Show only specific label key")
+	cmd.Flags().StringVar(&keyPrefix, "key-prefix", "", "Show only labels whose key starts with this prefix")
+	cmd.Flags().StringVar(&platform, "platform", "", "Show the labels of this platform of an index (e.g., linux/arm64), or of every platform with 'all'")
 	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output in JSON format")
-	cmd.Flags().StringVarP(&outputFormat, "output", "o", "", "Output format (json, table)")
+	cmd.Flags().BoolVar(&envOutput, "env", false, "Output as KEY='value' lines for shell eval")
+	addOutputFlag(cmd, &outputFormat, display.OutputModeJSON, display.OutputModeTable)
 	cmd.MarkFlagsMutuallyExclusive("tag", "digest", "version")
+	cmd.MarkFlagsMutuallyExclusive("env", "json", "output")
 
 	cmd.ValidArgsFunction = imageRefValidArgsFunc
 
 	return cmd
 }
 
+// allPlatforms is the --platform value that selects every platform of an index
+const allPlatforms = "all"
+
+// labelsFetchFunc fetches the config labels of an image manifest.
+// getImageLabelsFromDigest satisfies this signature.
+type labelsFetchFunc func(ctx context.Context, image, digest string) (map[string]string, error)
+
 func getImageLabelsFromDigest(ctx context.Context, image, digest string) (map[string]string, error) {
 	// Fetch image config to get labels
 	config, err := discover.GetImageConfig(ctx, image, digest)
@@ -215,6 +313,190 @@ func getImageLabelsFromDigest(ctx context.Context, image, digest string) (map[st
 	return config.Config.Labels, nil
 }
 
+// selectPlatformDigest returns the digest of the manifest for platform in the index at digest
+func selectPlatformDigest(ctx context.Context, fetch manifestFetchFunc, image, digest, platform string) (string, error) {
+	data, mediaType, err := fetch(ctx, image, digest)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch manifest: %w", err)
+	}
+	if !discover.IsIndexMediaType(mediaType) {
+		return "", fmt.Errorf("--platform requires an image index, but %s is %s", digest, mediaType)
+	}
+	return discover.SelectPlatformManifest(data, platform)
+}
+
+// getPlatformLabels returns the labels of every platform manifest in the index
+// at digest, keyed by platform (e.g. linux/amd64).
+func getPlatformLabels(ctx context.Context, fetchManifest manifestFetchFunc, fetchLabels labelsFetchFunc, image, digest string) (map[string]map[string]string, error) {
+	data, mediaType, err := fetchManifest(ctx, image, digest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch manifest: %w", err)
+	}
+	if !discover.IsIndexMediaType(mediaType) {
+		return nil, fmt.Errorf("--platform requires an image index, but %s is %s", digest, mediaType)
+	}
+	manifests, err := discover.ListPlatformManifests(data)
+	if err != nil {
+		return nil, err
+	}
+
+	platformLabels := make(map[string]map[string]string, len(manifests))
+	for _, m := range manifests {
+		labels, err := fetchLabels(ctx, image, m.Digest)
+		if err != nil {
+			return nil, fmt.Errorf("platform %s: %w", m.Platform, err)
+		}
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		platformLabels[m.Platform] = labels
+	}
+	return platformLabels, nil
+}
+
+// labelKeyFilter selects labels by an exact key (--key) or a key prefix
+// (--key-prefix). The exact key takes precedence over the prefix.
+type labelKeyFilter struct {
+	Key    string
+	Prefix string
+}
+
+// active reports whether the filter selects anything
+func (f labelKeyFilter) active() bool {
+	return f.Key != "" || f.Prefix != ""
+}
+
+// matches reports whether the label key k is selected
+func (f labelKeyFilter) matches(k string) bool {
+	if f.Key != "" {
+		return k == f.Key
+	}
+	return strings.HasPrefix(k, f.Prefix)
+}
+
+// describe names what the filter selects, for error messages
+func (f labelKeyFilter) describe() string {
+	if f.Key != "" {
+		return fmt.Sprintf("label key %q", f.Key)
+	}
+	return fmt.Sprintf("label keys starting with %q", f.Prefix)
+}
+
+// filterLabels keeps the labels selected by f. It fails if none match.
+func filterLabels(labels map[string]string, f labelKeyFilter) (map[string]string, error) {
+	filtered := make(map[string]string)
+	for k, v := range labels {
+		if f.matches(k) {
+			filtered[k] = v
+		}
+	}
+	if len(filtered) == 0 {
+		return nil, fmt.Errorf("%s not found", f.describe())
+	}
+	return filtered, nil
+}
+
+// filterPlatformLabels keeps the labels selected by f on each platform.
+// Platforms without a match keep an empty set. It fails if no platform has a
+// match.
+func filterPlatformLabels(platformLabels map[string]map[string]string, f labelKeyFilter) (map[string]map[string]string, error) {
+	filtered := make(map[string]map[string]string, len(platformLabels))
+	found := false
+	for platform, labels := range platformLabels {
+		filtered[platform] = map[string]string{}
+		for k, v := range labels {
+			if f.matches(k) {
+				filtered[platform][k] = v
+				found = true
+			}
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("%s not found on any platform", f.describe())
+	}
+	return filtered, nil
+}
+
+// divergentLabelKeys returns the sorted label keys whose value differs between
+// platforms, including keys that are missing on some platforms.
+func divergentLabelKeys(platformLabels map[string]map[string]string) []string {
+	keys := make(map[string]bool)
+	for _, labels := range platformLabels {
+		for k := range labels {
+			keys[k] = true
+		}
+	}
+
+	var divergent []string
+	for k := range keys {
+		first := true
+		var value string
+		var present bool
+		for _, labels := range platformLabels {
+			v, ok := labels[k]
+			if first {
+				value, present, first = v, ok, false
+				continue
+			}
+			if v != value || ok != present {
+				divergent = append(divergent, k)
+				break
+			}
+		}
+	}
+	sort.Strings(divergent)
+	return divergent
+}
+
+// outputPlatformLabelsTable prints the labels of each platform, followed by the
+// keys that differ between platforms
+func outputPlatformLabelsTable(w io.Writer, platformLabels map[string]map[string]string, packageName, tag, digest string) error {
+	selector := display.ShortDigest(digest)
+	if tag != "" {
+		selector = tag
+	}
+
+	if len(platformLabels) == 0 {
+		fmt.Fprintf(w, "No platforms found for %s (%s)\n", packageName, selector)
+		return nil
+	}
+
+	fmt.Fprintf(w, "Labels for %s (%s) by platform:\n", packageName, selector)
+
+	platforms := make([]string, 0, len(platformLabels))
+	for p := range platformLabels {
+		platforms = append(platforms, p)
+	}
+	sort.Strings(platforms)
+
+	for _, p := range platforms {
+		labels := platformLabels[p]
+		fmt.Fprintf(w, "\n%s:\n", p)
+		if len(labels) == 0 {
+			fmt.Fprintf(w, "  (no labels)\n")
+			continue
+		}
+
+		keys := make([]string, 0, len(labels))
+		maxKeyLen := 0
+		for k := range labels {
+			keys = append(keys, k)
+			maxKeyLen = max(maxKeyLen, len(k))
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Fprintf(w, "  %-*s  %s\n", maxKeyLen, k, labels[k])
+		}
+	}
+
+	if divergent := divergentLabelKeys(platformLabels); len(divergent) > 0 {
+		fmt.Fprintf(w, "\n%s %s\n", display.ColorWarning("Labels differ between platforms:"), strings.Join(divergent, ", "))
+	} else {
+		fmt.Fprintf(w, "\nAll %d platform(s) have the same labels\n", len(platforms))
+	}
+	return nil
+}
+
 func outputGetLabelsTable(w io.Writer, labels map[string]string, packageName, tag, digest string) error {
 	// Build display string for selector
 	selector := display.ShortDigest(digest)
@@ -253,6 +535,61 @@ func outputGetLabelsTable(w io.Writer, labels map[string]string, packageName, ta
 	return nil
 }
 
+// outputLabelsEnv prints labels as shell variable assignments, sorted by
+// variable name. It fails if two keys map to the same variable, so that eval
+// never silently drops a label.
+func outputLabelsEnv(w io.Writer, labels map[string]string) error {
+	keys := make(map[string]string, len(labels))
+	for k := range labels {
+		name := envVarName(k)
+		if other, ok := keys[name]; ok {
+			pair := []string{k, other}
+			sort.Strings(pair)
+			return fmt.Errorf("labels %s and %s both map to the variable %s; select one with --key", pair[0], pair[1], name)
+		}
+		keys[name] = k
+	}
+
+	names := make([]string, 0, len(keys))
+	for name := range keys {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(w, "%s=%s\n", name, shellQuote(labels[keys[name]]))
+	}
+	return nil
+}
+
+// envVarName turns a label key into a valid shell variable name: uppercased,
+// with every character other than letters, digits and underscores replaced by
+// an underscore, and an underscore prepended if it would start with a digit
+func envVarName(key string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(key) {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte('_')
+		}
+	}
+	name := b.String()
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		name = "_" + name
+	}
+	return name
+}
+
+// shellQuote single-quotes s for POSIX shells. Nothing is expanded inside
+// single quotes, so a single quote itself closes the quotes, is escaped and
+// opens them again:
+//
+//	it's -> 'it'\''s'
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
 // newGetSBOMCmd creates the get sbom subcommand.
 func newGetSBOMCmd() *cobra.Command {
 	return newGetArtifactCmd(getArtifactParams{
@@ -266,6 +603,9 @@ If --digest or --version points directly to an SBOM, it is displayed.
 Otherwise, the command finds SBOMs in the image containing that version.
 If multiple SBOMs exist, use --all to show all or select a specific one by its digest.
 
+SPDX and CycloneDX SBOMs are shown as a table of components with their version
+and license. Use --json for the raw document.
+
 Requires a selector: --tag, --digest, or --version.
 
 Examples:
@@ -281,7 +621,13 @@ Examples:
   # Get all SBOMs for an image
   ghcrctl get sbom mkoepf/myimage --tag v1.0.0 --all
 
-  # Output in JSON format
+  # Save the SBOM to a file (writes sbom.spdx.json or sbom.cdx.json)
+  ghcrctl get sbom mkoepf/myimage --tag v1.0.0 --output-file sbom
+
+  # Decode a DSSE-wrapped SBOM (e.g. a cosign attestation) into its in-toto statement
+  ghcrctl get sbom mkoepf/myimage --tag v1.0.0 --decode
+
+  # Output the raw SBOM document in JSON format
   ghcrctl get sbom mkoepf/myimage --tag v1.0.0 --json`,
 	})
 }
@@ -289,16 +635,27 @@ Examples:
 // newGetProvenanceCmd creates the get provenance subcommand.
 func newGetProvenanceCmd() *cobra.Command {
 	return newGetArtifactCmd(getArtifactParams{
-		Name:       "provenance",
-		Short:      "Get provenance attestation",
-		NoFoundMsg: "no provenance found",
-		Role:       "provenance",
+		Name:         "provenance",
+		Short:        "Get provenance attestation",
+		NoFoundMsg:   "no provenance found",
+		Role:         "provenance",
+		BuilderCheck: true,
+		Materials:    true,
 		Long: `Get the provenance attestation for a container image or version.
 
 If --digest or --version points directly to a provenance attestation, it is displayed.
 Otherwise, the command finds provenance in the image containing that version.
 If multiple provenance documents exist, use --all to show all or select a specific one by its digest.
 
+With --expect-builder, the SLSA builder ID of every provenance document in the
+image is compared with the given value instead of displaying the documents.
+The command exits with an error and prints the actual builder on a mismatch.
+
+With --materials, only the build inputs of every provenance document in the
+image are listed: the source repository and dependencies such as base images,
+each with the digest it was resolved to. They are read from materials in SLSA
+v0.2 and from buildDefinition.resolvedDependencies in SLSA v1.
+
 Requires a selector: --tag, --digest, or --version.
 
 Examples:
@@ -314,29 +671,110 @@ Examples:
   # Get all provenance documents for an image
   ghcrctl get provenance mkoepf/myimage --tag v1.0.0 --all
 
+  # Decode a DSSE-wrapped provenance into its in-toto statement
+  ghcrctl get provenance mkoepf/myimage --tag v1.0.0 --decode
+
+  # List the source and dependencies the image was built from
+  ghcrctl get provenance mkoepf/myimage --tag v1.0.0 --materials
+
+  # Fail unless the image was built by the expected builder
+  ghcrctl get provenance mkoepf/myimage --tag v1.0.0 --expect-builder https://github.com/actions/runner
+
   # Output in JSON format
   ghcrctl get provenance mkoepf/myimage --tag v1.0.0 --json`,
 	})
 }
 
+// newGetVEXCmd creates the get vex subcommand.
+func newGetVEXCmd() *cobra.Command {
+	return newGetArtifactCmd(getArtifactParams{
+		Name:       "vex",
+		Short:      "Get VEX (Vulnerability Exploitability eXchange) attestation",
+		NoFoundMsg: "no VEX document found",
+		Role:       "vex",
+		Long: `Get the VEX attestation for a container image or version.
+
+VEX documents (e.g. OpenVEX) state whether known vulnerabilities actually
+affect the image. If --digest or --version points directly to a VEX
+attestation, it is displayed. Otherwise, the command finds VEX documents in
+the image containing that version. If multiple exist, use --all to show all
+or select a specific one by its digest.
+
+Requires a selector: --tag, --digest, or --version.
+
+Examples:
+  # Get VEX for a tagged image
+  ghcrctl get vex mkoepf/myimage --tag v1.0.0
+
+  # Get VEX by version ID (from 'list versions' output)
+  ghcrctl get vex mkoepf/myimage --version 12345678
+
+  # Get all VEX documents for an image
+  ghcrctl get vex mkoepf/myimage --tag v1.0.0 --all
+
+  # Output in JSON format
+  ghcrctl get vex mkoepf/myimage --tag v1.0.0 --json`,
+	})
+}
+
+// newGetVulnScanCmd creates the get vuln-scan subcommand.
+func newGetVulnScanCmd() *cobra.Command {
+	return newGetArtifactCmd(getArtifactParams{
+		Name:       "vuln-scan",
+		Short:      "Get vulnerability scan attestation",
+		NoFoundMsg: "no vulnerability scan found",
+		Role:       "vuln-scan",
+		Long: `Get the vulnerability scan attestation for a container image or version.
+
+If --digest or --version points directly to a vulnerability scan, it is displayed.
+Otherwise, the command finds scan results in the image containing that version.
+If multiple scans exist, use --all to show all or select a specific one by its digest.
+
+Requires a selector: --tag, --digest, or --version.
+
+Examples:
+  # Get vulnerability scan for a tagged image
+  ghcrctl get vuln-scan mkoepf/myimage --tag v1.0.0
+
+  # Get vulnerability scan by version ID (from 'list versions' output)
+  ghcrctl get vuln-scan mkoepf/myimage --version 12345678
+
+  # Get all vulnerability scans for an image
+  ghcrctl get vuln-scan mkoepf/myimage --tag v1.0.0 --all
+
+  # Output in JSON format
+  ghcrctl get vuln-scan mkoepf/myimage --tag v1.0.0 --json`,
+	})
+}
+
 // getArtifactParams defines the configuration for a specific artifact type command.
 type getArtifactParams struct {
-	Name       string // "sbom" or "provenance"
+	Name       string // "sbom", "provenance", "vex", or "vuln-scan"
 	Short      string // Short description
 	Long       string // Long description with examples
 	NoFoundMsg string // Message when no artifacts found
 	Role       string // OCI artifact role to filter for
+
+	// BuilderCheck adds --expect-builder to verify the SLSA builder ID (provenance only)
+	BuilderCheck bool
+
+	// Materials adds --materials to list the SLSA materials (provenance only)
+	Materials bool
 }
 
 // newGetArtifactCmd creates a command for getting OCI artifacts of a specific type.
 func newGetArtifactCmd(cfg getArtifactParams) *cobra.Command {
 	var (
-		tag          string
-		digest       string
-		versionID    int64
-		all          bool
-		jsonOutput   bool
-		outputFormat string
+		tag           string
+		digest        string
+		versionID     int64
+		all           bool
+		jsonOutput    bool
+		outputFormat  string
+		outputFile    string
+		decode        bool
+		expectBuilder string
+		materials     bool
 	)
 
 	cmd := &cobra.Command{
@@ -346,7 +784,7 @@ func newGetArtifactCmd(cfg getArtifactParams) *cobra.Command {
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// Parse owner/package reference (reject inline tags)
-			owner, packageName, err := parsePackageRef(args[0])
+			owner, packageName, err := parsePackageRef(args[0], defaultOwner(cmd))
 			if err != nil {
 				cmd.SilenceUsage = true
 				return err
@@ -359,16 +797,16 @@ func newGetArtifactCmd(cfg getArtifactParams) *cobra.Command {
 			}
 
 			// Handle output format flag (-o)
-			if outputFormat != "" {
-				switch outputFormat {
-				case "json":
-					jsonOutput = true
-				case "table":
-					jsonOutput = false
-				default:
-					cmd.SilenceUsage = true
-					return fmt.Errorf("invalid output format %q. Supported formats: json, table", outputFormat)
-				}
+			mode, err := display.ParseOutputMode(outputFormat, display.OutputModeJSON, display.OutputModeTable)
+			if err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
+			switch mode {
+			case display.OutputModeJSON:
+				jsonOutput = true
+			case display.OutputModeTable:
+				jsonOutput = false
 			}
 
 			// Verify GitHub token is available
@@ -410,6 +848,7 @@ func newGetArtifactCmd(cfg getArtifactParams) *cobra.Command {
 				cmd.SilenceUsage = true
 				return fmt.Errorf("failed to discover package: %w", err)
 			}
+			printDiscoveryWarnings(ctx, cmd.ErrOrStderr(), versions)
 
 			versionMap := discover.ToMap(versions)
 
@@ -445,80 +884,163 @@ func newGetArtifactCmd(cfg getArtifactParams) *cobra.Command {
 				selectorValue = display.ShortDigest(resolvedDigest)
 			}
 
-			// Check if the selected version is itself an artifact of the requested type
-			selectedVersion, exists := versionMap[resolvedDigest]
-			if exists {
-				for _, t := range selectedVersion.Types {
-					if t == cfg.Role {
-						// The selected version IS the artifact - display it directly
-						return fetchAndDisplayArtifact(cmd.OutOrStdout(), ctx, fullImage, resolvedDigest, jsonOutput, cfg.Name)
-					}
-				}
-			}
+			cmd.SilenceUsage = true
+			return showArtifacts(cmd.OutOrStdout(), ctx, discover.GetArtifactContent, cfg, artifactOptions{
+				All:           all,
+				JSON:          jsonOutput,
+				OutputFile:    outputFile,
+				Decode:        decode,
+				ExpectBuilder: expectBuilder,
+				Materials:     materials,
+			}, versionMap, artifactSelection{
+				Image:         fullImage,
+				PackageName:   packageName,
+				Digest:        resolvedDigest,
+				SelectorType:  selectorType,
+				SelectorValue: selectorValue,
+			})
+		},
+	}
 
-			// The selected version is not an artifact of this type
-			// Print informational message about searching in the containing graph
-			if !quiet.IsQuiet(ctx) && !jsonOutput {
-				fmt.Fprintf(cmd.OutOrStdout(), "Version %s is not a %s. Searching in containing graph...\n\n", selectorValue, cfg.Name)
-			}
+	cmd.Flags().StringVar(&tag, "tag", "", "Select version by tag")
+	cmd.Flags().StringVar(&digest, "digest", "", "Select version by digest (supports short form)")
+	cmd.Flags().Int64Var(&versionID, "version", 0, "Select version by ID")
+	cmd.Flags().BoolVar(&all, "all", false, fmt.Sprintf("Show all %s documents", cfg.Name))
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output in JSON format")
+	addOutputFlag(cmd, &outputFormat, display.OutputModeJSON, display.OutputModeTable)
+	cmd.Flags().StringVar(&outputFile, "output-file", "", "Write the document to a file (extension is chosen from the content type if omitted; numbered with --all)")
+	cmd.Flags().BoolVar(&decode, "decode", false, "Decode DSSE envelopes and output the in-toto statement they carry")
+	cmd.MarkFlagsMutuallyExclusive("tag", "digest", "version")
 
-			// Find the graph it belongs to and look for artifacts there
-			graphVersions := discover.FindGraphsContainingVersion(versionMap, resolvedDigest)
-			if len(graphVersions) == 0 {
-				// Fall back to treating it as a root and looking for children
-				graphVersions = discover.FindGraphByDigest(versionMap, resolvedDigest)
-			}
+	if cfg.BuilderCheck {
+		cmd.Flags().StringVar(&expectBuilder, "expect-builder", "", "Fail unless the SLSA builder ID of the provenance matches this value")
+		cmd.MarkFlagsMutuallyExclusive("expect-builder", "output-file")
+		cmd.MarkFlagsMutuallyExclusive("expect-builder", "json")
+		cmd.MarkFlagsMutuallyExclusive("expect-builder", "output")
+	}
 
-			// Filter for artifacts of the specified role
-			var artifacts []discover.VersionInfo
-			for _, v := range graphVersions {
-				// Skip the selected version itself
-				if v.Digest == resolvedDigest {
-					continue
+	if cfg.Materials {
+		cmd.Flags().BoolVar(&materials, "materials", false, "List only the SLSA materials (source and dependencies with their digests)")
+		cmd.MarkFlagsMutuallyExclusive("materials", "expect-builder")
+		cmd.MarkFlagsMutuallyExclusive("materials", "output-file")
+		cmd.MarkFlagsMutuallyExclusive("materials", "decode")
+	}
+
+	cmd.ValidArgsFunction = imageRefValidArgsFunc
+
+	return cmd
+}
+
+// artifactOptions holds the output flags of a get artifact command
+type artifactOptions struct {
+	All           bool
+	JSON          bool
+	OutputFile    string
+	Decode        bool
+	ExpectBuilder string
+	Materials     bool
+}
+
+// artifactSelection identifies the version a get artifact command was run for
+type artifactSelection struct {
+	Image         string // Full image reference, e.g. ghcr.io/owner/package
+	PackageName   string
+	Digest        string // Resolved digest of the selected version
+	SelectorType  string // "tag", "digest", or "version"
+	SelectorValue string // The actual value used
+}
+
+// showArtifacts displays the artifacts with the role of cfg for the selected
+// version: the version itself if it has the role, otherwise those in the graph
+// containing it.
+func showArtifacts(w io.Writer, ctx context.Context, fetch artifactFetchFunc, cfg getArtifactParams, opts artifactOptions, versionMap map[string]discover.VersionInfo, sel artifactSelection) error {
+	// Check if the selected version is itself an artifact of the requested type
+	if selectedVersion, exists := versionMap[sel.Digest]; exists {
+		for _, t := range selectedVersion.Types {
+			if t == cfg.Role {
+				// The selected version IS the artifact - display it directly
+				if opts.ExpectBuilder != "" {
+					return verifyProvenanceBuilders(w, ctx, fetch, sel.Image, []discover.VersionInfo{selectedVersion}, opts.ExpectBuilder)
 				}
-				// Check if any of the types match the requested role
-				for _, t := range v.Types {
-					if t == cfg.Role {
-						artifacts = append(artifacts, v)
-						break
-					}
+				if opts.Materials {
+					return fetchAndDisplayMaterials(w, ctx, fetch, sel.Image, []discover.VersionInfo{selectedVersion}, opts.JSON)
 				}
+				if opts.OutputFile != "" {
+					return fetchAndSaveArtifact(w, ctx, fetch, sel.Image, sel.Digest, opts.OutputFile, opts.Decode, cfg.Name)
+				}
+				return fetchAndDisplayArtifact(w, ctx, fetch, sel.Image, sel.Digest, opts.JSON, opts.Decode, cfg.Name)
 			}
+		}
+	}
 
-			// Check if no artifacts found
-			if len(artifacts) == 0 {
-				cmd.SilenceUsage = true
-				return fmt.Errorf("%s for %s (%s)", cfg.NoFoundMsg, packageName, selectorValue)
-			}
+	// The selected version is not an artifact of this type
+	// Print informational message about searching in the containing graph
+	if !quiet.IsQuiet(ctx) && !opts.JSON && opts.OutputFile == "" && opts.ExpectBuilder == "" {
+		fmt.Fprintf(w, "Version %s is not a %s. Searching in containing graph...\n\n", sel.SelectorValue, cfg.Name)
+	}
 
-			// If --all flag, show all artifacts
-			if all {
-				return fetchAndDisplayAllArtifacts(cmd.OutOrStdout(), ctx, fullImage, artifacts, jsonOutput, cfg.Name)
-			}
+	// Find the graph it belongs to and look for artifacts there
+	artifacts := findArtifactsByRole(versionMap, sel.Digest, cfg.Role)
+	if len(artifacts) == 0 {
+		return fmt.Errorf("%s for %s (%s)", cfg.NoFoundMsg, sel.PackageName, sel.SelectorValue)
+	}
 
-			// Smart behavior: if only one artifact, show it; otherwise list them
-			if len(artifacts) == 1 {
-				return fetchAndDisplayArtifact(cmd.OutOrStdout(), ctx, fullImage, artifacts[0].Digest, jsonOutput, cfg.Name)
-			}
+	// Builder verification checks every provenance document in the image
+	if opts.ExpectBuilder != "" {
+		return verifyProvenanceBuilders(w, ctx, fetch, sel.Image, artifacts, opts.ExpectBuilder)
+	}
 
-			// Multiple artifacts: if JSON output requested, show all; otherwise list them
-			if jsonOutput {
-				return fetchAndDisplayAllArtifacts(cmd.OutOrStdout(), ctx, fullImage, artifacts, jsonOutput, cfg.Name)
-			}
+	// Materials are listed for every provenance document in the image
+	if opts.Materials {
+		return fetchAndDisplayMaterials(w, ctx, fetch, sel.Image, artifacts, opts.JSON)
+	}
 
-			return listArtifacts(cmd.OutOrStdout(), artifacts, packageName, cfg.Name, selectorType, selectorValue)
-		},
+	// If --all flag, show all artifacts
+	if opts.All {
+		if opts.OutputFile != "" {
+			return fetchAndSaveAllArtifacts(w, ctx, fetch, sel.Image, artifacts, opts.OutputFile, opts.Decode, cfg.Name)
+		}
+		return fetchAndDisplayAllArtifacts(w, ctx, fetch, sel.Image, artifacts, opts.JSON, opts.Decode, cfg.Name)
 	}
 
-	cmd.Flags().StringVar(&tag, "tag", "", "Select version by tag")
-	cmd.Flags().StringVar(&digest, "digest", "", "Select version by digest (supports short form)")
-	cmd.Flags().Int64Var(&versionID, "version", 0, "Select version by ID")
-	cmd.Flags().BoolVar(&all, "all", false, fmt.Sprintf("Show all %s documents", cfg.Name))
-	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output in JSON format")
-	cmd.Flags().StringVarP(&outputFormat, "output", "o", "", "Output format (json, table)")
-	cmd.MarkFlagsMutuallyExclusive("tag", "digest", "version")
+	// Smart behavior: if only one artifact, show it; otherwise list them
+	if len(artifacts) == 1 {
+		if opts.OutputFile != "" {
+			return fetchAndSaveArtifact(w, ctx, fetch, sel.Image, artifacts[0].Digest, opts.OutputFile, opts.Decode, cfg.Name)
+		}
+		return fetchAndDisplayArtifact(w, ctx, fetch, sel.Image, artifacts[0].Digest, opts.JSON, opts.Decode, cfg.Name)
+	}
 
-	cmd.ValidArgsFunction = imageRefValidArgsFunc
+	// Multiple artifacts: if JSON output requested, show all; otherwise list them
+	if opts.JSON && opts.OutputFile == "" {
+		return fetchAndDisplayAllArtifacts(w, ctx, fetch, sel.Image, artifacts, opts.JSON, opts.Decode, cfg.Name)
+	}
 
-	return cmd
+	return listArtifacts(w, artifacts, sel.PackageName, cfg.Name, sel.SelectorType, sel.SelectorValue)
+}
+
+// findArtifactsByRole returns the artifacts with the given role in the graph
+// containing digest, excluding the version identified by digest itself.
+func findArtifactsByRole(versionMap map[string]discover.VersionInfo, digest, role string) []discover.VersionInfo {
+	graphVersions := discover.FindGraphsContainingVersion(versionMap, digest)
+	if len(graphVersions) == 0 {
+		// Fall back to treating it as a root and looking for children
+		graphVersions = discover.FindGraphByDigest(versionMap, digest)
+	}
+
+	var artifacts []discover.VersionInfo
+	for _, v := range graphVersions {
+		// Skip the selected version itself
+		if v.Digest == digest {
+			continue
+		}
+		// Check if any of the types match the requested role
+		for _, t := range v.Types {
+			if t == role {
+				artifacts = append(artifacts, v)
+				break
+			}
+		}
+	}
+	return artifacts
 }
@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/mkoepf/ghcrctl/internal/discover"
+	"github.com/mkoepf/ghcrctl/internal/display"
+	"github.com/mkoepf/ghcrctl/internal/filter"
+	"github.com/mkoepf/ghcrctl/internal/gh"
+)
+
+// Dimensions accepted by --group-by
+const (
+	groupByTagPrefix = "tag-prefix"
+	groupByMonth     = "month"
+	groupByType      = "type"
+	groupByPlatform  = "platform"
+)
+
+var groupByDimensions = []string{groupByTagPrefix, groupByMonth, groupByType, groupByPlatform}
+
+// Group names for versions that have no value in a dimension
+const (
+	groupUntagged = "(untagged)"
+	groupNone     = "(none)"
+	groupUnknown  = "(unknown)"
+)
+
+// versionGroup is the number and total size of the versions in one group
+type versionGroup struct {
+	Group string `json:"group"`
+	Count int    `json:"count"`
+	Size  int64  `json:"size"`
+}
+
+// validateGroupBy checks the dimension given to --group-by
+func validateGroupBy(dimension string) error {
+	for _, d := range groupByDimensions {
+		if d == dimension {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid --group-by %q (valid: %s)", dimension, strings.Join(groupByDimensions, ", "))
+}
+
+// groupVersions counts versions and sums their discovered sizes per group of the
+// given dimension, sorted by group name. A version with several values in a
+// dimension (e.g. tags v1.0 and v2.0, or an attestation that is both sbom and
+// provenance) counts in each of its groups.
+func groupVersions(versions []gh.PackageVersionInfo, discovered []discover.VersionInfo, dimension string) []versionGroup {
+	infos := discover.ToMap(discovered)
+	groups := make(map[string]*versionGroup)
+	for _, ver := range versions {
+		info := infos[ver.Digest]
+		for _, key := range versionGroupKeys(ver, info, dimension) {
+			group, ok := groups[key]
+			if !ok {
+				group = &versionGroup{Group: key}
+				groups[key] = group
+			}
+			group.Count++
+			group.Size += info.Size
+		}
+	}
+
+	result := make([]versionGroup, 0, len(groups))
+	for _, group := range groups {
+		result = append(result, *group)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Group < result[j].Group })
+	return result
+}
+
+// groupGraphVersions counts the discovered versions of listed graphs like
+// groupVersions. A digest counts once, even if GHCR lists it under several
+// version IDs.
+func groupGraphVersions(versions []discover.VersionInfo, dimension string) []versionGroup {
+	listed := make([]gh.PackageVersionInfo, len(versions))
+	for i, v := range versions {
+		listed[i] = gh.PackageVersionInfo{ID: v.ID, Digest: v.Digest, Tags: v.Tags, CreatedAt: v.CreatedAt}
+	}
+	return groupVersions(listed, versions, dimension)
+}
+
+// versionGroupKeys returns the distinct groups of a version in a dimension
+func versionGroupKeys(ver gh.PackageVersionInfo, info discover.VersionInfo, dimension string) []string {
+	var keys []string
+	switch dimension {
+	case groupByTagPrefix:
+		for _, tag := range ver.Tags {
+			keys = append(keys, tagPrefix(tag))
+		}
+		if len(keys) == 0 {
+			return []string{groupUntagged}
+		}
+	case groupByMonth:
+		createdAt, err := filter.ParseDate(ver.CreatedAt)
+		if err != nil {
+			return []string{groupUnknown}
+		}
+		return []string{createdAt.Format("2006-01")}
+	case groupByType:
+		for _, t := range info.Types {
+			if strings.Contains(t, "/") {
+				t = "platform"
+			}
+			keys = append(keys, t)
+		}
+		if len(keys) == 0 {
+			return []string{groupUnknown}
+		}
+	case groupByPlatform:
+		for _, t := range info.Types {
+			if strings.Contains(t, "/") {
+				keys = append(keys, t)
+			}
+		}
+		if len(keys) == 0 {
+			return []string{groupNone}
+		}
+	}
+	return uniqueStrings(keys)
+}
+
+// tagPrefix returns the leading token of a tag, up to the first '.', '-' or '_'.
+// v1.2.3 and v1-rc1 both yield v1; latest yields latest.
+func tagPrefix(tag string) string {
+	if i := strings.IndexAny(tag, ".-_"); i > 0 {
+		return tag[:i]
+	}
+	return tag
+}
+
+// uniqueStrings returns list without repeated entries, keeping the first occurrence
+func uniqueStrings(list []string) []string {
+	var result []string
+	for _, s := range list {
+		if !containsString(result, s) {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// outputGroupsTable prints the groups found by groupVersions. Counts and sizes
+// are right-aligned; with rawBytes, sizes are exact byte counts.
+func outputGroupsTable(w io.Writer, groups []versionGroup, packageName, dimension string, rawBytes, quiet bool) error {
+	if !quiet {
+		fmt.Fprintf(w, "Versions of %s by %s:\n\n", packageName, dimension)
+	}
+
+	formatSize := discover.FormatSize
+	if rawBytes {
+		formatSize = discover.FormatSizeBytes
+	}
+
+	groupWidth := len("GROUP")
+	countWidth := len("COUNT")
+	sizeWidth := len("SIZE")
+	for _, group := range groups {
+		groupWidth = max(groupWidth, len(group.Group))
+		countWidth = max(countWidth, len(display.Thousands(int64(group.Count))))
+		sizeWidth = max(sizeWidth, len(formatSize(group.Size)))
+	}
+
+	fmt.Fprintf(w, "  %s  %s  %s\n",
+		display.ColorHeader(fmt.Sprintf("%-*s", groupWidth, "GROUP")),
+		display.ColorHeader(fmt.Sprintf("%*s", countWidth, "COUNT")),
+		display.ColorHeader(fmt.Sprintf("%*s", sizeWidth, "SIZE")))
+	fmt.Fprintf(w, "  %s  %s  %s\n",
+		display.ColorSeparator(strings.Repeat("-", groupWidth)),
+		display.ColorSeparator(strings.Repeat("-", countWidth)),
+		display.ColorSeparator(strings.Repeat("-", sizeWidth)))
+	for _, group := range groups {
+		fmt.Fprintf(w, "  %-*s  %*s  %*s\n", groupWidth, group.Group,
+			countWidth, display.Thousands(int64(group.Count)), sizeWidth, formatSize(group.Size))
+	}
+
+	if !quiet {
+		fmt.Fprintf(w, "\nTotal: %s group(s).\n", display.ColorCount(len(groups)))
+	}
+	return nil
+}
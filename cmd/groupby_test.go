@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mkoepf/ghcrctl/internal/discover"
+	"github.com/mkoepf/ghcrctl/internal/gh"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// groupTestVersions is a multi-arch image with its platforms, a signature and an
+// attestation that is both sbom and provenance, pushed over two months
+func groupTestVersions() ([]gh.PackageVersionInfo, []discover.VersionInfo) {
+	versions := []gh.PackageVersionInfo{
+		{ID: 1, Digest: "sha256:index", Tags: []string{"v1.2.0", "v1.2", "latest"}, CreatedAt: "2025-01-10 12:00:00"},
+		{ID: 2, Digest: "sha256:amd64", CreatedAt: "2025-01-10 12:00:00"},
+		{ID: 3, Digest: "sha256:arm64", CreatedAt: "2025-01-10 12:00:00"},
+		{ID: 4, Digest: "sha256:attest", CreatedAt: "2025-01-10 12:01:00"},
+		{ID: 5, Digest: "sha256:sig", Tags: []string{"sha256-index.sig"}, CreatedAt: "2025-02-03 08:00:00"},
+		{ID: 6, Digest: "sha256:old", Tags: []string{"v0.9.0"}, CreatedAt: "invalid"},
+	}
+	discovered := []discover.VersionInfo{
+		{ID: 1, Digest: "sha256:index", Types: []string{"index"}, Size: 1000},
+		{ID: 2, Digest: "sha256:amd64", Types: []string{"linux/amd64"}, Size: 200},
+		{ID: 3, Digest: "sha256:arm64", Types: []string{"linux/arm64"}, Size: 300},
+		{ID: 4, Digest: "sha256:attest", Types: []string{"sbom", "provenance"}, Size: 50},
+		{ID: 5, Digest: "sha256:sig", Types: []string{"signature"}, Size: 10},
+	}
+	return versions, discovered
+}
+
+func TestGroupVersions_ByMonth(t *testing.T) {
+	t.Parallel()
+	versions, discovered := groupTestVersions()
+
+	assert.Equal(t, []versionGroup{
+		{Group: "(unknown)", Count: 1, Size: 0},
+		{Group: "2025-01", Count: 4, Size: 1550},
+		{Group: "2025-02", Count: 1, Size: 10},
+	}, groupVersions(versions, discovered, groupByMonth))
+}
+
+func TestGroupVersions_ByType(t *testing.T) {
+	t.Parallel()
+	versions, discovered := groupTestVersions()
+
+	assert.Equal(t, []versionGroup{
+		{Group: "(unknown)", Count: 1, Size: 0},
+		{Group: "index", Count: 1, Size: 1000},
+		{Group: "platform", Count: 2, Size: 500},
+		{Group: "provenance", Count: 1, Size: 50},
+		{Group: "sbom", Count: 1, Size: 50},
+		{Group: "signature", Count: 1, Size: 10},
+	}, groupVersions(versions, discovered, groupByType))
+}
+
+func TestGroupVersions_ByTagPrefixAndPlatform(t *testing.T) {
+	t.Parallel()
+	versions, discovered := groupTestVersions()
+
+	assert.Equal(t, []versionGroup{
+		{Group: "(untagged)", Count: 3, Size: 550},
+		{Group: "latest", Count: 1, Size: 1000},
+		{Group: "sha256", Count: 1, Size: 10},
+		{Group: "v0", Count: 1, Size: 0},
+		{Group: "v1", Count: 1, Size: 1000},
+	}, groupVersions(versions, discovered, groupByTagPrefix))
+
+	assert.Equal(t, []versionGroup{
+		{Group: "(none)", Count: 4, Size: 1060},
+		{Group: "linux/amd64", Count: 1, Size: 200},
+		{Group: "linux/arm64", Count: 1, Size: 300},
+	}, groupVersions(versions, discovered, groupByPlatform))
+}
+
+func TestTagPrefix(t *testing.T) {
+	t.Parallel()
+	tests := map[string]string{
+		"v1.2.3":           "v1",
+		"v1-rc1":           "v1",
+		"nightly_20250101": "nightly",
+		"latest":           "latest",
+		".hidden":          ".hidden",
+	}
+	for tag, want := range tests {
+		assert.Equal(t, want, tagPrefix(tag), tag)
+	}
+}
+
+func TestOutputGroupsTable(t *testing.T) {
+	t.Parallel()
+	versions, discovered := groupTestVersions()
+
+	var buf bytes.Buffer
+	require.NoError(t, outputGroupsTable(&buf, groupVersions(versions, discovered, groupByMonth), "myimage", groupByMonth, false, false))
+
+	output := buf.String()
+	assert.Contains(t, output, "Versions of myimage by month:")
+	assert.Contains(t, output, "GROUP")
+	assert.Contains(t, output, "2025-01")
+	assert.Contains(t, output, "1.5 KiB")
+	assert.Contains(t, output, "Total: 3 group(s).")
+}
+
+func TestOutputGroupsTable_RightAlignsNumbers(t *testing.T) {
+	t.Parallel()
+	groups := []versionGroup{
+		{Group: "2025-01", Count: 1234, Size: 1550},
+		{Group: "2025-02", Count: 5, Size: 10},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, outputGroupsTable(&buf, groups, "myimage", groupByMonth, true, true))
+
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	require.Len(t, lines, 4)
+	assert.Equal(t, "  GROUP    COUNT   SIZE", lines[0])
+	assert.Equal(t, "  2025-01  1,234  1,550", lines[2])
+	assert.Equal(t, "  2025-02      5     10", lines[3])
+}
+
+func TestVersionGroup_JSON(t *testing.T) {
+	t.Parallel()
+	versions, discovered := groupTestVersions()
+
+	data, err := json.Marshal(groupVersions(versions, discovered, groupByMonth))
+	require.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"group":"(unknown)","count":1,"size":0},
+		{"group":"2025-01","count":4,"size":1550},
+		{"group":"2025-02","count":1,"size":10}
+	]`, string(data))
+}
+
+func TestListVersionsCmd_GroupByFlags(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{
+			name:    "invalid dimension",
+			args:    []string{"list", "versions", "owner/pkg", "--group-by", "color"},
+			wantErr: `invalid --group-by "color" (valid: tag-prefix, month, type, platform)`,
+		},
+		{
+			name:    "exclusive with duplicates",
+			args:    []string{"list", "versions", "owner/pkg", "--group-by", "month", "--duplicates"},
+			wantErr: "none of the others can be",
+		},
+		{
+			name:    "bytes requires group-by",
+			args:    []string{"list", "versions", "owner/pkg", "--bytes"},
+			wantErr: "--bytes requires --group-by",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cmd := NewRootCmd()
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(new(bytes.Buffer))
+			cmd.SetErr(new(bytes.Buffer))
+
+			err := cmd.Execute()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestGroupGraphVersions(t *testing.T) {
+	t.Parallel()
+	graph := []discover.VersionInfo{
+		{ID: 1, DuplicateIDs: []int64{7}, Digest: "sha256:index", Tags: []string{"v1.2.0"}, Types: []string{"index"}, Size: 1000, CreatedAt: "2025-01-10 12:00:00"},
+		{ID: 2, Digest: "sha256:amd64", Types: []string{"linux/amd64"}, Size: 200, CreatedAt: "2025-01-10 12:00:00"},
+		{ID: 5, Digest: "sha256:sig", Types: []string{"signature"}, Size: 10, CreatedAt: "2025-02-03 08:00:00"},
+	}
+
+	assert.Equal(t, []versionGroup{
+		{Group: "2025-01", Count: 2, Size: 1200},
+		{Group: "2025-02", Count: 1, Size: 10},
+	}, groupGraphVersions(graph, groupByMonth), "the duplicate ID of the index counts once")
+
+	assert.Equal(t, []versionGroup{
+		{Group: "(untagged)", Count: 2, Size: 210},
+		{Group: "v1", Count: 1, Size: 1000},
+	}, groupGraphVersions(graph, groupByTagPrefix))
+}
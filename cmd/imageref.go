@@ -3,12 +3,25 @@ package cmd
 import (
 	"fmt"
 	"strings"
+
+	"github.com/spf13/cobra"
 )
 
+// ownerFlag is the persistent flag that sets the owner of bare package names
+const ownerFlag = "owner"
+
+// defaultOwner returns the owner for package references without one: --owner,
+// or the owner of the selected profile
+func defaultOwner(cmd *cobra.Command) string {
+	owner, _ := cmd.Flags().GetString(ownerFlag)
+	return owner
+}
+
 // parsePackageRef parses a package reference in the format owner/package
 // It rejects inline tags - use selector flags (--tag, --digest, --version) instead.
+// A bare package name uses defaultOwner; an explicit owner in ref always wins.
 // Returns owner, package name, and error
-func parsePackageRef(ref string) (owner, packageName string, err error) {
+func parsePackageRef(ref, defaultOwner string) (owner, packageName string, err error) {
 	if ref == "" {
 		return "", "", fmt.Errorf("package reference cannot be empty")
 	}
@@ -21,7 +34,10 @@ func parsePackageRef(ref string) (owner, packageName string, err error) {
 	// Split on slash to get owner and package
 	slashIdx := strings.Index(ref, "/")
 	if slashIdx == -1 {
-		return "", "", fmt.Errorf("invalid package reference %q: must be in format owner/package", ref)
+		if defaultOwner == "" {
+			return "", "", fmt.Errorf("invalid package reference %q: must be in format owner/package, or set the owner with --owner", ref)
+		}
+		return defaultOwner, ref, nil
 	}
 
 	owner = ref[:slashIdx]
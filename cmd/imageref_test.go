@@ -3,6 +3,7 @@ package cmd
 import (
 	"testing"
 
+	"github.com/mkoepf/ghcrctl/internal/config"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -13,6 +14,7 @@ func TestParsePackageRef(t *testing.T) {
 	tests := []struct {
 		name        string
 		input       string
+		owner       string
 		wantOwner   string
 		wantPackage string
 		wantErr     bool
@@ -56,6 +58,27 @@ func TestParsePackageRef(t *testing.T) {
 			wantErr:     true,
 			errContains: "must be in format owner/package",
 		},
+		{
+			name:        "bare package with default owner",
+			input:       "justpackage",
+			owner:       "myorg",
+			wantOwner:   "myorg",
+			wantPackage: "justpackage",
+		},
+		{
+			name:        "explicit owner wins over default owner",
+			input:       "mkoepf/myimage",
+			owner:       "myorg",
+			wantOwner:   "mkoepf",
+			wantPackage: "myimage",
+		},
+		{
+			name:        "bare package with inline tag rejected",
+			input:       "justpackage:v1",
+			owner:       "myorg",
+			wantErr:     true,
+			errContains: "inline tags not supported",
+		},
 		{
 			name:        "empty owner",
 			input:       "/mypackage",
@@ -78,7 +101,7 @@ func TestParsePackageRef(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			owner, pkg, err := parsePackageRef(tt.input)
+			owner, pkg, err := parsePackageRef(tt.input, tt.owner)
 
 			if tt.wantErr {
 				require.Error(t, err)
@@ -94,3 +117,30 @@ func TestParsePackageRef(t *testing.T) {
 		})
 	}
 }
+
+func TestDefaultOwner(t *testing.T) {
+	t.Parallel()
+
+	cmd := parsedSubcommand(t, "list", "versions", "myimage", "--owner", "myorg")
+	assert.Equal(t, "myorg", defaultOwner(cmd))
+
+	cmd = parsedSubcommand(t, "list", "versions", "mkoepf/myimage")
+	assert.Empty(t, defaultOwner(cmd))
+}
+
+func TestDefaultOwner_FromProfile(t *testing.T) {
+	t.Parallel()
+
+	profile := config.Profile{Owner: "myorg"}
+
+	cmd := parsedSubcommand(t, "delete", "version", "myimage", "--untagged")
+	require.NoError(t, applyProfileDefaults(cmd, profile))
+	owner, packageName, err := parsePackageRef("myimage", defaultOwner(cmd))
+	require.NoError(t, err)
+	assert.Equal(t, "myorg", owner)
+	assert.Equal(t, "myimage", packageName)
+
+	cmd = parsedSubcommand(t, "delete", "version", "myimage", "--owner", "other")
+	require.NoError(t, applyProfileDefaults(cmd, profile))
+	assert.Equal(t, "other", defaultOwner(cmd), "--owner wins over the profile")
+}
@@ -2,10 +2,18 @@ package cmd
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/mkoepf/ghcrctl/internal/discover"
+	"github.com/mkoepf/ghcrctl/internal/display"
 	"github.com/mkoepf/ghcrctl/internal/filter"
+	"github.com/mkoepf/ghcrctl/internal/quiet"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -215,3 +223,409 @@ func TestFilterImagesByTime(t *testing.T) {
 		assert.Len(t, result, 2)
 	})
 }
+
+func TestListGraphsCmd_HasTypeFlags(t *testing.T) {
+	t.Parallel()
+	rootCmd := NewRootCmd()
+	imagesCmd, _, err := rootCmd.Find([]string{"list", "graphs"})
+	require.NoError(t, err, "Failed to find list graphs command")
+
+	assert.NotNil(t, imagesCmd.Flags().Lookup("type"), "expected --type flag")
+	assert.NotNil(t, imagesCmd.Flags().Lookup("exclude-type"), "expected --exclude-type flag")
+}
+
+func TestListGraphsCmd_InvalidType(t *testing.T) {
+	t.Parallel()
+	rootCmd := NewRootCmd()
+	rootCmd.SetOut(&bytes.Buffer{})
+	rootCmd.SetErr(&bytes.Buffer{})
+	rootCmd.SetArgs([]string{"list", "graphs", "owner/test-package", "--exclude-type", "layer"})
+
+	err := rootCmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid --exclude-type")
+}
+
+func TestListGraphsCmd_OnlyRootsExcludesGraphFilters(t *testing.T) {
+	t.Parallel()
+
+	for _, flag := range []string{"--tag=v1", "--digest=sha256:abc", "--type=sbom", "--show-size-totals"} {
+		t.Run(flag, func(t *testing.T) {
+			t.Parallel()
+			rootCmd := NewRootCmd()
+			rootCmd.SetOut(&bytes.Buffer{})
+			rootCmd.SetErr(&bytes.Buffer{})
+			rootCmd.SetArgs([]string{"list", "graphs", "owner/test-package", "--only-roots", flag})
+
+			err := rootCmd.Execute()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "only-roots")
+		})
+	}
+}
+
+func TestParseDigestLength(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		value    string
+		expected int
+		wantErr  bool
+	}{
+		{"12", 12, false},
+		{"19", 19, false},
+		{"64", 64, false},
+		{"0", display.FullDigestLength, false},
+		{"full", display.FullDigestLength, false},
+		{"-1", 0, true},
+		{"short", 0, true},
+	}
+
+	for _, tt := range tests {
+		n, err := parseDigestLength(tt.value)
+		if tt.wantErr {
+			assert.ErrorContains(t, err, "invalid --digest-length", tt.value)
+			continue
+		}
+		require.NoError(t, err, tt.value)
+		assert.Equal(t, tt.expected, n, tt.value)
+	}
+}
+
+func TestListGraphsCmd_InvalidDigestLength(t *testing.T) {
+	t.Parallel()
+	rootCmd := NewRootCmd()
+	rootCmd.SetOut(&bytes.Buffer{})
+	rootCmd.SetErr(&bytes.Buffer{})
+	rootCmd.SetArgs([]string{"list", "graphs", "owner/test-package", "--digest-length", "abc"})
+
+	err := rootCmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid --digest-length "abc"`)
+}
+
+func TestReportCycles(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	cycles := [][]string{{"sha256:aaa111", "sha256:bbb222", "sha256:aaa111"}}
+
+	t.Run("cycles found", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		err := reportCycles(ctx, &buf, cycles, "myimage", 3, false)
+		assert.EqualError(t, err, "found 1 cycle(s) in the references of myimage")
+		assert.Contains(t, buf.String(), "Found 1 cycle(s) in the references of myimage:")
+		assert.Contains(t, buf.String(), "  aaa111 -> bbb222 -> aaa111")
+	})
+
+	t.Run("no cycles", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		require.NoError(t, reportCycles(ctx, &buf, nil, "myimage", 3, false))
+		assert.Contains(t, buf.String(), "No cycles found in 3 version(s) of myimage")
+	})
+
+	t.Run("json", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		require.NoError(t, reportCycles(ctx, &buf, nil, "myimage", 3, true))
+		assert.JSONEq(t, `[]`, buf.String())
+
+		buf.Reset()
+		require.Error(t, reportCycles(ctx, &buf, cycles, "myimage", 3, true))
+		assert.JSONEq(t, `[["sha256:aaa111", "sha256:bbb222", "sha256:aaa111"]]`, buf.String())
+	})
+}
+
+func TestListGraphsCmd_CheckCyclesValidation(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{
+			name:    "with filter",
+			args:    []string{"--check-cycles", "--tag", "v1"},
+			wantErr: "--check-cycles checks the whole package and cannot be combined with filters",
+		},
+		{
+			name:    "with only roots",
+			args:    []string{"--check-cycles", "--only-roots"},
+			wantErr: "none of the others can be",
+		},
+		{
+			name:    "with highlight",
+			args:    []string{"--check-cycles", "--highlight", "abc123"},
+			wantErr: "none of the others can be",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			rootCmd := NewRootCmd()
+			rootCmd.SetOut(new(bytes.Buffer))
+			rootCmd.SetErr(new(bytes.Buffer))
+			rootCmd.SetArgs(append([]string{"list", "graphs", "owner/test-package"}, tt.args...))
+
+			err := rootCmd.Execute()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestListGraphsCmd_GraphEdgesRequiresJSON(t *testing.T) {
+	t.Parallel()
+	rootCmd := NewRootCmd()
+	rootCmd.SetOut(new(bytes.Buffer))
+	rootCmd.SetErr(new(bytes.Buffer))
+	rootCmd.SetArgs([]string{"list", "graphs", "owner/test-package", "--graph-edges"})
+
+	err := rootCmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--graph-edges requires JSON output")
+}
+
+func TestOutputEmptyResult(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	t.Run("json array", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		require.NoError(t, outputEmptyResult(ctx, &buf, true, []discover.VersionInfo{}, "No graphs found for myimage"))
+		assert.Equal(t, "[]\n", buf.String(), "JSON mode must not print prose")
+	})
+
+	t.Run("json with size totals", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		require.NoError(t, outputEmptyResult(ctx, &buf, true, graphsWithTotals{SchemaVersion: 1, Versions: []discover.VersionInfo{}}, "No graphs found for myimage"))
+		assert.JSONEq(t, `{"schema_version": 1, "versions": [], "totals": {"graphs": 0, "versions": 0, "size": 0}}`, buf.String())
+	})
+
+	t.Run("table", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		require.NoError(t, outputEmptyResult(ctx, &buf, false, []discover.VersionInfo{}, "No graphs found for myimage"))
+		assert.Equal(t, "No graphs found for myimage\n", buf.String())
+	})
+}
+
+func TestListGraphsCmd_HighlightRequiresTree(t *testing.T) {
+	t.Parallel()
+	for _, args := range [][]string{
+		{"--highlight", "abc123", "--json"},
+		{"--highlight", "abc123", "--flat"},
+		{"--highlight", "abc123", "-o", "table"},
+	} {
+		rootCmd := NewRootCmd()
+		rootCmd.SetOut(new(bytes.Buffer))
+		rootCmd.SetErr(new(bytes.Buffer))
+		rootCmd.SetArgs(append([]string{"list", "graphs", "owner/test-package"}, args...))
+
+		err := rootCmd.Execute()
+		assert.EqualError(t, err, "--highlight only applies to tree output", "args %v", args)
+	}
+}
+
+func TestListGraphsCmd_FromJSON(t *testing.T) {
+	t.Parallel()
+	data, err := json.Marshal(sharedPlatformGraphs())
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "graphs.json")
+	require.NoError(t, os.WriteFile(path, data, 0o644))
+
+	run := func(t *testing.T, stdin string, args ...string) string {
+		t.Helper()
+		rootCmd := NewRootCmd()
+		var out bytes.Buffer
+		rootCmd.SetOut(&out)
+		rootCmd.SetErr(new(bytes.Buffer))
+		rootCmd.SetIn(strings.NewReader(stdin))
+		rootCmd.SetArgs(append([]string{"list", "graphs", "mkoepf/myimage"}, args...))
+		require.NoError(t, rootCmd.Execute())
+		return out.String()
+	}
+
+	t.Run("re-rendering a dump is stable", func(t *testing.T) {
+		t.Parallel()
+		dump := run(t, "", "--from-json", path, "--json")
+		assert.Equal(t, dump, run(t, dump, "--from-json", "-", "--json"))
+
+		tree := run(t, "", "--from-json", path)
+		assert.Equal(t, tree, run(t, dump, "--from-json", "-"))
+		assert.Contains(t, tree, "Total: 5 versions in 2 graphs")
+	})
+
+	t.Run("tag resolves from the dump", func(t *testing.T) {
+		t.Parallel()
+		out := run(t, "", "--from-json", path, "--tag", "v2", "--flat")
+		assert.Contains(t, out, "index2")
+		assert.NotContains(t, out, "arm64")
+	})
+
+	t.Run("group by platform within a graph", func(t *testing.T) {
+		t.Parallel()
+		out := run(t, "", "--from-json", path, "--tag", "v1", "--group-by", "platform", "--json", "--compact")
+		assert.JSONEq(t, `[
+			{"group": "(none)", "count": 2, "size": 0},
+			{"group": "linux/amd64", "count": 1, "size": 0},
+			{"group": "linux/arm64", "count": 1, "size": 0}
+		]`, out)
+
+		table := run(t, "", "--from-json", path, "--group-by", "type")
+		assert.Contains(t, table, "Versions of myimage by type:")
+		assert.Contains(t, table, "Total: 4 group(s).")
+	})
+}
+
+func TestListGraphsCmd_ImageObject(t *testing.T) {
+	t.Parallel()
+	data, err := json.Marshal(sharedPlatformGraphs())
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "graphs.json")
+	require.NoError(t, os.WriteFile(path, data, 0o644))
+
+	run := func(args ...string) (string, error) {
+		rootCmd := NewRootCmd()
+		var out bytes.Buffer
+		rootCmd.SetOut(&out)
+		rootCmd.SetErr(new(bytes.Buffer))
+		rootCmd.SetArgs(append([]string{"list", "graphs", "mkoepf/myimage", "--from-json", path, "--json", "--compact", "--image-object"}, args...))
+		err := rootCmd.Execute()
+		return out.String(), err
+	}
+
+	t.Run("multi-arch image", func(t *testing.T) {
+		t.Parallel()
+		out, err := run("--tag", "v1")
+		require.NoError(t, err)
+
+		var object discover.ImageObject
+		require.NoError(t, json.Unmarshal([]byte(out), &object))
+		assert.Equal(t, "sha256:index1", object.Digest)
+		assert.Equal(t, []string{"v1"}, object.Tags)
+		require.Len(t, object.Manifests, 2)
+		assert.Equal(t, []string{"linux/amd64"}, object.Manifests[0].Types)
+		assert.Equal(t, []string{"linux/arm64"}, object.Manifests[1].Types)
+		assert.Equal(t, []int64{15}, object.Manifests[1].DuplicateIDs)
+		require.Len(t, object.Referrers, 1)
+		assert.Equal(t, []string{"sbom", "provenance"}, object.Referrers[0].Types)
+	})
+
+	t.Run("several graphs", func(t *testing.T) {
+		t.Parallel()
+		_, err := run("--digest", "sha256:amd64")
+		assert.EqualError(t, err, "--image-object needs exactly one graph, but 2 match; select one with --tag, --digest or --version")
+	})
+
+	t.Run("no graph", func(t *testing.T) {
+		t.Parallel()
+		out, err := run("--type", "vex")
+		require.NoError(t, err)
+		assert.Equal(t, "null\n", out)
+	})
+}
+
+func TestListGraphsCmd_ObjectOutputsHaveSchemaVersion(t *testing.T) {
+	t.Parallel()
+	data, err := json.Marshal(sharedPlatformGraphs())
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "graphs.json")
+	require.NoError(t, os.WriteFile(path, data, 0o644))
+
+	tests := []struct {
+		name string
+		args []string
+		key  string // a field of the normal output next to schema_version
+	}{
+		{"size totals", []string{"--show-size-totals"}, "totals"},
+		{"graph edges", []string{"--graph-edges"}, "edges"},
+		{"image object", []string{"--image-object", "--tag", "v1"}, "manifests"},
+	}
+	for _, tt := range tests {
+		for _, envelope := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s, envelope %t", tt.name, envelope), func(t *testing.T) {
+				t.Parallel()
+				args := append([]string{"list", "graphs", "mkoepf/myimage", "--from-json", path, "--json"}, tt.args...)
+				if envelope {
+					args = append(args, "--envelope")
+				}
+				rootCmd := NewRootCmd()
+				var out bytes.Buffer
+				rootCmd.SetOut(&out)
+				rootCmd.SetErr(new(bytes.Buffer))
+				rootCmd.SetArgs(args)
+				require.NoError(t, rootCmd.Execute())
+
+				var got map[string]interface{}
+				require.NoError(t, json.Unmarshal(out.Bytes(), &got))
+				assert.EqualValues(t, display.SchemaVersion, got["schema_version"])
+				assert.Contains(t, got, tt.key, "objects are not wrapped in items")
+			})
+		}
+	}
+
+	t.Run("nested image objects have no schema version", func(t *testing.T) {
+		t.Parallel()
+		rootCmd := NewRootCmd()
+		var out bytes.Buffer
+		rootCmd.SetOut(&out)
+		rootCmd.SetErr(new(bytes.Buffer))
+		rootCmd.SetArgs([]string{"list", "graphs", "mkoepf/myimage", "--from-json", path, "--json", "--image-object", "--tag", "v1"})
+		require.NoError(t, rootCmd.Execute())
+
+		var got struct {
+			Manifests []map[string]interface{} `json:"manifests"`
+		}
+		require.NoError(t, json.Unmarshal(out.Bytes(), &got))
+		require.NotEmpty(t, got.Manifests)
+		assert.NotContains(t, got.Manifests[0], "schema_version")
+	})
+}
+
+func TestListGraphsCmd_FromJSONValidation(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{name: "stdin refs", args: []string{"-", "--from-json", "graphs.json"}, wantErr: "cannot be combined with package references from stdin"},
+		{name: "only roots", args: []string{"owner/pkg", "--from-json", "graphs.json", "--only-roots"}, wantErr: "none of the others can be"},
+		{name: "missing file", args: []string{"owner/pkg", "--from-json", filepath.Join(t.TempDir(), "missing.json")}, wantErr: "failed to read versions"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			rootCmd := NewRootCmd()
+			rootCmd.SetOut(new(bytes.Buffer))
+			rootCmd.SetErr(new(bytes.Buffer))
+			rootCmd.SetArgs(append([]string{"list", "graphs"}, tt.args...))
+			err := rootCmd.Execute()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestPrintDiscoveryWarnings(t *testing.T) {
+	t.Parallel()
+	warning := `tag "latest" is listed on 2 digests: sha256:new, sha256:old`
+	versions := []discover.VersionInfo{
+		{Digest: "sha256:new", Tags: []string{"latest"}, Warnings: []string{warning}},
+		{Digest: "sha256:old", Tags: []string{"latest"}, Warnings: []string{warning}},
+		{Digest: "sha256:other"},
+	}
+
+	var buf bytes.Buffer
+	printDiscoveryWarnings(context.Background(), &buf, versions)
+	assert.Equal(t, "Warning: "+warning+"\n", buf.String(), "a warning shared by versions is printed once")
+
+	buf.Reset()
+	printDiscoveryWarnings(quiet.EnableQuiet(context.Background()), &buf, versions)
+	assert.Empty(t, buf.String())
+}
@@ -2,8 +2,13 @@ package cmd
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
 	"testing"
 
+	"github.com/mkoepf/ghcrctl/internal/display"
+
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -74,3 +79,255 @@ func TestLabelsCommandHasFlags(t *testing.T) {
 		assert.NotNil(t, flag, "Expected --%s flag to exist", flagName)
 	}
 }
+
+const (
+	labelsIndexDigest = "sha256:1000000000000000000000000000000000000000000000000000000000000000"
+	labelsAmd64Digest = "sha256:2000000000000000000000000000000000000000000000000000000000000000"
+	labelsArm64Digest = "sha256:3000000000000000000000000000000000000000000000000000000000000000"
+	labelsAttDigest   = "sha256:4000000000000000000000000000000000000000000000000000000000000000"
+)
+
+// labelsIndex is a multi-arch index whose platforms were built with different labels
+var labelsIndex = []byte(`{"schemaVersion":2,"mediaType":"application/vnd.oci.image.index.v1+json","manifests":[` +
+	`{"mediaType":"application/vnd.oci.image.manifest.v1+json","digest":"` + labelsAmd64Digest + `","size":1,"platform":{"architecture":"amd64","os":"linux"}},` +
+	`{"mediaType":"application/vnd.oci.image.manifest.v1+json","digest":"` + labelsArm64Digest + `","size":1,"platform":{"architecture":"arm64","os":"linux"}},` +
+	`{"mediaType":"application/vnd.oci.image.manifest.v1+json","digest":"` + labelsAttDigest + `","size":1,"platform":{"architecture":"unknown","os":"unknown"}}]}`)
+
+func fetchLabelsIndex(ctx context.Context, image, digest string) ([]byte, string, error) {
+	switch digest {
+	case labelsIndexDigest:
+		return labelsIndex, "application/vnd.oci.image.index.v1+json", nil
+	case labelsAmd64Digest:
+		return []byte(`{}`), "application/vnd.oci.image.manifest.v1+json", nil
+	}
+	return nil, "", fmt.Errorf("manifest unknown")
+}
+
+func fetchDivergentLabels(ctx context.Context, image, digest string) (map[string]string, error) {
+	switch digest {
+	case labelsAmd64Digest:
+		return map[string]string{
+			"org.opencontainers.image.source":  "https://github.com/mkoepf/myimage",
+			"org.opencontainers.image.version": "1.0.0",
+		}, nil
+	case labelsArm64Digest:
+		return map[string]string{
+			"org.opencontainers.image.source":  "https://github.com/mkoepf/myimage",
+			"org.opencontainers.image.version": "0.9.0",
+			"com.example.arch-only":            "true",
+		}, nil
+	}
+	return nil, fmt.Errorf("no config for %s", digest)
+}
+
+func TestGetPlatformLabels_DivergentPlatforms(t *testing.T) {
+	t.Parallel()
+
+	platformLabels, err := getPlatformLabels(context.Background(), fetchLabelsIndex, fetchDivergentLabels, "ghcr.io/mkoepf/myimage", labelsIndexDigest)
+	require.NoError(t, err)
+	require.Len(t, platformLabels, 2, "the attestation manifest is not a platform")
+	assert.Equal(t, "1.0.0", platformLabels["linux/amd64"]["org.opencontainers.image.version"])
+	assert.Equal(t, "0.9.0", platformLabels["linux/arm64"]["org.opencontainers.image.version"])
+
+	assert.Equal(t, []string{"com.example.arch-only", "org.opencontainers.image.version"}, divergentLabelKeys(platformLabels))
+
+	var buf bytes.Buffer
+	require.NoError(t, outputPlatformLabelsTable(&buf, platformLabels, "myimage", "v1.0.0", labelsIndexDigest))
+	output := buf.String()
+	assert.Contains(t, output, "Labels for myimage (v1.0.0) by platform:")
+	assert.Contains(t, output, "linux/amd64:")
+	assert.Contains(t, output, "linux/arm64:")
+	assert.Contains(t, output, "Labels differ between platforms: com.example.arch-only, org.opencontainers.image.version")
+
+	buf.Reset()
+	ctx := display.WithJSONStyle(context.Background(), display.JSONStyleCompact)
+	require.NoError(t, display.OutputJSON(ctx, &buf, platformLabels))
+	var decoded map[string]map[string]string
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, platformLabels, decoded)
+}
+
+func TestGetPlatformLabels_Errors(t *testing.T) {
+	t.Parallel()
+
+	_, err := getPlatformLabels(context.Background(), fetchLabelsIndex, fetchDivergentLabels, "ghcr.io/mkoepf/myimage", labelsAmd64Digest)
+	assert.ErrorContains(t, err, "--platform requires an image index")
+
+	failing := func(ctx context.Context, image, digest string) (map[string]string, error) {
+		return nil, fmt.Errorf("config blob unknown")
+	}
+	_, err = getPlatformLabels(context.Background(), fetchLabelsIndex, failing, "ghcr.io/mkoepf/myimage", labelsIndexDigest)
+	assert.EqualError(t, err, "platform linux/amd64: config blob unknown")
+}
+
+func TestFilterPlatformLabels(t *testing.T) {
+	t.Parallel()
+
+	platformLabels, err := getPlatformLabels(context.Background(), fetchLabelsIndex, fetchDivergentLabels, "ghcr.io/mkoepf/myimage", labelsIndexDigest)
+	require.NoError(t, err)
+
+	filtered, err := filterPlatformLabels(platformLabels, labelKeyFilter{Key: "com.example.arch-only"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]map[string]string{
+		"linux/amd64": {},
+		"linux/arm64": {"com.example.arch-only": "true"},
+	}, filtered)
+
+	_, err = filterPlatformLabels(platformLabels, labelKeyFilter{Key: "missing"})
+	assert.EqualError(t, err, `label key "missing" not found on any platform`)
+
+	filtered, err = filterPlatformLabels(platformLabels, labelKeyFilter{Prefix: "com.example."})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]map[string]string{
+		"linux/amd64": {},
+		"linux/arm64": {"com.example.arch-only": "true"},
+	}, filtered)
+
+	_, err = filterPlatformLabels(platformLabels, labelKeyFilter{Prefix: "io.missing."})
+	assert.EqualError(t, err, `label keys starting with "io.missing." not found on any platform`)
+}
+
+func TestFilterLabels(t *testing.T) {
+	t.Parallel()
+	labels := map[string]string{
+		"org.opencontainers.image.source":  "https://github.com/mkoepf/myimage",
+		"org.opencontainers.image.version": "1.0.0",
+		"com.example.team":                 "platform",
+	}
+
+	tests := []struct {
+		name    string
+		filter  labelKeyFilter
+		want    map[string]string
+		wantErr string
+	}{
+		{
+			name:   "prefix",
+			filter: labelKeyFilter{Prefix: "org.opencontainers.image."},
+			want: map[string]string{
+				"org.opencontainers.image.source":  "https://github.com/mkoepf/myimage",
+				"org.opencontainers.image.version": "1.0.0",
+			},
+		},
+		{
+			name:   "key takes precedence over prefix",
+			filter: labelKeyFilter{Key: "com.example.team", Prefix: "org.opencontainers.image."},
+			want:   map[string]string{"com.example.team": "platform"},
+		},
+		{
+			name:    "no match",
+			filter:  labelKeyFilter{Prefix: "io.k8s."},
+			wantErr: `label keys starting with "io.k8s." not found`,
+		},
+		{
+			name:    "missing key",
+			filter:  labelKeyFilter{Key: "missing", Prefix: "com."},
+			wantErr: `label key "missing" not found`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := filterLabels(labels, tt.filter)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestOutputPlatformLabelsTable_SameLabels(t *testing.T) {
+	t.Parallel()
+	same := map[string]string{"org.opencontainers.image.version": "1.0.0"}
+
+	var buf bytes.Buffer
+	require.NoError(t, outputPlatformLabelsTable(&buf, map[string]map[string]string{
+		"linux/amd64": same,
+		"linux/arm64": same,
+	}, "myimage", "", labelsIndexDigest))
+	assert.Contains(t, buf.String(), "All 2 platform(s) have the same labels")
+}
+
+func TestEnvVarName(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{key: "org.opencontainers.image.version", want: "ORG_OPENCONTAINERS_IMAGE_VERSION"},
+		{key: "com.example.build-id", want: "COM_EXAMPLE_BUILD_ID"},
+		{key: "maintainer", want: "MAINTAINER"},
+		{key: "already_VALID_1", want: "ALREADY_VALID_1"},
+		{key: "1st.label", want: "_1ST_LABEL"},
+		{key: "with space/and:colon", want: "WITH_SPACE_AND_COLON"},
+		{key: "café", want: "CAF_"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, envVarName(tt.key))
+		})
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{value: "1.2.3", want: `'1.2.3'`},
+		{value: "", want: `''`},
+		{value: "with spaces", want: `'with spaces'`},
+		{value: "it's", want: `'it'\''s'`},
+		{value: `say "hi"`, want: `'say "hi"'`},
+		{value: "$HOME `id` $(id)", want: "'$HOME `id` $(id)'"},
+		{value: "two\nlines", want: "'two\nlines'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, shellQuote(tt.value))
+		})
+	}
+}
+
+func TestOutputLabelsEnv(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	require.NoError(t, outputLabelsEnv(&buf, map[string]string{
+		"org.opencontainers.image.version":     "1.2.3",
+		"org.opencontainers.image.description": "Bob's image",
+	}))
+	assert.Equal(t, "ORG_OPENCONTAINERS_IMAGE_DESCRIPTION='Bob'\\''s image'\n"+
+		"ORG_OPENCONTAINERS_IMAGE_VERSION='1.2.3'\n", buf.String())
+
+	err := outputLabelsEnv(&bytes.Buffer{}, map[string]string{"build.id": "1", "build-id": "2"})
+	assert.EqualError(t, err, "labels build-id and build.id both map to the variable BUILD_ID; select one with --key")
+}
+
+func TestLabelsCmd_EnvConflicts(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{name: "json", args: []string{"--env", "--json"}, wantErr: "none of the others can be"},
+		{name: "platform all", args: []string{"--env", "--platform", "all"}, wantErr: "--env cannot be used with --platform all"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cmd := NewRootCmd()
+			cmd.SetArgs(append([]string{"get", "labels", "owner/pkg", "--tag", "v1"}, tt.args...))
+			cmd.SetOut(&bytes.Buffer{})
+			cmd.SetErr(&bytes.Buffer{})
+			assert.ErrorContains(t, cmd.Execute(), tt.wantErr)
+		})
+	}
+}
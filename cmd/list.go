@@ -1,9 +1,15 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"os"
+	"os/signal"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/mkoepf/ghcrctl/internal/discover"
 	"github.com/mkoepf/ghcrctl/internal/display"
@@ -17,17 +23,19 @@ import (
 func newListCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "list",
-		Short: "List resources (packages, versions, graphs)",
+		Short: "List resources (packages, versions, tags, graphs)",
 		Long: `List resources from GitHub Container Registry.
 
 Available subcommands:
   packages    List all container packages for an owner
   versions    List all versions of a package
+  tags        List the tags of a package
   graphs      List artifact graphs with their relationships`,
 	}
 
 	cmd.AddCommand(newListPackagesCmd())
 	cmd.AddCommand(newListVersionsCmd())
+	cmd.AddCommand(newListTagsCmd())
 	cmd.AddCommand(newListGraphsCmd())
 
 	return cmd
@@ -38,12 +46,17 @@ func newListPackagesCmd() *cobra.Command {
 	var (
 		jsonOutput   bool
 		outputFormat string
+		packageType  string
 	)
 
 	cmd := &cobra.Command{
-		Use:   "packages <owner>",
+		Use:   "packages [owner]",
 		Short: "List container packages for an owner",
 		Long: `List all container packages for the specified owner from GitHub Container Registry.
+The owner may be left out if it is set with --owner or by the selected profile.
+
+Use --package-type docker to list packages in the legacy Docker registry
+namespace instead, which some organizations still have.
 
 Examples:
   # List all packages for a user
@@ -53,22 +66,40 @@ Examples:
   ghcrctl list packages myorg
 
   # List packages in JSON format
-  ghcrctl list packages mkoepf --json`,
-		Args: cobra.ExactArgs(1),
+  ghcrctl list packages mkoepf --json
+
+  # List packages of the legacy docker type
+  ghcrctl list packages myorg --package-type docker
+
+  # List packages of the default owner
+  ghcrctl list packages --owner myorg`,
+		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			owner := args[0]
+			owner := defaultOwner(cmd)
+			if len(args) == 1 {
+				owner = args[0]
+			}
+			if owner == "" {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("owner required: pass it as argument or set --owner")
+			}
 
 			// Handle output format flag (-o)
-			if outputFormat != "" {
-				switch outputFormat {
-				case "json":
-					jsonOutput = true
-				case "table":
-					jsonOutput = false
-				default:
-					cmd.SilenceUsage = true
-					return fmt.Errorf("invalid output format %q. Supported formats: json, table", outputFormat)
-				}
+			mode, err := display.ParseOutputMode(outputFormat, display.OutputModeJSON, display.OutputModeTable)
+			if err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
+			switch mode {
+			case display.OutputModeJSON:
+				jsonOutput = true
+			case display.OutputModeTable:
+				jsonOutput = false
+			}
+
+			if err := gh.ValidatePackageType(packageType); err != nil {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("invalid --package-type: %w", err)
 			}
 
 			// Get GitHub token
@@ -95,7 +126,7 @@ Examples:
 			}
 
 			// List packages
-			packages, err := client.ListPackages(ctx, owner, ownerType)
+			packages, err := client.ListPackages(ctx, owner, ownerType, packageType)
 			if err != nil {
 				cmd.SilenceUsage = true
 				return fmt.Errorf("failed to list packages: %w", err)
@@ -103,14 +134,18 @@ Examples:
 
 			// Output results
 			if jsonOutput {
-				return display.OutputJSON(cmd.OutOrStdout(), packages)
+				if packages == nil {
+					packages = []string{}
+				}
+				return display.OutputJSON(ctx, cmd.OutOrStdout(), packages)
 			}
 			return outputListPackagesTable(cmd.OutOrStdout(), packages, owner, quiet.IsQuiet(cmd.Context()))
 		},
 	}
 
 	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output in JSON format")
-	cmd.Flags().StringVarP(&outputFormat, "output", "o", "", "Output format (json, table)")
+	addOutputFlag(cmd, &outputFormat, display.OutputModeJSON, display.OutputModeTable)
+	cmd.Flags().StringVar(&packageType, "package-type", gh.PackageTypeContainer, "Package type to list (container, docker)")
 
 	return cmd
 }
@@ -142,13 +177,37 @@ func newListVersionsCmd() *cobra.Command {
 		jsonOutput   bool
 		tag          string
 		tagPattern   string
+		tagPrefix    string
+		tagSuffix    string
 		onlyTagged   bool
 		onlyUntagged bool
 		olderThan    string
 		newerThan    string
+		minAge       string
+		maxAge       string
 		outputFormat string
 		versionID    int64
 		digest       string
+		watch        bool
+		interval     time.Duration
+		refresh      time.Duration
+		jsonStream   bool
+		types        []string
+		excludeTypes []string
+		sinceTag     string
+		afterID      int64
+		beforeID     int64
+		showURL      bool
+		duplicates   bool
+		maxTags      int
+		groupBy      string
+		relativeTime bool
+		fields       []string
+		mediaTypes   []string
+		safe         bool
+		rawBytes     bool
+		newestPerTag bool
+		withContext  bool
 	)
 
 	cmd := &cobra.Command{
@@ -159,6 +218,43 @@ func newListVersionsCmd() *cobra.Command {
 This command displays all versions of a package with their version ID, digest,
 tags, and creation date. The version ID can be used with the delete command.
 
+Pass - instead of a package to read owner/package references from stdin.
+
+With --duplicates, versions are grouped by digest and only cleanup candidates
+are shown: digests with more than one version entry (e.g. from repeated pushes)
+and digests with more than --max-tags tags. Filters are applied first.
+
+Untagged versions include the platform manifests and attestations of tagged
+multi-arch images. With --untagged --safe, the package is discovered and only
+orphans are listed: untagged versions outside the graph of any tagged version.
+
+With --group-by, the filtered versions are counted per group instead of being
+listed: by tag-prefix (the leading token of each tag, e.g. v1 for v1.2.3), by
+month of creation, by type, or by platform. The size of each group is the sum
+of its manifest sizes, shown in KiB, MiB and GiB, or exactly with --bytes. A
+version with several tags or types counts in each of its groups.
+
+With --newest-per-tag, each tag is shown only on the newest version carrying
+it, i.e. where it points now. Versions whose tags all moved on are listed as
+untagged; add --tagged to hide them.
+
+--after-id and --before-id keep versions whose ID lies strictly between the
+given IDs; the boundary versions themselves are excluded. Version IDs grow with
+every push, so this selects a stable window unaffected by clocks or time zones.
+
+--min-age and --max-age take only durations and say which way they filter:
+--min-age 30d keeps versions at least 30 days old (same as --older-than 30d),
+--max-age 90d keeps versions at most 90 days old (same as --newer-than 90d).
+
+With --fields, JSON output holds only the given fields of each object, e.g.
+--fields id,digest,tags. Field names are matched regardless of case and
+underscores.
+
+JSON output is a bare array of versions. With --with-context, the array is
+wrapped in an object naming the package it belongs to, so that the output of
+several packages can be merged without losing track of them:
+{"owner": ..., "package": ..., "owner_type": ..., "versions": [...]}.
+
 To see artifact relationships (platform manifests, attestations, signatures),
 use 'ghcrctl list graphs' instead.
 
@@ -175,51 +271,190 @@ Examples:
   # List only untagged versions
   ghcrctl list versions mkoepf/myimage --untagged
 
+  # List only untagged versions that no tagged image references
+  ghcrctl list versions mkoepf/myimage --untagged --safe
+
   # List versions matching a tag pattern (regex)
   ghcrctl list versions mkoepf/myimage --tag-pattern "^v1\\..*"
 
+  # List versions with a tag starting with pr- (literal, no regex)
+  ghcrctl list versions mkoepf/myimage --tag-prefix pr-
+
   # List versions older than a specific date
   ghcrctl list versions mkoepf/myimage --older-than 2025-01-01
 
   # List versions newer than a specific date
   ghcrctl list versions mkoepf/myimage --newer-than 2025-11-01
 
+  # List versions between 30 and 90 days old
+  ghcrctl list versions mkoepf/myimage --min-age 30d --max-age 90d
+
   # List versions older than 30 days
   ghcrctl list versions mkoepf/myimage --older-than 30d
 
   # List versions from the last hour
   ghcrctl list versions mkoepf/myimage --newer-than 1h
 
+  # Show the GitHub web URL of each version
+  ghcrctl list versions mkoepf/myimage --show-url
+
+  # Find digests with several version entries or more than 4 tags
+  ghcrctl list versions mkoepf/myimage --duplicates
+
+  # Report digests with more than 10 tags as tag sprawl
+  ghcrctl list versions mkoepf/myimage --duplicates --max-tags 10
+
+  # Show creation times as "3 days ago"
+  ghcrctl list versions mkoepf/myimage --relative-time
+
+  # Count versions per month, and per type
+  ghcrctl list versions mkoepf/myimage --group-by month
+  ghcrctl list versions mkoepf/myimage --group-by type --json
+
   # Combine filters: untagged versions older than 7 days
   ghcrctl list versions mkoepf/myimage --untagged --older-than 7d
 
   # Filter by specific version ID
   ghcrctl list versions mkoepf/myimage --version 12345678
 
+  # Show what each tag points to now
+  ghcrctl list versions mkoepf/myimage --newest-per-tag --tagged
+
+  # List versions pushed after the version tagged v1.0.0
+  ghcrctl list versions mkoepf/myimage --since-tag v1.0.0
+
+  # List versions with IDs between two known versions (both excluded)
+  ghcrctl list versions mkoepf/myimage --after-id 12345000 --before-id 12346000
+
   # Filter by digest (supports prefix matching)
   ghcrctl list versions mkoepf/myimage --digest sha256:abc123
 
+  # List only platform manifests (hides attestations and signatures)
+  ghcrctl list versions mkoepf/myimage --type platform
+
+  # List everything except supply-chain artifacts
+  ghcrctl list versions mkoepf/myimage --exclude-type sbom --exclude-type provenance --exclude-type signature
+
+  # List Docker manifest lists, e.g. to migrate them to OCI indexes
+  ghcrctl list versions mkoepf/myimage --media-type application/vnd.docker.distribution.manifest.list.v2+json
+
   # List versions in JSON format
-  ghcrctl list versions mkoepf/myimage --json`,
+  ghcrctl list versions mkoepf/myimage --json
+
+  # Output only the ID and tags of each version
+  ghcrctl list versions mkoepf/myimage --json --fields id,tags
+
+  # Include the owner and package in the JSON output
+  ghcrctl list versions mkoepf/myimage --json --with-context
+
+  # Watch for new versions, polling every 30 seconds
+  ghcrctl list versions mkoepf/myimage --watch --interval 30s
+
+  # Stream new versions as NDJSON
+  ghcrctl list versions mkoepf/myimage --watch --json-stream
+
+  # Also report tags moved to another digest, checked every 10 minutes
+  ghcrctl list versions mkoepf/myimage --watch --background-refresh 10m
+
+  # Read package references from stdin, one per line
+  cat packages.txt | ghcrctl list versions -`,
 		Args: cobra.ExactArgs(1),
-		RunE: func(cmd *cobra.Command, args []string) error {
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := applyAgeFlags(minAge, maxAge, &olderThan, &newerThan); err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
+
+			if watch && args[0] == stdinRefArg {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("--watch cannot be used with package references from stdin")
+			}
+			return nil
+		},
+		RunE: withStdinRefs(func() bool { return jsonOutput || outputFormat == "json" }, func(cmd *cobra.Command, args []string) error {
 			// Parse owner/package reference (reject inline tags)
-			owner, packageName, err := parsePackageRef(args[0])
+			owner, packageName, err := parsePackageRef(args[0], defaultOwner(cmd))
 			if err != nil {
 				cmd.SilenceUsage = true
 				return err
 			}
 
+			if jsonStream && !watch {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("--json-stream requires --watch")
+			}
+
+			if cmd.Flags().Changed("background-refresh") && !watch {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("--background-refresh requires --watch")
+			}
+
+			if cmd.Flags().Changed("max-tags") && !duplicates {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("--max-tags requires --duplicates")
+			}
+			if maxTags < 0 {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("--max-tags must not be negative, got %d", maxTags)
+			}
+
+			if groupBy != "" {
+				if err := validateGroupBy(groupBy); err != nil {
+					cmd.SilenceUsage = true
+					return err
+				}
+			}
+
+			if rawBytes && groupBy == "" {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("--bytes requires --group-by")
+			}
+
+			if safe && !onlyUntagged {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("--safe requires --untagged")
+			}
+
+			filterByType := len(types) > 0 || len(excludeTypes) > 0 || len(mediaTypes) > 0
+			if filterByType {
+				if watch {
+					cmd.SilenceUsage = true
+					return fmt.Errorf("--type, --exclude-type and --media-type cannot be used with --watch")
+				}
+				if err := validateTypeFlags(types, excludeTypes); err != nil {
+					cmd.SilenceUsage = true
+					return err
+				}
+			}
+
 			// Handle output format flag (-o)
-			if outputFormat != "" {
-				switch outputFormat {
-				case "json":
-					jsonOutput = true
-				case "table":
-					jsonOutput = false
-				default:
+			mode, err := display.ParseOutputMode(outputFormat, display.OutputModeJSON, display.OutputModeTable)
+			if err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
+			switch mode {
+			case display.OutputModeJSON:
+				jsonOutput = true
+			case display.OutputModeTable:
+				jsonOutput = false
+			}
+
+			if withContext && !jsonOutput {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("--with-context requires --json")
+			}
+
+			if len(fields) > 0 {
+				var sample interface{} = []gh.PackageVersionInfo(nil)
+				if groupBy != "" {
+					sample = []versionGroup(nil)
+				} else if duplicates {
+					sample = []duplicateGroup(nil)
+				}
+				if err := validateFields(jsonOutput, sample, fields); err != nil {
 					cmd.SilenceUsage = true
-					return fmt.Errorf("invalid output format %q. Supported formats: json, table", outputFormat)
+					return err
 				}
 			}
 
@@ -246,6 +481,31 @@ Examples:
 				return fmt.Errorf("failed to determine owner type: %w", err)
 			}
 
+			// Build filter from command-line flags
+			versionFilter, err := buildListVersionFilter(tag, tagPattern, tagPrefix, tagSuffix, onlyTagged, onlyUntagged,
+				olderThan, newerThan, dateLocation(cmd), versionID, digest, afterID, beforeID)
+			if err != nil {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("invalid filter options: %w", err)
+			}
+
+			// Watch mode: poll until interrupted, emitting only new versions
+			if watch {
+				watchCtx, stop := signal.NotifyContext(ctx, os.Interrupt)
+				defer stop()
+				cmd.SilenceUsage = true
+				return watchVersions(watchCtx, client, watchParams{
+					Owner:       owner,
+					OwnerType:   ownerType,
+					PackageName: packageName,
+					Interval:    interval,
+					Filter:      versionFilter,
+					JSONStream:  jsonStream,
+					QuietMode:   quiet.IsQuiet(ctx),
+					Refresh:     refresh,
+				}, cmd.OutOrStdout())
+			}
+
 			// List package versions
 			allVersions, err := client.ListPackageVersions(ctx, owner, ownerType, packageName)
 			if err != nil {
@@ -253,53 +513,422 @@ Examples:
 				return fmt.Errorf("failed to list versions: %w", err)
 			}
 
-			// Build filter from command-line flags
-			versionFilter, err := buildListVersionFilter(tag, tagPattern, onlyTagged, onlyUntagged,
-				olderThan, newerThan, versionID, digest)
-			if err != nil {
-				cmd.SilenceUsage = true
-				return fmt.Errorf("invalid filter options: %w", err)
+			// Restrict to versions created after the reference tag.
+			// Version IDs are assigned in increasing order, so a higher ID means a later push.
+			if sinceTag != "" {
+				sinceID, err := findVersionIDByTag(allVersions, sinceTag)
+				if err != nil {
+					cmd.SilenceUsage = true
+					return fmt.Errorf("invalid --since-tag: %w", err)
+				}
+				versionFilter.MinVersionID = max(versionFilter.MinVersionID, sinceID)
+			}
+
+			// Safe mode, type filtering and grouping need the discovered graphs
+			var discovered []discover.VersionInfo
+			discoverVersions := func() error {
+				if discovered != nil {
+					return nil
+				}
+				ociRef := fmt.Sprintf("ghcr.io/%s/%s", owner, packageName)
+				var allTags []string
+				for _, v := range allVersions {
+					allTags = append(allTags, v.Tags...)
+				}
+				discovered, err = discover.NewPackageDiscoverer().DiscoverPackage(ctx, ociRef, allVersions, allTags)
+				if err != nil {
+					cmd.SilenceUsage = true
+					return fmt.Errorf("failed to discover versions: %w", err)
+				}
+				printDiscoveryWarnings(ctx, cmd.ErrOrStderr(), discovered)
+				return nil
+			}
+
+			// Hide the children of tagged images, which are untagged but not orphans
+			if safe {
+				if err := discoverVersions(); err != nil {
+					return err
+				}
+				versionFilter.TaggedGraphMembers = discover.TaggedGraphMembers(discovered)
 			}
 
 			// Apply filters to determine which versions to display
-			filteredVersions := versionFilter.Apply(allVersions)
+			listedVersions := allVersions
+			if newestPerTag {
+				listedVersions = newestVersionPerTag(allVersions)
+			}
+			filteredVersions := versionFilter.Apply(listedVersions)
+
+			if (filterByType || groupBy != "") && len(filteredVersions) > 0 {
+				if err := discoverVersions(); err != nil {
+					return err
+				}
+				if filterByType {
+					filteredVersions = filterVersionsByType(filteredVersions, discovered, types, excludeTypes, mediaTypes)
+				}
+			}
+
 			if len(filteredVersions) == 0 {
-				fmt.Fprintln(cmd.OutOrStdout(), "No versions found matching filter criteria")
-				return nil
+				if withContext {
+					return outputVersionsWithContext(ctx, cmd.OutOrStdout(), owner, ownerType, packageName, []gh.PackageVersionInfo{}, fields)
+				}
+				return outputEmptyResult(ctx, cmd.OutOrStdout(), jsonOutput, []gh.PackageVersionInfo{}, "No versions found matching filter criteria")
+			}
+
+			// Count versions per group instead of listing them
+			if groupBy != "" {
+				groups := groupVersions(filteredVersions, discovered, groupBy)
+				if jsonOutput {
+					return display.OutputJSONFields(ctx, cmd.OutOrStdout(), groups, fields)
+				}
+				return outputGroupsTable(cmd.OutOrStdout(), groups, packageName, groupBy, rawBytes, quiet.IsQuiet(ctx))
+			}
+
+			// Group by digest to report cleanup candidates
+			if duplicates {
+				groups := findDuplicates(filteredVersions, maxTags)
+				if jsonOutput {
+					if groups == nil {
+						groups = []duplicateGroup{}
+					}
+					return display.OutputJSONFields(ctx, cmd.OutOrStdout(), groups, fields)
+				}
+				return outputDuplicatesTable(cmd.OutOrStdout(), groups, packageName, maxTags, quiet.IsQuiet(ctx))
+			}
+
+			if err := appendVersionsSummary(ctx, packageName, filteredVersions); err != nil {
+				cmd.SilenceUsage = true
+				return err
 			}
 
 			// JSON output
+			if withContext {
+				return outputVersionsWithContext(ctx, cmd.OutOrStdout(), owner, ownerType, packageName, filteredVersions, fields)
+			}
 			if jsonOutput {
-				return display.OutputJSON(cmd.OutOrStdout(), filteredVersions)
+				return display.OutputJSONFields(ctx, cmd.OutOrStdout(), filteredVersions, fields)
 			}
 
 			// Table output (default)
-			return outputVersionsTable(cmd.OutOrStdout(), filteredVersions, packageName, quiet.IsQuiet(cmd.Context()))
-		},
+			return outputVersionsTable(cmd.OutOrStdout(), filteredVersions, packageName, showURL, relativeTime, quiet.IsQuiet(cmd.Context()))
+		}),
 	}
 
 	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output in JSON format")
 	cmd.Flags().StringVar(&tag, "tag", "", "Filter versions by exact tag match")
 	cmd.Flags().StringVar(&tagPattern, "tag-pattern", "", "Filter versions by tag regex pattern")
+	cmd.Flags().StringVar(&tagPrefix, "tag-prefix", "", "Filter versions with a tag starting with this literal prefix")
+	cmd.Flags().StringVar(&tagSuffix, "tag-suffix", "", "Filter versions with a tag ending with this literal suffix")
 	cmd.Flags().BoolVar(&onlyTagged, "tagged", false, "Show only tagged versions")
 	cmd.Flags().BoolVar(&onlyUntagged, "untagged", false, "Show only untagged versions")
+	cmd.Flags().BoolVar(&safe, "safe", false, "With --untagged, hide versions that belong to the graph of a tagged version")
 	cmd.Flags().StringVar(&olderThan, "older-than", "", "Show versions older than date or duration (e.g., 2025-01-01, 7d, 24h, 30m)")
 	cmd.Flags().StringVar(&newerThan, "newer-than", "", "Show versions newer than date or duration (e.g., 2025-01-01, 7d, 24h, 30m)")
-	cmd.Flags().StringVarP(&outputFormat, "output", "o", "", "Output format (json, table)")
+	addAgeFlags(cmd, &minAge, &maxAge)
+	addOutputFlag(cmd, &outputFormat, display.OutputModeJSON, display.OutputModeTable)
 	cmd.Flags().Int64Var(&versionID, "version", 0, "Filter by exact version ID")
 	cmd.Flags().StringVar(&digest, "digest", "", "Filter by digest (supports prefix matching)")
+	cmd.Flags().BoolVar(&watch, "watch", false, "Poll for new versions and print them as they appear")
+	cmd.Flags().DurationVar(&interval, "interval", 30*time.Second, "Polling interval for --watch")
+	cmd.Flags().BoolVar(&jsonStream, "json-stream", false, "With --watch, emit each new version as a JSON line (NDJSON) with an \"event\" field")
+	cmd.Flags().DurationVar(&refresh, "background-refresh", 0, "With --watch, look up the owner type and tags again at this age and report moved tags (0 = never)")
+	cmd.Flags().StringSliceVar(&types, "type", nil, "Show only versions of this type (repeatable: index, manifest, platform, sbom, provenance, signature, vex, vuln-scan, attestation)")
+	cmd.Flags().StringSliceVar(&excludeTypes, "exclude-type", nil, "Hide versions of this type (repeatable)")
+	cmd.Flags().StringSliceVar(&mediaTypes, "media-type", nil, "Show only versions with this descriptor media type (repeatable, e.g. application/vnd.oci.image.index.v1+json)")
+	cmd.Flags().StringVar(&sinceTag, "since-tag", "", "Show only versions pushed after the version with this tag")
+	cmd.Flags().Int64Var(&afterID, "after-id", 0, "Show only versions with an ID greater than this (exclusive)")
+	cmd.Flags().Int64Var(&beforeID, "before-id", 0, "Show only versions with an ID less than this (exclusive)")
+	cmd.Flags().BoolVar(&showURL, "show-url", false, "Show the GitHub web URL of each version")
+	cmd.Flags().BoolVar(&duplicates, "duplicates", false, "Show only digests with several version entries or too many tags")
+	cmd.Flags().IntVar(&maxTags, "max-tags", defaultMaxTags, "With --duplicates, report digests with more than this many tags")
+	cmd.Flags().BoolVar(&relativeTime, "relative-time", false, "Show creation times relative to now (e.g. 3 days ago)")
+	cmd.Flags().StringVar(&groupBy, "group-by", "", "Count versions per group instead of listing them (tag-prefix, month, type, platform)")
+	cmd.Flags().BoolVar(&rawBytes, "bytes", false, "With --group-by, show sizes as exact byte counts instead of KiB/MiB/GiB")
+	cmd.Flags().StringSliceVar(&fields, "fields", nil, "With JSON output, include only these fields of each object (e.g. id,digest,tags)")
+	cmd.Flags().BoolVar(&newestPerTag, "newest-per-tag", false, "Show each tag only on the newest version carrying it")
+	cmd.Flags().BoolVar(&withContext, "with-context", false, "With JSON output, wrap the versions in an object with the owner, package and owner type")
 
 	// Mark mutually exclusive flags
 	cmd.MarkFlagsMutuallyExclusive("tagged", "untagged")
+	cmd.MarkFlagsMutuallyExclusive("duplicates", "watch")
+	cmd.MarkFlagsMutuallyExclusive("duplicates", "show-url")
+	cmd.MarkFlagsMutuallyExclusive("group-by", "duplicates")
+	cmd.MarkFlagsMutuallyExclusive("group-by", "watch")
+	cmd.MarkFlagsMutuallyExclusive("group-by", "show-url")
+	cmd.MarkFlagsMutuallyExclusive("watch", "json")
+	cmd.MarkFlagsMutuallyExclusive("watch", "since-tag")
+	cmd.MarkFlagsMutuallyExclusive("watch", "safe")
+	cmd.MarkFlagsMutuallyExclusive("watch", "newest-per-tag")
+	cmd.MarkFlagsMutuallyExclusive("with-context", "watch")
+	cmd.MarkFlagsMutuallyExclusive("with-context", "duplicates")
+	cmd.MarkFlagsMutuallyExclusive("with-context", "group-by")
 
 	cmd.ValidArgsFunction = imageRefValidArgsFunc
 
 	return cmd
 }
 
+// newestVersionPerTag keeps each tag only on the newest (highest ID) version
+// carrying it. Versions whose tags all appear on newer versions are returned
+// without tags. The input is not modified.
+func newestVersionPerTag(versions []gh.PackageVersionInfo) []gh.PackageVersionInfo {
+	newest := make(map[string]int64)
+	for _, v := range versions {
+		for _, t := range v.Tags {
+			if v.ID > newest[t] {
+				newest[t] = v.ID
+			}
+		}
+	}
+
+	result := make([]gh.PackageVersionInfo, len(versions))
+	for i, v := range versions {
+		tags := []string{}
+		for _, t := range v.Tags {
+			if newest[t] == v.ID {
+				tags = append(tags, t)
+			}
+		}
+		v.Tags = tags
+		result[i] = v
+	}
+	return result
+}
+
+// findVersionIDByTag returns the ID of the version carrying the given tag.
+func findVersionIDByTag(versions []gh.PackageVersionInfo, tag string) (int64, error) {
+	for _, v := range versions {
+		for _, t := range v.Tags {
+			if t == tag {
+				return v.ID, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("tag %q not found", tag)
+}
+
+// validateFields checks --fields: it needs JSON output, and every field must
+// exist in the objects of sample, the slice type the command emits
+func validateFields(jsonOutput bool, sample interface{}, fields []string) error {
+	if !jsonOutput {
+		return fmt.Errorf("--fields requires JSON output (--json or -o json)")
+	}
+	if err := display.ValidateFields(sample, fields); err != nil {
+		return fmt.Errorf("invalid --fields: %w", err)
+	}
+	return nil
+}
+
+// reportCycles prints the cycles found by discover.FindCycles, or a JSON array
+// of them, and fails if there are any
+func reportCycles(ctx context.Context, w io.Writer, cycles [][]string, packageName string, versionCount int, jsonOutput bool) error {
+	if jsonOutput {
+		if cycles == nil {
+			cycles = [][]string{}
+		}
+		if err := display.OutputJSON(ctx, w, cycles); err != nil {
+			return err
+		}
+	} else if len(cycles) == 0 {
+		fmt.Fprintf(w, "No cycles found in %s version(s) of %s\n", display.ColorCount(versionCount), packageName)
+	} else {
+		fmt.Fprintf(w, "%s\n", display.ColorError(fmt.Sprintf("Found %d cycle(s) in the references of %s:", len(cycles), packageName)))
+		for _, cycle := range cycles {
+			short := make([]string, len(cycle))
+			for i, digest := range cycle {
+				short[i] = display.ShortDigest(digest)
+			}
+			fmt.Fprintf(w, "  %s\n", strings.Join(short, " -> "))
+		}
+	}
+
+	if len(cycles) > 0 {
+		return fmt.Errorf("found %d cycle(s) in the references of %s", len(cycles), packageName)
+	}
+	return nil
+}
+
+// outputEmptyResult reports that nothing matched: in JSON mode by writing
+// empty, e.g. an empty array, so that stdout stays parseable, and otherwise by
+// printing message
+func outputEmptyResult(ctx context.Context, w io.Writer, jsonOutput bool, empty interface{}, message string) error {
+	if jsonOutput {
+		return display.OutputJSON(ctx, w, empty)
+	}
+	fmt.Fprintln(w, message)
+	return nil
+}
+
+// versionsWithContext is the JSON output of list versions with --with-context
+type versionsWithContext struct {
+	SchemaVersion int         `json:"schema_version"`
+	Owner         string      `json:"owner"`
+	Package       string      `json:"package"`
+	OwnerType     string      `json:"owner_type"`
+	Versions      interface{} `json:"versions"` // []gh.PackageVersionInfo, or projected to --fields
+}
+
+// outputVersionsWithContext writes versions wrapped in an object naming the
+// package they belong to. --fields applies to each version.
+func outputVersionsWithContext(ctx context.Context, w io.Writer, owner, ownerType, packageName string, versions []gh.PackageVersionInfo, fields []string) error {
+	output := versionsWithContext{
+		SchemaVersion: display.SchemaVersion,
+		Owner:         owner,
+		Package:       packageName,
+		OwnerType:     ownerType,
+		Versions:      versions,
+	}
+	if len(fields) > 0 {
+		projected, err := display.ProjectFields(versions, fields)
+		if err != nil {
+			return err
+		}
+		output.Versions = projected
+	}
+	return display.OutputJSON(ctx, w, output)
+}
+
+// singleImageObject returns the graph of list graphs --image-object. The
+// filters must leave exactly one root.
+func singleImageObject(results []discover.VersionInfo, allVersions map[string]discover.VersionInfo) (discover.ImageObject, error) {
+	var roots []discover.VersionInfo
+	for _, v := range results {
+		if v.IsRoot(allVersions) {
+			roots = append(roots, v)
+		}
+	}
+	if len(roots) != 1 {
+		return discover.ImageObject{}, fmt.Errorf("--image-object needs exactly one graph, but %d match; select one with --tag, --digest or --version", len(roots))
+	}
+	return discover.BuildImageObject(roots[0], allVersions), nil
+}
+
+// printDiscoveryWarnings writes the warnings discovery attached to versions to
+// w, each once, unless quiet mode is enabled
+func printDiscoveryWarnings(ctx context.Context, w io.Writer, versions []discover.VersionInfo) {
+	if quiet.IsQuiet(ctx) {
+		return
+	}
+	for _, warning := range discover.Warnings(versions) {
+		fmt.Fprintf(w, "%s %s\n", display.ColorWarning("Warning:"), warning)
+	}
+}
+
+// graphsWithTotals is the JSON output of list graphs with --show-size-totals
+type graphsWithTotals struct {
+	SchemaVersion int                    `json:"schema_version"`
+	Versions      []discover.VersionInfo `json:"versions"`
+	Totals        discover.Totals        `json:"totals"`
+}
+
+// graphEdgesOutput is the JSON output of list graphs with --graph-edges
+type graphEdgesOutput struct {
+	SchemaVersion int `json:"schema_version"`
+	discover.Graph
+}
+
+// imageObjectOutput is the JSON output of list graphs with --image-object. The
+// schema version is only written at the top, not in every nested object.
+type imageObjectOutput struct {
+	SchemaVersion int `json:"schema_version"`
+	discover.ImageObject
+}
+
+// loadVersionsDump reads the versions saved with list graphs --json from the
+// file given to --from-json, or from stdin for -
+func loadVersionsDump(path string, stdin io.Reader) ([]discover.VersionInfo, error) {
+	if path == "-" {
+		versions, err := discover.LoadVersions(stdin)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read versions from stdin: %w", err)
+		}
+		return versions, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read versions: %w", err)
+	}
+	defer f.Close()
+	versions, err := discover.LoadVersions(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read versions from %s: %w", path, err)
+	}
+	return versions, nil
+}
+
+// dumpTagDigest resolves tag to the digest of the version carrying it in a
+// --from-json dump, where the registry is not asked
+func dumpTagDigest(versions []discover.VersionInfo, tag string) (string, error) {
+	for _, v := range versions {
+		if slices.Contains(v.Tags, tag) {
+			return v.Digest, nil
+		}
+	}
+	return "", fmt.Errorf("no version in the dump has tag %q", tag)
+}
+
+// parseDigestLength parses --digest-length: a number of digest characters,
+// or "full" (or 0) to show digests untruncated.
+func parseDigestLength(value string) (int, error) {
+	if value == "full" {
+		return display.FullDigestLength, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid --digest-length %q: use a positive number, 0, or full", value)
+	}
+	if n == 0 {
+		return display.FullDigestLength, nil
+	}
+	return n, nil
+}
+
+// validateTypeFlags checks the roles given to --type and --exclude-type.
+func validateTypeFlags(types, excludeTypes []string) error {
+	if err := discover.ValidateTypeRoles(types); err != nil {
+		return fmt.Errorf("invalid --type: %w", err)
+	}
+	if err := discover.ValidateTypeRoles(excludeTypes); err != nil {
+		return fmt.Errorf("invalid --exclude-type: %w", err)
+	}
+	return nil
+}
+
+// filterVersionsByType keeps the versions whose discovered types pass the
+// include/exclude type filters and whose media type is one of mediaTypes (if
+// any are given). Versions missing from discovered are dropped.
+func filterVersionsByType(versions []gh.PackageVersionInfo, discovered []discover.VersionInfo, include, exclude, mediaTypes []string) []gh.PackageVersionInfo {
+	allowed := make(map[string]bool)
+	for _, v := range discover.FilterByMediaType(discover.FilterByType(discovered, include, exclude), mediaTypes) {
+		allowed[v.Digest] = true
+	}
+
+	var result []gh.PackageVersionInfo
+	for _, v := range versions {
+		if allowed[v.Digest] {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// appendVersionsSummary adds the listed versions to the GitHub Actions job
+// summary, if --github-summary is set
+func appendVersionsSummary(ctx context.Context, packageName string, versions []gh.PackageVersionInfo) error {
+	rows := make([][]string, len(versions))
+	for i, ver := range versions {
+		rows[i] = []string{fmt.Sprintf("%d", ver.ID), display.ShortDigest(ver.Digest),
+			strings.Join(ver.Tags, ", "), ver.CreatedAt}
+	}
+	return display.AppendStepSummary(ctx, fmt.Sprintf("Versions of %s", packageName),
+		[]string{"Version ID", "Digest", "Tags", "Created"}, rows)
+}
+
 // outputVersionsTable outputs a flat list of versions
+// If showURL is true, a URL column with each version's GitHub web page is added.
+// If relativeTime is true, creation times are shown as e.g. "3 days ago".
 // If quiet is true, informational headers and summaries are suppressed.
-func outputVersionsTable(w io.Writer, versions []gh.PackageVersionInfo, packageName string, quiet bool) error {
+func outputVersionsTable(w io.Writer, versions []gh.PackageVersionInfo, packageName string, showURL, relativeTime, quiet bool) error {
 	if len(versions) == 0 {
 		if !quiet {
 			fmt.Fprintf(w, "No versions found for %s\n", packageName)
@@ -324,34 +953,58 @@ func outputVersionsTable(w io.Writer, versions []gh.PackageVersionInfo, packageN
 		if len(digestStr) > maxDigestLen {
 			maxDigestLen = len(digestStr)
 		}
-		if tagsStr := display.FormatTags(ver.Tags); len(tagsStr) > maxTagsLen {
-			maxTagsLen = len(tagsStr)
+		// Tags may contain wide characters, so measure display width rather than bytes
+		if tagsWidth := display.Width(display.FormatTags(ver.Tags)); tagsWidth > maxTagsLen {
+			maxTagsLen = tagsWidth
+		}
+	}
+
+	// The URL column follows CREATED, so CREATED needs padding when it is shown
+	createdWidth := len("CREATED")
+	if showURL {
+		for _, ver := range versions {
+			if created := formatCreatedAt(ver.CreatedAt, relativeTime); len(created) > createdWidth {
+				createdWidth = len(created)
+			}
 		}
 	}
 
 	// Print header
-	fmt.Fprintf(w, "  %s  %s  %s  %s\n",
-		display.ColorHeader(fmt.Sprintf("%-*s", maxIDLen, "VERSION ID")),
+	header := fmt.Sprintf("  %s  %s  %s  %s",
+		display.ColorHeader(fmt.Sprintf("%*s", maxIDLen, "VERSION ID")),
 		display.ColorHeader(fmt.Sprintf("%-*s", maxDigestLen, "DIGEST")),
 		display.ColorHeader(fmt.Sprintf("%-*s", maxTagsLen, "TAGS")),
 		display.ColorHeader("CREATED"))
-	fmt.Fprintf(w, "  %s  %s  %s  %s\n",
+	separator := fmt.Sprintf("  %s  %s  %s  %s",
 		display.ColorSeparator(strings.Repeat("-", maxIDLen)),
 		display.ColorSeparator(strings.Repeat("-", maxDigestLen)),
 		display.ColorSeparator(strings.Repeat("-", maxTagsLen)),
 		display.ColorSeparator(strings.Repeat("-", len("CREATED"))))
+	if showURL {
+		header += strings.Repeat(" ", createdWidth-len("CREATED")) + "  " + display.ColorHeader("URL")
+		separator += strings.Repeat(" ", createdWidth-len("CREATED")) + "  " + display.ColorSeparator(strings.Repeat("-", len("URL")))
+	}
+	fmt.Fprintln(w, header)
+	fmt.Fprintln(w, separator)
 
 	// Print versions
 	for _, ver := range versions {
-		tagsStr := display.FormatTags(ver.Tags)
 		digestStr := display.ShortDigest(ver.Digest)
+		created := formatCreatedAt(ver.CreatedAt, relativeTime)
 
-		fmt.Fprintf(w, "  %-*d  %s  %s%s  %s\n",
+		line := fmt.Sprintf("  %*d  %s  %s  %s",
 			maxIDLen, ver.ID,
 			display.ColorDigest(fmt.Sprintf("%-*s", maxDigestLen, digestStr)),
-			display.ColorTags(ver.Tags),
-			strings.Repeat(" ", maxTagsLen-len(tagsStr)),
-			ver.CreatedAt)
+			display.PadRight(display.ColorTags(ver.Tags), maxTagsLen),
+			created)
+		if showURL {
+			url := ver.HTMLURL
+			if url == "" {
+				url = "-"
+			}
+			line += strings.Repeat(" ", createdWidth-len(created)) + "  " + url
+		}
+		fmt.Fprintln(w, line)
 	}
 
 	// Summary (only in non-quiet mode)
@@ -366,6 +1019,19 @@ func outputVersionsTable(w io.Writer, versions []gh.PackageVersionInfo, packageN
 	return nil
 }
 
+// formatCreatedAt returns createdAt as given by GitHub, or relative to now if
+// relative is true. Timestamps that cannot be parsed are returned unchanged.
+func formatCreatedAt(createdAt string, relative bool) string {
+	if !relative {
+		return createdAt
+	}
+	t, err := filter.ParseDate(createdAt)
+	if err != nil {
+		return createdAt
+	}
+	return display.HumanizeTime(t)
+}
+
 // filterGraphsByTime filters graphs to those where ANY version matches the time criteria.
 // A graph is included if any of its versions (including children in OutgoingRefs)
 // match the time filter.
@@ -417,21 +1083,43 @@ func versionMatchesTime(createdAt string, timeFilter *filter.VersionFilter) bool
 	return true
 }
 
+// validateVersionIDRange checks the --after-id and --before-id bounds. Both are
+// exclusive, so the range must leave room for at least one ID.
+func validateVersionIDRange(afterID, beforeID int64) error {
+	if afterID < 0 {
+		return fmt.Errorf("--after-id must be a positive version ID, got %d", afterID)
+	}
+	if beforeID < 0 {
+		return fmt.Errorf("--before-id must be a positive version ID, got %d", beforeID)
+	}
+	if afterID != 0 && beforeID != 0 && beforeID-afterID < 2 {
+		return fmt.Errorf("--after-id %d and --before-id %d leave no version IDs in between", afterID, beforeID)
+	}
+	return nil
+}
+
 // buildListVersionFilter creates a VersionFilter from command-line flags
-func buildListVersionFilter(tag, tagPattern string, onlyTagged, onlyUntagged bool,
-	olderThan, newerThan string,
-	versionID int64, digest string) (*filter.VersionFilter, error) {
+func buildListVersionFilter(tag, tagPattern, tagPrefix, tagSuffix string, onlyTagged, onlyUntagged bool,
+	olderThan, newerThan string, loc *time.Location,
+	versionID int64, digest string, afterID, beforeID int64) (*filter.VersionFilter, error) {
 	// Check for conflicting flags
 	if onlyTagged && onlyUntagged {
 		return nil, fmt.Errorf("cannot use --tagged and --untagged together")
 	}
+	if err := validateVersionIDRange(afterID, beforeID); err != nil {
+		return nil, err
+	}
 
 	vf := &filter.VersionFilter{
 		OnlyTagged:   onlyTagged,
 		OnlyUntagged: onlyUntagged,
 		TagPattern:   tagPattern,
+		TagPrefix:    tagPrefix,
+		TagSuffix:    tagSuffix,
 		VersionID:    versionID,
 		Digest:       digest,
+		MinVersionID: afterID,
+		MaxVersionID: beforeID,
 	}
 
 	// Handle exact tag match
@@ -441,7 +1129,7 @@ func buildListVersionFilter(tag, tagPattern string, onlyTagged, onlyUntagged boo
 
 	// Parse date/duration filters
 	if olderThan != "" {
-		t, err := filter.ParseDateOrDuration(olderThan)
+		t, err := filter.ParseDateOrDurationIn(olderThan, loc)
 		if err != nil {
 			return nil, fmt.Errorf("invalid --older-than value: %w", err)
 		}
@@ -449,7 +1137,7 @@ func buildListVersionFilter(tag, tagPattern string, onlyTagged, onlyUntagged boo
 	}
 
 	if newerThan != "" {
-		t, err := filter.ParseDateOrDuration(newerThan)
+		t, err := filter.ParseDateOrDurationIn(newerThan, loc)
 		if err != nil {
 			return nil, fmt.Errorf("invalid --newer-than value: %w", err)
 		}
@@ -470,6 +1158,25 @@ func newListGraphsCmd() *cobra.Command {
 		filterTag     string
 		olderThan     string
 		newerThan     string
+		minAge        string
+		maxAge        string
+		types         []string
+		excludeTypes  []string
+		sizeTotals    bool
+		onlyRoots     bool
+		digestLength  string
+		fields        []string
+		checkCycles   bool
+		mediaTypes    []string
+		highlight     string
+		rawBytes      bool
+		indexRefsOnly bool
+		platRefsOnly  bool
+		graphEdges    bool
+		fromJSON      string
+		strict        bool
+		imageObject   bool
+		groupBy       string
 	)
 
 	cmd := &cobra.Command{
@@ -483,7 +1190,70 @@ relationships. Use --flat for a simple table view.
 
 Use --version, --digest, or --tag to filter output to only graphs containing
 a specific version. Use --older-than or --newer-than to filter by time (a graph
-is included if ANY of its versions match the time criteria).
+is included if ANY of its versions match the time criteria). --min-age and
+--max-age are their duration-only forms: --min-age 30d is --older-than 30d,
+--max-age 90d is --newer-than 90d.
+
+Every version shows the size of its manifest or artifact. Use --show-size-totals
+to add the size of each graph and the total size of all listed versions, where
+versions shared by several graphs are counted once. Sizes are shown in KiB, MiB
+and GiB; use --bytes for exact byte counts. JSON sizes are always in bytes.
+
+Use --only-roots for a fast overview of large packages: it lists only the graph
+roots (indexes and standalone manifests) with their tags and sizes. It resolves
+each version's descriptor and the contents of indexes only, so platform
+manifests, attestations and signatures are not fetched and non-index roots are
+shown as "manifest".
+
+With --fields, JSON output holds only the given fields of each version, e.g.
+--fields digest,types,size.
+
+With --check-cycles, the graphs are not listed. Instead, the references found
+by discovery are checked for cycles, which only a bad push can create and which
+would otherwise produce wrong trees. Each cycle is reported with its digests,
+and the command fails if any cycle is found. The whole package is checked, so
+filters cannot be used.
+
+A manifest or index that cannot be parsed is shown as type "unknown" or
+without children. With --strict, discovery fails instead and names the digest
+and media type of every such manifest, to diagnose corrupt pushes.
+
+Referrers (signatures and attestations) are attached either to an index or to
+a platform manifest. --index-referrers-only hides the referrers of platform
+manifests, and --platform-referrers-only hides the referrers of indexes, e.g.
+to audit per-platform signatures. The attestation manifests buildx lists in an
+index count as referrers of the index.
+
+With --graph-edges, the JSON output is an object with a nodes array of the
+versions and an edges array with one {parent_id, child_id, parent_digest,
+child_digest, role} entry per reference, so the graph can be loaded into graph
+databases and visualizers without joining digests to version IDs. The role is
+"manifest" for the manifests of an index and "referrer" for signatures and
+attestations, which are children of the version they are attached to.
+
+With --image-object, the one graph selected by the filters is output as a
+single nested object instead of an array: the root version with a manifests
+array (e.g. its platforms) and a referrers array (signatures and attestations),
+each entry nested the same way. It fails if more than one graph matches, and
+outputs null if none does.
+
+With --group-by, the versions of the listed graphs are counted per group
+instead of being shown, like list versions --group-by: by tag-prefix, month,
+type, or platform, after all filters. A digest counts once, even if GHCR lists
+it under several version IDs.
+
+With --highlight, the version with the given digest (full or short) is marked
+with "◀── HERE" wherever it appears in the tree, e.g. to find the platform of
+a layer reported by a scanner.
+
+With --from-json, the versions are read from a file saved with --json (- for
+stdin) instead of GitHub and the registry, so a snapshot can be rendered,
+filtered and checked offline, e.g. as a CI artifact or in a support ticket.
+No token is needed. The package argument only names the package in messages.
+Dumps written with --envelope or --show-size-totals are accepted. Output
+reduced with --fields or --graph-edges lacks the references and cannot be used.
+
+Pass - instead of a package to read owner/package references from stdin.
 
 Examples:
   # List graphs with relationships (tree view, default)
@@ -495,6 +1265,9 @@ Examples:
   # Output in JSON format
   ghcrctl list graphs mkoepf/my-package --json
 
+  # Output only the digest, types and size of each version
+  ghcrctl list graphs mkoepf/my-package --json --fields digest,types,size
+
   # Filter to graphs containing a specific tag
   ghcrctl list graphs mkoepf/my-package --tag v1.0.0
 
@@ -511,81 +1284,223 @@ Examples:
   ghcrctl list graphs mkoepf/my-package --newer-than 1h
 
   # List graphs with ANY version older than a specific date
-  ghcrctl list graphs mkoepf/my-package --older-than 2025-01-01`,
+  ghcrctl list graphs mkoepf/my-package --older-than 2025-01-01
+
+  # Show only the supply-chain layer (attestations and signatures)
+  ghcrctl list graphs mkoepf/my-package --type sbom --type provenance --type signature
+
+  # Hide attestations and signatures
+  ghcrctl list graphs mkoepf/my-package --exclude-type sbom --exclude-type provenance --exclude-type signature
+
+  # Show only the signatures and attestations of platform manifests
+  ghcrctl list graphs mkoepf/my-package --platform-referrers-only
+
+  # Output the graph as explicit nodes and parent/child edges
+  ghcrctl list graphs mkoepf/my-package --json --graph-edges
+
+  # Output the graph of one tag as a nested object
+  ghcrctl list graphs mkoepf/my-package --tag v1.0.0 --json --image-object
+
+  # List only OCI indexes, not Docker manifest lists
+  ghcrctl list graphs mkoepf/my-package --media-type application/vnd.oci.image.index.v1+json
+
+  # Show the size of each graph and of the whole package
+  ghcrctl list graphs mkoepf/my-package --show-size-totals
+
+  # Count the platforms of all graphs with a version older than 90 days
+  ghcrctl list graphs mkoepf/my-package --older-than 90d --group-by platform
+
+  # Show exact sizes in bytes
+  ghcrctl list graphs mkoepf/my-package --flat --bytes
+
+  # Quickly list only the graph roots of a large package
+  ghcrctl list graphs mkoepf/my-package --only-roots
+
+  # Show complete digests
+  ghcrctl list graphs mkoepf/my-package --digest-length full
+
+  # Check the references of a package for cycles
+  ghcrctl list graphs mkoepf/my-package --check-cycles
+
+  # Mark a version in the tree
+  ghcrctl list graphs mkoepf/my-package --highlight 01af50cc8b0d
+
+  # Render a saved dump without network access
+  ghcrctl list graphs mkoepf/my-package --json > graphs.json
+  ghcrctl list graphs mkoepf/my-package --from-json graphs.json --flat
+
+  # List graphs of every package read from stdin
+  cat packages.txt | ghcrctl list graphs -`,
 		Args: cobra.ExactArgs(1),
-		RunE: func(cmd *cobra.Command, args []string) error {
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := applyAgeFlags(minAge, maxAge, &olderThan, &newerThan); err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
+
+			if fromJSON != "" && args[0] == stdinRefArg {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("--from-json reads one package and cannot be combined with package references from stdin")
+			}
+			return nil
+		},
+		RunE: withStdinRefs(func() bool { return jsonOutput || outputFormat == "json" }, func(cmd *cobra.Command, args []string) error {
 			// Parse owner/package reference (reject inline tags)
-			owner, packageName, err := parsePackageRef(args[0])
+			owner, packageName, err := parsePackageRef(args[0], defaultOwner(cmd))
 			if err != nil {
 				cmd.SilenceUsage = true
 				return err
 			}
 
 			// Handle output format flag (-o)
-			if outputFormat != "" {
-				switch outputFormat {
-				case "json":
-					jsonOutput = true
-				case "table", "flat":
-					flatOutput = true
-				case "tree":
-					flatOutput = false
-				default:
+			mode, err := display.ParseOutputMode(outputFormat, display.OutputModeJSON, display.OutputModeTable, display.OutputModeTree)
+			if err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
+			switch mode {
+			case display.OutputModeJSON:
+				jsonOutput = true
+			case display.OutputModeTable:
+				flatOutput = true
+			case display.OutputModeTree:
+				flatOutput = false
+			}
+
+			if groupBy != "" {
+				if err := validateGroupBy(groupBy); err != nil {
 					cmd.SilenceUsage = true
-					return fmt.Errorf("invalid output format %q. Supported formats: json, table, tree", outputFormat)
+					return err
 				}
 			}
 
-			// Get GitHub token
-			token, err := gh.GetToken()
-			if err != nil {
+			if len(fields) > 0 {
+				var sample interface{} = []discover.VersionInfo(nil)
+				if groupBy != "" {
+					sample = []versionGroup(nil)
+				}
+				if err := validateFields(jsonOutput, sample, fields); err != nil {
+					cmd.SilenceUsage = true
+					return err
+				}
+			}
+
+			if err := validateTypeFlags(types, excludeTypes); err != nil {
 				cmd.SilenceUsage = true
 				return err
 			}
 
-			// Create GitHub client
-			client, err := gh.NewClientWithContext(cmd.Context(), token)
+			digestLen, err := parseDigestLength(digestLength)
 			if err != nil {
 				cmd.SilenceUsage = true
-				return fmt.Errorf("failed to create GitHub client: %w", err)
+				return err
 			}
 
-			ctx := cmd.Context()
-
-			// Auto-detect owner type
-			ownerType, err := client.GetOwnerType(ctx, owner)
-			if err != nil {
+			if graphEdges && !jsonOutput {
 				cmd.SilenceUsage = true
-				return fmt.Errorf("failed to determine owner type: %w", err)
+				return fmt.Errorf("--graph-edges requires JSON output (--json or -o json)")
+			}
+			if imageObject && !jsonOutput {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("--image-object requires JSON output (--json or -o json)")
 			}
 
-			// List package versions
-			versions, err := client.ListPackageVersions(ctx, owner, ownerType, packageName)
-			if err != nil {
+			if highlight != "" && (jsonOutput || flatOutput) {
 				cmd.SilenceUsage = true
-				return fmt.Errorf("failed to list package versions: %w", err)
+				return fmt.Errorf("--highlight only applies to tree output")
 			}
 
-			if len(versions) == 0 {
-				fmt.Fprintf(cmd.OutOrStdout(), "No graphs found for %s\n", packageName)
-				return nil
+			if checkCycles && (filterVersion != 0 || filterDigest != "" || filterTag != "" ||
+				olderThan != "" || newerThan != "" || len(types) > 0 || len(excludeTypes) > 0 || len(mediaTypes) > 0 ||
+				indexRefsOnly || platRefsOnly) {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("--check-cycles checks the whole package and cannot be combined with filters")
 			}
 
-			// Collect all tags for cosign discovery
-			var allTags []string
-			for _, v := range versions {
-				allTags = append(allTags, v.Tags...)
+			ctx := cmd.Context()
+
+			// In JSON mode, empty results keep the shape of the normal output
+			var noGraphs interface{} = []discover.VersionInfo{}
+			if sizeTotals {
+				noGraphs = graphsWithTotals{SchemaVersion: display.SchemaVersion, Versions: []discover.VersionInfo{}}
+			}
+			if graphEdges {
+				noGraphs = graphEdgesOutput{SchemaVersion: display.SchemaVersion, Graph: discover.BuildGraph(nil)}
+			}
+			if imageObject {
+				noGraphs = nil
 			}
 
 			// Build OCI reference
 			ociRef := fmt.Sprintf("ghcr.io/%s/%s", owner, packageName)
 
-			// Discover versions and relationships
-			discoverer := discover.NewPackageDiscoverer()
-			results, err := discoverer.DiscoverPackage(ctx, ociRef, versions, allTags)
-			if err != nil {
+			var results []discover.VersionInfo
+			if fromJSON != "" {
+				results, err = loadVersionsDump(fromJSON, cmd.InOrStdin())
+				if err != nil {
+					cmd.SilenceUsage = true
+					return err
+				}
+				if len(results) == 0 {
+					return outputEmptyResult(ctx, cmd.OutOrStdout(), jsonOutput, noGraphs, fmt.Sprintf("No graphs found for %s", packageName))
+				}
+			} else {
+				// Get GitHub token
+				token, err := gh.GetToken()
+				if err != nil {
+					cmd.SilenceUsage = true
+					return err
+				}
+
+				// Create GitHub client
+				client, err := gh.NewClientWithContext(ctx, token)
+				if err != nil {
+					cmd.SilenceUsage = true
+					return fmt.Errorf("failed to create GitHub client: %w", err)
+				}
+
+				// Auto-detect owner type
+				ownerType, err := client.GetOwnerType(ctx, owner)
+				if err != nil {
+					cmd.SilenceUsage = true
+					return fmt.Errorf("failed to determine owner type: %w", err)
+				}
+
+				// List package versions
+				versions, err := client.ListPackageVersions(ctx, owner, ownerType, packageName)
+				if err != nil {
+					cmd.SilenceUsage = true
+					return fmt.Errorf("failed to list package versions: %w", err)
+				}
+
+				if len(versions) == 0 {
+					return outputEmptyResult(ctx, cmd.OutOrStdout(), jsonOutput, noGraphs, fmt.Sprintf("No graphs found for %s", packageName))
+				}
+
+				// Collect all tags for cosign discovery
+				var allTags []string
+				for _, v := range versions {
+					allTags = append(allTags, v.Tags...)
+				}
+
+				// Discover versions and relationships, or only the roots
+				discoverer := discover.NewPackageDiscoverer()
+				discoverer.Strict = strict
+				if onlyRoots {
+					results, err = discoverer.DiscoverRoots(ctx, ociRef, versions)
+				} else {
+					results, err = discoverer.DiscoverPackage(ctx, ociRef, versions, allTags)
+				}
+				if err != nil {
+					cmd.SilenceUsage = true
+					return fmt.Errorf("failed to discover graphs: %w", err)
+				}
+				printDiscoveryWarnings(ctx, cmd.ErrOrStderr(), results)
+			}
+
+			if checkCycles {
 				cmd.SilenceUsage = true
-				return fmt.Errorf("failed to discover graphs: %w", err)
+				return reportCycles(ctx, cmd.OutOrStdout(), discover.FindCycles(results), packageName, len(results), jsonOutput)
 			}
 
 			// Build version map for output
@@ -596,7 +1511,12 @@ Examples:
 
 			// Apply tag filter if specified (resolve tag to digest first)
 			if filterTag != "" {
-				resolvedDigest, err := discover.ResolveTag(ctx, ociRef, filterTag)
+				var resolvedDigest string
+				if fromJSON != "" {
+					resolvedDigest, err = dumpTagDigest(results, filterTag)
+				} else {
+					resolvedDigest, err = discover.ResolveTag(ctx, ociRef, filterTag)
+				}
 				if err != nil {
 					cmd.SilenceUsage = true
 					return fmt.Errorf("failed to resolve tag '%s': %w", filterTag, err)
@@ -635,8 +1555,7 @@ Examples:
 				// Filter to graphs containing this version
 				results = discover.FindGraphsContainingVersion(allVersions, targetDigest)
 				if len(results) == 0 {
-					fmt.Fprintf(cmd.OutOrStdout(), "No graphs found containing the specified version\n")
-					return nil
+					return outputEmptyResult(ctx, cmd.OutOrStdout(), jsonOutput, noGraphs, "No graphs found containing the specified version")
 				}
 
 				// Rebuild version map with filtered results
@@ -651,7 +1570,7 @@ Examples:
 				timeFilter := &filter.VersionFilter{}
 
 				if olderThan != "" {
-					t, err := filter.ParseDateOrDuration(olderThan)
+					t, err := filter.ParseDateOrDurationIn(olderThan, dateLocation(cmd))
 					if err != nil {
 						cmd.SilenceUsage = true
 						return fmt.Errorf("invalid --older-than value: %w", err)
@@ -660,7 +1579,7 @@ Examples:
 				}
 
 				if newerThan != "" {
-					t, err := filter.ParseDateOrDuration(newerThan)
+					t, err := filter.ParseDateOrDurationIn(newerThan, dateLocation(cmd))
 					if err != nil {
 						cmd.SilenceUsage = true
 						return fmt.Errorf("invalid --newer-than value: %w", err)
@@ -671,8 +1590,7 @@ Examples:
 				// Filter results to graphs where ANY version matches the time criteria
 				results = filterGraphsByTime(results, allVersions, timeFilter)
 				if len(results) == 0 {
-					fmt.Fprintf(cmd.OutOrStdout(), "No graphs found matching time criteria\n")
-					return nil
+					return outputEmptyResult(ctx, cmd.OutOrStdout(), jsonOutput, noGraphs, "No graphs found matching time criteria")
 				}
 
 				// Rebuild version map with filtered results
@@ -682,31 +1600,160 @@ Examples:
 				}
 			}
 
+			// Keep the referrers of one scope, judged by the unfiltered graphs
+			if indexRefsOnly || platRefsOnly {
+				scope := discover.ReferrerScopeIndex
+				if platRefsOnly {
+					scope = discover.ReferrerScopePlatform
+				}
+				results = discover.FilterByReferrerScope(results, allVersions, scope)
+
+				// Rebuild version map with filtered results
+				allVersions = make(map[string]discover.VersionInfo)
+				for _, v := range results {
+					allVersions[v.Digest] = v
+				}
+			}
+
+			// Apply type filtering over the discovered roles and media types
+			if len(types) > 0 || len(excludeTypes) > 0 || len(mediaTypes) > 0 {
+				results = discover.FilterByMediaType(discover.FilterByType(results, types, excludeTypes), mediaTypes)
+				if len(results) == 0 {
+					return outputEmptyResult(ctx, cmd.OutOrStdout(), jsonOutput, noGraphs, "No versions found matching type criteria")
+				}
+
+				// Rebuild version map with filtered results
+				allVersions = make(map[string]discover.VersionInfo)
+				for _, v := range results {
+					allVersions[v.Digest] = v
+				}
+			}
+
+			// Count versions per group instead of listing them
+			if groupBy != "" {
+				groups := groupGraphVersions(results, groupBy)
+				if jsonOutput {
+					return display.OutputJSONFields(ctx, cmd.OutOrStdout(), groups, fields)
+				}
+				return outputGroupsTable(cmd.OutOrStdout(), groups, packageName, groupBy, rawBytes, quiet.IsQuiet(ctx))
+			}
+
 			// Output results
 			if jsonOutput {
-				return display.OutputJSON(cmd.OutOrStdout(), results)
+				if graphEdges {
+					return display.OutputJSON(ctx, cmd.OutOrStdout(), graphEdgesOutput{
+						SchemaVersion: display.SchemaVersion,
+						Graph:         discover.BuildGraph(results),
+					})
+				}
+				if imageObject {
+					object, err := singleImageObject(results, allVersions)
+					if err != nil {
+						cmd.SilenceUsage = true
+						return err
+					}
+					return display.OutputJSON(ctx, cmd.OutOrStdout(), imageObjectOutput{
+						SchemaVersion: display.SchemaVersion,
+						ImageObject:   object,
+					})
+				}
+				if sizeTotals {
+					return display.OutputJSON(ctx, cmd.OutOrStdout(), graphsWithTotals{
+						SchemaVersion: display.SchemaVersion,
+						Versions:      results,
+						Totals:        discover.CalculateTotals(results, allVersions),
+					})
+				}
+				return display.OutputJSONFields(ctx, cmd.OutOrStdout(), results, fields)
 			}
 
 			// Default is tree output; --flat switches to table
+			formatOpts := discover.FormatOptions{
+				ShowSizeTotals: sizeTotals,
+				DigestLength:   digestLen,
+				Quiet:          quiet.IsQuiet(ctx),
+				RawBytes:       rawBytes,
+			}
+			if highlight != "" {
+				formatOpts.Highlight, err = discover.FindDigestByShortDigest(allVersions, highlight)
+				if err != nil {
+					cmd.SilenceUsage = true
+					return fmt.Errorf("invalid --highlight: %w", err)
+				}
+			}
 			if flatOutput {
-				discover.FormatTable(cmd.OutOrStdout(), results, allVersions)
+				discover.FormatTableWithOptions(cmd.OutOrStdout(), results, allVersions, formatOpts)
 			} else {
-				discover.FormatTree(cmd.OutOrStdout(), results, allVersions)
+				discover.FormatTreeWithOptions(cmd.OutOrStdout(), results, allVersions, formatOpts)
 			}
 
 			return nil
-		},
+		}),
 	}
 
 	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output in JSON format")
 	cmd.Flags().BoolVar(&flatOutput, "flat", false, "Output in flat table format (default is tree)")
-	cmd.Flags().StringVarP(&outputFormat, "output", "o", "", "Output format (json, table, tree)")
+	addOutputFlag(cmd, &outputFormat, display.OutputModeJSON, display.OutputModeTable, display.OutputModeTree)
 	cmd.Flags().Int64Var(&filterVersion, "version", 0, "Filter to graphs containing this version ID")
 	cmd.Flags().StringVar(&filterDigest, "digest", "", "Filter to graphs containing this digest")
 	cmd.Flags().StringVar(&filterTag, "tag", "", "Filter to graphs containing this tag")
 	cmd.Flags().StringVar(&olderThan, "older-than", "", "Show graphs with ANY version older than date or duration (e.g., 2025-01-01, 7d, 24h)")
 	cmd.Flags().StringVar(&newerThan, "newer-than", "", "Show graphs with ANY version newer than date or duration (e.g., 2025-01-01, 7d, 24h)")
+	addAgeFlags(cmd, &minAge, &maxAge)
+	cmd.Flags().StringSliceVar(&types, "type", nil, "Show only versions of this type (repeatable: index, manifest, platform, sbom, provenance, signature, vex, vuln-scan, attestation)")
+	cmd.Flags().StringSliceVar(&excludeTypes, "exclude-type", nil, "Hide versions of this type (repeatable)")
+	cmd.Flags().StringSliceVar(&mediaTypes, "media-type", nil, "Show only versions with this descriptor media type (repeatable, e.g. application/vnd.oci.image.index.v1+json)")
+	cmd.Flags().BoolVar(&sizeTotals, "show-size-totals", false, "Show the size of each graph and the total size in the summary (with --json, add a totals object)")
+	cmd.Flags().BoolVar(&rawBytes, "bytes", false, "Show sizes as exact byte counts instead of KiB/MiB/GiB")
+	cmd.Flags().BoolVar(&onlyRoots, "only-roots", false, "List only graph roots with their tags and sizes, skipping child discovery")
+	cmd.Flags().StringVar(&digestLength, "digest-length", strconv.Itoa(display.DefaultDigestLength), "Number of digest characters to show in tree and table output (0 or full for complete digests)")
+	cmd.Flags().StringSliceVar(&fields, "fields", nil, "With JSON output, include only these fields of each version (e.g. digest,types,size)")
 	cmd.MarkFlagsMutuallyExclusive("version", "digest", "tag")
+	// The totals object is not an array of versions
+	cmd.MarkFlagsMutuallyExclusive("fields", "show-size-totals")
+	// Roots-only discovery knows neither the children nor the types of non-index roots
+	cmd.MarkFlagsMutuallyExclusive("only-roots", "version")
+	cmd.MarkFlagsMutuallyExclusive("only-roots", "digest")
+	cmd.MarkFlagsMutuallyExclusive("only-roots", "tag")
+	cmd.MarkFlagsMutuallyExclusive("only-roots", "type")
+	cmd.MarkFlagsMutuallyExclusive("only-roots", "exclude-type")
+	cmd.MarkFlagsMutuallyExclusive("only-roots", "show-size-totals")
+	cmd.Flags().BoolVar(&checkCycles, "check-cycles", false, "Report cycles in the references between versions instead of listing graphs")
+	// Roots-only discovery does not follow references, so it cannot find cycles
+	cmd.MarkFlagsMutuallyExclusive("check-cycles", "only-roots")
+	cmd.MarkFlagsMutuallyExclusive("check-cycles", "show-size-totals")
+	cmd.MarkFlagsMutuallyExclusive("check-cycles", "fields")
+	cmd.Flags().StringVar(&highlight, "highlight", "", "Mark the version with this digest (full or short) in the tree")
+	cmd.MarkFlagsMutuallyExclusive("highlight", "check-cycles")
+	cmd.Flags().BoolVar(&indexRefsOnly, "index-referrers-only", false, "Show only the signatures and attestations attached to indexes")
+	cmd.Flags().BoolVar(&platRefsOnly, "platform-referrers-only", false, "Show only the signatures and attestations attached to platform manifests")
+	cmd.MarkFlagsMutuallyExclusive("index-referrers-only", "platform-referrers-only")
+	// Roots-only discovery does not find referrers
+	cmd.MarkFlagsMutuallyExclusive("only-roots", "index-referrers-only")
+	cmd.MarkFlagsMutuallyExclusive("only-roots", "platform-referrers-only")
+	cmd.Flags().BoolVar(&graphEdges, "graph-edges", false, "With JSON output, emit nodes and explicit parent/child edges instead of versions with digest refs")
+	// The graph object has its own shape, and roots-only discovery finds no edges
+	cmd.MarkFlagsMutuallyExclusive("graph-edges", "fields")
+	cmd.MarkFlagsMutuallyExclusive("graph-edges", "show-size-totals")
+	cmd.MarkFlagsMutuallyExclusive("graph-edges", "only-roots")
+	cmd.MarkFlagsMutuallyExclusive("graph-edges", "check-cycles")
+	cmd.Flags().BoolVar(&imageObject, "image-object", false, "With JSON output, emit the one selected graph as a nested object")
+	cmd.MarkFlagsMutuallyExclusive("image-object", "graph-edges")
+	cmd.MarkFlagsMutuallyExclusive("image-object", "fields")
+	cmd.MarkFlagsMutuallyExclusive("image-object", "show-size-totals")
+	cmd.MarkFlagsMutuallyExclusive("image-object", "only-roots")
+	cmd.MarkFlagsMutuallyExclusive("image-object", "check-cycles")
+	cmd.Flags().StringVar(&fromJSON, "from-json", "", "Read the versions from a file saved with --json (- for stdin) instead of the registry")
+	cmd.MarkFlagsMutuallyExclusive("from-json", "only-roots")
+	cmd.Flags().BoolVar(&strict, "strict", false, "Fail if a manifest or index cannot be parsed instead of skipping it")
+	cmd.MarkFlagsMutuallyExclusive("strict", "from-json")
+	cmd.Flags().StringVar(&groupBy, "group-by", "", "Count the versions of the listed graphs per group instead of showing them (tag-prefix, month, type, platform)")
+	// Groups replace the versions, so the other output shapes do not apply
+	cmd.MarkFlagsMutuallyExclusive("group-by", "show-size-totals")
+	cmd.MarkFlagsMutuallyExclusive("group-by", "graph-edges")
+	cmd.MarkFlagsMutuallyExclusive("group-by", "image-object")
+	cmd.MarkFlagsMutuallyExclusive("group-by", "check-cycles")
+	cmd.MarkFlagsMutuallyExclusive("group-by", "highlight")
 
 	return cmd
 }
@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/mkoepf/ghcrctl/internal/display"
+	"github.com/mkoepf/ghcrctl/internal/gh"
+	"github.com/mkoepf/ghcrctl/internal/quiet"
+	"github.com/spf13/cobra"
+)
+
+// listTagsParams contains parameters for list tags execution
+type listTagsParams struct {
+	Owner       string
+	OwnerType   string
+	PackageName string
+	JSONOutput  bool
+	QuietMode   bool
+}
+
+// newListTagsCmd creates the list tags subcommand.
+func newListTagsCmd() *cobra.Command {
+	var (
+		jsonOutput   bool
+		outputFormat string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "tags <owner/package>",
+		Short: "List the tags of a package",
+		Long: `List the tags of a package, sorted by name.
+
+The tags are read from the registry's tag list, which includes every tag of
+the repository. If the registry cannot be reached, the tags of the package
+versions from the GitHub API are listed instead.
+
+Examples:
+  # List the tags of a package
+  ghcrctl list tags mkoepf/myimage
+
+  # List tags in JSON format
+  ghcrctl list tags mkoepf/myimage --json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			owner, packageName, err := parsePackageRef(args[0], defaultOwner(cmd))
+			if err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
+
+			// Handle output format flag (-o)
+			mode, err := display.ParseOutputMode(outputFormat, display.OutputModeJSON, display.OutputModeTable)
+			if err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
+			switch mode {
+			case display.OutputModeJSON:
+				jsonOutput = true
+			case display.OutputModeTable:
+				jsonOutput = false
+			}
+
+			token, err := gh.GetToken()
+			if err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
+
+			ctx := cmd.Context()
+			client, err := gh.NewClientWithContext(ctx, token)
+			if err != nil {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("failed to create GitHub client: %w", err)
+			}
+
+			ownerType, err := client.GetOwnerType(ctx, owner)
+			if err != nil {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("failed to determine owner type: %w", err)
+			}
+
+			cmd.SilenceUsage = true
+			return executeListTags(ctx, orasTagResolver{}, client, listTagsParams{
+				Owner:       owner,
+				OwnerType:   ownerType,
+				PackageName: packageName,
+				JSONOutput:  jsonOutput,
+				QuietMode:   quiet.IsQuiet(ctx),
+			}, cmd.OutOrStdout(), cmd.ErrOrStderr())
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output in JSON format")
+	addOutputFlag(cmd, &outputFormat, display.OutputModeJSON, display.OutputModeTable)
+
+	cmd.ValidArgsFunction = imageRefValidArgsFunc
+
+	return cmd
+}
+
+// executeListTags lists the tags of a package from the registry, falling back
+// to the tags of the package versions if the registry tag list fails
+func executeListTags(ctx context.Context, registry registryTagResolver, lister versionLister, params listTagsParams, out, errOut io.Writer) error {
+	image := fmt.Sprintf("ghcr.io/%s/%s", params.Owner, params.PackageName)
+	tags, err := registry.ListTags(ctx, image)
+	if err != nil {
+		fmt.Fprintf(errOut, "Warning: failed to list tags in the registry, using the package versions instead: %v\n", err)
+		versions, listErr := lister.ListPackageVersions(ctx, params.Owner, params.OwnerType, params.PackageName)
+		if listErr != nil {
+			return fmt.Errorf("failed to list versions: %w", listErr)
+		}
+		tags = nil
+		for _, v := range versions {
+			tags = append(tags, v.Tags...)
+		}
+	}
+	tags = sortedUniqueTags(tags)
+
+	if params.JSONOutput {
+		return display.OutputJSON(ctx, out, tags)
+	}
+	return outputListTagsTable(out, tags, params.PackageName, params.QuietMode)
+}
+
+// sortedUniqueTags sorts tags by name and removes duplicates. The result is
+// never nil, so that it marshals to an empty JSON array.
+func sortedUniqueTags(tags []string) []string {
+	seen := make(map[string]bool, len(tags))
+	unique := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if !seen[tag] {
+			seen[tag] = true
+			unique = append(unique, tag)
+		}
+	}
+	sort.Strings(unique)
+	return unique
+}
+
+func outputListTagsTable(w io.Writer, tags []string, packageName string, quietMode bool) error {
+	if len(tags) == 0 {
+		if !quietMode {
+			fmt.Fprintf(w, "No tags found for %s\n", packageName)
+		}
+		return nil
+	}
+
+	if !quietMode {
+		fmt.Fprintf(w, "Tags for %s:\n\n", packageName)
+	}
+	for _, tag := range tags {
+		fmt.Fprintf(w, "  %s\n", tag)
+	}
+	if !quietMode {
+		fmt.Fprintf(w, "\nTotal: %s tag(s)\n", display.ColorCount(len(tags)))
+	}
+
+	return nil
+}
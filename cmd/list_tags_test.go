@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/mkoepf/ghcrctl/internal/gh"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecuteListTags_Registry(t *testing.T) {
+	t.Parallel()
+	registry := mockRegistries{"ghcr.io/owner/pkg": {"v1.1.0": "sha256:b", "latest": "sha256:b", "v1.0.0": "sha256:a"}}
+	lister := &mockVersionLister{err: fmt.Errorf("not called")}
+
+	var out, errOut bytes.Buffer
+	err := executeListTags(context.Background(), registry, lister, listTagsParams{Owner: "owner", PackageName: "pkg"}, &out, &errOut)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Tags for pkg:\n\n  latest\n  v1.0.0\n  v1.1.0\n\nTotal: 3 tag(s)\n", out.String())
+	assert.Empty(t, errOut.String())
+}
+
+func TestExecuteListTags_FallsBackToVersions(t *testing.T) {
+	t.Parallel()
+	lister := &mockVersionLister{versions: []gh.PackageVersionInfo{
+		{ID: 3, Tags: []string{"v2", "latest"}},
+		{ID: 2},
+		{ID: 1, Tags: []string{"v1"}},
+	}}
+
+	var out, errOut bytes.Buffer
+	err := executeListTags(context.Background(), mockRegistries{}, lister, listTagsParams{
+		Owner: "owner", PackageName: "pkg", JSONOutput: true,
+	}, &out, &errOut)
+	require.NoError(t, err)
+
+	var tags []string
+	require.NoError(t, json.Unmarshal(out.Bytes(), &tags))
+	assert.Equal(t, []string{"latest", "v1", "v2"}, tags)
+	assert.Contains(t, errOut.String(), "using the package versions instead")
+}
+
+func TestExecuteListTags_Empty(t *testing.T) {
+	t.Parallel()
+	registry := mockRegistries{"ghcr.io/owner/pkg": {}}
+
+	var out bytes.Buffer
+	err := executeListTags(context.Background(), registry, &mockVersionLister{}, listTagsParams{
+		Owner: "owner", PackageName: "pkg", JSONOutput: true,
+	}, &out, &bytes.Buffer{})
+	require.NoError(t, err)
+	assert.JSONEq(t, "[]", out.String())
+
+	out.Reset()
+	err = executeListTags(context.Background(), registry, &mockVersionLister{}, listTagsParams{Owner: "owner", PackageName: "pkg"}, &out, &bytes.Buffer{})
+	require.NoError(t, err)
+	assert.Equal(t, "No tags found for pkg\n", out.String())
+}
+
+func TestExecuteListTags_BothFail(t *testing.T) {
+	t.Parallel()
+	lister := &mockVersionLister{err: fmt.Errorf("not found")}
+
+	err := executeListTags(context.Background(), mockRegistries{}, lister, listTagsParams{Owner: "owner", PackageName: "pkg"}, &bytes.Buffer{}, &bytes.Buffer{})
+	assert.EqualError(t, err, "failed to list versions: not found")
+}
@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/mkoepf/ghcrctl/internal/discover"
+	"github.com/mkoepf/ghcrctl/internal/display"
+	"github.com/mkoepf/ghcrctl/internal/gh"
+	"github.com/mkoepf/ghcrctl/internal/quiet"
+	"github.com/spf13/cobra"
+)
+
+// manifestFetchFunc fetches the raw manifest for a digest along with its media type.
+// discover.FetchManifest satisfies this signature.
+type manifestFetchFunc func(ctx context.Context, image, digest string) ([]byte, string, error)
+
+// getManifestParams contains parameters for printing a raw manifest
+type getManifestParams struct {
+	Image    string // e.g. ghcr.io/owner/package
+	Digest   string // Full digest of the selected version
+	Platform string // Optional os/arch[/variant] to select from an index
+}
+
+// newGetManifestCmd creates the get manifest subcommand.
+func newGetManifestCmd() *cobra.Command {
+	var (
+		tag       string
+		digest    string
+		versionID int64
+		platform  string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "manifest <owner/package>",
+		Short: "Get the raw manifest or index JSON",
+		Long: `Get the raw manifest or image index of a version exactly as the registry returns it.
+
+The manifest bytes are written to stdout unmodified, so piping them into
+sha256sum reproduces the digest. Use the global --pretty flag to indent the
+JSON instead. The media type and digest are written to stderr.
+
+Use --platform to select the manifest of one platform from an image index.
+
+Requires a selector: --tag, --digest, or --version.
+
+Examples:
+  # Get the index of a multi-arch image
+  ghcrctl get manifest mkoepf/myimage --tag v1.0.0
+
+  # Get the manifest of one platform, indented
+  ghcrctl get manifest mkoepf/myimage --tag v1.0.0 --platform linux/arm64 --pretty
+
+  # Get a manifest by digest (short form supported)
+  ghcrctl get manifest mkoepf/myimage --digest abc123
+
+  # Verify the digest of the raw bytes
+  ghcrctl get manifest mkoepf/myimage --tag v1.0.0 | sha256sum`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			// Parse owner/package reference (reject inline tags)
+			owner, packageName, err := parsePackageRef(args[0], defaultOwner(cmd))
+			if err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
+
+			// Require at least one selector
+			if tag == "" && digest == "" && versionID == 0 {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("selector required: use --tag, --digest, or --version to specify which version")
+			}
+
+			fullImage := fmt.Sprintf("ghcr.io/%s/%s", owner, packageName)
+			ctx := cmd.Context()
+
+			// Resolve the selector to a full digest
+			var targetDigest string
+
+			if tag != "" {
+				targetDigest, err = discover.ResolveTag(ctx, fullImage, tag)
+				if err != nil {
+					cmd.SilenceUsage = true
+					return fmt.Errorf("failed to resolve tag '%s': %w", tag, err)
+				}
+			} else {
+				// Version IDs and short digests are resolved against the package versions
+				token, err := gh.GetToken()
+				if err != nil {
+					cmd.SilenceUsage = true
+					return err
+				}
+
+				ghClient, err := gh.NewClientWithContext(ctx, token)
+				if err != nil {
+					cmd.SilenceUsage = true
+					return fmt.Errorf("failed to create GitHub client: %w", err)
+				}
+
+				ownerType, err := ghClient.GetOwnerType(ctx, owner)
+				if err != nil {
+					cmd.SilenceUsage = true
+					return fmt.Errorf("failed to determine owner type: %w", err)
+				}
+
+				allVersions, err := ghClient.ListPackageVersions(ctx, owner, ownerType, packageName)
+				if err != nil {
+					cmd.SilenceUsage = true
+					return fmt.Errorf("failed to list package versions: %w", err)
+				}
+
+				if versionID != 0 {
+					for _, ver := range allVersions {
+						if ver.ID == versionID {
+							targetDigest = ver.Digest
+							break
+						}
+					}
+					if targetDigest == "" {
+						cmd.SilenceUsage = true
+						return fmt.Errorf("version ID %d not found", versionID)
+					}
+				} else {
+					targetDigest, err = resolveDigestPrefix(allVersions, digest, true, cmd.ErrOrStderr())
+					if err != nil {
+						cmd.SilenceUsage = true
+						return err
+					}
+				}
+			}
+
+			cmd.SilenceUsage = true
+			return executeGetManifest(ctx, discover.FetchManifest, getManifestParams{
+				Image:    fullImage,
+				Digest:   targetDigest,
+				Platform: platform,
+			}, cmd.OutOrStdout(), cmd.ErrOrStderr())
+		},
+	}
+
+	cmd.Flags().StringVar(&tag, "tag", "", "Select version by tag")
+	cmd.Flags().StringVar(&digest, "digest", "", "Select version by digest (supports short form)")
+	cmd.Flags().Int64Var(&versionID, "version", 0, "Select version by ID")
+	cmd.Flags().StringVar(&platform, "platform", "", "Select a platform manifest from an index (e.g., linux/amd64, linux/arm64/v8)")
+	cmd.MarkFlagsMutuallyExclusive("tag", "digest", "version")
+
+	cmd.ValidArgsFunction = imageRefValidArgsFunc
+
+	return cmd
+}
+
+// executeGetManifest fetches a manifest and writes it to w, with its media type
+// and digest on errW. With a platform, the manifest of that platform is selected
+// from the index first. The bytes are only reformatted if pretty JSON was
+// requested explicitly; the terminal auto-detection of OutputJSON does not apply.
+func executeGetManifest(ctx context.Context, fetch manifestFetchFunc, params getManifestParams, w, errW io.Writer) error {
+	data, mediaType, err := fetch(ctx, params.Image, params.Digest)
+	if err != nil {
+		return fmt.Errorf("failed to fetch manifest: %w", err)
+	}
+	digest := params.Digest
+
+	if params.Platform != "" {
+		if !discover.IsIndexMediaType(mediaType) {
+			return fmt.Errorf("--platform requires an image index, but %s is %s", digest, mediaType)
+		}
+		digest, err = discover.SelectPlatformManifest(data, params.Platform)
+		if err != nil {
+			return err
+		}
+		data, mediaType, err = fetch(ctx, params.Image, digest)
+		if err != nil {
+			return fmt.Errorf("failed to fetch manifest for platform %s: %w", params.Platform, err)
+		}
+	}
+
+	if !quiet.IsQuiet(ctx) {
+		fmt.Fprintf(errW, "Media type: %s\n", mediaType)
+		fmt.Fprintf(errW, "Digest:     %s\n", digest)
+	}
+
+	if display.JSONStyleFromContext(ctx) == display.JSONStylePretty {
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, data, "", "  "); err != nil {
+			return fmt.Errorf("failed to format manifest: %w", err)
+		}
+		buf.WriteByte('\n')
+		data = buf.Bytes()
+	}
+
+	_, err = w.Write(data)
+	return err
+}
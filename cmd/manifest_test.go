@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/mkoepf/ghcrctl/internal/display"
+	"github.com/mkoepf/ghcrctl/internal/quiet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testIndexDigest = "sha256:aaaa000000000000000000000000000000000000000000000000000000000000"
+	testArm64Digest = "sha256:bbbb000000000000000000000000000000000000000000000000000000000000"
+)
+
+// Unusual spacing and key order must survive, since the digest covers the exact bytes
+var (
+	testIndexBytes = []byte(`{"schemaVersion":2,"mediaType":"application/vnd.oci.image.index.v1+json","manifests":[` +
+		`{"mediaType":"application/vnd.oci.image.manifest.v1+json","digest":"` + testArm64Digest + `","size":1024,` +
+		`"platform":{"architecture":"arm64","os":"linux"}}]}`)
+	testArm64Bytes = []byte(`{"schemaVersion":2,  "mediaType":"application/vnd.oci.image.manifest.v1+json","layers":[]}`)
+)
+
+// fakeManifestFetcher serves fixed manifests by digest and records the fetched digests
+type fakeManifestFetcher struct {
+	fetched []string
+}
+
+func (f *fakeManifestFetcher) fetch(ctx context.Context, image, digest string) ([]byte, string, error) {
+	f.fetched = append(f.fetched, digest)
+	switch digest {
+	case testIndexDigest:
+		return testIndexBytes, "application/vnd.oci.image.index.v1+json", nil
+	case testArm64Digest:
+		return testArm64Bytes, "application/vnd.oci.image.manifest.v1+json", nil
+	}
+	return nil, "", fmt.Errorf("manifest unknown")
+}
+
+func TestExecuteGetManifest_RawBytesUnmodified(t *testing.T) {
+	t.Parallel()
+	fetcher := &fakeManifestFetcher{}
+
+	var out, errOut bytes.Buffer
+	err := executeGetManifest(context.Background(), fetcher.fetch, getManifestParams{
+		Image:  "ghcr.io/mkoepf/myimage",
+		Digest: testArm64Digest,
+	}, &out, &errOut)
+	require.NoError(t, err)
+
+	assert.Equal(t, testArm64Bytes, out.Bytes())
+	assert.Contains(t, errOut.String(), "Media type: application/vnd.oci.image.manifest.v1+json")
+	assert.Contains(t, errOut.String(), "Digest:     "+testArm64Digest)
+}
+
+func TestExecuteGetManifest_Pretty(t *testing.T) {
+	t.Parallel()
+	fetcher := &fakeManifestFetcher{}
+
+	var out bytes.Buffer
+	ctx := display.WithJSONStyle(context.Background(), display.JSONStylePretty)
+	err := executeGetManifest(ctx, fetcher.fetch, getManifestParams{
+		Image:  "ghcr.io/mkoepf/myimage",
+		Digest: testArm64Digest,
+	}, &out, new(bytes.Buffer))
+	require.NoError(t, err)
+
+	assert.Equal(t, "{\n  \"schemaVersion\": 2,\n  \"mediaType\": \"application/vnd.oci.image.manifest.v1+json\",\n  \"layers\": []\n}\n", out.String())
+}
+
+func TestExecuteGetManifest_Platform(t *testing.T) {
+	t.Parallel()
+	fetcher := &fakeManifestFetcher{}
+
+	var out, errOut bytes.Buffer
+	err := executeGetManifest(context.Background(), fetcher.fetch, getManifestParams{
+		Image:    "ghcr.io/mkoepf/myimage",
+		Digest:   testIndexDigest,
+		Platform: "linux/arm64",
+	}, &out, &errOut)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{testIndexDigest, testArm64Digest}, fetcher.fetched)
+	assert.Equal(t, testArm64Bytes, out.Bytes())
+	assert.Contains(t, errOut.String(), "Digest:     "+testArm64Digest)
+}
+
+func TestExecuteGetManifest_PlatformErrors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("not an index", func(t *testing.T) {
+		t.Parallel()
+		fetcher := &fakeManifestFetcher{}
+		err := executeGetManifest(context.Background(), fetcher.fetch, getManifestParams{
+			Image:    "ghcr.io/mkoepf/myimage",
+			Digest:   testArm64Digest,
+			Platform: "linux/arm64",
+		}, new(bytes.Buffer), new(bytes.Buffer))
+		assert.ErrorContains(t, err, "--platform requires an image index")
+	})
+
+	t.Run("unknown platform", func(t *testing.T) {
+		t.Parallel()
+		fetcher := &fakeManifestFetcher{}
+		var out bytes.Buffer
+		err := executeGetManifest(context.Background(), fetcher.fetch, getManifestParams{
+			Image:    "ghcr.io/mkoepf/myimage",
+			Digest:   testIndexDigest,
+			Platform: "linux/s390x",
+		}, &out, new(bytes.Buffer))
+		assert.ErrorContains(t, err, "platform linux/s390x not found in image index (available: linux/arm64)")
+		assert.Empty(t, out.String())
+	})
+}
+
+func TestExecuteGetManifest_Quiet(t *testing.T) {
+	t.Parallel()
+	fetcher := &fakeManifestFetcher{}
+
+	var out, errOut bytes.Buffer
+	err := executeGetManifest(quiet.EnableQuiet(context.Background()), fetcher.fetch, getManifestParams{
+		Image:  "ghcr.io/mkoepf/myimage",
+		Digest: testIndexDigest,
+	}, &out, &errOut)
+	require.NoError(t, err)
+	assert.Equal(t, testIndexBytes, out.Bytes())
+	assert.Empty(t, errOut.String())
+}
+
+func TestGetManifestCmd_RequiresSelector(t *testing.T) {
+	t.Parallel()
+	cmd := NewRootCmd()
+	cmd.SetArgs([]string{"get", "manifest", "mkoepf/myimage"})
+	cmd.SetOut(new(bytes.Buffer))
+	cmd.SetErr(new(bytes.Buffer))
+
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "selector required")
+}
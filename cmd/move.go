@@ -0,0 +1,240 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/mkoepf/ghcrctl/internal/discover"
+	"github.com/mkoepf/ghcrctl/internal/display"
+	"github.com/mkoepf/ghcrctl/internal/gh"
+	"github.com/mkoepf/ghcrctl/internal/prompts"
+	"github.com/spf13/cobra"
+)
+
+// newMoveCmd creates the move command.
+func newMoveCmd() *cobra.Command {
+	var (
+		force  bool
+		yes    bool
+		dryRun bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "move <owner/source> <owner/destination>",
+		Short: "Move a package by copying its tags and deleting the source",
+		Long: `Move a package to a new name or owner.
+
+GHCR cannot rename packages. This command copies every tag of the source
+package to the destination package, including everything the tagged artifacts
+reference (platform manifests, attestations in an index, layers). It then
+verifies that each tag resolves to the same digest in the destination, and
+only then deletes the source package.
+
+Untagged versions that no tagged version references are not copied.
+Nothing is deleted if a copy or the verification fails.
+
+Examples:
+  # Preview the move
+  ghcrctl move mkoepf/oldname mkoepf/newname --dry-run
+
+  # Move a package to another owner
+  ghcrctl move mkoepf/myimage myorg/myimage
+
+  # Move without confirmation
+  ghcrctl move mkoepf/oldname mkoepf/newname --force`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			srcOwner, srcPackage, err := parsePackageRef(args[0], defaultOwner(cmd))
+			if err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
+			dstOwner, dstPackage, err := parsePackageRef(args[1], defaultOwner(cmd))
+			if err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
+			if srcOwner == dstOwner && srcPackage == dstPackage {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("source and destination must be different packages")
+			}
+
+			token, err := gh.GetToken()
+			if err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
+
+			ctx := cmd.Context()
+
+			client, err := gh.NewClientWithContext(ctx, token)
+			if err != nil {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("failed to create GitHub client: %w", err)
+			}
+
+			ownerType, err := client.GetOwnerType(ctx, srcOwner)
+			if err != nil {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("failed to determine owner type: %w", err)
+			}
+
+			versions, err := client.ListPackageVersions(ctx, srcOwner, ownerType, srcPackage)
+			if err != nil {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("failed to list package versions: %w", err)
+			}
+
+			cmd.SilenceUsage = true
+			return executeMove(ctx, orasTagCopier{}, client, moveParams{
+				SrcOwner:   srcOwner,
+				SrcPackage: srcPackage,
+				DstOwner:   dstOwner,
+				DstPackage: dstPackage,
+				OwnerType:  ownerType,
+				Versions:   versions,
+				DryRun:     dryRun,
+				Force:      force || yes,
+			}, cmd.OutOrStdout(), func() (bool, error) {
+				return prompts.ConfirmWithInput(os.Stdin, cmd.OutOrStdout(),
+					"To confirm, type the source package name", srcPackage)
+			})
+		},
+	}
+
+	cmd.Flags().BoolVar(&force, "force", false, "Skip confirmation prompt")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Skip confirmation prompt (alias for --force)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be copied and deleted without making changes")
+
+	cmd.ValidArgsFunction = imageRefValidArgsFunc
+
+	return cmd
+}
+
+// tagCopier copies tags between images and resolves them for verification
+type tagCopier interface {
+	CopyTag(ctx context.Context, srcImage, tag, dstImage string) (string, error)
+	ResolveTag(ctx context.Context, image, tag string) (string, error)
+}
+
+// orasTagCopier copies tags using the discover package
+type orasTagCopier struct{}
+
+func (orasTagCopier) CopyTag(ctx context.Context, srcImage, tag, dstImage string) (string, error) {
+	return discover.CopyTag(ctx, srcImage, tag, dstImage)
+}
+
+func (orasTagCopier) ResolveTag(ctx context.Context, image, tag string) (string, error) {
+	return discover.ResolveTag(ctx, image, tag)
+}
+
+// moveParams contains parameters for moving a package
+type moveParams struct {
+	SrcOwner   string
+	SrcPackage string
+	DstOwner   string
+	DstPackage string
+	OwnerType  string // Owner type of the source package
+	Versions   []gh.PackageVersionInfo
+	DryRun     bool
+	Force      bool
+}
+
+// moveTag is a tag of the source package and the digest it points to
+type moveTag struct {
+	Tag    string
+	Digest string
+}
+
+// planMoveTags returns the tags of versions sorted by name, and the number of
+// untagged versions
+func planMoveTags(versions []gh.PackageVersionInfo) ([]moveTag, int) {
+	var tags []moveTag
+	untagged := 0
+	for _, v := range versions {
+		if len(v.Tags) == 0 {
+			untagged++
+		}
+		for _, tag := range v.Tags {
+			tags = append(tags, moveTag{Tag: tag, Digest: v.Digest})
+		}
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Tag < tags[j].Tag })
+	return tags, untagged
+}
+
+// executeMove copies every tag of the source package to the destination,
+// verifies the copies, and deletes the source package. The source is only
+// deleted after all tags were copied and verified.
+func executeMove(ctx context.Context, copier tagCopier, remover packageRemover, params moveParams, w io.Writer, confirmFn func() (bool, error)) error {
+	src := fmt.Sprintf("%s/%s", params.SrcOwner, params.SrcPackage)
+	dst := fmt.Sprintf("%s/%s", params.DstOwner, params.DstPackage)
+	srcImage := "ghcr.io/" + src
+	dstImage := "ghcr.io/" + dst
+
+	tags, untagged := planMoveTags(params.Versions)
+	if len(tags) == 0 {
+		return fmt.Errorf("package %s has no tags to move", src)
+	}
+
+	fmt.Fprintf(w, "Preparing to move package:\n")
+	fmt.Fprintf(w, "  From: %s\n", src)
+	fmt.Fprintf(w, "  To:   %s\n", dst)
+	fmt.Fprintf(w, "  Tags: %d\n", len(tags))
+	for _, t := range tags {
+		fmt.Fprintf(w, "    - %s (%s)\n", t.Tag, display.ShortDigest(t.Digest))
+	}
+	if untagged > 0 {
+		fmt.Fprintf(w, "\n%s\n", display.ColorWarning(fmt.Sprintf(
+			"%d untagged version(s) are only copied if a tagged version references them.", untagged)))
+	}
+	fmt.Fprintf(w, "\n%s\n\n", display.ColorError(fmt.Sprintf(
+		"WARNING: The source package %s and ALL its versions will be deleted after copying!", src)))
+
+	if params.DryRun {
+		fmt.Fprintln(w, display.ColorDryRun("DRY RUN: No changes made"))
+		return nil
+	}
+
+	if !params.Force {
+		confirmed, err := confirmFn()
+		if err != nil {
+			return fmt.Errorf("failed to read confirmation: %w", err)
+		}
+		if !confirmed {
+			fmt.Fprintln(w, "Move cancelled (input did not match package name)")
+			return nil
+		}
+	}
+
+	// Copy all tags before anything is deleted
+	for i, t := range tags {
+		fmt.Fprintf(w, "Copying tag %d/%d (%s)...\n", i+1, len(tags), t.Tag)
+		if _, err := copier.CopyTag(ctx, srcImage, t.Tag, dstImage); err != nil {
+			return fmt.Errorf("%w; source package was not deleted", err)
+		}
+	}
+
+	// Verify every tag points to the same digest in the destination
+	for _, t := range tags {
+		digest, err := copier.ResolveTag(ctx, dstImage, t.Tag)
+		if err != nil {
+			return fmt.Errorf("failed to verify tag '%s' in %s: %w; source package was not deleted", t.Tag, dst, err)
+		}
+		if digest != t.Digest {
+			return fmt.Errorf("tag '%s' in %s points to %s instead of %s; source package was not deleted",
+				t.Tag, dst, display.ShortDigest(digest), display.ShortDigest(t.Digest))
+		}
+	}
+	fmt.Fprintf(w, "Verified %d tag(s) in %s\n", len(tags), dst)
+
+	if err := remover.DeletePackage(ctx, params.SrcOwner, params.OwnerType, params.SrcPackage); err != nil {
+		return fmt.Errorf("copied all tags to %s, but failed to delete source package: %w", dst, err)
+	}
+
+	fmt.Fprintln(w, display.ColorSuccess(fmt.Sprintf("Successfully moved %s to %s", src, dst)))
+	return nil
+}
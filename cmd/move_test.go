@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/mkoepf/ghcrctl/internal/gh"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// moveRecorder copies tags into an in-memory destination and records every
+// copy and delete in order, so tests can check that nothing is deleted early
+type moveRecorder struct {
+	ops       []string
+	copied    map[string]string // dest tag -> digest
+	digests   map[string]string // source tag -> digest
+	copyErr   map[string]error
+	wrongCopy map[string]string // dest tag -> digest the copy ends up with
+}
+
+func newMoveRecorder(versions []gh.PackageVersionInfo) *moveRecorder {
+	r := &moveRecorder{copied: map[string]string{}, digests: map[string]string{}}
+	for _, v := range versions {
+		for _, tag := range v.Tags {
+			r.digests[tag] = v.Digest
+		}
+	}
+	return r
+}
+
+func (r *moveRecorder) CopyTag(ctx context.Context, srcImage, tag, dstImage string) (string, error) {
+	r.ops = append(r.ops, fmt.Sprintf("copy %s %s -> %s", srcImage, tag, dstImage))
+	if err := r.copyErr[tag]; err != nil {
+		return "", err
+	}
+	digest := r.digests[tag]
+	if wrong, ok := r.wrongCopy[tag]; ok {
+		digest = wrong
+	}
+	r.copied[tag] = digest
+	return digest, nil
+}
+
+func (r *moveRecorder) ResolveTag(ctx context.Context, image, tag string) (string, error) {
+	digest, ok := r.copied[tag]
+	if !ok {
+		return "", fmt.Errorf("tag %s not found", tag)
+	}
+	return digest, nil
+}
+
+func (r *moveRecorder) DeletePackage(ctx context.Context, owner, ownerType, packageName string) error {
+	r.ops = append(r.ops, fmt.Sprintf("delete %s/%s", owner, packageName))
+	return nil
+}
+
+func moveTestVersions() []gh.PackageVersionInfo {
+	return []gh.PackageVersionInfo{
+		{ID: 1, Digest: "sha256:aaa111", Tags: []string{"v1.0.0", "latest"}},
+		{ID: 2, Digest: "sha256:bbb222", Tags: []string{"v0.9.0"}},
+		{ID: 3, Digest: "sha256:ccc333"},
+		{ID: 4, Digest: "sha256:ddd444", Tags: []string{"sha256-aaa111.sig"}},
+	}
+}
+
+func moveTestParams() moveParams {
+	return moveParams{
+		SrcOwner:   "mkoepf",
+		SrcPackage: "oldname",
+		DstOwner:   "mkoepf",
+		DstPackage: "newname",
+		OwnerType:  "user",
+		Versions:   moveTestVersions(),
+		Force:      true,
+	}
+}
+
+func TestExecuteMove_CopiesAllTagsBeforeDeleting(t *testing.T) {
+	t.Parallel()
+	recorder := newMoveRecorder(moveTestVersions())
+
+	var out bytes.Buffer
+	err := executeMove(context.Background(), recorder, recorder, moveTestParams(), &out, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"copy ghcr.io/mkoepf/oldname latest -> ghcr.io/mkoepf/newname",
+		"copy ghcr.io/mkoepf/oldname sha256-aaa111.sig -> ghcr.io/mkoepf/newname",
+		"copy ghcr.io/mkoepf/oldname v0.9.0 -> ghcr.io/mkoepf/newname",
+		"copy ghcr.io/mkoepf/oldname v1.0.0 -> ghcr.io/mkoepf/newname",
+		"delete mkoepf/oldname",
+	}, recorder.ops)
+	assert.Contains(t, out.String(), "1 untagged version(s) are only copied if a tagged version references them")
+	assert.Contains(t, out.String(), "Verified 4 tag(s) in mkoepf/newname")
+	assert.Contains(t, out.String(), "Successfully moved mkoepf/oldname to mkoepf/newname")
+}
+
+func TestExecuteMove_NothingDeletedOnFailure(t *testing.T) {
+	t.Parallel()
+
+	t.Run("copy fails", func(t *testing.T) {
+		t.Parallel()
+		recorder := newMoveRecorder(moveTestVersions())
+		recorder.copyErr = map[string]error{"v0.9.0": fmt.Errorf("denied")}
+
+		err := executeMove(context.Background(), recorder, recorder, moveTestParams(), new(bytes.Buffer), nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "denied; source package was not deleted")
+		assert.NotContains(t, recorder.ops, "delete mkoepf/oldname")
+	})
+
+	t.Run("verification fails", func(t *testing.T) {
+		t.Parallel()
+		recorder := newMoveRecorder(moveTestVersions())
+		recorder.wrongCopy = map[string]string{"latest": "sha256:fff999"}
+
+		err := executeMove(context.Background(), recorder, recorder, moveTestParams(), new(bytes.Buffer), nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "tag 'latest' in mkoepf/newname points to fff999 instead of aaa111")
+		assert.NotContains(t, recorder.ops, "delete mkoepf/oldname")
+	})
+}
+
+func TestExecuteMove_DryRunAndCancel(t *testing.T) {
+	t.Parallel()
+
+	t.Run("dry run", func(t *testing.T) {
+		t.Parallel()
+		recorder := newMoveRecorder(moveTestVersions())
+		params := moveTestParams()
+		params.DryRun = true
+
+		var out bytes.Buffer
+		require.NoError(t, executeMove(context.Background(), recorder, recorder, params, &out, nil))
+		assert.Empty(t, recorder.ops)
+		assert.Contains(t, out.String(), "v1.0.0 (aaa111)")
+		assert.Contains(t, out.String(), "DRY RUN: No changes made")
+	})
+
+	t.Run("confirmation declined", func(t *testing.T) {
+		t.Parallel()
+		recorder := newMoveRecorder(moveTestVersions())
+		params := moveTestParams()
+		params.Force = false
+
+		var out bytes.Buffer
+		require.NoError(t, executeMove(context.Background(), recorder, recorder, params, &out, func() (bool, error) {
+			return false, nil
+		}))
+		assert.Empty(t, recorder.ops)
+		assert.Contains(t, out.String(), "Move cancelled")
+	})
+}
+
+func TestExecuteMove_NoTags(t *testing.T) {
+	t.Parallel()
+	recorder := newMoveRecorder(nil)
+	params := moveTestParams()
+	params.Versions = []gh.PackageVersionInfo{{ID: 3, Digest: "sha256:ccc333"}}
+
+	err := executeMove(context.Background(), recorder, recorder, params, new(bytes.Buffer), nil)
+	assert.EqualError(t, err, "package mkoepf/oldname has no tags to move")
+	assert.Empty(t, recorder.ops)
+}
+
+func TestMoveCmd_RejectsSamePackage(t *testing.T) {
+	t.Parallel()
+	cmd := NewRootCmd()
+	cmd.SetArgs([]string{"move", "mkoepf/myimage", "mkoepf/myimage"})
+	cmd.SetOut(new(bytes.Buffer))
+	cmd.SetErr(new(bytes.Buffer))
+
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "source and destination must be different packages")
+}
@@ -1,8 +1,11 @@
 package cmd
 
 import (
+	"bytes"
+	"strings"
 	"testing"
 
+	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -123,3 +126,68 @@ func TestOutputFlagDescription(t *testing.T) {
 		})
 	}
 }
+
+// TestOutputFormatValidation runs each command with -o and checks the allowed set
+func TestOutputFormatValidation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		args    []string
+		format  string
+		wantErr string
+	}{
+		{[]string{"list", "packages", "owner"}, "yaml", `invalid output format "yaml". Supported formats: json, table`},
+		{[]string{"list", "packages", "owner"}, "tree", `invalid output format "tree". Supported formats: json, table`},
+		{[]string{"list", "versions", "owner/pkg"}, "tree", `invalid output format "tree". Supported formats: json, table`},
+		{[]string{"list", "graphs", "owner/pkg"}, "csv", `invalid output format "csv". Supported formats: json, table, tree`},
+		{[]string{"get", "labels", "owner/pkg", "--tag", "v1"}, "yaml", `invalid output format "yaml". Supported formats: json, table`},
+		{[]string{"get", "sbom", "owner/pkg", "--tag", "v1"}, "tree", `invalid output format "tree". Supported formats: json, table`},
+		{[]string{"get", "provenance", "owner/pkg", "--tag", "v1"}, "yaml", `invalid output format "yaml". Supported formats: json, table`},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args[:2], " ")+" -o "+tt.format, func(t *testing.T) {
+			t.Parallel()
+			cmd := NewRootCmd()
+			cmd.SetArgs(append(tt.args, "-o", tt.format))
+			cmd.SetOut(new(bytes.Buffer))
+			cmd.SetErr(new(bytes.Buffer))
+
+			err := cmd.Execute()
+			require.Error(t, err)
+			assert.Equal(t, tt.wantErr, err.Error())
+		})
+	}
+}
+
+// TestOutputFormatCompletion checks that -o completes each command's allowed formats
+func TestOutputFormatCompletion(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		command string
+		want    string
+	}{
+		{"list packages", "json\ntable\n"},
+		{"list versions", "json\ntable\n"},
+		{"list graphs", "json\ntable\ntree\n"},
+		{"get labels", "json\ntable\n"},
+		{"get sbom", "json\ntable\n"},
+		{"get provenance", "json\ntable\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			t.Parallel()
+			cmd := NewRootCmd()
+			out := new(bytes.Buffer)
+			args := append([]string{cobra.ShellCompNoDescRequestCmd}, strings.Split(tt.command, " ")...)
+			cmd.SetArgs(append(args, "owner/pkg", "-o", ""))
+			cmd.SetOut(out)
+			cmd.SetErr(new(bytes.Buffer))
+
+			require.NoError(t, cmd.Execute())
+			assert.True(t, strings.HasPrefix(out.String(), tt.want), "got completions %q", out.String())
+		})
+	}
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"testing"
 
@@ -18,13 +19,13 @@ func TestListPackagesCommandStructure(t *testing.T) {
 	require.NoError(t, err, "Failed to find list packages command")
 	require.NotNil(t, packagesCmd, "packagesCmd should not be nil")
 
-	assert.Equal(t, "packages <owner>", packagesCmd.Use)
+	assert.Equal(t, "packages [owner]", packagesCmd.Use)
 	assert.NotNil(t, packagesCmd.RunE, "packagesCmd should have RunE function")
 }
 
 func TestListPackagesCommandArguments(t *testing.T) {
 	t.Parallel()
-	// Test that list packages command requires exactly one argument (owner)
+	// Test that list packages command takes the owner as argument or from --owner
 
 	tests := []struct {
 		name        string
@@ -36,7 +37,7 @@ func TestListPackagesCommandArguments(t *testing.T) {
 			name:        "no arguments",
 			args:        []string{"list", "packages"},
 			wantError:   true,
-			errContains: "accepts 1 arg",
+			errContains: "owner required",
 		},
 		{
 			name:        "with owner argument",
@@ -44,11 +45,17 @@ func TestListPackagesCommandArguments(t *testing.T) {
 			wantError:   false, // Will fail for other reasons (no token), but not arg validation
 			errContains: "",
 		},
+		{
+			name:        "with --owner instead of argument",
+			args:        []string{"list", "packages", "--owner", "mkoepf"},
+			wantError:   false,
+			errContains: "",
+		},
 		{
 			name:        "with too many arguments",
 			args:        []string{"list", "packages", "mkoepf", "extra"},
 			wantError:   true,
-			errContains: "accepts 1 arg",
+			errContains: "accepts at most 1 arg",
 		},
 	}
 
@@ -67,6 +74,7 @@ func TestListPackagesCommandArguments(t *testing.T) {
 				// For valid args, it may still fail but not due to argument validation
 				if err != nil {
 					assert.NotContains(t, err.Error(), "accepts", "Should not fail on argument count")
+					assert.NotContains(t, err.Error(), "owner required")
 				}
 			}
 		})
@@ -82,6 +90,22 @@ func TestListPackagesCommandHasJSONFlag(t *testing.T) {
 	assert.NotNil(t, jsonFlag, "list packages command should have --json flag")
 }
 
+func TestListPackagesCmd_PackageType(t *testing.T) {
+	t.Parallel()
+	cmd := NewRootCmd()
+	packagesCmd, _, _ := cmd.Find([]string{"list", "packages"})
+	flag := packagesCmd.Flags().Lookup("package-type")
+	require.NotNil(t, flag, "list packages command should have --package-type flag")
+	assert.Equal(t, "container", flag.DefValue)
+
+	cmd.SetArgs([]string{"list", "packages", "myorg", "--package-type", "npm"})
+	cmd.SetOut(new(bytes.Buffer))
+	cmd.SetErr(new(bytes.Buffer))
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid --package-type: package type must be 'container' or 'docker', got 'npm'")
+}
+
 func TestPackagesOutputJSON(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
@@ -110,7 +134,7 @@ func TestPackagesOutputJSON(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			// Capture output in buffer
 			buf := &bytes.Buffer{}
-			err := display.OutputJSON(buf, tt.packages)
+			err := display.OutputJSON(context.Background(), buf, tt.packages)
 
 			if tt.wantErr {
 				assert.Error(t, err, "Expected error but got none")
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/mkoepf/ghcrctl/internal/discover"
+)
+
+// defaultPRPattern matches the per pull request tags pr-123
+const defaultPRPattern = `^pr-(\d+)$`
+
+// compilePRPattern compiles the regex given to --pr-pattern. It must have
+// exactly one capture group for the pull request number.
+func compilePRPattern(pattern string) (*regexp.Regexp, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --pr-pattern: %w", err)
+	}
+	if re.NumSubexp() != 1 {
+		return nil, fmt.Errorf("invalid --pr-pattern %q: needs exactly one capture group for the pull request number, has %d", pattern, re.NumSubexp())
+	}
+	return re, nil
+}
+
+// readClosedPRs reads pull request numbers separated by whitespace, commas, or
+// newlines. A leading # is allowed, so both 123 and #123 work.
+func readClosedPRs(r io.Reader) (map[int]bool, error) {
+	closed := make(map[int]bool)
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		fields := strings.FieldsFunc(scanner.Text(), func(c rune) bool {
+			return c == ',' || c == ' ' || c == '\t'
+		})
+		for _, field := range fields {
+			n, err := strconv.Atoi(strings.TrimPrefix(field, "#"))
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("line %d: invalid pull request number %q", line, field)
+			}
+			closed[n] = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return closed, nil
+}
+
+// loadClosedPRs reads the pull request numbers from the file given to
+// --closed-prs-file, or from stdin for -
+func loadClosedPRs(path string, stdin io.Reader) (map[int]bool, error) {
+	if path == "-" {
+		return readClosedPRs(stdin)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read closed pull requests: %w", err)
+	}
+	defer f.Close()
+	closed, err := readClosedPRs(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read closed pull requests from %s: %w", path, err)
+	}
+	return closed, nil
+}
+
+// closedPRTag reports whether tag matches pattern and its pull request number
+// is in closed
+func closedPRTag(tag string, pattern *regexp.Regexp, closed map[int]bool) bool {
+	match := pattern.FindStringSubmatch(tag)
+	if match == nil {
+		return false
+	}
+	n, err := strconv.Atoi(match[1])
+	return err == nil && closed[n]
+}
+
+// planDeleteClosedPRs collects the graphs of versions whose tags all belong to
+// closed pull requests, classified like planDeleteAllTags. Versions that also
+// carry other tags, e.g. latest, are returned as kept and not deleted.
+func planDeleteClosedPRs(versions []discover.VersionInfo, pattern *regexp.Regexp, closed map[int]bool) (tagged, toDelete, shared, kept []discover.VersionInfo) {
+	selected := func(v discover.VersionInfo) bool {
+		if len(v.Tags) == 0 {
+			return false
+		}
+		for _, tag := range v.Tags {
+			if !closedPRTag(tag, pattern, closed) {
+				return false
+			}
+		}
+		return true
+	}
+
+	for _, v := range versions {
+		if selected(v) {
+			continue
+		}
+		for _, tag := range v.Tags {
+			if closedPRTag(tag, pattern, closed) {
+				kept = append(kept, v)
+				break
+			}
+		}
+	}
+
+	sort.Slice(kept, func(i, j int) bool { return kept[i].ID > kept[j].ID })
+
+	tagged, toDelete, shared = planDeleteGraphs(versions, selected)
+	return tagged, toDelete, shared, kept
+}
@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/mkoepf/ghcrctl/internal/discover"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// prImages returns the images of three pull requests, each an index with one
+// platform manifest. pr-3 is also tagged latest.
+func prImages() []discover.VersionInfo {
+	return []discover.VersionInfo{
+		{ID: 10, Digest: "sha256:idx1", Tags: []string{"pr-1"}, Types: []string{"index"}, OutgoingRefs: []string{"sha256:amd64-1"}},
+		{ID: 1, Digest: "sha256:amd64-1", Types: []string{"linux/amd64"}, IncomingRefs: []string{"sha256:idx1"}},
+		{ID: 20, Digest: "sha256:idx2", Tags: []string{"pr-2"}, Types: []string{"index"}, OutgoingRefs: []string{"sha256:amd64-2"}},
+		{ID: 2, Digest: "sha256:amd64-2", Types: []string{"linux/amd64"}, IncomingRefs: []string{"sha256:idx2"}},
+		{ID: 30, Digest: "sha256:idx3", Tags: []string{"pr-3", "latest"}, Types: []string{"index"}, OutgoingRefs: []string{"sha256:amd64-3"}},
+		{ID: 3, Digest: "sha256:amd64-3", Types: []string{"linux/amd64"}, IncomingRefs: []string{"sha256:idx3"}},
+		{ID: 40, Digest: "sha256:idx4", Tags: []string{"v1.0.0"}, Types: []string{"index"}},
+	}
+}
+
+func TestReadClosedPRs(t *testing.T) {
+	t.Parallel()
+
+	closed, err := readClosedPRs(strings.NewReader("1\n#2, 3\n\n  42\t7\n"))
+	require.NoError(t, err)
+	assert.Equal(t, map[int]bool{1: true, 2: true, 3: true, 42: true, 7: true}, closed)
+}
+
+func TestReadClosedPRs_Invalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := readClosedPRs(strings.NewReader("1\npr-2\n"))
+	assert.EqualError(t, err, `line 2: invalid pull request number "pr-2"`)
+
+	_, err = readClosedPRs(strings.NewReader("0\n"))
+	assert.Error(t, err)
+}
+
+func TestCompilePRPattern(t *testing.T) {
+	t.Parallel()
+
+	_, err := compilePRPattern(defaultPRPattern)
+	assert.NoError(t, err)
+
+	_, err = compilePRPattern(`^pr-\d+$`)
+	assert.ErrorContains(t, err, "needs exactly one capture group")
+
+	_, err = compilePRPattern(`^(pr)-(\d+)$`)
+	assert.ErrorContains(t, err, "has 2")
+
+	_, err = compilePRPattern(`^pr-(\d+`)
+	assert.ErrorContains(t, err, "invalid --pr-pattern")
+}
+
+func TestClosedPRTag(t *testing.T) {
+	t.Parallel()
+
+	closed := map[int]bool{12: true}
+	tests := []struct {
+		pattern string
+		tag     string
+		want    bool
+	}{
+		{defaultPRPattern, "pr-12", true},
+		{defaultPRPattern, "pr-13", false},
+		{defaultPRPattern, "pr-12-amd64", false},
+		{defaultPRPattern, "latest", false},
+		{`^pr-(\d+)-`, "pr-12-amd64", true},
+		{`^pull/(\d+)$`, "pull/12", true},
+	}
+	for _, tt := range tests {
+		got := closedPRTag(tt.tag, regexp.MustCompile(tt.pattern), closed)
+		assert.Equal(t, tt.want, got, "closedPRTag(%q) with %s", tt.tag, tt.pattern)
+	}
+}
+
+func TestPlanDeleteClosedPRs(t *testing.T) {
+	t.Parallel()
+
+	closed := map[int]bool{1: true, 3: true, 99: true}
+	tagged, toDelete, shared, kept := planDeleteClosedPRs(prImages(), regexp.MustCompile(defaultPRPattern), closed)
+
+	assert.Equal(t, []int64{10}, versionIDs(tagged), "pr-2 is open and pr-3 is also latest")
+	assert.Equal(t, []int64{1, 10}, versionIDs(toDelete), "children first")
+	assert.Empty(t, shared)
+	assert.Equal(t, []int64{30}, versionIDs(kept))
+}
+
+func TestPlanDeleteClosedPRs_NoneClosed(t *testing.T) {
+	t.Parallel()
+
+	tagged, toDelete, _, kept := planDeleteClosedPRs(prImages(), regexp.MustCompile(defaultPRPattern), map[int]bool{5: true})
+
+	assert.Empty(t, tagged)
+	assert.Empty(t, toDelete)
+	assert.Empty(t, kept)
+}
+
+func TestExecuteDeleteAllTags_ClosedPRs(t *testing.T) {
+	t.Parallel()
+
+	closed := map[int]bool{1: true, 2: true}
+	tagged, toDelete, shared, _ := planDeleteClosedPRs(prImages(), regexp.MustCompile(defaultPRPattern), closed)
+	mock := newMockPackageDeleter()
+
+	var buf strings.Builder
+	err := executeDeleteAllTags(context.Background(), mock, deleteAllTagsParams{
+		Owner: "owner", OwnerType: "user", PackageName: "pkg", Scope: "graphs of closed pull requests",
+		Tagged: tagged, ToDelete: toDelete, Shared: shared, Force: true,
+	}, &buf, nil)
+	require.NoError(t, err)
+
+	assert.ElementsMatch(t, []int64{1, 10, 2, 20}, mock.deletedVersions)
+	assert.Contains(t, buf.String(), "Preparing to delete graphs of closed pull requests:")
+	assert.Contains(t, buf.String(), "Successfully deleted 4 version(s) of pkg")
+}
+
+func TestExecuteDeleteAllTags_NoClosedPRs(t *testing.T) {
+	t.Parallel()
+
+	var buf strings.Builder
+	err := executeDeleteAllTags(context.Background(), newMockPackageDeleter(), deleteAllTagsParams{
+		PackageName: "pkg", Scope: "graphs of closed pull requests",
+	}, &buf, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "No graphs of closed pull requests found in pkg\n", buf.String())
+}
+
+func TestDeleteGraphCmd_ClosedPRsValidation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{"pattern without file", []string{"--tag", "v1", "--pr-pattern", `^pr-(\d+)$`}, "--pr-pattern requires --closed-prs-file"},
+		{"pattern without group", []string{"--closed-prs-file", "closed.txt", "--pr-pattern", "^pr-"}, "needs exactly one capture group"},
+		{"stdin needs force", []string{"--closed-prs-file", "-"}, "requires --force or --dry-run"},
+		{"with tag", []string{"--closed-prs-file", "closed.txt", "--tag", "v1"}, "none of the others can be"},
+		{"with all-tags", []string{"--closed-prs-file", "closed.txt", "--all-tags"}, "none of the others can be"},
+		{"with json", []string{"--closed-prs-file", "closed.txt", "--json", "--dry-run"}, "none of the others can be"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cmd := NewRootCmd()
+			cmd.SetArgs(append([]string{"delete", "graph", "owner/pkg"}, tt.args...))
+			cmd.SetOut(new(strings.Builder))
+			cmd.SetErr(new(strings.Builder))
+
+			err := cmd.Execute()
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/mkoepf/ghcrctl/internal/config"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// profileEnv selects a profile when --profile is not given
+const profileEnv = "GHCRCTL_PROFILE"
+
+// skipProfileAnnotation marks commands that do not load profile defaults
+const skipProfileAnnotation = "ghcrctl_skip_profile"
+
+// profileDefaultAnnotation marks flags whose value came from the selected profile
+const profileDefaultAnnotation = "ghcrctl_profile_default"
+
+// mutuallyExclusiveAnnotation is the flag annotation cobra uses to record
+// MarkFlagsMutuallyExclusive groups. Each value is a space-separated group.
+const mutuallyExclusiveAnnotation = "cobra_annotation_mutually_exclusive"
+
+// loadProfileDefaults applies the defaults of the selected profile to cmd.
+// The profile comes from --profile, or from $GHCRCTL_PROFILE if the flag is empty.
+func loadProfileDefaults(cmd *cobra.Command, profileName string) error {
+	if profileName == "" {
+		profileName = os.Getenv(profileEnv)
+	}
+	if profileName == "" {
+		return nil
+	}
+
+	path, err := config.Path()
+	if err != nil {
+		return err
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		return err
+	}
+	profile, err := cfg.Profile(profileName)
+	if err != nil {
+		return fmt.Errorf("%w; profiles are read from %s", err, path)
+	}
+	return applyProfileDefaults(cmd, profile)
+}
+
+// applyProfileDefaults sets the profile's default values on the flags of cmd
+// that were not given on the command line. Flags stay unchanged in cobra's
+// sense, so the values behave like built-in defaults; flagSet tells them apart
+// for flags whose presence matters. A default is skipped when an explicit flag
+// in the same mutually exclusive group was given, so that e.g. --tagged on the
+// command line wins over a profile's untagged=true. A profile that sets two
+// mutually exclusive flags itself is an error.
+func applyProfileDefaults(cmd *cobra.Command, profile config.Profile) error {
+	commandPath := strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")
+	defaults, err := profile.FlagDefaults(commandPath)
+	if err != nil {
+		return err
+	}
+
+	// Apply in a stable order so errors are deterministic
+	names := make([]string, 0, len(defaults))
+	for name := range defaults {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	flags := cmd.Flags()
+	for _, name := range names {
+		flag := flags.Lookup(name)
+		if flag == nil {
+			return fmt.Errorf("profile sets unknown flag --%s for %q", name, commandPath)
+		}
+		if flag.Changed || conflictsWithExplicitFlag(flags, flag) {
+			continue
+		}
+		if other := conflictingProfileFlag(flags, flag); other != "" {
+			return fmt.Errorf("profile sets both --%s and --%s for %q, which cannot be combined", other, name, commandPath)
+		}
+		if err := flag.Value.Set(defaults[name]); err != nil {
+			return fmt.Errorf("invalid profile default for --%s in %q: %w", name, commandPath, err)
+		}
+		if err := flags.SetAnnotation(name, profileDefaultAnnotation, []string{"true"}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// flagSet reports whether a flag was given on the command line or by the
+// selected profile, as opposed to keeping its built-in default.
+func flagSet(cmd *cobra.Command, name string) bool {
+	flag := cmd.Flags().Lookup(name)
+	return flag != nil && (flag.Changed || len(flag.Annotations[profileDefaultAnnotation]) > 0)
+}
+
+// conflictsWithExplicitFlag reports whether a flag that shares a mutually
+// exclusive group with flag was given on the command line.
+func conflictsWithExplicitFlag(flags *pflag.FlagSet, flag *pflag.Flag) bool {
+	for _, group := range flag.Annotations[mutuallyExclusiveAnnotation] {
+		for _, other := range strings.Split(group, " ") {
+			if other == flag.Name {
+				continue
+			}
+			if f := flags.Lookup(other); f != nil && f.Changed {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// conflictingProfileFlag returns the name of a flag that shares a mutually
+// exclusive group with flag and was already set by the profile, or "".
+func conflictingProfileFlag(flags *pflag.FlagSet, flag *pflag.Flag) string {
+	for _, group := range flag.Annotations[mutuallyExclusiveAnnotation] {
+		for _, other := range strings.Split(group, " ") {
+			if other == flag.Name {
+				continue
+			}
+			if f := flags.Lookup(other); f != nil && len(f.Annotations[profileDefaultAnnotation]) > 0 {
+				return other
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mkoepf/ghcrctl/internal/config"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var cleanupProfile = config.Profile{Defaults: map[string]map[string]any{
+	"delete version": {"untagged": true, "older-than": "30d"},
+	"list versions":  {"exclude-type": []any{"sbom", "provenance"}},
+}}
+
+// parsedSubcommand finds the subcommand for args and parses its flags like Execute would
+func parsedSubcommand(t *testing.T, args ...string) *cobra.Command {
+	t.Helper()
+	root := NewRootCmd()
+	cmd, flagArgs, err := root.Find(args)
+	require.NoError(t, err)
+	require.NoError(t, cmd.ParseFlags(flagArgs))
+	return cmd
+}
+
+func TestApplyProfileDefaults(t *testing.T) {
+	t.Parallel()
+	cmd := parsedSubcommand(t, "delete", "version", "owner/pkg")
+
+	require.NoError(t, applyProfileDefaults(cmd, cleanupProfile))
+
+	untagged, err := cmd.Flags().GetBool("untagged")
+	require.NoError(t, err)
+	assert.True(t, untagged)
+	olderThan, err := cmd.Flags().GetString("older-than")
+	require.NoError(t, err)
+	assert.Equal(t, "30d", olderThan)
+	assert.False(t, cmd.Flags().Changed("untagged"), "profile values act as defaults, not as explicit flags")
+}
+
+func TestApplyProfileDefaults_ListValues(t *testing.T) {
+	t.Parallel()
+	cmd := parsedSubcommand(t, "list", "versions", "owner/pkg")
+
+	require.NoError(t, applyProfileDefaults(cmd, cleanupProfile))
+
+	excluded, err := cmd.Flags().GetStringSlice("exclude-type")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sbom", "provenance"}, excluded)
+}
+
+func TestApplyProfileDefaults_ExplicitFlagsWin(t *testing.T) {
+	t.Parallel()
+
+	t.Run("same flag", func(t *testing.T) {
+		t.Parallel()
+		cmd := parsedSubcommand(t, "delete", "version", "owner/pkg", "--older-than", "7d")
+
+		require.NoError(t, applyProfileDefaults(cmd, cleanupProfile))
+
+		olderThan, err := cmd.Flags().GetString("older-than")
+		require.NoError(t, err)
+		assert.Equal(t, "7d", olderThan)
+	})
+
+	t.Run("mutually exclusive flag", func(t *testing.T) {
+		t.Parallel()
+		cmd := parsedSubcommand(t, "delete", "version", "owner/pkg", "--tagged")
+
+		require.NoError(t, applyProfileDefaults(cmd, cleanupProfile))
+
+		untagged, err := cmd.Flags().GetBool("untagged")
+		require.NoError(t, err)
+		assert.False(t, untagged, "--tagged on the command line must override the profile's untagged default")
+		olderThan, err := cmd.Flags().GetString("older-than")
+		require.NoError(t, err)
+		assert.Equal(t, "30d", olderThan, "unrelated defaults still apply")
+	})
+}
+
+func TestApplyProfileDefaults_Errors(t *testing.T) {
+	t.Parallel()
+
+	t.Run("unknown flag", func(t *testing.T) {
+		t.Parallel()
+		cmd := parsedSubcommand(t, "list", "graphs", "owner/pkg")
+		profile := config.Profile{Defaults: map[string]map[string]any{"list graphs": {"untagged": true}}}
+
+		err := applyProfileDefaults(cmd, profile)
+		assert.EqualError(t, err, `profile sets unknown flag --untagged for "list graphs"`)
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		t.Parallel()
+		cmd := parsedSubcommand(t, "delete", "version", "owner/pkg")
+		profile := config.Profile{Defaults: map[string]map[string]any{"delete version": {"batch-size": "many"}}}
+
+		err := applyProfileDefaults(cmd, profile)
+		assert.ErrorContains(t, err, `invalid profile default for --batch-size in "delete version"`)
+	})
+}
+
+func TestApplyProfileDefaults_MarksProfileValuesAsSet(t *testing.T) {
+	t.Parallel()
+	cmd := parsedSubcommand(t, "delete", "version", "owner/pkg")
+	profile := config.Profile{Defaults: map[string]map[string]any{"delete version": {"untagged": true, "max-delete": float64(50)}}}
+
+	require.NoError(t, applyProfileDefaults(cmd, profile))
+
+	assert.True(t, flagSet(cmd, "max-delete"))
+	assert.False(t, cmd.Flags().Changed("max-delete"))
+	assert.False(t, flagSet(cmd, "batch-size"), "flags the profile leaves alone keep their built-in default")
+}
+
+func TestApplyProfileDefaults_ConflictingProfileFlags(t *testing.T) {
+	t.Parallel()
+	cmd := parsedSubcommand(t, "delete", "version", "owner/pkg")
+	profile := config.Profile{Defaults: map[string]map[string]any{"delete version": {"batch-size": float64(50), "max-delete": float64(10)}}}
+
+	err := applyProfileDefaults(cmd, profile)
+	assert.EqualError(t, err, `profile sets both --batch-size and --max-delete for "delete version", which cannot be combined`)
+}
+
+// TestProfileFlag_LoadsConfigFile runs a command end to end with a config file.
+// It sets environment variables, so it cannot run in parallel.
+func TestProfileFlag_LoadsConfigFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+		"profiles": {"strict": {"defaults": {"list versions": {"duplicates": true, "max-tags": -1}}}}
+	}`), 0o600))
+	t.Setenv(config.PathEnv, path)
+	t.Setenv(profileEnv, "")
+
+	run := func(args ...string) error {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		cmd.SetOut(new(bytes.Buffer))
+		cmd.SetErr(new(bytes.Buffer))
+		return cmd.Execute()
+	}
+
+	// The profile's invalid threshold reaches the command's validation
+	err := run("list", "versions", "owner/pkg", "--profile", "strict")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--max-tags must not be negative")
+
+	// An explicit flag replaces the profile value
+	err = run("list", "versions", "owner/pkg", "--profile", "strict", "--max-tags", "3")
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "--max-tags")
+
+	// Unknown profiles name the config file
+	err = run("list", "versions", "owner/pkg", "--profile", "missing")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `profile "missing" not found (available: strict)`)
+	assert.Contains(t, err.Error(), path)
+
+	// The environment variable selects the profile too
+	t.Setenv(profileEnv, "missing")
+	err = run("list", "versions", "owner/pkg")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `profile "missing" not found`)
+}
+
+// TestProfileFlag_DeleteLimits checks that the profile's --batch-size and
+// --max-delete take effect like the flags. It sets environment variables, so
+// it cannot run in parallel.
+func TestProfileFlag_DeleteLimits(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+		"profiles": {
+			"stream": {"defaults": {"delete version": {"untagged": true, "batch-size": 500}}},
+			"capped": {"defaults": {"delete version": {"untagged": true, "max-delete": 0}}}
+		}
+	}`), 0o600))
+	t.Setenv(config.PathEnv, path)
+	t.Setenv(profileEnv, "")
+	t.Setenv("GITHUB_TOKEN", "")
+
+	run := func(args ...string) error {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		cmd.SetOut(new(bytes.Buffer))
+		cmd.SetErr(new(bytes.Buffer))
+		return cmd.Execute()
+	}
+
+	// The profile's values reach the validation of bulk deletion
+	err := run("delete", "version", "owner/pkg", "--profile", "stream")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--batch-size must be between 1 and 100, got 500")
+
+	err = run("delete", "version", "owner/pkg", "--profile", "capped")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--max-delete must be at least 1, got 0")
+
+	// Selecting a single version leaves the profile's batch size unused
+	err = run("delete", "version", "owner/pkg", "--profile", "stream", "--version", "1")
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "--batch-size")
+}
@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"bytes"
+	"encoding/base64"
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -79,3 +81,158 @@ func TestGetProvenanceCommandHasFlags(t *testing.T) {
 		assert.NotNil(t, flag, "Expected flag '%s' to exist", flagName)
 	}
 }
+
+func TestGetProvenanceCommandHasExpectBuilderFlag(t *testing.T) {
+	t.Parallel()
+	cmd := NewRootCmd()
+
+	provenanceCmd, _, _ := cmd.Find([]string{"get", "provenance"})
+	assert.NotNil(t, provenanceCmd.Flags().Lookup("expect-builder"))
+
+	// Builder verification only makes sense for provenance
+	sbomCmd, _, _ := cmd.Find([]string{"get", "sbom"})
+	assert.Nil(t, sbomCmd.Flags().Lookup("expect-builder"))
+}
+
+const githubBuilder = "https://github.com/actions/runner/github-hosted"
+
+// slsaV02Provenance is an in-toto statement with a SLSA v0.2 predicate
+var slsaV02Provenance = map[string]interface{}{
+	"_type":         "https://in-toto.io/Statement/v0.1",
+	"predicateType": "https://slsa.dev/provenance/v0.2",
+	"predicate": map[string]interface{}{
+		"builder":   map[string]interface{}{"id": githubBuilder},
+		"buildType": "https://mobyproject.org/buildkit@v1",
+	},
+}
+
+// slsaV1Provenance is an in-toto statement with a SLSA v1 predicate
+var slsaV1Provenance = map[string]interface{}{
+	"_type":         "https://in-toto.io/Statement/v1",
+	"predicateType": "https://slsa.dev/provenance/v1",
+	"predicate": map[string]interface{}{
+		"buildDefinition": map[string]interface{}{"buildType": "https://actions.github.io/buildtypes/workflow/v1"},
+		"runDetails": map[string]interface{}{
+			"builder": map[string]interface{}{"id": githubBuilder},
+		},
+	},
+}
+
+func TestProvenanceBuilderID(t *testing.T) {
+	t.Parallel()
+
+	statement, err := json.Marshal(slsaV1Provenance)
+	require.NoError(t, err)
+	envelope := map[string]interface{}{
+		"payloadType": "application/vnd.in-toto+json",
+		"payload":     base64.StdEncoding.EncodeToString(statement),
+	}
+
+	tests := []struct {
+		name string
+		doc  map[string]interface{}
+		want string
+	}{
+		{"SLSA v0.2", slsaV02Provenance, githubBuilder},
+		{"SLSA v1", slsaV1Provenance, githubBuilder},
+		{"DSSE envelope", envelope, githubBuilder},
+		{"no predicate", map[string]interface{}{"predicateType": "https://slsa.dev/provenance/v1"}, ""},
+		{"not a statement", map[string]interface{}{"foo": "bar"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, provenanceBuilderID(tt.doc))
+		})
+	}
+}
+
+func TestCheckProvenanceBuilder(t *testing.T) {
+	t.Parallel()
+	digest := "sha256:1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef"
+
+	t.Run("matching builder", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		err := checkProvenanceBuilder(&buf, []map[string]interface{}{slsaV02Provenance, slsaV1Provenance}, digest, githubBuilder)
+		require.NoError(t, err)
+		assert.Contains(t, buf.String(), "Verified:")
+		assert.Contains(t, buf.String(), githubBuilder)
+	})
+
+	t.Run("mismatching builder reports actual value", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		err := checkProvenanceBuilder(&buf, []map[string]interface{}{slsaV1Provenance}, digest, "https://example.com/trusted-builder")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `expected "https://example.com/trusted-builder"`)
+		assert.Contains(t, err.Error(), `got "`+githubBuilder+`"`)
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("missing builder ID", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		err := checkProvenanceBuilder(&buf, []map[string]interface{}{{"predicateType": "https://slsa.dev/provenance/v1"}}, digest, githubBuilder)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no SLSA builder ID found")
+	})
+}
+
+func TestGetProvenanceCommandHasMaterialsFlag(t *testing.T) {
+	t.Parallel()
+	cmd := NewRootCmd()
+
+	provenanceCmd, _, _ := cmd.Find([]string{"get", "provenance"})
+	assert.NotNil(t, provenanceCmd.Flags().Lookup("materials"))
+
+	vexCmd, _, _ := cmd.Find([]string{"get", "vex"})
+	assert.Nil(t, vexCmd.Flags().Lookup("materials"))
+}
+
+func TestCollectProvenanceMaterials(t *testing.T) {
+	t.Parallel()
+	digest := "sha256:1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef"
+
+	v02 := map[string]interface{}{
+		"predicateType": "https://slsa.dev/provenance/v0.2",
+		"predicate": map[string]interface{}{
+			"builder": map[string]interface{}{"id": githubBuilder},
+			"materials": []interface{}{
+				map[string]interface{}{"uri": "pkg:docker/golang@1.23-alpine", "digest": map[string]interface{}{"sha256": "b0c1"}},
+			},
+		},
+	}
+	v1, err := json.Marshal(map[string]interface{}{
+		"predicateType": "https://slsa.dev/provenance/v1",
+		"predicate": map[string]interface{}{
+			"buildDefinition": map[string]interface{}{
+				"resolvedDependencies": []interface{}{
+					map[string]interface{}{"uri": "git+https://github.com/mkoepf/myimage@refs/heads/main", "digest": map[string]interface{}{"gitCommit": "9f2e"}},
+					map[string]interface{}{"name": "go.sum"},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+	envelope := map[string]interface{}{
+		"payloadType": "application/vnd.in-toto+json",
+		"payload":     base64.StdEncoding.EncodeToString(v1),
+	}
+	sbom := map[string]interface{}{"predicateType": "https://spdx.dev/Document", "predicate": map[string]interface{}{}}
+
+	results := collectProvenanceMaterials([]map[string]interface{}{v02, envelope, sbom}, digest)
+	require.Len(t, results, 2)
+	assert.Equal(t, "v0.2", results[0].SLSAVersion)
+	assert.Equal(t, "v1", results[1].SLSAVersion)
+
+	var buf bytes.Buffer
+	outputMaterialsTable(&buf, results)
+	out := buf.String()
+	assert.Contains(t, out, "Provenance: 1234567890ab (SLSA v0.2)")
+	assert.Contains(t, out, "pkg:docker/golang@1.23-alpine  sha256:b0c1")
+	assert.Contains(t, out, "git+https://github.com/mkoepf/myimage@refs/heads/main  gitCommit:9f2e")
+	assert.Contains(t, out, "go.sum                                                 -")
+	assert.Contains(t, out, "Total: 2 material(s).")
+}
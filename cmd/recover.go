@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+
+	"github.com/mkoepf/ghcrctl/internal/display"
+	"github.com/mkoepf/ghcrctl/internal/gh"
+	"github.com/spf13/cobra"
+)
+
+// newRecoverCmd creates the recover command.
+func newRecoverCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "recover <owner/package>",
+		Short: "Show where deleted versions of a package can be restored",
+		Long: `Show the page of the GitHub web UI on which the deleted versions of a package
+can be restored, and how to restore a deleted package.
+
+GitHub keeps deleted package versions for 30 days. Within that time they can
+be restored, as long as the package name has not been taken by a new package.
+ghcrctl does not restore versions itself; this command prints the links for the
+owner, which works for both users and organizations.
+
+Examples:
+  # Show the restore page of a package
+  ghcrctl recover mkoepf/myimage`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			owner, packageName, err := parsePackageRef(args[0], defaultOwner(cmd))
+			if err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
+
+			token, err := gh.GetToken()
+			if err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
+
+			ctx := cmd.Context()
+
+			client, err := gh.NewClientWithContext(ctx, token)
+			if err != nil {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("failed to create GitHub client: %w", err)
+			}
+
+			ownerType, err := client.GetOwnerType(ctx, owner)
+			if err != nil {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("failed to determine owner type: %w", err)
+			}
+
+			outputRecoverGuide(cmd.OutOrStdout(), owner, ownerType, packageName)
+			return nil
+		},
+	}
+
+	cmd.ValidArgsFunction = imageRefValidArgsFunc
+
+	return cmd
+}
+
+// outputRecoverGuide prints the restore pages of a package and how to use them
+func outputRecoverGuide(w io.Writer, owner, ownerType, packageName string) {
+	fmt.Fprintf(w, "Deleted versions of %s/%s can be restored in the GitHub web UI:\n", owner, packageName)
+	fmt.Fprintf(w, "  %s\n\n", deletedVersionsURL(owner, ownerType, packageName))
+	fmt.Fprintln(w, "Find the version by its digest and click Restore. ghcrctl prints the version IDs")
+	fmt.Fprintln(w, "and digests it deletes, and delete package --archive records all of them.")
+	fmt.Fprintln(w)
+
+	if ownerType == "org" {
+		fmt.Fprintf(w, "If the whole package was deleted, restore it in the package settings of %s:\n", owner)
+	} else {
+		fmt.Fprintf(w, "If the whole package was deleted, restore it in your package settings (signed in as %s):\n", owner)
+	}
+	fmt.Fprintf(w, "  %s\n\n", deletedPackagesURL(owner, ownerType))
+
+	fmt.Fprintln(w, display.ColorWarning("Note:"), "Deleted versions and packages can only be restored within 30 days, and")
+	fmt.Fprintln(w, "a package only while its name is not used by a new package.")
+}
+
+// deletedVersionsURL returns the versions page of a package in the GitHub web
+// UI, filtered to deleted versions. Nested package names are escaped as GitHub
+// does, e.g. team%2Fapp.
+func deletedVersionsURL(owner, ownerType, packageName string) string {
+	scope := "users"
+	if ownerType == "org" {
+		scope = "orgs"
+	}
+	return fmt.Sprintf("https://github.com/%s/%s/packages/container/%s/versions?filters%%5Bversion_type%%5D=deleted",
+		scope, url.PathEscape(owner), url.PathEscape(packageName))
+}
+
+// deletedPackagesURL returns the package settings page listing the deleted
+// packages of an owner. For users it is the settings page of the signed-in user.
+func deletedPackagesURL(owner, ownerType string) string {
+	if ownerType == "org" {
+		return fmt.Sprintf("https://github.com/organizations/%s/settings/packages", url.PathEscape(owner))
+	}
+	return "https://github.com/settings/packages"
+}
+
+// printRestoreHint tells where versions deleted by a successful run can be
+// restored
+func printRestoreHint(w io.Writer, owner, ownerType, packageName string) {
+	fmt.Fprintf(w, "Deleted versions can be restored within 30 days: %s\n", deletedVersionsURL(owner, ownerType, packageName))
+}
+
+// printPackageRestoreHint tells where a deleted package can be restored
+func printPackageRestoreHint(w io.Writer, owner, ownerType string) {
+	fmt.Fprintf(w, "The package can be restored within 30 days: %s\n", deletedPackagesURL(owner, ownerType))
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeletedVersionsURL(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name        string
+		owner       string
+		ownerType   string
+		packageName string
+		want        string
+	}{
+		{
+			name:        "user",
+			owner:       "mkoepf",
+			ownerType:   "user",
+			packageName: "myimage",
+			want:        "https://github.com/users/mkoepf/packages/container/myimage/versions?filters%5Bversion_type%5D=deleted",
+		},
+		{
+			name:        "org",
+			owner:       "myorg",
+			ownerType:   "org",
+			packageName: "myimage",
+			want:        "https://github.com/orgs/myorg/packages/container/myimage/versions?filters%5Bversion_type%5D=deleted",
+		},
+		{
+			name:        "nested package name",
+			owner:       "myorg",
+			ownerType:   "org",
+			packageName: "team/app",
+			want:        "https://github.com/orgs/myorg/packages/container/team%2Fapp/versions?filters%5Bversion_type%5D=deleted",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, deletedVersionsURL(tt.owner, tt.ownerType, tt.packageName))
+		})
+	}
+}
+
+func TestDeletedPackagesURL(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "https://github.com/settings/packages", deletedPackagesURL("mkoepf", "user"))
+	assert.Equal(t, "https://github.com/organizations/myorg/settings/packages", deletedPackagesURL("myorg", "org"))
+}
+
+func TestOutputRecoverGuide(t *testing.T) {
+	t.Parallel()
+
+	var buf strings.Builder
+	outputRecoverGuide(&buf, "myorg", "org", "myimage")
+	assert.Contains(t, buf.String(), "Deleted versions of myorg/myimage can be restored in the GitHub web UI:\n"+
+		"  https://github.com/orgs/myorg/packages/container/myimage/versions?filters%5Bversion_type%5D=deleted\n")
+	assert.Contains(t, buf.String(), "restore it in the package settings of myorg:\n"+
+		"  https://github.com/organizations/myorg/settings/packages\n")
+	assert.Contains(t, buf.String(), "within 30 days")
+
+	buf.Reset()
+	outputRecoverGuide(&buf, "mkoepf", "user", "myimage")
+	assert.Contains(t, buf.String(), "restore it in your package settings (signed in as mkoepf):\n"+
+		"  https://github.com/settings/packages\n")
+}
@@ -1,18 +1,27 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"time"
 
+	"github.com/mkoepf/ghcrctl/internal/discover"
+	"github.com/mkoepf/ghcrctl/internal/display"
+	"github.com/mkoepf/ghcrctl/internal/filter"
+	"github.com/mkoepf/ghcrctl/internal/gh"
 	"github.com/mkoepf/ghcrctl/internal/logging"
 	"github.com/mkoepf/ghcrctl/internal/quiet"
+	"github.com/mkoepf/ghcrctl/internal/ratelimit"
 	"github.com/spf13/cobra"
 )
 
+// Build information, set at build time via ldflags
+// Example: go build -ldflags "-X github.com/mkoepf/ghcrctl/cmd.Version=v1.0.0 -X github.com/mkoepf/ghcrctl/cmd.Commit=$(git rev-parse HEAD)"
 var (
-	// Version is set at build time via ldflags
-	// Example: go build -ldflags "-X github.com/mkoepf/ghcrctl/cmd.Version=v1.0.0"
 	Version = "dev"
+	Commit  = "unknown"
+	Date    = "unknown" // Build date
 )
 
 // newRootCmd creates a new root command with isolated flag state.
@@ -20,6 +29,17 @@ var (
 func newRootCmd() *cobra.Command {
 	var logAPICalls bool
 	var quietMode bool
+	var compactJSON bool
+	var prettyJSON bool
+	var profileName string
+	var rate float64
+	var githubSummary bool
+	var owner string
+	var query string
+	var envelope bool
+	var timezone string
+	var authFile string
+	var noCache bool
 
 	root := &cobra.Command{
 		Use:   "ghcrctl",
@@ -33,8 +53,36 @@ It provides functionality for:
 - Managing GHCR version metadata (labels, tags)
 - Safe deletion of package versions`, Version),
 		SilenceErrors: true,
-		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			// Fill in flag defaults from the selected profile before anything reads them.
+			// config doctor reports a broken profile instead of failing on it.
+			if cmd.Annotations[skipProfileAnnotation] == "" {
+				if err := loadProfileDefaults(cmd, profileName); err != nil {
+					cmd.SilenceUsage = true
+					return err
+				}
+			}
+
+			if rate < 0 {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("--rate must not be negative")
+			}
+
+			if _, err := filter.ParseLocation(timezone); err != nil {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("invalid --%s: %w", timezoneFlag, err)
+			}
+
 			ctx := cmd.Context()
+			// Append Markdown summaries to the GitHub Actions job summary
+			if githubSummary {
+				path := os.Getenv("GITHUB_STEP_SUMMARY")
+				if path == "" {
+					cmd.SilenceUsage = true
+					return fmt.Errorf("--github-summary requires GITHUB_STEP_SUMMARY to be set (as in GitHub Actions)")
+				}
+				ctx = display.WithStepSummary(ctx, path)
+			}
 			// Enable API call logging if flag is set
 			if logAPICalls {
 				ctx = logging.EnableLogging(ctx)
@@ -43,7 +91,59 @@ It provides functionality for:
 			if quietMode {
 				ctx = quiet.EnableQuiet(ctx)
 			}
+			// Select JSON formatting; auto-detection applies when neither is set
+			if compactJSON {
+				ctx = display.WithJSONStyle(ctx, display.JSONStyleCompact)
+			} else if prettyJSON {
+				ctx = display.WithJSONStyle(ctx, display.JSONStylePretty)
+			}
+			// Reduce JSON output to the results of the query
+			if query != "" {
+				if !jsonOutputRequested(cmd) {
+					cmd.SilenceUsage = true
+					return fmt.Errorf("--query requires JSON output (--json or -o json)")
+				}
+				q, err := display.ParseQuery(query)
+				if err != nil {
+					cmd.SilenceUsage = true
+					return fmt.Errorf("invalid --query: %w", err)
+				}
+				ctx = display.WithQuery(ctx, q)
+			}
+			// Wrap JSON arrays with the schema version
+			if envelope {
+				ctx = display.WithEnvelope(ctx)
+			}
+			// Pace all GitHub API and registry requests if a rate is set
+			if rate > 0 {
+				ctx = ratelimit.WithLimiter(ctx, ratelimit.NewLimiter(rate))
+			}
+			// Keep ETag-cached listings between runs, unless there is no cache directory
+			if !noCache {
+				if dir, err := gh.DefaultCacheDir(); err == nil {
+					ctx = gh.WithCacheDir(ctx, dir)
+				}
+			}
+			// Offer registry credentials from a containers auth.json. As for
+			// podman, a missing file named by the environment is an empty one.
+			fromEnv := authFile == ""
+			if fromEnv {
+				authFile = os.Getenv(discover.AuthFileEnv)
+			}
+			if authFile != "" {
+				creds, err := discover.LoadAuthFile(authFile)
+				switch {
+				case fromEnv && errors.Is(err, os.ErrNotExist):
+					// No credentials to offer
+				case err != nil:
+					cmd.SilenceUsage = true
+					return err
+				default:
+					ctx = discover.WithAuthFile(ctx, creds)
+				}
+			}
 			cmd.SetContext(ctx)
+			return nil
 		},
 	}
 
@@ -53,25 +153,125 @@ It provides functionality for:
 	// Add persistent flags
 	root.PersistentFlags().BoolVar(&logAPICalls, "log-api-calls", false, "Log all API calls with timing and categorization to stderr")
 	root.PersistentFlags().BoolVarP(&quietMode, "quiet", "q", false, "Suppress informational output (for scripting)")
+	root.PersistentFlags().BoolVar(&compactJSON, "compact", false, "Emit JSON output on a single line (default when stdout is not a terminal)")
+	root.PersistentFlags().BoolVar(&prettyJSON, "pretty", false, "Emit indented JSON output (default when stdout is a terminal)")
+	root.PersistentFlags().StringVar(&profileName, "profile", "", "Apply flag defaults from this config profile (default $GHCRCTL_PROFILE)")
+	root.PersistentFlags().StringVar(&owner, ownerFlag, "", "Owner of package arguments given without one (e.g. myimage instead of mkoepf/myimage)")
+	root.PersistentFlags().Float64Var(&rate, "rate", 0, "Limit GitHub API and registry requests to this many per second (0 = unlimited)")
+	root.PersistentFlags().BoolVar(&githubSummary, "github-summary", false, "Append a Markdown summary of listed or deleted versions to the GitHub Actions job summary")
+	root.PersistentFlags().StringVar(&query, "query", "", "With --json or -o json, print only the parts of the output selected by a path expression (e.g. '[].digest')")
+	root.PersistentFlags().BoolVar(&envelope, "envelope", false, "Wrap JSON arrays in an object with the schema version: {\"schema_version\": 1, \"items\": [...]}")
+	root.PersistentFlags().StringVar(&authFile, "authfile", "", "Read registry credentials from this containers auth.json, as written by podman login (default $REGISTRY_AUTH_FILE)")
+	root.PersistentFlags().StringVar(&timezone, timezoneFlag, "UTC", "Time zone of dates without one in --older-than and --newer-than (IANA name such as America/New_York, or local)")
+	root.PersistentFlags().BoolVar(&noCache, "no-cache", false, "Keep the ETag cache of package and version listings in memory only, instead of also in the user cache directory")
+	root.MarkFlagsMutuallyExclusive("compact", "pretty")
 
 	// Add subcommands via their factories
 	root.AddCommand(newListCmd())
 	root.AddCommand(newGetCmd())
 	root.AddCommand(newDeleteCmd())
 	root.AddCommand(newTagCmd())
+	root.AddCommand(newMoveCmd())
+	root.AddCommand(newRecoverCmd())
 	root.AddCommand(newStatsCmd())
+	root.AddCommand(newDiffRegistryCmd())
+	root.AddCommand(newConfigCmd())
 	root.AddCommand(newCompletionCmd())
+	root.AddCommand(newVersionCmd())
 
 	return root
 }
 
+// timezoneFlag is the persistent flag that sets the time zone of date filters
+const timezoneFlag = "timezone"
+
+// dateLocation returns the time zone in which --older-than and --newer-than
+// dates without a zone are interpreted. Version timestamps are in UTC.
+func dateLocation(cmd *cobra.Command) *time.Location {
+	name, _ := cmd.Flags().GetString(timezoneFlag)
+	loc, err := filter.ParseLocation(name)
+	if err != nil {
+		// Rejected in PersistentPreRunE
+		return time.UTC
+	}
+	return loc
+}
+
+// addAgeFlags adds --min-age and --max-age, the duration-only aliases of
+// --older-than and --newer-than, to a command that has both
+func addAgeFlags(cmd *cobra.Command, minAge, maxAge *string) {
+	cmd.Flags().StringVar(minAge, "min-age", "", "Match versions at least this old, a duration (e.g., 30d); same as --older-than 30d")
+	cmd.Flags().StringVar(maxAge, "max-age", "", "Match versions at most this old, a duration (e.g., 90d); same as --newer-than 90d")
+	cmd.MarkFlagsMutuallyExclusive("min-age", "older-than")
+	cmd.MarkFlagsMutuallyExclusive("max-age", "newer-than")
+}
+
+// applyAgeFlags checks that --min-age and --max-age are durations that some
+// version can match, and maps them onto --older-than and --newer-than
+func applyAgeFlags(minAge, maxAge string, olderThan, newerThan *string) error {
+	var minCutoff, maxCutoff time.Time
+	if minAge != "" {
+		t, err := filter.ParseAge(minAge)
+		if err != nil {
+			return fmt.Errorf("invalid --min-age: %w", err)
+		}
+		minCutoff = t
+		*olderThan = minAge
+	}
+	if maxAge != "" {
+		t, err := filter.ParseAge(maxAge)
+		if err != nil {
+			return fmt.Errorf("invalid --max-age: %w", err)
+		}
+		maxCutoff = t
+		*newerThan = maxAge
+	}
+	if minAge != "" && maxAge != "" && !minCutoff.After(maxCutoff) {
+		return fmt.Errorf("--min-age %s is not less than --max-age %s, no version can match", minAge, maxAge)
+	}
+	return nil
+}
+
 // rootCmd is the global command instance used by main.go
 var rootCmd = newRootCmd()
 
+// exitCodeWouldDelete is the exit code of a dry run with --detailed-exitcode
+// that found versions to delete, like terraform plan -detailed-exitcode.
+const exitCodeWouldDelete = 2
+
+// errWouldDelete is returned by dry runs with --detailed-exitcode when one or
+// more versions would be deleted. The dry-run output already explains it, so
+// Execute exits without printing it.
+var errWouldDelete = errors.New("dry run: versions would be deleted")
+
+// exitCode maps the error returned by a command to the process exit code
+func exitCode(err error) int {
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, errWouldDelete):
+		return exitCodeWouldDelete
+	default:
+		return 1
+	}
+}
+
 // Execute runs the root command
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+		if !errors.Is(err, errWouldDelete) {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		os.Exit(exitCode(err))
+	}
+}
+
+// jsonOutputRequested reports whether cmd was asked for JSON output, with
+// --json or -o json, on the command line or by the selected profile
+func jsonOutputRequested(cmd *cobra.Command) bool {
+	if flag := cmd.Flags().Lookup("json"); flag != nil && flag.Value.String() == "true" {
+		return true
 	}
+	flag := cmd.Flags().Lookup("output")
+	return flag != nil && flag.Value.String() == string(display.OutputModeJSON)
 }
@@ -2,6 +2,10 @@ package cmd
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -109,3 +113,105 @@ func TestRootCommandHasQuietFlag(t *testing.T) {
 	qFlag := cmd.PersistentFlags().ShorthandLookup("q")
 	assert.NotNil(t, qFlag, "Expected -q shorthand for --quiet flag")
 }
+
+func TestRootCommandHasJSONStyleFlags(t *testing.T) {
+	t.Parallel()
+	cmd := NewRootCmd()
+
+	assert.NotNil(t, cmd.PersistentFlags().Lookup("compact"), "Expected --compact persistent flag to exist")
+	assert.NotNil(t, cmd.PersistentFlags().Lookup("pretty"), "Expected --pretty persistent flag to exist")
+}
+
+func TestRootCommandCompactAndPrettyAreExclusive(t *testing.T) {
+	t.Parallel()
+	cmd := NewRootCmd()
+	cmd.SetArgs([]string{"list", "packages", "owner", "--compact", "--pretty"})
+	cmd.SetOut(new(bytes.Buffer))
+	cmd.SetErr(new(bytes.Buffer))
+
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "none of the others can be")
+}
+
+func TestRootCommandRejectsUnknownTimezone(t *testing.T) {
+	t.Parallel()
+	cmd := NewRootCmd()
+	cmd.SetArgs([]string{"list", "versions", "owner/pkg", "--older-than", "2025-01-01", "--timezone", "Mars/Olympus_Mons"})
+	cmd.SetOut(new(bytes.Buffer))
+	cmd.SetErr(new(bytes.Buffer))
+
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid --timezone: unknown time zone "Mars/Olympus_Mons"`)
+}
+
+func TestRootCommandRejectsNegativeRate(t *testing.T) {
+	t.Parallel()
+	cmd := NewRootCmd()
+	cmd.SetArgs([]string{"list", "packages", "owner", "--rate", "-1"})
+	cmd.SetOut(new(bytes.Buffer))
+	cmd.SetErr(new(bytes.Buffer))
+
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--rate must not be negative")
+}
+
+func TestRootCommandRejectsMissingAuthFile(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "auth.json")
+	cmd := NewRootCmd()
+	cmd.SetArgs([]string{"list", "packages", "owner", "--authfile", path})
+	cmd.SetOut(new(bytes.Buffer))
+	cmd.SetErr(new(bytes.Buffer))
+
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to read auth file")
+}
+
+func TestRootCommandAuthFileFromEnv(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "auth.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"auths": {"ghcr.io": {"auth": "not base64!"}}}`), 0o600))
+	t.Setenv("REGISTRY_AUTH_FILE", path)
+	cmd := NewRootCmd()
+	cmd.SetArgs([]string{"list", "packages", "owner"})
+	cmd.SetOut(new(bytes.Buffer))
+	cmd.SetErr(new(bytes.Buffer))
+
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid credentials for ghcr.io")
+}
+
+func TestRootCommandIgnoresMissingAuthFileFromEnv(t *testing.T) {
+	t.Setenv("REGISTRY_AUTH_FILE", filepath.Join(t.TempDir(), "auth.json"))
+	cmd := NewRootCmd()
+	cmd.SetArgs([]string{"version"})
+	cmd.SetOut(new(bytes.Buffer))
+	cmd.SetErr(new(bytes.Buffer))
+
+	require.NoError(t, cmd.Execute())
+}
+
+func TestRootCommandGitHubSummaryRequiresEnv(t *testing.T) {
+	t.Setenv("GITHUB_STEP_SUMMARY", "")
+	cmd := NewRootCmd()
+	cmd.SetArgs([]string{"list", "packages", "owner", "--github-summary"})
+	cmd.SetOut(new(bytes.Buffer))
+	cmd.SetErr(new(bytes.Buffer))
+
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--github-summary requires GITHUB_STEP_SUMMARY")
+}
+
+func TestExitCode(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, 0, exitCode(nil))
+	assert.Equal(t, 1, exitCode(errors.New("failed to list package versions")))
+	assert.Equal(t, 2, exitCode(errWouldDelete))
+	assert.Equal(t, 2, exitCode(fmt.Errorf("wrapped: %w", errWouldDelete)))
+}
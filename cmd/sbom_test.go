@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"bytes"
+	"strings"
 	"testing"
 
 	"github.com/mkoepf/ghcrctl/internal/display"
@@ -112,3 +113,40 @@ func TestShortDigest(t *testing.T) {
 		})
 	}
 }
+
+func TestOutputArtifactReadable_SBOMTable(t *testing.T) {
+	t.Parallel()
+	content := []map[string]interface{}{{
+		"predicateType": "https://spdx.dev/Document",
+		"predicate": map[string]interface{}{
+			"spdxVersion": "SPDX-2.3",
+			"packages": []interface{}{
+				map[string]interface{}{"name": "zlib", "versionInfo": "1.3.1-r0", "licenseConcluded": "Zlib"},
+				map[string]interface{}{"name": "busybox", "versionInfo": "1.36.1-r15", "licenseConcluded": "GPL-2.0-only"},
+				map[string]interface{}{"name": "ca-certificates", "versionInfo": "20240226-r0", "licenseConcluded": "NOASSERTION"},
+			},
+		},
+	}}
+
+	var buf bytes.Buffer
+	require.NoError(t, outputArtifactReadable(&buf, content, "sha256:abcdef1234567890", "sbom"))
+
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	require.Len(t, lines, 9)
+	assert.Equal(t, "SBOM: abcdef123456 (SPDX-2.3)", lines[0])
+	assert.Equal(t, "  NAME             VERSION      LICENSE", lines[2])
+	assert.Equal(t, "  busybox          1.36.1-r15   GPL-2.0-only", lines[4])
+	assert.Equal(t, "  ca-certificates  20240226-r0  -", lines[5])
+	assert.Equal(t, "  zlib             1.3.1-r0     Zlib", lines[6])
+	assert.Equal(t, "Total: 3 component(s), 2 distinct license(s).", lines[8])
+}
+
+func TestOutputArtifactReadable_UnknownSBOMFormat(t *testing.T) {
+	t.Parallel()
+	content := []map[string]interface{}{{"predicateType": "https://example.com/sbom", "predicate": map[string]interface{}{"files": []interface{}{}}}}
+
+	var buf bytes.Buffer
+	require.NoError(t, outputArtifactReadable(&buf, content, "sha256:abcdef1234567890", "sbom"))
+	assert.Contains(t, buf.String(), "Sbom: abcdef123456")
+	assert.Contains(t, buf.String(), `"predicateType": "https://example.com/sbom"`, "unknown formats are shown as JSON")
+}
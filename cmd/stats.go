@@ -4,9 +4,12 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"strings"
 
+	"github.com/mkoepf/ghcrctl/internal/discover"
 	"github.com/mkoepf/ghcrctl/internal/display"
 	"github.com/mkoepf/ghcrctl/internal/gh"
+	"github.com/mkoepf/ghcrctl/internal/parallel"
 	"github.com/mkoepf/ghcrctl/internal/quiet"
 	"github.com/spf13/cobra"
 )
@@ -23,25 +26,73 @@ type packageStats struct {
 }
 
 func newStatsCmd() *cobra.Command {
-	var jsonOutput bool
+	var (
+		jsonOutput         bool
+		allPackages        bool
+		concurrentPackages int
+		fromJSON           string
+	)
 
 	cmd := &cobra.Command{
 		Use:   "stats <owner/package>",
 		Short: "Show statistics for a package",
 		Long: `Display statistics for a container package including version counts and dates.
 
+With --all-packages, the argument is an owner, and the statistics of all its
+container packages are shown as a table. Up to --concurrent-packages packages
+are read at once. A package that fails does not stop the others; the failures
+are listed at the end and the command exits with an error.
+
+With --from-json, the statistics are computed from versions saved with
+list graphs --json (- for stdin) instead of GitHub, without a token.
+
 Examples:
   # Show statistics for a package
   ghcrctl stats mkoepf/myimage
 
   # Output as JSON
-  ghcrctl stats mkoepf/myimage --json`,
+  ghcrctl stats mkoepf/myimage --json
+
+  # Show statistics for all packages of an owner
+  ghcrctl stats myorg --all-packages
+
+  # Show statistics of a saved list graphs dump
+  ghcrctl stats mkoepf/myimage --from-json graphs.json`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			owner, packageName, err := parsePackageRef(args[0])
-			if err != nil {
+			if concurrentPackages < 1 {
 				cmd.SilenceUsage = true
-				return err
+				return fmt.Errorf("--concurrent-packages must be at least 1")
+			}
+
+			var owner, packageName string
+			if allPackages {
+				owner = args[0]
+				if strings.Contains(owner, "/") {
+					cmd.SilenceUsage = true
+					return fmt.Errorf("--all-packages takes an owner, not a package: %q", owner)
+				}
+			} else {
+				var err error
+				owner, packageName, err = parsePackageRef(args[0], defaultOwner(cmd))
+				if err != nil {
+					cmd.SilenceUsage = true
+					return err
+				}
+			}
+
+			if fromJSON != "" {
+				dump, err := loadVersionsDump(fromJSON, cmd.InOrStdin())
+				if err != nil {
+					cmd.SilenceUsage = true
+					return err
+				}
+				stats := calculateStats(discover.ToPackageVersions(dump))
+				stats.PackageName = packageName
+				if jsonOutput {
+					return display.OutputJSON(cmd.Context(), cmd.OutOrStdout(), stats)
+				}
+				return outputStatsTable(cmd.OutOrStdout(), stats, quiet.IsQuiet(cmd.Context()))
 			}
 
 			token, err := gh.GetToken()
@@ -63,6 +114,17 @@ Examples:
 				return fmt.Errorf("failed to determine owner type: %w", err)
 			}
 
+			if allPackages {
+				cmd.SilenceUsage = true
+				return executeOwnerStats(cmd.Context(), ghClient, ownerStatsParams{
+					Owner:       owner,
+					OwnerType:   ownerType,
+					Concurrency: concurrentPackages,
+					JSONOutput:  jsonOutput,
+					QuietMode:   quiet.IsQuiet(cmd.Context()),
+				}, cmd.OutOrStdout())
+			}
+
 			// List all versions
 			versions, err := ghClient.ListPackageVersions(cmd.Context(), owner, ownerType, packageName)
 			if err != nil {
@@ -76,7 +138,7 @@ Examples:
 
 			// Output
 			if jsonOutput {
-				return display.OutputJSON(cmd.OutOrStdout(), stats)
+				return display.OutputJSON(cmd.Context(), cmd.OutOrStdout(), stats)
 			}
 
 			return outputStatsTable(cmd.OutOrStdout(), stats, quiet.IsQuiet(cmd.Context()))
@@ -84,6 +146,10 @@ Examples:
 	}
 
 	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output in JSON format")
+	cmd.Flags().BoolVar(&allPackages, "all-packages", false, "Show statistics for all packages of the owner given as argument")
+	addConcurrentPackagesFlag(cmd, &concurrentPackages)
+	cmd.Flags().StringVar(&fromJSON, "from-json", "", "Read the versions from a file saved with list graphs --json (- for stdin)")
+	cmd.MarkFlagsMutuallyExclusive("from-json", "all-packages")
 
 	cmd.ValidArgsFunction = imageRefValidArgsFunc
 
@@ -151,7 +217,7 @@ func executeStats(ctx context.Context, lister versionLister, params statsParams,
 	stats.PackageName = params.PackageName
 
 	if params.JSONOutput {
-		return display.OutputJSON(out, stats)
+		return display.OutputJSON(ctx, out, stats)
 	}
 
 	return outputStatsTable(out, stats, params.QuietMode)
@@ -177,3 +243,130 @@ func outputStatsTable(w io.Writer, stats packageStats, quietMode bool) error {
 
 	return nil
 }
+
+// packageLister is an interface for listing the packages of an owner
+type packageLister interface {
+	ListPackages(ctx context.Context, owner, ownerType, packageType string) ([]string, error)
+}
+
+// ownerStatsClient lists the packages of an owner and their versions
+type ownerStatsClient interface {
+	packageLister
+	versionLister
+}
+
+// ownerStatsParams contains parameters for stats --all-packages execution
+type ownerStatsParams struct {
+	Owner       string
+	OwnerType   string
+	Concurrency int
+	JSONOutput  bool
+	QuietMode   bool
+}
+
+// addConcurrentPackagesFlag adds the --concurrent-packages flag of commands
+// that process all packages of an owner
+func addConcurrentPackagesFlag(cmd *cobra.Command, target *int) {
+	cmd.Flags().IntVar(target, "concurrent-packages", parallel.DefaultLimit, "Number of packages processed at once")
+}
+
+// executeOwnerStats computes the statistics of every container package of an
+// owner, reading up to params.Concurrency packages at once. The statistics of
+// the packages that succeeded are written before the failures are returned.
+func executeOwnerStats(ctx context.Context, client ownerStatsClient, params ownerStatsParams, out io.Writer) error {
+	packages, err := client.ListPackages(ctx, params.Owner, params.OwnerType, gh.PackageTypeContainer)
+	if err != nil {
+		return fmt.Errorf("failed to list packages: %w", err)
+	}
+
+	results := make([]packageStats, len(packages))
+	errs := parallel.Run(ctx, params.Concurrency, len(packages), func(ctx context.Context, i int) error {
+		versions, err := client.ListPackageVersions(ctx, params.Owner, params.OwnerType, packages[i])
+		if err != nil {
+			return err
+		}
+		results[i] = calculateStats(versions)
+		results[i].PackageName = packages[i]
+		return nil
+	})
+
+	stats := make([]packageStats, 0, len(packages))
+	for i, err := range errs {
+		if err == nil {
+			stats = append(stats, results[i])
+		}
+	}
+
+	if params.JSONOutput {
+		err = display.OutputJSON(ctx, out, stats)
+	} else {
+		err = outputOwnerStatsTable(out, stats, params.Owner, params.QuietMode)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := parallel.Collect(packages, errs); err != nil {
+		return fmt.Errorf("failed to get statistics: %w", err)
+	}
+	return nil
+}
+
+// outputOwnerStatsTable outputs the statistics of several packages, one row
+// per package
+func outputOwnerStatsTable(w io.Writer, stats []packageStats, owner string, quietMode bool) error {
+	if len(stats) == 0 {
+		if !quietMode {
+			fmt.Fprintf(w, "No packages found for %s\n", owner)
+		}
+		return nil
+	}
+
+	if !quietMode {
+		fmt.Fprintf(w, "Statistics for packages of %s:\n\n", owner)
+	}
+
+	headers := []string{"PACKAGE", "VERSIONS", "TAGGED", "UNTAGGED", "TAGS", "NEWEST"}
+	rows := make([][]string, 0, len(stats))
+	for _, s := range stats {
+		rows = append(rows, []string{
+			s.PackageName,
+			fmt.Sprintf("%d", s.TotalVersions),
+			fmt.Sprintf("%d", s.TaggedVersions),
+			fmt.Sprintf("%d", s.UntaggedVersions),
+			fmt.Sprintf("%d", s.TotalTags),
+			s.NewestVersion,
+		})
+	}
+	widths := make([]int, len(headers))
+	for i, h := range headers {
+		widths[i] = len(h)
+		for _, row := range rows {
+			widths[i] = max(widths[i], len(row[i]))
+		}
+	}
+
+	header := make([]string, len(headers))
+	separator := make([]string, len(headers))
+	for i, h := range headers {
+		header[i] = display.ColorHeader(fmt.Sprintf("%-*s", widths[i], h))
+		if i == len(headers)-1 {
+			header[i] = display.ColorHeader(h)
+		}
+		separator[i] = display.ColorSeparator(strings.Repeat("-", widths[i]))
+	}
+	fmt.Fprintf(w, "  %s\n", strings.Join(header, "  "))
+	fmt.Fprintf(w, "  %s\n", strings.Join(separator, "  "))
+	for _, row := range rows {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			cells[i] = fmt.Sprintf("%-*s", widths[i], cell)
+		}
+		fmt.Fprintf(w, "  %s\n", strings.TrimRight(strings.Join(cells, "  "), " "))
+	}
+
+	if !quietMode {
+		fmt.Fprintf(w, "\nTotal: %s package(s)\n", display.ColorCount(len(stats)))
+	}
+	return nil
+}
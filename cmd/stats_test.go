@@ -3,6 +3,7 @@ package cmd
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"testing"
 
@@ -48,6 +49,31 @@ func TestStatsCommandRequiresArg(t *testing.T) {
 	assert.Error(t, err, "Expected error when no argument provided")
 }
 
+func TestStatsCommand_FromJSON(t *testing.T) {
+	t.Parallel()
+	// Versions 12 and 15 share the arm64 digest
+	data, err := json.Marshal(sharedPlatformGraphs())
+	require.NoError(t, err)
+
+	cmd := NewRootCmd()
+	stdout := new(bytes.Buffer)
+	cmd.SetOut(stdout)
+	cmd.SetErr(new(bytes.Buffer))
+	cmd.SetIn(bytes.NewReader(data))
+	cmd.SetArgs([]string{"stats", "mkoepf/myimage", "--from-json", "-", "--json"})
+	require.NoError(t, cmd.Execute())
+
+	var stats packageStats
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &stats))
+	assert.Equal(t, packageStats{
+		PackageName:      "myimage",
+		TotalVersions:    6,
+		TaggedVersions:   2,
+		UntaggedVersions: 4,
+		TotalTags:        2,
+	}, stats)
+}
+
 func TestCalculateStats(t *testing.T) {
 	t.Parallel()
 
@@ -274,3 +300,81 @@ func TestExecuteStats_QuietModeNoHeader(t *testing.T) {
 	output := buf.String()
 	assert.NotContains(t, output, "Statistics for", "quiet mode should not include 'Statistics for' header")
 }
+
+// mockOwnerStatsClient lists fixed packages, each with its own versions or error
+type mockOwnerStatsClient struct {
+	packages []string
+	versions map[string][]gh.PackageVersionInfo
+	errs     map[string]error
+}
+
+func (m *mockOwnerStatsClient) ListPackages(ctx context.Context, owner, ownerType, packageType string) ([]string, error) {
+	return m.packages, nil
+}
+
+func (m *mockOwnerStatsClient) ListPackageVersions(ctx context.Context, owner, ownerType, packageName string) ([]gh.PackageVersionInfo, error) {
+	if err := m.errs[packageName]; err != nil {
+		return nil, err
+	}
+	return m.versions[packageName], nil
+}
+
+func TestExecuteOwnerStats(t *testing.T) {
+	t.Parallel()
+	client := &mockOwnerStatsClient{
+		packages: []string{"api", "web"},
+		versions: map[string][]gh.PackageVersionInfo{
+			"api": {
+				{ID: 1, Tags: []string{"v1", "latest"}, CreatedAt: "2025-01-15T10:00:00Z"},
+				{ID: 2, CreatedAt: "2025-01-10T10:00:00Z"},
+			},
+			"web": {{ID: 3, Tags: []string{"v2"}, CreatedAt: "2025-02-01T10:00:00Z"}},
+		},
+	}
+
+	var buf bytes.Buffer
+	err := executeOwnerStats(context.Background(), client, ownerStatsParams{Owner: "myorg", OwnerType: "org", Concurrency: 2}, &buf)
+	require.NoError(t, err)
+	assert.Equal(t, `Statistics for packages of myorg:
+
+  PACKAGE  VERSIONS  TAGGED  UNTAGGED  TAGS  NEWEST
+  -------  --------  ------  --------  ----  --------------------
+  api      2         1       1         2     2025-01-15T10:00:00Z
+  web      1         1       0         1     2025-02-01T10:00:00Z
+
+Total: 2 package(s)
+`, buf.String())
+}
+
+func TestExecuteOwnerStats_IsolatesFailures(t *testing.T) {
+	t.Parallel()
+	client := &mockOwnerStatsClient{
+		packages: []string{"api", "broken", "web"},
+		versions: map[string][]gh.PackageVersionInfo{
+			"api": {{ID: 1, Tags: []string{"v1"}}},
+			"web": {{ID: 2}},
+		},
+		errs: map[string]error{"broken": fmt.Errorf("forbidden")},
+	}
+
+	var buf bytes.Buffer
+	err := executeOwnerStats(context.Background(), client, ownerStatsParams{Owner: "myorg", Concurrency: 1, JSONOutput: true}, &buf)
+	require.EqualError(t, err, "failed to get statistics: 1 of 3 packages failed:\n  broken: forbidden")
+
+	var stats []packageStats
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &stats))
+	require.Len(t, stats, 2)
+	assert.Equal(t, "api", stats[0].PackageName)
+	assert.Equal(t, "web", stats[1].PackageName)
+}
+
+func TestStatsCommand_AllPackagesRejectsPackageRef(t *testing.T) {
+	t.Parallel()
+	cmd := NewRootCmd()
+	cmd.SetArgs([]string{"stats", "myorg/api", "--all-packages"})
+	cmd.SetOut(new(bytes.Buffer))
+	cmd.SetErr(new(bytes.Buffer))
+
+	err := cmd.Execute()
+	assert.EqualError(t, err, `--all-packages takes an owner, not a package: "myorg/api"`)
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/mkoepf/ghcrctl/internal/display"
+	"github.com/spf13/cobra"
+)
+
+// stdinRefArg is the positional argument that makes a command read
+// owner/package references from stdin, one per line.
+const stdinRefArg = "-"
+
+// stdinRefResult is the JSON output for one reference read from stdin
+type stdinRefResult struct {
+	Package string          `json:"package"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Message string          `json:"message,omitempty"`
+	Error   string          `json:"error,omitempty"`
+}
+
+// readPackageRefs reads one reference per line, skipping blank lines and # comments.
+func readPackageRefs(r io.Reader) ([]string, error) {
+	var refs []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		refs = append(refs, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read package references from stdin: %w", err)
+	}
+	if len(refs) == 0 {
+		return nil, fmt.Errorf("no package references found on stdin")
+	}
+	return refs, nil
+}
+
+// runForRefs runs fn for the reference in args[0], or for every reference read
+// from stdin when args[0] is "-".
+//
+// With stdin input, table output is written in sections with a header per
+// reference. JSON output is collected into a single array with one entry per
+// reference. A failing reference does not stop the others; the error is
+// reported once all references were processed.
+func runForRefs(cmd *cobra.Command, args []string, jsonOutput bool, fn func(ref string, w io.Writer) error) error {
+	if args[0] != stdinRefArg {
+		return fn(args[0], cmd.OutOrStdout())
+	}
+
+	refs, err := readPackageRefs(cmd.InOrStdin())
+	if err != nil {
+		cmd.SilenceUsage = true
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	var results []stdinRefResult
+	failed := 0
+
+	for i, ref := range refs {
+		if jsonOutput {
+			var buf bytes.Buffer
+			result := stdinRefResult{Package: ref}
+			if err := fn(ref, &buf); err != nil {
+				result.Error = err.Error()
+				failed++
+			} else if raw := bytes.TrimSpace(buf.Bytes()); json.Valid(raw) {
+				result.Result = raw
+			} else {
+				// Informational text such as "no versions found"
+				result.Message = string(raw)
+			}
+			results = append(results, result)
+			continue
+		}
+
+		if i > 0 {
+			fmt.Fprintln(out)
+		}
+		fmt.Fprintf(out, "==> %s <==\n", ref)
+		if err := fn(ref, out); err != nil {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Error: %s: %v\n", ref, err)
+			failed++
+		}
+	}
+
+	if jsonOutput {
+		if err := display.OutputJSON(cmd.Context(), out, results); err != nil {
+			return err
+		}
+	}
+
+	if failed > 0 {
+		cmd.SilenceUsage = true
+		return fmt.Errorf("failed for %d of %d package(s)", failed, len(refs))
+	}
+	return nil
+}
+
+// withStdinRefs returns a RunE that runs run for the reference in args[0], or
+// through runForRefs for every reference read from stdin when args[0] is "-".
+// run sees each reference as args[0] and writes its output to cmd.OutOrStdout().
+func withStdinRefs(jsonOutput func() bool, run func(cmd *cobra.Command, args []string) error) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		if args[0] != stdinRefArg {
+			return run(cmd, args)
+		}
+
+		out := cmd.OutOrStdout()
+		defer cmd.SetOut(out)
+		return runForRefs(cmd, args, jsonOutput(), func(ref string, w io.Writer) error {
+			cmd.SetOut(w)
+			return run(cmd, []string{ref})
+		})
+	}
+}
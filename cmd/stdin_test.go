@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadPackageRefs(t *testing.T) {
+	t.Parallel()
+
+	refs, err := readPackageRefs(strings.NewReader("mkoepf/one\n\n  mkoepf/two  \n# comment\nmyorg/three\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"mkoepf/one", "mkoepf/two", "myorg/three"}, refs)
+
+	_, err = readPackageRefs(strings.NewReader("\n# only comments\n"))
+	assert.ErrorContains(t, err, "no package references found on stdin")
+}
+
+// newStdinTestCmd returns a command wired to the given stdin with captured stdout and stderr
+func newStdinTestCmd(stdin string) (*cobra.Command, *bytes.Buffer, *bytes.Buffer) {
+	cmd := &cobra.Command{}
+	out, errOut := new(bytes.Buffer), new(bytes.Buffer)
+	cmd.SetIn(strings.NewReader(stdin))
+	cmd.SetOut(out)
+	cmd.SetErr(errOut)
+	return cmd, out, errOut
+}
+
+func TestRunForRefs_SingleArgument(t *testing.T) {
+	t.Parallel()
+	cmd, out, _ := newStdinTestCmd("ignored/pkg\n")
+
+	var seen []string
+	err := runForRefs(cmd, []string{"mkoepf/myimage"}, false, func(ref string, w io.Writer) error {
+		seen = append(seen, ref)
+		fmt.Fprintf(w, "output for %s\n", ref)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"mkoepf/myimage"}, seen, "stdin should only be read for '-'")
+	assert.Equal(t, "output for mkoepf/myimage\n", out.String())
+}
+
+func TestRunForRefs_TableSections(t *testing.T) {
+	t.Parallel()
+	cmd, out, _ := newStdinTestCmd("mkoepf/one\nmkoepf/two\n")
+
+	err := runForRefs(cmd, []string{"-"}, false, func(ref string, w io.Writer) error {
+		fmt.Fprintf(w, "versions of %s\n", ref)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "==> mkoepf/one <==\nversions of mkoepf/one\n\n==> mkoepf/two <==\nversions of mkoepf/two\n", out.String())
+}
+
+func TestRunForRefs_JSONArray(t *testing.T) {
+	t.Parallel()
+	cmd, out, _ := newStdinTestCmd("mkoepf/one\nmkoepf/empty\nmkoepf/two\n")
+
+	err := runForRefs(cmd, []string{"-"}, true, func(ref string, w io.Writer) error {
+		if ref == "mkoepf/empty" {
+			fmt.Fprintln(w, "No versions found matching filter criteria")
+			return nil
+		}
+		fmt.Fprintf(w, `[{"package":%q}]`+"\n", ref)
+		return nil
+	})
+	require.NoError(t, err)
+
+	var results []struct {
+		Package string              `json:"package"`
+		Result  []map[string]string `json:"result"`
+		Message string              `json:"message"`
+	}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &results), "output should be a single JSON array: %s", out.String())
+	require.Len(t, results, 3)
+	assert.Equal(t, "mkoepf/one", results[0].Package)
+	assert.Equal(t, "mkoepf/one", results[0].Result[0]["package"])
+	assert.Equal(t, "No versions found matching filter criteria", results[1].Message)
+	assert.Nil(t, results[1].Result)
+	assert.Equal(t, "mkoepf/two", results[2].Result[0]["package"])
+}
+
+func TestRunForRefs_ContinuesAfterFailure(t *testing.T) {
+	t.Parallel()
+
+	t.Run("table", func(t *testing.T) {
+		t.Parallel()
+		cmd, out, errOut := newStdinTestCmd("mkoepf/bad\nmkoepf/good\n")
+
+		err := runForRefs(cmd, []string{"-"}, false, func(ref string, w io.Writer) error {
+			if ref == "mkoepf/bad" {
+				return fmt.Errorf("package not found")
+			}
+			fmt.Fprintf(w, "versions of %s\n", ref)
+			return nil
+		})
+		require.Error(t, err)
+		assert.Equal(t, "failed for 1 of 2 package(s)", err.Error())
+		assert.Contains(t, out.String(), "versions of mkoepf/good")
+		assert.Contains(t, errOut.String(), "mkoepf/bad: package not found")
+	})
+
+	t.Run("json", func(t *testing.T) {
+		t.Parallel()
+		cmd, out, _ := newStdinTestCmd("mkoepf/bad\nmkoepf/good\n")
+
+		err := runForRefs(cmd, []string{"-"}, true, func(ref string, w io.Writer) error {
+			if ref == "mkoepf/bad" {
+				return fmt.Errorf("package not found")
+			}
+			fmt.Fprintln(w, `{"ok":true}`)
+			return nil
+		})
+		require.Error(t, err)
+
+		var results []stdinRefResult
+		require.NoError(t, json.Unmarshal(out.Bytes(), &results))
+		require.Len(t, results, 2)
+		assert.Equal(t, "package not found", results[0].Error)
+		assert.JSONEq(t, `{"ok":true}`, string(results[1].Result))
+	})
+}
+
+func TestListVersionsCmd_StdinRejectsWatch(t *testing.T) {
+	t.Parallel()
+	cmd := NewRootCmd()
+	cmd.SetArgs([]string{"list", "versions", "-", "--watch"})
+	cmd.SetIn(strings.NewReader("mkoepf/one\n"))
+	cmd.SetOut(new(bytes.Buffer))
+	cmd.SetErr(new(bytes.Buffer))
+
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "--watch cannot be used with package references from stdin")
+}
+
+func TestListVersionsCmd_StdinReportsInvalidRefs(t *testing.T) {
+	t.Parallel()
+	cmd := NewRootCmd()
+	errOut := new(bytes.Buffer)
+	cmd.SetArgs([]string{"list", "versions", "-"})
+	cmd.SetIn(strings.NewReader("not-a-ref\nalso-bad\n"))
+	cmd.SetOut(new(bytes.Buffer))
+	cmd.SetErr(errOut)
+
+	err := cmd.Execute()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed for 2 of 2 package(s)")
+	assert.Contains(t, errOut.String(), "not-a-ref:")
+	assert.Contains(t, errOut.String(), "also-bad:")
+}
+
+func TestWithStdinRefs(t *testing.T) {
+	t.Parallel()
+	cmd, out, _ := newStdinTestCmd("mkoepf/one\nmkoepf/two\n")
+
+	runE := withStdinRefs(func() bool { return true }, func(cmd *cobra.Command, args []string) error {
+		fmt.Fprintf(cmd.OutOrStdout(), `{"package": %q}`, args[0])
+		return nil
+	})
+	require.NoError(t, runE(cmd, []string{"-"}))
+
+	var results []stdinRefResult
+	require.NoError(t, json.Unmarshal(out.Bytes(), &results), out.String())
+	require.Len(t, results, 2)
+	assert.JSONEq(t, `{"package": "mkoepf/two"}`, string(results[1].Result))
+	assert.Same(t, out, cmd.OutOrStdout(), "the output is restored afterwards")
+
+	// A single reference is run directly
+	out.Reset()
+	require.NoError(t, runE(cmd, []string{"mkoepf/three"}))
+	assert.Equal(t, `{"package": "mkoepf/three"}`, out.String())
+}
@@ -17,6 +17,7 @@ func newTagCmd() *cobra.Command {
 		sourceTag       string
 		sourceDigest    string
 		sourceVersionID int64
+		jsonOutput      bool
 	)
 
 	cmd := &cobra.Command{
@@ -43,11 +44,14 @@ Examples:
   ghcrctl tag mkoepf/myimage stable --version 12345678
 
   # Tag by digest (short form supported)
-  ghcrctl tag mkoepf/myimage stable --digest abc123`,
+  ghcrctl tag mkoepf/myimage stable --digest abc123
+
+  # Capture the promoted digest for pinning
+  ghcrctl tag mkoepf/myimage production --tag v2.1.0 --json | jq -r .digest`,
 		Args: cobra.ExactArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			// Parse owner/package reference (reject inline tags)
-			owner, packageName, err := parsePackageRef(args[0])
+			owner, packageName, err := parsePackageRef(args[0], defaultOwner(cmd))
 			if err != nil {
 				cmd.SilenceUsage = true
 				return err
@@ -66,17 +70,17 @@ Examples:
 
 			ctx := cmd.Context()
 
-			var targetDigest string
-			var selectorDesc string
+			params := tagAddParams{
+				Owner:       owner,
+				PackageName: packageName,
+				NewTag:      newTag,
+				SourceTag:   sourceTag,
+				JSONOutput:  jsonOutput,
+			}
 
-			if sourceTag != "" {
-				targetDigest, err = discover.ResolveTag(ctx, fullImage, sourceTag)
-				if err != nil {
-					cmd.SilenceUsage = true
-					return fmt.Errorf("failed to resolve source tag '%s': %w", sourceTag, err)
-				}
-				selectorDesc = sourceTag
-			} else if sourceVersionID != 0 || sourceDigest != "" {
+			// A source tag is resolved by executeTagAdd; version IDs and short
+			// digests need the package's versions
+			if sourceTag == "" {
 				// Need to fetch versions to resolve version ID or short digest
 				token, err := gh.GetToken()
 				if err != nil {
@@ -108,42 +112,37 @@ Examples:
 					cmd.SilenceUsage = true
 					return fmt.Errorf("failed to discover package: %w", err)
 				}
+				printDiscoveryWarnings(ctx, cmd.ErrOrStderr(), versions)
 
 				versionMap := discover.ToMap(versions)
 
 				if sourceVersionID != 0 {
-					targetDigest, err = discover.FindDigestByVersionID(versionMap, sourceVersionID)
+					params.SourceDigest, err = discover.FindDigestByVersionID(versionMap, sourceVersionID)
 					if err != nil {
 						cmd.SilenceUsage = true
 						return fmt.Errorf("failed to find version ID %d: %w", sourceVersionID, err)
 					}
-					selectorDesc = fmt.Sprintf("version %d", sourceVersionID)
+					params.Selector = fmt.Sprintf("version %d", sourceVersionID)
 				} else {
-					targetDigest, err = discover.FindDigestByShortDigest(versionMap, sourceDigest)
+					params.SourceDigest, err = discover.FindDigestByShortDigest(versionMap, sourceDigest)
 					if err != nil {
 						cmd.SilenceUsage = true
 						return fmt.Errorf("failed to find digest '%s': %w", sourceDigest, err)
 					}
-					selectorDesc = display.ShortDigest(targetDigest)
+					params.Selector = display.ShortDigest(params.SourceDigest)
 				}
 			}
 
 			// Add the new tag (creates new tag pointing to same digest)
-			err = discover.AddTagByDigest(ctx, fullImage, targetDigest, newTag)
-			if err != nil {
-				cmd.SilenceUsage = true
-				return fmt.Errorf("failed to add tag: %w", err)
-			}
-
-			// Display success message
-			fmt.Fprintf(cmd.OutOrStdout(), "Successfully added tag '%s' to %s (source: %s)\n", newTag, packageName, selectorDesc)
-			return nil
+			cmd.SilenceUsage = true
+			return executeTagAdd(ctx, orasTagAdder{}, params, cmd.OutOrStdout())
 		},
 	}
 
 	cmd.Flags().StringVar(&sourceTag, "tag", "", "Source version by tag")
 	cmd.Flags().StringVar(&sourceDigest, "digest", "", "Source version by digest (supports short form)")
 	cmd.Flags().Int64Var(&sourceVersionID, "version", 0, "Source version by ID")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output the tagged digest in JSON format")
 	cmd.MarkFlagsMutuallyExclusive("tag", "digest", "version")
 
 	return cmd
@@ -152,7 +151,18 @@ Examples:
 // tagAdder is an interface for tag add operations
 type tagAdder interface {
 	ResolveTag(ctx context.Context, fullImage, tag string) (string, error)
-	AddTagByDigest(ctx context.Context, fullImage, digest, newTag string) error
+	AddTagByDigest(ctx context.Context, fullImage, digest, newTag string) (string, error)
+}
+
+// orasTagAdder adds tags using the discover package
+type orasTagAdder struct{}
+
+func (orasTagAdder) ResolveTag(ctx context.Context, fullImage, tag string) (string, error) {
+	return discover.ResolveTag(ctx, fullImage, tag)
+}
+
+func (orasTagAdder) AddTagByDigest(ctx context.Context, fullImage, digest, newTag string) (string, error) {
+	return discover.AddTagByDigest(ctx, fullImage, digest, newTag)
 }
 
 // tagAddParams contains parameters for tag add execution
@@ -162,6 +172,16 @@ type tagAddParams struct {
 	NewTag       string
 	SourceTag    string
 	SourceDigest string
+	Selector     string // Source shown in the success message; defaults to the source tag or digest
+	JSONOutput   bool
+}
+
+// tagAddResult is the JSON output of the tag command
+type tagAddResult struct {
+	Package   string `json:"package"`
+	SourceTag string `json:"source_tag,omitempty"`
+	DestTag   string `json:"dest_tag"`
+	Digest    string `json:"digest"`
 }
 
 // executeTagAdd executes the tag add logic with injected dependencies
@@ -179,16 +199,28 @@ func executeTagAdd(ctx context.Context, adder tagAdder, params tagAddParams, out
 	}
 
 	// Add the new tag
-	err := adder.AddTagByDigest(ctx, fullImage, targetDigest, params.NewTag)
+	taggedDigest, err := adder.AddTagByDigest(ctx, fullImage, targetDigest, params.NewTag)
 	if err != nil {
 		return fmt.Errorf("failed to add tag: %w", err)
 	}
 
+	if params.JSONOutput {
+		return display.OutputJSON(ctx, out, tagAddResult{
+			Package:   params.Owner + "/" + params.PackageName,
+			SourceTag: params.SourceTag,
+			DestTag:   params.NewTag,
+			Digest:    taggedDigest,
+		})
+	}
+
 	// Display success message
-	if params.SourceTag != "" {
-		fmt.Fprintf(out, "Successfully added tag '%s' to %s (source: %s)\n", params.NewTag, params.PackageName, params.SourceTag)
-	} else {
-		fmt.Fprintf(out, "Successfully added tag '%s' to %s (source: %s)\n", params.NewTag, params.PackageName, params.SourceDigest[:19])
+	selector := params.Selector
+	if selector == "" {
+		selector = params.SourceTag
+	}
+	if selector == "" {
+		selector = params.SourceDigest[:min(19, len(params.SourceDigest))]
 	}
+	fmt.Fprintf(out, "Successfully added tag '%s' to %s (source: %s)\n", params.NewTag, params.PackageName, selector)
 	return nil
 }
@@ -54,8 +54,9 @@ func TestAddTag_Mutating(t *testing.T) {
 	// Add a new tag using AddTagByDigest
 	newTag := "test-tag-v1"
 	t.Logf("Adding tag %s to %s", newTag, ephemeralImage)
-	err = discover.AddTagByDigest(ctx, ephemeralImage, digest, newTag)
+	taggedDigest, err := discover.AddTagByDigest(ctx, ephemeralImage, digest, newTag)
 	require.NoError(t, err, "Failed to add tag")
+	assert.Equal(t, digest, taggedDigest, "AddTagByDigest returned a different digest")
 
 	// Verify the new tag resolves to the same digest
 	resolvedDigest, err := discover.ResolveTag(ctx, ephemeralImage, newTag)
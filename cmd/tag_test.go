@@ -3,6 +3,7 @@ package cmd
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"testing"
 
@@ -101,8 +102,11 @@ func (m *mockTagAdder) ResolveTag(ctx context.Context, fullImage, tag string) (s
 	return m.resolvedDigest, nil
 }
 
-func (m *mockTagAdder) AddTagByDigest(ctx context.Context, fullImage, digest, newTag string) error {
-	return m.addErr
+func (m *mockTagAdder) AddTagByDigest(ctx context.Context, fullImage, digest, newTag string) (string, error) {
+	if m.addErr != nil {
+		return "", m.addErr
+	}
+	return digest, nil
 }
 
 func TestExecuteTagAdd(t *testing.T) {
@@ -200,3 +204,68 @@ func TestExecuteTagAdd(t *testing.T) {
 		})
 	}
 }
+
+func TestExecuteTagAdd_JSON(t *testing.T) {
+	t.Parallel()
+
+	t.Run("source tag", func(t *testing.T) {
+		t.Parallel()
+		mock := &mockTagAdder{resolvedDigest: "sha256:abc123def456789"}
+
+		var buf bytes.Buffer
+		err := ExecuteTagAdd(context.Background(), mock, TagAddParams{
+			Owner:       "testowner",
+			PackageName: "testimage",
+			NewTag:      "production",
+			SourceTag:   "v2.1.0",
+			JSONOutput:  true,
+		}, &buf)
+		require.NoError(t, err)
+
+		var result map[string]string
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &result))
+		assert.Equal(t, map[string]string{
+			"package":    "testowner/testimage",
+			"source_tag": "v2.1.0",
+			"dest_tag":   "production",
+			"digest":     "sha256:abc123def456789",
+		}, result)
+	})
+
+	t.Run("source digest", func(t *testing.T) {
+		t.Parallel()
+		mock := &mockTagAdder{}
+
+		var buf bytes.Buffer
+		err := ExecuteTagAdd(context.Background(), mock, TagAddParams{
+			Owner:        "testowner",
+			PackageName:  "testimage",
+			NewTag:       "stable",
+			SourceDigest: "sha256:abc123def456789",
+			Selector:     "version 42",
+			JSONOutput:   true,
+		}, &buf)
+		require.NoError(t, err)
+
+		var result map[string]string
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &result))
+		assert.Equal(t, "sha256:abc123def456789", result["digest"])
+		assert.NotContains(t, result, "source_tag")
+		assert.NotContains(t, buf.String(), "Successfully")
+	})
+}
+
+func TestExecuteTagAdd_Selector(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	err := ExecuteTagAdd(context.Background(), &mockTagAdder{}, TagAddParams{
+		Owner:        "testowner",
+		PackageName:  "testimage",
+		NewTag:       "stable",
+		SourceDigest: "sha256:abc123def456789",
+		Selector:     "version 42",
+	}, &buf)
+	require.NoError(t, err)
+	assert.Equal(t, "Successfully added tag 'stable' to testimage (source: version 42)\n", buf.String())
+}
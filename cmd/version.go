@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+
+	"github.com/mkoepf/ghcrctl/internal/config"
+	"github.com/mkoepf/ghcrctl/internal/display"
+	"github.com/spf13/cobra"
+)
+
+// newVersionCmd creates the version command.
+func newVersionCmd() *cobra.Command {
+	var (
+		jsonOutput   bool
+		outputFormat string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "version",
+		Short: "Show the version and build information",
+		Long: `Show the version of ghcrctl with the commit and date it was built from, the
+Go version and platform, the registry, and the location of the config file.
+Include it when reporting a bug.
+
+Examples:
+  # Show the version and build information
+  ghcrctl version
+
+  # Read the build information from a script
+  ghcrctl version --json`,
+		Args:        cobra.NoArgs,
+		Annotations: map[string]string{skipProfileAnnotation: "true"},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			mode, err := display.ParseOutputMode(outputFormat, display.OutputModeJSON, display.OutputModeTable)
+			if err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
+			switch mode {
+			case display.OutputModeJSON:
+				jsonOutput = true
+			case display.OutputModeTable:
+				jsonOutput = false
+			}
+
+			// A missing home directory must not keep the version from being shown
+			configPath, err := config.Path()
+			if err != nil {
+				configPath = ""
+			}
+
+			info := newVersionInfo(configPath)
+			cmd.SilenceUsage = true
+			if jsonOutput {
+				return display.OutputJSON(cmd.Context(), cmd.OutOrStdout(), info)
+			}
+			return outputVersionInfo(cmd.OutOrStdout(), info)
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Output in JSON format")
+	addOutputFlag(cmd, &outputFormat, display.OutputModeJSON, display.OutputModeTable)
+
+	return cmd
+}
+
+// versionInfo is the build information shown by the version command
+type versionInfo struct {
+	Version    string `json:"version"`
+	Commit     string `json:"commit"`
+	BuildDate  string `json:"build_date"`
+	GoVersion  string `json:"go_version"`
+	Platform   string `json:"platform"`
+	Registry   string `json:"registry"`
+	ConfigFile string `json:"config_file"`
+}
+
+// newVersionInfo returns the build information set via ldflags, with the
+// config file at configPath
+func newVersionInfo(configPath string) versionInfo {
+	return versionInfo{
+		Version:    Version,
+		Commit:     Commit,
+		BuildDate:  Date,
+		GoVersion:  runtime.Version(),
+		Platform:   runtime.GOOS + "/" + runtime.GOARCH,
+		Registry:   registryHost,
+		ConfigFile: configPath,
+	}
+}
+
+func outputVersionInfo(w io.Writer, info versionInfo) error {
+	configFile := info.ConfigFile
+	if configFile == "" {
+		configFile = "(unknown)"
+	}
+
+	fmt.Fprintf(w, "ghcrctl %s\n\n", info.Version)
+	fmt.Fprintf(w, "%-12s %s\n", "Commit:", info.Commit)
+	fmt.Fprintf(w, "%-12s %s\n", "Built:", info.BuildDate)
+	fmt.Fprintf(w, "%-12s %s\n", "Go version:", info.GoVersion)
+	fmt.Fprintf(w, "%-12s %s\n", "Platform:", info.Platform)
+	fmt.Fprintf(w, "%-12s %s\n", "Registry:", info.Registry)
+	fmt.Fprintf(w, "%-12s %s\n", "Config file:", configFile)
+	return nil
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutputVersionInfo(t *testing.T) {
+	t.Parallel()
+	info := versionInfo{
+		Version:    "v1.2.3",
+		Commit:     "0123abc",
+		BuildDate:  "2026-01-02T03:04:05Z",
+		GoVersion:  "go1.25.0",
+		Platform:   "linux/arm64",
+		Registry:   "ghcr.io",
+		ConfigFile: "/home/me/.config/ghcrctl/config.json",
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, outputVersionInfo(&buf, info))
+	assert.Equal(t, `ghcrctl v1.2.3
+
+Commit:      0123abc
+Built:       2026-01-02T03:04:05Z
+Go version:  go1.25.0
+Platform:    linux/arm64
+Registry:    ghcr.io
+Config file: /home/me/.config/ghcrctl/config.json
+`, buf.String())
+
+	buf.Reset()
+	info.ConfigFile = ""
+	require.NoError(t, outputVersionInfo(&buf, info))
+	assert.Contains(t, buf.String(), "Config file: (unknown)")
+}
+
+func TestVersionCmd_JSON(t *testing.T) {
+	t.Parallel()
+	rootCmd := NewRootCmd()
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetErr(new(bytes.Buffer))
+	rootCmd.SetArgs([]string{"version", "--json"})
+	require.NoError(t, rootCmd.Execute())
+
+	var info versionInfo
+	require.NoError(t, json.Unmarshal(out.Bytes(), &info))
+	assert.Equal(t, Version, info.Version)
+	assert.Equal(t, Commit, info.Commit)
+	assert.Equal(t, Date, info.BuildDate)
+	assert.Equal(t, runtime.Version(), info.GoVersion)
+	assert.Equal(t, registryHost, info.Registry)
+}
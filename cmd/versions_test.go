@@ -2,8 +2,14 @@ package cmd
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/mkoepf/ghcrctl/internal/discover"
+	"github.com/mkoepf/ghcrctl/internal/display"
 	"github.com/mkoepf/ghcrctl/internal/gh"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -22,6 +28,8 @@ func TestBuildVersionFilter(t *testing.T) {
 		newerThan    string
 		versionID    int64
 		digest       string
+		afterID      int64
+		beforeID     int64
 		wantErr      bool
 		errContains  string
 	}{
@@ -82,14 +90,33 @@ func TestBuildVersionFilter(t *testing.T) {
 			digest:  "sha256:abc123",
 			wantErr: false,
 		},
+		{
+			name:     "version ID range",
+			afterID:  100,
+			beforeID: 102,
+			wantErr:  false,
+		},
+		{
+			name:        "negative after-id",
+			afterID:     -1,
+			wantErr:     true,
+			errContains: "--after-id must be a positive version ID",
+		},
+		{
+			name:        "before-id not above after-id",
+			afterID:     200,
+			beforeID:    100,
+			wantErr:     true,
+			errContains: "leave no version IDs in between",
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			filter, err := buildListVersionFilter(
-				tt.tag, tt.tagPattern, tt.onlyTagged, tt.onlyUntagged,
-				tt.olderThan, tt.newerThan,
-				tt.versionID, tt.digest,
+				tt.tag, tt.tagPattern, "", "", tt.onlyTagged, tt.onlyUntagged,
+				tt.olderThan, tt.newerThan, time.UTC,
+				tt.versionID, tt.digest, tt.afterID, tt.beforeID,
 			)
 
 			if tt.wantErr {
@@ -198,7 +225,7 @@ func TestOutputListVersionsTableQuietMode(t *testing.T) {
 
 	// Normal mode should include header and summary
 	var normalBuf bytes.Buffer
-	err := OutputVersionsTable(&normalBuf, versions, "testpkg", false)
+	err := OutputVersionsTable(&normalBuf, versions, "testpkg", false, false, false)
 	require.NoError(t, err, "unexpected error")
 	normalOutput := normalBuf.String()
 	assert.Contains(t, normalOutput, "Versions for testpkg", "normal mode should include 'Versions for' header")
@@ -206,7 +233,7 @@ func TestOutputListVersionsTableQuietMode(t *testing.T) {
 
 	// Quiet mode should NOT include header or summary
 	var quietBuf bytes.Buffer
-	err = OutputVersionsTable(&quietBuf, versions, "testpkg", true)
+	err = OutputVersionsTable(&quietBuf, versions, "testpkg", false, false, true)
 	require.NoError(t, err, "unexpected error")
 	quietOutput := quietBuf.String()
 	assert.NotContains(t, quietOutput, "Versions for testpkg", "quiet mode should NOT include 'Versions for' header")
@@ -214,3 +241,526 @@ func TestOutputListVersionsTableQuietMode(t *testing.T) {
 	// But should still have data
 	assert.Contains(t, quietOutput, "123", "quiet mode should still include version ID")
 }
+
+func TestOutputListVersionsTableShowURL(t *testing.T) {
+	t.Parallel()
+	versions := []gh.PackageVersionInfo{
+		{ID: 123, Digest: "sha256:abc123", Tags: []string{"v1.0.0"}, CreatedAt: "2025-01-01 10:00:00",
+			HTMLURL: "https://github.com/users/mkoepf/packages/container/myimage/123"},
+		{ID: 124, Digest: "sha256:def456", CreatedAt: "2025-01-02 10:00:00"},
+	}
+
+	var withoutURL bytes.Buffer
+	require.NoError(t, OutputVersionsTable(&withoutURL, versions, "testpkg", false, false, true))
+	assert.NotContains(t, withoutURL.String(), "URL")
+	assert.NotContains(t, withoutURL.String(), "https://github.com")
+
+	var withURL bytes.Buffer
+	require.NoError(t, OutputVersionsTable(&withURL, versions, "testpkg", true, false, true))
+	lines := strings.Split(strings.TrimSpace(withURL.String()), "\n")
+	require.Len(t, lines, 4)
+	assert.Contains(t, lines[0], "URL")
+	assert.True(t, strings.HasSuffix(lines[2], "  https://github.com/users/mkoepf/packages/container/myimage/123"), lines[2])
+	assert.True(t, strings.HasSuffix(lines[3], "  -"), "versions without a URL should show a dash")
+}
+
+func TestOutputListVersionsTableRightAlignsIDs(t *testing.T) {
+	t.Parallel()
+	versions := []gh.PackageVersionInfo{
+		{ID: 585861918, Digest: "sha256:abc123", CreatedAt: "2025-01-01"},
+		{ID: 42, Digest: "sha256:def456", CreatedAt: "2025-01-02"},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, OutputVersionsTable(&buf, versions, "testpkg", false, false, true))
+
+	lines := strings.Split(buf.String(), "\n")
+	assert.True(t, strings.HasPrefix(lines[0], "  VERSION ID  "), lines[0])
+	assert.True(t, strings.HasPrefix(lines[2], "   585861918  "), lines[2])
+	assert.True(t, strings.HasPrefix(lines[3], "          42  "), lines[3])
+}
+
+func TestOutputListVersionsTableAlignsWideTags(t *testing.T) {
+	t.Parallel()
+	versions := []gh.PackageVersionInfo{
+		{ID: 1, Digest: "sha256:aaa", Tags: []string{"v1.0.0"}, CreatedAt: "2025-01-01 10:00:00"},
+		{ID: 2, Digest: "sha256:bbb", Tags: []string{"リリース"}, CreatedAt: "2025-01-02 10:00:00"},
+		{ID: 3, Digest: "sha256:ccc", Tags: []string{"🚀-launch"}, CreatedAt: "2025-01-03 10:00:00"},
+		{ID: 4, Digest: "sha256:ddd", CreatedAt: "2025-01-04 10:00:00"},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, OutputVersionsTable(&buf, versions, "testpkg", false, false, true))
+
+	// The CREATED column must start at the same display column on every row
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	require.Len(t, lines, 6)
+	want := display.Width(lines[0][:strings.Index(lines[0], "CREATED")])
+	for _, line := range lines[2:] {
+		idx := strings.Index(line, "2025-")
+		require.NotEqual(t, -1, idx, line)
+		assert.Equal(t, want, display.Width(line[:idx]), "misaligned row: %q", line)
+	}
+}
+
+func TestOutputListVersionsTableRelativeTime(t *testing.T) {
+	t.Parallel()
+	versions := []gh.PackageVersionInfo{
+		{ID: 1, Digest: "sha256:aaa", CreatedAt: time.Now().UTC().Add(-3 * 24 * time.Hour).Format("2006-01-02 15:04:05"),
+			HTMLURL: "https://github.com/users/mkoepf/packages/container/myimage/1"},
+		{ID: 2, Digest: "sha256:bbb", CreatedAt: "not a date"},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, OutputVersionsTable(&buf, versions, "testpkg", true, true, true))
+
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	require.Len(t, lines, 4)
+	assert.Contains(t, lines[2], "3 days ago")
+	assert.Contains(t, lines[3], "not a date", "unparseable timestamps are shown as is")
+	assert.Equal(t, strings.Index(lines[0], "URL"), strings.Index(lines[2], "https://"), "URL column should stay aligned")
+}
+
+func TestFilterVersionsByType(t *testing.T) {
+	t.Parallel()
+	versions := []gh.PackageVersionInfo{
+		{ID: 1, Digest: "sha256:index"},
+		{ID: 2, Digest: "sha256:amd64"},
+		{ID: 3, Digest: "sha256:attest"},
+		{ID: 4, Digest: "sha256:sig"},
+	}
+	discovered := []discover.VersionInfo{
+		{ID: 1, Digest: "sha256:index", Types: []string{"index"}},
+		{ID: 2, Digest: "sha256:amd64", Types: []string{"linux/amd64"}},
+		{ID: 3, Digest: "sha256:attest", Types: []string{"sbom", "provenance"}},
+		{ID: 4, Digest: "sha256:sig", Types: []string{"signature"}},
+	}
+
+	ids := func(vs []gh.PackageVersionInfo) []int64 {
+		var result []int64
+		for _, v := range vs {
+			result = append(result, v.ID)
+		}
+		return result
+	}
+
+	assert.Equal(t, []int64{1, 2}, ids(filterVersionsByType(versions, discovered, []string{"index", "platform"}, nil, nil)))
+	assert.Equal(t, []int64{1, 2}, ids(filterVersionsByType(versions, discovered, nil, []string{"sbom", "signature"}, nil)))
+	assert.Equal(t, []int64{3}, ids(filterVersionsByType(versions, discovered, []string{"sbom"}, nil, nil)))
+	assert.Empty(t, filterVersionsByType(versions, discovered, []string{"sbom"}, []string{"provenance"}, nil))
+}
+
+func TestFilterVersionsByType_MediaType(t *testing.T) {
+	t.Parallel()
+	const (
+		ociIndex   = "application/vnd.oci.image.index.v1+json"
+		dockerList = "application/vnd.docker.distribution.manifest.list.v2+json"
+	)
+	versions := []gh.PackageVersionInfo{
+		{ID: 1, Digest: "sha256:oci"},
+		{ID: 2, Digest: "sha256:docker"},
+		{ID: 3, Digest: "sha256:amd64"},
+	}
+	discovered := []discover.VersionInfo{
+		{ID: 1, Digest: "sha256:oci", Types: []string{"index"}, MediaType: ociIndex},
+		{ID: 2, Digest: "sha256:docker", Types: []string{"index"}, MediaType: dockerList},
+		{ID: 3, Digest: "sha256:amd64", Types: []string{"linux/amd64"}, MediaType: "application/vnd.docker.distribution.manifest.v2+json"},
+	}
+
+	ids := func(vs []gh.PackageVersionInfo) []int64 {
+		var result []int64
+		for _, v := range vs {
+			result = append(result, v.ID)
+		}
+		return result
+	}
+
+	assert.Equal(t, []int64{2}, ids(filterVersionsByType(versions, discovered, nil, nil, []string{dockerList})))
+	assert.Equal(t, []int64{1, 2}, ids(filterVersionsByType(versions, discovered, nil, nil, []string{ociIndex, dockerList})))
+	assert.Equal(t, []int64{1}, ids(filterVersionsByType(versions, discovered, []string{"index"}, nil, []string{ociIndex})))
+	assert.Empty(t, filterVersionsByType(versions, discovered, []string{"platform"}, nil, []string{ociIndex}))
+}
+
+func TestListVersionsCmd_TypeRejectsWatch(t *testing.T) {
+	t.Parallel()
+	cmd := NewRootCmd()
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"list", "versions", "owner/pkg", "--watch", "--type", "index"})
+
+	err := cmd.Execute()
+	assert.ErrorContains(t, err, "cannot be used with --watch")
+}
+
+func TestFindVersionIDByTag(t *testing.T) {
+	t.Parallel()
+	versions := []gh.PackageVersionInfo{
+		{ID: 100, Tags: []string{"v0.9.0"}},
+		{ID: 105, Tags: []string{"v1.0.0", "stable"}},
+		{ID: 110},
+	}
+
+	id, err := findVersionIDByTag(versions, "stable")
+	require.NoError(t, err)
+	assert.Equal(t, int64(105), id)
+
+	_, err = findVersionIDByTag(versions, "v2.0.0")
+	assert.ErrorContains(t, err, `tag "v2.0.0" not found`)
+}
+
+func TestNewestVersionPerTag(t *testing.T) {
+	t.Parallel()
+	// latest moved from 100 to 120; v1.0.0 stayed on 100
+	versions := []gh.PackageVersionInfo{
+		{ID: 120, Digest: "sha256:c", Tags: []string{"latest", "v1.1.0"}},
+		{ID: 110, Digest: "sha256:b", Tags: []string{"latest"}},
+		{ID: 105, Digest: "sha256:u"},
+		{ID: 100, Digest: "sha256:a", Tags: []string{"v1.0.0", "latest"}},
+	}
+
+	collapsed := newestVersionPerTag(versions)
+	assert.Equal(t, []string{"latest", "v1.1.0"}, collapsed[0].Tags)
+	assert.Empty(t, collapsed[1].Tags, "latest moved on, so 110 no longer carries a tag")
+	assert.Empty(t, collapsed[2].Tags)
+	assert.Equal(t, []string{"v1.0.0"}, collapsed[3].Tags)
+	assert.Equal(t, []string{"latest"}, versions[1].Tags, "input must not be modified")
+
+	vf, err := buildListVersionFilter("latest", "", "", "", false, false, "", "", time.UTC, 0, "", 0, 0)
+	require.NoError(t, err)
+	latest := vf.Apply(collapsed)
+	require.Len(t, latest, 1)
+	assert.Equal(t, int64(120), latest[0].ID)
+
+	vf, err = buildListVersionFilter("", "", "", "", true, false, "", "", time.UTC, 0, "", 0, 0)
+	require.NoError(t, err)
+	var ids []int64
+	for _, v := range vf.Apply(collapsed) {
+		ids = append(ids, v.ID)
+	}
+	assert.Equal(t, []int64{120, 100}, ids)
+}
+
+func TestBuildListVersionFilter_Timezone(t *testing.T) {
+	t.Parallel()
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	utc, err := buildListVersionFilter("", "", "", "", false, false, "2025-01-01", "", time.UTC, 0, "", 0, 0)
+	require.NoError(t, err)
+	local, err := buildListVersionFilter("", "", "", "", false, false, "2025-01-01", "", newYork, 0, "", 0, 0)
+	require.NoError(t, err)
+
+	// Pushed at 02:00 UTC: on the new day in UTC, on New Year's Eve in New York
+	versions := []gh.PackageVersionInfo{{ID: 1, CreatedAt: "2025-01-01T02:00:00Z"}}
+	assert.Empty(t, utc.Apply(versions))
+	assert.Len(t, local.Apply(versions), 1)
+}
+
+func TestApplyAgeFlags(t *testing.T) {
+	t.Parallel()
+	now := time.Now().UTC()
+	versions := []gh.PackageVersionInfo{
+		{ID: 3, CreatedAt: now.AddDate(0, 0, -10).Format(time.RFC3339)},
+		{ID: 2, CreatedAt: now.AddDate(0, 0, -60).Format(time.RFC3339)},
+		{ID: 1, CreatedAt: now.AddDate(0, 0, -120).Format(time.RFC3339)},
+	}
+	selected := func(t *testing.T, minAge, maxAge string) []int64 {
+		t.Helper()
+		var olderThan, newerThan string
+		require.NoError(t, applyAgeFlags(minAge, maxAge, &olderThan, &newerThan))
+		vf, err := buildListVersionFilter("", "", "", "", false, false, olderThan, newerThan, time.UTC, 0, "", 0, 0)
+		require.NoError(t, err)
+		var ids []int64
+		for _, v := range vf.Apply(versions) {
+			ids = append(ids, v.ID)
+		}
+		return ids
+	}
+
+	// --min-age keeps versions at least that old, --max-age at most that old
+	assert.Equal(t, []int64{2, 1}, selected(t, "30d", ""))
+	assert.Equal(t, []int64{3}, selected(t, "", "30d"))
+	assert.Equal(t, []int64{2}, selected(t, "30d", "90d"))
+
+	var olderThan, newerThan string
+	err := applyAgeFlags("2025-01-01", "", &olderThan, &newerThan)
+	assert.ErrorContains(t, err, "invalid --min-age")
+	err = applyAgeFlags("90d", "30d", &olderThan, &newerThan)
+	assert.EqualError(t, err, "--min-age 90d is not less than --max-age 30d, no version can match")
+}
+
+func TestSinceTag_FiltersVersionsAfterReference(t *testing.T) {
+	t.Parallel()
+	// Reference tag sits in the middle of the ID range
+	versions := []gh.PackageVersionInfo{
+		{ID: 120, Digest: "sha256:e", Tags: []string{"v1.1.0"}},
+		{ID: 115, Digest: "sha256:d"},
+		{ID: 110, Digest: "sha256:c", Tags: []string{"v1.0.0"}},
+		{ID: 105, Digest: "sha256:b"},
+		{ID: 100, Digest: "sha256:a", Tags: []string{"v0.9.0"}},
+	}
+
+	sinceID, err := findVersionIDByTag(versions, "v1.0.0")
+	require.NoError(t, err)
+
+	vf, err := buildListVersionFilter("", "", "", "", false, false, "", "", time.UTC, 0, "", 0, 0)
+	require.NoError(t, err)
+	vf.MinVersionID = sinceID
+
+	var ids []int64
+	for _, v := range vf.Apply(versions) {
+		ids = append(ids, v.ID)
+	}
+	assert.Equal(t, []int64{120, 115}, ids, "only versions pushed after v1.0.0 should remain, excluding v1.0.0 itself")
+}
+
+func TestUntaggedSafe_HidesChildrenOfTaggedImages(t *testing.T) {
+	t.Parallel()
+	// Multi-arch image latest with two platforms and an SBOM, plus an orphan
+	versions := []gh.PackageVersionInfo{
+		{ID: 1, Digest: "sha256:index", Tags: []string{"latest"}},
+		{ID: 2, Digest: "sha256:amd64"},
+		{ID: 3, Digest: "sha256:arm64"},
+		{ID: 4, Digest: "sha256:sbom"},
+		{ID: 5, Digest: "sha256:orphan"},
+	}
+	discovered := []discover.VersionInfo{
+		{ID: 1, Digest: "sha256:index", Tags: []string{"latest"}, Types: []string{"index"},
+			OutgoingRefs: []string{"sha256:amd64", "sha256:arm64"}},
+		{ID: 2, Digest: "sha256:amd64", Types: []string{"linux/amd64"},
+			IncomingRefs: []string{"sha256:index", "sha256:sbom"}},
+		{ID: 3, Digest: "sha256:arm64", Types: []string{"linux/arm64"}, IncomingRefs: []string{"sha256:index"}},
+		{ID: 4, Digest: "sha256:sbom", Types: []string{"sbom"}, OutgoingRefs: []string{"sha256:amd64"}},
+		{ID: 5, Digest: "sha256:orphan", Types: []string{"linux/amd64"}},
+	}
+	ids := func(vs []gh.PackageVersionInfo) []int64 {
+		var result []int64
+		for _, v := range vs {
+			result = append(result, v.ID)
+		}
+		return result
+	}
+
+	unsafe, err := buildListVersionFilter("", "", "", "", false, true, "", "", time.UTC, 0, "", 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{2, 3, 4, 5}, ids(unsafe.Apply(versions)), "children of latest are listed as untagged")
+
+	safe, err := buildListVersionFilter("", "", "", "", false, true, "", "", time.UTC, 0, "", 0, 0)
+	require.NoError(t, err)
+	safe.TaggedGraphMembers = discover.TaggedGraphMembers(discovered)
+	assert.Equal(t, []int64{5}, ids(safe.Apply(versions)), "only the orphan is listed")
+}
+
+func TestListVersionsCmd_SafeValidation(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{"requires untagged", []string{"--safe"}, "--safe requires --untagged"},
+		{"with tagged", []string{"--safe", "--tagged"}, "--safe requires --untagged"},
+		{"with watch", []string{"--safe", "--untagged", "--watch"}, "none of the others can be"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cmd := NewRootCmd()
+			cmd.SetArgs(append([]string{"list", "versions", "owner/pkg"}, tt.args...))
+			cmd.SetOut(new(bytes.Buffer))
+			cmd.SetErr(new(bytes.Buffer))
+
+			err := cmd.Execute()
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestListVersionsJSON_Query(t *testing.T) {
+	t.Parallel()
+	versions := []gh.PackageVersionInfo{
+		{ID: 12, Digest: "sha256:bbb", Tags: []string{"latest", "v1.1"}},
+		{ID: 11, Digest: "sha256:aaa", Tags: []string{"v1.0"}},
+		{ID: 10, Digest: "sha256:999"},
+	}
+	query := func(expr string) string {
+		q, err := display.ParseQuery(expr)
+		require.NoError(t, err)
+		ctx := display.WithJSONStyle(display.WithQuery(context.Background(), q), display.JSONStyleCompact)
+		var buf bytes.Buffer
+		require.NoError(t, display.OutputJSONFields(ctx, &buf, versions, nil))
+		return buf.String()
+	}
+
+	assert.Equal(t, "sha256:bbb\nsha256:aaa\nsha256:999\n", query("[].digest"), "field projection")
+	assert.Equal(t, "latest\nv1.1\nv1.0\n", query("[].tags[]"), "array flattening")
+	assert.Equal(t, "11\n", query("[?tags==v1.0].id"))
+	assert.Equal(t, "[\"latest\",\"v1.1\"]\n", query("[0].tags"))
+}
+
+func TestRootCmd_InvalidQuery(t *testing.T) {
+	t.Parallel()
+	cmd := NewRootCmd()
+	cmd.SetArgs([]string{"list", "versions", "owner/pkg", "--json", "--query", "[].digest|length"})
+	cmd.SetOut(new(bytes.Buffer))
+	cmd.SetErr(new(bytes.Buffer))
+
+	err := cmd.Execute()
+	assert.ErrorContains(t, err, "invalid --query")
+}
+
+func TestRootCmd_QueryRequiresJSON(t *testing.T) {
+	t.Parallel()
+	for _, args := range [][]string{
+		{"list", "versions", "owner/pkg", "--query", "[].digest"},
+		{"list", "graphs", "owner/pkg", "-o", "table", "--query", "[].digest"},
+		{"list", "versions", "owner/pkg", "--json=false", "--query", "[].digest"},
+	} {
+		cmd := NewRootCmd()
+		cmd.SetArgs(args)
+		cmd.SetOut(new(bytes.Buffer))
+		cmd.SetErr(new(bytes.Buffer))
+
+		err := cmd.Execute()
+		assert.EqualError(t, err, "--query requires JSON output (--json or -o json)", "args %v", args)
+	}
+
+	// -o json satisfies it, so the invalid expression is reported instead
+	cmd := NewRootCmd()
+	cmd.SetArgs([]string{"list", "versions", "owner/pkg", "-o", "json", "--query", "[].digest|length"})
+	cmd.SetOut(new(bytes.Buffer))
+	cmd.SetErr(new(bytes.Buffer))
+	assert.ErrorContains(t, cmd.Execute(), "invalid --query")
+}
+
+func TestListVersionsCmd_FieldsValidation(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{
+			name:    "requires JSON output",
+			args:    []string{"list", "versions", "mkoepf/myimage", "--fields", "id"},
+			wantErr: "--fields requires JSON output",
+		},
+		{
+			name:    "unknown version field",
+			args:    []string{"list", "versions", "mkoepf/myimage", "--json", "--fields", "id,name"},
+			wantErr: `invalid --fields: unknown field "name"`,
+		},
+		{
+			name:    "fields are checked against groups",
+			args:    []string{"list", "versions", "mkoepf/myimage", "--json", "--group-by", "month", "--fields", "tags"},
+			wantErr: `unknown field "tags" (valid: count, group, size)`,
+		},
+		{
+			name:    "unknown graph field",
+			args:    []string{"list", "graphs", "mkoepf/myimage", "-o", "json", "--fields", "digest,platform"},
+			wantErr: `invalid --fields: unknown field "platform"`,
+		},
+		{
+			name:    "graph fields exclude totals",
+			args:    []string{"list", "graphs", "mkoepf/myimage", "--json", "--fields", "digest", "--show-size-totals"},
+			wantErr: "none of the others can be",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cmd := NewRootCmd()
+			cmd.SetArgs(tt.args)
+			cmd.SetOut(new(bytes.Buffer))
+			cmd.SetErr(new(bytes.Buffer))
+
+			err := cmd.Execute()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestOutputVersionsWithContext(t *testing.T) {
+	t.Parallel()
+	versions := []gh.PackageVersionInfo{
+		{ID: 12, Digest: "sha256:bbb", Tags: []string{"latest"}, CreatedAt: "2025-01-02 10:00:00"},
+		{ID: 11, Digest: "sha256:aaa"},
+	}
+	ctx := display.WithJSONStyle(context.Background(), display.JSONStyleCompact)
+
+	t.Run("wraps the versions", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		require.NoError(t, outputVersionsWithContext(ctx, &buf, "myorg", "org", "myimage", versions, nil))
+
+		var got struct {
+			SchemaVersion int                      `json:"schema_version"`
+			Owner         string                   `json:"owner"`
+			Package       string                   `json:"package"`
+			OwnerType     string                   `json:"owner_type"`
+			Versions      []map[string]interface{} `json:"versions"`
+		}
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+		assert.Equal(t, display.SchemaVersion, got.SchemaVersion)
+		assert.Equal(t, "myorg", got.Owner)
+		assert.Equal(t, "myimage", got.Package)
+		assert.Equal(t, "org", got.OwnerType)
+		require.Len(t, got.Versions, 2)
+		assert.Equal(t, "sha256:bbb", got.Versions[0]["Digest"])
+	})
+
+	t.Run("fields apply to the versions", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		require.NoError(t, outputVersionsWithContext(ctx, &buf, "mkoepf", "user", "myimage", versions, []string{"id"}))
+		assert.JSONEq(t, `{"schema_version": 1, "owner": "mkoepf", "package": "myimage", "owner_type": "user", "versions": [{"ID": 12}, {"ID": 11}]}`, buf.String())
+	})
+
+	t.Run("no versions", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		require.NoError(t, outputVersionsWithContext(ctx, &buf, "mkoepf", "user", "myimage", []gh.PackageVersionInfo{}, nil))
+		assert.JSONEq(t, `{"schema_version": 1, "owner": "mkoepf", "package": "myimage", "owner_type": "user", "versions": []}`, buf.String())
+	})
+
+	t.Run("envelope keeps the object", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		require.NoError(t, outputVersionsWithContext(display.WithEnvelope(ctx), &buf, "mkoepf", "user", "myimage", []gh.PackageVersionInfo{}, nil))
+		assert.JSONEq(t, `{"schema_version": 1, "owner": "mkoepf", "package": "myimage", "owner_type": "user", "versions": []}`, buf.String())
+	})
+
+	t.Run("bare mode is unchanged", func(t *testing.T) {
+		t.Parallel()
+		var buf bytes.Buffer
+		require.NoError(t, display.OutputJSONFields(ctx, &buf, versions, []string{"id"}))
+		assert.JSONEq(t, `[{"ID": 12}, {"ID": 11}]`, buf.String())
+	})
+}
+
+func TestListVersionsCmd_WithContextValidation(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{"requires JSON output", []string{"--with-context"}, "--with-context requires --json"},
+		{"with duplicates", []string{"--json", "--with-context", "--duplicates"}, "none of the others can be"},
+		{"with group-by", []string{"--json", "--with-context", "--group-by", "month"}, "none of the others can be"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cmd := NewRootCmd()
+			cmd.SetArgs(append([]string{"list", "versions", "owner/pkg"}, tt.args...))
+			cmd.SetOut(new(bytes.Buffer))
+			cmd.SetErr(new(bytes.Buffer))
+
+			err := cmd.Execute()
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
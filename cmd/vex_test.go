@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/mkoepf/ghcrctl/internal/discover"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// attestationGraph returns a discovered graph with an index, a platform manifest
+// and one attestation of each role, all referenced from the index.
+func attestationGraph() map[string]discover.VersionInfo {
+	versions := []discover.VersionInfo{
+		{ID: 1, Digest: "sha256:index", Types: []string{"index"}, Tags: []string{"v1.0.0"},
+			OutgoingRefs: []string{"sha256:amd64", "sha256:sbom", "sha256:vex", "sha256:vulnscan"}},
+		{ID: 2, Digest: "sha256:amd64", Types: []string{"linux/amd64"}, IncomingRefs: []string{"sha256:index"}},
+		{ID: 3, Digest: "sha256:sbom", Types: []string{"sbom"}, IncomingRefs: []string{"sha256:index"}},
+		{ID: 4, Digest: "sha256:vex", Types: []string{"vex"}, IncomingRefs: []string{"sha256:index"}},
+		{ID: 5, Digest: "sha256:vulnscan", Types: []string{"vuln-scan"}, IncomingRefs: []string{"sha256:index"}},
+	}
+	return discover.ToMap(versions)
+}
+
+func TestGetVEXCommandStructure(t *testing.T) {
+	t.Parallel()
+	cmd := NewRootCmd()
+	vexCmd, _, err := cmd.Find([]string{"get", "vex"})
+	require.NoError(t, err, "Failed to find get vex command")
+
+	assert.Equal(t, "vex <owner/package>", vexCmd.Use)
+	assert.NotEmpty(t, vexCmd.Short)
+	assert.NotEmpty(t, vexCmd.Long)
+
+	for _, flagName := range []string{"tag", "digest", "version", "all", "json"} {
+		assert.NotNil(t, vexCmd.Flags().Lookup(flagName), "Expected flag '%s' to exist", flagName)
+	}
+}
+
+func TestFindArtifactsByRole_VEX(t *testing.T) {
+	t.Parallel()
+	versionMap := attestationGraph()
+
+	artifacts := findArtifactsByRole(versionMap, "sha256:index", "vex")
+	require.Len(t, artifacts, 1)
+	assert.Equal(t, "sha256:vex", artifacts[0].Digest)
+
+	// Selecting a sibling platform manifest finds the VEX through the containing graph
+	artifacts = findArtifactsByRole(versionMap, "sha256:amd64", "vex")
+	require.Len(t, artifacts, 1)
+	assert.Equal(t, int64(4), artifacts[0].ID)
+}
+
+func TestFindArtifactsByRole_ExcludesSelectedVersion(t *testing.T) {
+	t.Parallel()
+	versionMap := attestationGraph()
+
+	artifacts := findArtifactsByRole(versionMap, "sha256:vex", "vex")
+	assert.Empty(t, artifacts)
+}
+
+func TestFindArtifactsByRole_NoMatch(t *testing.T) {
+	t.Parallel()
+	versionMap := attestationGraph()
+
+	artifacts := findArtifactsByRole(versionMap, "sha256:index", "signature")
+	assert.Empty(t, artifacts)
+}
+
+// fakeArtifactFetch returns the document stored for a digest in docs and
+// records every fetched digest in fetched
+func fakeArtifactFetch(docs map[string]map[string]interface{}, fetched *[]string) artifactFetchFunc {
+	return func(ctx context.Context, image, digest string) ([]map[string]interface{}, error) {
+		*fetched = append(*fetched, digest)
+		doc, ok := docs[digest]
+		if !ok {
+			return nil, fmt.Errorf("manifest unknown: %s", digest)
+		}
+		return []map[string]interface{}{doc}, nil
+	}
+}
+
+var vexParams = getArtifactParams{Name: "vex", NoFoundMsg: "no VEX document found", Role: "vex"}
+
+func TestShowArtifacts_VEXInGraph(t *testing.T) {
+	t.Parallel()
+	var fetched []string
+	fetch := fakeArtifactFetch(map[string]map[string]interface{}{
+		"sha256:vex": {"@context": "https://openvex.dev/ns/v0.2.0", "author": "mkoepf"},
+	}, &fetched)
+
+	var buf bytes.Buffer
+	err := showArtifacts(&buf, context.Background(), fetch, vexParams, artifactOptions{}, attestationGraph(), artifactSelection{
+		Image: "ghcr.io/mkoepf/myimage", PackageName: "myimage", Digest: "sha256:index",
+		SelectorType: "tag", SelectorValue: "v1.0.0",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"sha256:vex"}, fetched)
+	output := buf.String()
+	assert.Contains(t, output, "Version v1.0.0 is not a vex. Searching in containing graph...")
+	assert.Contains(t, output, "Vex: ")
+	assert.Contains(t, output, `"@context": "https://openvex.dev/ns/v0.2.0"`)
+}
+
+func TestShowArtifacts_VEXSelectedDirectly(t *testing.T) {
+	t.Parallel()
+	var fetched []string
+	fetch := fakeArtifactFetch(map[string]map[string]interface{}{
+		"sha256:vex": {"@context": "https://openvex.dev/ns/v0.2.0"},
+	}, &fetched)
+
+	var buf bytes.Buffer
+	err := showArtifacts(&buf, context.Background(), fetch, vexParams, artifactOptions{JSON: true}, attestationGraph(), artifactSelection{
+		Image: "ghcr.io/mkoepf/myimage", PackageName: "myimage", Digest: "sha256:vex",
+		SelectorType: "version", SelectorValue: "4",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"sha256:vex"}, fetched)
+	var docs []map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &docs), "JSON output has no search message")
+	assert.Equal(t, "https://openvex.dev/ns/v0.2.0", docs[0]["@context"])
+}
+
+func TestShowArtifacts_NoVEX(t *testing.T) {
+	t.Parallel()
+	versionMap := discover.ToMap([]discover.VersionInfo{
+		{ID: 1, Digest: "sha256:index", Types: []string{"index"}, Tags: []string{"v1.0.0"}, OutgoingRefs: []string{"sha256:amd64", "sha256:sbom"}},
+		{ID: 2, Digest: "sha256:amd64", Types: []string{"linux/amd64"}, IncomingRefs: []string{"sha256:index"}},
+		{ID: 3, Digest: "sha256:sbom", Types: []string{"sbom"}, IncomingRefs: []string{"sha256:index"}},
+	})
+	var fetched []string
+
+	err := showArtifacts(io.Discard, context.Background(), fakeArtifactFetch(nil, &fetched), vexParams, artifactOptions{}, versionMap, artifactSelection{
+		Image: "ghcr.io/mkoepf/myimage", PackageName: "myimage", Digest: "sha256:index",
+		SelectorType: "tag", SelectorValue: "v1.0.0",
+	})
+	assert.EqualError(t, err, "no VEX document found for myimage (v1.0.0)")
+	assert.Empty(t, fetched)
+}
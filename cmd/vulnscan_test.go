@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/mkoepf/ghcrctl/internal/discover"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetVulnScanCommandStructure(t *testing.T) {
+	t.Parallel()
+	cmd := NewRootCmd()
+	scanCmd, _, err := cmd.Find([]string{"get", "vuln-scan"})
+	require.NoError(t, err, "Failed to find get vuln-scan command")
+
+	assert.Equal(t, "vuln-scan <owner/package>", scanCmd.Use)
+	assert.NotEmpty(t, scanCmd.Short)
+	assert.NotEmpty(t, scanCmd.Long)
+
+	for _, flagName := range []string{"tag", "digest", "version", "all", "json"} {
+		assert.NotNil(t, scanCmd.Flags().Lookup(flagName), "Expected flag '%s' to exist", flagName)
+	}
+}
+
+func TestFindArtifactsByRole_VulnScan(t *testing.T) {
+	t.Parallel()
+	versionMap := attestationGraph()
+
+	artifacts := findArtifactsByRole(versionMap, "sha256:index", "vuln-scan")
+	require.Len(t, artifacts, 1)
+	assert.Equal(t, "sha256:vulnscan", artifacts[0].Digest)
+}
+
+func TestListArtifacts_VulnScanExample(t *testing.T) {
+	t.Parallel()
+	artifacts := []discover.VersionInfo{
+		{Digest: "sha256:1111111111111111111111111111111111111111111111111111111111111111"},
+		{Digest: "sha256:2222222222222222222222222222222222222222222222222222222222222222"},
+	}
+
+	var buf bytes.Buffer
+	err := listArtifacts(&buf, artifacts, "myimage", "vuln-scan", "tag", "v1.0.0")
+	require.NoError(t, err)
+
+	output := buf.String()
+	assert.Contains(t, output, "Multiple vuln-scan documents found")
+	assert.Contains(t, output, "ghcrctl get vuln-scan myimage --digest 111111111111")
+}
+
+func TestFormatTree_LabelsVEXAndVulnScan(t *testing.T) {
+	t.Parallel()
+	versionMap := attestationGraph()
+
+	var buf bytes.Buffer
+	discover.FormatTree(&buf, []discover.VersionInfo{versionMap["sha256:index"]}, versionMap)
+
+	output := buf.String()
+	assert.Contains(t, output, "vex")
+	assert.Contains(t, output, "vuln-scan")
+}
+
+var vulnScanParams = getArtifactParams{Name: "vuln-scan", NoFoundMsg: "no vulnerability scan found", Role: "vuln-scan"}
+
+func TestShowArtifacts_VulnScanInGraph(t *testing.T) {
+	t.Parallel()
+	var fetched []string
+	fetch := fakeArtifactFetch(map[string]map[string]interface{}{
+		"sha256:vulnscan": {"predicateType": "https://cosign.sigstore.dev/attestation/vuln/v1"},
+	}, &fetched)
+
+	// Selecting the platform manifest finds the scan of its image
+	var buf bytes.Buffer
+	err := showArtifacts(&buf, context.Background(), fetch, vulnScanParams, artifactOptions{}, attestationGraph(), artifactSelection{
+		Image: "ghcr.io/mkoepf/myimage", PackageName: "myimage", Digest: "sha256:amd64",
+		SelectorType: "version", SelectorValue: "2",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"sha256:vulnscan"}, fetched)
+	output := buf.String()
+	assert.Contains(t, output, "Version 2 is not a vuln-scan. Searching in containing graph...")
+	assert.Contains(t, output, "Vuln-scan: ")
+	assert.Contains(t, output, "https://cosign.sigstore.dev/attestation/vuln/v1")
+}
+
+func TestShowArtifacts_AllVulnScans(t *testing.T) {
+	t.Parallel()
+	versionMap := attestationGraph()
+	index := versionMap["sha256:index"]
+	index.OutgoingRefs = append(index.OutgoingRefs, "sha256:vulnscan2")
+	versionMap["sha256:index"] = index
+	versionMap["sha256:vulnscan2"] = discover.VersionInfo{ID: 6, Digest: "sha256:vulnscan2", Types: []string{"vuln-scan"}, IncomingRefs: []string{"sha256:index"}}
+
+	var fetched []string
+	fetch := fakeArtifactFetch(map[string]map[string]interface{}{
+		"sha256:vulnscan":  {"scanner": "trivy"},
+		"sha256:vulnscan2": {"scanner": "grype"},
+	}, &fetched)
+
+	// Without --all, multiple scans are listed without fetching them
+	var buf bytes.Buffer
+	sel := artifactSelection{Image: "ghcr.io/mkoepf/myimage", PackageName: "myimage", Digest: "sha256:index", SelectorType: "tag", SelectorValue: "v1.0.0"}
+	require.NoError(t, showArtifacts(&buf, context.Background(), fetch, vulnScanParams, artifactOptions{}, versionMap, sel))
+	assert.Contains(t, buf.String(), "Multiple vuln-scan documents found in image tagged 'v1.0.0'")
+	assert.Empty(t, fetched)
+
+	buf.Reset()
+	require.NoError(t, showArtifacts(&buf, context.Background(), fetch, vulnScanParams, artifactOptions{All: true, JSON: true}, versionMap, sel))
+	assert.ElementsMatch(t, []string{"sha256:vulnscan", "sha256:vulnscan2"}, fetched)
+	var docs []struct {
+		Digest  string                   `json:"digest"`
+		Content []map[string]interface{} `json:"content"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &docs))
+	require.Len(t, docs, 2)
+}
+
+func TestShowArtifacts_NoVulnScan(t *testing.T) {
+	t.Parallel()
+	versionMap := discover.ToMap([]discover.VersionInfo{
+		{ID: 1, Digest: "sha256:amd64", Types: []string{"linux/amd64"}, Tags: []string{"v1.0.0"}},
+	})
+	var fetched []string
+
+	err := showArtifacts(io.Discard, context.Background(), fakeArtifactFetch(nil, &fetched), vulnScanParams, artifactOptions{}, versionMap, artifactSelection{
+		Image: "ghcr.io/mkoepf/myimage", PackageName: "myimage", Digest: "sha256:amd64",
+		SelectorType: "tag", SelectorValue: "v1.0.0",
+	})
+	assert.EqualError(t, err, "no vulnerability scan found for myimage (v1.0.0)")
+	assert.Empty(t, fetched)
+}
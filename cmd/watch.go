@@ -0,0 +1,249 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/mkoepf/ghcrctl/internal/display"
+	"github.com/mkoepf/ghcrctl/internal/filter"
+	"github.com/mkoepf/ghcrctl/internal/gh"
+)
+
+// watchClient lists the versions of a watched package and looks its owner
+// type up again when the cache is refreshed
+type watchClient interface {
+	versionLister
+	RefreshOwnerType(ctx context.Context, owner string) (string, error)
+}
+
+// watchParams contains parameters for watch mode execution
+type watchParams struct {
+	Owner       string
+	OwnerType   string
+	PackageName string
+	Interval    time.Duration
+	Filter      *filter.VersionFilter
+	JSONStream  bool
+	QuietMode   bool
+	Refresh     time.Duration    // Maximum age of the watch cache, 0 to never refresh it
+	Now         func() time.Time // Clock for the cache age, time.Now if nil
+}
+
+// watchCache is what a watch looks up once and then reuses between polls: the
+// owner type and the digest each tag points to. Once it is older than maxAge,
+// the owner type is looked up again, and the tags are compared with the
+// current listing to report tags that moved to another digest.
+type watchCache struct {
+	maxAge    time.Duration
+	ownerType string
+	tags      map[string]string
+	fetchedAt time.Time
+}
+
+// stale reports whether the cache is due for a refresh at now
+func (c *watchCache) stale(now time.Time) bool {
+	return c.maxAge > 0 && now.Sub(c.fetchedAt) >= c.maxAge
+}
+
+// movedTag is a tag that points to another digest than at the last refresh
+type movedTag struct {
+	Tag        string `json:"tag"`
+	FromDigest string `json:"from_digest"`
+	ToDigest   string `json:"to_digest"`
+}
+
+// tagDigests maps every tag of versions to the digest carrying it
+func tagDigests(versions []gh.PackageVersionInfo) map[string]string {
+	tags := make(map[string]string)
+	for _, ver := range versions {
+		for _, tag := range ver.Tags {
+			tags[tag] = ver.Digest
+		}
+	}
+	return tags
+}
+
+// findMovedTags returns the tags of current that pointed to another digest in
+// previous, ordered by tag. New and vanished tags are not moves.
+func findMovedTags(previous, current map[string]string) []movedTag {
+	var moved []movedTag
+	for tag, digest := range current {
+		if from, ok := previous[tag]; ok && from != digest {
+			moved = append(moved, movedTag{Tag: tag, FromDigest: from, ToDigest: digest})
+		}
+	}
+	sort.Slice(moved, func(i, j int) bool {
+		return moved[i].Tag < moved[j].Tag
+	})
+	return moved
+}
+
+// watchVersions polls the package versions every interval and emits only versions
+// that appeared since the previous poll. The first poll establishes the baseline.
+// With params.Refresh, the owner type and tags are refreshed once they are that
+// old, and tags that moved in the meantime are emitted too.
+// It runs until the context is cancelled, which is treated as a normal exit.
+func watchVersions(ctx context.Context, client watchClient, params watchParams, out io.Writer) error {
+	if params.Interval <= 0 {
+		return fmt.Errorf("watch interval must be positive, got %s", params.Interval)
+	}
+	if params.Refresh < 0 {
+		return fmt.Errorf("refresh interval must not be negative, got %s", params.Refresh)
+	}
+	now := params.Now
+	if now == nil {
+		now = time.Now
+	}
+
+	// Copy the filter so the incremental watermark doesn't leak to the caller
+	vf := filter.VersionFilter{}
+	if params.Filter != nil {
+		vf = *params.Filter
+	}
+
+	versions, err := client.ListPackageVersions(ctx, params.Owner, params.OwnerType, params.PackageName)
+	if err != nil {
+		return fmt.Errorf("failed to list versions: %w", err)
+	}
+	vf.MinVersionID = maxVersionID(versions, vf.MinVersionID)
+	cache := watchCache{maxAge: params.Refresh, ownerType: params.OwnerType, tags: tagDigests(versions), fetchedAt: now()}
+
+	if !params.QuietMode && !params.JSONStream {
+		fmt.Fprintf(out, "Watching %s for new versions every %s (%s existing, newest ID %d). Press Ctrl+C to stop.\n\n",
+			params.PackageName, params.Interval, display.ColorCount(len(versions)), vf.MinVersionID)
+	}
+
+	ticker := time.NewTicker(params.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		refresh := cache.stale(now())
+		if refresh {
+			// A failed lookup keeps the cached owner type; listing reports real problems
+			if ownerType, err := client.RefreshOwnerType(ctx, params.Owner); err == nil {
+				cache.ownerType = ownerType
+			}
+		}
+
+		versions, err := client.ListPackageVersions(ctx, params.Owner, cache.ownerType, params.PackageName)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if gh.IsNotFound(err) {
+				return fmt.Errorf("package %s no longer exists: %w", params.PackageName, err)
+			}
+			return fmt.Errorf("failed to list versions: %w", err)
+		}
+
+		newVersions := vf.Apply(versions)
+		for _, ver := range newVersions {
+			if err := outputWatchedVersion(out, ver, params.JSONStream); err != nil {
+				return err
+			}
+		}
+
+		vf.MinVersionID = maxVersionID(versions, vf.MinVersionID)
+
+		if refresh {
+			tags := tagDigests(versions)
+			for _, moved := range findMovedTags(cache.tags, tags) {
+				if err := outputMovedTag(out, moved, params.JSONStream); err != nil {
+					return err
+				}
+			}
+			cache.tags = tags
+			cache.fetchedAt = now()
+		}
+	}
+}
+
+// Events of --json-stream lines, in their "event" field
+const (
+	watchEventVersion  = "version"
+	watchEventTagMoved = "tag_moved"
+)
+
+// versionFields are the fields of gh.PackageVersionInfo without its
+// MarshalJSON method, which would otherwise be promoted to versionEvent
+type versionFields gh.PackageVersionInfo
+
+// versionEvent is the --json-stream line of a new version
+type versionEvent struct {
+	Event string `json:"event"`
+	versionFields
+}
+
+// tagMovedEvent is the --json-stream line of a tag that moved
+type tagMovedEvent struct {
+	Event string `json:"event"`
+	movedTag
+}
+
+// newVersionEvent returns the line of ver, with the RFC3339 timestamps of
+// gh.PackageVersionInfo's JSON
+func newVersionEvent(ver gh.PackageVersionInfo) versionEvent {
+	fields := versionFields(ver)
+	fields.CreatedAt = gh.RFC3339Timestamp(ver.CreatedAt)
+	fields.UpdatedAt = gh.RFC3339Timestamp(ver.UpdatedAt)
+	return versionEvent{Event: watchEventVersion, versionFields: fields}
+}
+
+// writeWatchEvent writes event as a single NDJSON line
+func writeWatchEvent(w io.Writer, event any) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	fmt.Fprintln(w, string(data))
+	return nil
+}
+
+// outputMovedTag writes a tag that moved to another digest as a table row or
+// NDJSON line
+func outputMovedTag(w io.Writer, moved movedTag, jsonStream bool) error {
+	if jsonStream {
+		return writeWatchEvent(w, tagMovedEvent{Event: watchEventTagMoved, movedTag: moved})
+	}
+
+	fmt.Fprintf(w, "  %s moved: %s -> %s\n",
+		display.ColorTags([]string{moved.Tag}),
+		display.ColorDigest(display.ShortDigest(moved.FromDigest)),
+		display.ColorDigest(display.ShortDigest(moved.ToDigest)))
+	return nil
+}
+
+// outputWatchedVersion writes a single newly appeared version as a table row or NDJSON line
+func outputWatchedVersion(w io.Writer, ver gh.PackageVersionInfo, jsonStream bool) error {
+	if jsonStream {
+		return writeWatchEvent(w, newVersionEvent(ver))
+	}
+
+	fmt.Fprintf(w, "  %d  %s  %s  %s\n",
+		ver.ID,
+		display.ColorDigest(display.ShortDigest(ver.Digest)),
+		display.ColorTags(ver.Tags),
+		ver.CreatedAt)
+	return nil
+}
+
+// maxVersionID returns the highest version ID in versions, or floor if none is higher
+func maxVersionID(versions []gh.PackageVersionInfo, floor int64) int64 {
+	highest := floor
+	for _, ver := range versions {
+		if ver.ID > highest {
+			highest = ver.ID
+		}
+	}
+	return highest
+}
@@ -0,0 +1,322 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mkoepf/ghcrctl/internal/filter"
+	"github.com/mkoepf/ghcrctl/internal/gh"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// growingVersionLister returns a different version list on each poll and
+// cancels the context once all polls have been served. Each poll advances its
+// clock by tick, and the owner type is looked up again as refreshedType.
+type growingVersionLister struct {
+	mu            sync.Mutex
+	polls         [][]gh.PackageVersionInfo
+	calls         int
+	cancel        context.CancelFunc
+	tick          time.Duration
+	clock         time.Time
+	refreshedType string
+	refreshes     int
+	ownerTypes    []string
+}
+
+func (m *growingVersionLister) ListPackageVersions(ctx context.Context, owner, ownerType, packageName string) ([]gh.PackageVersionInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.clock = m.clock.Add(m.tick)
+	m.ownerTypes = append(m.ownerTypes, ownerType)
+	idx := m.calls
+	if idx >= len(m.polls) {
+		idx = len(m.polls) - 1
+	}
+	m.calls++
+	if m.calls >= len(m.polls) {
+		m.cancel()
+	}
+	return m.polls[idx], nil
+}
+
+func (m *growingVersionLister) RefreshOwnerType(ctx context.Context, owner string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.refreshes++
+	return m.refreshedType, nil
+}
+
+func (m *growingVersionLister) now() time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.clock
+}
+
+func TestWatchVersions_EmitsOnlyDeltas(t *testing.T) {
+	t.Parallel()
+
+	v1 := gh.PackageVersionInfo{ID: 100, Digest: "sha256:aaa", Tags: []string{"v1.0.0"}, CreatedAt: "2025-01-01 10:00:00"}
+	v2 := gh.PackageVersionInfo{ID: 101, Digest: "sha256:bbb", CreatedAt: "2025-01-02 10:00:00"}
+	v3 := gh.PackageVersionInfo{ID: 102, Digest: "sha256:ccc", Tags: []string{"v1.1.0"}, CreatedAt: "2025-01-03 10:00:00"}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	lister := &growingVersionLister{
+		polls: [][]gh.PackageVersionInfo{
+			{v1},
+			{v1, v2},
+			{v1, v2},
+			{v1, v2, v3},
+		},
+		cancel: cancel,
+	}
+
+	var buf bytes.Buffer
+	err := watchVersions(ctx, lister, watchParams{
+		Owner:       "owner",
+		OwnerType:   "user",
+		PackageName: "pkg",
+		Interval:    time.Millisecond,
+		JSONStream:  true,
+	}, &buf)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2, "only versions that appeared after the baseline should be emitted")
+
+	var first, second gh.PackageVersionInfo
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &first))
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &second))
+	assert.Equal(t, int64(101), first.ID)
+	assert.Equal(t, int64(102), second.ID)
+	assert.True(t, strings.HasPrefix(lines[0], `{"event":"version","ID":101,`), lines[0])
+}
+
+func TestWatchVersions_AppliesFilter(t *testing.T) {
+	t.Parallel()
+
+	v1 := gh.PackageVersionInfo{ID: 100, Digest: "sha256:aaa", Tags: []string{"v1.0.0"}}
+	v2 := gh.PackageVersionInfo{ID: 101, Digest: "sha256:bbb"}
+	v3 := gh.PackageVersionInfo{ID: 102, Digest: "sha256:ccc", Tags: []string{"v1.1.0"}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	lister := &growingVersionLister{
+		polls:  [][]gh.PackageVersionInfo{{v1}, {v1, v2, v3}},
+		cancel: cancel,
+	}
+
+	var buf bytes.Buffer
+	err := watchVersions(ctx, lister, watchParams{
+		PackageName: "pkg",
+		Interval:    time.Millisecond,
+		Filter:      &filter.VersionFilter{OnlyTagged: true},
+		QuietMode:   true,
+	}, &buf)
+	require.NoError(t, err)
+
+	output := buf.String()
+	assert.Contains(t, output, "102")
+	assert.NotContains(t, output, "101", "untagged version should be filtered out")
+	assert.NotContains(t, output, "100", "baseline version should not be emitted")
+}
+
+func TestWatchVersions_InvalidInterval(t *testing.T) {
+	t.Parallel()
+
+	err := watchVersions(context.Background(), &growingVersionLister{}, watchParams{Interval: 0}, &bytes.Buffer{})
+	assert.ErrorContains(t, err, "interval must be positive")
+
+	err = watchVersions(context.Background(), &growingVersionLister{}, watchParams{Interval: time.Second, Refresh: -time.Second}, &bytes.Buffer{})
+	assert.ErrorContains(t, err, "refresh interval must not be negative")
+}
+
+func TestWatchVersions_BackgroundRefreshReportsMovedTag(t *testing.T) {
+	t.Parallel()
+
+	v1 := gh.PackageVersionInfo{ID: 100, Digest: "sha256:aaa", Tags: []string{"latest", "v1"}}
+	v1Untagged := gh.PackageVersionInfo{ID: 100, Digest: "sha256:aaa", Tags: []string{"v1"}}
+	v2 := gh.PackageVersionInfo{ID: 101, Digest: "sha256:bbb", Tags: []string{"latest"}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// latest moves to v2 on the second poll; the cache is refreshed on the fifth
+	lister := &growingVersionLister{
+		polls: [][]gh.PackageVersionInfo{
+			{v1},
+			{v1Untagged, v2},
+			{v1Untagged, v2},
+			{v1Untagged, v2},
+			{v1Untagged, v2},
+		},
+		cancel:        cancel,
+		tick:          time.Minute,
+		refreshedType: "org",
+	}
+
+	var buf bytes.Buffer
+	err := watchVersions(ctx, lister, watchParams{
+		Owner:       "owner",
+		OwnerType:   "user",
+		PackageName: "pkg",
+		Interval:    time.Millisecond,
+		JSONStream:  true,
+		Refresh:     3 * time.Minute,
+		Now:         lister.now,
+	}, &buf)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2, "the new version right away, the moved tag only after the refresh interval")
+
+	var added gh.PackageVersionInfo
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &added))
+	assert.Equal(t, int64(101), added.ID)
+	assert.Contains(t, lines[0], `"event":"version"`)
+	assert.JSONEq(t, `{"event":"tag_moved","tag":"latest","from_digest":"sha256:aaa","to_digest":"sha256:bbb"}`, lines[1])
+
+	assert.Equal(t, 1, lister.refreshes)
+	assert.Equal(t, []string{"user", "user", "user", "user", "org"}, lister.ownerTypes)
+}
+
+func TestWatchVersions_NoBackgroundRefresh(t *testing.T) {
+	t.Parallel()
+
+	v1 := gh.PackageVersionInfo{ID: 100, Digest: "sha256:aaa", Tags: []string{"latest"}}
+	v2 := gh.PackageVersionInfo{ID: 101, Digest: "sha256:bbb", Tags: []string{"latest"}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	lister := &growingVersionLister{
+		polls:  [][]gh.PackageVersionInfo{{v1}, {{ID: 100, Digest: "sha256:aaa"}, v2}, {{ID: 100, Digest: "sha256:aaa"}, v2}},
+		cancel: cancel,
+		tick:   time.Hour,
+	}
+
+	var buf bytes.Buffer
+	err := watchVersions(ctx, lister, watchParams{
+		OwnerType:   "user",
+		PackageName: "pkg",
+		Interval:    time.Millisecond,
+		QuietMode:   true,
+		Now:         lister.now,
+	}, &buf)
+	require.NoError(t, err)
+
+	assert.NotContains(t, buf.String(), "moved")
+	assert.Zero(t, lister.refreshes)
+}
+
+func TestFindMovedTags(t *testing.T) {
+	t.Parallel()
+
+	previous := map[string]string{"latest": "sha256:aaa", "v1": "sha256:aaa", "gone": "sha256:ccc"}
+	current := map[string]string{"latest": "sha256:bbb", "v1": "sha256:aaa", "new": "sha256:bbb"}
+
+	assert.Equal(t, []movedTag{{Tag: "latest", FromDigest: "sha256:aaa", ToDigest: "sha256:bbb"}}, findMovedTags(previous, current))
+	assert.Empty(t, findMovedTags(current, current))
+}
+
+// vanishingVersionLister answers 404 once the first poll has been served,
+// like a package deleted while it is being watched.
+type vanishingVersionLister struct {
+	calls int
+}
+
+func (m *vanishingVersionLister) ListPackageVersions(ctx context.Context, owner, ownerType, packageName string) ([]gh.PackageVersionInfo, error) {
+	m.calls++
+	if m.calls > 1 {
+		return nil, &gh.NotFoundError{Action: "list package versions", Err: fmt.Errorf("404 Not Found")}
+	}
+	return nil, nil
+}
+
+func (m *vanishingVersionLister) RefreshOwnerType(ctx context.Context, owner string) (string, error) {
+	return "user", nil
+}
+
+func TestWatchJSONStream_LinesAreJSON(t *testing.T) {
+	t.Parallel()
+	// Tags that Go quoting and JSON escaping write differently
+	odd := []string{"quote\"", "tab\t", "nul\x00", "ä", "<html>", "bad\xff"}
+
+	var buf bytes.Buffer
+	ver := gh.PackageVersionInfo{ID: 7, Digest: "sha256:aaa", Tags: odd, CreatedAt: "2025-01-02 10:00:00"}
+	require.NoError(t, outputWatchedVersion(&buf, ver, true))
+	for _, tag := range odd {
+		require.NoError(t, outputMovedTag(&buf, movedTag{Tag: tag, FromDigest: "sha256:aaa", ToDigest: "sha256:bbb"}, true))
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 1+len(odd))
+	for _, line := range lines {
+		var event map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &event), line)
+		assert.Contains(t, []interface{}{"version", "tag_moved"}, event["event"])
+	}
+
+	var version struct {
+		Event string
+		gh.PackageVersionInfo
+	}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &version))
+	assert.Equal(t, "version", version.Event)
+	assert.Equal(t, int64(7), version.ID)
+	assert.Equal(t, "2025-01-02T10:00:00Z", version.CreatedAt, "same timestamps as the JSON of a version")
+	assert.Equal(t, []string{"quote\"", "tab\t", "nul\x00", "ä", "<html>", "bad\uFFFD"}, version.Tags)
+
+	var moved tagMovedEvent
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &moved))
+	assert.Equal(t, "tag_moved", moved.Event)
+	assert.Equal(t, movedTag{Tag: "quote\"", FromDigest: "sha256:aaa", ToDigest: "sha256:bbb"}, moved.movedTag)
+}
+
+func TestWatchVersions_PackageDeleted(t *testing.T) {
+	t.Parallel()
+
+	err := watchVersions(context.Background(), &vanishingVersionLister{}, watchParams{
+		PackageName: "myimage",
+		Interval:    time.Millisecond,
+		QuietMode:   true,
+	}, &bytes.Buffer{})
+	assert.ErrorContains(t, err, "package myimage no longer exists")
+	assert.True(t, gh.IsNotFound(err))
+}
+
+func TestListVersionsCmd_JSONStreamRequiresWatch(t *testing.T) {
+	t.Parallel()
+
+	cmd := NewRootCmd()
+	cmd.SetArgs([]string{"list", "versions", "owner/pkg", "--json-stream"})
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+
+	err := cmd.Execute()
+	assert.ErrorContains(t, err, "--json-stream requires --watch")
+}
+
+func TestListVersionsCmd_BackgroundRefreshRequiresWatch(t *testing.T) {
+	t.Parallel()
+
+	cmd := NewRootCmd()
+	cmd.SetArgs([]string{"list", "versions", "owner/pkg", "--background-refresh", "10m"})
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+
+	err := cmd.Execute()
+	assert.ErrorContains(t, err, "--background-refresh requires --watch")
+}
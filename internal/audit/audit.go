@@ -0,0 +1,107 @@
+// Package audit appends a JSON line for every deleted package version to an
+// audit log file, which builds up an NDJSON record of deletions across runs.
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+type contextKey struct{}
+
+// Entry is one deleted version in the audit log
+type Entry struct {
+	Timestamp string   `json:"timestamp"`
+	Actor     string   `json:"actor"`
+	Owner     string   `json:"owner"`
+	Package   string   `json:"package"`
+	VersionID int64    `json:"version_id"`
+	Digest    string   `json:"digest"`
+	Tags      []string `json:"tags"`
+	Selector  string   `json:"selector"`
+}
+
+// Version is what the audit log records about a deleted version itself
+type Version struct {
+	ID     int64
+	Digest string
+	Tags   []string
+}
+
+// Append appends entries to the audit log at path, one JSON object per line.
+// The file is created if it does not exist. Without entries, Append only checks
+// that the file can be written.
+func Append(path string, entries ...Entry) error {
+	var buf bytes.Buffer
+	for _, entry := range entries {
+		if entry.Tags == nil {
+			entry.Tags = []string{}
+		}
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("failed to marshal audit entry: %w", err)
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	// One write per call, so that lines of concurrent runs do not interleave
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write audit log %s: %w", path, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write audit log %s: %w", path, err)
+	}
+	return nil
+}
+
+// Log is the audit log of a run and what all of its entries share
+type Log struct {
+	Path     string
+	Actor    string // Login of the user the token belongs to
+	Owner    string
+	Package  string
+	Selector string // How the deleted versions were selected, e.g. "delete version --untagged"
+}
+
+// WithLog returns a context in which Record appends to log
+func WithLog(ctx context.Context, log Log) context.Context {
+	return context.WithValue(ctx, contextKey{}, log)
+}
+
+// Record appends an entry for each deleted version to the audit log set by
+// WithLog, timestamped now. It does nothing if no audit log is set.
+func Record(ctx context.Context, versions ...Version) error {
+	if ctx == nil {
+		return nil
+	}
+	log, ok := ctx.Value(contextKey{}).(Log)
+	if !ok {
+		return nil
+	}
+
+	timestamp := time.Now().UTC().Format(time.RFC3339)
+	entries := make([]Entry, 0, len(versions))
+	for _, v := range versions {
+		entries = append(entries, Entry{
+			Timestamp: timestamp,
+			Actor:     log.Actor,
+			Owner:     log.Owner,
+			Package:   log.Package,
+			VersionID: v.ID,
+			Digest:    v.Digest,
+			Tags:      v.Tags,
+			Selector:  log.Selector,
+		})
+	}
+	return Append(log.Path, entries...)
+}
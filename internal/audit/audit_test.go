@@ -0,0 +1,94 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readEntries reads the audit log at path
+func readEntries(t *testing.T, path string) []Entry {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var entries []Entry
+	for _, line := range strings.Split(strings.TrimSuffix(string(content), "\n"), "\n") {
+		var entry Entry
+		require.NoError(t, json.Unmarshal([]byte(line), &entry), line)
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestAppend(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "audit.ndjson")
+
+	// Without entries the file is only created
+	require.NoError(t, Append(path))
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Empty(t, content)
+
+	require.NoError(t, Append(path, Entry{VersionID: 1, Digest: "sha256:aaa", Tags: []string{"v1"}}))
+	require.NoError(t, Append(path, Entry{VersionID: 2, Digest: "sha256:bbb"}))
+
+	content, err = os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	require.Len(t, lines, 2, "runs append to the log")
+	assert.Contains(t, lines[1], `"tags":[]`, "untagged versions have an empty tag list")
+}
+
+func TestAppend_Unwritable(t *testing.T) {
+	t.Parallel()
+	err := Append(filepath.Join(t.TempDir(), "missing", "audit.ndjson"))
+	assert.ErrorContains(t, err, "failed to open audit log")
+}
+
+func TestRecord(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "audit.ndjson")
+
+	// Without an audit log nothing is written
+	require.NoError(t, Record(context.Background(), Version{ID: 1}))
+	_, err := os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+
+	ctx := WithLog(context.Background(), Log{
+		Path:     path,
+		Actor:    "octocat",
+		Owner:    "mkoepf",
+		Package:  "myimage",
+		Selector: "delete version --untagged",
+	})
+	before := time.Now().UTC().Truncate(time.Second)
+	require.NoError(t, Record(ctx, Version{ID: 10, Digest: "sha256:aaa", Tags: []string{"v1", "latest"}}, Version{ID: 11, Digest: "sha256:bbb"}))
+
+	entries := readEntries(t, path)
+	require.Len(t, entries, 2)
+	timestamp, err := time.Parse(time.RFC3339, entries[0].Timestamp)
+	require.NoError(t, err)
+	assert.False(t, timestamp.Before(before))
+
+	entries[0].Timestamp = ""
+	assert.Equal(t, Entry{
+		Actor:     "octocat",
+		Owner:     "mkoepf",
+		Package:   "myimage",
+		VersionID: 10,
+		Digest:    "sha256:aaa",
+		Tags:      []string{"v1", "latest"},
+		Selector:  "delete version --untagged",
+	}, entries[0])
+	assert.Equal(t, int64(11), entries[1].VersionID)
+	assert.Equal(t, []string{}, entries[1].Tags)
+}
@@ -0,0 +1,137 @@
+// Package config loads the ghcrctl configuration file.
+// The file defines named profiles that carry default flag values per command,
+// so teams can encode conventions such as "cleanup deletes untagged versions
+// older than 30 days" once instead of repeating the flags.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// PathEnv is the environment variable that overrides the config file location
+const PathEnv = "GHCRCTL_CONFIG"
+
+// Config is the content of the configuration file.
+//
+// Example:
+//
+//	{
+//	  "profiles": {
+//	    "cleanup": {
+//	      "owner": "mkoepf",
+//	      "defaults": {
+//	        "delete version": {"untagged": true, "older-than": "30d"},
+//	        "list versions": {"untagged": true}
+//	      }
+//	    }
+//	  }
+//	}
+type Config struct {
+	Profiles map[string]Profile `json:"profiles"`
+}
+
+// Profile holds default flag values keyed by command path (e.g. "delete version")
+// and flag name. Values may be strings, booleans, numbers, or lists of these.
+// Owner is the default of --owner for every command, so that package arguments
+// can be given without their owner.
+type Profile struct {
+	Owner    string                    `json:"owner,omitempty"`
+	Defaults map[string]map[string]any `json:"defaults"`
+}
+
+// Path returns the location of the config file: $GHCRCTL_CONFIG if set,
+// otherwise ghcrctl/config.json in the user's config directory.
+func Path() (string, error) {
+	if path := os.Getenv(PathEnv); path != "" {
+		return path, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate config directory: %w", err)
+	}
+	return filepath.Join(dir, "ghcrctl", "config.json"), nil
+}
+
+// Load reads the config file at path. A missing file yields an empty config.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config %s: %w", path, err)
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// Profile returns the named profile, or an error listing the defined profiles.
+func (c *Config) Profile(name string) (Profile, error) {
+	if profile, ok := c.Profiles[name]; ok {
+		return profile, nil
+	}
+
+	names := make([]string, 0, len(c.Profiles))
+	for n := range c.Profiles {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	if len(names) == 0 {
+		return Profile{}, fmt.Errorf("profile %q not found: no profiles are defined", name)
+	}
+	return Profile{}, fmt.Errorf("profile %q not found (available: %s)", name, strings.Join(names, ", "))
+}
+
+// FlagDefaults returns the default flag values of a command as strings suitable
+// for pflag's Value.Set. Lists are joined with commas.
+func (p Profile) FlagDefaults(commandPath string) (map[string]string, error) {
+	defaults := make(map[string]string, len(p.Defaults[commandPath]))
+	for name, value := range p.Defaults[commandPath] {
+		str, err := flagValueString(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid default for --%s in %q: %w", name, commandPath, err)
+		}
+		defaults[name] = str
+	}
+	// A command's own owner default wins over the profile-wide one
+	if _, ok := defaults["owner"]; !ok && p.Owner != "" {
+		defaults["owner"] = p.Owner
+	}
+	return defaults, nil
+}
+
+// flagValueString converts a decoded JSON value into its flag representation
+func flagValueString(value any) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case bool, float64:
+		return fmt.Sprint(v), nil
+	case []any:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			if _, isList := item.([]any); isList {
+				return "", fmt.Errorf("nested lists are not supported")
+			}
+			part, err := flagValueString(item)
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, part)
+		}
+		return strings.Join(parts, ","), nil
+	default:
+		return "", fmt.Errorf("unsupported value %v", value)
+	}
+}
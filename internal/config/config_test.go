@@ -0,0 +1,118 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testConfig = `{
+  "profiles": {
+    "cleanup": {
+      "defaults": {
+        "delete version": {"untagged": true, "older-than": "30d", "batch-size": 50},
+        "list versions": {"exclude-type": ["sbom", "provenance"]}
+      }
+    },
+    "audit": {}
+  }
+}`
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := Load(writeConfig(t, testConfig))
+	require.NoError(t, err)
+	assert.Len(t, cfg.Profiles, 2)
+
+	profile, err := cfg.Profile("cleanup")
+	require.NoError(t, err)
+
+	defaults, err := profile.FlagDefaults("delete version")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"untagged": "true", "older-than": "30d", "batch-size": "50"}, defaults)
+
+	defaults, err = profile.FlagDefaults("list versions")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"exclude-type": "sbom,provenance"}, defaults)
+
+	defaults, err = profile.FlagDefaults("delete graph")
+	require.NoError(t, err)
+	assert.Empty(t, defaults)
+}
+
+func TestLoad_MissingFileIsEmpty(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := Load(filepath.Join(t.TempDir(), "missing.json"))
+	require.NoError(t, err)
+	assert.Empty(t, cfg.Profiles)
+
+	_, err = cfg.Profile("cleanup")
+	assert.EqualError(t, err, `profile "cleanup" not found: no profiles are defined`)
+}
+
+func TestLoad_InvalidJSON(t *testing.T) {
+	t.Parallel()
+
+	_, err := Load(writeConfig(t, `{"profiles": [}`))
+	assert.ErrorContains(t, err, "failed to parse config")
+}
+
+func TestConfig_UnknownProfile(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := Load(writeConfig(t, testConfig))
+	require.NoError(t, err)
+
+	_, err = cfg.Profile("nightly")
+	assert.EqualError(t, err, `profile "nightly" not found (available: audit, cleanup)`)
+}
+
+func TestProfile_FlagDefaultsRejectsUnsupportedValues(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := Load(writeConfig(t, `{"profiles": {"bad": {"defaults": {"list versions": {"tag": {"nested": true}}}}}}`))
+	require.NoError(t, err)
+	profile, err := cfg.Profile("bad")
+	require.NoError(t, err)
+
+	_, err = profile.FlagDefaults("list versions")
+	assert.ErrorContains(t, err, `invalid default for --tag in "list versions"`)
+}
+
+func TestPath_EnvOverride(t *testing.T) {
+	t.Setenv(PathEnv, "/tmp/ghcrctl-test.json")
+
+	path, err := Path()
+	require.NoError(t, err)
+	assert.Equal(t, "/tmp/ghcrctl-test.json", path)
+}
+
+func TestProfile_FlagDefaultsOwner(t *testing.T) {
+	t.Parallel()
+
+	cfg, err := Load(writeConfig(t, `{"profiles": {"team": {"owner": "myorg", "defaults": {"move": {"owner": "other"}}}}}`))
+	require.NoError(t, err)
+	profile, err := cfg.Profile("team")
+	require.NoError(t, err)
+	assert.Equal(t, "myorg", profile.Owner)
+
+	defaults, err := profile.FlagDefaults("list versions")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"owner": "myorg"}, defaults)
+
+	defaults, err = profile.FlagDefaults("move")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"owner": "other"}, defaults, "a command's own default wins")
+}
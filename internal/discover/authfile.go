@@ -0,0 +1,118 @@
+package discover
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"oras.land/oras-go/v2/registry/remote/auth"
+	"oras.land/oras-go/v2/registry/remote/credentials"
+)
+
+// AuthFileEnv names the containers auth file when --authfile is not given, as
+// for Podman, Buildah and Skopeo
+const AuthFileEnv = "REGISTRY_AUTH_FILE"
+
+type authFileKey struct{}
+
+// AuthFile holds the registry credentials of a containers auth.json, by
+// registry host
+type AuthFile map[string]auth.Credential
+
+// LoadAuthFile reads the credentials of a containers auth.json, as written by
+// podman login:
+//
+//	{"auths": {"ghcr.io": {"auth": "<base64 of user:password>"}}}
+//
+// Keys may carry a scheme or a repository path (https://ghcr.io,
+// ghcr.io/owner/repo); their credentials apply to the whole registry, with
+// plain host keys taking precedence. Entries without an auth field, e.g. those
+// kept in a credential helper, are skipped.
+func LoadAuthFile(path string) (AuthFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read auth file: %w", err)
+	}
+
+	var parsed struct {
+		Auths map[string]struct {
+			Auth string `json:"auth"`
+		} `json:"auths"`
+	}
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse auth file %s: %w", path, err)
+	}
+
+	creds := make(AuthFile)
+	exact := make(map[string]bool)
+	for key, entry := range parsed.Auths {
+		if entry.Auth == "" {
+			continue
+		}
+		cred, err := decodeAuth(entry.Auth)
+		if err != nil {
+			return nil, fmt.Errorf("invalid credentials for %s in %s: %w", key, path, err)
+		}
+
+		host := strings.TrimPrefix(strings.TrimPrefix(key, "https://"), "http://")
+		host, rest, _ := strings.Cut(host, "/")
+		isExact := rest == "" || rest == "v1/" || rest == "v2/"
+		if _, ok := creds[host]; ok && (exact[host] || !isExact) {
+			continue
+		}
+		creds[host] = cred
+		exact[host] = isExact
+	}
+	return creds, nil
+}
+
+// decodeAuth decodes the base64 user:password of an auth.json entry
+func decodeAuth(encoded string) (auth.Credential, error) {
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return auth.Credential{}, fmt.Errorf("auth is not valid base64: %w", err)
+	}
+	username, password, ok := strings.Cut(string(decoded), ":")
+	if !ok || username == "" {
+		return auth.Credential{}, fmt.Errorf("auth is not of the form user:password")
+	}
+	return auth.Credential{Username: username, Password: password}, nil
+}
+
+// WithAuthFile returns a context in which registry requests use the
+// credentials of creds
+func WithAuthFile(ctx context.Context, creds AuthFile) context.Context {
+	return context.WithValue(ctx, authFileKey{}, creds)
+}
+
+// authFileFromContext returns the credentials set by WithAuthFile, if any
+func authFileFromContext(ctx context.Context) AuthFile {
+	if ctx == nil {
+		return nil
+	}
+	creds, _ := ctx.Value(authFileKey{}).(AuthFile)
+	return creds
+}
+
+// newCredentialStore returns the registry credentials for requests in ctx: those
+// of the auth file, and token for ghcr.io, which takes precedence over the
+// auth file. It returns nil if there are none.
+func newCredentialStore(ctx context.Context, token string) credentials.Store {
+	creds := authFileFromContext(ctx)
+	if len(creds) == 0 && token == "" {
+		return nil
+	}
+
+	store := credentials.NewMemoryStore()
+	for host, cred := range creds {
+		_ = store.Put(context.Background(), host, cred)
+	}
+	if token != "" {
+		// ghcr.io uses oauth2 as username
+		_ = store.Put(context.Background(), "ghcr.io", auth.Credential{Username: "oauth2", Password: token})
+	}
+	return store
+}
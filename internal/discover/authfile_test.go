@@ -0,0 +1,101 @@
+package discover
+
+import (
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"oras.land/oras-go/v2/registry/remote/auth"
+)
+
+// writeAuthFile writes content as auth.json in a temporary directory
+func writeAuthFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "auth.json")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func basicAuth(user, password string) string {
+	return base64.StdEncoding.EncodeToString([]byte(user + ":" + password))
+}
+
+func TestLoadAuthFile(t *testing.T) {
+	t.Parallel()
+	path := writeAuthFile(t, `{"auths": {
+		"ghcr.io": {"auth": "`+basicAuth("mkoepf", "ghp_secret:with:colons")+`"},
+		"ghcr.io/mkoepf/other": {"auth": "`+basicAuth("other", "ignored")+`"},
+		"https://registry.example.com/v2/": {"auth": "`+basicAuth("robot", "pass")+`"},
+		"quay.io/team/app": {"auth": "`+basicAuth("team", "quay")+`"},
+		"docker.io": {}
+	}}`)
+
+	creds, err := LoadAuthFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, AuthFile{
+		"ghcr.io":              {Username: "mkoepf", Password: "ghp_secret:with:colons"},
+		"registry.example.com": {Username: "robot", Password: "pass"},
+		"quay.io":              {Username: "team", Password: "quay"},
+	}, creds, "plain host keys win over repository keys, entries without auth are skipped")
+}
+
+func TestLoadAuthFile_Invalid(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "not JSON", content: `auths`, wantErr: "failed to parse auth file"},
+		{name: "not base64", content: `{"auths": {"ghcr.io": {"auth": "%%%"}}}`, wantErr: "invalid credentials for ghcr.io in"},
+		{name: "no password separator", content: `{"auths": {"ghcr.io": {"auth": "` + base64.StdEncoding.EncodeToString([]byte("token")) + `"}}}`, wantErr: "not of the form user:password"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := LoadAuthFile(writeAuthFile(t, tt.content))
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+
+	_, err := LoadAuthFile(filepath.Join(t.TempDir(), "missing.json"))
+	assert.ErrorContains(t, err, "failed to read auth file")
+}
+
+func TestNewCredentialStore(t *testing.T) {
+	t.Parallel()
+	ctx := WithAuthFile(context.Background(), AuthFile{
+		"ghcr.io":     {Username: "mkoepf", Password: "from-auth-file"},
+		"example.com": {Username: "robot", Password: "pass"},
+	})
+
+	t.Run("auth file only", func(t *testing.T) {
+		t.Parallel()
+		store := newCredentialStore(ctx, "")
+		require.NotNil(t, store)
+		cred, err := store.Get(context.Background(), "ghcr.io")
+		require.NoError(t, err)
+		assert.Equal(t, auth.Credential{Username: "mkoepf", Password: "from-auth-file"}, cred)
+	})
+
+	t.Run("token wins for ghcr.io", func(t *testing.T) {
+		t.Parallel()
+		store := newCredentialStore(ctx, "ghp_token")
+		cred, err := store.Get(context.Background(), "ghcr.io")
+		require.NoError(t, err)
+		assert.Equal(t, auth.Credential{Username: "oauth2", Password: "ghp_token"}, cred)
+
+		cred, err = store.Get(context.Background(), "example.com")
+		require.NoError(t, err)
+		assert.Equal(t, "robot", cred.Username)
+	})
+
+	t.Run("no credentials", func(t *testing.T) {
+		t.Parallel()
+		assert.Nil(t, newCredentialStore(context.Background(), ""))
+	})
+}
@@ -0,0 +1,75 @@
+package discover
+
+import "sort"
+
+// FindCycles returns the cycles formed by the OutgoingRefs of versions. Each
+// cycle lists its digests in reference order, starting at the digest that
+// sorts first, and ends with that digest again. A version that references
+// itself is a cycle of one. Refs to digests outside versions are ignored.
+//
+// Well-formed graphs have no cycles; they only arise from bad pushes, and
+// would make recursive traversals loop or produce wrong trees.
+func FindCycles(versions []VersionInfo) [][]string {
+	byDigest := ToMap(versions)
+	digests := make([]string, 0, len(byDigest))
+	for digest := range byDigest {
+		digests = append(digests, digest)
+	}
+	sort.Strings(digests)
+
+	const (
+		unvisited = iota
+		onStack
+		done
+	)
+	state := make(map[string]int, len(digests))
+	var stack []string
+	var cycles [][]string
+
+	var visit func(digest string)
+	visit = func(digest string) {
+		state[digest] = onStack
+		stack = append(stack, digest)
+		for _, out := range byDigest[digest].OutgoingRefs {
+			if _, ok := byDigest[out]; !ok {
+				continue
+			}
+			switch state[out] {
+			case unvisited:
+				visit(out)
+			case onStack:
+				// The stack from out to here is a cycle
+				for i := len(stack) - 1; i >= 0; i-- {
+					if stack[i] == out {
+						cycles = append(cycles, normalizeCycle(stack[i:]))
+						break
+					}
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[digest] = done
+	}
+
+	for _, digest := range digests {
+		if state[digest] == unvisited {
+			visit(digest)
+		}
+	}
+	return cycles
+}
+
+// normalizeCycle rotates a cycle to start at its smallest digest and closes it
+// by repeating that digest at the end
+func normalizeCycle(cycle []string) []string {
+	start := 0
+	for i, digest := range cycle {
+		if digest < cycle[start] {
+			start = i
+		}
+	}
+	result := make([]string, 0, len(cycle)+1)
+	result = append(result, cycle[start:]...)
+	result = append(result, cycle[:start]...)
+	return append(result, cycle[start])
+}
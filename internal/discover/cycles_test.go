@@ -0,0 +1,81 @@
+package discover
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindCycles(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		versions []VersionInfo
+		want     [][]string
+	}{
+		{
+			name: "well-formed graph",
+			versions: []VersionInfo{
+				{Digest: "sha256:index", OutgoingRefs: []string{"sha256:amd64", "sha256:arm64"}},
+				{Digest: "sha256:amd64"},
+				{Digest: "sha256:arm64"},
+				{Digest: "sha256:sig", OutgoingRefs: []string{"sha256:index"}},
+			},
+			want: nil,
+		},
+		{
+			name: "shared child is not a cycle",
+			versions: []VersionInfo{
+				{Digest: "sha256:a", OutgoingRefs: []string{"sha256:c"}},
+				{Digest: "sha256:b", OutgoingRefs: []string{"sha256:c"}},
+				{Digest: "sha256:c"},
+			},
+			want: nil,
+		},
+		{
+			name: "cycle of three",
+			versions: []VersionInfo{
+				{Digest: "sha256:index", OutgoingRefs: []string{"sha256:c"}},
+				{Digest: "sha256:c", OutgoingRefs: []string{"sha256:b"}},
+				{Digest: "sha256:b", OutgoingRefs: []string{"sha256:a"}},
+				{Digest: "sha256:a", OutgoingRefs: []string{"sha256:c"}},
+			},
+			want: [][]string{{"sha256:a", "sha256:c", "sha256:b", "sha256:a"}},
+		},
+		{
+			name: "self reference",
+			versions: []VersionInfo{
+				{Digest: "sha256:a", OutgoingRefs: []string{"sha256:a"}},
+			},
+			want: [][]string{{"sha256:a", "sha256:a"}},
+		},
+		{
+			name: "two separate cycles",
+			versions: []VersionInfo{
+				{Digest: "sha256:a", OutgoingRefs: []string{"sha256:b"}},
+				{Digest: "sha256:b", OutgoingRefs: []string{"sha256:a"}},
+				{Digest: "sha256:x", OutgoingRefs: []string{"sha256:y"}},
+				{Digest: "sha256:y", OutgoingRefs: []string{"sha256:x"}},
+			},
+			want: [][]string{
+				{"sha256:a", "sha256:b", "sha256:a"},
+				{"sha256:x", "sha256:y", "sha256:x"},
+			},
+		},
+		{
+			name: "refs outside the versions are ignored",
+			versions: []VersionInfo{
+				{Digest: "sha256:a", OutgoingRefs: []string{"sha256:missing"}},
+			},
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, FindCycles(tt.versions))
+		})
+	}
+}
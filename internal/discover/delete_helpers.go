@@ -1,6 +1,10 @@
 package discover
 
-import "fmt"
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
 
 // ClassifyGraphVersions separates graph versions into exclusive (to delete) and shared (to preserve).
 // A version is shared if it has incoming refs from outside the graph being deleted.
@@ -52,6 +56,48 @@ func FindGraphByDigest(versions map[string]VersionInfo, rootDigest string) []Ver
 	return result
 }
 
+// TaggedGraphMembers returns the version IDs, including duplicate IDs, of all
+// versions that belong to the graph of a tagged version: everything reachable
+// from a tagged version via OutgoingRefs, and the referrers (signatures and
+// attestations) attached to those versions. Untagged versions outside this set
+// are orphans.
+func TaggedGraphMembers(versions []VersionInfo) map[int64]bool {
+	versionMap := ToMap(versions)
+	members := make(map[int64]bool)
+	visited := make(map[string]bool)
+
+	var collect func(digest string)
+	collect = func(digest string) {
+		if visited[digest] {
+			return
+		}
+		visited[digest] = true
+
+		v, ok := versionMap[digest]
+		if !ok {
+			return
+		}
+		for _, id := range v.VersionIDs() {
+			members[id] = true
+		}
+		for _, out := range v.OutgoingRefs {
+			collect(out)
+		}
+		for _, in := range v.IncomingRefs {
+			if referrer, ok := versionMap[in]; ok && referrer.IsReferrer() {
+				collect(in)
+			}
+		}
+	}
+
+	for _, v := range versions {
+		if len(v.Tags) > 0 {
+			collect(v.Digest)
+		}
+	}
+	return members
+}
+
 // ToMap converts a slice of VersionInfo to a map keyed by digest.
 func ToMap(versions []VersionInfo) map[string]VersionInfo {
 	m := make(map[string]VersionInfo, len(versions))
@@ -62,7 +108,8 @@ func ToMap(versions []VersionInfo) map[string]VersionInfo {
 }
 
 // FindGraphsContainingVersion returns all versions that belong to graphs containing the target digest.
-// It finds the root(s) that can reach the target and returns all versions in those graphs.
+// It finds the root(s) that can reach the target and returns all versions in those graphs,
+// sorted by ID descending like DiscoverPackage.
 func FindGraphsContainingVersion(versions map[string]VersionInfo, targetDigest string) []VersionInfo {
 	// Find all roots that can reach the target
 	roots := findRootsContaining(versions, targetDigest)
@@ -81,10 +128,36 @@ func FindGraphsContainingVersion(versions map[string]VersionInfo, targetDigest s
 			}
 		}
 	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID > result[j].ID
+	})
 	return result
 }
 
-// findRootsContaining finds all root digests whose graphs contain the target digest.
+// FindRootDigest returns the root of the graph that contains digest. A root
+// digest is returned as is; a platform manifest, signature, or attestation
+// resolves to the image it belongs to. Unknown digests are returned unchanged.
+// A child shared by several graphs is an error, since deleting one of them
+// would leave the child in place.
+func FindRootDigest(versions map[string]VersionInfo, digest string) (string, error) {
+	v, ok := versions[digest]
+	if !ok || v.IsRoot(versions) {
+		return digest, nil
+	}
+
+	roots := findRootsContaining(versions, digest)
+	switch len(roots) {
+	case 0:
+		return digest, nil
+	case 1:
+		return roots[0], nil
+	default:
+		return "", fmt.Errorf("%s belongs to %d graphs:\n  %s\nSelect one of them by its root digest",
+			digest, len(roots), strings.Join(roots, "\n  "))
+	}
+}
+
+// findRootsContaining finds all root digests whose graphs contain the target digest, sorted.
 func findRootsContaining(versions map[string]VersionInfo, targetDigest string) []string {
 	// If target doesn't exist, return empty
 	if _, exists := versions[targetDigest]; !exists {
@@ -99,6 +172,8 @@ func findRootsContaining(versions map[string]VersionInfo, targetDigest string) [
 		}
 	}
 
+	sort.Strings(roots)
+
 	// Check which roots can reach the target
 	var containingRoots []string
 	for _, rootDigest := range roots {
@@ -109,41 +184,53 @@ func findRootsContaining(versions map[string]VersionInfo, targetDigest string) [
 	return containingRoots
 }
 
+// AmbiguousDigestError is returned when a short digest matches more than one version.
+type AmbiguousDigestError struct {
+	Input      string
+	Candidates []string // Matching full digests
+}
+
+func (e *AmbiguousDigestError) Error() string {
+	return fmt.Sprintf("digest %s is ambiguous, matches %d versions:\n  %s\nUse a longer digest to select one",
+		e.Input, len(e.Candidates), strings.Join(e.Candidates, "\n  "))
+}
+
+// MatchShortDigest returns the digests that start with the given short or full digest,
+// in the order they were given. The sha256: prefix of the input is optional.
+func MatchShortDigest(digests []string, input string) []string {
+	shortInput := strings.TrimPrefix(input, "sha256:")
+
+	var matches []string
+	for _, digest := range digests {
+		if strings.HasPrefix(strings.TrimPrefix(digest, "sha256:"), shortInput) {
+			matches = append(matches, digest)
+		}
+	}
+	return matches
+}
+
 // FindDigestByShortDigest finds a full digest from a short or full digest input.
 // It supports full digests (sha256:abc...), short digests without prefix (abc123...),
-// and short digests with prefix (sha256:abc...). Returns error if not found or ambiguous.
+// and short digests with prefix (sha256:abc...). Returns error if not found, or an
+// *AmbiguousDigestError listing the candidates if more than one version matches.
 func FindDigestByShortDigest(versions map[string]VersionInfo, input string) (string, error) {
 	// Check for exact match first
 	if _, exists := versions[input]; exists {
 		return input, nil
 	}
 
-	// Normalize: remove sha256: prefix for comparison
-	shortInput := input
-	if len(input) > 7 && input[:7] == "sha256:" {
-		shortInput = input[7:]
-	}
-
-	// Find all matching digests
-	var matches []string
+	digests := make([]string, 0, len(versions))
 	for digest := range versions {
-		// Get the hash part without prefix
-		hashPart := digest
-		if len(digest) > 7 && digest[:7] == "sha256:" {
-			hashPart = digest[7:]
-		}
-
-		// Check if hash starts with input
-		if len(hashPart) >= len(shortInput) && hashPart[:len(shortInput)] == shortInput {
-			matches = append(matches, digest)
-		}
+		digests = append(digests, digest)
 	}
+	matches := MatchShortDigest(digests, input)
 
 	if len(matches) == 0 {
 		return "", fmt.Errorf("digest %s not found", input)
 	}
 	if len(matches) > 1 {
-		return "", fmt.Errorf("digest %s is ambiguous, matches %d versions", input, len(matches))
+		sort.Strings(matches)
+		return "", &AmbiguousDigestError{Input: input, Candidates: matches}
 	}
 	return matches[0], nil
 }
@@ -187,3 +274,35 @@ func canReach(versions map[string]VersionInfo, startDigest, targetDigest string)
 	}
 	return search(startDigest)
 }
+
+// SortChildrenFirst orders versions so that every version appears before any
+// version in the set that references it. Deleting in this order never removes
+// a parent while its children still exist.
+func SortChildrenFirst(versions []VersionInfo) []VersionInfo {
+	byDigest := make(map[string]VersionInfo, len(versions))
+	for _, v := range versions {
+		byDigest[v.Digest] = v
+	}
+
+	visited := make(map[string]bool)
+	result := make([]VersionInfo, 0, len(versions))
+
+	var visit func(v VersionInfo)
+	visit = func(v VersionInfo) {
+		if visited[v.Digest] {
+			return
+		}
+		visited[v.Digest] = true
+		for _, out := range v.OutgoingRefs {
+			if child, ok := byDigest[out]; ok {
+				visit(child)
+			}
+		}
+		result = append(result, v)
+	}
+
+	for _, v := range versions {
+		visit(v)
+	}
+	return result
+}
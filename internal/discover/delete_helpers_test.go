@@ -215,6 +215,15 @@ func TestFindGraphsContainingVersion(t *testing.T) {
 	result := FindGraphsContainingVersion(versions, "sha256:shared-platform")
 	assert.Len(t, result, 4)
 
+	// The order does not depend on map iteration
+	for i := 0; i < 10; i++ {
+		var ids []int64
+		for _, v := range FindGraphsContainingVersion(versions, "sha256:shared-platform") {
+			ids = append(ids, v.ID)
+		}
+		assert.Equal(t, []int64{4, 3, 2, 1}, ids)
+	}
+
 	// Find graphs containing exclusive platform - should return only index2's graph
 	result = FindGraphsContainingVersion(versions, "sha256:exclusive")
 	assert.Len(t, result, 3)
@@ -327,6 +336,31 @@ func TestFindDigestByShortDigest(t *testing.T) {
 	}
 	_, err = FindDigestByShortDigest(versionsAmbiguous, "abc123")
 	assert.Error(t, err)
+
+	var ambiguous *AmbiguousDigestError
+	require.ErrorAs(t, err, &ambiguous)
+	assert.Equal(t, []string{
+		"sha256:abc123456789012345678901234567890123456789012345678901234567",
+		"sha256:abc123999999012345678901234567890123456789012345678901234567",
+	}, ambiguous.Candidates)
+	assert.Contains(t, err.Error(), "matches 2 versions")
+	assert.Contains(t, err.Error(), "sha256:abc123999999012345678901234567890123456789012345678901234567")
+
+	// A longer prefix disambiguates
+	result, err = FindDigestByShortDigest(versionsAmbiguous, "sha256:abc1239")
+	require.NoError(t, err)
+	assert.Equal(t, "sha256:abc123999999012345678901234567890123456789012345678901234567", result)
+}
+
+func TestMatchShortDigest(t *testing.T) {
+	t.Parallel()
+
+	digests := []string{"sha256:abc111", "sha256:def222", "sha256:abc333"}
+
+	assert.Equal(t, []string{"sha256:abc111", "sha256:abc333"}, MatchShortDigest(digests, "abc"))
+	assert.Equal(t, []string{"sha256:abc111", "sha256:abc333"}, MatchShortDigest(digests, "sha256:abc"))
+	assert.Equal(t, []string{"sha256:def222"}, MatchShortDigest(digests, "def2"))
+	assert.Empty(t, MatchShortDigest(digests, "999"))
 }
 
 func TestFindDigestByVersionID(t *testing.T) {
@@ -357,3 +391,120 @@ func TestFindDigestByVersionID(t *testing.T) {
 	_, err = FindDigestByVersionID(versions, 99999)
 	assert.Error(t, err)
 }
+
+func TestSortChildrenFirst(t *testing.T) {
+	t.Parallel()
+
+	// Two indexes sharing a platform manifest; the platform has an attestation
+	versions := []VersionInfo{
+		{ID: 1, Digest: "sha256:index1", OutgoingRefs: []string{"sha256:shared", "sha256:arm"}},
+		{ID: 2, Digest: "sha256:index2", OutgoingRefs: []string{"sha256:shared"}},
+		{ID: 3, Digest: "sha256:shared", OutgoingRefs: []string{"sha256:sbom"}},
+		{ID: 4, Digest: "sha256:arm"},
+		{ID: 5, Digest: "sha256:sbom"},
+	}
+
+	sorted := SortChildrenFirst(versions)
+	require.Len(t, sorted, len(versions))
+
+	position := make(map[string]int)
+	for i, v := range sorted {
+		position[v.Digest] = i
+	}
+	for _, v := range versions {
+		for _, child := range v.OutgoingRefs {
+			assert.Less(t, position[child], position[v.Digest], "%s must come before its parent %s", child, v.Digest)
+		}
+	}
+}
+
+func TestSortChildrenFirst_IgnoresRefsOutsideSet(t *testing.T) {
+	t.Parallel()
+
+	versions := []VersionInfo{
+		{ID: 1, Digest: "sha256:index", OutgoingRefs: []string{"sha256:missing"}},
+	}
+
+	sorted := SortChildrenFirst(versions)
+	require.Len(t, sorted, 1)
+	assert.Equal(t, int64(1), sorted[0].ID)
+}
+
+func TestTaggedGraphMembers(t *testing.T) {
+	t.Parallel()
+
+	versions := []VersionInfo{
+		{ID: 1, Digest: "sha256:index", Tags: []string{"latest"}, Types: []string{"index"},
+			OutgoingRefs: []string{"sha256:amd64", "sha256:arm64"}, IncomingRefs: []string{"sha256:sig"}},
+		{ID: 2, DuplicateIDs: []int64{6}, Digest: "sha256:amd64", Types: []string{"linux/amd64"}, IncomingRefs: []string{"sha256:index"}},
+		{ID: 3, Digest: "sha256:arm64", Types: []string{"linux/arm64"}, IncomingRefs: []string{"sha256:index"}},
+		{ID: 4, Digest: "sha256:sig", Types: []string{"signature"}, OutgoingRefs: []string{"sha256:index"}},
+		{ID: 5, Digest: "sha256:orphan", Types: []string{"linux/amd64"}},
+	}
+
+	members := TaggedGraphMembers(versions)
+
+	assert.Equal(t, map[int64]bool{1: true, 2: true, 6: true, 3: true, 4: true}, members)
+}
+
+func TestTaggedGraphMembers_NoTags(t *testing.T) {
+	t.Parallel()
+
+	members := TaggedGraphMembers([]VersionInfo{{ID: 1, Digest: "sha256:a"}})
+
+	assert.NotNil(t, members)
+	assert.Empty(t, members)
+}
+
+func TestFindRootDigest(t *testing.T) {
+	t.Parallel()
+
+	versions := map[string]VersionInfo{
+		"sha256:index1": {
+			ID: 1, Digest: "sha256:index1", Types: []string{"index"},
+			OutgoingRefs: []string{"sha256:shared-platform", "sha256:exclusive", "sha256:sig"},
+		},
+		"sha256:index2": {
+			ID: 2, Digest: "sha256:index2", Types: []string{"index"},
+			OutgoingRefs: []string{"sha256:shared-platform"},
+		},
+		"sha256:shared-platform": {
+			ID: 3, Digest: "sha256:shared-platform", Types: []string{"linux/amd64"},
+			IncomingRefs: []string{"sha256:index1", "sha256:index2"},
+		},
+		"sha256:exclusive": {
+			ID: 4, Digest: "sha256:exclusive", Types: []string{"linux/arm64"},
+			IncomingRefs: []string{"sha256:index1"},
+		},
+		"sha256:sig": {
+			ID: 5, Digest: "sha256:sig", Types: []string{"signature"},
+			IncomingRefs: []string{"sha256:index1"},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		digest  string
+		want    string
+		wantErr string
+	}{
+		{name: "root", digest: "sha256:index2", want: "sha256:index2"},
+		{name: "platform", digest: "sha256:exclusive", want: "sha256:index1"},
+		{name: "signature", digest: "sha256:sig", want: "sha256:index1"},
+		{name: "unknown", digest: "sha256:missing", want: "sha256:missing"},
+		{name: "shared platform", digest: "sha256:shared-platform",
+			wantErr: "sha256:shared-platform belongs to 2 graphs:\n  sha256:index1\n  sha256:index2\nSelect one of them by its root digest"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := FindRootDigest(versions, tt.digest)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
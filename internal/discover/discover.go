@@ -7,7 +7,11 @@ package discover
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"slices"
+	"sort"
 	"strings"
 	"sync"
 
@@ -19,8 +23,13 @@ import (
 
 // PackageDiscoverer discovers all versions and their relationships.
 type PackageDiscoverer struct {
+	// Strict fails discovery if a manifest or index cannot be parsed. By
+	// default, such a version is typed "unknown" or listed without children.
+	Strict bool
+
 	resolver        typeResolver
 	childDiscoverer childDiscoverer
+	rootResolver    rootResolver
 }
 
 // childDiscoverer discovers children of an OCI artifact.
@@ -28,52 +37,65 @@ type childDiscoverer interface {
 	discoverChildren(ctx context.Context, image, digest string, allTags []string) ([]string, error)
 }
 
+// rootResolver resolves only the descriptor of a version, plus the children
+// of image indexes, which is enough to tell roots from children.
+type rootResolver interface {
+	resolveRoot(ctx context.Context, image, digest string) (resolvedVersion, []string, error)
+}
+
 // NewPackageDiscoverer creates a new PackageDiscoverer with default implementations.
 func NewPackageDiscoverer() *PackageDiscoverer {
 	resolver := newOrasResolver()
 	return &PackageDiscoverer{
 		resolver:        resolver,
 		childDiscoverer: &orasChildDiscoverer{resolver: resolver},
+		rootResolver:    resolver,
 	}
 }
 
 // DiscoverPackage discovers all versions and their relationships.
 func (d *PackageDiscoverer) DiscoverPackage(ctx context.Context, image string, versions []gh.PackageVersionInfo, allTags []string) ([]VersionInfo, error) {
 	// Build version map
-	versionMap := make(map[string]*VersionInfo)
-
-	for _, v := range versions {
-		info := &VersionInfo{
-			ID:        v.ID,
-			Digest:    v.Digest,
-			Tags:      v.Tags,
-			CreatedAt: v.CreatedAt,
-		}
-		versionMap[v.Digest] = info
+	infos := mergeDuplicateDigests(versions)
+	markTagConflicts(infos)
+	versionMap := make(map[string]*VersionInfo, len(infos))
+	for i := range infos {
+		versionMap[infos[i].Digest] = &infos[i]
 	}
 
 	// Resolve types, size, and discover children for each version in parallel
 	var wg sync.WaitGroup
+	var parseErrs parseErrors
 	for digest, info := range versionMap {
 		wg.Add(1)
 		go func(digest string, info *VersionInfo) {
 			defer wg.Done()
 
-			types, size, err := d.resolver.resolveVersionInfo(ctx, image, digest)
+			// Unresolvable versions keep zero size and an empty media type
+			resolved, err := d.resolver.resolveVersionInfo(ctx, image, digest)
 			if err != nil {
+				parseErrs.add(err)
 				info.Types = []string{"unknown"}
 			} else {
-				info.Types = types
-				info.Size = size
+				info.Types = resolved.Types
+				info.Size = resolved.Size
+				info.MediaType = resolved.MediaType
 			}
 
 			children, err := d.childDiscoverer.discoverChildren(ctx, image, digest, allTags)
 			if err == nil {
 				info.OutgoingRefs = children
+			} else {
+				parseErrs.add(err)
 			}
 		}(digest, info)
 	}
 	wg.Wait()
+	if d.Strict {
+		if err := parseErrs.err(); err != nil {
+			return nil, err
+		}
+	}
 
 	// Infer incoming refs from outgoing refs
 	for digest, info := range versionMap {
@@ -89,10 +111,252 @@ func (d *PackageDiscoverer) DiscoverPackage(ctx context.Context, image string, v
 	for _, info := range versionMap {
 		result = append(result, *info)
 	}
+	sortDiscovered(result)
 
 	return result, nil
 }
 
+// sortDiscovered orders discovered versions and their refs, which would
+// otherwise follow map iteration and vary between runs: versions by ID
+// descending, refs to platform manifests by os/arch, then the other refs
+// (referrers, nested manifests, and digests outside the package) by digest.
+func sortDiscovered(versions []VersionInfo) {
+	platforms := make(map[string]string)
+	for _, v := range versions {
+		for _, t := range v.Types {
+			if strings.Contains(t, "/") {
+				platforms[v.Digest] = t
+				break
+			}
+		}
+	}
+	less := func(a, b string) bool {
+		pa, aIsPlatform := platforms[a]
+		pb, bIsPlatform := platforms[b]
+		switch {
+		case aIsPlatform != bIsPlatform:
+			return aIsPlatform
+		case pa != pb:
+			return pa < pb
+		default:
+			return a < b
+		}
+	}
+
+	for i := range versions {
+		sort.SliceStable(versions[i].OutgoingRefs, func(x, y int) bool {
+			return less(versions[i].OutgoingRefs[x], versions[i].OutgoingRefs[y])
+		})
+		sort.SliceStable(versions[i].IncomingRefs, func(x, y int) bool {
+			return less(versions[i].IncomingRefs[x], versions[i].IncomingRefs[y])
+		})
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].ID > versions[j].ID
+	})
+}
+
+// DiscoverRoots returns only the root versions of a package, i.e. the versions
+// DiscoverPackage would report as roots. It skips fetching platform manifests,
+// configs and cosign tags: a version is a child if an index in the package
+// lists it, or if it carries a cosign tag (sha256-<hex>.sig/.att) of another
+// version in the package. Non-index roots are typed "manifest", and the
+// returned versions carry no refs.
+func (d *PackageDiscoverer) DiscoverRoots(ctx context.Context, image string, versions []gh.PackageVersionInfo) ([]VersionInfo, error) {
+	inPackage := make(map[string]bool, len(versions))
+	for _, v := range versions {
+		inPackage[v.Digest] = true
+	}
+
+	infos := mergeDuplicateDigests(versions)
+	markTagConflicts(infos)
+	children := make([][]string, len(infos))
+
+	var wg sync.WaitGroup
+	var parseErrs parseErrors
+	for i := range infos {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			resolved, indexChildren, err := d.rootResolver.resolveRoot(ctx, image, infos[i].Digest)
+			if err != nil {
+				parseErrs.add(err)
+				infos[i].Types = []string{"unknown"}
+				return
+			}
+			infos[i].Types = resolved.Types
+			infos[i].Size = resolved.Size
+			infos[i].MediaType = resolved.MediaType
+			children[i] = indexChildren
+		}(i)
+	}
+	wg.Wait()
+	if d.Strict {
+		if err := parseErrs.err(); err != nil {
+			return nil, err
+		}
+	}
+
+	isChild := make(map[string]bool)
+	for _, digests := range children {
+		for _, digest := range digests {
+			isChild[digest] = true
+		}
+	}
+
+	roots := make([]VersionInfo, 0, len(infos))
+	for _, info := range infos {
+		isIndex := len(info.Types) == 1 && info.Types[0] == "index"
+		if !isIndex && (isChild[info.Digest] || signsVersionIn(info.Tags, inPackage)) {
+			continue
+		}
+		roots = append(roots, info)
+	}
+	return roots, nil
+}
+
+// parseErrors collects the ManifestParseErrors of concurrent discovery, for
+// strict mode. Other errors, such as failed requests, are ignored.
+type parseErrors struct {
+	mu   sync.Mutex
+	errs []*ManifestParseError
+}
+
+// add records err if it is or wraps a ManifestParseError
+func (p *parseErrors) add(err error) {
+	var parseErr *ManifestParseError
+	if !errors.As(err, &parseErr) {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.errs = append(p.errs, parseErr)
+}
+
+// err returns all recorded errors as one, ordered by digest, or nil
+func (p *parseErrors) err() error {
+	if len(p.errs) == 0 {
+		return nil
+	}
+	sort.Slice(p.errs, func(i, j int) bool {
+		return p.errs[i].Digest < p.errs[j].Digest
+	})
+	if len(p.errs) == 1 {
+		return p.errs[0]
+	}
+	lines := make([]string, len(p.errs))
+	for i, err := range p.errs {
+		lines[i] = err.Error()
+	}
+	return fmt.Errorf("%d manifests cannot be parsed:\n  %s", len(p.errs), strings.Join(lines, "\n  "))
+}
+
+// mergeDuplicateDigests converts package versions to VersionInfos, one per
+// digest. When GHCR lists a digest under several version IDs, the first one
+// listed becomes the ID, the others are kept as DuplicateIDs, and the tags of
+// all of them are combined.
+func mergeDuplicateDigests(versions []gh.PackageVersionInfo) []VersionInfo {
+	infos := make([]VersionInfo, 0, len(versions))
+	byDigest := make(map[string]int, len(versions))
+	for _, v := range versions {
+		i, ok := byDigest[v.Digest]
+		if !ok {
+			byDigest[v.Digest] = len(infos)
+			infos = append(infos, VersionInfo{
+				ID:        v.ID,
+				Digest:    v.Digest,
+				Tags:      v.Tags,
+				CreatedAt: v.CreatedAt,
+			})
+			continue
+		}
+
+		info := &infos[i]
+		info.DuplicateIDs = append(info.DuplicateIDs, v.ID)
+		// Copy before appending so the caller's tag slices stay untouched
+		tags := append([]string(nil), info.Tags...)
+		known := make(map[string]bool, len(tags))
+		for _, tag := range tags {
+			known[tag] = true
+		}
+		for _, tag := range v.Tags {
+			if !known[tag] {
+				known[tag] = true
+				tags = append(tags, tag)
+			}
+		}
+		info.Tags = tags
+	}
+	return infos
+}
+
+// markTagConflicts finds tags that GHCR lists on more than one digest, which
+// can happen after racing pushes. Every version carrying such a tag gets a
+// warning, since selecting it by that tag is ambiguous. The warnings are
+// returned once per tag, ordered by tag.
+func markTagConflicts(infos []VersionInfo) []string {
+	digestsByTag := make(map[string][]string)
+	for _, info := range infos {
+		for _, tag := range info.Tags {
+			digestsByTag[tag] = append(digestsByTag[tag], info.Digest)
+		}
+	}
+
+	var tags []string
+	for tag, digests := range digestsByTag {
+		if len(digests) > 1 {
+			tags = append(tags, tag)
+		}
+	}
+	sort.Strings(tags)
+
+	warnings := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		digests := digestsByTag[tag]
+		sort.Strings(digests)
+		warning := fmt.Sprintf("tag %q is listed on %d digests: %s", tag, len(digests), strings.Join(digests, ", "))
+		warnings = append(warnings, warning)
+		for i := range infos {
+			if slices.Contains(infos[i].Tags, tag) {
+				infos[i].Warnings = append(infos[i].Warnings, warning)
+			}
+		}
+	}
+	return warnings
+}
+
+// Warnings returns the distinct warnings of versions, such as tags listed on
+// more than one digest, ordered. Discovery attaches them to the versions and
+// leaves reporting them to the caller, so that a command prints them once.
+func Warnings(versions []VersionInfo) []string {
+	var warnings []string
+	for _, v := range versions {
+		for _, warning := range v.Warnings {
+			if !slices.Contains(warnings, warning) {
+				warnings = append(warnings, warning)
+			}
+		}
+	}
+	sort.Strings(warnings)
+	return warnings
+}
+
+// signsVersionIn reports whether tags contain a cosign signature or attestation
+// tag whose subject digest is one of the given versions.
+func signsVersionIn(tags []string, versions map[string]bool) bool {
+	for _, tag := range tags {
+		subject, ok := strings.CutSuffix(tag, ".sig")
+		if !ok {
+			subject, ok = strings.CutSuffix(tag, ".att")
+		}
+		if ok && versions[strings.Replace(subject, "-", ":", 1)] {
+			return true
+		}
+	}
+	return false
+}
+
 // orasChildDiscoverer discovers children using ORAS.
 type orasChildDiscoverer struct {
 	resolver *orasResolver
@@ -126,7 +390,7 @@ func (d *orasChildDiscoverer) discoverChildren(ctx context.Context, image, diges
 	// Only discover children from index manifests
 	if desc.MediaType == ocispec.MediaTypeImageIndex ||
 		desc.MediaType == "application/vnd.docker.distribution.manifest.list.v2+json" {
-		children, err = d.discoverFromIndex(ctx, repo, desc)
+		children, err = fetchIndexChildren(ctx, repo, desc)
 		if err != nil {
 			return nil, err
 		}
@@ -139,17 +403,40 @@ func (d *orasChildDiscoverer) discoverChildren(ctx context.Context, image, diges
 	return children, nil
 }
 
-func (d *orasChildDiscoverer) discoverFromIndex(ctx context.Context, repo *remote.Repository, desc ocispec.Descriptor) ([]string, error) {
+// fetchIndexChildren returns the digests of the manifests listed in an index.
+// Indexes fetched before from the same repository are not fetched again.
+func fetchIndexChildren(ctx context.Context, repo *remote.Repository, desc ocispec.Descriptor) ([]string, error) {
+	key := manifestCacheKey(repo, desc.Digest.String())
+	manifestCacheMu.RLock()
+	children, ok := indexChildrenCache[key]
+	manifestCacheMu.RUnlock()
+	if ok {
+		return slices.Clone(children), nil
+	}
+
 	indexBytes, err := repo.Fetch(ctx, desc)
 	if err != nil {
 		return nil, err
 	}
 	defer indexBytes.Close()
 
-	var index ocispec.Index
-	if err := json.NewDecoder(indexBytes).Decode(&index); err != nil {
+	children, err = decodeIndexChildren(indexBytes, desc)
+	if err != nil {
 		return nil, err
 	}
+	manifestCacheMu.Lock()
+	indexChildrenCache[key] = slices.Clone(children)
+	manifestCacheMu.Unlock()
+	return children, nil
+}
+
+// decodeIndexChildren decodes the index described by desc and returns the
+// digests of the manifests it lists
+func decodeIndexChildren(r io.Reader, desc ocispec.Descriptor) ([]string, error) {
+	var index ocispec.Index
+	if err := json.NewDecoder(r).Decode(&index); err != nil {
+		return nil, &ManifestParseError{Digest: desc.Digest.String(), MediaType: desc.MediaType, Err: err}
+	}
 
 	var children []string
 	for _, manifest := range index.Manifests {
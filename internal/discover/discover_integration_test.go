@@ -36,8 +36,9 @@ func TestResolveVersionInfo_Integration_Index(t *testing.T) {
 	require.NoError(t, err, "Failed to resolve tag")
 
 	// Now resolve the version info for the index
-	types, size, err := resolver.resolveVersionInfo(ctx, image, digest)
+	info, err := resolver.resolveVersionInfo(ctx, image, digest)
 	require.NoError(t, err, "ResolveVersionInfo failed")
+	types, size := info.Types, info.Size
 
 	// Multi-arch image should be an index
 	require.NotEmpty(t, types, "Expected at least one type")
@@ -64,8 +65,9 @@ func TestResolveVersionInfo_Integration_Platform(t *testing.T) {
 	digest, err := ResolveTag(ctx, image, "latest")
 	require.NoError(t, err, "Failed to resolve tag")
 
-	types, size, err := resolver.resolveVersionInfo(ctx, image, digest)
+	info, err := resolver.resolveVersionInfo(ctx, image, digest)
 	require.NoError(t, err, "ResolveVersionInfo failed")
+	types, size := info.Types, info.Size
 
 	require.NotEmpty(t, types, "Expected at least one type")
 
@@ -91,7 +93,7 @@ func TestResolveVersionInfo_Integration_InvalidDigest(t *testing.T) {
 	// Valid format but non-existent digest
 	invalidDigest := "sha256:0000000000000000000000000000000000000000000000000000000000000000"
 
-	_, _, err := resolver.resolveVersionInfo(ctx, image, invalidDigest)
+	_, err := resolver.resolveVersionInfo(ctx, image, invalidDigest)
 	assert.Error(t, err, "Expected error for non-existent digest")
 }
 
@@ -148,11 +150,11 @@ func TestGetArtifactContent_Integration_SBOM(t *testing.T) {
 	// Find an SBOM among the children
 	var sbomDigest string
 	for _, childDigest := range children {
-		types, _, err := resolver.resolveVersionInfo(ctx, image, childDigest)
+		info, err := resolver.resolveVersionInfo(ctx, image, childDigest)
 		if err != nil {
 			continue
 		}
-		for _, typ := range types {
+		for _, typ := range info.Types {
 			if typ == "sbom" {
 				sbomDigest = childDigest
 				break
@@ -2,12 +2,16 @@ package discover
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/mkoepf/ghcrctl/internal/gh"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -207,3 +211,461 @@ func TestDiscoverPackage_IncomingRefsInferred(t *testing.T) {
 	require.Len(t, platformVersion.IncomingRefs, 1)
 	assert.Equal(t, "sha256:index1", platformVersion.IncomingRefs[0])
 }
+
+func TestDiscoverPackage_DeterministicOrder(t *testing.T) {
+	t.Parallel()
+	// Two indexes share the amd64 manifest; the index lists arm64 first
+	types := map[string][]string{
+		"sha256:index1":  {"index"},
+		"sha256:index2":  {"index"},
+		"sha256:arm64":   {"linux/arm64"},
+		"sha256:amd64":   {"linux/amd64"},
+		"sha256:attest1": {"sbom"},
+		"sha256:attest0": {"provenance"},
+	}
+	children := map[string][]string{
+		"sha256:index1": {"sha256:attest1", "sha256:arm64", "sha256:attest0", "sha256:amd64"},
+		"sha256:index2": {"sha256:amd64"},
+	}
+	discoverer := &PackageDiscoverer{
+		resolver: &mockResolver{resolveFunc: func(ctx context.Context, image, digest string) ([]string, error) {
+			return types[digest], nil
+		}},
+		childDiscoverer: &mockChildDiscoverer{discoverFunc: func(ctx context.Context, image, digest string, allTags []string) ([]string, error) {
+			return children[digest], nil
+		}},
+	}
+	versions := []gh.PackageVersionInfo{
+		{ID: 3, Digest: "sha256:arm64"},
+		{ID: 6, Digest: "sha256:index2", Tags: []string{"v2"}},
+		{ID: 1, Digest: "sha256:attest1"},
+		{ID: 5, Digest: "sha256:index1", Tags: []string{"v1"}},
+		{ID: 2, Digest: "sha256:amd64"},
+		{ID: 4, Digest: "sha256:attest0"},
+	}
+
+	var first []byte
+	for i := 0; i < 20; i++ {
+		results, err := discoverer.DiscoverPackage(context.Background(), "ghcr.io/test/image", versions, nil)
+		require.NoError(t, err)
+		data, err := json.Marshal(results)
+		require.NoError(t, err)
+		if i == 0 {
+			first = data
+
+			var ids []int64
+			for _, v := range results {
+				ids = append(ids, v.ID)
+			}
+			assert.Equal(t, []int64{6, 5, 4, 3, 2, 1}, ids)
+			index1 := ToMap(results)["sha256:index1"]
+			assert.Equal(t, []string{"sha256:amd64", "sha256:arm64", "sha256:attest0", "sha256:attest1"}, index1.OutgoingRefs)
+			amd64 := ToMap(results)["sha256:amd64"]
+			assert.Equal(t, []string{"sha256:index1", "sha256:index2"}, amd64.IncomingRefs)
+			continue
+		}
+		assert.Equal(t, string(first), string(data), "run %d", i)
+	}
+}
+
+func TestDiscoverPackage_DuplicateDigests(t *testing.T) {
+	t.Parallel()
+	var resolved sync.Map
+	discoverer := &PackageDiscoverer{
+		resolver: &mockResolver{
+			resolveFunc: func(ctx context.Context, image, digest string) ([]string, error) {
+				_, again := resolved.LoadOrStore(digest, true)
+				assert.False(t, again, "digest %s resolved twice", digest)
+				if digest == "sha256:index1" {
+					return []string{"index"}, nil
+				}
+				return []string{"linux/amd64"}, nil
+			},
+		},
+		childDiscoverer: &mockChildDiscoverer{
+			discoverFunc: func(ctx context.Context, image, digest string, allTags []string) ([]string, error) {
+				if digest == "sha256:index1" {
+					return []string{"sha256:platform1"}, nil
+				}
+				return nil, nil
+			},
+		},
+	}
+
+	// The index was re-pushed and GHCR lists it under two version IDs
+	tags := make([]string, 1, 4) // spare capacity would expose appends to the caller's slice
+	tags[0] = "v1.0.0"
+	versions := []gh.PackageVersionInfo{
+		{ID: 3, Digest: "sha256:index1", Tags: tags, CreatedAt: "2025-01-16"},
+		{ID: 2, Digest: "sha256:platform1", CreatedAt: "2025-01-15"},
+		{ID: 1, Digest: "sha256:index1", Tags: []string{"latest", "v1.0.0"}, CreatedAt: "2025-01-15"},
+	}
+
+	results, err := discoverer.DiscoverPackage(context.Background(), "ghcr.io/test/image", versions, nil)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	index := ToMap(results)["sha256:index1"]
+	assert.Equal(t, int64(3), index.ID, "the first listed version keeps its ID")
+	assert.Equal(t, []int64{1}, index.DuplicateIDs)
+	assert.Equal(t, []int64{3, 1}, index.VersionIDs())
+	assert.Equal(t, []string{"v1.0.0", "latest"}, index.Tags)
+	assert.Equal(t, []string{"v1.0.0", ""}, tags[:2], "input tags are not modified")
+	assert.Equal(t, []string{"sha256:index1"}, ToMap(results)["sha256:platform1"].IncomingRefs)
+
+	roots, err := (&PackageDiscoverer{rootResolver: &mockRootResolver{
+		resolveFunc: func(ctx context.Context, image, digest string) (resolvedVersion, []string, error) {
+			return resolvedVersion{Types: []string{"index"}}, []string{"sha256:platform1"}, nil
+		},
+	}}).DiscoverRoots(context.Background(), "ghcr.io/test/image", versions)
+	require.NoError(t, err)
+	require.Len(t, roots, 2, "each digest is reported once")
+	assert.Equal(t, []int64{3, 1}, ToMap(roots)["sha256:index1"].VersionIDs())
+}
+
+func TestDiscoverPackage_TagOnTwoDigests(t *testing.T) {
+	t.Parallel()
+	discoverer := &PackageDiscoverer{
+		resolver: &mockResolver{
+			resolveFunc: func(ctx context.Context, image, digest string) ([]string, error) {
+				return []string{"index"}, nil
+			},
+		},
+		childDiscoverer: &mockChildDiscoverer{
+			discoverFunc: func(ctx context.Context, image, digest string, allTags []string) ([]string, error) {
+				return nil, nil
+			},
+		},
+	}
+
+	// Two racing pushes left latest on both images
+	versions := []gh.PackageVersionInfo{
+		{ID: 3, Digest: "sha256:new", Tags: []string{"latest", "v2"}},
+		{ID: 2, Digest: "sha256:old", Tags: []string{"v1", "latest"}},
+		{ID: 1, Digest: "sha256:other", Tags: []string{"v0"}},
+	}
+	results, err := discoverer.DiscoverPackage(context.Background(), "ghcr.io/test/image", versions, nil)
+	require.NoError(t, err)
+
+	want := []string{`tag "latest" is listed on 2 digests: sha256:new, sha256:old`}
+	byDigest := ToMap(results)
+	assert.Equal(t, want, byDigest["sha256:new"].Warnings)
+	assert.Equal(t, want, byDigest["sha256:old"].Warnings)
+	assert.Empty(t, byDigest["sha256:other"].Warnings)
+	assert.Equal(t, want, Warnings(results), "each warning is reported once")
+
+	infos := mergeDuplicateDigests(versions)
+	assert.Equal(t, want, markTagConflicts(infos))
+}
+
+func TestMarkTagConflicts_DuplicateDigestIsNoConflict(t *testing.T) {
+	t.Parallel()
+	infos := mergeDuplicateDigests([]gh.PackageVersionInfo{
+		{ID: 2, Digest: "sha256:index", Tags: []string{"latest"}},
+		{ID: 1, Digest: "sha256:index", Tags: []string{"latest"}},
+	})
+	assert.Empty(t, markTagConflicts(infos))
+	assert.Empty(t, infos[0].Warnings)
+}
+
+func TestDiscoverPackage_PopulatesSizeAndMediaType(t *testing.T) {
+	mockResolver := &mockResolver{
+		resolveFunc: func(ctx context.Context, image, digest string) ([]string, error) {
+			switch digest {
+			case "sha256:index1":
+				return []string{"index"}, nil
+			case "sha256:platform1":
+				return []string{"linux/amd64"}, nil
+			case "sha256:sbom1":
+				return []string{"sbom"}, nil
+			default:
+				return nil, fmt.Errorf("manifest unknown")
+			}
+		},
+	}
+
+	mockDiscoverer := &mockChildDiscoverer{
+		discoverFunc: func(ctx context.Context, image, digest string, allTags []string) ([]string, error) {
+			return nil, nil
+		},
+	}
+
+	versions := []gh.PackageVersionInfo{
+		{ID: 1, Digest: "sha256:index1"},
+		{ID: 2, Digest: "sha256:platform1"},
+		{ID: 3, Digest: "sha256:sbom1"},
+		{ID: 4, Digest: "sha256:gone"},
+	}
+
+	discoverer := &PackageDiscoverer{
+		resolver:        mockResolver,
+		childDiscoverer: mockDiscoverer,
+	}
+
+	results, err := discoverer.DiscoverPackage(context.Background(), "ghcr.io/test/image", versions, nil)
+	require.NoError(t, err)
+
+	byDigest := ToMap(results)
+
+	assert.Equal(t, ocispec.MediaTypeImageIndex, byDigest["sha256:index1"].MediaType)
+	assert.Equal(t, int64(1024), byDigest["sha256:index1"].Size)
+
+	assert.Equal(t, ocispec.MediaTypeImageManifest, byDigest["sha256:platform1"].MediaType)
+	assert.Equal(t, int64(1024), byDigest["sha256:platform1"].Size)
+
+	assert.Equal(t, ocispec.MediaTypeImageManifest, byDigest["sha256:sbom1"].MediaType)
+	assert.Equal(t, int64(1024), byDigest["sha256:sbom1"].Size)
+
+	// Unresolvable versions stay in the result with zero size and empty media type
+	gone := byDigest["sha256:gone"]
+	assert.Equal(t, []string{"unknown"}, gone.Types)
+	assert.Zero(t, gone.Size)
+	assert.Empty(t, gone.MediaType)
+}
+
+// mockRootResolver implements rootResolver for testing
+type mockRootResolver struct {
+	resolveFunc func(ctx context.Context, image, digest string) (resolvedVersion, []string, error)
+	calls       atomic.Int32
+}
+
+func (m *mockRootResolver) resolveRoot(ctx context.Context, image, digest string) (resolvedVersion, []string, error) {
+	m.calls.Add(1)
+	return m.resolveFunc(ctx, image, digest)
+}
+
+// rootsFixture is a package with a multi-arch image, its in-index attestation,
+// a cosign signature, a single-arch image, an orphaned SBOM, an index nested in
+// another index, and a version the registry no longer knows.
+var rootsFixture = struct {
+	versions []gh.PackageVersionInfo
+	types    map[string][]string
+	children map[string][]string
+}{
+	versions: []gh.PackageVersionInfo{
+		{ID: 1, Digest: "sha256:index1", Tags: []string{"v1.0.0"}},
+		{ID: 2, Digest: "sha256:amd64", Tags: nil},
+		{ID: 3, Digest: "sha256:arm64", Tags: nil},
+		{ID: 4, Digest: "sha256:attest1", Tags: nil},
+		{ID: 5, Digest: "sha256:sig1", Tags: []string{"sha256-index1.sig"}},
+		{ID: 6, Digest: "sha256:single", Tags: []string{"v0.9.0"}},
+		{ID: 7, Digest: "sha256:orphansbom", Tags: nil},
+		{ID: 8, Digest: "sha256:outer", Tags: []string{"bundle"}},
+		{ID: 9, Digest: "sha256:gone", Tags: nil},
+	},
+	types: map[string][]string{
+		"sha256:index1":     {"index"},
+		"sha256:amd64":      {"linux/amd64"},
+		"sha256:arm64":      {"linux/arm64"},
+		"sha256:attest1":    {"sbom", "provenance"},
+		"sha256:sig1":       {"signature"},
+		"sha256:single":     {"linux/amd64"},
+		"sha256:orphansbom": {"sbom"},
+		"sha256:outer":      {"index"},
+	},
+	children: map[string][]string{
+		"sha256:index1": {"sha256:amd64", "sha256:arm64", "sha256:attest1"},
+		"sha256:outer":  {"sha256:index1"},
+	},
+}
+
+func rootsFixtureDiscoverer() (*PackageDiscoverer, *mockRootResolver) {
+	fixture := rootsFixture
+	roots := &mockRootResolver{
+		resolveFunc: func(ctx context.Context, image, digest string) (resolvedVersion, []string, error) {
+			types, ok := fixture.types[digest]
+			if !ok {
+				return resolvedVersion{}, nil, fmt.Errorf("manifest unknown")
+			}
+			if types[0] == "index" {
+				return resolvedVersion{Types: types, Size: 512, MediaType: ocispec.MediaTypeImageIndex}, fixture.children[digest], nil
+			}
+			return resolvedVersion{Types: []string{"manifest"}, Size: 1024, MediaType: ocispec.MediaTypeImageManifest}, nil, nil
+		},
+	}
+	return &PackageDiscoverer{
+		resolver: &mockResolver{
+			resolveFunc: func(ctx context.Context, image, digest string) ([]string, error) {
+				if types, ok := fixture.types[digest]; ok {
+					return types, nil
+				}
+				return nil, fmt.Errorf("manifest unknown")
+			},
+		},
+		childDiscoverer: &mockChildDiscoverer{
+			discoverFunc: func(ctx context.Context, image, digest string, allTags []string) ([]string, error) {
+				children := fixture.children[digest]
+				if digest == "sha256:index1" {
+					children = append(children, "sha256:sig1")
+				}
+				return children, nil
+			},
+		},
+		rootResolver: roots,
+	}, roots
+}
+
+func TestDiscoverRoots_MatchesFullDiscovery(t *testing.T) {
+	t.Parallel()
+	discoverer, _ := rootsFixtureDiscoverer()
+	var allTags []string
+	for _, v := range rootsFixture.versions {
+		allTags = append(allTags, v.Tags...)
+	}
+
+	full, err := discoverer.DiscoverPackage(context.Background(), "ghcr.io/test/image", rootsFixture.versions, allTags)
+	require.NoError(t, err)
+	fullMap := ToMap(full)
+	var wantRoots []string
+	for _, v := range full {
+		if v.IsRoot(fullMap) {
+			wantRoots = append(wantRoots, v.Digest)
+		}
+	}
+
+	roots, err := discoverer.DiscoverRoots(context.Background(), "ghcr.io/test/image", rootsFixture.versions)
+	require.NoError(t, err)
+	var gotRoots []string
+	for _, v := range roots {
+		gotRoots = append(gotRoots, v.Digest)
+	}
+
+	assert.ElementsMatch(t, wantRoots, gotRoots)
+	assert.ElementsMatch(t, []string{"sha256:index1", "sha256:single", "sha256:orphansbom", "sha256:outer", "sha256:gone"}, gotRoots)
+}
+
+func TestDiscoverRoots_TypesSizesAndTags(t *testing.T) {
+	t.Parallel()
+	discoverer, resolver := rootsFixtureDiscoverer()
+
+	roots, err := discoverer.DiscoverRoots(context.Background(), "ghcr.io/test/image", rootsFixture.versions)
+	require.NoError(t, err)
+	byDigest := ToMap(roots)
+
+	index := byDigest["sha256:index1"]
+	assert.Equal(t, []string{"index"}, index.Types)
+	assert.Equal(t, int64(512), index.Size)
+	assert.Equal(t, []string{"v1.0.0"}, index.Tags)
+	assert.Empty(t, index.OutgoingRefs, "roots-only discovery does not record refs")
+
+	// Non-index roots are not fetched, so they are not classified further
+	assert.Equal(t, []string{"manifest"}, byDigest["sha256:orphansbom"].Types)
+	assert.Equal(t, int64(1024), byDigest["sha256:single"].Size)
+	assert.Equal(t, []string{"unknown"}, byDigest["sha256:gone"].Types)
+
+	// Every version is resolved once; no other registry calls are made
+	assert.Equal(t, int32(len(rootsFixture.versions)), resolver.calls.Load())
+}
+
+func TestSignsVersionIn(t *testing.T) {
+	t.Parallel()
+	versions := map[string]bool{"sha256:abc": true}
+
+	assert.True(t, signsVersionIn([]string{"latest", "sha256-abc.sig"}, versions))
+	assert.True(t, signsVersionIn([]string{"sha256-abc.att"}, versions))
+	assert.False(t, signsVersionIn([]string{"sha256-def.sig"}, versions), "subject outside the package")
+	assert.False(t, signsVersionIn([]string{"sha256-abc"}, versions))
+	assert.False(t, signsVersionIn(nil, versions))
+}
+
+func TestDiscoverPackage_Strict(t *testing.T) {
+	t.Parallel()
+	versions := []gh.PackageVersionInfo{
+		{ID: 1, Digest: "sha256:index1", Tags: []string{"v1.0.0"}},
+		{ID: 2, Digest: "sha256:amd64"},
+		{ID: 3, Digest: "sha256:broken"},
+	}
+	newDiscoverer := func(strict bool) *PackageDiscoverer {
+		return &PackageDiscoverer{
+			Strict: strict,
+			resolver: &mockResolver{
+				resolveFunc: func(ctx context.Context, image, digest string) ([]string, error) {
+					switch digest {
+					case "sha256:index1":
+						return []string{"index"}, nil
+					case "sha256:broken":
+						return nil, &ManifestParseError{Digest: digest, MediaType: ocispec.MediaTypeImageManifest, Err: fmt.Errorf("unexpected EOF")}
+					default:
+						return []string{"linux/amd64"}, nil
+					}
+				},
+			},
+			childDiscoverer: &mockChildDiscoverer{
+				discoverFunc: func(ctx context.Context, image, digest string, allTags []string) ([]string, error) {
+					if digest == "sha256:index1" {
+						return []string{"sha256:amd64"}, nil
+					}
+					return nil, nil
+				},
+			},
+		}
+	}
+
+	t.Run("lenient skips the manifest", func(t *testing.T) {
+		t.Parallel()
+		results, err := newDiscoverer(false).DiscoverPackage(context.Background(), "ghcr.io/test/image", versions, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"unknown"}, ToMap(results)["sha256:broken"].Types)
+	})
+
+	t.Run("strict fails", func(t *testing.T) {
+		t.Parallel()
+		_, err := newDiscoverer(true).DiscoverPackage(context.Background(), "ghcr.io/test/image", versions, nil)
+		assert.EqualError(t, err, "failed to parse sha256:broken (application/vnd.oci.image.manifest.v1+json): unexpected EOF")
+		var parseErr *ManifestParseError
+		assert.ErrorAs(t, err, &parseErr)
+	})
+
+	t.Run("strict ignores failed requests", func(t *testing.T) {
+		t.Parallel()
+		d := newDiscoverer(true)
+		d.resolver = &mockResolver{
+			resolveFunc: func(ctx context.Context, image, digest string) ([]string, error) {
+				return nil, fmt.Errorf("failed to resolve digest: not found")
+			},
+		}
+		_, err := d.DiscoverPackage(context.Background(), "ghcr.io/test/image", versions, nil)
+		assert.NoError(t, err)
+	})
+}
+
+func TestDiscoverRoots_Strict(t *testing.T) {
+	t.Parallel()
+	versions := []gh.PackageVersionInfo{
+		{ID: 1, Digest: "sha256:index1"},
+		{ID: 2, Digest: "sha256:index2"},
+	}
+	d := &PackageDiscoverer{
+		Strict: true,
+		rootResolver: &mockRootResolver{
+			resolveFunc: func(ctx context.Context, image, digest string) (resolvedVersion, []string, error) {
+				return resolvedVersion{}, nil, fmt.Errorf("failed to fetch index: %w",
+					&ManifestParseError{Digest: digest, MediaType: ocispec.MediaTypeImageIndex, Err: fmt.Errorf("invalid character 'x'")})
+			},
+		},
+	}
+
+	_, err := d.DiscoverRoots(context.Background(), "ghcr.io/test/image", versions)
+	assert.EqualError(t, err, "2 manifests cannot be parsed:\n"+
+		"  failed to parse sha256:index1 (application/vnd.oci.image.index.v1+json): invalid character 'x'\n"+
+		"  failed to parse sha256:index2 (application/vnd.oci.image.index.v1+json): invalid character 'x'")
+}
+
+func TestDecodeIndexChildren(t *testing.T) {
+	t.Parallel()
+	desc := ocispec.Descriptor{MediaType: ocispec.MediaTypeImageIndex, Digest: "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"}
+
+	children, err := decodeIndexChildren(strings.NewReader(`{"manifests": [{"digest": "sha256:amd64"}]}`), desc)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sha256:amd64"}, children)
+
+	_, err = decodeIndexChildren(strings.NewReader(`{"manifests": [`), desc)
+	var parseErr *ManifestParseError
+	require.ErrorAs(t, err, &parseErr)
+	assert.Equal(t, desc.Digest.String(), parseErr.Digest)
+	assert.Equal(t, ocispec.MediaTypeImageIndex, parseErr.MediaType)
+
+	_, err = decodeManifest(strings.NewReader(`["not", "a", "manifest"]`), ocispec.Descriptor{MediaType: ocispec.MediaTypeImageManifest, Digest: desc.Digest})
+	require.ErrorAs(t, err, &parseErr)
+	assert.Equal(t, ocispec.MediaTypeImageManifest, parseErr.MediaType)
+}
@@ -0,0 +1,61 @@
+package discover
+
+import (
+	"encoding/base64"
+	"encoding/json"
+)
+
+// dsseEnvelopeMediaType is the layer media type of DSSE-wrapped attestations,
+// as stored by cosign under sha256-<hex>.att tags
+const dsseEnvelopeMediaType = "application/vnd.dsse.envelope.v1+json"
+
+// InTotoStatement returns doc itself if it is an in-toto statement, or the
+// statement decoded from the base64 payload of a DSSE envelope. It returns nil
+// if neither applies.
+func InTotoStatement(doc map[string]interface{}) map[string]interface{} {
+	if _, ok := doc["predicateType"]; ok {
+		return doc
+	}
+
+	payload, ok := doc["payload"].(string)
+	if !ok {
+		return nil
+	}
+	decoded, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return nil
+	}
+	var statement map[string]interface{}
+	if err := json.Unmarshal(decoded, &statement); err != nil {
+		return nil
+	}
+	return statement
+}
+
+// UnwrapDSSE replaces DSSE envelopes in attestations with the in-toto
+// statements they carry, so cosign attestations display like buildx ones.
+// Documents that are not envelopes, or cannot be decoded, are kept as they are.
+func UnwrapDSSE(attestations []map[string]interface{}) []map[string]interface{} {
+	unwrapped := make([]map[string]interface{}, len(attestations))
+	for i, doc := range attestations {
+		unwrapped[i] = doc
+		if statement := InTotoStatement(doc); statement != nil {
+			unwrapped[i] = statement
+		}
+	}
+	return unwrapped
+}
+
+// envelopeRole returns the artifact role of the statement in a DSSE envelope,
+// or "" if data is not an envelope with a predicate type.
+func envelopeRole(data []byte) string {
+	var envelope map[string]interface{}
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return ""
+	}
+	predicateType, _ := InTotoStatement(envelope)["predicateType"].(string)
+	if predicateType == "" {
+		return ""
+	}
+	return predicateToRole(predicateType)
+}
@@ -0,0 +1,70 @@
+package discover
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// cosignSBOMStatement is the in-toto statement inside the cosign .att fixture
+const cosignSBOMStatement = `{"_type":"https://in-toto.io/Statement/v0.1",` +
+	`"predicateType":"https://spdx.dev/Document",` +
+	`"subject":[{"name":"ghcr.io/mkoepf/myimage","digest":{"sha256":"aaaa"}}],` +
+	`"predicate":{"spdxVersion":"SPDX-2.3","name":"myimage"}}`
+
+// cosignAttestationLayer returns a DSSE envelope as stored by cosign attest
+// in the layer of a sha256-<hex>.att manifest
+func cosignAttestationLayer(statement string) map[string]interface{} {
+	return map[string]interface{}{
+		"payloadType": "application/vnd.in-toto+json",
+		"payload":     base64.StdEncoding.EncodeToString([]byte(statement)),
+		"signatures":  []interface{}{map[string]interface{}{"keyid": "", "sig": "MEUCIQ=="}},
+	}
+}
+
+func TestInTotoStatement(t *testing.T) {
+	t.Parallel()
+
+	statement := InTotoStatement(cosignAttestationLayer(cosignSBOMStatement))
+	require.NotNil(t, statement)
+	assert.Equal(t, "https://spdx.dev/Document", statement["predicateType"])
+	assert.Equal(t, map[string]interface{}{"spdxVersion": "SPDX-2.3", "name": "myimage"}, statement["predicate"])
+
+	plain := map[string]interface{}{"predicateType": "https://slsa.dev/provenance/v1"}
+	assert.Equal(t, plain, InTotoStatement(plain), "plain statements are returned as they are")
+
+	assert.Nil(t, InTotoStatement(map[string]interface{}{"payload": "not base64!"}))
+	assert.Nil(t, InTotoStatement(map[string]interface{}{"spdxVersion": "SPDX-2.3"}))
+}
+
+func TestUnwrapDSSE(t *testing.T) {
+	t.Parallel()
+
+	plain := map[string]interface{}{"predicateType": "https://slsa.dev/provenance/v1"}
+	other := map[string]interface{}{"spdxVersion": "SPDX-2.3"}
+	unwrapped := UnwrapDSSE([]map[string]interface{}{cosignAttestationLayer(cosignSBOMStatement), plain, other})
+
+	require.Len(t, unwrapped, 3)
+	assert.Equal(t, "https://spdx.dev/Document", unwrapped[0]["predicateType"])
+	assert.NotContains(t, unwrapped[0], "payload")
+	assert.Equal(t, plain, unwrapped[1])
+	assert.Equal(t, other, unwrapped[2])
+}
+
+func TestEnvelopeRole(t *testing.T) {
+	t.Parallel()
+
+	data, err := json.Marshal(cosignAttestationLayer(cosignSBOMStatement))
+	require.NoError(t, err)
+	assert.Equal(t, "sbom", envelopeRole(data))
+
+	data, err = json.Marshal(cosignAttestationLayer(`{"predicateType":"https://slsa.dev/provenance/v0.2"}`))
+	require.NoError(t, err)
+	assert.Equal(t, "provenance", envelopeRole(data))
+
+	assert.Empty(t, envelopeRole([]byte(`{"payload":"e30="}`)), "statement without predicate type")
+	assert.Empty(t, envelopeRole([]byte(`not json`)))
+}
@@ -0,0 +1,136 @@
+package discover
+
+import (
+	"sort"
+
+	"github.com/mkoepf/ghcrctl/internal/gh"
+)
+
+// Roles of an edge, i.e. how the child relates to its parent
+const (
+	EdgeRoleManifest = "manifest" // a platform or other manifest listed in an index
+	EdgeRoleReferrer = "referrer" // a signature or attestation of the parent
+)
+
+// GraphNode is a version in the explicit graph of list graphs --graph-edges
+type GraphNode struct {
+	ID           int64    `json:"id"`
+	DuplicateIDs []int64  `json:"duplicate_ids,omitempty"`
+	Digest       string   `json:"digest"`
+	Tags         []string `json:"tags"`
+	Types        []string `json:"types"`
+	Size         int64    `json:"size"`
+	MediaType    string   `json:"media_type"`
+	CreatedAt    string   `json:"created_at"` // RFC3339
+}
+
+// GraphEdge is a reference from a parent version to a child version. Referrers
+// are children of the version they are attached to.
+type GraphEdge struct {
+	ParentID     int64  `json:"parent_id"`
+	ChildID      int64  `json:"child_id"`
+	ParentDigest string `json:"parent_digest"`
+	ChildDigest  string `json:"child_digest"`
+	Role         string `json:"role"`
+}
+
+// Graph is the version graph as node and edge lists, ready to be loaded into
+// graph databases and visualizers without joining digests to IDs
+type Graph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+// BuildGraph returns the nodes of versions, sorted by ID descending, and an
+// edge for every OutgoingRef between them. Refs to digests outside versions
+// have no version ID and are left out. Nodes and Edges are never nil, so that
+// they marshal to empty JSON arrays.
+func BuildGraph(versions []VersionInfo) Graph {
+	sorted := make([]VersionInfo, len(versions))
+	copy(sorted, versions)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].ID > sorted[j].ID
+	})
+	byDigest := ToMap(sorted)
+
+	graph := Graph{
+		Nodes: make([]GraphNode, 0, len(sorted)),
+		Edges: []GraphEdge{},
+	}
+	for _, v := range sorted {
+		graph.Nodes = append(graph.Nodes, newGraphNode(v))
+		for _, ref := range v.OutgoingRefs {
+			child, ok := byDigest[ref]
+			if !ok {
+				continue
+			}
+			role := EdgeRoleManifest
+			if child.IsReferrer() {
+				role = EdgeRoleReferrer
+			}
+			graph.Edges = append(graph.Edges, GraphEdge{
+				ParentID:     v.ID,
+				ChildID:      child.ID,
+				ParentDigest: v.Digest,
+				ChildDigest:  child.Digest,
+				Role:         role,
+			})
+		}
+	}
+	return graph
+}
+
+// newGraphNode returns the node of a version, without its refs
+func newGraphNode(v VersionInfo) GraphNode {
+	return GraphNode{
+		ID:           v.ID,
+		DuplicateIDs: v.DuplicateIDs,
+		Digest:       v.Digest,
+		Tags:         v.Tags,
+		Types:        v.Types,
+		Size:         v.Size,
+		MediaType:    v.MediaType,
+		CreatedAt:    gh.RFC3339Timestamp(v.CreatedAt),
+	}
+}
+
+// ImageObject is a single graph as one nested object: a version with the
+// manifests it lists and the referrers attached to it, each nested the same
+// way. Manifests and Referrers are never nil.
+type ImageObject struct {
+	GraphNode
+	Manifests []ImageObject `json:"manifests"`
+	Referrers []ImageObject `json:"referrers"`
+}
+
+// BuildImageObject returns the graph below root as an ImageObject. Children are
+// split by the roles of BuildGraph and keep the order of OutgoingRefs. Refs to
+// digests outside versions are left out, and a version is not nested inside
+// itself, so that cycles end.
+func BuildImageObject(root VersionInfo, versions map[string]VersionInfo) ImageObject {
+	return buildImageObject(root, versions, make(map[string]bool))
+}
+
+func buildImageObject(v VersionInfo, versions map[string]VersionInfo, onPath map[string]bool) ImageObject {
+	onPath[v.Digest] = true
+	defer delete(onPath, v.Digest)
+
+	object := ImageObject{
+		GraphNode: newGraphNode(v),
+		Manifests: []ImageObject{},
+		Referrers: []ImageObject{},
+	}
+	for _, ref := range v.OutgoingRefs {
+		child, ok := versions[ref]
+		if !ok || onPath[ref] {
+			continue
+		}
+		nested := buildImageObject(child, versions, onPath)
+		if child.IsReferrer() {
+			object.Referrers = append(object.Referrers, nested)
+		} else {
+			object.Manifests = append(object.Manifests, nested)
+		}
+	}
+	return object
+}
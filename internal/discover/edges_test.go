@@ -0,0 +1,91 @@
+package discover
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildGraph_MultiArchWithAttestations(t *testing.T) {
+	t.Parallel()
+	// An index with two platforms and their buildx attestations, and a cosign
+	// signature of the index. One ref points outside the listed versions.
+	versions := []VersionInfo{
+		{ID: 2, Digest: "sha256:amd64", Types: []string{"linux/amd64"}, IncomingRefs: []string{"sha256:index"}},
+		{ID: 1, Digest: "sha256:index", Types: []string{"index"}, Tags: []string{"v1"},
+			OutgoingRefs: []string{"sha256:amd64", "sha256:arm64", "sha256:att-amd64", "sha256:att-arm64", "sha256:sig", "sha256:deleted"}},
+		{ID: 3, Digest: "sha256:arm64", Types: []string{"linux/arm64"}, IncomingRefs: []string{"sha256:index"}},
+		{ID: 4, Digest: "sha256:att-amd64", Types: []string{"sbom", "provenance"}, IncomingRefs: []string{"sha256:index"}},
+		{ID: 5, Digest: "sha256:att-arm64", Types: []string{"sbom", "provenance"}, IncomingRefs: []string{"sha256:index"}},
+		{ID: 6, Digest: "sha256:sig", Types: []string{"signature"}, IncomingRefs: []string{"sha256:index"}},
+	}
+
+	graph := BuildGraph(versions)
+
+	var nodeIDs []int64
+	for _, n := range graph.Nodes {
+		nodeIDs = append(nodeIDs, n.ID)
+	}
+	assert.Equal(t, []int64{6, 5, 4, 3, 2, 1}, nodeIDs)
+
+	// Every edge points from the index to a child, the missing ref is left out
+	assert.Equal(t, []GraphEdge{
+		{ParentID: 1, ChildID: 2, ParentDigest: "sha256:index", ChildDigest: "sha256:amd64", Role: EdgeRoleManifest},
+		{ParentID: 1, ChildID: 3, ParentDigest: "sha256:index", ChildDigest: "sha256:arm64", Role: EdgeRoleManifest},
+		{ParentID: 1, ChildID: 4, ParentDigest: "sha256:index", ChildDigest: "sha256:att-amd64", Role: EdgeRoleReferrer},
+		{ParentID: 1, ChildID: 5, ParentDigest: "sha256:index", ChildDigest: "sha256:att-arm64", Role: EdgeRoleReferrer},
+		{ParentID: 1, ChildID: 6, ParentDigest: "sha256:index", ChildDigest: "sha256:sig", Role: EdgeRoleReferrer},
+	}, graph.Edges)
+}
+
+func TestBuildGraph_Empty(t *testing.T) {
+	t.Parallel()
+	data, err := json.Marshal(BuildGraph(nil))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"nodes": [], "edges": []}`, string(data))
+}
+
+func TestBuildImageObject(t *testing.T) {
+	t.Parallel()
+	// An index with two platforms, a buildx attestation and a cosign signature
+	// of the index, and a cosign signature of the amd64 platform
+	versions := ToMap([]VersionInfo{
+		{ID: 1, Digest: "sha256:index", Types: []string{"index"}, Tags: []string{"v1"}, Size: 100,
+			OutgoingRefs: []string{"sha256:amd64", "sha256:arm64", "sha256:att", "sha256:sig", "sha256:deleted"}},
+		{ID: 2, Digest: "sha256:amd64", Types: []string{"linux/amd64"}, Size: 200, OutgoingRefs: []string{"sha256:sig-amd64"}},
+		{ID: 3, Digest: "sha256:arm64", Types: []string{"linux/arm64"}, Size: 300},
+		{ID: 4, Digest: "sha256:att", Types: []string{"sbom", "provenance"}, Size: 400},
+		{ID: 5, Digest: "sha256:sig", Types: []string{"signature"}, Size: 500},
+		{ID: 6, Digest: "sha256:sig-amd64", Types: []string{"signature"}, Size: 600},
+	})
+
+	data, err := json.Marshal(BuildImageObject(versions["sha256:index"], versions))
+	require.NoError(t, err)
+	node := func(id int, digest, types string, size int, manifests, referrers string) string {
+		return fmt.Sprintf(`{"id": %d, "digest": %q, "tags": null, "types": [%s], "size": %d, "media_type": "", "created_at": "", "manifests": [%s], "referrers": [%s]}`,
+			id, digest, types, size, manifests, referrers)
+	}
+	assert.JSONEq(t, `{"id": 1, "digest": "sha256:index", "tags": ["v1"], "types": ["index"], "size": 100, "media_type": "", "created_at": "",
+		"manifests": [`+
+		node(2, "sha256:amd64", `"linux/amd64"`, 200, "", node(6, "sha256:sig-amd64", `"signature"`, 600, "", ""))+`, `+
+		node(3, "sha256:arm64", `"linux/arm64"`, 300, "", "")+`],
+		"referrers": [`+
+		node(4, "sha256:att", `"sbom", "provenance"`, 400, "", "")+`, `+
+		node(5, "sha256:sig", `"signature"`, 500, "", "")+`]}`, string(data))
+}
+
+func TestBuildImageObject_Cycle(t *testing.T) {
+	t.Parallel()
+	versions := ToMap([]VersionInfo{
+		{ID: 1, Digest: "sha256:a", Types: []string{"index"}, OutgoingRefs: []string{"sha256:b"}},
+		{ID: 2, Digest: "sha256:b", Types: []string{"index"}, OutgoingRefs: []string{"sha256:a"}},
+	})
+
+	object := BuildImageObject(versions["sha256:a"], versions)
+	require.Len(t, object.Manifests, 1)
+	assert.Equal(t, "sha256:b", object.Manifests[0].Digest)
+	assert.Empty(t, object.Manifests[0].Manifests)
+}
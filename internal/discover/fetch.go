@@ -12,7 +12,9 @@ import (
 	"sync"
 
 	"github.com/mkoepf/ghcrctl/internal/logging"
+	"github.com/mkoepf/ghcrctl/internal/ratelimit"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
 	"oras.land/oras-go/v2/registry/remote"
 	"oras.land/oras-go/v2/registry/remote/auth"
 	"oras.land/oras-go/v2/registry/remote/credentials"
@@ -20,11 +22,25 @@ import (
 
 // Package-level auth client cache to avoid redundant token fetches
 var (
-	authClientCache     *auth.Client
-	authClientCacheMu   sync.RWMutex
-	authClientCacheInit sync.Once
+	authClientCache   *auth.Client
+	authClientCacheMu sync.RWMutex
 )
 
+// Package-level caches of what was resolved from manifests. Manifests cannot
+// change under their digest, so entries stay valid, e.g. across the refreshes
+// of watch. Keys are registry/owner/package@digest, so the same digest in two
+// repositories is resolved separately.
+var (
+	resolvedCache      = make(map[string]resolvedVersion)
+	indexChildrenCache = make(map[string][]string)
+	manifestCacheMu    sync.RWMutex
+)
+
+// manifestCacheKey returns the cache key of digest in repo
+func manifestCacheKey(repo *remote.Repository, digest string) string {
+	return repo.Reference.Registry + "/" + repo.Reference.Repository + "@" + digest
+}
+
 // ResolveTag resolves an image tag to its digest using ORAS
 // image should be in format: registry/owner/repo (e.g., ghcr.io/owner/repo)
 // tag is the tag name (e.g., "latest", "v1.0.0")
@@ -55,8 +71,12 @@ func ResolveTag(ctx context.Context, image, tag string) (string, error) {
 		return "", fmt.Errorf("failed to configure authentication: %w", err)
 	}
 
-	// Resolve the tag to a descriptor
-	descriptor, err := repo.Resolve(ctx, tag)
+	// Resolve the tag to a descriptor, retrying transient registry errors
+	var descriptor ocispec.Descriptor
+	err = retryOnUnauthorized(ctx, repo, func() (err error) {
+		descriptor, err = resolveWithRetry(ctx, repo, tag, defaultRetryPolicy)
+		return err
+	})
 	if err != nil {
 		return "", fmt.Errorf("failed to resolve tag '%s': %w", tag, err)
 	}
@@ -72,6 +92,37 @@ func ResolveTag(ctx context.Context, image, tag string) (string, error) {
 	return digest, nil
 }
 
+// ListTags returns all tags of the repository referenced by image.
+func ListTags(ctx context.Context, image string) ([]string, error) {
+	registry, path, err := ParseImageReference(image)
+	if err != nil {
+		return nil, err
+	}
+
+	repo, err := remote.NewRepository(fmt.Sprintf("%s/%s", registry, path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create repository reference: %w", err)
+	}
+
+	if err := configureAuth(ctx, repo); err != nil {
+		return nil, fmt.Errorf("failed to configure authentication: %w", err)
+	}
+
+	var tags []string
+	err = retryOnUnauthorized(ctx, repo, func() error {
+		tags = nil
+		return repo.Tags(ctx, "", func(page []string) error {
+			tags = append(tags, page...)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+
+	return tags, nil
+}
+
 // ParseImageReference parses an image reference into registry and path components
 // Expected format: registry/owner/repo or registry/owner/org/repo
 // Returns: registry, path, error
@@ -161,13 +212,13 @@ func GetArtifactContent(ctx context.Context, image, digestStr string) ([]map[str
 
 	// Resolve the digest to get the full descriptor (with media type)
 	// ORAS Resolve can accept both tags and digests
-	desc, err := repo.Resolve(ctx, digestStr)
+	desc, err := resolveRefreshing(ctx, repo, digestStr)
 	if err != nil {
 		return nil, fmt.Errorf("failed to resolve digest: %w", err)
 	}
 
 	// Fetch the manifest
-	manifestBytes, err := repo.Fetch(ctx, desc)
+	manifestBytes, err := fetchRefreshing(ctx, repo, desc)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch manifest: %w", err)
 	}
@@ -185,7 +236,7 @@ func GetArtifactContent(ctx context.Context, image, digestStr string) ([]map[str
 	// Fetch each layer (attestations are stored in layers)
 	for _, layer := range manifest.Layers {
 		// Fetch the layer blob
-		layerBytes, err := repo.Fetch(ctx, layer)
+		layerBytes, err := fetchRefreshing(ctx, repo, layer)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Warning: failed to fetch layer %s: %v\n", layer.Digest.String(), err)
 			continue
@@ -209,6 +260,127 @@ func GetArtifactContent(ctx context.Context, image, digestStr string) ([]map[str
 	return attestations, nil
 }
 
+// FetchManifest fetches the raw manifest JSON for a digest, exactly as the registry returns it,
+// together with its media type.
+// image should be in format: registry/owner/repo (e.g., ghcr.io/owner/repo)
+func FetchManifest(ctx context.Context, image, digestStr string) ([]byte, string, error) {
+	// Validate inputs
+	if image == "" {
+		return nil, "", fmt.Errorf("image cannot be empty")
+	}
+	if !ValidateDigestFormat(digestStr) {
+		return nil, "", fmt.Errorf("invalid digest format: %s", digestStr)
+	}
+
+	// Parse image reference
+	registry, path, err := ParseImageReference(image)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Create repository reference
+	repo, err := remote.NewRepository(fmt.Sprintf("%s/%s", registry, path))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create repository reference: %w", err)
+	}
+
+	// Configure authentication
+	if err := configureAuth(ctx, repo); err != nil {
+		return nil, "", fmt.Errorf("failed to configure authentication: %w", err)
+	}
+
+	desc, err := resolveRefreshing(ctx, repo, digestStr)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to resolve digest: %w", err)
+	}
+
+	manifestReader, err := fetchRefreshing(ctx, repo, desc)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch manifest: %w", err)
+	}
+	defer manifestReader.Close()
+
+	manifestData, err := io.ReadAll(manifestReader)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read manifest: %w", err)
+	}
+	return manifestData, desc.MediaType, nil
+}
+
+// SelectPlatformManifest returns the digest of the manifest for a platform in an image index.
+// platform has the form os/arch or os/arch/variant, e.g. linux/amd64 or linux/arm64/v8.
+func SelectPlatformManifest(indexData []byte, platform string) (string, error) {
+	parts := strings.Split(platform, "/")
+	if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" {
+		return "", fmt.Errorf("invalid platform %q: expected os/arch or os/arch/variant", platform)
+	}
+
+	var index ocispec.Index
+	if err := json.Unmarshal(indexData, &index); err != nil {
+		return "", fmt.Errorf("failed to decode image index: %w", err)
+	}
+
+	var available []string
+	for _, desc := range index.Manifests {
+		if desc.Platform == nil {
+			continue
+		}
+		p := desc.Platform
+		available = append(available, platformName(p))
+
+		if p.OS != parts[0] || p.Architecture != parts[1] {
+			continue
+		}
+		if len(parts) == 3 && p.Variant != parts[2] {
+			continue
+		}
+		return desc.Digest.String(), nil
+	}
+
+	return "", fmt.Errorf("platform %s not found in image index (available: %s)", platform, strings.Join(available, ", "))
+}
+
+// PlatformManifest is a platform-specific manifest listed in an image index
+type PlatformManifest struct {
+	Platform string // os/arch or os/arch/variant
+	Digest   string
+}
+
+// ListPlatformManifests returns the platform manifests of an image index in index order.
+// Attestation manifests that buildx stores with an unknown/unknown platform are skipped.
+func ListPlatformManifests(indexData []byte) ([]PlatformManifest, error) {
+	var index ocispec.Index
+	if err := json.Unmarshal(indexData, &index); err != nil {
+		return nil, fmt.Errorf("failed to decode image index: %w", err)
+	}
+
+	var manifests []PlatformManifest
+	for _, desc := range index.Manifests {
+		if desc.Platform == nil || desc.Platform.OS == "unknown" {
+			continue
+		}
+		manifests = append(manifests, PlatformManifest{
+			Platform: platformName(desc.Platform),
+			Digest:   desc.Digest.String(),
+		})
+	}
+	return manifests, nil
+}
+
+// platformName formats a platform as os/arch or os/arch/variant
+func platformName(p *ocispec.Platform) string {
+	name := p.OS + "/" + p.Architecture
+	if p.Variant != "" {
+		name += "/" + p.Variant
+	}
+	return name
+}
+
+// IsIndexMediaType reports whether a media type is an OCI image index or a Docker manifest list.
+func IsIndexMediaType(mediaType string) bool {
+	return mediaType == ocispec.MediaTypeImageIndex || mediaType == "application/vnd.docker.distribution.manifest.list.v2+json"
+}
+
 // GetImageConfig retrieves the image config blob which contains labels and other metadata
 func GetImageConfig(ctx context.Context, image, digestStr string) (*ocispec.Image, error) {
 	// Validate inputs
@@ -240,13 +412,13 @@ func GetImageConfig(ctx context.Context, image, digestStr string) (*ocispec.Imag
 	}
 
 	// Resolve the digest to get the full descriptor
-	desc, err := repo.Resolve(ctx, digestStr)
+	desc, err := resolveRefreshing(ctx, repo, digestStr)
 	if err != nil {
 		return nil, fmt.Errorf("failed to resolve digest: %w", err)
 	}
 
 	// Fetch the manifest
-	manifestReader, err := repo.Fetch(ctx, desc)
+	manifestReader, err := fetchRefreshing(ctx, repo, desc)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch manifest: %w", err)
 	}
@@ -270,7 +442,7 @@ func GetImageConfig(ctx context.Context, image, digestStr string) (*ocispec.Imag
 
 		// Fetch the first platform manifest
 		platformDesc := index.Manifests[0]
-		platformManifestBytes, err := repo.Fetch(ctx, platformDesc)
+		platformManifestBytes, err := fetchRefreshing(ctx, repo, platformDesc)
 		if err != nil {
 			return nil, fmt.Errorf("failed to fetch platform manifest: %w", err)
 		}
@@ -283,7 +455,7 @@ func GetImageConfig(ctx context.Context, image, digestStr string) (*ocispec.Imag
 		}
 
 		// Fetch the config blob from the platform manifest
-		configBytes, err := repo.Fetch(ctx, platformManifest.Config)
+		configBytes, err := fetchRefreshing(ctx, repo, platformManifest.Config)
 		if err != nil {
 			return nil, fmt.Errorf("failed to fetch config blob: %w", err)
 		}
@@ -305,7 +477,7 @@ func GetImageConfig(ctx context.Context, image, digestStr string) (*ocispec.Imag
 	}
 
 	// Fetch the config blob
-	configBytes, err := repo.Fetch(ctx, manifest.Config)
+	configBytes, err := fetchRefreshing(ctx, repo, manifest.Config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch config blob: %w", err)
 	}
@@ -320,115 +492,215 @@ func GetImageConfig(ctx context.Context, image, digestStr string) (*ocispec.Imag
 	return &imageConfig, nil
 }
 
-// AddTagByDigest creates a new tag pointing to the specified digest
-func AddTagByDigest(ctx context.Context, image, digest, destTag string) error {
+// AddTagByDigest creates a new tag pointing to the specified digest.
+// It returns the digest of the tagged descriptor as resolved by the registry.
+func AddTagByDigest(ctx context.Context, image, digest, destTag string) (string, error) {
 	// Validate inputs
 	if image == "" {
-		return fmt.Errorf("image cannot be empty")
+		return "", fmt.Errorf("image cannot be empty")
 	}
 	if digest == "" {
-		return fmt.Errorf("digest cannot be empty")
+		return "", fmt.Errorf("digest cannot be empty")
 	}
 	if destTag == "" {
-		return fmt.Errorf("destination tag cannot be empty")
+		return "", fmt.Errorf("destination tag cannot be empty")
 	}
 
 	// Parse image reference
 	registry, path, err := ParseImageReference(image)
 	if err != nil {
-		return err
+		return "", err
 	}
 
 	// Create repository reference
 	repo, err := remote.NewRepository(fmt.Sprintf("%s/%s", registry, path))
 	if err != nil {
-		return fmt.Errorf("failed to create repository reference: %w", err)
+		return "", fmt.Errorf("failed to create repository reference: %w", err)
 	}
 
 	// Configure authentication
 	if err := configureAuth(ctx, repo); err != nil {
-		return fmt.Errorf("failed to configure authentication: %w", err)
+		return "", fmt.Errorf("failed to configure authentication: %w", err)
 	}
 
 	// Resolve the digest to get its descriptor
-	sourceDesc, err := repo.Resolve(ctx, digest)
+	sourceDesc, err := resolveRefreshing(ctx, repo, digest)
 	if err != nil {
-		return fmt.Errorf("failed to resolve digest '%s': %w", digest, err)
+		return "", fmt.Errorf("failed to resolve digest '%s': %w", digest, err)
 	}
 
 	// Tag the descriptor with the destination tag
-	err = repo.Tag(ctx, sourceDesc, destTag)
+	err = tagRefreshing(ctx, repo, sourceDesc, destTag)
 	if err != nil {
-		return fmt.Errorf("failed to tag with '%s': %w", destTag, err)
+		return "", fmt.Errorf("failed to tag with '%s': %w", destTag, err)
 	}
 
-	return nil
+	return sourceDesc.Digest.String(), nil
+}
+
+// CopyTag copies the artifact tagged tag in srcImage, with everything it
+// references (platform manifests, attestations in an index, blobs), to dstImage
+// under the same tag. Both images are in format registry/owner/repo.
+// Returns the digest of the copied root manifest.
+func CopyTag(ctx context.Context, srcImage, tag, dstImage string) (string, error) {
+	if tag == "" {
+		return "", fmt.Errorf("tag cannot be empty")
+	}
+
+	srcRepo, err := newAuthRepository(ctx, srcImage)
+	if err != nil {
+		return "", fmt.Errorf("invalid source image: %w", err)
+	}
+	dstRepo, err := newAuthRepository(ctx, dstImage)
+	if err != nil {
+		return "", fmt.Errorf("invalid destination image: %w", err)
+	}
+
+	// Copying is idempotent, so a copy stopped by an expired token is simply
+	// started over; blobs already copied are skipped. Both repositories share
+	// the cached auth client, so a refreshed one is passed on to dstRepo.
+	var desc ocispec.Descriptor
+	err = retryOnUnauthorized(ctx, srcRepo, func() (err error) {
+		dstRepo.Client = srcRepo.Client
+		desc, err = oras.Copy(ctx, srcRepo, tag, dstRepo, tag, oras.CopyOptions{})
+		return err
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to copy tag '%s': %w", tag, err)
+	}
+	return desc.Digest.String(), nil
+}
+
+// newAuthRepository creates an authenticated repository reference for image
+func newAuthRepository(ctx context.Context, image string) (*remote.Repository, error) {
+	registry, path, err := ParseImageReference(image)
+	if err != nil {
+		return nil, err
+	}
+	repo, err := remote.NewRepository(fmt.Sprintf("%s/%s", registry, path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create repository reference: %w", err)
+	}
+	if err := configureAuth(ctx, repo); err != nil {
+		return nil, fmt.Errorf("failed to configure authentication: %w", err)
+	}
+	return repo, nil
 }
 
 // getOrCreateAuthClient returns a cached auth client or creates a new one
-// This ensures token caching across multiple ORAS operations, avoiding redundant auth cycles
+// This ensures token caching across multiple ORAS operations, avoiding redundant auth cycles.
+// Tokens in the auth cache are keyed by registry host and scope, so repositories
+// on different registries never share credentials.
 func getOrCreateAuthClient(ctx context.Context) *auth.Client {
 	// Fast path: check if we have a cached client
 	authClientCacheMu.RLock()
-	if authClientCache != nil {
-		client := authClientCache
-		authClientCacheMu.RUnlock()
+	client := authClientCache
+	authClientCacheMu.RUnlock()
+	if client != nil {
 		return client
 	}
-	authClientCacheMu.RUnlock()
 
-	// Slow path: create and cache the client (only done once)
-	authClientCacheInit.Do(func() {
-		authClientCacheMu.Lock()
-		defer authClientCacheMu.Unlock()
-
-		// Create HTTP client with optional logging
-		var httpClient *http.Client
-		if logging.IsLoggingEnabled(ctx) {
-			httpClient = &http.Client{
-				Transport: logging.NewLoggingRoundTripper(http.DefaultTransport, os.Stderr),
-			}
-		}
-
-		// Get GitHub token from environment
-		token := os.Getenv("GITHUB_TOKEN")
-		if token == "" {
-			// No token - create anonymous auth client
-			authClientCache = &auth.Client{
-				Cache:  auth.NewCache(),
-				Client: httpClient,
-			}
-			return
-		}
+	// Slow path: create and cache the client, re-checking under the write lock
+	authClientCacheMu.Lock()
+	defer authClientCacheMu.Unlock()
+	if authClientCache == nil {
+		authClientCache = newAuthClient(ctx)
+	}
+	return authClientCache
+}
 
-		// Configure credential store with GitHub token
-		store := credentials.NewMemoryStore()
+// refreshAuthClient replaces the cached auth client, and with it all cached
+// registry tokens, after stale was rejected with 401. If another operation
+// already replaced stale, its fresh client is reused.
+func refreshAuthClient(ctx context.Context, stale remote.Client) *auth.Client {
+	authClientCacheMu.Lock()
+	defer authClientCacheMu.Unlock()
+	if authClientCache == nil || remote.Client(authClientCache) == stale {
+		authClientCache = newAuthClient(ctx)
+	}
+	return authClientCache
+}
 
-		// Store credentials for ghcr.io
-		cred := auth.Credential{
-			Username: "oauth2", // ghcr.io uses oauth2 as username
-			Password: token,
-		}
+// PingRegistry checks that the registry's v2 API endpoint answers, e.g.
+// https://ghcr.io/v2/. An unauthenticated request is answered with 401, which
+// counts as reachable; only network errors and server errors fail.
+func PingRegistry(ctx context.Context, registry string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, "https://"+registry+"/v2/", nil)
+	if err != nil {
+		return err
+	}
+	client := newHTTPClient(ctx)
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("registry %s is not reachable: %w", registry, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("registry %s answered %s", registry, resp.Status)
+	}
+	return nil
+}
 
-		// Store credentials (ignoring errors in initialization)
-		_ = store.Put(context.Background(), "ghcr.io", cred)
+// newHTTPClient returns the HTTP client for registry requests: API call logging
+// and request pacing are installed when enabled in ctx. It returns nil, meaning
+// the default client, when neither is enabled.
+func newHTTPClient(ctx context.Context) *http.Client {
+	transport := http.DefaultTransport
+	if logging.IsLoggingEnabled(ctx) {
+		transport = logging.NewLoggingRoundTripper(transport, os.Stderr)
+	}
+	if limiter := ratelimit.FromContext(ctx); limiter != nil {
+		transport = ratelimit.NewRoundTripper(transport, limiter)
+	}
+	if transport == http.DefaultTransport {
+		return nil
+	}
+	return &http.Client{Transport: transport}
+}
 
-		// Create auth client with credential store, cache, and logging
-		// The cache persists tokens across requests, eliminating redundant auth cycles
-		authClientCache = &auth.Client{
-			Cache:      auth.NewCache(),
-			Credential: credentials.Credential(store),
-			Client:     httpClient,
+// newAuthClient creates an auth client with a fresh token cache.
+// The GitHub token from the environment is only offered to ghcr.io; other
+// registries get the credentials of the auth file in ctx, if any.
+func newAuthClient(ctx context.Context) *auth.Client {
+	httpClient := newHTTPClient(ctx)
+
+	store := newCredentialStore(ctx, os.Getenv("GITHUB_TOKEN"))
+	if store == nil {
+		// No credentials - create anonymous auth client
+		return &auth.Client{
+			Cache:  auth.NewCache(),
+			Client: httpClient,
 		}
-	})
+	}
 
-	authClientCacheMu.RLock()
-	client := authClientCache
-	authClientCacheMu.RUnlock()
-	return client
+	// Create auth client with credential store, cache, and logging
+	// The cache persists tokens across requests, eliminating redundant auth cycles
+	return &auth.Client{
+		Cache:      auth.NewCache(),
+		Credential: credentials.Credential(store),
+		Client:     httpClient,
+	}
+}
+
+// ResetCaches clears all package-level caches so the next registry operation
+// starts from a clean state. It is intended for tests that change GITHUB_TOKEN
+// or need to be independent of earlier registry calls.
+func ResetCaches() {
+	authClientCacheMu.Lock()
+	authClientCache = nil
+	authClientCacheMu.Unlock()
+
+	manifestCacheMu.Lock()
+	defer manifestCacheMu.Unlock()
+	resolvedCache = make(map[string]resolvedVersion)
+	indexChildrenCache = make(map[string][]string)
 }
 
-// configureAuth configures authentication for GHCR using GitHub token
+// configureAuth configures authentication using GitHub token for GHCR and the
+// credentials of the auth file for other registries
 // Now uses a cached auth client to avoid redundant token fetches
 func configureAuth(ctx context.Context, repo *remote.Repository) error {
 	repo.Client = getOrCreateAuthClient(ctx)
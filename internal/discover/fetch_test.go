@@ -6,6 +6,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"oras.land/oras-go/v2/registry/remote/auth"
 )
 
 func TestResolveTag(t *testing.T) {
@@ -65,6 +66,16 @@ func TestResolveTag(t *testing.T) {
 	}
 }
 
+func TestListTags_InvalidImage(t *testing.T) {
+	t.Parallel()
+
+	_, err := ListTags(context.Background(), "owner/image")
+	assert.ErrorContains(t, err, "invalid image format")
+
+	_, err = ListTags(context.Background(), "")
+	assert.ErrorContains(t, err, "invalid image format")
+}
+
 func TestValidateDigestFormat(t *testing.T) {
 	t.Parallel()
 	tests := []struct {
@@ -284,3 +295,149 @@ func TestGetImageConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestFetchManifest_InvalidInput(t *testing.T) {
+	t.Parallel()
+	validDigest := "sha256:1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef"
+
+	tests := []struct {
+		name     string
+		image    string
+		digest   string
+		errorMsg string
+	}{
+		{"empty image", "", validDigest, "image cannot be empty"},
+		{"invalid digest format", "ghcr.io/owner/image", "latest", "invalid digest format"},
+		{"invalid image format", "owner/image", validDigest, "invalid image format"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manifest, mediaType, err := FetchManifest(context.Background(), tt.image, tt.digest)
+			require.Error(t, err)
+			assert.ErrorContains(t, err, tt.errorMsg)
+			assert.Nil(t, manifest)
+			assert.Empty(t, mediaType)
+		})
+	}
+}
+
+const platformIndex = `{
+  "schemaVersion": 2,
+  "mediaType": "application/vnd.oci.image.index.v1+json",
+  "manifests": [
+    {
+      "mediaType": "application/vnd.oci.image.manifest.v1+json",
+      "digest": "sha256:1111111111111111111111111111111111111111111111111111111111111111",
+      "size": 1024,
+      "platform": {"architecture": "amd64", "os": "linux"}
+    },
+    {
+      "mediaType": "application/vnd.oci.image.manifest.v1+json",
+      "digest": "sha256:2222222222222222222222222222222222222222222222222222222222222222",
+      "size": 1024,
+      "platform": {"architecture": "arm64", "os": "linux", "variant": "v8"}
+    },
+    {
+      "mediaType": "application/vnd.oci.image.manifest.v1+json",
+      "digest": "sha256:3333333333333333333333333333333333333333333333333333333333333333",
+      "size": 840,
+      "annotations": {"vnd.docker.reference.type": "attestation-manifest"},
+      "platform": {"architecture": "unknown", "os": "unknown"}
+    }
+  ]
+}`
+
+func TestSelectPlatformManifest(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		platform string
+		want     string
+		errorMsg string
+	}{
+		{"os and arch", "linux/amd64", "sha256:1111111111111111111111111111111111111111111111111111111111111111", ""},
+		{"without variant", "linux/arm64", "sha256:2222222222222222222222222222222222222222222222222222222222222222", ""},
+		{"with variant", "linux/arm64/v8", "sha256:2222222222222222222222222222222222222222222222222222222222222222", ""},
+		{"wrong variant", "linux/arm64/v7", "", "platform linux/arm64/v7 not found in image index (available: linux/amd64, linux/arm64/v8, unknown/unknown)"},
+		{"missing arch", "linux", "", "expected os/arch or os/arch/variant"},
+		{"empty arch", "linux/", "", "expected os/arch or os/arch/variant"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := SelectPlatformManifest([]byte(platformIndex), tt.platform)
+			if tt.errorMsg != "" {
+				assert.ErrorContains(t, err, tt.errorMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := SelectPlatformManifest([]byte("not json"), "linux/amd64")
+	assert.ErrorContains(t, err, "failed to decode image index")
+}
+
+func TestListPlatformManifests(t *testing.T) {
+	t.Parallel()
+
+	got, err := ListPlatformManifests([]byte(platformIndex))
+	require.NoError(t, err)
+	assert.Equal(t, []PlatformManifest{
+		{Platform: "linux/amd64", Digest: "sha256:1111111111111111111111111111111111111111111111111111111111111111"},
+		{Platform: "linux/arm64/v8", Digest: "sha256:2222222222222222222222222222222222222222222222222222222222222222"},
+	}, got, "the attestation manifest is skipped")
+
+	_, err = ListPlatformManifests([]byte("not json"))
+	assert.ErrorContains(t, err, "failed to decode image index")
+}
+
+func TestIsIndexMediaType(t *testing.T) {
+	t.Parallel()
+	assert.True(t, IsIndexMediaType("application/vnd.oci.image.index.v1+json"))
+	assert.True(t, IsIndexMediaType("application/vnd.docker.distribution.manifest.list.v2+json"))
+	assert.False(t, IsIndexMediaType("application/vnd.oci.image.manifest.v1+json"))
+}
+
+func TestResetCaches_CreatesFreshAuthClient(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "ghp_first")
+	ResetCaches()
+	t.Cleanup(ResetCaches)
+	ctx := context.Background()
+
+	first := getOrCreateAuthClient(ctx)
+	assert.Same(t, first, getOrCreateAuthClient(ctx), "auth client should be reused until reset")
+
+	// A reset picks up the changed token instead of reusing stale credentials
+	t.Setenv("GITHUB_TOKEN", "ghp_second")
+	ResetCaches()
+	second := getOrCreateAuthClient(ctx)
+	assert.NotSame(t, first, second)
+
+	cred, err := second.Credential(ctx, "ghcr.io")
+	require.NoError(t, err)
+	assert.Equal(t, "ghp_second", cred.Password)
+}
+
+func TestAuthClient_CredentialsScopedToGHCR(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "ghp_secret")
+	ResetCaches()
+	t.Cleanup(ResetCaches)
+	ctx := context.Background()
+
+	client := getOrCreateAuthClient(ctx)
+
+	cred, err := client.Credential(ctx, "ghcr.io")
+	require.NoError(t, err)
+	assert.Equal(t, "oauth2", cred.Username)
+	assert.Equal(t, "ghp_secret", cred.Password)
+
+	// Mirror registries must not receive the GitHub token
+	cred, err = client.Credential(ctx, "registry.example.com")
+	require.NoError(t, err)
+	assert.Equal(t, auth.EmptyCredential, cred)
+}
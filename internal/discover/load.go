@@ -0,0 +1,89 @@
+package discover
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/mkoepf/ghcrctl/internal/display"
+	"github.com/mkoepf/ghcrctl/internal/gh"
+)
+
+// LoadVersions reads versions saved with list graphs --json, to render them
+// again without the registry. It accepts the plain array as well as the
+// --envelope and --show-size-totals objects. The versions are ordered as
+// DiscoverPackage orders them, so that a dump renders like the live package.
+func LoadVersions(r io.Reader) ([]VersionInfo, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimSpace(data)
+
+	items := data
+	if bytes.HasPrefix(data, []byte("{")) {
+		var wrapped struct {
+			SchemaVersion *int            `json:"schema_version"`
+			Items         json.RawMessage `json:"items"`
+			Versions      json.RawMessage `json:"versions"`
+		}
+		if err := json.Unmarshal(data, &wrapped); err != nil {
+			return nil, fmt.Errorf("not a list graphs --json dump: %w", err)
+		}
+		if wrapped.SchemaVersion != nil && *wrapped.SchemaVersion != display.SchemaVersion {
+			return nil, fmt.Errorf("dump has schema_version %d, expected %d", *wrapped.SchemaVersion, display.SchemaVersion)
+		}
+		switch {
+		case wrapped.Items != nil:
+			items = wrapped.Items
+		case wrapped.Versions != nil:
+			items = wrapped.Versions
+		default:
+			return nil, fmt.Errorf("not a list graphs --json dump: no items or versions")
+		}
+	}
+
+	var versions []VersionInfo
+	if err := json.Unmarshal(items, &versions); err != nil {
+		return nil, fmt.Errorf("not a list graphs --json dump: %w", err)
+	}
+
+	seen := make(map[string]bool, len(versions))
+	for i, v := range versions {
+		if v.Digest == "" {
+			return nil, fmt.Errorf("version %d in dump has no digest", v.ID)
+		}
+		if seen[v.Digest] {
+			return nil, fmt.Errorf("digest %s appears twice in dump", v.Digest)
+		}
+		seen[v.Digest] = true
+		// Render timestamps as for the live package
+		versions[i].CreatedAt = gh.FromRFC3339Timestamp(v.CreatedAt)
+	}
+
+	sortDiscovered(versions)
+	return versions, nil
+}
+
+// ToPackageVersions lists versions as GitHub does, with one entry per version
+// ID. Tags stay on the primary ID of versions with duplicates.
+func ToPackageVersions(versions []VersionInfo) []gh.PackageVersionInfo {
+	var result []gh.PackageVersionInfo
+	for _, v := range versions {
+		result = append(result, gh.PackageVersionInfo{
+			ID:        v.ID,
+			Digest:    v.Digest,
+			Tags:      v.Tags,
+			CreatedAt: v.CreatedAt,
+		})
+		for _, id := range v.DuplicateIDs {
+			result = append(result, gh.PackageVersionInfo{
+				ID:        id,
+				Digest:    v.Digest,
+				CreatedAt: v.CreatedAt,
+			})
+		}
+	}
+	return result
+}
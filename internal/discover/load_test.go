@@ -0,0 +1,141 @@
+package discover
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mkoepf/ghcrctl/internal/display"
+	"github.com/mkoepf/ghcrctl/internal/gh"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// discoveredDump discovers a package with two indexes sharing a platform
+// manifest, as list graphs does before writing --json
+func discoveredDump(t *testing.T) []VersionInfo {
+	t.Helper()
+	types := map[string][]string{
+		"sha256:index1":  {"index"},
+		"sha256:index2":  {"index"},
+		"sha256:arm64":   {"linux/arm64"},
+		"sha256:amd64":   {"linux/amd64"},
+		"sha256:attest1": {"sbom"},
+		"sha256:sig":     {"signature"},
+	}
+	children := map[string][]string{
+		"sha256:index1": {"sha256:attest1", "sha256:arm64", "sha256:amd64", "sha256:sig"},
+		"sha256:index2": {"sha256:amd64"},
+	}
+	discoverer := &PackageDiscoverer{
+		resolver: &mockResolver{resolveFunc: func(ctx context.Context, image, digest string) ([]string, error) {
+			return types[digest], nil
+		}},
+		childDiscoverer: &mockChildDiscoverer{discoverFunc: func(ctx context.Context, image, digest string, allTags []string) ([]string, error) {
+			return children[digest], nil
+		}},
+	}
+	versions := []gh.PackageVersionInfo{
+		{ID: 3, Digest: "sha256:arm64", CreatedAt: "2025-01-01 00:00:00"},
+		{ID: 6, Digest: "sha256:index2", Tags: []string{"v2"}, CreatedAt: "2025-02-01 00:00:00"},
+		{ID: 1, Digest: "sha256:attest1", CreatedAt: "2025-01-01 00:00:00"},
+		{ID: 5, Digest: "sha256:index1", Tags: []string{"v1", "latest"}, CreatedAt: "2025-01-01 00:00:00"},
+		{ID: 2, Digest: "sha256:amd64", CreatedAt: "2025-01-01 00:00:00"},
+		{ID: 4, Digest: "sha256:sig", CreatedAt: "2025-01-02 00:00:00"},
+		{ID: 7, Digest: "sha256:sig", CreatedAt: "2025-01-03 00:00:00"},
+	}
+	results, err := discoverer.DiscoverPackage(context.Background(), "ghcr.io/test/image", versions, nil)
+	require.NoError(t, err)
+	return results
+}
+
+// renderDump renders versions as list graphs does in tree and table mode
+func renderDump(versions []VersionInfo) string {
+	var buf bytes.Buffer
+	FormatTree(&buf, versions, ToMap(versions))
+	FormatTable(&buf, versions, ToMap(versions))
+	return buf.String()
+}
+
+func TestLoadVersions_RoundTrip(t *testing.T) {
+	t.Parallel()
+	discovered := discoveredDump(t)
+	want := renderDump(discovered)
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		data interface{}
+	}{
+		{name: "array", ctx: context.Background(), data: discovered},
+		{name: "envelope", ctx: display.WithEnvelope(context.Background()), data: discovered},
+		{name: "size totals", ctx: context.Background(), data: map[string]interface{}{
+			"versions": discovered,
+			"totals":   CalculateTotals(discovered, ToMap(discovered)),
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var dump bytes.Buffer
+			require.NoError(t, display.OutputJSON(tt.ctx, &dump, tt.data))
+
+			loaded, err := LoadVersions(&dump)
+			require.NoError(t, err)
+			assert.Equal(t, discovered, loaded)
+			assert.Equal(t, want, renderDump(loaded))
+		})
+	}
+
+	t.Run("reordered dump renders the same", func(t *testing.T) {
+		t.Parallel()
+		reversed := make([]VersionInfo, len(discovered))
+		for i, v := range discovered {
+			reversed[len(discovered)-1-i] = v
+		}
+		data, err := json.Marshal(reversed)
+		require.NoError(t, err)
+
+		loaded, err := LoadVersions(bytes.NewReader(data))
+		require.NoError(t, err)
+		assert.Equal(t, want, renderDump(loaded))
+	})
+}
+
+func TestLoadVersions_Invalid(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{name: "not JSON", content: "graphs", wantErr: "not a list graphs --json dump"},
+		{name: "graph edges", content: `{"nodes": [], "edges": []}`, wantErr: "no items or versions"},
+		{name: "other schema", content: `{"schema_version": 2, "items": []}`, wantErr: "dump has schema_version 2, expected 1"},
+		{name: "no digest", content: `[{"id": 1}]`, wantErr: "version 1 in dump has no digest"},
+		{name: "duplicate digest", content: `[{"id": 1, "digest": "sha256:a"}, {"id": 2, "digest": "sha256:a"}]`, wantErr: "digest sha256:a appears twice"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := LoadVersions(strings.NewReader(tt.content))
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestToPackageVersions(t *testing.T) {
+	t.Parallel()
+	versions := []VersionInfo{
+		{ID: 7, DuplicateIDs: []int64{4}, Digest: "sha256:sig", CreatedAt: "2025-01-03T00:00:00Z"},
+		{ID: 5, Digest: "sha256:index1", Tags: []string{"v1"}, CreatedAt: "2025-01-01T00:00:00Z"},
+	}
+
+	assert.Equal(t, []gh.PackageVersionInfo{
+		{ID: 7, Digest: "sha256:sig", CreatedAt: "2025-01-03T00:00:00Z"},
+		{ID: 4, Digest: "sha256:sig", CreatedAt: "2025-01-03T00:00:00Z"},
+		{ID: 5, Digest: "sha256:index1", Tags: []string{"v1"}, CreatedAt: "2025-01-01T00:00:00Z"},
+	}, ToPackageVersions(versions))
+}
@@ -9,8 +9,90 @@ import (
 	"github.com/mkoepf/ghcrctl/internal/display"
 )
 
+const (
+	// sha256HexLength is the length of a sha256 digest without its prefix
+	sha256HexLength = 64
+	// refIndicatorWidth is the visible width of a ref indicator like "[⬇✓] "
+	refIndicatorWidth = 5
+)
+
+// FormatOptions controls optional parts of the table and tree output.
+type FormatOptions struct {
+	ShowSizeTotals bool // Add per-graph sizes (tree only) and the total size to the summary
+	DigestLength   int  // Digest characters to show; 0 means display.DefaultDigestLength, display.FullDigestLength shows full digests
+	Quiet          bool // Omit the totals row (table only) and the summary
+	// Highlight is the full digest of a version to mark in the tree, e.g. to
+	// locate a layer reported by a scanner. Empty marks nothing.
+	Highlight string
+	RawBytes  bool // Show sizes as exact byte counts instead of KiB/MiB/GiB
+}
+
+// highlightMarker is appended to the tree rows of the highlighted version
+const highlightMarker = "  ◀── HERE"
+
+// highlight returns the marker for the version with digest, or "" if it is not highlighted
+func (o FormatOptions) highlight(digest string) string {
+	if o.Highlight == "" || digest != o.Highlight {
+		return ""
+	}
+	return display.ColorHighlight(highlightMarker)
+}
+
+// Totals summarizes the versions of a listing.
+type Totals struct {
+	Graphs   int   `json:"graphs"`
+	Versions int   `json:"versions"`
+	Size     int64 `json:"size"`
+}
+
+// CalculateTotals counts the graphs (roots) and versions and sums the sizes of versions.
+func CalculateTotals(versions []VersionInfo, allVersions map[string]VersionInfo) Totals {
+	totals := Totals{Versions: len(versions)}
+	for _, v := range versions {
+		if v.IsRoot(allVersions) {
+			totals.Graphs++
+		}
+		totals.Size += v.Size
+	}
+	return totals
+}
+
+// digestLength returns the number of digest characters to show
+func (o FormatOptions) digestLength() int {
+	if o.DigestLength == 0 {
+		return display.DefaultDigestLength
+	}
+	return o.DigestLength
+}
+
+// digestWidth returns the width of the digest column
+func (o FormatOptions) digestWidth() int {
+	if n := o.digestLength(); n > 0 && n < sha256HexLength {
+		return n
+	}
+	return sha256HexLength
+}
+
+// size formats a size in bytes, as exact bytes with RawBytes
+func (o FormatOptions) size(bytes int64) string {
+	if o.RawBytes {
+		return FormatSizeBytes(bytes)
+	}
+	return FormatSize(bytes)
+}
+
+// shortDigest truncates a digest to the configured length
+func (o FormatOptions) shortDigest(digest string) string {
+	return display.TruncateDigest(digest, o.digestLength())
+}
+
 // FormatTable outputs versions in a flat table format.
 func FormatTable(w io.Writer, versions []VersionInfo, allVersions map[string]VersionInfo) {
+	FormatTableWithOptions(w, versions, allVersions, FormatOptions{})
+}
+
+// FormatTableWithOptions outputs versions in a flat table format with the given options.
+func FormatTableWithOptions(w io.Writer, versions []VersionInfo, allVersions map[string]VersionInfo, opts FormatOptions) {
 	// Sort versions by ID descending
 	sortedVersions := make([]VersionInfo, len(versions))
 	copy(sortedVersions, versions)
@@ -21,10 +103,10 @@ func FormatTable(w io.Writer, versions []VersionInfo, allVersions map[string]Ver
 	// Calculate dynamic column widths
 	idWidth := len("VERSION ID")
 	typeWidth := len("TYPE")
-	digestWidth := 12
+	digestWidth := opts.digestWidth()
 	sizeWidth := len("SIZE")
 	tagWidth := 20
-	refWidth := 20
+	refWidth := max(20, refIndicatorWidth+digestWidth)
 	for _, v := range sortedVersions {
 		idLen := len(fmt.Sprintf("%d", v.ID))
 		if idLen > idWidth {
@@ -34,7 +116,7 @@ func FormatTable(w io.Writer, versions []VersionInfo, allVersions map[string]Ver
 		if typeLen > typeWidth {
 			typeWidth = typeLen
 		}
-		sizeLen := len(formatSize(v.Size))
+		sizeLen := len(opts.size(v.Size))
 		if sizeLen > sizeWidth {
 			sizeWidth = sizeLen
 		}
@@ -45,13 +127,18 @@ func FormatTable(w io.Writer, versions []VersionInfo, allVersions map[string]Ver
 			}
 		}
 	}
+	// The totals row is right-aligned in the size column as well
+	totals := CalculateTotals(versions, allVersions)
+	if !opts.Quiet {
+		sizeWidth = max(sizeWidth, len(opts.size(totals.Size)))
+	}
 
 	// Print header (pad first, then color to avoid ANSI length issues)
 	fmt.Fprintf(w, "  %s  %s  %s  %s  %s  %s  %s\n",
-		display.ColorHeader(fmt.Sprintf("%-*s", idWidth, "VERSION ID")),
+		display.ColorHeader(fmt.Sprintf("%*s", idWidth, "VERSION ID")),
 		display.ColorHeader(fmt.Sprintf("%-*s", typeWidth, "TYPE")),
 		display.ColorHeader(fmt.Sprintf("%-*s", digestWidth, "DIGEST")),
-		display.ColorHeader(fmt.Sprintf("%-*s", sizeWidth, "SIZE")),
+		display.ColorHeader(fmt.Sprintf("%*s", sizeWidth, "SIZE")),
 		display.ColorHeader(fmt.Sprintf("%-*s", tagWidth, "TAGS")),
 		display.ColorHeader(fmt.Sprintf("%-*s", refWidth, "REFS")),
 		display.ColorHeader("CREATED"))
@@ -65,7 +152,7 @@ func FormatTable(w io.Writer, versions []VersionInfo, allVersions map[string]Ver
 		display.ColorSeparator("-------------------"))
 
 	for _, v := range sortedVersions {
-		refs := buildRefList(v, allVersions)
+		refs := buildRefList(v, allVersions, opts)
 		typeStr := formatTypes(v.Types)
 
 		// Combine tags and refs into a single list of "extra" rows
@@ -78,10 +165,10 @@ func FormatTable(w io.Writer, versions []VersionInfo, allVersions map[string]Ver
 
 			if row == 0 {
 				// Pad raw strings first, then apply color to preserve alignment
-				idStr = fmt.Sprintf("%-*d", idWidth, v.ID)
+				idStr = fmt.Sprintf("%*d", idWidth, v.ID)
 				typeOut = display.ColorVersionType(fmt.Sprintf("%-*s", typeWidth, typeStr))
-				digestOut = display.ColorDigest(fmt.Sprintf("%-*s", digestWidth, shortDigest(v.Digest)))
-				sizeOut = fmt.Sprintf("%-*s", sizeWidth, formatSize(v.Size))
+				digestOut = display.ColorDigest(fmt.Sprintf("%-*s", digestWidth, opts.shortDigest(v.Digest)))
+				sizeOut = fmt.Sprintf("%*s", sizeWidth, opts.size(v.Size))
 				createdOut = v.CreatedAt
 			} else {
 				// Empty cells need proper padding
@@ -98,7 +185,7 @@ func FormatTable(w io.Writer, versions []VersionInfo, allVersions map[string]Ver
 			}
 
 			if row < len(refs) {
-				refOut = padRefString(refs[row], refWidth)
+				refOut = padRefString(refs[row], refWidth, digestWidth)
 			} else {
 				refOut = strings.Repeat(" ", refWidth)
 			}
@@ -114,16 +201,31 @@ func FormatTable(w io.Writer, versions []VersionInfo, allVersions map[string]Ver
 		}
 	}
 
-	// Print summary
-	printSummary(w, versions, allVersions)
+	if opts.Quiet {
+		return
+	}
+
+	// Print totals row below the size column, then the summary
+	fmt.Fprintf(w, "  %s  %s  %s  %s\n",
+		display.ColorSeparator(strings.Repeat("-", idWidth)),
+		strings.Repeat(" ", typeWidth),
+		strings.Repeat(" ", digestWidth),
+		display.ColorSeparator(strings.Repeat("-", sizeWidth)))
+	fmt.Fprintf(w, "  %s  %s  %s  %s  %s %s, %s %s\n",
+		display.ColorHeader(fmt.Sprintf("%-*s", idWidth, "TOTAL")),
+		strings.Repeat(" ", typeWidth),
+		strings.Repeat(" ", digestWidth),
+		fmt.Sprintf("%*s", sizeWidth, opts.size(totals.Size)),
+		display.ColorCount(totals.Graphs), pluralize(totals.Graphs, "graph", "graphs"),
+		display.ColorCount(totals.Versions), pluralize(totals.Versions, "version", "versions"))
+
+	printSummary(w, versions, allVersions, opts)
 }
 
 // padRefString pads a ref string (which contains ANSI codes) to the target width.
 // The ref format is "[⬇✓] digest" where [⬇✓] is 4 visible chars but more bytes.
-func padRefString(ref string, width int) string {
-	// The ref indicator "[⬇✓] " is 5 visible chars (including space after)
-	// Plus 12 chars for digest = 17 visible chars total
-	visibleLen := 5 + 12 // indicator + space + digest
+func padRefString(ref string, width, digestWidth int) string {
+	visibleLen := refIndicatorWidth + digestWidth
 	if len(ref) == 0 {
 		return strings.Repeat(" ", width)
 	}
@@ -136,6 +238,11 @@ func padRefString(ref string, width int) string {
 
 // FormatTree outputs versions in a tree-style grouped format.
 func FormatTree(w io.Writer, versions []VersionInfo, allVersions map[string]VersionInfo) {
+	FormatTreeWithOptions(w, versions, allVersions, FormatOptions{})
+}
+
+// FormatTreeWithOptions outputs versions in a tree-style grouped format with the given options.
+func FormatTreeWithOptions(w io.Writer, versions []VersionInfo, allVersions map[string]VersionInfo, opts FormatOptions) {
 	// Find roots
 	var roots []VersionInfo
 	for _, v := range versions {
@@ -166,7 +273,7 @@ func FormatTree(w io.Writer, versions []VersionInfo, allVersions map[string]Vers
 	// Calculate dynamic column widths
 	idWidth := 10 // minimum width for VERSION ID
 	typeWidth := 4
-	digestWidth := 12
+	digestWidth := opts.digestWidth()
 	sizeWidth := len("SIZE")
 	for _, v := range versions {
 		idLen := len(fmt.Sprintf("%d", v.ID))
@@ -177,7 +284,7 @@ func FormatTree(w io.Writer, versions []VersionInfo, allVersions map[string]Vers
 		if typeLen > typeWidth {
 			typeWidth = typeLen
 		}
-		sizeLen := len(formatSize(v.Size))
+		sizeLen := len(opts.size(v.Size))
 		if sizeLen > sizeWidth {
 			sizeWidth = sizeLen
 		}
@@ -192,7 +299,7 @@ func FormatTree(w io.Writer, versions []VersionInfo, allVersions map[string]Vers
 		multiPadding,
 		display.ColorHeader(fmt.Sprintf("%-*s", typeWidth, "TYPE")),
 		display.ColorHeader(fmt.Sprintf("%-*s", digestWidth, "DIGEST")),
-		display.ColorHeader(fmt.Sprintf("%-*s", sizeWidth, "SIZE")),
+		display.ColorHeader(fmt.Sprintf("%*s", sizeWidth, "SIZE")),
 		display.ColorHeader("TAGS"))
 	fmt.Fprintf(w, "%s%s%s  %s  %s  %s  %s\n",
 		treePrefix,
@@ -207,11 +314,42 @@ func FormatTree(w io.Writer, versions []VersionInfo, allVersions map[string]Vers
 		if i > 0 {
 			fmt.Fprintln(w)
 		}
-		printTree(w, root, allVersions, graphCounts, "", true, idWidth, typeWidth, sizeWidth, maxMultiplicityWidth)
+		printTree(w, root, allVersions, graphCounts, "", true, idWidth, typeWidth, sizeWidth, maxMultiplicityWidth, opts)
+		if opts.ShowSizeTotals {
+			fmt.Fprintf(w, "%sGraph size: %s\n", treePrefix, opts.size(graphSize(root, allVersions)))
+		}
 	}
 
 	// Print summary
-	printSummary(w, versions, allVersions)
+	if !opts.Quiet {
+		printSummary(w, versions, allVersions, opts)
+	}
+}
+
+// graphSize sums the sizes of every version reachable from a root through its
+// references and referrers, counting each digest once.
+func graphSize(root VersionInfo, allVersions map[string]VersionInfo) int64 {
+	seen := map[string]bool{root.Digest: true}
+	queue := []VersionInfo{root}
+	var total int64
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+		total += v.Size
+		for _, outRef := range v.OutgoingRefs {
+			if child, found := allVersions[outRef]; found && !seen[outRef] {
+				seen[outRef] = true
+				queue = append(queue, child)
+			}
+		}
+		for _, inRef := range v.IncomingRefs {
+			if child, found := allVersions[inRef]; found && child.IsReferrer() && !seen[inRef] {
+				seen[inRef] = true
+				queue = append(queue, child)
+			}
+		}
+	}
+	return total
 }
 
 // calculateGraphCounts counts how many distinct graphs (roots) each version belongs to.
@@ -284,9 +422,9 @@ func findRoot(allVersions map[string]VersionInfo, digest string) string {
 	}
 }
 
-func printTree(w io.Writer, v VersionInfo, allVersions map[string]VersionInfo, graphCounts map[string]int, prefix string, isRoot bool, idWidth, typeWidth, sizeWidth, maxMultiplicityWidth int) {
+func printTree(w io.Writer, v VersionInfo, allVersions map[string]VersionInfo, graphCounts map[string]int, prefix string, isRoot bool, idWidth, typeWidth, sizeWidth, maxMultiplicityWidth int, opts FormatOptions) {
 	typeStr := formatTypes(v.Types)
-	sizeStr := formatSize(v.Size)
+	sizeStr := opts.size(v.Size)
 	tagsStr := ""
 	if len(v.Tags) > 0 {
 		tagsStr = "  " + formatTags(v.Tags)
@@ -328,15 +466,15 @@ func printTree(w io.Writer, v VersionInfo, allVersions map[string]VersionInfo, g
 	// Pad raw strings first, then apply color to preserve alignment
 	if isRoot {
 		paddedType := display.ColorVersionType(fmt.Sprintf("%-*s", typeWidth, typeStr))
-		paddedSize := fmt.Sprintf("%-*s", sizeWidth, sizeStr)
+		paddedSize := fmt.Sprintf("%*s", sizeWidth, sizeStr)
 		// Roots don't have multiplicity indicator, so pad with spaces to align with children
 		multiPadding := strings.Repeat(" ", maxMultiplicityWidth)
 		if len(children) > 0 {
-			fmt.Fprintf(w, "%s┌      %-*d%s  %s  %s  %s%s\n",
-				prefix, idWidth, v.ID, multiPadding, paddedType, display.ColorDigest(shortDigest(v.Digest)), paddedSize, tagsStr)
+			fmt.Fprintf(w, "%s┌      %-*d%s  %s  %s  %s%s%s\n",
+				prefix, idWidth, v.ID, multiPadding, paddedType, display.ColorDigest(opts.shortDigest(v.Digest)), paddedSize, tagsStr, opts.highlight(v.Digest))
 		} else {
-			fmt.Fprintf(w, "%s       %-*d%s  %s  %s  %s%s\n",
-				prefix, idWidth, v.ID, multiPadding, paddedType, display.ColorDigest(shortDigest(v.Digest)), paddedSize, tagsStr)
+			fmt.Fprintf(w, "%s       %-*d%s  %s  %s  %s%s%s\n",
+				prefix, idWidth, v.ID, multiPadding, paddedType, display.ColorDigest(opts.shortDigest(v.Digest)), paddedSize, tagsStr, opts.highlight(v.Digest))
 		}
 	}
 
@@ -352,7 +490,7 @@ func printTree(w io.Writer, v VersionInfo, allVersions map[string]VersionInfo, g
 		if child.found {
 			childVer := allVersions[child.ref]
 			childTypeStr := formatTypes(childVer.Types)
-			childSizeStr := formatSize(childVer.Size)
+			childSizeStr := opts.size(childVer.Size)
 			childTagsStr := ""
 			if len(childVer.Tags) > 0 {
 				childTagsStr = "  " + formatTags(childVer.Tags)
@@ -368,35 +506,35 @@ func printTree(w io.Writer, v VersionInfo, allVersions map[string]VersionInfo, g
 				multiplicityStr = strings.Repeat(" ", maxMultiplicityWidth)
 			}
 			paddedType := display.ColorVersionType(fmt.Sprintf("%-*s", typeWidth, childTypeStr))
-			paddedSize := fmt.Sprintf("%-*s", sizeWidth, childSizeStr)
-			fmt.Fprintf(w, "%s%s %s %-*d%s  %s  %s  %s%s\n",
+			paddedSize := fmt.Sprintf("%*s", sizeWidth, childSizeStr)
+			fmt.Fprintf(w, "%s%s %s %-*d%s  %s  %s  %s%s%s\n",
 				prefix, connector, indicator, idWidth, childVer.ID, multiplicityStr, paddedType,
-				display.ColorDigest(shortDigest(childVer.Digest)), paddedSize, childTagsStr)
+				display.ColorDigest(opts.shortDigest(childVer.Digest)), paddedSize, childTagsStr, opts.highlight(childVer.Digest))
 		} else {
 			multiPadding := strings.Repeat(" ", maxMultiplicityWidth)
 			paddedType := fmt.Sprintf("%-*s", typeWidth, "???")
-			paddedSize := fmt.Sprintf("%-*s", sizeWidth, "-")
+			paddedSize := fmt.Sprintf("%*s", sizeWidth, "-")
 			fmt.Fprintf(w, "%s%s %s %-*s%s  %s  %s  %s  (not found)\n",
-				prefix, connector, indicator, idWidth, "-", multiPadding, paddedType, display.ColorDigest(shortDigest(child.ref)), paddedSize)
+				prefix, connector, indicator, idWidth, "-", multiPadding, paddedType, display.ColorDigest(opts.shortDigest(child.ref)), paddedSize)
 		}
 	}
 }
 
-func buildRefList(v VersionInfo, allVersions map[string]VersionInfo) []string {
+func buildRefList(v VersionInfo, allVersions map[string]VersionInfo, opts FormatOptions) []string {
 	var refs []string
 
 	// Outgoing refs
 	for _, outRef := range v.OutgoingRefs {
 		_, found := allVersions[outRef]
 		indicator := buildRefIndicator("out", found)
-		refs = append(refs, fmt.Sprintf("%s %s", indicator, shortDigest(outRef)))
+		refs = append(refs, fmt.Sprintf("%s %s", indicator, opts.shortDigest(outRef)))
 	}
 
 	// Incoming refs
 	for _, inRef := range v.IncomingRefs {
 		_, found := allVersions[inRef]
 		indicator := buildRefIndicator("in", found)
-		refs = append(refs, fmt.Sprintf("%s %s", indicator, shortDigest(inRef)))
+		refs = append(refs, fmt.Sprintf("%s %s", indicator, opts.shortDigest(inRef)))
 	}
 
 	return refs
@@ -416,14 +554,6 @@ func buildRefIndicator(direction string, found bool) string {
 	return display.ColorError("[⬆✗]")
 }
 
-func shortDigest(digest string) string {
-	digest = strings.TrimPrefix(digest, "sha256:")
-	if len(digest) > 12 {
-		return digest[:12]
-	}
-	return digest
-}
-
 func formatTags(tags []string) string {
 	if len(tags) == 0 {
 		return "[]"
@@ -439,16 +569,10 @@ func formatTypes(types []string) string {
 }
 
 // printSummary prints a summary line with version and graph counts.
-func printSummary(w io.Writer, versions []VersionInfo, allVersions map[string]VersionInfo) {
-	totalVersions := len(versions)
-
-	// Count roots (graphs)
-	var rootCount int
-	for _, v := range versions {
-		if v.IsRoot(allVersions) {
-			rootCount++
-		}
-	}
+// With ShowSizeTotals, a second line reports the total size of all versions,
+// counting shared versions once.
+func printSummary(w io.Writer, versions []VersionInfo, allVersions map[string]VersionInfo, opts FormatOptions) {
+	totals := CalculateTotals(versions, allVersions)
 
 	// Count shared versions (appear in multiple graphs)
 	graphCounts := calculateGraphCounts(allVersions)
@@ -460,48 +584,48 @@ func printSummary(w io.Writer, versions []VersionInfo, allVersions map[string]Ve
 	}
 
 	// Build summary
-	versionWord := "versions"
-	if totalVersions == 1 {
-		versionWord = "version"
-	}
-	graphWord := "graphs"
-	if rootCount == 1 {
-		graphWord = "graph"
-	}
+	versionWord := pluralize(totals.Versions, "version", "versions")
+	graphWord := pluralize(totals.Graphs, "graph", "graphs")
 
 	if sharedCount > 0 {
-		sharedWord := "versions appear"
-		if sharedCount == 1 {
-			sharedWord = "version appears"
-		}
+		sharedWord := pluralize(sharedCount, "version appears", "versions appear")
 		fmt.Fprintf(w, "\nTotal: %s %s in %s %s. %s %s in multiple graphs.\n",
-			display.ColorCount(totalVersions), versionWord,
-			display.ColorCount(rootCount), graphWord,
+			display.ColorCount(totals.Versions), versionWord,
+			display.ColorCount(totals.Graphs), graphWord,
 			display.ColorShared(fmt.Sprintf("%d", sharedCount)), sharedWord)
 	} else {
 		fmt.Fprintf(w, "\nTotal: %s %s in %s %s.\n",
-			display.ColorCount(totalVersions), versionWord,
-			display.ColorCount(rootCount), graphWord)
+			display.ColorCount(totals.Versions), versionWord,
+			display.ColorCount(totals.Graphs), graphWord)
+	}
+
+	if opts.ShowSizeTotals {
+		fmt.Fprintf(w, "Total size: %s\n", opts.size(totals.Size))
+	}
+}
+
+// pluralize returns singular for a count of one and plural otherwise.
+func pluralize(count int, singular, plural string) string {
+	if count == 1 {
+		return singular
+	}
+	return plural
+}
+
+// FormatSize formats a size in bytes as a human-readable string.
+// Unknown sizes (zero or less) are shown as "-".
+func FormatSize(bytes int64) string {
+	if bytes <= 0 {
+		return "-"
 	}
+	return display.HumanSize(bytes)
 }
 
-// formatSize formats a size in bytes as a human-readable string.
-func formatSize(bytes int64) string {
-	const (
-		KB = 1024
-		MB = KB * 1024
-		GB = MB * 1024
-	)
-	switch {
-	case bytes >= GB:
-		return fmt.Sprintf("%.1f GB", float64(bytes)/float64(GB))
-	case bytes >= MB:
-		return fmt.Sprintf("%.1f MB", float64(bytes)/float64(MB))
-	case bytes >= KB:
-		return fmt.Sprintf("%.1f KB", float64(bytes)/float64(KB))
-	case bytes > 0:
-		return fmt.Sprintf("%d B", bytes)
-	default:
+// FormatSizeBytes formats a size as an exact byte count with thousands
+// separators. Unknown sizes (zero or less) are shown as "-".
+func FormatSizeBytes(bytes int64) string {
+	if bytes <= 0 {
 		return "-"
 	}
+	return display.Thousands(bytes)
 }
@@ -5,7 +5,9 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/mkoepf/ghcrctl/internal/display"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestFormatTable_Basic(t *testing.T) {
@@ -32,7 +34,7 @@ func TestFormatTable_Basic(t *testing.T) {
 	assert.Contains(t, output, "SIZE")
 	assert.Contains(t, output, "123")
 	assert.Contains(t, output, "index")
-	assert.Contains(t, output, "1.2 MB")
+	assert.Contains(t, output, "1.2 MiB")
 }
 
 func TestFormatTable_RefIndicators(t *testing.T) {
@@ -77,7 +79,7 @@ func TestShortDigest(t *testing.T) {
 	}
 
 	for _, tt := range tests {
-		result := shortDigest(tt.input)
+		result := FormatOptions{}.shortDigest(tt.input)
 		assert.Equal(t, tt.expected, result, "shortDigest(%s)", tt.input)
 	}
 }
@@ -245,10 +247,10 @@ func TestFormatTree_Size(t *testing.T) {
 	FormatTree(&buf, versions, allVersions)
 
 	output := buf.String()
-	// Root should show 1.5 KB
-	assert.Contains(t, output, "1.5 KB", "expected root size 1.5 KB in output")
-	// Child should show 50.0 MB
-	assert.Contains(t, output, "50.0 MB", "expected child size 50.0 MB in output")
+	// Root should show 1.5 KiB
+	assert.Contains(t, output, "1.5 KiB", "expected root size 1.5 KiB in output")
+	// Child should show 50.0 MiB
+	assert.Contains(t, output, "50.0 MiB", "expected child size 50.0 MiB in output")
 }
 
 func TestFormatTree_Summary(t *testing.T) {
@@ -291,6 +293,23 @@ func TestFormatTree_Summary(t *testing.T) {
 	assert.Contains(t, output, "1 version appears in multiple graphs")
 }
 
+func TestFormatTree_MultiLayerAttestationIsOneNode(t *testing.T) {
+	versions := []VersionInfo{
+		{ID: 100, Digest: "sha256:index", Tags: []string{"v1"}, Types: []string{"index"},
+			OutgoingRefs: []string{"sha256:amd64", "sha256:attest"}},
+		{ID: 101, Digest: "sha256:amd64", Types: []string{"linux/amd64"}, IncomingRefs: []string{"sha256:index"}},
+		{ID: 102, Digest: "sha256:attest", Types: []string{"sbom", "provenance"}, IncomingRefs: []string{"sha256:index"}},
+	}
+
+	var buf bytes.Buffer
+	FormatTree(&buf, versions, ToMap(versions))
+
+	output := buf.String()
+	assert.Equal(t, 1, strings.Count(output, "102"), "the attestation should be listed once")
+	assert.Contains(t, output, "sbom, provenance")
+	assert.Contains(t, output, "3 versions")
+}
+
 func TestFormatTable_Summary(t *testing.T) {
 	versions := []VersionInfo{
 		{
@@ -339,40 +358,40 @@ func TestFormatSize(t *testing.T) {
 			want:  "500 B",
 		},
 		{
-			name:  "exactly 1 KB",
+			name:  "exactly 1 KiB",
 			bytes: 1024,
-			want:  "1.0 KB",
+			want:  "1.0 KiB",
 		},
 		{
-			name:  "1.5 KB",
+			name:  "1.5 KiB",
 			bytes: 1536,
-			want:  "1.5 KB",
+			want:  "1.5 KiB",
 		},
 		{
-			name:  "exactly 1 MB",
+			name:  "exactly 1 MiB",
 			bytes: 1024 * 1024,
-			want:  "1.0 MB",
+			want:  "1.0 MiB",
 		},
 		{
-			name:  "50 MB",
+			name:  "50 MiB",
 			bytes: 50 * 1024 * 1024,
-			want:  "50.0 MB",
+			want:  "50.0 MiB",
 		},
 		{
-			name:  "exactly 1 GB",
+			name:  "exactly 1 GiB",
 			bytes: 1024 * 1024 * 1024,
-			want:  "1.0 GB",
+			want:  "1.0 GiB",
 		},
 		{
-			name:  "2.5 GB",
+			name:  "2.5 GiB",
 			bytes: int64(2.5 * 1024 * 1024 * 1024),
-			want:  "2.5 GB",
+			want:  "2.5 GiB",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := formatSize(tt.bytes)
+			got := FormatSize(tt.bytes)
 			assert.Equal(t, tt.want, got)
 		})
 	}
@@ -475,3 +494,290 @@ func TestFormatTree_AlignedVersionIDs(t *testing.T) {
 		}
 	}
 }
+
+// sizedGraph returns an index with a platform manifest and an SBOM referrer,
+// plus a standalone image
+func sizedGraph() ([]VersionInfo, map[string]VersionInfo) {
+	versions := []VersionInfo{
+		{
+			ID:           100,
+			Digest:       "sha256:index",
+			Types:        []string{"index"},
+			Size:         1024,
+			OutgoingRefs: []string{"sha256:amd64"},
+			IncomingRefs: []string{"sha256:sbom"},
+		},
+		{
+			ID:           101,
+			Digest:       "sha256:amd64",
+			Types:        []string{"linux/amd64"},
+			Size:         2048,
+			IncomingRefs: []string{"sha256:index"},
+		},
+		{
+			ID:           102,
+			Digest:       "sha256:sbom",
+			Types:        []string{"sbom"},
+			Size:         3072,
+			OutgoingRefs: []string{"sha256:index"},
+		},
+		{
+			ID:     200,
+			Digest: "sha256:single",
+			Types:  []string{"manifest"},
+			Size:   512,
+		},
+	}
+	allVersions := make(map[string]VersionInfo)
+	for _, v := range versions {
+		allVersions[v.Digest] = v
+	}
+	return versions, allVersions
+}
+
+func TestFormatTree_SizeOnEveryNode(t *testing.T) {
+	versions, allVersions := sizedGraph()
+
+	var buf bytes.Buffer
+	FormatTree(&buf, versions, allVersions)
+
+	lines := strings.Split(buf.String(), "\n")
+	for _, want := range []struct{ id, size string }{
+		{"100", "1.0 KiB"}, // index
+		{"101", "2.0 KiB"}, // platform
+		{"102", "3.0 KiB"}, // referrer
+		{"200", "512 B"},   // standalone manifest
+	} {
+		found := false
+		for _, line := range lines {
+			if strings.Contains(line, want.id) && strings.Contains(line, want.size) {
+				found = true
+				break
+			}
+		}
+		assert.True(t, found, "expected version %s to show size %s in:\n%s", want.id, want.size, buf.String())
+	}
+	assert.NotContains(t, buf.String(), "Total size:", "totals are opt-in")
+	assert.NotContains(t, buf.String(), "Graph size:", "totals are opt-in")
+}
+
+func TestFormatTree_SizeTotals(t *testing.T) {
+	versions, allVersions := sizedGraph()
+
+	var buf bytes.Buffer
+	FormatTreeWithOptions(&buf, versions, allVersions, FormatOptions{ShowSizeTotals: true})
+
+	output := buf.String()
+	assert.Contains(t, output, "Graph size: 6.0 KiB", "index graph includes platform and referrer")
+	assert.Contains(t, output, "Graph size: 512 B")
+	assert.Contains(t, output, "Total size: 6.5 KiB")
+}
+
+func TestFormatTable_SizeTotals(t *testing.T) {
+	versions, allVersions := sizedGraph()
+
+	var buf bytes.Buffer
+	FormatTableWithOptions(&buf, versions, allVersions, FormatOptions{ShowSizeTotals: true})
+
+	output := buf.String()
+	assert.Contains(t, output, "Total size: 6.5 KiB")
+	assert.NotContains(t, output, "Graph size:")
+}
+
+func TestGraphSize_SharedVersionsCountInEachGraph(t *testing.T) {
+	allVersions := map[string]VersionInfo{
+		"sha256:a":      {Digest: "sha256:a", Types: []string{"index"}, Size: 100, OutgoingRefs: []string{"sha256:shared", "sha256:missing"}},
+		"sha256:b":      {Digest: "sha256:b", Types: []string{"index"}, Size: 200, OutgoingRefs: []string{"sha256:shared"}},
+		"sha256:shared": {Digest: "sha256:shared", Types: []string{"linux/amd64"}, Size: 1000, IncomingRefs: []string{"sha256:a", "sha256:b"}},
+	}
+
+	assert.Equal(t, int64(1100), graphSize(allVersions["sha256:a"], allVersions))
+	assert.Equal(t, int64(1200), graphSize(allVersions["sha256:b"], allVersions))
+}
+
+func TestGraphSize_WalksWholeGraph(t *testing.T) {
+	// index -> nested index -> manifest, with a signature on the manifest and
+	// an attestation on the signature; the manifest is also listed directly
+	// under the root, so it must only count once
+	allVersions := map[string]VersionInfo{
+		"sha256:root":   {Digest: "sha256:root", Types: []string{"index"}, Size: 1, OutgoingRefs: []string{"sha256:nested", "sha256:amd64"}},
+		"sha256:nested": {Digest: "sha256:nested", Types: []string{"index"}, Size: 10, OutgoingRefs: []string{"sha256:amd64"}, IncomingRefs: []string{"sha256:root"}},
+		"sha256:amd64":  {Digest: "sha256:amd64", Types: []string{"linux/amd64"}, Size: 100, IncomingRefs: []string{"sha256:root", "sha256:nested", "sha256:sig"}},
+		"sha256:sig":    {Digest: "sha256:sig", Types: []string{"signature"}, Size: 1000, OutgoingRefs: []string{"sha256:amd64"}, IncomingRefs: []string{"sha256:att"}},
+		"sha256:att":    {Digest: "sha256:att", Types: []string{"attestation"}, Size: 10000, OutgoingRefs: []string{"sha256:sig"}},
+		"sha256:other":  {Digest: "sha256:other", Types: []string{"index"}, Size: 100000},
+	}
+
+	assert.Equal(t, int64(11111), graphSize(allVersions["sha256:root"], allVersions))
+}
+
+func TestFormat_DigestLength(t *testing.T) {
+	indexDigest := "sha256:1111111111aaaaaaaaaabbbbbbbbbbccccccccccddddddddddeeeeeeeeee0123"
+	childDigest := "sha256:2222222222aaaaaaaaaabbbbbbbbbbccccccccccddddddddddeeeeeeeeee4567"
+	versions := []VersionInfo{
+		{ID: 1, Digest: indexDigest, Types: []string{"index"}, OutgoingRefs: []string{childDigest}},
+		{ID: 2, Digest: childDigest, Types: []string{"linux/amd64"}, IncomingRefs: []string{indexDigest}},
+	}
+	allVersions := map[string]VersionInfo{indexDigest: versions[0], childDigest: versions[1]}
+
+	tests := []struct {
+		name     string
+		length   int
+		expected string
+	}{
+		{"default", 0, "1111111111aa"},
+		{"short", 6, "111111"},
+		{"long", 19, "1111111111aaaaaaaaa"},
+		{"full", display.FullDigestLength, strings.TrimPrefix(indexDigest, "sha256:")},
+	}
+
+	for _, tt := range tests {
+		opts := FormatOptions{DigestLength: tt.length}
+		for name, format := range map[string]func(*bytes.Buffer){
+			"tree":  func(buf *bytes.Buffer) { FormatTreeWithOptions(buf, versions, allVersions, opts) },
+			"table": func(buf *bytes.Buffer) { FormatTableWithOptions(buf, versions, allVersions, opts) },
+		} {
+			var buf bytes.Buffer
+			format(&buf)
+			output := buf.String()
+
+			assert.Contains(t, output, tt.expected+" ", "%s/%s: digest truncated to the requested length", tt.name, name)
+			if hex := strings.TrimPrefix(indexDigest, "sha256:"); len(tt.expected) < len(hex) {
+				assert.NotContains(t, output, hex[:len(tt.expected)+1], "%s/%s: digest not longer than requested", tt.name, name)
+			}
+		}
+	}
+}
+
+func TestCalculateTotals(t *testing.T) {
+	t.Parallel()
+	versions, allVersions := sizedGraph()
+
+	assert.Equal(t, Totals{Graphs: 2, Versions: 4, Size: 6656}, CalculateTotals(versions, allVersions))
+	assert.Equal(t, Totals{}, CalculateTotals(nil, allVersions))
+}
+
+func TestFormatTable_TotalsRow(t *testing.T) {
+	t.Parallel()
+	versions, allVersions := sizedGraph()
+
+	var buf bytes.Buffer
+	FormatTable(&buf, versions, allVersions)
+
+	// The SBOM attestation counts towards the versions and the size
+	lines := strings.Split(buf.String(), "\n")
+	var totalsRow string
+	for _, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "TOTAL") {
+			totalsRow = line
+		}
+	}
+	require.NotEmpty(t, totalsRow, "table has a totals row")
+	assert.Contains(t, totalsRow, "6.5 KiB")
+	assert.Contains(t, totalsRow, "2 graphs, 4 versions")
+
+	// The total size is right-aligned in the SIZE column
+	assert.Equal(t, strings.Index(lines[0], "SIZE")+len("SIZE"), strings.Index(totalsRow, "6.5 KiB")+len("6.5 KiB"))
+}
+
+func TestFormat_RawBytes(t *testing.T) {
+	t.Parallel()
+	versions, allVersions := sizedGraph()
+	opts := FormatOptions{RawBytes: true, ShowSizeTotals: true}
+
+	var table, tree bytes.Buffer
+	FormatTableWithOptions(&table, versions, allVersions, opts)
+	FormatTreeWithOptions(&tree, versions, allVersions, opts)
+
+	for name, output := range map[string]string{"table": table.String(), "tree": tree.String()} {
+		assert.Contains(t, output, "2,048", name)
+		assert.Contains(t, output, "Total size: 6,656", name)
+		assert.NotContains(t, output, "KiB", name)
+	}
+}
+
+func TestFormatTable_RightAlignsIDsAndSizes(t *testing.T) {
+	t.Parallel()
+	versions, allVersions := sizedGraph()
+
+	var buf bytes.Buffer
+	FormatTable(&buf, versions, allVersions)
+
+	lines := strings.Split(buf.String(), "\n")
+	idEnd := strings.Index(lines[0], "VERSION ID") + len("VERSION ID")
+	sizeEnd := strings.Index(lines[0], "SIZE") + len("SIZE")
+	for _, want := range []struct{ id, size string }{{"200", "512 B"}, {"101", "2.0 KiB"}} {
+		for _, line := range lines[2:] {
+			if !strings.Contains(line, " "+want.id+" ") {
+				continue
+			}
+			assert.Equal(t, idEnd, strings.Index(line, want.id)+len(want.id), "ID %s is right-aligned", want.id)
+			assert.Equal(t, sizeEnd, strings.Index(line, want.size)+len(want.size), "size %s is right-aligned", want.size)
+		}
+	}
+}
+
+func TestFormat_QuietOmitsFooter(t *testing.T) {
+	t.Parallel()
+	versions, allVersions := sizedGraph()
+
+	var table, tree bytes.Buffer
+	FormatTableWithOptions(&table, versions, allVersions, FormatOptions{Quiet: true})
+	FormatTreeWithOptions(&tree, versions, allVersions, FormatOptions{Quiet: true})
+
+	for name, output := range map[string]string{"table": table.String(), "tree": tree.String()} {
+		assert.NotContains(t, output, "TOTAL", name)
+		assert.NotContains(t, output, "Total:", name)
+		assert.Contains(t, output, "sbom", "%s still lists the versions", name)
+	}
+}
+
+func TestFormatTree_Highlight(t *testing.T) {
+	versions := []VersionInfo{
+		{ID: 100, Digest: "sha256:root1", Types: []string{"index"}, Tags: []string{"v1"},
+			OutgoingRefs: []string{"sha256:amd64", "sha256:arm64"}},
+		{ID: 101, Digest: "sha256:amd64", Types: []string{"linux/amd64"}, IncomingRefs: []string{"sha256:root1"}},
+		{ID: 102, Digest: "sha256:arm64", Types: []string{"linux/arm64"}, IncomingRefs: []string{"sha256:root1"}},
+		{ID: 200, Digest: "sha256:root2", Types: []string{"manifest"}, Tags: []string{"v2"}},
+	}
+	allVersions := ToMap(versions)
+
+	var buf bytes.Buffer
+	FormatTreeWithOptions(&buf, versions, allVersions, FormatOptions{Highlight: "sha256:arm64", Quiet: true})
+
+	var marked []string
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if strings.Contains(line, "◀── HERE") {
+			marked = append(marked, line)
+		}
+	}
+	require.Len(t, marked, 1, "only the highlighted version is marked")
+	assert.Contains(t, marked[0], "102")
+	assert.Contains(t, marked[0], "linux/arm64")
+}
+
+func TestFormatTree_HighlightRootAndSharedChild(t *testing.T) {
+	versions := []VersionInfo{
+		{ID: 100, Digest: "sha256:root1", Types: []string{"index"}, OutgoingRefs: []string{"sha256:shared"}},
+		{ID: 200, Digest: "sha256:root2", Types: []string{"index"}, OutgoingRefs: []string{"sha256:shared"}},
+		{ID: 300, Digest: "sha256:shared", Types: []string{"linux/amd64"}, IncomingRefs: []string{"sha256:root1", "sha256:root2"}},
+	}
+	allVersions := ToMap(versions)
+
+	var buf bytes.Buffer
+	FormatTreeWithOptions(&buf, versions, allVersions, FormatOptions{Highlight: "sha256:shared", Quiet: true})
+	assert.Equal(t, 2, strings.Count(buf.String(), "◀── HERE"), "a shared child is marked under each of its roots")
+
+	buf.Reset()
+	FormatTreeWithOptions(&buf, versions, allVersions, FormatOptions{Highlight: "sha256:root2", Quiet: true})
+	require.Equal(t, 1, strings.Count(buf.String(), "◀── HERE"))
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if strings.Contains(line, "◀── HERE") {
+			assert.Contains(t, line, "200")
+		}
+	}
+
+	buf.Reset()
+	FormatTreeWithOptions(&buf, versions, allVersions, FormatOptions{Quiet: true})
+	assert.NotContains(t, buf.String(), "HERE")
+}
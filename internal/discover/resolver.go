@@ -4,22 +4,44 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"net/http"
-	"os"
+	"io"
+	"slices"
+	"sort"
 	"strings"
 	"sync"
 
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
 	"oras.land/oras-go/v2/registry/remote"
 	"oras.land/oras-go/v2/registry/remote/auth"
-	"oras.land/oras-go/v2/registry/remote/credentials"
-
-	"github.com/mkoepf/ghcrctl/internal/logging"
 )
 
 // typeResolver resolves OCI artifact types.
 type typeResolver interface {
-	resolveVersionInfo(ctx context.Context, image, digest string) ([]string, int64, error)
+	resolveVersionInfo(ctx context.Context, image, digest string) (resolvedVersion, error)
+}
+
+// resolvedVersion holds the details resolved from a version's descriptor and manifest.
+type resolvedVersion struct {
+	Types     []string
+	Size      int64
+	MediaType string
+}
+
+// ManifestParseError is returned when a manifest or index fetched during
+// discovery is not valid JSON of its kind, e.g. after a corrupt push
+type ManifestParseError struct {
+	Digest    string
+	MediaType string
+	Err       error
+}
+
+func (e *ManifestParseError) Error() string {
+	return fmt.Sprintf("failed to parse %s (%s): %v", e.Digest, e.MediaType, e.Err)
+}
+
+func (e *ManifestParseError) Unwrap() error {
+	return e.Err
 }
 
 // orasResolver implements typeResolver using ORAS library.
@@ -33,118 +55,178 @@ func newOrasResolver() *orasResolver {
 	return &orasResolver{}
 }
 
-// resolveVersionInfo resolves the type(s) and size of an OCI artifact by digest.
-func (r *orasResolver) resolveVersionInfo(ctx context.Context, image, digest string) ([]string, int64, error) {
+// resolveVersionInfo resolves the type(s), size and media type of an OCI artifact by digest.
+// Size and media type come from the resolved descriptor, so they need no extra requests.
+func (r *orasResolver) resolveVersionInfo(ctx context.Context, image, digest string) (resolvedVersion, error) {
 	if image == "" {
-		return nil, 0, fmt.Errorf("image cannot be empty")
+		return resolvedVersion{}, fmt.Errorf("image cannot be empty")
 	}
 	if digest == "" {
-		return nil, 0, fmt.Errorf("digest cannot be empty")
+		return resolvedVersion{}, fmt.Errorf("digest cannot be empty")
 	}
 	if !ValidateDigestFormat(digest) {
-		return nil, 0, fmt.Errorf("invalid digest format: %s", digest)
+		return resolvedVersion{}, fmt.Errorf("invalid digest format: %s", digest)
 	}
 
 	registry, path, err := ParseImageReference(image)
 	if err != nil {
-		return nil, 0, err
+		return resolvedVersion{}, err
 	}
 
 	repo, err := remote.NewRepository(fmt.Sprintf("%s/%s", registry, path))
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to create repository: %w", err)
+		return resolvedVersion{}, fmt.Errorf("failed to create repository: %w", err)
 	}
 
 	r.configureAuth(ctx, repo)
+	return r.resolveRepoVersionInfo(ctx, repo, digest)
+}
+
+// resolveRepoVersionInfo resolves the version info of digest in repo. Results
+// are cached per repository, so each manifest is only resolved once.
+func (r *orasResolver) resolveRepoVersionInfo(ctx context.Context, repo *remote.Repository, digest string) (resolvedVersion, error) {
+	key := manifestCacheKey(repo, digest)
+	manifestCacheMu.RLock()
+	cached, ok := resolvedCache[key]
+	manifestCacheMu.RUnlock()
+	if ok {
+		cached.Types = slices.Clone(cached.Types)
+		return cached, nil
+	}
+
+	info, err := r.fetchVersionInfo(ctx, repo, digest)
+	if err != nil {
+		return resolvedVersion{}, err
+	}
+	stored := info
+	stored.Types = slices.Clone(info.Types)
+	manifestCacheMu.Lock()
+	resolvedCache[key] = stored
+	manifestCacheMu.Unlock()
+	return info, nil
+}
 
+// fetchVersionInfo resolves the version info of digest in repo from the registry
+func (r *orasResolver) fetchVersionInfo(ctx context.Context, repo *remote.Repository, digest string) (resolvedVersion, error) {
 	desc, err := repo.Resolve(ctx, digest)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to resolve digest: %w", err)
+		return resolvedVersion{}, fmt.Errorf("failed to resolve digest: %w", err)
 	}
 
-	size := desc.Size
+	info := resolvedVersion{Size: desc.Size, MediaType: desc.MediaType}
 
 	// Check if index
 	if desc.MediaType == ocispec.MediaTypeImageIndex ||
 		desc.MediaType == "application/vnd.docker.distribution.manifest.list.v2+json" {
-		return []string{"index"}, size, nil
+		info.Types = []string{"index"}
+		return info, nil
 	}
 
 	// Fetch manifest to determine type
 	manifestBytes, err := repo.Fetch(ctx, desc)
 	if err != nil {
-		return nil, size, fmt.Errorf("failed to fetch manifest: %w", err)
+		return resolvedVersion{}, fmt.Errorf("failed to fetch manifest: %w", err)
 	}
 	defer manifestBytes.Close()
 
-	var manifest ocispec.Manifest
-	if err := json.NewDecoder(manifestBytes).Decode(&manifest); err != nil {
-		return nil, size, fmt.Errorf("failed to decode manifest: %w", err)
+	manifest, err := decodeManifest(manifestBytes, desc)
+	if err != nil {
+		return resolvedVersion{}, err
 	}
 
 	// Check for signature
-	if isSignature(&manifest) {
-		return []string{"signature"}, size, nil
+	if isSignature(manifest) {
+		info.Types = []string{"signature"}
+		return info, nil
 	}
 
 	// Check for attestation
-	if isAttestation(&manifest) {
-		roles := determineAttestationRoles(&manifest)
+	if isAttestation(manifest) {
+		roles := determineAttestationRoles(manifest)
+		if len(roles) == 0 {
+			// cosign attestations may carry the predicate type only inside the DSSE payload
+			roles = r.fetchEnvelopeRoles(ctx, repo, manifest)
+		}
 		if len(roles) == 0 {
-			return []string{"attestation"}, size, nil
+			info.Types = []string{"attestation"}
+			return info, nil
 		}
-		return roles, size, nil
+		info.Types = roles
+		return info, nil
 	}
 
 	// It's a platform manifest - get os/arch from config
 	configBytes, err := repo.Fetch(ctx, manifest.Config)
 	if err != nil {
-		return []string{"manifest"}, size, nil
+		info.Types = []string{"manifest"}
+		return info, nil
 	}
 	defer configBytes.Close()
 
 	var imageConfig ocispec.Image
 	if err := json.NewDecoder(configBytes).Decode(&imageConfig); err != nil {
-		return []string{"manifest"}, size, nil
+		info.Types = []string{"manifest"}
+		return info, nil
 	}
 
 	platform := imageConfig.OS + "/" + imageConfig.Architecture
 	if imageConfig.Variant != "" {
 		platform += "/" + imageConfig.Variant
 	}
-	return []string{platform}, size, nil
+	info.Types = []string{platform}
+	return info, nil
 }
 
-func (r *orasResolver) configureAuth(ctx context.Context, repo *remote.Repository) {
-	r.authOnce.Do(func() {
-		var httpClient *http.Client
-		if logging.IsLoggingEnabled(ctx) {
-			httpClient = &http.Client{
-				Transport: logging.NewLoggingRoundTripper(http.DefaultTransport, os.Stderr),
-			}
-		}
+// resolveRoot resolves the descriptor of an artifact by digest. Indexes also
+// return the digests they list; every other artifact is typed "manifest"
+// without fetching it.
+func (r *orasResolver) resolveRoot(ctx context.Context, image, digest string) (resolvedVersion, []string, error) {
+	if !ValidateDigestFormat(digest) {
+		return resolvedVersion{}, nil, fmt.Errorf("invalid digest format: %s", digest)
+	}
 
-		token := os.Getenv("GITHUB_TOKEN")
-		if token == "" {
-			r.authClient = &auth.Client{
-				Cache:  auth.NewCache(),
-				Client: httpClient,
-			}
-			return
-		}
+	registry, path, err := ParseImageReference(image)
+	if err != nil {
+		return resolvedVersion{}, nil, err
+	}
 
-		store := credentials.NewMemoryStore()
-		cred := auth.Credential{
-			Username: "oauth2",
-			Password: token,
-		}
-		_ = store.Put(context.Background(), "ghcr.io", cred)
+	repo, err := remote.NewRepository(fmt.Sprintf("%s/%s", registry, path))
+	if err != nil {
+		return resolvedVersion{}, nil, fmt.Errorf("failed to create repository: %w", err)
+	}
 
-		r.authClient = &auth.Client{
-			Cache:      auth.NewCache(),
-			Credential: credentials.Credential(store),
-			Client:     httpClient,
-		}
+	r.configureAuth(ctx, repo)
+
+	desc, err := repo.Resolve(ctx, digest)
+	if err != nil {
+		return resolvedVersion{}, nil, fmt.Errorf("failed to resolve digest: %w", err)
+	}
+
+	info := resolvedVersion{Types: []string{"manifest"}, Size: desc.Size, MediaType: desc.MediaType}
+	if !IsIndexMediaType(desc.MediaType) {
+		return info, nil, nil
+	}
+
+	children, err := fetchIndexChildren(ctx, repo, desc)
+	if err != nil {
+		return resolvedVersion{}, nil, fmt.Errorf("failed to fetch index: %w", err)
+	}
+	info.Types = []string{"index"}
+	return info, children, nil
+}
+
+// decodeManifest decodes the manifest described by desc
+func decodeManifest(r io.Reader, desc ocispec.Descriptor) (*ocispec.Manifest, error) {
+	var manifest ocispec.Manifest
+	if err := json.NewDecoder(r).Decode(&manifest); err != nil {
+		return nil, &ManifestParseError{Digest: desc.Digest.String(), MediaType: desc.MediaType, Err: err}
+	}
+	return &manifest, nil
+}
+
+func (r *orasResolver) configureAuth(ctx context.Context, repo *remote.Repository) {
+	r.authOnce.Do(func() {
+		r.authClient = newAuthClient(ctx)
 	})
 	repo.Client = r.authClient
 }
@@ -163,7 +245,7 @@ func isAttestation(manifest *ocispec.Manifest) bool {
 		return true
 	}
 	for _, layer := range manifest.Layers {
-		if strings.Contains(layer.MediaType, "in-toto") {
+		if strings.Contains(layer.MediaType, "in-toto") || layer.MediaType == dsseEnvelopeMediaType {
 			return true
 		}
 		if layer.Annotations != nil {
@@ -178,6 +260,32 @@ func isAttestation(manifest *ocispec.Manifest) bool {
 	return false
 }
 
+// fetchEnvelopeRoles determines attestation roles from the predicate types in
+// the manifest's DSSE envelope layers. Layers that cannot be fetched or decoded
+// are skipped.
+func (r *orasResolver) fetchEnvelopeRoles(ctx context.Context, repo *remote.Repository, manifest *ocispec.Manifest) []string {
+	roleSet := make(map[string]bool)
+	for _, layer := range manifest.Layers {
+		if layer.MediaType != dsseEnvelopeMediaType {
+			continue
+		}
+		data, err := content.FetchAll(ctx, repo, layer)
+		if err != nil {
+			continue
+		}
+		if role := envelopeRole(data); role != "" {
+			roleSet[role] = true
+		}
+	}
+
+	var roles []string
+	for role := range roleSet {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	return roles
+}
+
 func determineAttestationRoles(manifest *ocispec.Manifest) []string {
 	roleSet := make(map[string]bool)
 
@@ -196,6 +304,7 @@ func determineAttestationRoles(manifest *ocispec.Manifest) []string {
 	for role := range roleSet {
 		roles = append(roles, role)
 	}
+	sort.Strings(roles)
 	return roles
 }
 
@@ -2,11 +2,18 @@ package discover
 
 import (
 	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"oras.land/oras-go/v2/registry/remote"
 )
 
 // mockResolver implements typeResolver for testing
@@ -14,9 +21,17 @@ type mockResolver struct {
 	resolveFunc func(ctx context.Context, image, digest string) ([]string, error)
 }
 
-func (m *mockResolver) resolveVersionInfo(ctx context.Context, image, digest string) ([]string, int64, error) {
+func (m *mockResolver) resolveVersionInfo(ctx context.Context, image, digest string) (resolvedVersion, error) {
 	types, err := m.resolveFunc(ctx, image, digest)
-	return types, 1024, err // Return a default size of 1024 for testing
+	if err != nil {
+		return resolvedVersion{}, err
+	}
+	// Return a default size of 1024 and a media type matching the kind of artifact
+	mediaType := ocispec.MediaTypeImageManifest
+	if len(types) > 0 && types[0] == "index" {
+		mediaType = ocispec.MediaTypeImageIndex
+	}
+	return resolvedVersion{Types: types, Size: 1024, MediaType: mediaType}, nil
 }
 
 func TestResolveVersionInfo_Index(t *testing.T) {
@@ -26,8 +41,9 @@ func TestResolveVersionInfo_Index(t *testing.T) {
 		},
 	}
 
-	types, _, err := resolver.resolveVersionInfo(context.Background(), "ghcr.io/test/image", "sha256:abc123")
+	info, err := resolver.resolveVersionInfo(context.Background(), "ghcr.io/test/image", "sha256:abc123")
 	require.NoError(t, err)
+	types := info.Types
 	assert.Equal(t, []string{"index"}, types)
 }
 
@@ -38,8 +54,9 @@ func TestResolveVersionInfo_Platform(t *testing.T) {
 		},
 	}
 
-	types, _, err := resolver.resolveVersionInfo(context.Background(), "ghcr.io/test/image", "sha256:abc123")
+	info, err := resolver.resolveVersionInfo(context.Background(), "ghcr.io/test/image", "sha256:abc123")
 	require.NoError(t, err)
+	types := info.Types
 	assert.Equal(t, []string{"linux/amd64"}, types)
 }
 
@@ -50,8 +67,9 @@ func TestResolveVersionInfo_MultipleAttestations(t *testing.T) {
 		},
 	}
 
-	types, _, err := resolver.resolveVersionInfo(context.Background(), "ghcr.io/test/image", "sha256:abc123")
+	info, err := resolver.resolveVersionInfo(context.Background(), "ghcr.io/test/image", "sha256:abc123")
 	require.NoError(t, err)
+	types := info.Types
 	assert.Len(t, types, 2)
 }
 
@@ -244,6 +262,18 @@ func TestIsAttestation(t *testing.T) {
 			},
 			want: true,
 		},
+		{
+			name: "cosign DSSE envelope without annotations",
+			manifest: &ocispec.Manifest{
+				Config: ocispec.Descriptor{
+					MediaType: "application/vnd.oci.image.config.v1+json",
+				},
+				Layers: []ocispec.Descriptor{
+					{MediaType: "application/vnd.dsse.envelope.v1+json"},
+				},
+			},
+			want: true,
+		},
 		{
 			name: "regular image manifest",
 			manifest: &ocispec.Manifest{
@@ -355,3 +385,105 @@ func TestDetermineAttestationRoles(t *testing.T) {
 		})
 	}
 }
+
+// sharedDigest is pushed to both repositories of repoRegistry with different content
+const sharedDigest = "sha256:5555555555555555555555555555555555555555555555555555555555555555"
+
+// repoRegistry serves one manifest per repository, all under sharedDigest, and
+// counts the requests for each repository
+type repoRegistry struct {
+	mu        sync.Mutex
+	manifests map[string]string // repository -> manifest JSON
+	mediaType map[string]string // repository -> media type
+	requests  map[string]int
+}
+
+func (r *repoRegistry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	repository, _, ok := strings.Cut(strings.TrimPrefix(req.URL.Path, "/v2/"), "/manifests/")
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	r.requests[repository]++
+	body := r.manifests[repository]
+	w.Header().Set("Content-Type", r.mediaType[repository])
+	w.Header().Set("Docker-Content-Digest", sharedDigest)
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	if req.Method == http.MethodGet {
+		_, _ = io.WriteString(w, body)
+	}
+}
+
+// count returns the number of requests for repository
+func (r *repoRegistry) count(repository string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.requests[repository]
+}
+
+// plainRepository returns a repository on the test server without authentication
+func plainRepository(t *testing.T, server *httptest.Server, repository string) *remote.Repository {
+	t.Helper()
+	repo, err := remote.NewRepository(strings.TrimPrefix(server.URL, "http://") + "/" + repository)
+	require.NoError(t, err)
+	repo.PlainHTTP = true
+	return repo
+}
+
+func TestManifestCaches_KeyedByRepository(t *testing.T) {
+	ResetCaches()
+	t.Cleanup(ResetCaches)
+
+	registry := &repoRegistry{
+		manifests: map[string]string{
+			"owner/a": `{"schemaVersion": 2, "manifests": [{"mediaType": "application/vnd.oci.image.manifest.v1+json", "digest": "sha256:aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa", "size": 1}]}`,
+			"owner/b": `{"schemaVersion": 2, "manifests": [{"mediaType": "application/vnd.oci.image.manifest.v1+json", "digest": "sha256:bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb", "size": 1}, {"mediaType": "application/vnd.oci.image.manifest.v1+json", "digest": "sha256:cccccccccccccccccccccccccccccccccccccccccccccccccccccccccccccccc", "size": 1}]}`,
+		},
+		mediaType: map[string]string{
+			"owner/a": ocispec.MediaTypeImageIndex,
+			"owner/b": ocispec.MediaTypeImageIndex,
+		},
+		requests: make(map[string]int),
+	}
+	server := httptest.NewServer(registry)
+	defer server.Close()
+	ctx := context.Background()
+	repoA := plainRepository(t, server, "owner/a")
+	repoB := plainRepository(t, server, "owner/b")
+	resolver := newOrasResolver()
+
+	infoA, err := resolver.resolveRepoVersionInfo(ctx, repoA, sharedDigest)
+	require.NoError(t, err)
+	infoB, err := resolver.resolveRepoVersionInfo(ctx, repoB, sharedDigest)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(registry.manifests["owner/a"])), infoA.Size)
+	assert.Equal(t, int64(len(registry.manifests["owner/b"])), infoB.Size, "repository b must not get the cached info of a")
+
+	desc, err := repoA.Resolve(ctx, sharedDigest)
+	require.NoError(t, err)
+	childrenA, err := fetchIndexChildren(ctx, repoA, desc)
+	require.NoError(t, err)
+	desc, err = repoB.Resolve(ctx, sharedDigest)
+	require.NoError(t, err)
+	childrenB, err := fetchIndexChildren(ctx, repoB, desc)
+	require.NoError(t, err)
+	assert.Len(t, childrenA, 1)
+	assert.Len(t, childrenB, 2, "repository b must not get the cached children of a")
+
+	// Both are answered from the cache now
+	requests := registry.count("owner/a")
+	_, err = resolver.resolveRepoVersionInfo(ctx, repoA, sharedDigest)
+	require.NoError(t, err)
+	_, err = fetchIndexChildren(ctx, repoA, desc)
+	require.NoError(t, err)
+	assert.Equal(t, requests, registry.count("owner/a"))
+
+	// A reset fetches them again
+	ResetCaches()
+	_, err = resolver.resolveRepoVersionInfo(ctx, repoA, sharedDigest)
+	require.NoError(t, err)
+	assert.Greater(t, registry.count("owner/a"), requests)
+}
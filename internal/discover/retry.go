@@ -0,0 +1,135 @@
+package discover
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/registry/remote"
+	"oras.land/oras-go/v2/registry/remote/errcode"
+)
+
+// retryPolicy controls how transient registry errors are retried.
+type retryPolicy struct {
+	Attempts int           // Total attempts including the first (values below 1 mean 1)
+	Backoff  time.Duration // Delay before the first retry, doubled after each retry
+}
+
+// defaultRetryPolicy is used for tag resolution.
+var defaultRetryPolicy = retryPolicy{Attempts: 3, Backoff: 500 * time.Millisecond}
+
+// referenceResolver resolves a tag or digest to a descriptor.
+// *remote.Repository satisfies this interface.
+type referenceResolver interface {
+	Resolve(ctx context.Context, reference string) (ocispec.Descriptor, error)
+}
+
+// resolveWithRetry resolves reference, retrying transient failures with exponential backoff.
+// Permanent errors such as a missing tag are returned immediately.
+func resolveWithRetry(ctx context.Context, resolver referenceResolver, reference string, policy retryPolicy) (ocispec.Descriptor, error) {
+	attempts := policy.Attempts
+	if attempts < 1 {
+		attempts = 1
+	}
+	backoff := policy.Backoff
+
+	var lastErr error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		desc, err := resolver.Resolve(ctx, reference)
+		if err == nil {
+			return desc, nil
+		}
+		lastErr = err
+
+		if attempt == attempts || !isTransientError(err) {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return ocispec.Descriptor{}, fmt.Errorf("%w (last error: %v)", ctx.Err(), lastErr)
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+	return ocispec.Descriptor{}, lastErr
+}
+
+// isTransientError reports whether err is worth retrying: server errors,
+// rate limiting, and network failures. Not-found and client errors are permanent.
+func isTransientError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, errdef.ErrNotFound) {
+		return false
+	}
+
+	var respErr *errcode.ErrorResponse
+	if errors.As(err, &respErr) {
+		return respErr.StatusCode >= http.StatusInternalServerError ||
+			respErr.StatusCode == http.StatusTooManyRequests
+	}
+
+	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// isUnauthorized reports whether the registry answered err with 401.
+func isUnauthorized(err error) bool {
+	var respErr *errcode.ErrorResponse
+	return errors.As(err, &respErr) && respErr.StatusCode == http.StatusUnauthorized
+}
+
+// retryOnUnauthorized runs op, which sends registry requests through repo. The
+// auth client fetches a new token when a cached one is rejected with a
+// challenge, but a bearer token that expires during a long run can also be
+// answered with a plain 401. In that case the cached tokens are dropped and op
+// runs once more with a fresh auth client.
+func retryOnUnauthorized(ctx context.Context, repo *remote.Repository, op func() error) error {
+	err := op()
+	if !isUnauthorized(err) {
+		return err
+	}
+	repo.Client = refreshAuthClient(ctx, repo.Client)
+	return op()
+}
+
+// resolveRefreshing resolves reference like repo.Resolve, refreshing the
+// registry token on 401
+func resolveRefreshing(ctx context.Context, repo *remote.Repository, reference string) (ocispec.Descriptor, error) {
+	var desc ocispec.Descriptor
+	err := retryOnUnauthorized(ctx, repo, func() (err error) {
+		desc, err = repo.Resolve(ctx, reference)
+		return err
+	})
+	return desc, err
+}
+
+// fetchRefreshing fetches the content of desc like repo.Fetch, refreshing the
+// registry token on 401
+func fetchRefreshing(ctx context.Context, repo *remote.Repository, desc ocispec.Descriptor) (io.ReadCloser, error) {
+	var rc io.ReadCloser
+	err := retryOnUnauthorized(ctx, repo, func() (err error) {
+		rc, err = repo.Fetch(ctx, desc)
+		return err
+	})
+	return rc, err
+}
+
+// tagRefreshing tags desc like repo.Tag, refreshing the registry token on 401
+func tagRefreshing(ctx context.Context, repo *remote.Repository, desc ocispec.Descriptor, reference string) error {
+	return retryOnUnauthorized(ctx, repo, func() error {
+		return repo.Tag(ctx, desc, reference)
+	})
+}
@@ -0,0 +1,224 @@
+package discover
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/registry/remote"
+	"oras.land/oras-go/v2/registry/remote/auth"
+	"oras.land/oras-go/v2/registry/remote/errcode"
+)
+
+// flakyResolver fails with the queued errors before succeeding
+type flakyResolver struct {
+	errs  []error
+	calls int
+}
+
+func (f *flakyResolver) Resolve(ctx context.Context, reference string) (ocispec.Descriptor, error) {
+	f.calls++
+	if f.calls <= len(f.errs) {
+		return ocispec.Descriptor{}, f.errs[f.calls-1]
+	}
+	return ocispec.Descriptor{Digest: "sha256:1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef"}, nil
+}
+
+var testRetryPolicy = retryPolicy{Attempts: 3, Backoff: time.Millisecond}
+
+func serverError(status int) error {
+	return &errcode.ErrorResponse{Method: http.MethodHead, StatusCode: status}
+}
+
+func TestResolveWithRetry_SucceedsAfterTransientFailures(t *testing.T) {
+	t.Parallel()
+
+	resolver := &flakyResolver{errs: []error{
+		serverError(http.StatusBadGateway),
+		&net.OpError{Op: "read", Net: "tcp", Err: fmt.Errorf("connection reset by peer")},
+	}}
+
+	desc, err := resolveWithRetry(context.Background(), resolver, "latest", testRetryPolicy)
+	require.NoError(t, err)
+	assert.NotEmpty(t, desc.Digest)
+	assert.Equal(t, 3, resolver.calls)
+}
+
+func TestResolveWithRetry_NotFoundIsNotRetried(t *testing.T) {
+	t.Parallel()
+
+	resolver := &flakyResolver{errs: []error{fmt.Errorf("latest: %w", errdef.ErrNotFound)}}
+
+	_, err := resolveWithRetry(context.Background(), resolver, "latest", testRetryPolicy)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, errdef.ErrNotFound)
+	assert.Equal(t, 1, resolver.calls)
+}
+
+func TestResolveWithRetry_GivesUpAfterMaxAttempts(t *testing.T) {
+	t.Parallel()
+
+	resolver := &flakyResolver{errs: []error{
+		serverError(http.StatusServiceUnavailable),
+		serverError(http.StatusServiceUnavailable),
+		serverError(http.StatusServiceUnavailable),
+		serverError(http.StatusServiceUnavailable),
+	}}
+
+	_, err := resolveWithRetry(context.Background(), resolver, "latest", testRetryPolicy)
+	require.Error(t, err)
+	assert.Equal(t, 3, resolver.calls)
+}
+
+func TestResolveWithRetry_RespectsContextCancellation(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	resolver := &flakyResolver{errs: []error{serverError(http.StatusInternalServerError)}}
+
+	_, err := resolveWithRetry(ctx, resolver, "latest", retryPolicy{Attempts: 3, Backoff: time.Hour})
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, resolver.calls)
+}
+
+func TestIsTransientError(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"server error", serverError(http.StatusInternalServerError), true},
+		{"rate limited", serverError(http.StatusTooManyRequests), true},
+		{"unauthorized", serverError(http.StatusUnauthorized), false},
+		{"not found status", serverError(http.StatusNotFound), false},
+		{"not found errdef", fmt.Errorf("v1: %w", errdef.ErrNotFound), false},
+		{"network error", &net.OpError{Op: "dial", Net: "tcp", Err: fmt.Errorf("refused")}, true},
+		{"context canceled", context.Canceled, false},
+		{"plain error", fmt.Errorf("something else"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isTransientError(tt.err))
+		})
+	}
+}
+
+func TestIsUnauthorized(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, isUnauthorized(fmt.Errorf("resolve: %w", serverError(http.StatusUnauthorized))))
+	assert.False(t, isUnauthorized(serverError(http.StatusForbidden)))
+	assert.False(t, isUnauthorized(fmt.Errorf("something else")))
+	assert.False(t, isUnauthorized(nil))
+}
+
+func TestRetryOnUnauthorized(t *testing.T) {
+	t.Cleanup(ResetCaches)
+
+	tests := []struct {
+		name      string
+		errs      []error
+		wantCalls int
+		wantErr   bool
+	}{
+		{"success", nil, 1, false},
+		{"refreshed after 401", []error{serverError(http.StatusUnauthorized)}, 2, false},
+		{"retried only once", []error{serverError(http.StatusUnauthorized), serverError(http.StatusUnauthorized)}, 2, true},
+		{"other errors are not retried", []error{serverError(http.StatusNotFound)}, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &remote.Repository{}
+			calls := 0
+			err := retryOnUnauthorized(context.Background(), repo, func() error {
+				calls++
+				if calls <= len(tt.errs) {
+					return tt.errs[calls-1]
+				}
+				return nil
+			})
+
+			assert.Equal(t, tt.wantCalls, calls)
+			assert.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
+
+// expiringTokenRegistry is a registry that accepts each bearer token for one
+// request only. An expired token is answered with a plain 401 without a
+// challenge, which auth.Client cannot recover from on its own.
+type expiringTokenRegistry struct {
+	mu     sync.Mutex
+	issued int
+	used   map[string]bool
+}
+
+func (r *expiringTokenRegistry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if req.URL.Path == "/token" {
+		r.issued++
+		fmt.Fprintf(w, `{"token": "token-%d"}`, r.issued)
+		return
+	}
+
+	token, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		w.Header().Set("Www-Authenticate", fmt.Sprintf(`Bearer realm="http://%s/token",service="test"`, req.Host))
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if r.used[token] {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	r.used[token] = true
+
+	w.Header().Set("Content-Type", ocispec.MediaTypeImageManifest)
+	w.Header().Set("Docker-Content-Digest", "sha256:1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef")
+	w.Header().Set("Content-Length", "100")
+}
+
+func TestResolveRefreshing_RefreshesExpiredToken(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "")
+	ResetCaches()
+	t.Cleanup(ResetCaches)
+
+	registry := &expiringTokenRegistry{used: make(map[string]bool)}
+	server := httptest.NewServer(registry)
+	defer server.Close()
+
+	repo, err := remote.NewRepository(strings.TrimPrefix(server.URL, "http://") + "/owner/pkg")
+	require.NoError(t, err)
+	repo.PlainHTTP = true
+	repo.Client = &auth.Client{Cache: auth.NewCache()}
+	ctx := context.Background()
+
+	_, err = resolveRefreshing(ctx, repo, "latest")
+	require.NoError(t, err)
+	assert.Equal(t, 1, registry.issued)
+
+	// The cached token has expired; the second resolve refreshes it transparently
+	desc, err := resolveRefreshing(ctx, repo, "latest")
+	require.NoError(t, err)
+	assert.Equal(t, "sha256:1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef", desc.Digest.String())
+	assert.Equal(t, 2, registry.issued)
+}
@@ -0,0 +1,76 @@
+package discover
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SubjectDigests returns the digests an attestation or signature is about,
+// sorted and without duplicates. They are read from the subject of its
+// manifest (OCI referrers), the subjects of the in-toto statements in its
+// layers, including DSSE-wrapped ones as pushed by cosign, and the image digest
+// of cosign simple signing payloads.
+func SubjectDigests(manifest []byte, documents []map[string]interface{}) []string {
+	seen := make(map[string]bool)
+
+	var parsed struct {
+		Subject *struct {
+			Digest string `json:"digest"`
+		} `json:"subject"`
+	}
+	if err := json.Unmarshal(manifest, &parsed); err == nil && parsed.Subject != nil && parsed.Subject.Digest != "" {
+		seen[parsed.Subject.Digest] = true
+	}
+
+	for _, doc := range documents {
+		if statement := InTotoStatement(doc); statement != nil {
+			subjects, _ := statement["subject"].([]interface{})
+			for _, s := range subjects {
+				subject, _ := s.(map[string]interface{})
+				digests, _ := subject["digest"].(map[string]interface{})
+				for algorithm, value := range digests {
+					if hex, ok := value.(string); ok && hex != "" {
+						seen[algorithm+":"+hex] = true
+					}
+				}
+			}
+			continue
+		}
+
+		// Cosign simple signing: {"critical": {"image": {"docker-manifest-digest": ...}}}
+		critical, _ := doc["critical"].(map[string]interface{})
+		image, _ := critical["image"].(map[string]interface{})
+		if digest, ok := image["docker-manifest-digest"].(string); ok && strings.Contains(digest, ":") {
+			seen[digest] = true
+		}
+	}
+
+	digests := make([]string, 0, len(seen))
+	for digest := range seen {
+		digests = append(digests, digest)
+	}
+	sort.Strings(digests)
+	return digests
+}
+
+// ExtractSubjectDigests fetches the manifest and layers of the attestation or
+// signature with the given digest and returns its subject digests (see
+// SubjectDigests). Unlike discovery, which follows references from the
+// subject, this reads the link from the attestation itself.
+func ExtractSubjectDigests(ctx context.Context, image, digest string) ([]string, error) {
+	manifest, _, err := FetchManifest(ctx, image, digest)
+	if err != nil {
+		return nil, err
+	}
+
+	// Artifacts without JSON layers may still name their subject in the manifest
+	documents, contentErr := GetArtifactContent(ctx, image, digest)
+	subjects := SubjectDigests(manifest, documents)
+	if len(subjects) == 0 && contentErr != nil {
+		return nil, fmt.Errorf("failed to read the subject of %s: %w", digest, contentErr)
+	}
+	return subjects, nil
+}
@@ -0,0 +1,64 @@
+package discover
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubjectDigests(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		manifest  string
+		documents []map[string]interface{}
+		want      []string
+	}{
+		{
+			name:      "cosign attestation",
+			manifest:  `{"schemaVersion": 2, "layers": []}`,
+			documents: []map[string]interface{}{cosignAttestationLayer(cosignSBOMStatement)},
+			want:      []string{"sha256:aaaa"},
+		},
+		{
+			name:     "buildx attestation",
+			manifest: `{"schemaVersion": 2}`,
+			documents: []map[string]interface{}{
+				{"predicateType": "https://spdx.dev/Document", "subject": []interface{}{
+					map[string]interface{}{"name": "pkg:docker/myimage", "digest": map[string]interface{}{"sha256": "bbbb"}},
+				}},
+				{"predicateType": "https://slsa.dev/provenance/v0.2", "subject": []interface{}{
+					map[string]interface{}{"name": "pkg:docker/myimage", "digest": map[string]interface{}{"sha256": "bbbb"}},
+				}},
+			},
+			want: []string{"sha256:bbbb"},
+		},
+		{
+			name:     "cosign signature",
+			manifest: `{"schemaVersion": 2}`,
+			documents: []map[string]interface{}{
+				{"critical": map[string]interface{}{"image": map[string]interface{}{"docker-manifest-digest": "sha256:cccc"}}},
+			},
+			want: []string{"sha256:cccc"},
+		},
+		{
+			name:     "OCI referrer",
+			manifest: `{"schemaVersion": 2, "subject": {"mediaType": "application/vnd.oci.image.manifest.v1+json", "digest": "sha256:dddd"}}`,
+			want:     []string{"sha256:dddd"},
+		},
+		{
+			name:      "no subject",
+			manifest:  `{"schemaVersion": 2}`,
+			documents: []map[string]interface{}{{"spdxVersion": "SPDX-2.3"}},
+			want:      []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, SubjectDigests([]byte(tt.manifest), tt.documents))
+		})
+	}
+}
@@ -1,15 +1,43 @@
 package discover
 
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mkoepf/ghcrctl/internal/gh"
+)
+
 // VersionInfo contains information about a package version with reference relationships.
+// GHCR sometimes lists the same digest under several version IDs; discovery
+// merges them into one VersionInfo that keeps the other IDs in DuplicateIDs.
 type VersionInfo struct {
 	ID           int64    `json:"id"`
+	DuplicateIDs []int64  `json:"duplicate_ids,omitempty"`
 	Digest       string   `json:"digest"`
 	Tags         []string `json:"tags"`
 	Types        []string `json:"types"`
 	Size         int64    `json:"size"`
+	MediaType    string   `json:"media_type"`
 	OutgoingRefs []string `json:"outgoing_refs"`
 	IncomingRefs []string `json:"incoming_refs"`
 	CreatedAt    string   `json:"created_at"`
+	Warnings     []string `json:"warnings,omitempty"`
+}
+
+// MarshalJSON writes CreatedAt as an RFC3339 timestamp, like the JSON output
+// of gh.PackageVersionInfo
+func (v VersionInfo) MarshalJSON() ([]byte, error) {
+	type plain VersionInfo // without the MarshalJSON method
+	p := plain(v)
+	p.CreatedAt = gh.RFC3339Timestamp(v.CreatedAt)
+	return json.Marshal(p)
+}
+
+// VersionIDs returns the version ID followed by the IDs of duplicate versions
+// with the same digest. Deleting the digest means deleting all of them.
+func (v VersionInfo) VersionIDs() []int64 {
+	return append([]int64{v.ID}, v.DuplicateIDs...)
 }
 
 // IsReferrer returns true if this version is a signature or attestation type.
@@ -73,3 +101,138 @@ func (v VersionInfo) IsRoot(allVersions map[string]VersionInfo) bool {
 	}
 	return true
 }
+
+// typeRoles lists the roles accepted by FilterByType.
+// "platform" matches platform manifests such as "linux/amd64".
+var typeRoles = []string{"index", "manifest", "platform", "sbom", "provenance", "signature", "vex", "vuln-scan", "attestation", "unknown"}
+
+// ValidateTypeRoles returns an error if any role is not a recognized type role.
+func ValidateTypeRoles(roles []string) error {
+	valid := make(map[string]bool, len(typeRoles))
+	for _, role := range typeRoles {
+		valid[role] = true
+	}
+	for _, role := range roles {
+		if !valid[role] {
+			return fmt.Errorf("unknown type %q (valid types: %s)", role, strings.Join(typeRoles, ", "))
+		}
+	}
+	return nil
+}
+
+// HasRole returns true if any of the version's types matches the given role.
+func (v VersionInfo) HasRole(role string) bool {
+	for _, t := range v.Types {
+		if t == role || (role == "platform" && strings.Contains(t, "/")) {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterByType returns the versions having at least one of the include roles
+// (all versions if include is empty) and none of the exclude roles.
+// Exclusion takes precedence for versions carrying multiple types.
+func FilterByType(versions []VersionInfo, include, exclude []string) []VersionInfo {
+	var result []VersionInfo
+	for _, v := range versions {
+		if len(include) > 0 && !v.hasAnyRole(include) {
+			continue
+		}
+		if v.hasAnyRole(exclude) {
+			continue
+		}
+		result = append(result, v)
+	}
+	return result
+}
+
+// FilterByMediaType returns the versions whose descriptor media type is one of
+// mediaTypes, e.g. application/vnd.oci.image.index.v1+json. All versions are
+// returned if mediaTypes is empty.
+func FilterByMediaType(versions []VersionInfo, mediaTypes []string) []VersionInfo {
+	if len(mediaTypes) == 0 {
+		return versions
+	}
+	var result []VersionInfo
+	for _, v := range versions {
+		for _, mediaType := range mediaTypes {
+			if v.MediaType == mediaType {
+				result = append(result, v)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// Scopes of a referrer, i.e. what a signature or attestation is attached to
+const (
+	ReferrerScopeIndex    = "index"    // attached to an index, including the attestations buildx lists in it
+	ReferrerScopePlatform = "platform" // attached to a platform or standalone manifest
+)
+
+// ReferrerScope returns ReferrerScopeIndex if the referrer v is attached to an
+// index in allVersions, ReferrerScopePlatform if it is attached to another
+// version, and "" if v is no referrer or not attached to anything.
+func ReferrerScope(v VersionInfo, allVersions map[string]VersionInfo) string {
+	if !v.IsReferrer() {
+		return ""
+	}
+	scope := ""
+	for _, parentDigest := range v.IncomingRefs {
+		parent, ok := allVersions[parentDigest]
+		if !ok {
+			continue
+		}
+		if parent.HasRole("index") {
+			return ReferrerScopeIndex
+		}
+		scope = ReferrerScopePlatform
+	}
+	return scope
+}
+
+// FilterByReferrerScope drops the referrers whose scope is not scope. Versions
+// other than referrers, and orphaned referrers, are kept. The dropped
+// referrers are removed from the refs of the kept versions, so that they are
+// not shown as missing children.
+func FilterByReferrerScope(versions []VersionInfo, allVersions map[string]VersionInfo, scope string) []VersionInfo {
+	dropped := make(map[string]bool)
+	for _, v := range versions {
+		if s := ReferrerScope(v, allVersions); s != "" && s != scope {
+			dropped[v.Digest] = true
+		}
+	}
+
+	var result []VersionInfo
+	for _, v := range versions {
+		if dropped[v.Digest] {
+			continue
+		}
+		v.OutgoingRefs = withoutDigests(v.OutgoingRefs, dropped)
+		v.IncomingRefs = withoutDigests(v.IncomingRefs, dropped)
+		result = append(result, v)
+	}
+	return result
+}
+
+// withoutDigests returns refs without the digests in drop
+func withoutDigests(refs []string, drop map[string]bool) []string {
+	var result []string
+	for _, ref := range refs {
+		if !drop[ref] {
+			result = append(result, ref)
+		}
+	}
+	return result
+}
+
+func (v VersionInfo) hasAnyRole(roles []string) bool {
+	for _, role := range roles {
+		if v.HasRole(role) {
+			return true
+		}
+	}
+	return false
+}
@@ -1,6 +1,7 @@
 package discover
 
 import (
+	"bytes"
 	"encoding/json"
 	"testing"
 
@@ -15,6 +16,7 @@ func TestVersionInfo_JSONSerialization(t *testing.T) {
 		Tags:         []string{"v1.0.0", "latest"},
 		Types:        []string{"index"},
 		Size:         1048576, // 1 MB
+		MediaType:    "application/vnd.oci.image.index.v1+json",
 		OutgoingRefs: []string{"sha256:def456"},
 		IncomingRefs: []string{"sha256:ghi789"},
 		CreatedAt:    "2025-01-15 10:30:45",
@@ -31,6 +33,10 @@ func TestVersionInfo_JSONSerialization(t *testing.T) {
 	assert.Equal(t, v.Digest, decoded.Digest)
 	assert.Len(t, decoded.Tags, len(v.Tags))
 	assert.Equal(t, v.Size, decoded.Size)
+	assert.Equal(t, v.MediaType, decoded.MediaType)
+	assert.Contains(t, string(data), `"media_type":"application/vnd.oci.image.index.v1+json"`)
+	assert.Contains(t, string(data), `"created_at":"2025-01-15T10:30:45Z"`)
+	assert.Equal(t, "2025-01-15 10:30:45", v.CreatedAt, "marshalling must not change the version")
 }
 
 func TestVersionInfo_IsReferrer(t *testing.T) {
@@ -113,3 +119,188 @@ func TestVersionInfo_IsRoot(t *testing.T) {
 		})
 	}
 }
+
+func TestVersionInfo_HasRole(t *testing.T) {
+	tests := []struct {
+		name     string
+		types    []string
+		role     string
+		expected bool
+	}{
+		{"exact match", []string{"sbom"}, "sbom", true},
+		{"no match", []string{"sbom"}, "provenance", false},
+		{"platform matches os/arch", []string{"linux/amd64"}, "platform", true},
+		{"platform does not match index", []string{"index"}, "platform", false},
+		{"second of multiple types", []string{"sbom", "provenance"}, "provenance", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := VersionInfo{Types: tt.types}
+			assert.Equal(t, tt.expected, v.HasRole(tt.role))
+		})
+	}
+}
+
+func TestFilterByType(t *testing.T) {
+	versions := []VersionInfo{
+		{ID: 1, Types: []string{"index"}},
+		{ID: 2, Types: []string{"linux/amd64"}},
+		{ID: 3, Types: []string{"sbom"}},
+		{ID: 4, Types: []string{"sbom", "provenance"}},
+		{ID: 5, Types: []string{"signature"}},
+	}
+
+	ids := func(vs []VersionInfo) []int64 {
+		var result []int64
+		for _, v := range vs {
+			result = append(result, v.ID)
+		}
+		return result
+	}
+
+	tests := []struct {
+		name     string
+		include  []string
+		exclude  []string
+		expected []int64
+	}{
+		{"no filters", nil, nil, []int64{1, 2, 3, 4, 5}},
+		{"include single role", []string{"sbom"}, nil, []int64{3, 4}},
+		{"include multiple roles", []string{"index", "platform"}, nil, []int64{1, 2}},
+		{"exclude supply-chain roles", nil, []string{"sbom", "provenance", "signature"}, []int64{1, 2}},
+		{"multi-type version excluded by either type", nil, []string{"provenance"}, []int64{1, 2, 3, 5}},
+		{"exclude wins over include", []string{"sbom"}, []string{"provenance"}, []int64{3}},
+		{"include with no matches", []string{"vex"}, nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ids(FilterByType(versions, tt.include, tt.exclude)))
+		})
+	}
+}
+
+func TestFilterByMediaType(t *testing.T) {
+	const (
+		ociIndex     = "application/vnd.oci.image.index.v1+json"
+		ociManifest  = "application/vnd.oci.image.manifest.v1+json"
+		dockerList   = "application/vnd.docker.distribution.manifest.list.v2+json"
+		dockerSingle = "application/vnd.docker.distribution.manifest.v2+json"
+	)
+	versions := []VersionInfo{
+		{ID: 1, MediaType: ociIndex},
+		{ID: 2, MediaType: dockerList},
+		{ID: 3, MediaType: ociManifest},
+		{ID: 4, MediaType: dockerSingle},
+		{ID: 5, MediaType: dockerList},
+		{ID: 6},
+	}
+
+	ids := func(vs []VersionInfo) []int64 {
+		var result []int64
+		for _, v := range vs {
+			result = append(result, v.ID)
+		}
+		return result
+	}
+
+	tests := []struct {
+		name       string
+		mediaTypes []string
+		expected   []int64
+	}{
+		{"no filter", nil, []int64{1, 2, 3, 4, 5, 6}},
+		{"OCI index", []string{ociIndex}, []int64{1}},
+		{"docker manifest list", []string{dockerList}, []int64{2, 5}},
+		{"both index formats", []string{ociIndex, dockerList}, []int64{1, 2, 5}},
+		{"no matches", []string{"application/vnd.oci.artifact.manifest.v1+json"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ids(FilterByMediaType(versions, tt.mediaTypes)))
+		})
+	}
+}
+
+func TestValidateTypeRoles(t *testing.T) {
+	assert.NoError(t, ValidateTypeRoles([]string{"index", "platform", "vex", "vuln-scan"}))
+	assert.NoError(t, ValidateTypeRoles(nil))
+
+	err := ValidateTypeRoles([]string{"sbom", "layer"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown type "layer"`)
+}
+
+// scopedReferrerGraph is a multi-arch image with referrers of both scopes: a
+// buildx attestation and a cosign signature of the index, and a cosign
+// signature of the amd64 platform
+func scopedReferrerGraph() []VersionInfo {
+	return []VersionInfo{
+		{ID: 1, Digest: "sha256:index0000001", Types: []string{"index"}, Tags: []string{"v1"},
+			OutgoingRefs: []string{"sha256:amd64000001", "sha256:arm64000001", "sha256:attest00001", "sha256:indexsig001"}},
+		{ID: 2, Digest: "sha256:amd64000001", Types: []string{"linux/amd64"},
+			IncomingRefs: []string{"sha256:index0000001"}, OutgoingRefs: []string{"sha256:amd64sig001"}},
+		{ID: 3, Digest: "sha256:arm64000001", Types: []string{"linux/arm64"}, IncomingRefs: []string{"sha256:index0000001"}},
+		{ID: 4, Digest: "sha256:attest00001", Types: []string{"sbom", "provenance"}, IncomingRefs: []string{"sha256:index0000001"}},
+		{ID: 5, Digest: "sha256:indexsig001", Types: []string{"signature"}, IncomingRefs: []string{"sha256:index0000001"}},
+		{ID: 6, Digest: "sha256:amd64sig001", Types: []string{"signature"}, IncomingRefs: []string{"sha256:amd64000001"}},
+	}
+}
+
+func TestReferrerScope(t *testing.T) {
+	t.Parallel()
+	versions := scopedReferrerGraph()
+	allVersions := ToMap(versions)
+
+	scopes := make(map[int64]string)
+	for _, v := range versions {
+		scopes[v.ID] = ReferrerScope(v, allVersions)
+	}
+	assert.Equal(t, map[int64]string{
+		1: "", 2: "", 3: "",
+		4: ReferrerScopeIndex, 5: ReferrerScopeIndex,
+		6: ReferrerScopePlatform,
+	}, scopes)
+
+	orphan := VersionInfo{Digest: "sha256:orphan", Types: []string{"signature"}}
+	assert.Empty(t, ReferrerScope(orphan, allVersions))
+}
+
+func TestFilterByReferrerScope(t *testing.T) {
+	t.Parallel()
+	versions := scopedReferrerGraph()
+	allVersions := ToMap(versions)
+
+	ids := func(versions []VersionInfo) []int64 {
+		var result []int64
+		for _, v := range versions {
+			result = append(result, v.ID)
+		}
+		return result
+	}
+	render := func(versions []VersionInfo) string {
+		var buf bytes.Buffer
+		FormatTreeWithOptions(&buf, versions, ToMap(versions), FormatOptions{})
+		return buf.String()
+	}
+
+	indexOnly := FilterByReferrerScope(versions, allVersions, ReferrerScopeIndex)
+	assert.Equal(t, []int64{1, 2, 3, 4, 5}, ids(indexOnly))
+	assert.Empty(t, indexOnly[1].OutgoingRefs, "the platform signature is no child of amd64 anymore")
+	tree := render(indexOnly)
+	assert.Contains(t, tree, "attest00001")
+	assert.Contains(t, tree, "indexsig001")
+	assert.NotContains(t, tree, "(not found)")
+	assert.Contains(t, tree, "Total: 5 versions in 1 graph")
+
+	platformOnly := FilterByReferrerScope(versions, allVersions, ReferrerScopePlatform)
+	assert.Equal(t, []int64{1, 2, 3, 6}, ids(platformOnly))
+	assert.Equal(t, []string{"sha256:amd64000001", "sha256:arm64000001"}, platformOnly[0].OutgoingRefs)
+	tree = render(platformOnly)
+	assert.NotContains(t, tree, "attest00001")
+	assert.NotContains(t, tree, "indexsig001")
+	assert.NotContains(t, tree, "(not found)")
+	assert.Contains(t, tree, "Total: 4 versions in 1 graph")
+}
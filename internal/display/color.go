@@ -19,6 +19,7 @@ var (
 	colorAttestation = color.New(color.FgYellow)
 	colorSignature   = color.New(color.FgMagenta)
 	colorVulnScan    = color.New(color.FgMagenta)
+	colorVEX         = color.New(color.FgHiMagenta)
 
 	// Tag colors
 	colorTag      = color.New(color.FgGreen, color.Bold)
@@ -39,6 +40,9 @@ var (
 
 	// Shared indicator color (magenta for visibility)
 	colorShared = color.New(color.FgMagenta, color.Bold)
+
+	// Highlight marker color (stands out from all type colors)
+	colorHighlight = color.New(color.FgRed, color.Bold)
 )
 
 // ColorVersionType applies color to version type strings based on their type.
@@ -48,8 +52,11 @@ var (
 // - attestations (sbom, provenance): yellow
 // - signatures: magenta
 // - vuln-scan: magenta
+// - vex: bright magenta
+//
+// Surrounding whitespace (from column padding) is ignored when matching.
 func ColorVersionType(versionType string) string {
-	lower := strings.ToLower(versionType)
+	lower := strings.ToLower(strings.TrimSpace(versionType))
 
 	switch {
 	case lower == "index":
@@ -66,6 +73,8 @@ func ColorVersionType(versionType string) string {
 		return colorSignature.Sprint(versionType)
 	case lower == "vuln-scan":
 		return colorVulnScan.Sprint(versionType)
+	case lower == "vex":
+		return colorVEX.Sprint(versionType)
 	default:
 		return versionType
 	}
@@ -133,3 +142,8 @@ func ColorCount(n int) string {
 func ColorShared(msg string) string {
 	return colorShared.Sprint(msg)
 }
+
+// ColorHighlight applies red bold styling to markers of highlighted versions.
+func ColorHighlight(msg string) string {
+	return colorHighlight.Sprint(msg)
+}
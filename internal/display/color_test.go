@@ -73,6 +73,16 @@ func TestColorVersionType(t *testing.T) {
 			versionType: "vuln-scan",
 			expected:    "vuln-scan",
 		},
+		{
+			name:        "vex type",
+			versionType: "vex",
+			expected:    "vex",
+		},
+		{
+			name:        "padded type keeps padding",
+			versionType: "vex       ",
+			expected:    "vex       ",
+		},
 	}
 
 	for _, tt := range tests {
@@ -217,6 +227,11 @@ func TestColorDryRun(t *testing.T) {
 	assert.Equal(t, "DRY RUN: No changes made", result)
 }
 
+func TestColorHighlight(t *testing.T) {
+	result := ColorHighlight("◀── HERE")
+	assert.Equal(t, "◀── HERE", result)
+}
+
 func TestColorCount(t *testing.T) {
 	result := ColorCount(42)
 	assert.Equal(t, "42", result)
@@ -0,0 +1,47 @@
+package display
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// SchemaVersion is the version of the JSON output schema. It is bumped when
+// fields are removed, renamed, or change their type; new fields do not bump it.
+const SchemaVersion = 1
+
+// envelope wraps a JSON array with the schema version, as written with --envelope
+type envelope struct {
+	SchemaVersion int             `json:"schema_version"`
+	Items         json.RawMessage `json:"items"`
+}
+
+// WithEnvelope returns a context in which OutputJSON wraps JSON arrays in an
+// object carrying the schema version
+func WithEnvelope(ctx context.Context) context.Context {
+	return context.WithValue(ctx, envelopeKey, true)
+}
+
+// EnvelopeFromContext reports whether JSON arrays are wrapped in an envelope
+func EnvelopeFromContext(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	enabled, _ := ctx.Value(envelopeKey).(bool)
+	return enabled
+}
+
+// wrapEnvelope returns {"schema_version": N, "items": [...]} if data is a JSON
+// array, and data unchanged otherwise. JSON objects written by commands carry
+// their own top-level schema_version.
+func wrapEnvelope(data interface{}) (interface{}, error) {
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	if !bytes.HasPrefix(jsonData, []byte("[")) {
+		return data, nil
+	}
+	return envelope{SchemaVersion: SchemaVersion, Items: jsonData}, nil
+}
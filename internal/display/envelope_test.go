@@ -0,0 +1,50 @@
+package display
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutputJSON_Envelope(t *testing.T) {
+	t.Parallel()
+	items := []map[string]interface{}{{"id": 1}, {"id": 2}}
+	compact := WithJSONStyle(context.Background(), JSONStyleCompact)
+
+	var bare bytes.Buffer
+	require.NoError(t, OutputJSON(compact, &bare, items))
+	assert.Equal(t, `[{"id":1},{"id":2}]`+"\n", bare.String(), "arrays stay bare without --envelope")
+
+	var enveloped bytes.Buffer
+	require.NoError(t, OutputJSON(WithEnvelope(compact), &enveloped, items))
+	assert.Equal(t, `{"schema_version":1,"items":[{"id":1},{"id":2}]}`+"\n", enveloped.String())
+}
+
+func TestOutputJSON_EnvelopeKeepsObjects(t *testing.T) {
+	t.Parallel()
+	ctx := WithEnvelope(WithJSONStyle(context.Background(), JSONStyleCompact))
+
+	var buf bytes.Buffer
+	require.NoError(t, OutputJSON(ctx, &buf, map[string]interface{}{"schema_version": SchemaVersion, "package": "pkg"}))
+	assert.Equal(t, `{"package":"pkg","schema_version":1}`+"\n", buf.String())
+}
+
+func TestOutputJSON_EnvelopeWithQuery(t *testing.T) {
+	t.Parallel()
+	q, err := ParseQuery(".items[].id")
+	require.NoError(t, err)
+	ctx := WithQuery(WithEnvelope(context.Background()), q)
+
+	var buf bytes.Buffer
+	require.NoError(t, OutputJSON(ctx, &buf, []map[string]interface{}{{"id": 1}, {"id": 2}}))
+	assert.Equal(t, "1\n2\n", buf.String(), "queries see the envelope")
+}
+
+func TestEnvelopeFromContext(t *testing.T) {
+	t.Parallel()
+	assert.False(t, EnvelopeFromContext(context.Background()))
+	assert.True(t, EnvelopeFromContext(WithEnvelope(context.Background())))
+}
@@ -0,0 +1,133 @@
+package display
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// jsonFieldNames returns the JSON keys of the elements of a slice of structs,
+// in declaration order. Fields without a json tag use the Go field name, as
+// encoding/json does.
+func jsonFieldNames(data interface{}) ([]string, error) {
+	t := reflect.TypeOf(data)
+	if t == nil || t.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("--fields requires a JSON array of objects")
+	}
+	elem := t.Elem()
+	for elem.Kind() == reflect.Pointer {
+		elem = elem.Elem()
+	}
+	if elem.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("--fields requires a JSON array of objects")
+	}
+
+	var names []string
+	for i := 0; i < elem.NumField(); i++ {
+		field := elem.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := field.Name
+		if tag, ok := field.Tag.Lookup("json"); ok {
+			tagName, _, _ := strings.Cut(tag, ",")
+			if tagName == "-" {
+				continue
+			}
+			if tagName != "" {
+				name = tagName
+			}
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// normalizeFieldName makes field names comparable regardless of case and
+// underscores, so that created_at matches both created_at and CreatedAt
+func normalizeFieldName(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, "_", ""))
+}
+
+// resolveFields maps the requested field names to the JSON keys of data.
+// Unknown names are an error listing the valid ones.
+func resolveFields(data interface{}, fields []string) ([]string, error) {
+	names, err := jsonFieldNames(data)
+	if err != nil {
+		return nil, err
+	}
+	byNormalized := make(map[string]string, len(names))
+	for _, name := range names {
+		byNormalized[normalizeFieldName(name)] = name
+	}
+
+	keys := make([]string, 0, len(fields))
+	for _, field := range fields {
+		key, ok := byNormalized[normalizeFieldName(strings.TrimSpace(field))]
+		if !ok {
+			valid := make([]string, len(names))
+			for i, name := range names {
+				valid[i] = strings.ToLower(name)
+			}
+			sort.Strings(valid)
+			return nil, fmt.Errorf("unknown field %q (valid: %s)", field, strings.Join(valid, ", "))
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// ValidateFields checks the field names given to --fields against the
+// elements of data, a slice of structs. data may be empty; only its type is used.
+func ValidateFields(data interface{}, fields []string) error {
+	_, err := resolveFields(data, fields)
+	return err
+}
+
+// ProjectFields reduces each object in data, a slice of structs, to the
+// requested fields. The result marshals to a JSON array of objects holding only
+// those keys.
+func ProjectFields(data interface{}, fields []string) ([]map[string]json.RawMessage, error) {
+	keys, err := resolveFields(data, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	var objects []map[string]json.RawMessage
+	if err := json.Unmarshal(jsonData, &objects); err != nil {
+		return nil, fmt.Errorf("failed to project fields: %w", err)
+	}
+
+	projected := make([]map[string]json.RawMessage, 0, len(objects))
+	for _, object := range objects {
+		p := make(map[string]json.RawMessage, len(keys))
+		for _, key := range keys {
+			if value, ok := object[key]; ok {
+				p[key] = value
+			}
+		}
+		projected = append(projected, p)
+	}
+	return projected, nil
+}
+
+// OutputJSONFields writes data like OutputJSON, projected to the given fields.
+// Without fields, data is written unchanged.
+func OutputJSONFields(ctx context.Context, w io.Writer, data interface{}, fields []string) error {
+	if len(fields) == 0 {
+		return OutputJSON(ctx, w, data)
+	}
+	projected, err := ProjectFields(data, fields)
+	if err != nil {
+		return err
+	}
+	return OutputJSON(ctx, w, projected)
+}
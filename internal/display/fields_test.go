@@ -0,0 +1,93 @@
+package display
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type taggedItem struct {
+	ID        int64    `json:"id"`
+	Tags      []string `json:"tags"`
+	CreatedAt string   `json:"created_at"`
+	Note      string   `json:"note,omitempty"`
+	Hidden    string   `json:"-"`
+}
+
+type untaggedItem struct {
+	ID        int64
+	CreatedAt string
+}
+
+func TestProjectFields(t *testing.T) {
+	t.Parallel()
+
+	t.Run("keeps only requested fields", func(t *testing.T) {
+		t.Parallel()
+		items := []taggedItem{{ID: 1, Tags: []string{"v1"}, CreatedAt: "2025-01-01"}, {ID: 2, Note: "x"}}
+		projected, err := ProjectFields(items, []string{"id", "tags"})
+		require.NoError(t, err)
+
+		data, err := json.Marshal(projected)
+		require.NoError(t, err)
+		assert.JSONEq(t, `[{"id": 1, "tags": ["v1"]}, {"id": 2, "tags": null}]`, string(data))
+	})
+
+	t.Run("omitted values stay omitted", func(t *testing.T) {
+		t.Parallel()
+		projected, err := ProjectFields([]taggedItem{{ID: 1}, {ID: 2, Note: "x"}}, []string{"note"})
+		require.NoError(t, err)
+		assert.Empty(t, projected[0])
+		assert.Equal(t, json.RawMessage(`"x"`), projected[1]["note"])
+	})
+
+	t.Run("matches Go field names regardless of case and underscores", func(t *testing.T) {
+		t.Parallel()
+		projected, err := ProjectFields([]untaggedItem{{ID: 7, CreatedAt: "2025-01-01"}}, []string{"id", "created_at"})
+		require.NoError(t, err)
+
+		data, err := json.Marshal(projected)
+		require.NoError(t, err)
+		assert.JSONEq(t, `[{"ID": 7, "CreatedAt": "2025-01-01"}]`, string(data))
+	})
+
+	t.Run("empty slice", func(t *testing.T) {
+		t.Parallel()
+		projected, err := ProjectFields([]taggedItem{}, []string{"id"})
+		require.NoError(t, err)
+		assert.Empty(t, projected)
+	})
+}
+
+func TestValidateFields(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, ValidateFields([]taggedItem(nil), []string{"id", " tags", "createdat"}))
+
+	err := ValidateFields([]taggedItem(nil), []string{"id", "name"})
+	assert.EqualError(t, err, `unknown field "name" (valid: created_at, id, note, tags)`)
+
+	err = ValidateFields([]taggedItem(nil), []string{"hidden"})
+	assert.Error(t, err, "fields excluded from JSON cannot be selected")
+
+	err = ValidateFields(taggedItem{}, []string{"id"})
+	assert.EqualError(t, err, "--fields requires a JSON array of objects")
+}
+
+func TestOutputJSONFields(t *testing.T) {
+	t.Parallel()
+	ctx := WithJSONStyle(context.Background(), JSONStyleCompact)
+	items := []taggedItem{{ID: 1, Tags: []string{"v1"}}}
+
+	var buf bytes.Buffer
+	require.NoError(t, OutputJSONFields(ctx, &buf, items, []string{"id"}))
+	assert.Equal(t, "[{\"id\":1}]\n", buf.String())
+
+	buf.Reset()
+	require.NoError(t, OutputJSONFields(ctx, &buf, items, nil))
+	assert.Equal(t, "[{\"id\":1,\"tags\":[\"v1\"],\"created_at\":\"\"}]\n", buf.String())
+}
@@ -1,12 +1,58 @@
 package display
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"os"
 	"strings"
+
+	"github.com/mattn/go-isatty"
+)
+
+// JSONStyle controls how OutputJSON formats its output.
+type JSONStyle int
+
+const (
+	// JSONStyleAuto pretty-prints for terminals and emits compact JSON when
+	// writing to a file or pipe.
+	JSONStyleAuto JSONStyle = iota
+	// JSONStylePretty always indents JSON output.
+	JSONStylePretty
+	// JSONStyleCompact always emits single-line JSON output.
+	JSONStyleCompact
+)
+
+// contextKey is a private type for context keys
+type contextKey int
+
+const (
+	jsonStyleKey contextKey = iota
+	stepSummaryKey
+	githubOutputKey
+	queryKey
+	envelopeKey
 )
 
+// WithJSONStyle returns a context carrying the given JSON output style
+func WithJSONStyle(ctx context.Context, style JSONStyle) context.Context {
+	return context.WithValue(ctx, jsonStyleKey, style)
+}
+
+// JSONStyleFromContext returns the JSON output style from the context,
+// defaulting to JSONStyleAuto.
+func JSONStyleFromContext(ctx context.Context) JSONStyle {
+	if ctx == nil {
+		return JSONStyleAuto
+	}
+	style, ok := ctx.Value(jsonStyleKey).(JSONStyle)
+	if !ok {
+		return JSONStyleAuto
+	}
+	return style
+}
+
 // FormatTags formats a list of tags into a bracketed string representation.
 // Empty or nil slices return "[]".
 func FormatTags(tags []string) string {
@@ -24,24 +70,72 @@ func FormatTags(tags []string) string {
 	return result
 }
 
+// DefaultDigestLength is the number of hex characters ShortDigest keeps
+const DefaultDigestLength = 12
+
+// FullDigestLength makes TruncateDigest keep the whole digest
+const FullDigestLength = -1
+
 // ShortDigest returns a shortened version of a digest string.
 // It removes the "sha256:" prefix and returns the first 12 characters.
 func ShortDigest(digest string) string {
-	// Remove sha256: prefix and take first 12 characters
+	return TruncateDigest(digest, DefaultDigestLength)
+}
+
+// TruncateDigest removes the "sha256:" prefix and returns the first n characters.
+// A length of zero or less (see FullDigestLength) keeps the whole digest.
+func TruncateDigest(digest string, n int) string {
 	digest = strings.TrimPrefix(digest, "sha256:")
-	if len(digest) > 12 {
-		return digest[:12]
+	if n > 0 && len(digest) > n {
+		return digest[:n]
 	}
 	return digest
 }
 
-// OutputJSON marshals data to indented JSON and writes it to the provided writer.
+// OutputJSON marshals data to JSON and writes it to the provided writer.
 // This is a common helper used across multiple commands for consistent JSON output.
-func OutputJSON(w io.Writer, data interface{}) error {
-	jsonData, err := json.MarshalIndent(data, "", "  ")
+// The style (pretty or compact) is taken from the context; see WithJSONStyle.
+// Arrays are wrapped with the schema version if enabled (see WithEnvelope).
+// If the context carries a query (see WithQuery), its results are written instead.
+func OutputJSON(ctx context.Context, w io.Writer, data interface{}) error {
+	if EnvelopeFromContext(ctx) {
+		wrapped, err := wrapEnvelope(data)
+		if err != nil {
+			return err
+		}
+		data = wrapped
+	}
+	if q := QueryFromContext(ctx); q != nil {
+		return outputQuery(ctx, w, q, data)
+	}
+
+	var jsonData []byte
+	var err error
+	if useCompactJSON(JSONStyleFromContext(ctx), w) {
+		jsonData, err = json.Marshal(data)
+	} else {
+		jsonData, err = json.MarshalIndent(data, "", "  ")
+	}
 	if err != nil {
 		return fmt.Errorf("failed to marshal JSON: %w", err)
 	}
 	fmt.Fprintln(w, string(jsonData))
 	return nil
 }
+
+// useCompactJSON resolves the JSON style for the given writer.
+// In auto mode, only files that are not terminals (pipes, redirects) get compact output;
+// other writers such as in-memory buffers keep the indented format.
+func useCompactJSON(style JSONStyle, w io.Writer) bool {
+	switch style {
+	case JSONStyleCompact:
+		return true
+	case JSONStylePretty:
+		return false
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	return !isatty.IsTerminal(f.Fd()) && !isatty.IsCygwinTerminal(f.Fd())
+}
@@ -2,6 +2,10 @@ package display
 
 import (
 	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -80,6 +84,32 @@ func TestShortDigest(t *testing.T) {
 	}
 }
 
+func TestTruncateDigest(t *testing.T) {
+	t.Parallel()
+	digest := "sha256:abcdef1234567890abcdef1234567890abcdef1234567890abcdef1234567890"
+
+	tests := []struct {
+		name     string
+		length   int
+		expected string
+	}{
+		{"one character", 1, "a"},
+		{"default length", DefaultDigestLength, "abcdef123456"},
+		{"longer than default", 19, "abcdef1234567890abc"},
+		{"exact length", 64, "abcdef1234567890abcdef1234567890abcdef1234567890abcdef1234567890"},
+		{"beyond length", 100, "abcdef1234567890abcdef1234567890abcdef1234567890abcdef1234567890"},
+		{"zero keeps full digest", 0, "abcdef1234567890abcdef1234567890abcdef1234567890abcdef1234567890"},
+		{"full", FullDigestLength, "abcdef1234567890abcdef1234567890abcdef1234567890abcdef1234567890"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.expected, TruncateDigest(digest, tt.length))
+		})
+	}
+}
+
 func TestOutputJSON(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -119,7 +149,7 @@ func TestOutputJSON(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			var buf bytes.Buffer
-			err := OutputJSON(&buf, tt.data)
+			err := OutputJSON(context.Background(), &buf, tt.data)
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -135,7 +165,48 @@ func TestOutputJSON(t *testing.T) {
 func TestOutputJSONWithInvalidData(t *testing.T) {
 	var buf bytes.Buffer
 	// Functions cannot be marshaled to JSON
-	err := OutputJSON(&buf, func() {})
+	err := OutputJSON(context.Background(), &buf, func() {})
 	assert.Error(t, err)
 	assert.ErrorContains(t, err, "failed to marshal JSON")
 }
+
+func TestOutputJSON_Compact(t *testing.T) {
+	ctx := WithJSONStyle(context.Background(), JSONStyleCompact)
+	data := map[string][]string{"tags": {"v1.0.0", "latest"}}
+
+	var buf bytes.Buffer
+	err := OutputJSON(ctx, &buf, data)
+	require.NoError(t, err)
+
+	output := strings.TrimSuffix(buf.String(), "\n")
+	assert.NotContains(t, output, "\n", "compact output should be a single line")
+	assert.Equal(t, `{"tags":["v1.0.0","latest"]}`, output)
+}
+
+func TestOutputJSON_Pretty(t *testing.T) {
+	ctx := WithJSONStyle(context.Background(), JSONStylePretty)
+	data := map[string]string{"key": "value"}
+
+	var buf bytes.Buffer
+	err := OutputJSON(ctx, &buf, data)
+	require.NoError(t, err)
+
+	assert.Equal(t, "{\n  \"key\": \"value\"\n}\n", buf.String())
+}
+
+func TestOutputJSON_AutoCompactForNonTerminalFile(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "out.json"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	err = OutputJSON(context.Background(), f, map[string]string{"key": "value"})
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(f.Name())
+	require.NoError(t, err)
+	assert.Equal(t, "{\"key\":\"value\"}\n", string(content))
+}
+
+func TestJSONStyleFromContext_Default(t *testing.T) {
+	assert.Equal(t, JSONStyleAuto, JSONStyleFromContext(context.Background()))
+}
@@ -0,0 +1,64 @@
+package display
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// githubOutputDelimiter ends multi-line values in the GITHUB_OUTPUT file
+const githubOutputDelimiter = "ghcrctl_EOF"
+
+// WithGitHubOutput returns a context whose commands write step outputs to
+// path, the file GitHub Actions names in GITHUB_OUTPUT
+func WithGitHubOutput(ctx context.Context, path string) context.Context {
+	return context.WithValue(ctx, githubOutputKey, path)
+}
+
+// GitHubOutputPath returns the step output file set by WithGitHubOutput, or an
+// empty string if step outputs are disabled
+func GitHubOutputPath(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	path, _ := ctx.Value(githubOutputKey).(string)
+	return path
+}
+
+// AppendGitHubOutputs appends outputs as name=value lines, sorted by name, to
+// the step output file. Values spanning several lines use the name<<delimiter
+// form. It does nothing if step outputs are disabled.
+func AppendGitHubOutputs(ctx context.Context, outputs map[string]string) error {
+	path := GitHubOutputPath(ctx)
+	if path == "" {
+		return nil
+	}
+
+	names := make([]string, 0, len(outputs))
+	for name := range outputs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		value := outputs[name]
+		if strings.Contains(value, "\n") {
+			fmt.Fprintf(&b, "%s<<%s\n%s\n%s\n", name, githubOutputDelimiter, value, githubOutputDelimiter)
+		} else {
+			fmt.Fprintf(&b, "%s=%s\n", name, value)
+		}
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open GitHub output file: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(b.String()); err != nil {
+		return fmt.Errorf("failed to write GitHub output file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package display
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppendGitHubOutputs(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "output")
+	require.NoError(t, os.WriteFile(path, []byte("previous=step\n"), 0o644))
+	ctx := WithGitHubOutput(context.Background(), path)
+
+	require.NoError(t, AppendGitHubOutputs(ctx, map[string]string{
+		"failed_count":  "1",
+		"deleted_count": "2",
+		"tags":          "v1.0\nlatest",
+	}))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "previous=step\n"+
+		"deleted_count=2\n"+
+		"failed_count=1\n"+
+		"tags<<ghcrctl_EOF\nv1.0\nlatest\nghcrctl_EOF\n", string(content))
+}
+
+func TestAppendGitHubOutputs_Disabled(t *testing.T) {
+	t.Parallel()
+	assert.Empty(t, GitHubOutputPath(context.Background()))
+	assert.NoError(t, AppendGitHubOutputs(context.Background(), map[string]string{"deleted_count": "1"}))
+}
@@ -0,0 +1,80 @@
+package display
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// MarkdownTable writes a GitHub-flavored Markdown table. Pipes and line breaks
+// in cells are escaped so that they cannot break the table.
+func MarkdownTable(w io.Writer, headers []string, rows [][]string) error {
+	var b strings.Builder
+	writeMarkdownRow(&b, headers)
+	separators := make([]string, len(headers))
+	for i := range separators {
+		separators[i] = "---"
+	}
+	writeMarkdownRow(&b, separators)
+	for _, row := range rows {
+		writeMarkdownRow(&b, row)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writeMarkdownRow(b *strings.Builder, cells []string) {
+	b.WriteString("|")
+	for _, cell := range cells {
+		cell = strings.ReplaceAll(cell, "|", "\\|")
+		cell = strings.ReplaceAll(cell, "\n", " ")
+		b.WriteString(" " + cell + " |")
+	}
+	b.WriteString("\n")
+}
+
+// WithStepSummary returns a context whose commands append Markdown summaries
+// to path, the file GitHub Actions names in GITHUB_STEP_SUMMARY
+func WithStepSummary(ctx context.Context, path string) context.Context {
+	return context.WithValue(ctx, stepSummaryKey, path)
+}
+
+// StepSummaryPath returns the step summary file set by WithStepSummary, or an
+// empty string if step summaries are disabled
+func StepSummaryPath(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	path, _ := ctx.Value(stepSummaryKey).(string)
+	return path
+}
+
+// AppendStepSummary appends a titled Markdown table to the step summary file.
+// It does nothing if step summaries are disabled.
+func AppendStepSummary(ctx context.Context, title string, headers []string, rows [][]string) error {
+	path := StepSummaryPath(ctx)
+	if path == "" {
+		return nil
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open step summary: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := fmt.Fprintf(f, "### %s\n\n", title); err != nil {
+		return fmt.Errorf("failed to write step summary: %w", err)
+	}
+	if len(rows) == 0 {
+		_, err = fmt.Fprintf(f, "_None._\n\n")
+	} else if err = MarkdownTable(f, headers, rows); err == nil {
+		_, err = fmt.Fprintln(f)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write step summary: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package display
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarkdownTable(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	err := MarkdownTable(&buf, []string{"Version ID", "Tags"}, [][]string{
+		{"100", "v1.0, latest"},
+		{"101", "a|b"},
+		{"102", "line\nbreak"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "| Version ID | Tags |\n"+
+		"| --- | --- |\n"+
+		"| 100 | v1.0, latest |\n"+
+		"| 101 | a\\|b |\n"+
+		"| 102 | line break |\n", buf.String())
+}
+
+func TestAppendStepSummary(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "summary.md")
+	require.NoError(t, os.WriteFile(path, []byte("# Cleanup\n\n"), 0o644))
+	ctx := WithStepSummary(context.Background(), path)
+
+	require.NoError(t, AppendStepSummary(ctx, "Versions of myimage", []string{"ID"}, [][]string{{"1"}}))
+	require.NoError(t, AppendStepSummary(ctx, "Versions of other", []string{"ID"}, nil))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "# Cleanup\n\n"+
+		"### Versions of myimage\n\n| ID |\n| --- |\n| 1 |\n\n"+
+		"### Versions of other\n\n_None._\n\n", string(content))
+}
+
+func TestAppendStepSummary_Disabled(t *testing.T) {
+	t.Parallel()
+	assert.Empty(t, StepSummaryPath(context.Background()))
+	assert.NoError(t, AppendStepSummary(context.Background(), "ignored", []string{"ID"}, [][]string{{"1"}}))
+}
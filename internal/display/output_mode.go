@@ -0,0 +1,57 @@
+package display
+
+import (
+	"fmt"
+	"strings"
+)
+
+// OutputMode is an output format selected with -o/--output.
+type OutputMode string
+
+const (
+	// OutputModeJSON emits machine-readable JSON.
+	OutputModeJSON OutputMode = "json"
+	// OutputModeTable emits a flat human-readable table.
+	OutputModeTable OutputMode = "table"
+	// OutputModeTree emits a hierarchical tree view.
+	OutputModeTree OutputMode = "tree"
+	// OutputModeGitHubActions writes results as step outputs to the file named
+	// by GITHUB_OUTPUT, in addition to the human-readable output.
+	OutputModeGitHubActions OutputMode = "github-actions"
+)
+
+// outputModeAliases maps alternative spellings to their canonical mode.
+var outputModeAliases = map[string]OutputMode{
+	"flat": OutputModeTable,
+}
+
+// ParseOutputMode validates s against the allowed modes and returns the
+// matching mode. An empty string returns an empty mode, meaning the command
+// should use its default. Unsupported values produce an error listing the
+// allowed modes.
+func ParseOutputMode(s string, allowed ...OutputMode) (OutputMode, error) {
+	if s == "" {
+		return "", nil
+	}
+
+	mode := OutputMode(s)
+	if alias, ok := outputModeAliases[s]; ok {
+		mode = alias
+	}
+
+	for _, a := range allowed {
+		if mode == a {
+			return mode, nil
+		}
+	}
+	return "", fmt.Errorf("invalid output format %q. Supported formats: %s", s, JoinOutputModes(allowed))
+}
+
+// JoinOutputModes returns the modes as a comma-separated list.
+func JoinOutputModes(modes []OutputMode) string {
+	names := make([]string, len(modes))
+	for i, m := range modes {
+		names[i] = string(m)
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,52 @@
+package display
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOutputMode(t *testing.T) {
+	t.Parallel()
+
+	tableOnly := []OutputMode{OutputModeJSON, OutputModeTable}
+	withTree := []OutputMode{OutputModeJSON, OutputModeTable, OutputModeTree}
+
+	tests := []struct {
+		name    string
+		input   string
+		allowed []OutputMode
+		want    OutputMode
+		wantErr string
+	}{
+		{"empty uses default", "", tableOnly, "", ""},
+		{"json", "json", tableOnly, OutputModeJSON, ""},
+		{"table", "table", tableOnly, OutputModeTable, ""},
+		{"tree allowed", "tree", withTree, OutputModeTree, ""},
+		{"flat is an alias for table", "flat", withTree, OutputModeTable, ""},
+		{"tree not allowed", "tree", tableOnly, "", `invalid output format "tree". Supported formats: json, table`},
+		{"unknown format", "yaml", withTree, "", `invalid output format "yaml". Supported formats: json, table, tree`},
+		{"case sensitive", "JSON", tableOnly, "", `invalid output format "JSON". Supported formats: json, table`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := ParseOutputMode(tt.input, tt.allowed...)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Equal(t, tt.wantErr, err.Error())
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestJoinOutputModes(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "json, table, tree", JoinOutputModes([]OutputMode{OutputModeJSON, OutputModeTable, OutputModeTree}))
+	assert.Equal(t, "", JoinOutputModes(nil))
+}
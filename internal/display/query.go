@@ -0,0 +1,354 @@
+package display
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Query is a small path expression applied to JSON output with --query. It is
+// deliberately far simpler than jq:
+//
+//	.field        the value of a field (names match like --fields)
+//	[]            each element of an array
+//	[N]           the element at index N, negative counts from the end
+//	[?path==v]    each element of an array whose path equals v; for an array
+//	              value, one of its elements must equal v. != negates.
+//
+// Steps are chained, e.g. [].digest or [?tags==latest].id. A query evaluates to
+// a stream of values, like jq.
+type Query struct {
+	steps []queryStep
+}
+
+// queryStep is one step of a Query
+type queryStep struct {
+	kind    queryStepKind
+	field   string   // queryField
+	index   int      // queryIndex
+	path    []string // queryFilter
+	value   string   // queryFilter
+	negated bool     // queryFilter
+}
+
+type queryStepKind int
+
+const (
+	queryField queryStepKind = iota
+	queryIterate
+	queryIndex
+	queryFilter
+)
+
+// ParseQuery parses a query expression. An empty expression or "." returns
+// the value unchanged.
+func ParseQuery(expr string) (*Query, error) {
+	q := &Query{}
+	rest := strings.TrimSpace(expr)
+	if rest == "." {
+		return q, nil
+	}
+	for first := true; rest != ""; first = false {
+		switch {
+		case rest[0] == '.':
+			name, remaining := cutIdent(rest[1:])
+			if name == "" {
+				return nil, fmt.Errorf("expected a field name after '.' in %q", expr)
+			}
+			q.steps = append(q.steps, queryStep{kind: queryField, field: name})
+			rest = remaining
+		case rest[0] == '[':
+			end := matchingBracket(rest)
+			if end < 0 {
+				return nil, fmt.Errorf("missing ']' in %q", expr)
+			}
+			step, err := parseBracket(rest[1:end])
+			if err != nil {
+				return nil, fmt.Errorf("%w in %q", err, expr)
+			}
+			q.steps = append(q.steps, step)
+			rest = rest[end+1:]
+		case first:
+			// A leading field may omit the dot, as in versions[].digest
+			name, remaining := cutIdent(rest)
+			if name == "" {
+				return nil, fmt.Errorf("unexpected %q in %q", rest[0], expr)
+			}
+			q.steps = append(q.steps, queryStep{kind: queryField, field: name})
+			rest = remaining
+		default:
+			return nil, fmt.Errorf("unexpected %q in %q", rest[0], expr)
+		}
+	}
+	return q, nil
+}
+
+// cutIdent splits a leading field name off s
+func cutIdent(s string) (string, string) {
+	i := 0
+	for i < len(s) && isIdentChar(s[i]) {
+		i++
+	}
+	return s[:i], s[i:]
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || c == '-' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// matchingBracket returns the index of the ']' that closes the '[' at the start
+// of s, or -1 if there is none. Nested brackets and brackets in quoted filter
+// values do not close it.
+func matchingBracket(s string) int {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// parseBracket parses the contents of [...]
+func parseBracket(inner string) (queryStep, error) {
+	inner = strings.TrimSpace(inner)
+	if inner == "" {
+		return queryStep{kind: queryIterate}, nil
+	}
+	if cond, ok := strings.CutPrefix(inner, "?"); ok {
+		// Field names contain neither == nor !=, so the first of them is the
+		// operator, and the value may contain them as well
+		eq, ne := strings.Index(cond, "=="), strings.Index(cond, "!=")
+		if eq < 0 && ne < 0 {
+			return queryStep{}, fmt.Errorf("filter [%s] needs == or !=", inner)
+		}
+		op, negated := eq, false
+		if ne >= 0 && (eq < 0 || ne < eq) {
+			op, negated = ne, true
+		}
+		path, value := cond[:op], cond[op+2:]
+		fields := strings.Split(strings.TrimPrefix(strings.TrimSpace(path), "."), ".")
+		for _, field := range fields {
+			if name, rest := cutIdent(field); name == "" || rest != "" {
+				return queryStep{}, fmt.Errorf("invalid field %q in filter [%s]", field, inner)
+			}
+		}
+		return queryStep{kind: queryFilter, path: fields, value: unquote(strings.TrimSpace(value)), negated: negated}, nil
+	}
+	index, err := strconv.Atoi(inner)
+	if err != nil {
+		return queryStep{}, fmt.Errorf("invalid index [%s]", inner)
+	}
+	return queryStep{kind: queryIndex, index: index}, nil
+}
+
+// unquote removes matching single or double quotes around s
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '\'' || s[0] == '"') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// Eval applies the query to data, which is converted to its JSON form first,
+// and returns the resulting values.
+func (q *Query) Eval(data interface{}) ([]interface{}, error) {
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	var value interface{}
+	if err := json.Unmarshal(jsonData, &value); err != nil {
+		return nil, fmt.Errorf("failed to decode JSON: %w", err)
+	}
+
+	values := []interface{}{value}
+	for _, step := range q.steps {
+		var next []interface{}
+		for _, v := range values {
+			out, err := step.apply(v)
+			if err != nil {
+				return nil, err
+			}
+			next = append(next, out...)
+		}
+		values = next
+	}
+	return values, nil
+}
+
+// apply evaluates one step on a single value
+func (s queryStep) apply(v interface{}) ([]interface{}, error) {
+	// Missing values stay null, and iterating over them yields nothing
+	if v == nil {
+		if s.kind == queryIterate || s.kind == queryFilter {
+			return nil, nil
+		}
+		return []interface{}{nil}, nil
+	}
+	switch s.kind {
+	case queryField:
+		object, ok := v.(map[string]interface{})
+		if !ok {
+			if _, isArray := v.([]interface{}); isArray {
+				return nil, fmt.Errorf("cannot get field %q of an array (use [].%s)", s.field, s.field)
+			}
+			return nil, fmt.Errorf("cannot get field %q of %s", s.field, jsonKind(v))
+		}
+		return []interface{}{lookupField(object, s.field)}, nil
+	}
+
+	array, ok := v.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("cannot iterate over %s", jsonKind(v))
+	}
+	switch s.kind {
+	case queryIndex:
+		i := s.index
+		if i < 0 {
+			i += len(array)
+		}
+		if i < 0 || i >= len(array) {
+			return []interface{}{nil}, nil
+		}
+		return []interface{}{array[i]}, nil
+	case queryFilter:
+		var matched []interface{}
+		for _, element := range array {
+			if s.matches(element) != s.negated {
+				matched = append(matched, element)
+			}
+		}
+		return matched, nil
+	default:
+		return array, nil
+	}
+}
+
+// matches reports whether the filter path of element equals the filter value
+func (s queryStep) matches(element interface{}) bool {
+	v := element
+	for _, field := range s.path {
+		object, ok := v.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		v = lookupField(object, field)
+	}
+	if array, ok := v.([]interface{}); ok {
+		for _, item := range array {
+			if scalarString(item) == s.value {
+				return true
+			}
+		}
+		return false
+	}
+	return scalarString(v) == s.value
+}
+
+// lookupField returns the value of a field, matching names like --fields does:
+// regardless of case and underscores. A missing field is null.
+func lookupField(object map[string]interface{}, name string) interface{} {
+	if v, ok := object[name]; ok {
+		return v
+	}
+	for key, v := range object {
+		if normalizeFieldName(key) == normalizeFieldName(name) {
+			return v
+		}
+	}
+	return nil
+}
+
+// scalarString formats a JSON scalar for comparison with a filter value
+func scalarString(v interface{}) string {
+	switch value := v.(type) {
+	case string:
+		return value
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(value)
+	case nil:
+		return "null"
+	default:
+		return ""
+	}
+}
+
+// jsonKind names the JSON type of v for error messages
+func jsonKind(v interface{}) string {
+	switch v.(type) {
+	case map[string]interface{}:
+		return "an object"
+	case []interface{}:
+		return "an array"
+	case string:
+		return "a string"
+	case float64:
+		return "a number"
+	case bool:
+		return "a boolean"
+	default:
+		return "null"
+	}
+}
+
+// WithQuery returns a context carrying a query that OutputJSON applies
+func WithQuery(ctx context.Context, q *Query) context.Context {
+	return context.WithValue(ctx, queryKey, q)
+}
+
+// QueryFromContext returns the query from the context, or nil if none is set
+func QueryFromContext(ctx context.Context) *Query {
+	if ctx == nil {
+		return nil
+	}
+	q, _ := ctx.Value(queryKey).(*Query)
+	return q
+}
+
+// outputQuery writes each result of the query on its own line. Strings are
+// written without quotes so that they can be used in shell scripts directly;
+// other values are written as JSON in the style of the context.
+func outputQuery(ctx context.Context, w io.Writer, q *Query, data interface{}) error {
+	results, err := q.Eval(data)
+	if err != nil {
+		return fmt.Errorf("--query: %w", err)
+	}
+	compact := useCompactJSON(JSONStyleFromContext(ctx), w)
+	for _, result := range results {
+		if s, ok := result.(string); ok {
+			fmt.Fprintln(w, s)
+			continue
+		}
+		var jsonData []byte
+		if compact {
+			jsonData, err = json.Marshal(result)
+		} else {
+			jsonData, err = json.MarshalIndent(result, "", "  ")
+		}
+		if err != nil {
+			return fmt.Errorf("failed to marshal JSON: %w", err)
+		}
+		fmt.Fprintln(w, string(jsonData))
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package display
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type queryTestVersion struct {
+	ID     int64             `json:"id"`
+	Digest string            `json:"digest"`
+	Tags   []string          `json:"tags"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+func queryTestVersions() []queryTestVersion {
+	return []queryTestVersion{
+		{ID: 3, Digest: "sha256:ccc", Tags: []string{"latest", "v2"}, Labels: map[string]string{"team": "core"}},
+		{ID: 2, Digest: "sha256:bbb", Tags: []string{"v1"}},
+		{ID: 1, Digest: "sha256:aaa", Tags: []string{"x[1]", "a]b", "k==v"}},
+	}
+}
+
+func TestQuery_Eval(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		expr string
+		want []interface{}
+	}{
+		{".", []interface{}{[]interface{}{
+			map[string]interface{}{"id": 3.0, "digest": "sha256:ccc", "tags": []interface{}{"latest", "v2"}, "labels": map[string]interface{}{"team": "core"}},
+			map[string]interface{}{"id": 2.0, "digest": "sha256:bbb", "tags": []interface{}{"v1"}},
+			map[string]interface{}{"id": 1.0, "digest": "sha256:aaa", "tags": []interface{}{"x[1]", "a]b", "k==v"}},
+		}}},
+		{"[].digest", []interface{}{"sha256:ccc", "sha256:bbb", "sha256:aaa"}},
+		{"[].Digest", []interface{}{"sha256:ccc", "sha256:bbb", "sha256:aaa"}},
+		{"[].tags[]", []interface{}{"latest", "v2", "v1", "x[1]", "a]b", "k==v"}},
+		{"[0].id", []interface{}{3.0}},
+		{"[-1].digest", []interface{}{"sha256:aaa"}},
+		{"[5].digest", []interface{}{nil}},
+		{"[].labels.team", []interface{}{"core", nil, nil}},
+		{"[?tags==latest].id", []interface{}{3.0}},
+		{"[?tags=='v1'].digest", []interface{}{"sha256:bbb"}},
+		{"[?id!=3].id", []interface{}{2.0, 1.0}},
+		{"[?labels.team==core].digest", []interface{}{"sha256:ccc"}},
+		// Brackets and operators in values do not end the filter
+		{"[?tags==x[1]].id", []interface{}{1.0}},
+		{"[?tags=='a]b'].id", []interface{}{1.0}},
+		{`[?tags=="a]b"].tags[0]`, []interface{}{"x[1]"}},
+		{"[?tags==k==v].id", []interface{}{1.0}},
+		{"[?tags!='k==v'].id", []interface{}{3.0, 2.0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			q, err := ParseQuery(tt.expr)
+			require.NoError(t, err)
+			got, err := q.Eval(queryTestVersions())
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestQuery_EvalErrors(t *testing.T) {
+	t.Parallel()
+
+	q, err := ParseQuery(".digest")
+	require.NoError(t, err)
+	_, err = q.Eval(queryTestVersions())
+	assert.EqualError(t, err, `cannot get field "digest" of an array (use [].digest)`)
+
+	q, err = ParseQuery("[].digest[]")
+	require.NoError(t, err)
+	_, err = q.Eval(queryTestVersions())
+	assert.EqualError(t, err, "cannot iterate over a string")
+}
+
+func TestParseQuery_Invalid(t *testing.T) {
+	t.Parallel()
+
+	for _, expr := range []string{"[", "[x]", "[].", "[?tags]", "[?ta gs==x]", "[] digest", ".digest|length", "[?tags=='a]", "[?tags==x[1]", "[[]"} {
+		_, err := ParseQuery(expr)
+		assert.Error(t, err, expr)
+	}
+}
+
+func TestOutputJSON_WithQuery(t *testing.T) {
+	t.Parallel()
+
+	q, err := ParseQuery("[?tags==latest]")
+	require.NoError(t, err)
+	ctx := WithJSONStyle(WithQuery(context.Background(), q), JSONStyleCompact)
+
+	var buf bytes.Buffer
+	require.NoError(t, OutputJSON(ctx, &buf, queryTestVersions()))
+	assert.Equal(t, `{"digest":"sha256:ccc","id":3,"labels":{"team":"core"},"tags":["latest","v2"]}`+"\n", buf.String())
+
+	// Strings are written raw, one per line
+	q, err = ParseQuery("[].digest")
+	require.NoError(t, err)
+	buf.Reset()
+	require.NoError(t, OutputJSON(WithQuery(context.Background(), q), &buf, queryTestVersions()))
+	assert.Equal(t, "sha256:ccc\nsha256:bbb\nsha256:aaa\n", buf.String())
+}
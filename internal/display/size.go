@@ -0,0 +1,40 @@
+package display
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// HumanSize formats a size in bytes with binary units, e.g. 512 B, 1.5 KiB or
+// 2.0 GiB
+func HumanSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit && bytes > -unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	value := float64(bytes)
+	for _, suffix := range []string{"KiB", "MiB", "GiB", "TiB"} {
+		value /= unit
+		if (value < unit && value > -unit) || suffix == "TiB" {
+			return fmt.Sprintf("%.1f %s", value, suffix)
+		}
+	}
+	return "" // unreachable
+}
+
+// Thousands formats n with comma thousands separators, e.g. 1,234,567
+func Thousands(n int64) string {
+	digits := strconv.FormatInt(n, 10)
+	sign := ""
+	if n < 0 {
+		sign, digits = "-", digits[1:]
+	}
+	var result []byte
+	for i := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			result = append(result, ',')
+		}
+		result = append(result, digits[i])
+	}
+	return sign + string(result)
+}
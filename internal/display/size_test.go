@@ -0,0 +1,52 @@
+package display
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHumanSize(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		bytes int64
+		want  string
+	}{
+		{0, "0 B"},
+		{512, "512 B"},
+		{1023, "1023 B"},
+		{1024, "1.0 KiB"},
+		{1536, "1.5 KiB"},
+		{1024 * 1024, "1.0 MiB"},
+		{50 * 1024 * 1024, "50.0 MiB"},
+		{1024 * 1024 * 1024, "1.0 GiB"},
+		{int64(2.5 * 1024 * 1024 * 1024), "2.5 GiB"},
+		{3 * 1024 * 1024 * 1024 * 1024, "3.0 TiB"},
+		{5000 * 1024 * 1024 * 1024 * 1024, "5000.0 TiB"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, HumanSize(tt.bytes), "HumanSize(%d)", tt.bytes)
+	}
+}
+
+func TestThousands(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{0, "0"},
+		{999, "999"},
+		{1000, "1,000"},
+		{123456, "123,456"},
+		{1234567, "1,234,567"},
+		{-1234567, "-1,234,567"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Thousands(tt.n), "Thousands(%d)", tt.n)
+	}
+}
@@ -0,0 +1,56 @@
+package display
+
+import (
+	"fmt"
+	"time"
+)
+
+// HumanizeTime describes t relative to now, e.g. "3 days ago" or "just now".
+// Times in the future are described as "in 2 hours".
+func HumanizeTime(t time.Time) string {
+	return humanizeDuration(time.Since(t))
+}
+
+// humanizeDuration describes how long ago something happened. Each unit is
+// used until the next one fits twice, so 36 hours is "36 hours ago" rather
+// than "1 day ago".
+func humanizeDuration(d time.Duration) string {
+	future := d < 0
+	if future {
+		d = -d
+	}
+	if d < time.Minute {
+		return "just now"
+	}
+
+	const (
+		day   = 24 * time.Hour
+		week  = 7 * day
+		month = 30 * day
+		year  = 365 * day
+	)
+	var n int64
+	var unit string
+	switch {
+	case d < 2*time.Hour:
+		n, unit = int64(d/time.Minute), "minute"
+	case d < 2*day:
+		n, unit = int64(d/time.Hour), "hour"
+	case d < 2*week:
+		n, unit = int64(d/day), "day"
+	case d < 2*month:
+		n, unit = int64(d/week), "week"
+	case d < 2*year:
+		n, unit = int64(d/month), "month"
+	default:
+		n, unit = int64(d/year), "year"
+	}
+	if n != 1 {
+		unit += "s"
+	}
+
+	if future {
+		return fmt.Sprintf("in %d %s", n, unit)
+	}
+	return fmt.Sprintf("%d %s ago", n, unit)
+}
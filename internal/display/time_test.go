@@ -0,0 +1,45 @@
+package display
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHumanizeDuration(t *testing.T) {
+	t.Parallel()
+	const day = 24 * time.Hour
+	tests := []struct {
+		name string
+		d    time.Duration
+		want string
+	}{
+		{"seconds", 30 * time.Second, "just now"},
+		{"one minute", time.Minute, "1 minute ago"},
+		{"minutes", 45 * time.Minute, "45 minutes ago"},
+		{"minutes up to two hours", 90 * time.Minute, "90 minutes ago"},
+		{"hours", 5 * time.Hour, "5 hours ago"},
+		{"hours up to two days", 36 * time.Hour, "36 hours ago"},
+		{"days", 3 * day, "3 days ago"},
+		{"days up to two weeks", 13 * day, "13 days ago"},
+		{"weeks", 3 * 7 * day, "3 weeks ago"},
+		{"partial weeks round down", 20 * day, "2 weeks ago"},
+		{"months", 90 * day, "3 months ago"},
+		{"years", 800 * day, "2 years ago"},
+		{"in the future", -3 * time.Hour, "in 3 hours"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, humanizeDuration(tt.d))
+		})
+	}
+}
+
+func TestHumanizeTime(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "3 days ago", HumanizeTime(time.Now().Add(-3*24*time.Hour-time.Minute)))
+	assert.Equal(t, "just now", HumanizeTime(time.Now()))
+}
@@ -0,0 +1,67 @@
+package display
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWidth(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name  string
+		input string
+		want  int
+	}{
+		{"empty", "", 0},
+		{"ascii", "v1.0.0", 6},
+		{"ansi color codes are ignored", "\x1b[32;1mv1.0.0\x1b[0m", 6},
+		{"CJK characters are double width", "版本", 4},
+		{"hangul", "한글", 4},
+		{"fullwidth latin", "ＡＢ", 4},
+		{"emoji", "🚀", 2},
+		{"emoji with variation selector", "✅️", 2},
+		{"combining accent", "é", 1},
+		{"accented latin is single width", "café", 4},
+		{"colored mixed content", "\x1b[32m[release-版本, 🚀]\x1b[0m", 18},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, Width(tt.input))
+		})
+	}
+}
+
+func TestPadRight(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "ab   ", PadRight("ab", 5))
+	assert.Equal(t, "版本 ", PadRight("版本", 5))
+	assert.Equal(t, "\x1b[1mab\x1b[0m   ", PadRight("\x1b[1mab\x1b[0m", 5))
+	assert.Equal(t, "toolong", PadRight("toolong", 3), "wider strings are not truncated")
+}
+
+// TestPadRight_ColumnBoundaries renders a column with and without color codes
+// and checks that the next column starts at the same display position.
+func TestPadRight_ColumnBoundaries(t *testing.T) {
+	t.Parallel()
+	cells := []string{"[latest]", "[v1.0.0, stable]", "[リリース]", "[🚀-launch]", "[]"}
+
+	width := 0
+	for _, c := range cells {
+		if w := Width(c); w > width {
+			width = w
+		}
+	}
+
+	for _, c := range cells {
+		plain := PadRight(c, width) + "|next"
+		colored := PadRight("\x1b[32;1m"+c+"\x1b[0m", width) + "|next"
+
+		assert.Equal(t, width, Width(plain[:strings.Index(plain, "|")]), "plain cell %q", c)
+		assert.Equal(t, width, Width(colored[:strings.Index(colored, "|")]), "colored cell %q", c)
+		assert.Equal(t, plain, ansiEscape.ReplaceAllString(colored, ""), "color must not change the layout of %q", c)
+	}
+}
@@ -1,5 +1,5 @@
 // Package filter provides version filtering capabilities for GHCR package versions.
-// It supports filtering by tags (exact match and regex), date ranges, age,
+// It supports filtering by tags (exact match, prefix/suffix, and regex), date ranges, age,
 // version IDs, digests, and tagged/untagged status with graph-aware filtering.
 package filter
 
@@ -8,6 +8,8 @@ import (
 	"regexp"
 	"strings"
 	"time"
+	// Embed the time zone database for --timezone on systems without one, e.g. Windows
+	_ "time/tzdata"
 
 	"github.com/mkoepf/ghcrctl/internal/gh"
 )
@@ -17,6 +19,8 @@ type VersionFilter struct {
 	// Tag filtering
 	Tags       []string // Exact tag matches (OR logic)
 	TagPattern string   // Regex pattern for tag matching
+	TagPrefix  string   // Literal tag prefix; with TagSuffix, one tag must match both
+	TagSuffix  string   // Literal tag suffix
 
 	// Tagged/Untagged filtering
 	OnlyTagged   bool
@@ -34,6 +38,10 @@ type VersionFilter struct {
 	// Direct version filtering
 	VersionID int64  // Filter by exact version ID (0 means no filter)
 	Digest    string // Filter by digest (supports prefix matching)
+
+	// Version ID range (both bounds exclusive; IDs grow with every push)
+	MinVersionID int64 // Include only versions with ID greater than this (0 means no filter)
+	MaxVersionID int64 // Include only versions with ID less than this (0 means no filter)
 }
 
 // Apply applies all configured filters to the provided versions
@@ -74,9 +82,17 @@ func (f *VersionFilter) matchesVersion(ver gh.PackageVersionInfo, tagRegex *rege
 		return false
 	}
 
+	// Check minimum version ID (versions are assigned monotonically increasing IDs)
+	if f.MinVersionID != 0 && ver.ID <= f.MinVersionID {
+		return false
+	}
+	if f.MaxVersionID != 0 && ver.ID >= f.MaxVersionID {
+		return false
+	}
+
 	// Check digest filter (prefix matching for short digests)
 	// Supports both "sha256:abc123" and "abc123" (as shown in DIGEST column)
-	if f.Digest != "" && !matchesDigest(ver.Digest, f.Digest) {
+	if f.Digest != "" && !MatchesDigest(ver.Digest, f.Digest) {
 		return false
 	}
 
@@ -110,6 +126,13 @@ func (f *VersionFilter) matchesVersion(ver gh.PackageVersionInfo, tagRegex *rege
 		}
 	}
 
+	// Check literal tag prefix/suffix match
+	if f.TagPrefix != "" || f.TagSuffix != "" {
+		if !hasTagWithAffixes(ver.Tags, f.TagPrefix, f.TagSuffix) {
+			return false
+		}
+	}
+
 	// Only parse dates if we actually need to check date filters
 	needsDateCheck := !f.OlderThan.IsZero() || !f.NewerThan.IsZero()
 
@@ -140,7 +163,15 @@ func (f *VersionFilter) matchesVersion(ver gh.PackageVersionInfo, tagRegex *rege
 //   - "2006-01-02T15:04:05.999999999Z07:00" (RFC3339Nano)
 //   - "2006-01-02 15:04:05" (GitHub API format)
 //   - "2006-01-02T15:04:05" (datetime without timezone)
+//
+// Dates and times without a zone are in UTC, like the timestamps of versions.
 func ParseDate(dateStr string) (time.Time, error) {
+	return ParseDateIn(dateStr, time.UTC)
+}
+
+// ParseDateIn is like ParseDate, but interprets dates and times without a zone
+// in loc, e.g. 2025-01-01 as midnight in New York. Explicit offsets win.
+func ParseDateIn(dateStr string, loc *time.Location) (time.Time, error) {
 	if dateStr == "" {
 		return time.Time{}, fmt.Errorf("date string cannot be empty")
 	}
@@ -154,7 +185,7 @@ func ParseDate(dateStr string) (time.Time, error) {
 	}
 
 	for _, format := range formats {
-		t, err := time.Parse(format, dateStr)
+		t, err := time.ParseInLocation(format, dateStr, loc)
 		if err == nil {
 			return t, nil
 		}
@@ -177,19 +208,55 @@ func ParseDate(dateStr string) (time.Time, error) {
 //   - "1h30m" -> 1 hour 30 minutes ago
 //   - "2d12h" -> 2 days and 12 hours ago
 func ParseDateOrDuration(s string) (time.Time, error) {
+	return ParseDateOrDurationIn(s, time.UTC)
+}
+
+// ParseDateOrDurationIn is like ParseDateOrDuration, but interprets dates
+// without a zone in loc. Durations do not depend on the zone.
+func ParseDateOrDurationIn(s string, loc *time.Location) (time.Time, error) {
 	if s == "" {
 		return time.Time{}, fmt.Errorf("date/duration string cannot be empty")
 	}
 
 	// Detect date format: starts with 4 digits followed by '-'
 	if len(s) >= 5 && isDigit(s[0]) && isDigit(s[1]) && isDigit(s[2]) && isDigit(s[3]) && s[4] == '-' {
-		return ParseDate(s)
+		return ParseDateIn(s, loc)
 	}
 
 	// Parse as duration
 	return parseDuration(s)
 }
 
+// ParseAge parses an age for --min-age and --max-age and returns the cutoff
+// time (now - age). Unlike ParseDateOrDuration, it accepts only durations, so
+// that the direction of the filter cannot be mistaken.
+func ParseAge(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, fmt.Errorf("age cannot be empty")
+	}
+	if len(s) >= 5 && isDigit(s[0]) && isDigit(s[1]) && isDigit(s[2]) && isDigit(s[3]) && s[4] == '-' {
+		return time.Time{}, fmt.Errorf("%q is a date, not an age (use a duration such as 30d or 12h)", s)
+	}
+	return parseDuration(s)
+}
+
+// ParseLocation returns the time zone for --timezone: UTC for "" and "UTC",
+// the system's zone for "local", and otherwise the IANA zone of that name,
+// e.g. America/New_York.
+func ParseLocation(name string) (*time.Location, error) {
+	switch strings.ToLower(name) {
+	case "", "utc":
+		return time.UTC, nil
+	case "local":
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown time zone %q (use an IANA name such as America/New_York, UTC, or local)", name)
+	}
+	return loc, nil
+}
+
 // parseDuration parses a duration string with support for 'd' (days).
 // Returns the cutoff time (now - duration).
 func parseDuration(s string) (time.Time, error) {
@@ -271,10 +338,21 @@ func hasMatchingTagPattern(versionTags []string, pattern *regexp.Regexp) bool {
 	return false
 }
 
-// matchesDigest checks if a version digest matches the filter digest.
+// hasTagWithAffixes checks if any version tag starts with prefix and ends with
+// suffix. Empty affixes match every tag.
+func hasTagWithAffixes(versionTags []string, prefix, suffix string) bool {
+	for _, tag := range versionTags {
+		if strings.HasPrefix(tag, prefix) && strings.HasSuffix(tag, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchesDigest checks if a version digest matches the filter digest.
 // Supports both full format "sha256:abc123..." and short format "abc123..."
 // (as displayed in the DIGEST column without the sha256: prefix).
-func matchesDigest(versionDigest, filterDigest string) bool {
+func MatchesDigest(versionDigest, filterDigest string) bool {
 	// Try direct prefix match first (handles "sha256:abc123" format)
 	if strings.HasPrefix(versionDigest, filterDigest) {
 		return true
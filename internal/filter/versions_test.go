@@ -6,6 +6,7 @@ import (
 
 	"github.com/mkoepf/ghcrctl/internal/gh"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // Helper to create test versions
@@ -378,6 +379,57 @@ func TestVersionFilter_Apply_VersionID_NotFound(t *testing.T) {
 	assert.Equal(t, 0, len(result))
 }
 
+func TestVersionFilter_Apply_MinVersionID(t *testing.T) {
+	versions := []gh.PackageVersionInfo{
+		createTestVersion(12345, []string{"v1.0.0"}, "2025-01-01T00:00:00Z"),
+		createTestVersion(12346, []string{}, "2025-01-02T00:00:00Z"),
+		createTestVersion(12347, []string{"latest"}, "2025-01-03T00:00:00Z"),
+	}
+
+	filter := &VersionFilter{MinVersionID: 12345}
+	result := filter.Apply(versions)
+
+	assert.Equal(t, 2, len(result))
+	assert.Equal(t, int64(12346), result[0].ID)
+	assert.Equal(t, int64(12347), result[1].ID)
+}
+
+func TestVersionFilter_Apply_VersionIDRange(t *testing.T) {
+	versions := []gh.PackageVersionInfo{
+		createTestVersion(100, []string{"v1.0.0"}, "2025-01-01T00:00:00Z"),
+		createTestVersion(101, []string{}, "2025-01-02T00:00:00Z"),
+		createTestVersion(102, []string{"v1.1.0"}, "2025-01-03T00:00:00Z"),
+		createTestVersion(103, []string{}, "2025-01-04T00:00:00Z"),
+		createTestVersion(104, []string{"latest"}, "2025-01-05T00:00:00Z"),
+	}
+
+	ids := func(result []gh.PackageVersionInfo) []int64 {
+		var got []int64
+		for _, v := range result {
+			got = append(got, v.ID)
+		}
+		return got
+	}
+
+	tests := []struct {
+		name   string
+		filter VersionFilter
+		want   []int64
+	}{
+		{"both bounds are exclusive", VersionFilter{MinVersionID: 100, MaxVersionID: 104}, []int64{101, 102, 103}},
+		{"upper bound only", VersionFilter{MaxVersionID: 102}, []int64{100, 101}},
+		{"lower bound only", VersionFilter{MinVersionID: 103}, []int64{104}},
+		{"no ID in between", VersionFilter{MinVersionID: 101, MaxVersionID: 102}, nil},
+		{"combined with untagged", VersionFilter{MinVersionID: 100, MaxVersionID: 104, OnlyUntagged: true}, []int64{101, 103}},
+		{"combined with date", VersionFilter{MaxVersionID: 104, NewerThan: time.Date(2025, 1, 2, 12, 0, 0, 0, time.UTC)}, []int64{102, 103}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ids(tt.filter.Apply(versions)))
+		})
+	}
+}
+
 func TestVersionFilter_Apply_Digest(t *testing.T) {
 	versions := []gh.PackageVersionInfo{
 		{ID: 1, Digest: "sha256:abc123", Tags: []string{"v1.0.0"}, CreatedAt: "2025-01-01T00:00:00Z"},
@@ -574,6 +626,78 @@ func TestParseDate(t *testing.T) {
 	}
 }
 
+func TestParseDateIn(t *testing.T) {
+	t.Parallel()
+	newYork, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+
+	// A date-only value is midnight in the given zone, five hours after UTC midnight in winter
+	utc, err := ParseDateIn("2025-01-01", time.UTC)
+	require.NoError(t, err)
+	local, err := ParseDateIn("2025-01-01", newYork)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), utc)
+	assert.Equal(t, time.Date(2025, 1, 1, 5, 0, 0, 0, time.UTC), local.UTC())
+
+	// A version pushed at 02:00 UTC on January 1st is on the new day in UTC,
+	// but still on New Year's Eve in New York
+	pushed := time.Date(2025, 1, 1, 2, 0, 0, 0, time.UTC)
+	assert.False(t, pushed.Before(utc))
+	assert.True(t, pushed.Before(local))
+
+	// Explicit offsets win over the zone
+	explicit, err := ParseDateIn("2025-01-01T00:00:00Z", newYork)
+	require.NoError(t, err)
+	assert.Equal(t, utc, explicit.UTC())
+
+	viaDuration, err := ParseDateOrDurationIn("2025-01-01", newYork)
+	require.NoError(t, err)
+	assert.Equal(t, local, viaDuration)
+
+	plain, err := ParseDate("2025-01-01")
+	require.NoError(t, err)
+	assert.Equal(t, utc, plain, "ParseDate keeps UTC")
+}
+
+func TestParseAge(t *testing.T) {
+	t.Parallel()
+	now := time.Now()
+
+	cutoff, err := ParseAge("30d")
+	require.NoError(t, err)
+	assert.WithinDuration(t, now.AddDate(0, 0, -30), cutoff, time.Minute)
+
+	cutoff, err = ParseAge("12h")
+	require.NoError(t, err)
+	assert.WithinDuration(t, now.Add(-12*time.Hour), cutoff, time.Minute)
+
+	_, err = ParseAge("2025-01-01")
+	assert.EqualError(t, err, `"2025-01-01" is a date, not an age (use a duration such as 30d or 12h)`)
+
+	_, err = ParseAge("")
+	assert.Error(t, err)
+}
+
+func TestParseLocation(t *testing.T) {
+	t.Parallel()
+	for _, name := range []string{"", "UTC", "utc"} {
+		loc, err := ParseLocation(name)
+		require.NoError(t, err)
+		assert.Equal(t, time.UTC, loc, name)
+	}
+
+	loc, err := ParseLocation("local")
+	require.NoError(t, err)
+	assert.Equal(t, time.Local, loc)
+
+	loc, err = ParseLocation("Europe/Berlin")
+	require.NoError(t, err)
+	assert.Equal(t, "Europe/Berlin", loc.String())
+
+	_, err = ParseLocation("Mars/Olympus_Mons")
+	assert.EqualError(t, err, `unknown time zone "Mars/Olympus_Mons" (use an IANA name such as America/New_York, UTC, or local)`)
+}
+
 func TestParseDateOrDuration(t *testing.T) {
 	t.Parallel()
 
@@ -732,3 +856,80 @@ func TestVersionFilter_Apply_OnlyUntagged_AllInTaggedGraph(t *testing.T) {
 	// No orphans exist, so result should be empty
 	assert.Equal(t, 0, len(result))
 }
+
+func TestVersionFilter_Apply_TagPrefix(t *testing.T) {
+	versions := []gh.PackageVersionInfo{
+		createTestVersion(1, []string{"pr-12"}, "2025-01-01T00:00:00Z"),
+		createTestVersion(2, []string{"v1.0.0", "pr-13"}, "2025-01-02T00:00:00Z"),
+		createTestVersion(3, []string{"main", "sha-pr-14"}, "2025-01-03T00:00:00Z"),
+		createTestVersion(4, []string{}, "2025-01-04T00:00:00Z"),
+	}
+
+	filter := &VersionFilter{TagPrefix: "pr-"}
+	result := filter.Apply(versions)
+
+	// Version 2 matches through its second tag; "sha-pr-14" only contains the prefix
+	assert.Equal(t, 2, len(result))
+	assert.Equal(t, int64(1), result[0].ID)
+	assert.Equal(t, int64(2), result[1].ID)
+}
+
+func TestVersionFilter_Apply_TagSuffix(t *testing.T) {
+	versions := []gh.PackageVersionInfo{
+		createTestVersion(1, []string{"v1.0.0-amd64"}, "2025-01-01T00:00:00Z"),
+		createTestVersion(2, []string{"latest", "v1.0.0-arm64"}, "2025-01-02T00:00:00Z"),
+		createTestVersion(3, []string{"v1.0.0-amd64-debug"}, "2025-01-03T00:00:00Z"),
+	}
+
+	filter := &VersionFilter{TagSuffix: "-arm64"}
+	result := filter.Apply(versions)
+
+	assert.Equal(t, 1, len(result))
+	assert.Equal(t, int64(2), result[0].ID)
+}
+
+func TestVersionFilter_Apply_TagPrefixIsLiteral(t *testing.T) {
+	versions := []gh.PackageVersionInfo{
+		createTestVersion(1, []string{"v1.0"}, "2025-01-01T00:00:00Z"),
+		createTestVersion(2, []string{"v1x0"}, "2025-01-02T00:00:00Z"),
+	}
+
+	// "." is not a regex wildcard here
+	filter := &VersionFilter{TagPrefix: "v1."}
+	result := filter.Apply(versions)
+
+	assert.Equal(t, 1, len(result))
+	assert.Equal(t, int64(1), result[0].ID)
+}
+
+func TestVersionFilter_Apply_TagPrefixAndSuffix_SameTag(t *testing.T) {
+	versions := []gh.PackageVersionInfo{
+		createTestVersion(1, []string{"pr-12-amd64"}, "2025-01-01T00:00:00Z"),
+		// Prefix and suffix match different tags only
+		createTestVersion(2, []string{"pr-13", "v1.0.0-amd64"}, "2025-01-02T00:00:00Z"),
+		createTestVersion(3, []string{"pr-14-arm64"}, "2025-01-03T00:00:00Z"),
+	}
+
+	filter := &VersionFilter{TagPrefix: "pr-", TagSuffix: "-amd64"}
+	result := filter.Apply(versions)
+
+	assert.Equal(t, 1, len(result))
+	assert.Equal(t, int64(1), result[0].ID)
+}
+
+func TestVersionFilter_Apply_TagPrefixWithOtherFilters(t *testing.T) {
+	versions := []gh.PackageVersionInfo{
+		createTestVersion(1, []string{"pr-12"}, "2025-01-01T00:00:00Z"),
+		createTestVersion(2, []string{"pr-13"}, "2025-06-01T00:00:00Z"),
+		createTestVersion(3, []string{"main"}, "2025-01-01T00:00:00Z"),
+	}
+
+	filter := &VersionFilter{
+		TagPrefix: "pr-",
+		OlderThan: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC),
+	}
+	result := filter.Apply(versions)
+
+	assert.Equal(t, 1, len(result))
+	assert.Equal(t, int64(1), result[0].ID)
+}
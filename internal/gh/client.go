@@ -5,14 +5,19 @@ package gh
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
 	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/google/go-github/v58/github"
 	"github.com/mkoepf/ghcrctl/internal/logging"
+	"github.com/mkoepf/ghcrctl/internal/ratelimit"
 )
 
 // Client wraps the GitHub API client
@@ -62,22 +67,29 @@ func NewClient(token string) (*Client, error) {
 }
 
 // NewClientWithContext creates a new GitHub API client with the provided token and context
-// If logging is enabled in the context, API calls will be logged
+// If logging is enabled in the context, API calls will be logged.
+// If the context carries a rate limiter, API calls are paced by it.
+// Package and version listings are cached with their ETags for the lifetime of
+// the client, and between runs if the context carries a cache directory (see
+// WithCacheDir), so repeated listings send conditional requests.
 func NewClientWithContext(ctx context.Context, token string) (*Client, error) {
 	if token == "" {
 		return nil, fmt.Errorf("token cannot be empty")
 	}
 
-	// Create HTTP client with logging if enabled
-	var httpClient *http.Client
+	// Create HTTP client with ETag caching, and with logging and pacing if enabled.
+	// The cache sits outside the logging transport, so 304 responses are logged as such.
+	transport := http.DefaultTransport
 	if logging.IsLoggingEnabled(ctx) {
-		httpClient = &http.Client{
-			Transport: logging.NewLoggingRoundTripper(http.DefaultTransport, os.Stderr),
-		}
+		transport = logging.NewLoggingRoundTripper(transport, os.Stderr)
+	}
+	transport = newETagTransport(transport, CacheDirFromContext(ctx), token)
+	if limiter := ratelimit.FromContext(ctx); limiter != nil {
+		transport = ratelimit.NewRoundTripper(transport, limiter)
 	}
 
 	// Create GitHub client with authentication
-	client := github.NewClient(httpClient).WithAuthToken(token)
+	client := github.NewClient(&http.Client{Transport: transport}).WithAuthToken(token)
 
 	return &Client{
 		client: client,
@@ -85,8 +97,24 @@ func NewClientWithContext(ctx context.Context, token string) (*Client, error) {
 	}, nil
 }
 
-// ListPackages lists all container packages for the specified owner
-func (c *Client) ListPackages(ctx context.Context, owner string, ownerType string) ([]string, error) {
+// Package types that hold images. GHCR packages are "container"; "docker" is
+// the legacy namespace of the Docker registry that some organizations still have.
+const (
+	PackageTypeContainer = "container"
+	PackageTypeDocker    = "docker"
+)
+
+// ValidatePackageType checks that packageType is one of the supported package types
+func ValidatePackageType(packageType string) error {
+	if packageType != PackageTypeContainer && packageType != PackageTypeDocker {
+		return fmt.Errorf("package type must be '%s' or '%s', got '%s'", PackageTypeContainer, PackageTypeDocker, packageType)
+	}
+	return nil
+}
+
+// ListPackages lists all packages of the given type (see PackageTypeContainer)
+// for the specified owner
+func (c *Client) ListPackages(ctx context.Context, owner, ownerType, packageType string) ([]string, error) {
 	// Validate inputs
 	if owner == "" {
 		return nil, fmt.Errorf("owner cannot be empty")
@@ -96,9 +124,16 @@ func (c *Client) ListPackages(ctx context.Context, owner string, ownerType strin
 		return nil, fmt.Errorf("owner type must be 'org' or 'user', got '%s'", ownerType)
 	}
 
+	if err := ValidatePackageType(packageType); err != nil {
+		return nil, err
+	}
+
+	// Unchanged listings are answered from the ETag cache
+	ctx = withETagCache(ctx)
+
 	// Set up options for listing packages
 	opts := &github.PackageListOptions{
-		PackageType: github.String("container"),
+		PackageType: github.String(packageType),
 		ListOptions: github.ListOptions{PerPage: 100},
 	}
 
@@ -127,7 +162,7 @@ func (c *Client) ListPackages(ctx context.Context, owner string, ownerType strin
 				return nil, fmt.Errorf("cannot list packages for %s %s. Your token might be either a fine-grained personal access token or repository-scoped.\nTo list all packages, you need the read:packages scope and the %s must be readable by you.\nYou might still have access to specific packages, e.g., 'ghcrctl graph <image-name>' might work with your token",
 					ownerType, owner, namespace)
 			}
-			return nil, fmt.Errorf("failed to list packages: %w", err)
+			return nil, wrapAPIError("list packages", err)
 		}
 
 		// Extract package names
@@ -187,7 +222,7 @@ func (c *Client) GetVersionIDByDigest(ctx context.Context, owner, ownerType, pac
 		}
 
 		if err != nil {
-			return 0, fmt.Errorf("failed to list package versions: %w", err)
+			return 0, wrapAPIError("list package versions", err)
 		}
 
 		allVersions = append(allVersions, versions...)
@@ -207,7 +242,7 @@ func (c *Client) GetVersionIDByDigest(ctx context.Context, owner, ownerType, pac
 		}
 	}
 
-	return 0, fmt.Errorf("no version found with digest %s", digest)
+	return 0, fmt.Errorf("version with digest %s %w", digest, ErrNotFound)
 }
 
 // GetVersionTags fetches all tags for a specific package version ID
@@ -237,7 +272,7 @@ func (c *Client) GetVersionTags(ctx context.Context, owner, ownerType, packageNa
 	}
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to get package version: %w", err)
+		return nil, wrapAPIError("get package version", err)
 	}
 
 	// Extract tags from metadata
@@ -251,98 +286,235 @@ func (c *Client) GetVersionTags(ctx context.Context, owner, ownerType, packageNa
 // PackageVersionInfo contains information about a package version.
 // For container packages, Digest contains the OCI digest (e.g., "sha256:abc123...").
 type PackageVersionInfo struct {
-	ID        int64
-	Digest    string
-	Tags      []string
-	CreatedAt string
-	UpdatedAt string
+	ID             int64
+	Digest         string
+	Tags           []string
+	CreatedAt      string
+	UpdatedAt      string
+	HTMLURL        string // Web page for this version on github.com
+	PackageHTMLURL string // Web page for the package on github.com
+}
+
+// timestampFormat is the format of CreatedAt and UpdatedAt, in UTC
+const timestampFormat = "2006-01-02 15:04:05"
+
+// MarshalJSON writes CreatedAt and UpdatedAt as RFC3339 timestamps, so that
+// consumers of the JSON output do not have to parse the format of
+// PackageVersionInfo. Values in another format are written unchanged.
+func (v PackageVersionInfo) MarshalJSON() ([]byte, error) {
+	type plain PackageVersionInfo // without the MarshalJSON method
+	p := plain(v)
+	p.CreatedAt = RFC3339Timestamp(v.CreatedAt)
+	p.UpdatedAt = RFC3339Timestamp(v.UpdatedAt)
+	return json.Marshal(p)
+}
+
+// RFC3339Timestamp converts a timestamp in the format of CreatedAt to RFC3339,
+// and returns anything else as is
+func RFC3339Timestamp(s string) string {
+	t, err := time.ParseInLocation(timestampFormat, s, time.UTC)
+	if err != nil {
+		return s
+	}
+	return t.Format(time.RFC3339)
+}
+
+// FromRFC3339Timestamp is the inverse of RFC3339Timestamp: it converts an
+// RFC3339 timestamp to the format of CreatedAt, and returns anything else as is
+func FromRFC3339Timestamp(s string) string {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return s
+	}
+	return t.UTC().Format(timestampFormat)
 }
 
 // ListPackageVersions lists all versions of a package
 func (c *Client) ListPackageVersions(ctx context.Context, owner, ownerType, packageName string) ([]PackageVersionInfo, error) {
+	// Unchanged pages are answered from the ETag cache
+	ctx = withETagCache(ctx)
+
+	var allVersions []PackageVersionInfo
+
+	page := 1
+	for {
+		versions, nextPage, err := c.ListPackageVersionsPage(ctx, owner, ownerType, packageName, page, 100)
+		if err != nil {
+			return nil, err
+		}
+		allVersions = append(allVersions, versions...)
+
+		// Check if there are more pages
+		if nextPage == 0 {
+			break
+		}
+		page = nextPage
+	}
+
+	return allVersions, nil
+}
+
+// ListPackageVersionsPage lists a single page of package versions, newest first.
+// perPage is capped at 100 by the GitHub API. The returned next page number is 0
+// when this was the last page.
+func (c *Client) ListPackageVersionsPage(ctx context.Context, owner, ownerType, packageName string, page, perPage int) ([]PackageVersionInfo, int, error) {
 	// Validate inputs
 	if owner == "" {
-		return nil, fmt.Errorf("owner cannot be empty")
+		return nil, 0, fmt.Errorf("owner cannot be empty")
 	}
 	if ownerType != "org" && ownerType != "user" {
-		return nil, fmt.Errorf("owner type must be 'org' or 'user', got '%s'", ownerType)
+		return nil, 0, fmt.Errorf("owner type must be 'org' or 'user', got '%s'", ownerType)
 	}
 	if packageName == "" {
-		return nil, fmt.Errorf("package name cannot be empty")
+		return nil, 0, fmt.Errorf("package name cannot be empty")
 	}
 
 	// Set up options for listing versions
 	opts := &github.PackageListOptions{
 		PackageType: github.String("container"),
-		ListOptions: github.ListOptions{PerPage: 100},
+		ListOptions: github.ListOptions{Page: page, PerPage: perPage},
 	}
 
-	var allVersions []PackageVersionInfo
-
-	// List versions based on owner type
-	for {
-		var versions []*github.PackageVersion
-		var resp *github.Response
-		var err error
+	var versions []*github.PackageVersion
+	var resp *github.Response
+	var err error
 
-		if ownerType == "org" {
-			versions, resp, err = c.client.Organizations.PackageGetAllVersions(ctx, owner, "container", packageName, opts)
-		} else {
-			versions, resp, err = c.client.Users.PackageGetAllVersions(ctx, owner, "container", packageName, opts)
-		}
+	if ownerType == "org" {
+		versions, resp, err = c.client.Organizations.PackageGetAllVersions(ctx, owner, "container", packageName, opts)
+	} else {
+		versions, resp, err = c.client.Users.PackageGetAllVersions(ctx, owner, "container", packageName, opts)
+	}
 
-		if err != nil {
-			return nil, fmt.Errorf("failed to list package versions: %w", err)
-		}
+	if err != nil {
+		return nil, 0, wrapAPIError("list package versions", err)
+	}
 
-		// Extract version info
-		for _, ver := range versions {
-			info := PackageVersionInfo{
-				ID:     *ver.ID,
-				Digest: *ver.Name,
-			}
+	// Extract version info
+	result := make([]PackageVersionInfo, 0, len(versions))
+	for _, ver := range versions {
+		result = append(result, versionInfoFromAPI(ver))
+	}
 
-			// Extract tags if available
-			if ver.Metadata != nil && ver.Metadata.Container != nil {
-				info.Tags = ver.Metadata.Container.Tags
-			}
+	return result, resp.NextPage, nil
+}
 
-			// Extract timestamps if available
-			if ver.CreatedAt != nil {
-				info.CreatedAt = ver.CreatedAt.Format("2006-01-02 15:04:05")
-			}
-			if ver.UpdatedAt != nil {
-				info.UpdatedAt = ver.UpdatedAt.Format("2006-01-02 15:04:05")
-			}
+// versionInfoFromAPI converts a package version returned by the GitHub API
+func versionInfoFromAPI(ver *github.PackageVersion) PackageVersionInfo {
+	info := PackageVersionInfo{
+		ID:             ver.GetID(),
+		Digest:         ver.GetName(),
+		HTMLURL:        ver.GetHTMLURL(),
+		PackageHTMLURL: ver.GetPackageHTMLURL(),
+	}
 
-			allVersions = append(allVersions, info)
-		}
+	// Extract tags if available
+	if ver.Metadata != nil && ver.Metadata.Container != nil {
+		info.Tags = ver.Metadata.Container.Tags
+	}
 
-		// Check if there are more pages
-		if resp.NextPage == 0 {
-			break
-		}
-		opts.Page = resp.NextPage
+	// Extract timestamps if available
+	if ver.CreatedAt != nil {
+		info.CreatedAt = ver.CreatedAt.UTC().Format(timestampFormat)
+	}
+	if ver.UpdatedAt != nil {
+		info.UpdatedAt = ver.UpdatedAt.UTC().Format(timestampFormat)
 	}
 
-	return allVersions, nil
+	return info
+}
+
+// ownerTypes caches the results of GetOwnerType for the lifetime of the
+// process, so that commands working on many packages of one owner look it up
+// once. Keys are the API base URL and the lowercased owner, since GitHub
+// logins are case-insensitive.
+var ownerTypes sync.Map
+
+// ResetOwnerTypeCache forgets all owner types looked up so far, e.g. after an
+// account was converted to an organization.
+func ResetOwnerTypeCache() {
+	ownerTypes.Clear()
 }
 
-// GetOwnerType determines whether the given owner is a user or organization
+// GetOwnerType determines whether the given owner is a user or organization.
+// The result is cached per process; failed lookups are not cached.
 func (c *Client) GetOwnerType(ctx context.Context, owner string) (string, error) {
 	if owner == "" {
 		return "", fmt.Errorf("owner cannot be empty")
 	}
 
+	key := c.ownerTypeKey(owner)
+	if ownerType, ok := ownerTypes.Load(key); ok {
+		return ownerType.(string), nil
+	}
+
 	user, _, err := c.client.Users.Get(ctx, owner)
 	if err != nil {
 		return "", fmt.Errorf("failed to get owner info: %w", err)
 	}
 
+	ownerType := "user"
 	if user.Type != nil && *user.Type == "Organization" {
-		return "org", nil
+		ownerType = "org"
 	}
-	return "user", nil
+	ownerTypes.Store(key, ownerType)
+	return ownerType, nil
+}
+
+// RefreshOwnerType looks the owner type up again and replaces the cached
+// result, for long-running commands such as watches
+func (c *Client) RefreshOwnerType(ctx context.Context, owner string) (string, error) {
+	if owner == "" {
+		return "", fmt.Errorf("owner cannot be empty")
+	}
+	ownerTypes.Delete(c.ownerTypeKey(owner))
+	return c.GetOwnerType(ctx, owner)
+}
+
+// ownerTypeKey is the key of owner in ownerTypes
+func (c *Client) ownerTypeKey(owner string) string {
+	return c.client.BaseURL.String() + strings.ToLower(owner)
+}
+
+// TokenInfo describes the user a token belongs to and the scopes it grants
+type TokenInfo struct {
+	Login string
+	// Scopes are the OAuth scopes of a classic personal access token.
+	// Fine-grained and GitHub Actions tokens do not report scopes, in which
+	// case ScopesKnown is false.
+	Scopes      []string
+	ScopesKnown bool
+}
+
+// HasScope reports whether the token grants scope. write:packages implies
+// read:packages.
+func (t TokenInfo) HasScope(scope string) bool {
+	for _, s := range t.Scopes {
+		if s == scope || (scope == "read:packages" && s == "write:packages") {
+			return true
+		}
+	}
+	return false
+}
+
+// WhoAmI returns the user the client's token belongs to, and its scopes
+func (c *Client) WhoAmI(ctx context.Context) (TokenInfo, error) {
+	user, resp, err := c.client.Users.Get(ctx, "")
+	if err != nil {
+		return TokenInfo{}, fmt.Errorf("failed to get authenticated user: %w", err)
+	}
+
+	info := TokenInfo{Login: user.GetLogin()}
+	if resp != nil {
+		if header, ok := resp.Header["X-Oauth-Scopes"]; ok {
+			info.ScopesKnown = true
+			for _, scope := range strings.Split(strings.Join(header, ","), ",") {
+				if scope = strings.TrimSpace(scope); scope != "" {
+					info.Scopes = append(info.Scopes, scope)
+				}
+			}
+		}
+	}
+	return info, nil
 }
 
 // DeletePackageVersion deletes a specific package version
@@ -370,7 +542,7 @@ func (c *Client) DeletePackageVersion(ctx context.Context, owner, ownerType, pac
 	}
 
 	if err != nil {
-		return fmt.Errorf("failed to delete version: %w", err)
+		return wrapAPIError("delete version", err)
 	}
 
 	return nil
@@ -384,6 +556,89 @@ func IsLastTaggedVersionError(err error) bool {
 	return strings.Contains(err.Error(), "cannot delete the last tagged version")
 }
 
+// fineGrainedPATDenied is the message GitHub returns when a fine-grained
+// personal access token lacks the permission for a request.
+const fineGrainedPATDenied = "Resource not accessible by personal access token"
+
+// InsufficientPermissionsError is returned when GitHub rejects a request because
+// a fine-grained personal access token lacks the required package permission.
+// Fine-grained tokens don't report their scopes, so this is only known once a
+// request fails.
+type InsufficientPermissionsError struct {
+	Action string // What was attempted, e.g. "delete version"
+	Err    error
+}
+
+func (e *InsufficientPermissionsError) Error() string {
+	return fmt.Sprintf("failed to %s: your fine-grained personal access token is not allowed to do this.\n"+
+		"Grant the token the \"Packages\" permission (read to list, read and write to delete) for the package owner, "+
+		"and make sure the package is accessible to the token's resource owner.\n"+
+		"Alternatively, use a classic token with the read:packages and delete:packages scopes", e.Action)
+}
+
+func (e *InsufficientPermissionsError) Unwrap() error {
+	return e.Err
+}
+
+// isInsufficientPermissions checks if the error is a 403 caused by a fine-grained
+// personal access token lacking permissions.
+func isInsufficientPermissions(err error) bool {
+	var permErr *InsufficientPermissionsError
+	if errors.As(err, &permErr) {
+		return true
+	}
+
+	var errResp *github.ErrorResponse
+	if !errors.As(err, &errResp) || errResp.Response == nil {
+		return false
+	}
+	return errResp.Response.StatusCode == http.StatusForbidden &&
+		strings.Contains(errResp.Message, fineGrainedPATDenied)
+}
+
+// ErrNotFound matches errors for packages or versions that do not exist.
+// Use IsNotFound to test for it.
+var ErrNotFound = errors.New("not found")
+
+// NotFoundError is returned when the GitHub API answers 404 Not Found, so that
+// callers can tell a missing package or version from other failures.
+type NotFoundError struct {
+	Action string // What was attempted, e.g. "list package versions"
+	Err    error
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("failed to %s: %v", e.Action, e.Err)
+}
+
+func (e *NotFoundError) Unwrap() error {
+	return e.Err
+}
+
+// Is makes errors.Is(err, ErrNotFound) match a NotFoundError.
+func (e *NotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// IsNotFound reports whether err means that a package or version does not exist.
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+// wrapAPIError annotates an error from the GitHub API with the failed action,
+// turning fine-grained token permission failures into an InsufficientPermissionsError
+// and 404 responses into a NotFoundError.
+func wrapAPIError(action string, err error) error {
+	if isInsufficientPermissions(err) {
+		return &InsufficientPermissionsError{Action: action, Err: err}
+	}
+	var errResp *github.ErrorResponse
+	if errors.As(err, &errResp) && errResp.Response != nil && errResp.Response.StatusCode == http.StatusNotFound {
+		return &NotFoundError{Action: action, Err: err}
+	}
+	return fmt.Errorf("failed to %s: %w", action, err)
+}
+
 // DeletePackage deletes an entire package (not just a version)
 func (c *Client) DeletePackage(ctx context.Context, owner, ownerType, packageName string) error {
 	// Validate inputs
@@ -406,7 +661,7 @@ func (c *Client) DeletePackage(ctx context.Context, owner, ownerType, packageNam
 	}
 
 	if err != nil {
-		return fmt.Errorf("failed to delete package: %w", err)
+		return wrapAPIError("delete package", err)
 	}
 
 	return nil
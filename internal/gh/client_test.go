@@ -2,9 +2,18 @@ package gh
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 
+	"github.com/google/go-github/v58/github"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -117,10 +126,11 @@ func TestListPackages(t *testing.T) {
 	// Test input validation for ListPackages
 
 	tests := []struct {
-		name      string
-		owner     string
-		ownerType string
-		wantError bool
+		name        string
+		owner       string
+		ownerType   string
+		packageType string
+		wantError   bool
 	}{
 		{
 			name:      "empty owner",
@@ -134,6 +144,13 @@ func TestListPackages(t *testing.T) {
 			ownerType: "invalid",
 			wantError: true,
 		},
+		{
+			name:        "invalid package type",
+			owner:       "test",
+			ownerType:   "org",
+			packageType: "npm",
+			wantError:   true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -141,8 +158,12 @@ func TestListPackages(t *testing.T) {
 			client, err := NewClient("ghp_fake_token")
 			require.NoError(t, err)
 
+			packageType := tt.packageType
+			if packageType == "" {
+				packageType = PackageTypeContainer
+			}
 			ctx := context.Background()
-			packages, err := client.ListPackages(ctx, tt.owner, tt.ownerType)
+			packages, err := client.ListPackages(ctx, tt.owner, tt.ownerType, packageType)
 
 			if tt.wantError {
 				assert.Error(t, err)
@@ -154,6 +175,37 @@ func TestListPackages(t *testing.T) {
 	}
 }
 
+func TestListPackages_FiltersByPackageType(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/orgs/myorg/packages", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("package_type") {
+		case PackageTypeContainer:
+			_, _ = w.Write([]byte(`[{"name": "web", "package_type": "container"}, {"name": "api", "package_type": "container"}]`))
+		case PackageTypeDocker:
+			_, _ = w.Write([]byte(`[{"name": "legacy-web", "package_type": "docker"}]`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient("ghp_fake_token")
+	require.NoError(t, err)
+	client.client.BaseURL, err = url.Parse(server.URL + "/")
+	require.NoError(t, err)
+
+	packages, err := client.ListPackages(context.Background(), "myorg", "org", PackageTypeContainer)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"api", "web"}, packages)
+
+	packages, err = client.ListPackages(context.Background(), "myorg", "org", PackageTypeDocker)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"legacy-web"}, packages)
+}
+
 func TestGetVersionIDByDigest(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -247,6 +299,58 @@ func TestGetOwnerType(t *testing.T) {
 	}
 }
 
+func TestGetOwnerType_Cached(t *testing.T) {
+	var lookups sync.Map
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count, _ := lookups.LoadOrStore(r.URL.Path, new(atomic.Int32))
+		count.(*atomic.Int32).Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"login": "myorg", "type": "Organization"}`))
+	}))
+	defer server.Close()
+
+	newClient := func() *Client {
+		client, err := NewClient("ghp_fake_token")
+		require.NoError(t, err)
+		client.client.BaseURL, err = url.Parse(server.URL + "/")
+		require.NoError(t, err)
+		return client
+	}
+	hits := func(path string) int32 {
+		count, ok := lookups.Load(path)
+		if !ok {
+			return 0
+		}
+		return count.(*atomic.Int32).Load()
+	}
+	ctx := context.Background()
+
+	// Separate commands create separate clients
+	for _, owner := range []string{"myorg", "MyOrg", "myorg"} {
+		ownerType, err := newClient().GetOwnerType(ctx, owner)
+		require.NoError(t, err)
+		assert.Equal(t, "org", ownerType)
+	}
+	assert.Equal(t, int32(1), hits("/users/myorg"), "owner type is looked up once per process")
+
+	_, err := newClient().GetOwnerType(ctx, "other")
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), hits("/users/other"))
+
+	ResetOwnerTypeCache()
+	_, err = newClient().GetOwnerType(ctx, "myorg")
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), hits("/users/myorg"), "reset forgets cached owner types")
+
+	client := newClient()
+	ownerType, err := client.RefreshOwnerType(ctx, "myorg")
+	require.NoError(t, err)
+	assert.Equal(t, "org", ownerType)
+	_, err = client.GetOwnerType(ctx, "myorg")
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), hits("/users/myorg"), "refresh looks the owner type up again and caches it")
+}
+
 func TestListPackageVersions(t *testing.T) {
 	t.Parallel()
 
@@ -301,6 +405,154 @@ func TestListPackageVersions(t *testing.T) {
 	}
 }
 
+// listVersionsPayload is a trimmed response from
+// GET /users/{user}/packages/container/{package}/versions
+const listVersionsPayload = `[
+  {
+    "id": 583491234,
+    "name": "sha256:6f1c2a1b9e0d4c3f8a7b6e5d4c3b2a1f0e9d8c7b6a5f4e3d2c1b0a9f8e7d6c5b",
+    "url": "https://api.github.com/users/mkoepf/packages/container/myimage/versions/583491234",
+    "package_html_url": "https://github.com/users/mkoepf/packages/container/package/myimage",
+    "created_at": "2025-11-20T09:15:42Z",
+    "updated_at": "2025-11-20T09:15:43Z",
+    "html_url": "https://github.com/users/mkoepf/packages/container/myimage/583491234",
+    "metadata": {
+      "package_type": "container",
+      "container": {
+        "tags": ["v1.2.0", "latest"]
+      }
+    }
+  },
+  {
+    "id": 583491100,
+    "name": "sha256:0a1b2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f9",
+    "url": "https://api.github.com/users/mkoepf/packages/container/myimage/versions/583491100",
+    "package_html_url": "https://github.com/users/mkoepf/packages/container/package/myimage",
+    "created_at": "2025-11-19T17:02:10Z",
+    "updated_at": "2025-11-19T17:02:10Z",
+    "html_url": "https://github.com/users/mkoepf/packages/container/myimage/583491100",
+    "metadata": {
+      "package_type": "container",
+      "container": {
+        "tags": []
+      }
+    }
+  }
+]`
+
+func TestListPackageVersions_DecodesAPIResponse(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/users/mkoepf/packages/container/myimage/versions", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(listVersionsPayload))
+	}))
+	defer server.Close()
+
+	client, err := NewClient("ghp_fake_token")
+	require.NoError(t, err)
+	client.client.BaseURL, err = url.Parse(server.URL + "/")
+	require.NoError(t, err)
+
+	versions, err := client.ListPackageVersions(context.Background(), "mkoepf", "user", "myimage")
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+
+	assert.Equal(t, PackageVersionInfo{
+		ID:             583491234,
+		Digest:         "sha256:6f1c2a1b9e0d4c3f8a7b6e5d4c3b2a1f0e9d8c7b6a5f4e3d2c1b0a9f8e7d6c5b",
+		Tags:           []string{"v1.2.0", "latest"},
+		CreatedAt:      "2025-11-20 09:15:42",
+		UpdatedAt:      "2025-11-20 09:15:43",
+		HTMLURL:        "https://github.com/users/mkoepf/packages/container/myimage/583491234",
+		PackageHTMLURL: "https://github.com/users/mkoepf/packages/container/package/myimage",
+	}, versions[0])
+
+	assert.Equal(t, int64(583491100), versions[1].ID)
+	assert.Empty(t, versions[1].Tags)
+	assert.Equal(t, "https://github.com/users/mkoepf/packages/container/myimage/583491100", versions[1].HTMLURL)
+}
+
+func TestListPackageVersions_FollowsPages(t *testing.T) {
+	t.Parallel()
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		assert.Equal(t, "100", r.URL.Query().Get("per_page"))
+		if r.URL.Query().Get("page") == "2" {
+			_, _ = w.Write([]byte(`[{"id": 2, "name": "sha256:two"}]`))
+			return
+		}
+		w.Header().Set("Link", fmt.Sprintf(`<%s%s?page=2&per_page=100>; rel="next"`, server.URL, r.URL.Path))
+		_, _ = w.Write([]byte(`[{"id": 1, "name": "sha256:one"}]`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient("ghp_fake_token")
+	require.NoError(t, err)
+	client.client.BaseURL, err = url.Parse(server.URL + "/")
+	require.NoError(t, err)
+
+	versions, err := client.ListPackageVersions(context.Background(), "myorg", "org", "myimage")
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	assert.Equal(t, int64(1), versions[0].ID)
+	assert.Equal(t, int64(2), versions[1].ID)
+
+	page, next, err := client.ListPackageVersionsPage(context.Background(), "myorg", "org", "myimage", 1, 100)
+	require.NoError(t, err)
+	assert.Len(t, page, 1)
+	assert.Equal(t, 2, next)
+}
+
+func TestVersionInfoFromAPI_MissingFields(t *testing.T) {
+	t.Parallel()
+
+	var versions []*github.PackageVersion
+	require.NoError(t, json.Unmarshal([]byte(`[{"id": 42, "name": "sha256:abc"}]`), &versions))
+
+	info := versionInfoFromAPI(versions[0])
+	assert.Equal(t, PackageVersionInfo{ID: 42, Digest: "sha256:abc"}, info)
+}
+
+func TestPackageVersionInfo_MarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		createdAt   string
+		wantCreated string
+	}{
+		{name: "GitHub format", createdAt: "2025-01-01 10:30:45", wantCreated: "2025-01-01T10:30:45Z"},
+		{name: "already RFC3339", createdAt: "2025-01-01T10:30:45+02:00", wantCreated: "2025-01-01T10:30:45+02:00"},
+		{name: "malformed", createdAt: "yesterday", wantCreated: "yesterday"},
+		{name: "empty", createdAt: "", wantCreated: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			data, err := json.Marshal([]PackageVersionInfo{{ID: 42, CreatedAt: tt.createdAt, UpdatedAt: "2025-01-02 08:00:00"}})
+			require.NoError(t, err)
+
+			var decoded []map[string]interface{}
+			require.NoError(t, json.Unmarshal(data, &decoded))
+			assert.Equal(t, tt.wantCreated, decoded[0]["CreatedAt"])
+			assert.Equal(t, "2025-01-02T08:00:00Z", decoded[0]["UpdatedAt"])
+			assert.EqualValues(t, 42, decoded[0]["ID"])
+		})
+	}
+}
+
+func TestFromRFC3339Timestamp(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "2025-01-01 10:30:45", FromRFC3339Timestamp("2025-01-01T10:30:45Z"))
+	assert.Equal(t, "2025-01-01 08:30:45", FromRFC3339Timestamp("2025-01-01T10:30:45+02:00"))
+	assert.Equal(t, "2025-01-01 10:30:45", FromRFC3339Timestamp(RFC3339Timestamp("2025-01-01 10:30:45")))
+	assert.Equal(t, "yesterday", FromRFC3339Timestamp("yesterday"))
+}
+
 func TestGetVersionTags(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -360,3 +612,307 @@ func TestGetVersionTags(t *testing.T) {
 		})
 	}
 }
+
+// newFineGrainedDeniedServer returns a server that rejects every request like
+// GitHub does for a fine-grained token without package permissions
+func newFineGrainedDeniedServer(t *testing.T) *Client {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"message":"Resource not accessible by personal access token","documentation_url":"https://docs.github.com/rest/packages/packages#delete-package-version-for-a-user","status":"403"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := NewClient("github_pat_fake_token")
+	require.NoError(t, err)
+	client.client.BaseURL, err = url.Parse(server.URL + "/")
+	require.NoError(t, err)
+	return client
+}
+
+func TestFineGrainedPATForbidden(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	client := newFineGrainedDeniedServer(t)
+
+	tests := []struct {
+		name   string
+		action string
+		call   func() error
+	}{
+		{
+			name:   "delete version",
+			action: "delete version",
+			call: func() error {
+				return client.DeletePackageVersion(ctx, "mkoepf", "user", "myimage", 123)
+			},
+		},
+		{
+			name:   "delete package",
+			action: "delete package",
+			call: func() error {
+				return client.DeletePackage(ctx, "myorg", "org", "myimage")
+			},
+		},
+		{
+			name:   "list versions",
+			action: "list package versions",
+			call: func() error {
+				_, err := client.ListPackageVersions(ctx, "mkoepf", "user", "myimage")
+				return err
+			},
+		},
+		{
+			name:   "get version tags",
+			action: "get package version",
+			call: func() error {
+				_, err := client.GetVersionTags(ctx, "mkoepf", "user", "myimage", 123)
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := tt.call()
+			require.Error(t, err)
+			assert.True(t, isInsufficientPermissions(err))
+
+			var permErr *InsufficientPermissionsError
+			require.ErrorAs(t, err, &permErr)
+			assert.Equal(t, tt.action, permErr.Action)
+			assert.Contains(t, err.Error(), "failed to "+tt.action)
+			assert.Contains(t, err.Error(), `"Packages" permission`)
+
+			var errResp *github.ErrorResponse
+			assert.ErrorAs(t, err, &errResp, "original API error should stay reachable")
+		})
+	}
+}
+
+func TestIsInsufficientPermissions(t *testing.T) {
+	t.Parallel()
+
+	forbidden := func(message string) error {
+		return &github.ErrorResponse{
+			Response: &http.Response{StatusCode: http.StatusForbidden},
+			Message:  message,
+		}
+	}
+
+	assert.False(t, isInsufficientPermissions(nil))
+	assert.False(t, isInsufficientPermissions(fmt.Errorf("connection refused")))
+	assert.False(t, isInsufficientPermissions(forbidden("Must have admin rights to Repository.")))
+	assert.False(t, isInsufficientPermissions(&github.ErrorResponse{
+		Response: &http.Response{StatusCode: http.StatusNotFound},
+		Message:  fineGrainedPATDenied,
+	}))
+	assert.True(t, isInsufficientPermissions(forbidden(fineGrainedPATDenied)))
+	assert.True(t, isInsufficientPermissions(fmt.Errorf("wrapped: %w", forbidden(fineGrainedPATDenied))))
+
+	// Other failures keep the existing message
+	err := wrapAPIError("delete version", forbidden("Must have admin rights to Repository."))
+	assert.NotErrorAs(t, err, new(*InsufficientPermissionsError))
+	assert.Contains(t, err.Error(), "failed to delete version:")
+}
+
+// newStatusServer returns a client whose API requests all fail with status
+func newStatusServer(t *testing.T, status int) *Client {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(fmt.Sprintf(`{"message":%q}`, http.StatusText(status))))
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := NewClient("ghp_fake_token")
+	require.NoError(t, err)
+	client.client.BaseURL, err = url.Parse(server.URL + "/")
+	require.NoError(t, err)
+	return client
+}
+
+func TestNotFoundErrors(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	calls := map[string]func(c *Client) error{
+		"list versions": func(c *Client) error {
+			_, err := c.ListPackageVersions(ctx, "mkoepf", "user", "myimage")
+			return err
+		},
+		"get version ID by digest": func(c *Client) error {
+			_, err := c.GetVersionIDByDigest(ctx, "myorg", "org", "myimage", "sha256:abc")
+			return err
+		},
+		"delete version": func(c *Client) error {
+			return c.DeletePackageVersion(ctx, "mkoepf", "user", "myimage", 123)
+		},
+	}
+
+	notFound := newStatusServer(t, http.StatusNotFound)
+	serverError := newStatusServer(t, http.StatusInternalServerError)
+	for name, call := range calls {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := call(notFound)
+			require.Error(t, err)
+			assert.True(t, IsNotFound(err), "404 should be a not found error: %v", err)
+			var notFoundErr *NotFoundError
+			assert.ErrorAs(t, err, &notFoundErr)
+			var errResp *github.ErrorResponse
+			assert.ErrorAs(t, err, &errResp, "original API error should stay reachable")
+
+			err = call(serverError)
+			require.Error(t, err)
+			assert.False(t, IsNotFound(err), "500 must not look like not found: %v", err)
+		})
+	}
+}
+
+func TestGetVersionIDByDigest_NoMatchIsNotFound(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"id": 1, "name": "sha256:other"}]`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient("ghp_fake_token")
+	require.NoError(t, err)
+	client.client.BaseURL, err = url.Parse(server.URL + "/")
+	require.NoError(t, err)
+
+	_, err = client.GetVersionIDByDigest(context.Background(), "mkoepf", "user", "myimage", "sha256:abc")
+	assert.True(t, IsNotFound(err))
+	assert.EqualError(t, err, "version with digest sha256:abc not found")
+}
+
+func TestIsNotFound(t *testing.T) {
+	t.Parallel()
+
+	assert.False(t, IsNotFound(nil))
+	assert.False(t, IsNotFound(fmt.Errorf("connection refused")))
+	assert.True(t, IsNotFound(fmt.Errorf("wrapped: %w", &NotFoundError{Action: "delete version", Err: fmt.Errorf("404")})))
+}
+
+func TestWhoAmI(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		scopes      *string
+		wantScopes  []string
+		scopesKnown bool
+	}{
+		{name: "classic token", scopes: github.String("repo, write:packages, delete:packages"), wantScopes: []string{"repo", "write:packages", "delete:packages"}, scopesKnown: true},
+		{name: "classic token without scopes", scopes: github.String(""), scopesKnown: true},
+		{name: "fine-grained token", scopes: nil, scopesKnown: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/user", r.URL.Path)
+				if tt.scopes != nil {
+					w.Header().Set("X-OAuth-Scopes", *tt.scopes)
+				}
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"login": "mkoepf", "type": "User"}`))
+			}))
+			defer server.Close()
+
+			client, err := NewClient("ghp_fake_token")
+			require.NoError(t, err)
+			client.client.BaseURL, err = url.Parse(server.URL + "/")
+			require.NoError(t, err)
+
+			info, err := client.WhoAmI(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, "mkoepf", info.Login)
+			assert.Equal(t, tt.wantScopes, info.Scopes)
+			assert.Equal(t, tt.scopesKnown, info.ScopesKnown)
+		})
+	}
+}
+
+func TestTokenInfo_HasScope(t *testing.T) {
+	t.Parallel()
+
+	info := TokenInfo{Scopes: []string{"write:packages"}, ScopesKnown: true}
+	assert.True(t, info.HasScope("write:packages"))
+	assert.True(t, info.HasScope("read:packages"), "write:packages implies read:packages")
+	assert.False(t, info.HasScope("delete:packages"))
+}
+
+// TestClient_OwnerTypeAPIPaths checks that every package endpoint is called
+// under /users/ for users and under /orgs/ for organizations
+func TestClient_OwnerTypeAPIPaths(t *testing.T) {
+	t.Parallel()
+
+	calls := []struct {
+		name   string
+		method string
+		path   string // below /users/{owner} or /orgs/{owner}
+		call   func(c *Client, ownerType string) error
+	}{
+		{"ListPackages", http.MethodGet, "/packages", func(c *Client, ownerType string) error {
+			_, err := c.ListPackages(context.Background(), "acme", ownerType, PackageTypeContainer)
+			return err
+		}},
+		{"ListPackageVersions", http.MethodGet, "/packages/container/app/versions", func(c *Client, ownerType string) error {
+			_, err := c.ListPackageVersions(context.Background(), "acme", ownerType, "app")
+			return err
+		}},
+		{"GetVersionTags", http.MethodGet, "/packages/container/app/versions/42", func(c *Client, ownerType string) error {
+			_, err := c.GetVersionTags(context.Background(), "acme", ownerType, "app", 42)
+			return err
+		}},
+		{"DeletePackageVersion", http.MethodDelete, "/packages/container/app/versions/42", func(c *Client, ownerType string) error {
+			return c.DeletePackageVersion(context.Background(), "acme", ownerType, "app", 42)
+		}},
+		{"DeletePackage", http.MethodDelete, "/packages/container/app", func(c *Client, ownerType string) error {
+			return c.DeletePackage(context.Background(), "acme", ownerType, "app")
+		}},
+	}
+	prefixes := map[string]string{"user": "/users/acme", "org": "/orgs/acme"}
+
+	for _, call := range calls {
+		for ownerType, prefix := range prefixes {
+			t.Run(call.name+"/"+ownerType, func(t *testing.T) {
+				t.Parallel()
+
+				var gotMethod, gotPath string
+				server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					gotMethod, gotPath = r.Method, r.URL.Path
+					switch {
+					case r.Method == http.MethodDelete:
+						w.WriteHeader(http.StatusNoContent)
+					case strings.HasSuffix(r.URL.Path, "/42"):
+						w.Header().Set("Content-Type", "application/json")
+						_, _ = w.Write([]byte(`{"id": 42, "metadata": {"container": {"tags": ["v1"]}}}`))
+					default:
+						w.Header().Set("Content-Type", "application/json")
+						_, _ = w.Write([]byte(`[]`))
+					}
+				}))
+				defer server.Close()
+
+				client, err := NewClient("ghp_fake_token")
+				require.NoError(t, err)
+				client.client.BaseURL, err = url.Parse(server.URL + "/")
+				require.NoError(t, err)
+
+				require.NoError(t, call.call(client, ownerType))
+				assert.Equal(t, call.method, gotMethod)
+				assert.Equal(t, prefix+call.path, gotPath)
+			})
+		}
+	}
+}
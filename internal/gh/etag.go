@@ -0,0 +1,210 @@
+package gh
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// etagCacheKey marks a request context whose GET responses may be cached
+type etagCacheKey struct{}
+
+// cacheDirKey carries the directory that keeps ETag cache entries between runs
+type cacheDirKey struct{}
+
+// WithCacheDir returns a context whose clients keep their ETag cache entries in
+// dir, so that a later run can send conditional requests too
+func WithCacheDir(ctx context.Context, dir string) context.Context {
+	return context.WithValue(ctx, cacheDirKey{}, dir)
+}
+
+// CacheDirFromContext returns the ETag cache directory of ctx, or "" if the
+// cache is kept in memory only
+func CacheDirFromContext(ctx context.Context) string {
+	dir, _ := ctx.Value(cacheDirKey{}).(string)
+	return dir
+}
+
+// DefaultCacheDir returns the directory for ETag cache entries below the
+// user's cache directory, e.g. ~/.cache/ghcrctl/etags on Linux
+func DefaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "ghcrctl", "etags"), nil
+}
+
+// withETagCache marks ctx so that list requests made with it send conditional
+// requests and reuse cached responses
+func withETagCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, etagCacheKey{}, true)
+}
+
+// etagEntry is a cached response body with the ETag GitHub returned for it
+type etagEntry struct {
+	ETag   string      `json:"etag"`
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+}
+
+// etagTransport sends conditional requests with If-None-Match for GET requests
+// whose context was marked with withETagCache. A 304 Not Modified is answered
+// with the cached response, so the GitHub client sees an ordinary 200. GitHub
+// does not count 304 responses against the rate limit, which makes repeated
+// listings, e.g. by watch or by frequent runs of list packages, cheap.
+//
+// Entries are kept in memory for the lifetime of the client, and in dir if it
+// is set. Files are named by a hash of the token and the URL, so that clients
+// with another token never see them. Failing to read or write them only costs
+// a full response.
+type etagTransport struct {
+	transport http.RoundTripper
+	dir       string
+	tokenHash string
+
+	mu      sync.Mutex
+	entries map[string]etagEntry
+}
+
+// newETagTransport creates an ETag-caching transport wrapping transport, which
+// keeps its entries for token in dir, or in memory only if dir is empty
+func newETagTransport(transport http.RoundTripper, dir, token string) *etagTransport {
+	sum := sha256.Sum256([]byte(token))
+	return &etagTransport{
+		transport: transport,
+		dir:       dir,
+		tokenHash: hex.EncodeToString(sum[:]),
+		entries:   make(map[string]etagEntry),
+	}
+}
+
+// lookup returns the entry of key from memory, or else from dir
+func (t *etagTransport) lookup(key string) (etagEntry, bool) {
+	t.mu.Lock()
+	entry, ok := t.entries[key]
+	t.mu.Unlock()
+	if ok || t.dir == "" {
+		return entry, ok
+	}
+
+	data, err := os.ReadFile(t.entryPath(key))
+	if err != nil {
+		return etagEntry{}, false
+	}
+	if err := json.Unmarshal(data, &entry); err != nil || entry.ETag == "" {
+		return etagEntry{}, false
+	}
+	t.mu.Lock()
+	t.entries[key] = entry
+	t.mu.Unlock()
+	return entry, true
+}
+
+// store keeps entry in memory and, if set, in dir. The file is replaced
+// atomically, so that concurrent runs never read half an entry.
+func (t *etagTransport) store(key string, entry etagEntry) {
+	t.mu.Lock()
+	t.entries[key] = entry
+	t.mu.Unlock()
+	if t.dir == "" {
+		return
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil || os.MkdirAll(t.dir, 0o700) != nil {
+		return
+	}
+	f, err := os.CreateTemp(t.dir, "entry-*.tmp")
+	if err != nil {
+		return
+	}
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), t.entryPath(key))
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+}
+
+// entryPath returns the file of key in dir
+func (t *etagTransport) entryPath(key string) string {
+	sum := sha256.Sum256([]byte(t.tokenHash + " " + key))
+	return filepath.Join(t.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *etagTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet || req.Context().Value(etagCacheKey{}) == nil {
+		return t.transport.RoundTrip(req)
+	}
+
+	key := req.URL.String()
+	entry, cached := t.lookup(key)
+
+	if cached {
+		req = req.Clone(req.Context())
+		req.Header.Set("If-None-Match", entry.ETag)
+	}
+
+	resp, err := t.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified && cached {
+		resp.Body.Close()
+		return cachedResponse(req, resp, entry), nil
+	}
+
+	etag := resp.Header.Get("ETag")
+	if resp.StatusCode != http.StatusOK || etag == "" {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	t.store(key, etagEntry{ETag: etag, Header: resp.Header.Clone(), Body: body})
+	return resp, nil
+}
+
+// cachedResponse builds a 200 response from a cache entry. Headers such as
+// Link for pagination come from the cache; the rate limit headers of the 304
+// response are kept, since they describe the current state.
+func cachedResponse(req *http.Request, notModified *http.Response, entry etagEntry) *http.Response {
+	header := entry.Header.Clone()
+	for name, values := range notModified.Header {
+		if strings.HasPrefix(name, "X-Ratelimit-") {
+			header[name] = values
+		}
+	}
+
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         notModified.Proto,
+		ProtoMajor:    notModified.ProtoMajor,
+		ProtoMinor:    notModified.ProtoMinor,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(entry.Body)),
+		ContentLength: int64(len(entry.Body)),
+		Request:       req,
+	}
+}
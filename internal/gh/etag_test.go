@@ -0,0 +1,164 @@
+package gh
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newETagServer serves body with ETag etag and answers matching conditional
+// requests with 304 Not Modified. It counts full and 304 responses.
+func newETagServer(t *testing.T, etag, body string) (*Client, *atomic.Int32, *atomic.Int32) {
+	t.Helper()
+	var full, notModified atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == etag {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		full.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", etag)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := NewClient("ghp_fake_token")
+	require.NoError(t, err)
+	client.client.BaseURL, err = url.Parse(server.URL + "/")
+	require.NoError(t, err)
+	return client, &full, &notModified
+}
+
+func TestListPackages_NotModifiedReusesCache(t *testing.T) {
+	t.Parallel()
+	client, full, notModified := newETagServer(t, `"v1"`,
+		`[{"name": "web", "package_type": "container"}, {"name": "api", "package_type": "container"}]`)
+	ctx := context.Background()
+
+	first, err := client.ListPackages(ctx, "myorg", "org", PackageTypeContainer)
+	require.NoError(t, err)
+	second, err := client.ListPackages(ctx, "myorg", "org", PackageTypeContainer)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"api", "web"}, first)
+	assert.Equal(t, first, second)
+	assert.Equal(t, int32(1), full.Load())
+	assert.Equal(t, int32(1), notModified.Load(), "the second listing is a conditional request")
+}
+
+func TestListPackageVersions_NotModifiedReusesCache(t *testing.T) {
+	t.Parallel()
+	client, full, notModified := newETagServer(t, `W/"abc"`,
+		`[{"id": 1, "name": "sha256:aaa", "metadata": {"container": {"tags": ["latest"]}}}]`)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		versions, err := client.ListPackageVersions(ctx, "mkoepf", "user", "myimage")
+		require.NoError(t, err)
+		require.Len(t, versions, 1)
+		assert.Equal(t, int64(1), versions[0].ID)
+		assert.Equal(t, []string{"latest"}, versions[0].Tags)
+	}
+	assert.Equal(t, int32(1), full.Load())
+	assert.Equal(t, int32(2), notModified.Load())
+}
+
+func TestETagTransport_OnlyCachesMarkedRequests(t *testing.T) {
+	t.Parallel()
+	client, full, notModified := newETagServer(t, `"v1"`, `{"login": "mkoepf", "type": "User"}`)
+
+	for i := 0; i < 2; i++ {
+		info, err := client.WhoAmI(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "mkoepf", info.Login)
+	}
+	assert.Equal(t, int32(2), full.Load())
+	assert.Zero(t, notModified.Load())
+}
+
+// newClientLike creates a client for the server of client, with ctx and token
+func newClientLike(t *testing.T, ctx context.Context, client *Client, token string) *Client {
+	t.Helper()
+	other, err := NewClientWithContext(ctx, token)
+	require.NoError(t, err)
+	other.client.BaseURL = client.client.BaseURL
+	return other
+}
+
+func TestETagTransport_PersistsBetweenClients(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	client, full, notModified := newETagServer(t, `"v1"`,
+		`[{"name": "web", "package_type": "container"}]`)
+	ctx := WithCacheDir(context.Background(), dir)
+
+	// A first run fills the cache directory
+	_, err := newClientLike(t, ctx, client, "ghp_fake_token").ListPackages(ctx, "myorg", "org", PackageTypeContainer)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), full.Load())
+
+	// A later run with the same token sends a conditional request
+	packages, err := newClientLike(t, ctx, client, "ghp_fake_token").ListPackages(ctx, "myorg", "org", PackageTypeContainer)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"web"}, packages)
+	assert.Equal(t, int32(1), full.Load())
+	assert.Equal(t, int32(1), notModified.Load(), "the second run is answered with 304 Not Modified")
+
+	// Another token does not see the entries
+	_, err = newClientLike(t, ctx, client, "ghp_other_token").ListPackages(ctx, "myorg", "org", PackageTypeContainer)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), full.Load())
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2, "one entry per token")
+	for _, entry := range entries {
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), "ghp_", "tokens are not written to the cache")
+		info, err := entry.Info()
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	}
+}
+
+func TestETagTransport_IgnoresBrokenCacheFiles(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	client, full, notModified := newETagServer(t, `"v1"`, `[{"name": "web", "package_type": "container"}]`)
+	ctx := WithCacheDir(context.Background(), dir)
+
+	_, err := newClientLike(t, ctx, client, "ghp_fake_token").ListPackages(ctx, "myorg", "org", PackageTypeContainer)
+	require.NoError(t, err)
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, entries[0].Name()), []byte("not json"), 0o600))
+
+	packages, err := newClientLike(t, ctx, client, "ghp_fake_token").ListPackages(ctx, "myorg", "org", PackageTypeContainer)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"web"}, packages)
+	assert.Equal(t, int32(2), full.Load())
+	assert.Zero(t, notModified.Load())
+}
+
+func TestETagTransport_MemoryOnlyWithoutCacheDir(t *testing.T) {
+	t.Parallel()
+	assert.Empty(t, CacheDirFromContext(context.Background()))
+	assert.Equal(t, "/tmp/etags", CacheDirFromContext(WithCacheDir(context.Background(), "/tmp/etags")))
+
+	transport := newETagTransport(http.DefaultTransport, "", "ghp_fake_token")
+	transport.store("https://api.github.com/x", etagEntry{ETag: `"v1"`})
+	_, ok := transport.lookup("https://api.github.com/x")
+	assert.True(t, ok)
+}
@@ -0,0 +1,118 @@
+// Package metrics writes the result of a deletion run as Prometheus metrics in
+// the text format, for the textfile collector of node_exporter.
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+type contextKey struct{}
+
+// Run is the result of a deletion run for one package
+type Run struct {
+	Owner          string
+	Package        string
+	Deleted        int
+	Failed         int
+	BytesReclaimed int64
+	SizeKnown      bool // BytesReclaimed is only written if the sizes of the deleted versions were discovered
+	Duration       time.Duration
+}
+
+// metric is one sample of the output with its help text
+type metric struct {
+	name  string
+	help  string
+	value string
+}
+
+// Format writes run in the Prometheus text exposition format, labeled by owner
+// and package. The values describe the last run, so all metrics are gauges.
+func Format(w io.Writer, run Run) error {
+	metrics := []metric{
+		{"ghcrctl_versions_deleted_total", "Package versions deleted by the last run.", fmt.Sprintf("%d", run.Deleted)},
+		{"ghcrctl_versions_failed_total", "Package versions the last run failed to delete.", fmt.Sprintf("%d", run.Failed)},
+	}
+	if run.SizeKnown {
+		metrics = append(metrics, metric{"ghcrctl_bytes_reclaimed", "Size in bytes of the manifests and artifacts deleted by the last run.", fmt.Sprintf("%d", run.BytesReclaimed)})
+	}
+	metrics = append(metrics, metric{"ghcrctl_run_duration_seconds", "Duration of the last run in seconds.", fmt.Sprintf("%.3f", run.Duration.Seconds())})
+
+	labels := fmt.Sprintf(`{owner="%s",package="%s"}`, escapeLabel(run.Owner), escapeLabel(run.Package))
+	var b strings.Builder
+	for _, m := range metrics {
+		fmt.Fprintf(&b, "# HELP %s %s\n", m.name, m.help)
+		fmt.Fprintf(&b, "# TYPE %s gauge\n", m.name)
+		fmt.Fprintf(&b, "%s%s %s\n", m.name, labels, m.value)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// escapeLabel escapes a label value as the text format requires
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// WriteFile writes run to path. The metrics are written to a temporary file in
+// the same directory first and then renamed, so that the collector never reads
+// a partial file.
+func WriteFile(path string, run Run) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := Format(tmp, run); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+	// CreateTemp creates the file readable by the owner only
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write metrics %s: %w", path, err)
+	}
+	return nil
+}
+
+// recorder is the metrics file of a run and what its metrics are labeled with
+type recorder struct {
+	path    string
+	owner   string
+	pkg     string
+	started time.Time
+}
+
+// WithFile returns a context in which Record writes the metrics of the run to
+// path. The run duration is measured from now.
+func WithFile(ctx context.Context, path, owner, packageName string) context.Context {
+	return context.WithValue(ctx, contextKey{}, recorder{path: path, owner: owner, pkg: packageName, started: time.Now()})
+}
+
+// Record writes the metrics of run, labeled and timed from the context, to the
+// file set by WithFile. It does nothing if no metrics file is set.
+func Record(ctx context.Context, run Run) error {
+	if ctx == nil {
+		return nil
+	}
+	r, ok := ctx.Value(contextKey{}).(recorder)
+	if !ok {
+		return nil
+	}
+	run.Owner = r.owner
+	run.Package = r.pkg
+	run.Duration = time.Since(r.started)
+	return WriteFile(r.path, run)
+}
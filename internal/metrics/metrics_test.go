@@ -0,0 +1,78 @@
+package metrics
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormat(t *testing.T) {
+	t.Parallel()
+	var b strings.Builder
+	err := Format(&b, Run{
+		Owner:          "mkoepf",
+		Package:        "my\"image\\x",
+		Deleted:        3,
+		Failed:         1,
+		BytesReclaimed: 2048,
+		SizeKnown:      true,
+		Duration:       1500 * time.Millisecond,
+	})
+	require.NoError(t, err)
+
+	labels := `{owner="mkoepf",package="my\"image\\x"}`
+	assert.Equal(t, "# HELP ghcrctl_versions_deleted_total Package versions deleted by the last run.\n"+
+		"# TYPE ghcrctl_versions_deleted_total gauge\n"+
+		"ghcrctl_versions_deleted_total"+labels+" 3\n"+
+		"# HELP ghcrctl_versions_failed_total Package versions the last run failed to delete.\n"+
+		"# TYPE ghcrctl_versions_failed_total gauge\n"+
+		"ghcrctl_versions_failed_total"+labels+" 1\n"+
+		"# HELP ghcrctl_bytes_reclaimed Size in bytes of the manifests and artifacts deleted by the last run.\n"+
+		"# TYPE ghcrctl_bytes_reclaimed gauge\n"+
+		"ghcrctl_bytes_reclaimed"+labels+" 2048\n"+
+		"# HELP ghcrctl_run_duration_seconds Duration of the last run in seconds.\n"+
+		"# TYPE ghcrctl_run_duration_seconds gauge\n"+
+		"ghcrctl_run_duration_seconds"+labels+" 1.500\n", b.String())
+}
+
+func TestFormat_SizeUnknown(t *testing.T) {
+	t.Parallel()
+	var b strings.Builder
+	require.NoError(t, Format(&b, Run{Owner: "mkoepf", Package: "myimage", Deleted: 2}))
+	assert.NotContains(t, b.String(), "ghcrctl_bytes_reclaimed")
+	assert.Contains(t, b.String(), `ghcrctl_versions_deleted_total{owner="mkoepf",package="myimage"} 2`+"\n")
+}
+
+func TestRecord(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "ghcrctl.prom")
+
+	// Without a metrics file nothing is written
+	require.NoError(t, Record(context.Background(), Run{Deleted: 1}))
+	_, err := os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+
+	ctx := WithFile(context.Background(), path, "mkoepf", "myimage")
+	require.NoError(t, Record(ctx, Run{Deleted: 2, Failed: 1}))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `ghcrctl_versions_deleted_total{owner="mkoepf",package="myimage"} 2`+"\n")
+	assert.Contains(t, string(data), `ghcrctl_versions_failed_total{owner="mkoepf",package="myimage"} 1`+"\n")
+	assert.Contains(t, string(data), `ghcrctl_run_duration_seconds{owner="mkoepf",package="myimage"} `)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o644), info.Mode().Perm())
+
+	// No temporary files are left behind
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
@@ -0,0 +1,96 @@
+// Package parallel runs per-package work with bounded concurrency, isolating
+// the failure of one package from the others.
+package parallel
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// DefaultLimit is the default number of packages processed at once
+const DefaultLimit = 4
+
+// Run calls fn for the items 0..n-1, with at most limit calls running at once.
+// It returns the error of each item, nil for items that succeeded. A failing
+// item does not stop the others; once ctx is cancelled, items that have not
+// started yet fail with the context's error.
+func Run(ctx context.Context, limit, n int, fn func(ctx context.Context, i int) error) []error {
+	if limit < 1 {
+		limit = 1
+	}
+
+	errs := make([]error, n)
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			for j := i; j < n; j++ {
+				errs[j] = ctx.Err()
+			}
+			wg.Wait()
+			return errs
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := ctx.Err(); err != nil {
+				errs[i] = err
+				return
+			}
+			errs[i] = fn(ctx, i)
+		}(i)
+	}
+	wg.Wait()
+	return errs
+}
+
+// ItemError is the failure of one named item
+type ItemError struct {
+	Name string
+	Err  error
+}
+
+// Errors aggregates the failures of a run
+type Errors struct {
+	Total  int // number of items in the run
+	Failed []ItemError
+}
+
+func (e *Errors) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d of %d packages failed:", len(e.Failed), e.Total)
+	for _, f := range e.Failed {
+		fmt.Fprintf(&b, "\n  %s: %v", f.Name, f.Err)
+	}
+	return b.String()
+}
+
+// Unwrap returns the errors of the failed items, for errors.Is and errors.As
+func (e *Errors) Unwrap() []error {
+	errs := make([]error, len(e.Failed))
+	for i, f := range e.Failed {
+		errs[i] = f.Err
+	}
+	return errs
+}
+
+// Collect pairs the errors returned by Run with the names of the items. It
+// returns an *Errors if any item failed, and nil otherwise.
+func Collect(names []string, errs []error) error {
+	agg := &Errors{Total: len(names)}
+	for i, err := range errs {
+		if err != nil {
+			agg.Failed = append(agg.Failed, ItemError{Name: names[i], Err: err})
+		}
+	}
+	if len(agg.Failed) == 0 {
+		return nil
+	}
+	return agg
+}
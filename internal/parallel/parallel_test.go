@@ -0,0 +1,92 @@
+package parallel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun_BoundedConcurrency(t *testing.T) {
+	t.Parallel()
+
+	var running, peak atomic.Int32
+	errs := Run(context.Background(), 3, 20, func(ctx context.Context, i int) error {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		running.Add(-1)
+		return nil
+	})
+
+	assert.Len(t, errs, 20)
+	for _, err := range errs {
+		assert.NoError(t, err)
+	}
+	assert.LessOrEqual(t, peak.Load(), int32(3))
+	assert.Greater(t, peak.Load(), int32(1), "items should run concurrently")
+}
+
+func TestRun_IsolatesErrors(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	errs := Run(context.Background(), 2, 4, func(ctx context.Context, i int) error {
+		calls.Add(1)
+		if i%2 == 1 {
+			return fmt.Errorf("item %d failed", i)
+		}
+		return nil
+	})
+
+	assert.Equal(t, int32(4), calls.Load())
+	assert.NoError(t, errs[0])
+	assert.EqualError(t, errs[1], "item 1 failed")
+	assert.NoError(t, errs[2])
+	assert.EqualError(t, errs[3], "item 3 failed")
+}
+
+func TestRun_Cancelled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := Run(ctx, 1, 5, func(ctx context.Context, i int) error {
+		if i == 1 {
+			cancel()
+		}
+		return nil
+	})
+
+	assert.NoError(t, errs[0])
+	assert.NoError(t, errs[1])
+	for _, err := range errs[2:] {
+		assert.ErrorIs(t, err, context.Canceled)
+	}
+}
+
+func TestCollect(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, Collect([]string{"a", "b"}, []error{nil, nil}))
+
+	notFound := errors.New("not found")
+	err := Collect([]string{"a", "b", "c"}, []error{nil, notFound, fmt.Errorf("forbidden")})
+	require.Error(t, err)
+	assert.Equal(t, "2 of 3 packages failed:\n  b: not found\n  c: forbidden", err.Error())
+	assert.ErrorIs(t, err, notFound)
+
+	var agg *Errors
+	require.ErrorAs(t, err, &agg)
+	assert.Equal(t, 3, agg.Total)
+	assert.Equal(t, "b", agg.Failed[0].Name)
+}
@@ -0,0 +1,103 @@
+// Package provenance extracts the materials of SLSA provenance statements, as
+// attached to images by buildx or the SLSA GitHub generator.
+package provenance
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Versions of the SLSA provenance predicate
+const (
+	SLSAv02 = "v0.2"
+	SLSAv1  = "v1"
+)
+
+// Material is an input of a build, such as the source repository or a base
+// image, with the digests it was resolved to
+type Material struct {
+	URI    string            `json:"uri"`
+	Name   string            `json:"name,omitempty"`
+	Digest map[string]string `json:"digest,omitempty"`
+}
+
+// DigestString returns the digest of the material as algorithm:value, with
+// sha256 preferred over other algorithms, or "" if it has none
+func (m Material) DigestString() string {
+	if value, ok := m.Digest["sha256"]; ok {
+		return "sha256:" + value
+	}
+	algorithms := make([]string, 0, len(m.Digest))
+	for algorithm := range m.Digest {
+		algorithms = append(algorithms, algorithm)
+	}
+	if len(algorithms) == 0 {
+		return ""
+	}
+	sort.Strings(algorithms)
+	return algorithms[0] + ":" + m.Digest[algorithms[0]]
+}
+
+// Document is the normalized form of a provenance predicate
+type Document struct {
+	SLSAVersion string     // SLSAv02 or SLSAv1
+	Materials   []Material // in the order of the predicate
+}
+
+// Parse extracts the materials of an in-toto statement carrying SLSA
+// provenance: predicate.materials in SLSA v0.2 and
+// predicate.buildDefinition.resolvedDependencies in SLSA v1.
+func Parse(statement map[string]interface{}) (*Document, error) {
+	predicate, ok := statement["predicate"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("not an in-toto statement")
+	}
+	predicateType := stringField(statement, "predicateType")
+
+	var parsed *Document
+	switch {
+	case strings.HasPrefix(predicateType, "https://slsa.dev/provenance/v1"), predicate["buildDefinition"] != nil:
+		buildDefinition, _ := predicate["buildDefinition"].(map[string]interface{})
+		parsed = &Document{SLSAVersion: SLSAv1, Materials: parseMaterials(buildDefinition["resolvedDependencies"])}
+	case strings.HasPrefix(predicateType, "https://slsa.dev/provenance/v0.2"), predicate["materials"] != nil:
+		parsed = &Document{SLSAVersion: SLSAv02, Materials: parseMaterials(predicate["materials"])}
+	default:
+		return nil, fmt.Errorf("not an SLSA provenance statement (predicate type %q)", predicateType)
+	}
+	return parsed, nil
+}
+
+// parseMaterials reads a list of SLSA v0.2 materials or SLSA v1 resource
+// descriptors. Entries without URI, name and digest are skipped.
+func parseMaterials(value interface{}) []Material {
+	entries, _ := value.([]interface{})
+	materials := make([]Material, 0, len(entries))
+	for _, e := range entries {
+		entry, ok := e.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		material := Material{URI: stringField(entry, "uri"), Name: stringField(entry, "name")}
+		digests, _ := entry["digest"].(map[string]interface{})
+		for algorithm, v := range digests {
+			if value, ok := v.(string); ok && value != "" {
+				if material.Digest == nil {
+					material.Digest = make(map[string]string)
+				}
+				material.Digest[algorithm] = value
+			}
+		}
+		if material.URI == "" && material.Name == "" && material.Digest == nil {
+			continue
+		}
+		materials = append(materials, material)
+	}
+	return materials
+}
+
+// stringField returns the string value of key in m, or "" if it is missing
+func stringField(m map[string]interface{}, key string) string {
+	s, _ := m[key].(string)
+	return s
+}
@@ -0,0 +1,89 @@
+package provenance
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// slsaV02Statement is SLSA v0.2 provenance as attached by buildx
+const slsaV02Statement = `{
+  "_type": "https://in-toto.io/Statement/v0.1",
+  "predicateType": "https://slsa.dev/provenance/v0.2",
+  "subject": [{"name": "pkg:docker/mkoepf/myimage@v1", "digest": {"sha256": "aaa"}}],
+  "predicate": {
+    "builder": {"id": "https://github.com/mkoepf/myimage/actions/runs/1"},
+    "buildType": "https://mobyproject.org/buildkit@v1",
+    "materials": [
+      {"uri": "pkg:docker/golang@1.23-alpine?platform=linux%2Famd64", "digest": {"sha256": "b0c1"}},
+      {"uri": "https://github.com/mkoepf/myimage.git#refs/heads/main", "digest": {"sha1": "9f2e"}},
+      {}
+    ]
+  }
+}`
+
+// slsaV1Statement is SLSA v1 provenance as generated by GitHub artifact attestations
+const slsaV1Statement = `{
+  "_type": "https://in-toto.io/Statement/v1",
+  "predicateType": "https://slsa.dev/provenance/v1",
+  "subject": [{"name": "ghcr.io/mkoepf/myimage", "digest": {"sha256": "aaa"}}],
+  "predicate": {
+    "buildDefinition": {
+      "buildType": "https://actions.github.io/buildtypes/workflow/v1",
+      "resolvedDependencies": [
+        {"uri": "git+https://github.com/mkoepf/myimage@refs/heads/main", "digest": {"gitCommit": "9f2e"}},
+        {"name": "base", "uri": "pkg:docker/alpine@3.20", "digest": {"sha512": "e5", "sha256": "d4"}}
+      ]
+    },
+    "runDetails": {"builder": {"id": "https://github.com/actions/runner/github-hosted"}}
+  }
+}`
+
+func parseFixture(t *testing.T, fixture string) map[string]interface{} {
+	t.Helper()
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(fixture), &doc))
+	return doc
+}
+
+func TestParse_SLSAv02(t *testing.T) {
+	t.Parallel()
+	doc, err := Parse(parseFixture(t, slsaV02Statement))
+	require.NoError(t, err)
+
+	assert.Equal(t, SLSAv02, doc.SLSAVersion)
+	assert.Equal(t, []Material{
+		{URI: "pkg:docker/golang@1.23-alpine?platform=linux%2Famd64", Digest: map[string]string{"sha256": "b0c1"}},
+		{URI: "https://github.com/mkoepf/myimage.git#refs/heads/main", Digest: map[string]string{"sha1": "9f2e"}},
+	}, doc.Materials)
+}
+
+func TestParse_SLSAv1(t *testing.T) {
+	t.Parallel()
+	doc, err := Parse(parseFixture(t, slsaV1Statement))
+	require.NoError(t, err)
+
+	assert.Equal(t, SLSAv1, doc.SLSAVersion)
+	assert.Equal(t, []Material{
+		{URI: "git+https://github.com/mkoepf/myimage@refs/heads/main", Digest: map[string]string{"gitCommit": "9f2e"}},
+		{URI: "pkg:docker/alpine@3.20", Name: "base", Digest: map[string]string{"sha256": "d4", "sha512": "e5"}},
+	}, doc.Materials)
+}
+
+func TestParse_NotProvenance(t *testing.T) {
+	t.Parallel()
+	_, err := Parse(parseFixture(t, `{"predicateType": "https://spdx.dev/Document", "predicate": {"spdxVersion": "SPDX-2.3"}}`))
+	assert.EqualError(t, err, `not an SLSA provenance statement (predicate type "https://spdx.dev/Document")`)
+
+	_, err = Parse(parseFixture(t, `{"spdxVersion": "SPDX-2.3"}`))
+	assert.EqualError(t, err, "not an in-toto statement")
+}
+
+func TestMaterial_DigestString(t *testing.T) {
+	t.Parallel()
+	assert.Equal(t, "sha256:d4", Material{Digest: map[string]string{"sha512": "e5", "sha256": "d4"}}.DigestString())
+	assert.Equal(t, "gitCommit:9f2e", Material{Digest: map[string]string{"sha1": "9f2f", "gitCommit": "9f2e"}}.DigestString())
+	assert.Equal(t, "", Material{URI: "https://example.com"}.DigestString())
+}
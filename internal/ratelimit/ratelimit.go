@@ -0,0 +1,92 @@
+// Package ratelimit paces outgoing HTTP requests. When enabled via the --rate
+// flag, every request to the GitHub API and the registry waits for a token, so
+// large scans and deletes stay below GitHub's secondary rate limits instead of
+// reacting to them after the fact.
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// contextKey is a private type for context keys
+type contextKey int
+
+const (
+	limiterKey contextKey = iota
+)
+
+// Limiter is a token bucket with a burst of one: requests are spaced at least
+// 1/rate apart. It is safe for concurrent use, so one limiter can be shared by
+// all HTTP clients of a command.
+type Limiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// NewLimiter creates a limiter that allows perSecond requests per second.
+// perSecond must be positive.
+func NewLimiter(perSecond float64) *Limiter {
+	return &Limiter{interval: time.Duration(float64(time.Second) / perSecond)}
+}
+
+// Wait blocks until the next request may be sent or ctx is done
+func (l *Limiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	slot := l.next
+	if slot.Before(now) {
+		slot = now
+	}
+	l.next = slot.Add(l.interval)
+	l.mu.Unlock()
+
+	delay := time.Until(slot)
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// WithLimiter returns a context whose HTTP clients are paced by l
+func WithLimiter(ctx context.Context, l *Limiter) context.Context {
+	return context.WithValue(ctx, limiterKey, l)
+}
+
+// FromContext returns the limiter of ctx, or nil if requests are not paced
+func FromContext(ctx context.Context) *Limiter {
+	l, _ := ctx.Value(limiterKey).(*Limiter)
+	return l
+}
+
+// RoundTripper waits for the limiter before passing each request to the
+// wrapped transport
+type RoundTripper struct {
+	transport http.RoundTripper
+	limiter   *Limiter
+}
+
+// NewRoundTripper creates a round tripper that paces requests through limiter.
+// It should wrap the logging round tripper, so logged durations do not include
+// the time spent waiting.
+func NewRoundTripper(transport http.RoundTripper, limiter *Limiter) *RoundTripper {
+	return &RoundTripper{transport: transport, limiter: limiter}
+}
+
+// RoundTrip implements http.RoundTripper
+func (rt *RoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := rt.limiter.Wait(req.Context()); err != nil {
+		return nil, err
+	}
+	return rt.transport.RoundTrip(req)
+}
@@ -0,0 +1,55 @@
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoundTripper_PacesRequests(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	const rate = 20.0
+	const requests = 5
+	client := &http.Client{Transport: NewRoundTripper(http.DefaultTransport, NewLimiter(rate))}
+
+	start := time.Now()
+	for i := 0; i < requests; i++ {
+		resp, err := client.Get(server.URL)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+	elapsed := time.Since(start)
+
+	// The first request goes out immediately, each further one waits 1/rate
+	minimum := time.Duration(float64(requests-1) / rate * float64(time.Second))
+	assert.GreaterOrEqual(t, elapsed, minimum)
+}
+
+func TestLimiter_WaitHonorsContext(t *testing.T) {
+	t.Parallel()
+	limiter := NewLimiter(0.1)
+	require.NoError(t, limiter.Wait(context.Background()), "the first request is not delayed")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := limiter.Wait(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestFromContext(t *testing.T) {
+	t.Parallel()
+	assert.Nil(t, FromContext(context.Background()))
+
+	limiter := NewLimiter(1)
+	assert.Same(t, limiter, FromContext(WithLimiter(context.Background(), limiter)))
+}
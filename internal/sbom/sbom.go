@@ -0,0 +1,160 @@
+// Package sbom extracts the component list from SPDX and CycloneDX documents,
+// as attached to images by buildx or cosign.
+package sbom
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Formats of SBOM documents
+const (
+	FormatSPDX      = "SPDX"
+	FormatCycloneDX = "CycloneDX"
+)
+
+// Component is a package listed in an SBOM, reduced to what is shown in the
+// component table
+type Component struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	License string `json:"license"`
+}
+
+// Document is the normalized form of an SBOM
+type Document struct {
+	Format     string      // FormatSPDX or FormatCycloneDX
+	Version    string      // version of the format, e.g. SPDX-2.3 or 1.5
+	Components []Component // sorted by name and version
+}
+
+// Spec names the format and its version, e.g. SPDX-2.3 or CycloneDX 1.5
+func (d *Document) Spec() string {
+	switch {
+	case d.Version == "":
+		return d.Format
+	case strings.HasPrefix(d.Version, d.Format):
+		return d.Version
+	default:
+		return d.Format + " " + d.Version
+	}
+}
+
+// Parse extracts the components of an SBOM. doc is an SPDX or CycloneDX JSON
+// document, or an in-toto statement carrying one as its predicate.
+func Parse(doc map[string]interface{}) (*Document, error) {
+	if predicate, ok := doc["predicate"].(map[string]interface{}); ok {
+		doc = predicate
+	}
+
+	var parsed *Document
+	switch {
+	case doc["spdxVersion"] != nil:
+		parsed = parseSPDX(doc)
+	case doc["bomFormat"] == FormatCycloneDX:
+		parsed = parseCycloneDX(doc)
+	default:
+		return nil, fmt.Errorf("not an SPDX or CycloneDX document")
+	}
+
+	sort.SliceStable(parsed.Components, func(i, j int) bool {
+		a, b := parsed.Components[i], parsed.Components[j]
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Version < b.Version
+	})
+	return parsed, nil
+}
+
+// parseSPDX reads the packages of an SPDX 2.x document. The concluded license
+// is preferred over the declared one.
+func parseSPDX(doc map[string]interface{}) *Document {
+	parsed := &Document{Format: FormatSPDX, Version: stringField(doc, "spdxVersion")}
+	packages, _ := doc["packages"].([]interface{})
+	for _, p := range packages {
+		pkg, ok := p.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		license := spdxLicense(stringField(pkg, "licenseConcluded"))
+		if license == "" {
+			license = spdxLicense(stringField(pkg, "licenseDeclared"))
+		}
+		parsed.Components = append(parsed.Components, Component{
+			Name:    stringField(pkg, "name"),
+			Version: stringField(pkg, "versionInfo"),
+			License: license,
+		})
+	}
+	return parsed
+}
+
+// spdxLicense drops the SPDX placeholders for unknown licenses
+func spdxLicense(license string) string {
+	if license == "NOASSERTION" || license == "NONE" {
+		return ""
+	}
+	return license
+}
+
+// parseCycloneDX reads the components of a CycloneDX document, including
+// nested ones. Components with a group are named group/name.
+func parseCycloneDX(doc map[string]interface{}) *Document {
+	parsed := &Document{Format: FormatCycloneDX, Version: stringField(doc, "specVersion")}
+
+	var collect func(components []interface{})
+	collect = func(components []interface{}) {
+		for _, c := range components {
+			component, ok := c.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name := stringField(component, "name")
+			if group := stringField(component, "group"); group != "" {
+				name = group + "/" + name
+			}
+			parsed.Components = append(parsed.Components, Component{
+				Name:    name,
+				Version: stringField(component, "version"),
+				License: cycloneDXLicense(component),
+			})
+			nested, _ := component["components"].([]interface{})
+			collect(nested)
+		}
+	}
+	components, _ := doc["components"].([]interface{})
+	collect(components)
+	return parsed
+}
+
+// cycloneDXLicense joins the licenses of a component. Each entry is either an
+// SPDX expression or a license with an id or name.
+func cycloneDXLicense(component map[string]interface{}) string {
+	entries, _ := component["licenses"].([]interface{})
+	var licenses []string
+	for _, e := range entries {
+		entry, ok := e.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if expression := stringField(entry, "expression"); expression != "" {
+			licenses = append(licenses, expression)
+			continue
+		}
+		license, _ := entry["license"].(map[string]interface{})
+		if id := stringField(license, "id"); id != "" {
+			licenses = append(licenses, id)
+		} else if name := stringField(license, "name"); name != "" {
+			licenses = append(licenses, name)
+		}
+	}
+	return strings.Join(licenses, ", ")
+}
+
+// stringField returns the string value of key in m, or "" if it is missing
+func stringField(m map[string]interface{}, key string) string {
+	s, _ := m[key].(string)
+	return s
+}
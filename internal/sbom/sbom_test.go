@@ -0,0 +1,97 @@
+package sbom
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// spdxStatement is an SPDX SBOM as attached by buildx
+const spdxStatement = `{
+  "_type": "https://in-toto.io/Statement/v0.1",
+  "predicateType": "https://spdx.dev/Document",
+  "predicate": {
+    "spdxVersion": "SPDX-2.3",
+    "name": "sbom",
+    "packages": [
+      {"name": "zlib", "versionInfo": "1.3.1-r0", "licenseConcluded": "NOASSERTION", "licenseDeclared": "Zlib"},
+      {"name": "busybox", "versionInfo": "1.36.1-r15", "licenseConcluded": "GPL-2.0-only", "licenseDeclared": "GPL-2.0-only"},
+      {"name": "alpine-baselayout", "versionInfo": "3.4.3-r2", "licenseConcluded": "NOASSERTION", "licenseDeclared": "NOASSERTION"}
+    ]
+  }
+}`
+
+// cycloneDXDocument is a CycloneDX SBOM with license ids, names, expressions,
+// and nested components
+const cycloneDXDocument = `{
+  "bomFormat": "CycloneDX",
+  "specVersion": "1.5",
+  "components": [
+    {"type": "library", "name": "golang.org/x/net", "version": "v0.24.0", "licenses": [{"license": {"id": "BSD-3-Clause"}}]},
+    {"type": "library", "group": "org.apache.logging.log4j", "name": "log4j-core", "version": "2.17.1",
+     "licenses": [{"license": {"name": "Apache License 2.0"}}],
+     "components": [{"type": "library", "name": "log4j-api", "version": "2.17.1"}]},
+    {"type": "library", "name": "openssl", "version": "3.1.4", "licenses": [{"expression": "Apache-2.0 OR OpenSSL"}]}
+  ]
+}`
+
+func parseFixture(t *testing.T, fixture string) map[string]interface{} {
+	t.Helper()
+	var doc map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(fixture), &doc))
+	return doc
+}
+
+func TestParse_SPDX(t *testing.T) {
+	t.Parallel()
+
+	doc, err := Parse(parseFixture(t, spdxStatement))
+	require.NoError(t, err)
+
+	assert.Equal(t, FormatSPDX, doc.Format)
+	assert.Equal(t, "SPDX-2.3", doc.Version)
+	assert.Equal(t, "SPDX-2.3", doc.Spec())
+	assert.Equal(t, []Component{
+		{Name: "alpine-baselayout", Version: "3.4.3-r2"},
+		{Name: "busybox", Version: "1.36.1-r15", License: "GPL-2.0-only"},
+		{Name: "zlib", Version: "1.3.1-r0", License: "Zlib"},
+	}, doc.Components)
+}
+
+func TestParse_CycloneDX(t *testing.T) {
+	t.Parallel()
+
+	doc, err := Parse(parseFixture(t, cycloneDXDocument))
+	require.NoError(t, err)
+
+	assert.Equal(t, FormatCycloneDX, doc.Format)
+	assert.Equal(t, "1.5", doc.Version)
+	assert.Equal(t, "CycloneDX 1.5", doc.Spec())
+	assert.Equal(t, []Component{
+		{Name: "golang.org/x/net", Version: "v0.24.0", License: "BSD-3-Clause"},
+		{Name: "log4j-api", Version: "2.17.1"},
+		{Name: "openssl", Version: "3.1.4", License: "Apache-2.0 OR OpenSSL"},
+		{Name: "org.apache.logging.log4j/log4j-core", Version: "2.17.1", License: "Apache License 2.0"},
+	}, doc.Components)
+}
+
+func TestParse_CycloneDXStatement(t *testing.T) {
+	t.Parallel()
+
+	statement := map[string]interface{}{
+		"predicateType": "https://cyclonedx.org/bom",
+		"predicate":     parseFixture(t, cycloneDXDocument),
+	}
+	doc, err := Parse(statement)
+	require.NoError(t, err)
+	assert.Len(t, doc.Components, 4)
+}
+
+func TestParse_Unknown(t *testing.T) {
+	t.Parallel()
+
+	_, err := Parse(map[string]interface{}{"predicateType": "https://slsa.dev/provenance/v1", "predicate": map[string]interface{}{}})
+	assert.EqualError(t, err, "not an SPDX or CycloneDX document")
+}